@@ -105,7 +105,7 @@ func setupAnyPostgres(ctx context.Context, logger *zap.SugaredLogger, uri string
 		return err
 	}
 
-	p, err := pool.New(uri, logger.Desugar(), sp)
+	p, err := pool.New(uri, logger.Desugar(), sp, nil)
 	if err != nil {
 		return err
 	}
@@ -183,7 +183,11 @@ func setupMongodbSecured(ctx context.Context, logger *zap.SugaredLogger) error {
 
 // setup runs all setup commands.
 func setup(ctx context.Context, logger *zap.SugaredLogger) error {
-	go debug.RunHandler(ctx, "127.0.0.1:8089", prometheus.DefaultRegisterer, logger.Named("debug").Desugar())
+	go debug.RunHandler(ctx, &debug.RunHandlerOpts{
+		Addr: "127.0.0.1:8089",
+		R:    prometheus.DefaultRegisterer,
+		L:    logger.Named("debug").Desugar(),
+	})
 
 	for _, f := range []func(context.Context, *zap.SugaredLogger) error{
 		setupPostgres,
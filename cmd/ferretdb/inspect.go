@@ -0,0 +1,391 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// Output formats of the inspect command.
+const (
+	inspectFormatText   = "text"
+	inspectFormatJSON   = "json"
+	inspectFormatScript = "script"
+)
+
+// inspectFormats are all output formats of the inspect command.
+var inspectFormats = []string{inspectFormatText, inspectFormatJSON, inspectFormatScript}
+
+// scriptSkipCommands are commands that are not included into reproduction scripts:
+// they are sent by drivers and shells on their own, or depend on the state of the original connection.
+var scriptSkipCommands = []string{
+	"hello", "isMaster", "ismaster", "ping", "buildInfo", "buildinfo",
+	"saslStart", "saslContinue", "authenticate", "logout",
+	"endSessions", "getMore", "killCursors",
+	"getLog", "getParameter", "getCmdLineOpts", "getFreeMonitoringStatus", "connectionStatus",
+}
+
+// scriptSkipFields are command fields that are removed from reproduction scripts,
+// because they are set by the shell itself or refer to the original session.
+var scriptSkipFields = []string{
+	"$db", "$clusterTime", "$readPreference", "lsid", "txnNumber", "autocommit", "startTransaction",
+}
+
+// inspectParams represents inspect command parameters.
+type inspectParams struct {
+	Paths      []string
+	Format     string
+	Commands   []string
+	Namespaces []string
+}
+
+// inspectMessage represents a single recorded client message.
+type inspectMessage struct {
+	header     *wire.MsgHeader
+	db         string
+	collection string
+	command    string
+	doc        *types.Document
+}
+
+// runInspect runs the inspect command.
+func runInspect() {
+	params := &inspectParams{
+		Paths:      cli.Inspect.Paths,
+		Format:     cli.Inspect.Format,
+		Commands:   cli.Inspect.Command,
+		Namespaces: cli.Inspect.Namespace,
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+
+	err := inspect(w, params)
+	if e := w.Flush(); err == nil {
+		err = e
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// inspect reads record files written with `--test-records-dir` flag from the given paths
+// (files or directories), filters messages, and writes them to w in the given format.
+func inspect(w io.Writer, params *inspectParams) error {
+	if !slices.Contains(inspectFormats, params.Format) {
+		return lazyerrors.Errorf("unexpected format %q", params.Format)
+	}
+
+	if params.Format == inspectFormatScript {
+		fmt.Fprintln(w, "// Reproduction script generated by `ferretdb inspect`; run it with mongosh.")
+	}
+
+	var n int
+
+	for _, path := range params.Paths {
+		records, err := wire.LoadRecords(path, 0)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		for _, r := range records {
+			msg, err := newInspectMessage(&r)
+			if err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			if msg == nil || !msg.matches(params) {
+				continue
+			}
+
+			n++
+
+			switch params.Format {
+			case inspectFormatText:
+				err = msg.writeText(w, n)
+			case inspectFormatJSON:
+				err = msg.writeJSON(w)
+			case inspectFormatScript:
+				err = msg.writeScript(w)
+			}
+
+			if err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// newInspectMessage returns inspectMessage for the given record.
+//
+// It returns nil for records without a command document, like invalid messages.
+func newInspectMessage(r *wire.Record) (*inspectMessage, error) {
+	if r.Header == nil || r.Body == nil {
+		return nil, nil
+	}
+
+	header, body := r.Header, r.Body
+
+	if compressed, ok := body.(*wire.OpCompressed); ok {
+		var err error
+		if header, body, err = wire.Decompress(header, compressed); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	res := &inspectMessage{
+		header: header,
+	}
+
+	switch body := body.(type) {
+	case *wire.OpMsg:
+		doc, err := body.Document()
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		res.doc = doc
+
+		if db, _ := doc.Get("$db"); db != nil {
+			res.db, _ = db.(string)
+		}
+
+	case *wire.OpQuery:
+		res.doc = body.Query
+		res.db, _, _ = strings.Cut(body.FullCollectionName, ".")
+
+	default:
+		return nil, nil
+	}
+
+	if res.doc == nil || res.doc.Len() == 0 {
+		return nil, nil
+	}
+
+	res.command = res.doc.Command()
+	res.collection, _ = must.NotFail(res.doc.Get(res.command)).(string)
+
+	return res, nil
+}
+
+// matches returns true if the message matches command and namespace filters.
+//
+// Namespace filters are database names or `db.collection` pairs.
+func (msg *inspectMessage) matches(params *inspectParams) bool {
+	if len(params.Commands) > 0 {
+		if !slices.ContainsFunc(params.Commands, func(c string) bool { return strings.EqualFold(c, msg.command) }) {
+			return false
+		}
+	}
+
+	if len(params.Namespaces) > 0 {
+		ns := msg.db + "." + msg.collection
+
+		if !slices.ContainsFunc(params.Namespaces, func(n string) bool { return n == msg.db || n == ns }) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// writeText writes a human-readable representation of the message.
+func (msg *inspectMessage) writeText(w io.Writer, n int) error {
+	var buf bytes.Buffer
+	if err := writeExtJSON(&buf, msg.doc); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, buf.Bytes(), "", "  "); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	ns := msg.db
+	if msg.collection != "" {
+		ns += "." + msg.collection
+	}
+
+	_, err := fmt.Fprintf(
+		w, "# %d: %s request_id=%d command=%s ns=%s\n%s\n\n",
+		n, msg.header.OpCode, msg.header.RequestID, msg.command, ns, indented.String(),
+	)
+
+	return err
+}
+
+// writeJSON writes a single line of canonical Extended JSON with the message.
+func (msg *inspectMessage) writeJSON(w io.Writer) error {
+	doc := must.NotFail(types.NewDocument(
+		"requestId", msg.header.RequestID,
+		"opCode", msg.header.OpCode.String(),
+		"db", msg.db,
+		"command", msg.doc,
+	))
+
+	var buf bytes.Buffer
+	if err := writeExtJSON(&buf, doc); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	buf.WriteByte('\n')
+
+	_, err := w.Write(buf.Bytes())
+
+	return err
+}
+
+// writeScript writes the message as a mongosh statement.
+//
+// Commands sent by drivers on their own and session fields are skipped,
+// so the script is a minimal reproduction.
+func (msg *inspectMessage) writeScript(w io.Writer) error {
+	if slices.Contains(scriptSkipCommands, msg.command) {
+		return nil
+	}
+
+	doc := msg.doc.DeepCopy()
+	for _, f := range scriptSkipFields {
+		doc.Remove(f)
+	}
+
+	var buf bytes.Buffer
+	if err := writeExtJSON(&buf, doc); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	db := msg.db
+	if db == "" {
+		db = "test"
+	}
+
+	_, err := fmt.Fprintf(
+		w, "db.getSiblingDB(%s).runCommand(EJSON.parse(%s));\n",
+		must.NotFail(json.Marshal(db)), must.NotFail(json.Marshal(buf.String())),
+	)
+
+	return err
+}
+
+// writeExtJSON writes canonical Extended JSON representation of the given value,
+// so all types are preserved.
+func writeExtJSON(buf *bytes.Buffer, v any) error {
+	switch v := v.(type) {
+	case *types.Document:
+		buf.WriteByte('{')
+
+		for i, k := range v.Keys() {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			buf.Write(must.NotFail(json.Marshal(k)))
+			buf.WriteByte(':')
+
+			if err := writeExtJSON(buf, must.NotFail(v.Get(k))); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte('}')
+
+	case *types.Array:
+		buf.WriteByte('[')
+
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			if err := writeExtJSON(buf, must.NotFail(v.Get(i))); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte(']')
+
+	case float64:
+		var s string
+
+		switch {
+		case math.IsNaN(v):
+			s = "NaN"
+		case math.IsInf(v, 1):
+			s = "Infinity"
+		case math.IsInf(v, -1):
+			s = "-Infinity"
+		default:
+			s = strconv.FormatFloat(v, 'g', -1, 64)
+		}
+
+		fmt.Fprintf(buf, `{"$numberDouble":%q}`, s)
+
+	case string:
+		buf.Write(must.NotFail(json.Marshal(v)))
+
+	case types.Binary:
+		fmt.Fprintf(buf, `{"$binary":{"base64":%q,"subType":"%02x"}}`, base64.StdEncoding.EncodeToString(v.B), byte(v.Subtype))
+
+	case types.ObjectID:
+		fmt.Fprintf(buf, `{"$oid":"%x"}`, v[:])
+
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+
+	case time.Time:
+		fmt.Fprintf(buf, `{"$date":{"$numberLong":"%d"}}`, v.UnixMilli())
+
+	case types.NullType:
+		buf.WriteString("null")
+
+	case types.Regex:
+		pattern := must.NotFail(json.Marshal(v.Pattern))
+		options := must.NotFail(json.Marshal(v.Options))
+		fmt.Fprintf(buf, `{"$regularExpression":{"pattern":%s,"options":%s}}`, pattern, options)
+
+	case int32:
+		fmt.Fprintf(buf, `{"$numberInt":"%d"}`, v)
+
+	case types.Timestamp:
+		fmt.Fprintf(buf, `{"$timestamp":{"t":%d,"i":%d}}`, uint64(v)>>32, uint32(v))
+
+	case int64:
+		fmt.Fprintf(buf, `{"$numberLong":"%d"}`, v)
+
+	default:
+		return lazyerrors.Errorf("unexpected type %T", v)
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// writeTestRecords writes OP_MSG messages with given documents to a record file in a temporary directory.
+func writeTestRecords(t *testing.T, docs ...*types.Document) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	f, err := os.Create(filepath.Join(dir, "test.bin"))
+	require.NoError(t, err)
+
+	w := bufio.NewWriter(f)
+
+	for i, doc := range docs {
+		var msg wire.OpMsg
+		require.NoError(t, msg.SetSections(wire.OpMsgSection{Documents: []*types.Document{doc}}))
+
+		b, err := msg.MarshalBinary()
+		require.NoError(t, err)
+
+		header := &wire.MsgHeader{
+			MessageLength: int32(wire.MsgHeaderLen + len(b)),
+			RequestID:     int32(i + 1),
+			OpCode:        wire.OpCodeMsg,
+		}
+		require.NoError(t, wire.WriteMessage(w, header, &msg))
+	}
+
+	require.NoError(t, w.Flush())
+	require.NoError(t, f.Close())
+
+	return dir
+}
+
+func TestInspect(t *testing.T) {
+	t.Parallel()
+
+	dir := writeTestRecords(
+		t,
+		must.NotFail(types.NewDocument("hello", int32(1), "$db", "admin")),
+		must.NotFail(types.NewDocument(
+			"insert", "values",
+			"documents", must.NotFail(types.NewArray(must.NotFail(types.NewDocument("_id", int64(1), "v", 42.5)))),
+			"lsid", must.NotFail(types.NewDocument("id", types.Binary{Subtype: types.BinaryUUID, B: make([]byte, 16)})),
+			"$db", "test",
+		)),
+		must.NotFail(types.NewDocument("find", "other", "filter", must.NotFail(types.NewDocument()), "$db", "test")),
+	)
+
+	for name, tc := range map[string]struct {
+		params   *inspectParams
+		expected string
+	}{
+		"JSON": {
+			params: &inspectParams{Format: inspectFormatJSON},
+			expected: `{"requestId":{"$numberInt":"1"},"opCode":"OP_MSG","db":"admin",` +
+				`"command":{"hello":{"$numberInt":"1"},"$db":"admin"}}` + "\n" +
+				`{"requestId":{"$numberInt":"2"},"opCode":"OP_MSG","db":"test",` +
+				`"command":{"insert":"values","documents":[{"_id":{"$numberLong":"1"},"v":{"$numberDouble":"42.5"}}],` +
+				`"lsid":{"id":{"$binary":{"base64":"AAAAAAAAAAAAAAAAAAAAAA==","subType":"04"}}},"$db":"test"}}` + "\n" +
+				`{"requestId":{"$numberInt":"3"},"opCode":"OP_MSG","db":"test",` +
+				`"command":{"find":"other","filter":{},"$db":"test"}}` + "\n",
+		},
+		"Filter": {
+			params: &inspectParams{Format: inspectFormatText, Commands: []string{"insert", "find"}, Namespaces: []string{"test.values"}},
+			expected: "# 1: OP_MSG request_id=2 command=insert ns=test.values\n" +
+				"{\n" +
+				"  \"insert\": \"values\",\n" +
+				"  \"documents\": [\n" +
+				"    {\n" +
+				"      \"_id\": {\n" +
+				"        \"$numberLong\": \"1\"\n" +
+				"      },\n" +
+				"      \"v\": {\n" +
+				"        \"$numberDouble\": \"42.5\"\n" +
+				"      }\n" +
+				"    }\n" +
+				"  ],\n" +
+				"  \"lsid\": {\n" +
+				"    \"id\": {\n" +
+				"      \"$binary\": {\n" +
+				"        \"base64\": \"AAAAAAAAAAAAAAAAAAAAAA==\",\n" +
+				"        \"subType\": \"04\"\n" +
+				"      }\n" +
+				"    }\n" +
+				"  },\n" +
+				"  \"$db\": \"test\"\n" +
+				"}\n\n",
+		},
+		"Script": {
+			params: &inspectParams{Format: inspectFormatScript},
+			expected: "// Reproduction script generated by `ferretdb inspect`; run it with mongosh.\n" +
+				`db.getSiblingDB("test").runCommand(EJSON.parse("{\"insert\":\"values\",\"documents\":` +
+				`[{\"_id\":{\"$numberLong\":\"1\"},\"v\":{\"$numberDouble\":\"42.5\"}}]}"));` + "\n" +
+				`db.getSiblingDB("test").runCommand(EJSON.parse("{\"find\":\"other\",\"filter\":{}}"));` + "\n",
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tc.params.Paths = []string{dir}
+
+			var actual strings.Builder
+			require.NoError(t, inspect(&actual, tc.params))
+			assert.Equal(t, tc.expected, actual.String())
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -36,12 +37,16 @@ import (
 
 	"github.com/FerretDB/FerretDB/build/version"
 	"github.com/FerretDB/FerretDB/internal/clientconn"
+	"github.com/FerretDB/FerretDB/internal/clientconn/clustertime"
 	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
+	"github.com/FerretDB/FerretDB/internal/clientconn/failpoints"
+	"github.com/FerretDB/FerretDB/internal/handlers/dropprotection"
 	"github.com/FerretDB/FerretDB/internal/handlers/registry"
 	"github.com/FerretDB/FerretDB/internal/util/debug"
 	"github.com/FerretDB/FerretDB/internal/util/debugbuild"
 	"github.com/FerretDB/FerretDB/internal/util/logging"
 	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/sqllog"
 	"github.com/FerretDB/FerretDB/internal/util/state"
 	"github.com/FerretDB/FerretDB/internal/util/telemetry"
 )
@@ -58,17 +63,52 @@ var cli struct {
 	Mode     string `default:"${default_mode}" help:"${help_mode}" enum:"${enum_mode}"`
 	StateDir string `default:"."               help:"Process state directory."`
 
+	SizeCacheMaxAge time.Duration `default:"0s"    help:"Maximum age of cached database sizes; 0 disables caching."`
+	QueryCacheSize  int           `default:"0"     help:"Maximum number of cached query results; 0 disables caching."`
+	TrashRetention  time.Duration `default:"0s"    help:"How long dropped collections are kept in the trash; 0 disables the trash."`
+	ArchiveInterval time.Duration `default:"1h"    help:"How often collection archiving policies are applied; 0 disables archiving."`
+	AnalyzeInterval time.Duration `default:"0s"    help:"How often database statistics are refreshed (ANALYZE); 0 disables it."`
+	CollectionStats bool          `default:"false" help:"Track per-collection latency and document size histograms."`
+
+	MaterializedViewsInterval time.Duration `default:"1m" help:"How often materialized views are checked for periodic refresh; 0 disables it."`
+
+	DiagnosticsDir      string        `default:""   help:"Directory for diagnostic data (FTDC) snapshots; empty disables them."`
+	DiagnosticsInterval time.Duration `default:"1s" help:"How often diagnostic data snapshots are written."`
+
+	ImportDir string `default:"" help:"Directory with files for the import command; empty disables importing from files."`
+	ExportDir string `default:"" help:"Directory for Parquet files written by the export command; empty disables it."`
+	InitDir   string `default:"" help:"Directory with seed files and index definitions applied on the first start; empty disables it."`
+
+	ImportURLHosts []string `default:"" help:"Hosts allowed for the import command from HTTP(S) URLs, comma-separated; empty disables importing from URLs."`
+
+	SQLStage bool `default:"false" help:"Allow FerretDB-specific $sql aggregation stage with raw SQL queries."`
+
+	AdminUsers []string `default:"" help:"Users allowed to import from URLs and use $sql stage, comma-separated."`
+
 	Listen struct {
-		Addr        string `default:"127.0.0.1:27017" help:"Listen TCP address."`
-		Unix        string `default:""                help:"Listen Unix domain socket path."`
-		TLS         string `default:""                help:"Listen TLS address."`
-		TLSCertFile string `default:""                help:"TLS cert file path."`
-		TLSKeyFile  string `default:""                help:"TLS key file path."`
-		TLSCAFile   string `default:""                help:"TLS CA file path." name:"tls-ca-file"`
+		Addr                  []string `default:"127.0.0.1:27017" help:"Listen TCP addresses, comma-separated."`
+		Unix                  []string `default:""                help:"Listen Unix domain socket paths, comma-separated."`
+		TLS                   []string `default:""                help:"Listen TLS addresses, comma-separated."`
+		TLSCertFile           string   `default:""                help:"TLS cert file path."`
+		TLSKeyFile            string   `default:""                help:"TLS key file path."`
+		TLSCAFile             string   `default:""                help:"TLS CA file path." name:"tls-ca-file"`
+		TLSClientCertOptional bool     `default:"false"           help:"Do not require client certificates; verify them only if presented."`
+		Systemd               bool     `default:"false"           help:"Also listen on sockets passed by systemd socket activation."`
+		ProxyProtocol         bool     `default:"false"           help:"Require PROXY protocol v1/v2 header on TCP and TLS connections."`
+
+		ProxyProtocolTrustedCIDRs []string `default:"" help:"Accept PROXY protocol connections only from those networks, comma-separated." name:"proxy-protocol-trusted-cidrs"` //nolint:lll // for readability
 	} `embed:"" prefix:"listen-"`
 
-	ProxyAddr string `default:""                help:"Proxy address."`
-	DebugAddr string `default:"127.0.0.1:8088"  help:"Listen address for HTTP handlers for metrics, pprof, etc."`
+	ProxyAddr string `default:"" help:"Proxy address."`
+
+	Debug struct {
+		Addr        string `default:"127.0.0.1:8088" help:"Listen address for HTTP handlers for metrics, pprof, etc."`
+		TLSCertFile string `default:""               help:"Debug handler TLS cert file path; enables HTTPS."`
+		TLSKeyFile  string `default:""               help:"Debug handler TLS key file path."`
+		Username    string `default:""               help:"Debug handler basic authentication username."`
+		Password    string `default:""               help:"Debug handler basic authentication password."`
+		Token       string `default:""               help:"Debug handler bearer authentication token."`
+	} `embed:"" prefix:"debug-"`
 
 	// see setCLIPlugins
 	kong.Plugins
@@ -77,18 +117,26 @@ var cli struct {
 		Level  string `default:"${default_log_level}" help:"${help_log_level}"`
 		Format string `default:"console"              help:"${help_log_format}"                     enum:"${enum_log_format}"`
 		UUID   bool   `default:"false"                help:"Add instance UUID to all log messages." negatable:""`
+		SQL    string `default:"${default_log_sql}"   help:"${help_log_sql}"                        enum:"${enum_log_sql}"`
 	} `embed:"" prefix:"log-"`
 
 	MetricsUUID bool `default:"false" help:"Add instance UUID to all metrics." negatable:""`
 
 	Telemetry telemetry.Flag `default:"undecided" help:"Enable or disable basic telemetry. See https://beacon.ferretdb.io."`
 
+	DropProtection struct {
+		Mode       string   `default:"off" help:"${help_drop_protection_mode}" enum:"${enum_drop_protection_mode}"`
+		Namespaces []string `default:""    help:"Databases or collections (db.collection) protected from dropping; '*' for all."`
+	} `embed:"" prefix:"drop-protection-"`
+
 	Test struct {
 		RecordsDir string `default:"" help:"Testing: directory for record files."`
 
 		DisableFilterPushdown    bool `default:"false" help:"Experimental: disable filter pushdown."`
 		EnableUnsafeSortPushdown bool `default:"false" help:"Experimental: enable unsafe sort pushdown."`
 		EnableOplog              bool `default:"false" help:"Experimental: enable capped collections, tailable cursors and OpLog." hidden:""`
+		EnableFailPoints         bool `default:"false" help:"Experimental: enable configureFailPoint command for testing." hidden:""`
+		EnableClusterTime        bool `default:"false" help:"Experimental: add $clusterTime and operationTime to responses for causal consistency."`
 
 		//nolint:lll // for readability
 		Telemetry struct {
@@ -99,6 +147,15 @@ var cli struct {
 			Package        string        `default:""                            help:"Telemetry: custom package type."`
 		} `embed:"" prefix:"telemetry-"`
 	} `embed:"" prefix:"test-"`
+
+	Run struct{} `cmd:"" default:"withargs" help:"Run FerretDB (default)."`
+
+	Inspect struct {
+		Paths     []string `arg:""         help:"Record files or directories written with --test-records-dir."               type:"path"`
+		Format    string   `default:"text" help:"${help_inspect_format}"                                                     enum:"${enum_inspect_format}" env:"-"`
+		Command   []string `default:""     help:"Only show those commands, comma-separated."                                 env:"-"`
+		Namespace []string `default:""     help:"Only show those databases or collections (db.collection), comma-separated." env:"-"`
+	} `cmd:"" help:"Pretty-print recorded wire traffic, convert it to Extended JSON, or produce a reproduction script."`
 }
 
 // The postgreSQLFlags struct represents flags that are used by the "postgresql" backend.
@@ -108,6 +165,13 @@ var cli struct {
 //nolint:lll // some tags are long
 var postgreSQLFlags struct {
 	PostgreSQLURL string `name:"postgresql-url" default:"postgres://127.0.0.1:5432/ferretdb" help:"PostgreSQL URL for 'postgresql' handler."`
+
+	PostgreSQLRelationalViews bool `name:"postgresql-relational-views" default:"false" help:"Maintain relational views over collections for SQL access."`
+
+	PostgreSQLStatementTimeout time.Duration `name:"postgresql-statement-timeout" default:"0s" help:"Default PostgreSQL statement_timeout; 0 keeps the server's setting."`
+	PostgreSQLLockTimeout      time.Duration `name:"postgresql-lock-timeout"      default:"0s" help:"Default PostgreSQL lock_timeout; 0 keeps the server's setting."`
+
+	PostgreSQLTenantIsolation bool `name:"postgresql-tenant-isolation" default:"false" help:"Isolate documents of each user in new collections with row-level security."`
 }
 
 // The sqliteFlags struct represents flags that are used by the "sqlite" backend.
@@ -159,15 +223,22 @@ var (
 	kongOptions = []kong.Option{
 		kong.Vars{
 			"default_log_level": defaultLogLevel().String(),
+			"default_log_sql":   sqllog.AllModes[0],
 			"default_mode":      clientconn.AllModes[0],
 
-			"enum_log_format": strings.Join(logFormats, ","),
-			"enum_mode":       strings.Join(clientconn.AllModes, ","),
-
-			"help_handler":    fmt.Sprintf("Backend handler: '%s'.", strings.Join(registry.Handlers(), "', '")),
-			"help_log_format": fmt.Sprintf("Log format: '%s'.", strings.Join(logFormats, "', '")),
-			"help_log_level":  fmt.Sprintf("Log level: '%s'.", strings.Join(logLevels, "', '")),
-			"help_mode":       fmt.Sprintf("Operation mode: '%s'.", strings.Join(clientconn.AllModes, "', '")),
+			"enum_drop_protection_mode": strings.Join(dropprotection.Modes, ","),
+			"enum_inspect_format":       strings.Join(inspectFormats, ","),
+			"enum_log_format":           strings.Join(logFormats, ","),
+			"enum_log_sql":              strings.Join(sqllog.AllModes, ","),
+			"enum_mode":                 strings.Join(clientconn.AllModes, ","),
+
+			"help_drop_protection_mode": fmt.Sprintf("Drop protection mode: '%s'.", strings.Join(dropprotection.Modes, "', '")),
+			"help_handler":              fmt.Sprintf("Backend handler: '%s'.", strings.Join(registry.Handlers(), "', '")),
+			"help_inspect_format":       fmt.Sprintf("Output format: '%s'.", strings.Join(inspectFormats, "', '")),
+			"help_log_format":           fmt.Sprintf("Log format: '%s'.", strings.Join(logFormats, "', '")),
+			"help_log_level":            fmt.Sprintf("Log level: '%s'.", strings.Join(logLevels, "', '")),
+			"help_log_sql":              fmt.Sprintf("Log SQL statements of each request with bind parameters: '%s'.", strings.Join(sqllog.AllModes, "', '")),
+			"help_mode":                 fmt.Sprintf("Operation mode: '%s'.", strings.Join(clientconn.AllModes, "', '")),
 		},
 		kong.DefaultEnvars("FERRETDB"),
 	}
@@ -175,9 +246,14 @@ var (
 
 func main() {
 	setCLIPlugins()
-	kong.Parse(&cli, kongOptions...)
+	ctx := kong.Parse(&cli, kongOptions...)
 
-	run()
+	switch ctx.Command() {
+	case "inspect <paths>":
+		runInspect()
+	default:
+		run()
+	}
 }
 
 // defaultLogLevel returns the default log level.
@@ -321,6 +397,10 @@ func run() {
 		logger.Sugar().Warnf("Failed to set GOMAXPROCS: %s.", err)
 	}
 
+	if (cli.Debug.TLSCertFile == "") != (cli.Debug.TLSKeyFile == "") {
+		logger.Sugar().Fatal("Both --debug-tls-cert-file and --debug-tls-key-file should be set.")
+	}
+
 	ctx, stop := notifyAppTermination(context.Background())
 
 	go func() {
@@ -335,11 +415,35 @@ func run() {
 
 	go func() {
 		defer wg.Done()
-		debug.RunHandler(ctx, cli.DebugAddr, metricsRegisterer, logger.Named("debug"))
+		debug.RunHandler(ctx, &debug.RunHandlerOpts{
+			Addr:        cli.Debug.Addr,
+			R:           metricsRegisterer,
+			L:           logger.Named("debug"),
+			TLSCertFile: cli.Debug.TLSCertFile,
+			TLSKeyFile:  cli.Debug.TLSKeyFile,
+			Username:    cli.Debug.Username,
+			Password:    cli.Debug.Password,
+			Token:       cli.Debug.Token,
+		})
 	}()
 
 	metrics := connmetrics.NewListenerMetrics()
 
+	var failPoints *failpoints.Registry
+	if cli.Test.EnableFailPoints {
+		failPoints = failpoints.NewRegistry()
+	}
+
+	var clusterTime *clustertime.Clock
+	if cli.Test.EnableClusterTime {
+		clusterTime = clustertime.NewClock(nil)
+	}
+
+	dropProtection, err := dropprotection.New(dropprotection.Mode(cli.DropProtection.Mode), cli.DropProtection.Namespaces)
+	if err != nil {
+		logger.Sugar().Fatalf("Failed to configure drop protection: %s.", err)
+	}
+
 	wg.Add(1)
 
 	go func() {
@@ -365,8 +469,36 @@ func run() {
 		Logger:        logger,
 		ConnMetrics:   metrics.ConnMetrics,
 		StateProvider: stateProvider,
+		FailPoints:    failPoints,
+
+		SizeCacheMaxAge: cli.SizeCacheMaxAge,
+		QueryCacheSize:  cli.QueryCacheSize,
+		DropProtection:  dropProtection,
+		TrashRetention:  cli.TrashRetention,
+		ArchiveInterval: cli.ArchiveInterval,
+		AnalyzeInterval: cli.AnalyzeInterval,
+		CollectionStats: cli.CollectionStats,
+
+		MaterializedViewsInterval: cli.MaterializedViewsInterval,
+
+		DiagnosticsDir:      cli.DiagnosticsDir,
+		DiagnosticsInterval: cli.DiagnosticsInterval,
+
+		ImportDir: cli.ImportDir,
+		ExportDir: cli.ExportDir,
+		InitDir:   cli.InitDir,
+
+		ImportURLHosts: cli.ImportURLHosts,
+
+		SQLStage: cli.SQLStage,
 
-		PostgreSQLURL: postgreSQLFlags.PostgreSQLURL,
+		AdminUsers: cli.AdminUsers,
+
+		PostgreSQLURL:              postgreSQLFlags.PostgreSQLURL,
+		PostgreSQLRelationalViews:  postgreSQLFlags.PostgreSQLRelationalViews,
+		PostgreSQLStatementTimeout: postgreSQLFlags.PostgreSQLStatementTimeout,
+		PostgreSQLLockTimeout:      postgreSQLFlags.PostgreSQLLockTimeout,
+		PostgreSQLTenantIsolation:  postgreSQLFlags.PostgreSQLTenantIsolation,
 
 		SQLiteURL: sqliteFlags.SQLiteURL,
 
@@ -382,19 +514,43 @@ func run() {
 		logger.Sugar().Fatalf("Failed to construct handler: %s.", err)
 	}
 
+	var proxyProtocolTrustedNetworks []*net.IPNet
+
+	for _, cidr := range cli.Listen.ProxyProtocolTrustedCIDRs {
+		if cidr == "" {
+			continue
+		}
+
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			logger.Sugar().Fatalf("Failed to parse PROXY protocol trusted network: %s.", err)
+		}
+
+		proxyProtocolTrustedNetworks = append(proxyProtocolTrustedNetworks, n)
+	}
+
 	l := clientconn.NewListener(&clientconn.NewListenerOpts{
-		TCP:         cli.Listen.Addr,
-		Unix:        cli.Listen.Unix,
-		TLS:         cli.Listen.TLS,
-		TLSCertFile: cli.Listen.TLSCertFile,
-		TLSKeyFile:  cli.Listen.TLSKeyFile,
-		TLSCAFile:   cli.Listen.TLSCAFile,
+		TCP:           cli.Listen.Addr,
+		Unix:          cli.Listen.Unix,
+		TLS:           cli.Listen.TLS,
+		TLSCertFile:   cli.Listen.TLSCertFile,
+		TLSKeyFile:    cli.Listen.TLSKeyFile,
+		TLSCAFile:     cli.Listen.TLSCAFile,
+		Systemd:       cli.Listen.Systemd,
+		ProxyProtocol: cli.Listen.ProxyProtocol,
+
+		TLSClientCertOptional: cli.Listen.TLSClientCertOptional,
+
+		ProxyProtocolTrustedNetworks: proxyProtocolTrustedNetworks,
 
 		ProxyAddr:      cli.ProxyAddr,
 		Mode:           clientconn.Mode(cli.Mode),
 		Metrics:        metrics,
+		FailPoints:     failPoints,
+		ClusterTime:    clusterTime,
 		Handler:        h,
 		Logger:         logger,
+		SQLLog:         sqllog.Mode(cli.Log.SQL),
 		TestRecordsDir: cli.Test.RecordsDir,
 	})
 
@@ -51,6 +51,12 @@ type Config struct {
 	// SQLite URI (directory) for `sqlite` handler.
 	// See https://www.sqlite.org/uri.html.
 	SQLiteURL string // For example: `file:data/`.
+
+	// Directory with seed files and index definitions that are applied
+	// if there are no databases yet, like /docker-entrypoint-initdb.d for mongod images.
+	// See the `--init-dir` flag documentation for the file format.
+	// If empty, nothing is applied.
+	InitDir string
 }
 
 // ListenerConfig represents listener configuration.
@@ -74,7 +80,11 @@ type ListenerConfig struct {
 	TLSKeyFile string
 
 	// Root CA certificate path.
+	// If set, client certificates are verified.
 	TLSCAFile string
+
+	// If true, client certificates are not required, but verified if presented.
+	TLSClientCertOptional bool
 }
 
 // FerretDB represents an instance of embeddable FerretDB implementation.
@@ -119,19 +129,23 @@ func New(config *Config) (*FerretDB, error) {
 		PostgreSQLURL: config.PostgreSQLURL,
 
 		SQLiteURL: config.SQLiteURL,
+
+		InitDir: config.InitDir,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to construct handler: %s", err)
 	}
 
 	l := clientconn.NewListener(&clientconn.NewListenerOpts{
-		TCP:         config.Listener.TCP,
-		Unix:        config.Listener.Unix,
-		TLS:         config.Listener.TLS,
+		TCP:         []string{config.Listener.TCP},
+		Unix:        []string{config.Listener.Unix},
+		TLS:         []string{config.Listener.TLS},
 		TLSCertFile: config.Listener.TLSCertFile,
 		TLSKeyFile:  config.Listener.TLSKeyFile,
 		TLSCAFile:   config.Listener.TLSCAFile,
 
+		TLSClientCertOptional: config.Listener.TLSClientCertOptional,
+
 		Mode:    clientconn.NormalMode,
 		Metrics: metrics,
 		Handler: h,
@@ -7,10 +7,12 @@ require (
 	github.com/SAP/go-hdb v1.5.9
 	github.com/alecthomas/kong v0.8.1
 	github.com/arl/statsviz v0.6.0
+	github.com/golang/snappy v0.0.1
 	github.com/google/uuid v1.4.0
 	github.com/jackc/pgerrcode v0.0.0-20220416144525-469b46aa5efa
 	github.com/jackc/pgx-zap v0.0.0-20221202020421-94b1cb2f889f
 	github.com/jackc/pgx/v5 v5.5.0
+	github.com/klauspost/compress v1.13.6
 	github.com/pmezard/go-difflib v1.0.0
 	github.com/prometheus/client_golang v1.17.0
 	github.com/prometheus/client_model v0.5.0
@@ -0,0 +1,413 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+)
+
+// Stages in this file read from or write to other collections,
+// so their tests can't use testAggregateStagesCompat.
+
+func TestAggregateCompatLookup(t *testing.T) {
+	t.Parallel()
+
+	orders := shareddata.NewTopLevelFieldsProvider(
+		"Orders",
+		nil,
+		map[int32]shareddata.Fields{
+			1: {{Key: "item", Value: "a"}, {Key: "tags", Value: bson.A{"x", "y"}}},
+			2: {{Key: "item", Value: "b"}},
+			3: {},
+		},
+	)
+
+	s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+		Providers: []shareddata.Provider{orders},
+	})
+	ctx, targetCollection, compatCollection := s.Ctx, s.TargetCollections[0], s.CompatCollections[0]
+
+	// inventory documents are inserted in a fixed order because lookup results
+	// without a pipeline are returned in the natural order
+	from := targetCollection.Name() + "_inventory"
+	inventory := []any{
+		bson.D{{"_id", int32(10)}, {"sku", "a"}, {"qty", int32(5)}, {"tag", "y"}},
+		bson.D{{"_id", int32(11)}, {"sku", "a"}, {"qty", int32(1)}},
+		bson.D{{"_id", int32(12)}, {"sku", "c"}, {"qty", int32(7)}},
+		bson.D{{"_id", int32(13)}, {"qty", int32(0)}},
+	}
+
+	for _, c := range []*mongo.Collection{targetCollection, compatCollection} {
+		_, err := c.Database().Collection(from).InsertMany(ctx, inventory)
+		require.NoError(t, err)
+	}
+
+	testCases := map[string]struct {
+		lookup     any                      // required
+		facet      bool                     // run $lookup inside $facet
+		resultType compatTestCaseResultType // defaults to nonEmptyResult
+
+		skip string // skip test for all handlers, must have issue number mentioned
+	}{
+		"Fields": {
+			lookup: bson.D{{"from", from}, {"localField", "item"}, {"foreignField", "sku"}, {"as", "stock"}},
+		},
+		"ArrayLocalField": {
+			lookup: bson.D{{"from", from}, {"localField", "tags"}, {"foreignField", "tag"}, {"as", "stock"}},
+		},
+		"Pipeline": {
+			lookup: bson.D{
+				{"from", from},
+				{"pipeline", bson.A{
+					bson.D{{"$match", bson.D{{"qty", bson.D{{"$gt", int32(4)}}}}}},
+					bson.D{{"$sort", bson.D{{"_id", 1}}}},
+					bson.D{{"$project", bson.D{{"_id", false}, {"sku", true}}}},
+				}},
+				{"as", "stock"},
+			},
+		},
+		"FieldsAndPipeline": {
+			lookup: bson.D{
+				{"from", from},
+				{"localField", "item"},
+				{"foreignField", "sku"},
+				{"pipeline", bson.A{bson.D{{"$count", "n"}}}},
+				{"as", "stock.count"},
+			},
+		},
+		"NonExistentCollection": {
+			lookup: bson.D{{"from", "non-existent"}, {"localField", "item"}, {"foreignField", "sku"}, {"as", "stock"}},
+		},
+		"InFacet": {
+			facet:  true,
+			lookup: bson.D{{"from", from}, {"localField", "item"}, {"foreignField", "sku"}, {"as", "stock"}},
+		},
+		"NotDocument": {
+			lookup:     from,
+			resultType: emptyResult,
+		},
+		"MissingAs": {
+			lookup:     bson.D{{"from", from}, {"localField", "item"}, {"foreignField", "sku"}},
+			resultType: emptyResult,
+		},
+		"MissingForeignField": {
+			lookup:     bson.D{{"from", from}, {"localField", "item"}, {"as", "stock"}},
+			resultType: emptyResult,
+		},
+		"UnknownArgument": {
+			lookup:     bson.D{{"from", from}, {"foo", int32(1)}},
+			resultType: emptyResult,
+		},
+		"FromType": {
+			lookup:     bson.D{{"from", int32(1)}, {"pipeline", bson.A{}}, {"as", "stock"}},
+			resultType: emptyResult,
+		},
+		"CollStatsInPipeline": {
+			lookup:     bson.D{{"from", from}, {"pipeline", bson.A{bson.D{{"$collStats", bson.D{}}}}}, {"as", "stock"}},
+			resultType: emptyResult,
+		},
+		"OutInPipeline": {
+			lookup:     bson.D{{"from", from}, {"pipeline", bson.A{bson.D{{"$out", "out"}}}}, {"as", "stock"}},
+			resultType: emptyResult,
+		},
+		"Let": {
+			lookup:     bson.D{{"from", from}, {"let", bson.D{{"v", "$item"}}}, {"pipeline", bson.A{}}, {"as", "stock"}},
+			resultType: emptyResult,
+			skip:       "https://github.com/FerretDB/FerretDB/issues/2275",
+		},
+	}
+
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+
+			if tc.skip != "" {
+				t.Skip(tc.skip)
+			}
+
+			t.Parallel()
+
+			pipeline := bson.A{
+				bson.D{{"$lookup", tc.lookup}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			}
+
+			if tc.facet {
+				pipeline = bson.A{bson.D{{"$facet", bson.D{{"joined", pipeline}}}}}
+			}
+
+			targetCursor, targetErr := targetCollection.Aggregate(ctx, pipeline)
+			compatCursor, compatErr := compatCollection.Aggregate(ctx, pipeline)
+
+			if targetErr != nil {
+				t.Logf("Target error: %v", targetErr)
+				t.Logf("Compat error: %v", compatErr)
+
+				// error messages are intentionally not compared
+				AssertMatchesCommandError(t, compatErr, targetErr)
+
+				require.Equal(t, emptyResult, tc.resultType, "unexpected error")
+
+				return
+			}
+			require.NoError(t, compatErr, "compat error; target returned no error")
+
+			targetRes := FetchAll(t, ctx, targetCursor)
+			compatRes := FetchAll(t, ctx, compatCursor)
+
+			AssertEqualDocumentsSlice(t, compatRes, targetRes)
+			require.NotEmpty(t, targetRes)
+		})
+	}
+}
+
+func TestAggregateCompatOutMerge(t *testing.T) {
+	t.Parallel()
+
+	source := shareddata.NewTopLevelFieldsProvider(
+		"Source",
+		nil,
+		map[int32]shareddata.Fields{
+			1: {{Key: "v", Value: "a"}, {Key: "n", Value: int32(1)}},
+			2: {{Key: "v", Value: "b"}, {Key: "n", Value: int32(2)}},
+			3: {{Key: "v", Value: "a"}, {Key: "n", Value: int32(3)}},
+		},
+	)
+
+	s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+		Providers: []shareddata.Provider{source},
+	})
+	ctx, targetCollection, compatCollection := s.Ctx, s.TargetCollections[0], s.CompatCollections[0]
+
+	testCases := map[string]struct {
+		existing []any                                // documents inserted into the output collection first
+		pipeline func(dbName, collName string) bson.A // required, dbName and collName are the output collection
+		dbSuffix string                               // suffix for the output database name
+		index    bson.D                               // unique index created on the output collection first
+
+		skipIDCheck bool // generated _id values are not compared
+		errExpected bool // both target and compat are expected to fail
+	}{
+		"Out": {
+			existing: []any{bson.D{{"_id", "old"}}},
+			pipeline: func(_, collName string) bson.A {
+				return bson.A{
+					bson.D{{"$group", bson.D{{"_id", "$v"}, {"total", bson.D{{"$sum", "$n"}}}}}},
+					bson.D{{"$out", collName}},
+				}
+			},
+		},
+		"OutOtherDatabase": {
+			pipeline: func(dbName, collName string) bson.A {
+				return bson.A{
+					bson.D{{"$match", bson.D{{"v", "b"}}}},
+					bson.D{{"$project", bson.D{{"_id", int32(0)}, {"v", int32(1)}}}},
+					bson.D{{"$out", bson.D{{"db", dbName}, {"coll", collName}}}},
+				}
+			},
+			dbSuffix:    "_other",
+			skipIDCheck: true,
+		},
+		"OutDuplicate": {
+			existing: []any{bson.D{{"_id", "old"}}},
+			pipeline: func(_, collName string) bson.A {
+				return bson.A{
+					bson.D{{"$project", bson.D{{"_id", "$v"}}}},
+					bson.D{{"$out", collName}},
+				}
+			},
+			errExpected: true,
+		},
+		"MergeDefaults": {
+			existing: []any{
+				bson.D{{"_id", int32(1)}, {"v", "old"}, {"extra", true}},
+				bson.D{{"_id", int32(4)}, {"v", "untouched"}},
+			},
+			pipeline: func(_, collName string) bson.A {
+				return bson.A{bson.D{{"$merge", collName}}}
+			},
+		},
+		"MergeReplaceDiscard": {
+			existing: []any{bson.D{{"_id", int32(1)}, {"v", "old"}, {"extra", true}}},
+			pipeline: func(_, collName string) bson.A {
+				return bson.A{bson.D{{"$merge", bson.D{
+					{"into", collName},
+					{"whenMatched", "replace"},
+					{"whenNotMatched", "discard"},
+				}}}}
+			},
+		},
+		"MergeOn": {
+			existing: []any{bson.D{{"_id", "x"}, {"key", "a"}, {"count", int32(0)}}},
+			index:    bson.D{{"key", 1}},
+			pipeline: func(dbName, collName string) bson.A {
+				return bson.A{
+					bson.D{{"$group", bson.D{{"_id", "$v"}, {"count", bson.D{{"$sum", int32(1)}}}}}},
+					bson.D{{"$project", bson.D{{"_id", int32(0)}, {"key", "$_id"}, {"count", int32(1)}}}},
+					bson.D{{"$merge", bson.D{
+						{"into", bson.D{{"db", dbName}, {"coll", collName}}},
+						{"on", "key"},
+						{"whenMatched", "merge"},
+					}}},
+				}
+			},
+			skipIDCheck: true,
+		},
+		"OutNotLast": {
+			pipeline: func(_, collName string) bson.A {
+				return bson.A{bson.D{{"$out", collName}}, bson.D{{"$match", bson.D{}}}}
+			},
+			errExpected: true,
+		},
+		"OutWrongType": {
+			pipeline: func(_, _ string) bson.A {
+				return bson.A{bson.D{{"$out", int32(1)}}}
+			},
+			errExpected: true,
+		},
+		"OutMissingDB": {
+			pipeline: func(_, collName string) bson.A {
+				return bson.A{bson.D{{"$out", bson.D{{"coll", collName}}}}}
+			},
+			errExpected: true,
+		},
+		"OutSystem": {
+			pipeline: func(_, _ string) bson.A {
+				return bson.A{bson.D{{"$out", "system.views"}}}
+			},
+			errExpected: true,
+		},
+		"MergeNotLast": {
+			pipeline: func(_, collName string) bson.A {
+				return bson.A{bson.D{{"$merge", collName}}, bson.D{{"$match", bson.D{}}}}
+			},
+			errExpected: true,
+		},
+		"MergeMissingInto": {
+			pipeline: func(_, _ string) bson.A {
+				return bson.A{bson.D{{"$merge", bson.D{{"on", "_id"}}}}}
+			},
+			errExpected: true,
+		},
+		"MergeWhenMatched": {
+			pipeline: func(_, collName string) bson.A {
+				return bson.A{bson.D{{"$merge", bson.D{{"into", collName}, {"whenMatched", "foo"}}}}}
+			},
+			errExpected: true,
+		},
+		"MergeOnEmpty": {
+			pipeline: func(_, collName string) bson.A {
+				return bson.A{bson.D{{"$merge", bson.D{{"into", collName}, {"on", bson.A{}}}}}}
+			},
+			errExpected: true,
+		},
+		"MergeWhenNotMatchedFail": {
+			pipeline: func(_, collName string) bson.A {
+				return bson.A{bson.D{{"$merge", bson.D{{"into", collName}, {"whenNotMatched", "fail"}}}}}
+			},
+			errExpected: true,
+		},
+		"MergeWhenMatchedFail": {
+			existing: []any{bson.D{{"_id", int32(1)}}},
+			pipeline: func(_, collName string) bson.A {
+				return bson.A{bson.D{{"$merge", bson.D{{"into", collName}, {"whenMatched", "fail"}}}}}
+			},
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+
+			t.Parallel()
+
+			collName := targetCollection.Name() + "_" + name
+
+			outputs := make([]*mongo.Collection, 2)
+			for i, c := range []*mongo.Collection{targetCollection, compatCollection} {
+				db := c.Database()
+
+				if tc.dbSuffix != "" {
+					db = c.Database().Client().Database(db.Name() + tc.dbSuffix)
+					t.Cleanup(func() {
+						require.NoError(t, db.Drop(ctx))
+					})
+				}
+
+				outputs[i] = db.Collection(collName)
+
+				if tc.index != nil {
+					_, err := outputs[i].Indexes().CreateOne(ctx, mongo.IndexModel{
+						Keys:    tc.index,
+						Options: options.Index().SetUnique(true),
+					})
+					require.NoError(t, err)
+				}
+
+				if tc.existing != nil {
+					_, err := outputs[i].InsertMany(ctx, tc.existing)
+					require.NoError(t, err)
+				}
+			}
+
+			targetPipeline := tc.pipeline(outputs[0].Database().Name(), collName)
+			compatPipeline := tc.pipeline(outputs[1].Database().Name(), collName)
+
+			targetCursor, targetErr := targetCollection.Aggregate(ctx, targetPipeline)
+			compatCursor, compatErr := compatCollection.Aggregate(ctx, compatPipeline)
+
+			if tc.errExpected {
+				t.Logf("Target error: %v", targetErr)
+				t.Logf("Compat error: %v", compatErr)
+
+				// error messages are intentionally not compared
+				AssertMatchesCommandError(t, compatErr, targetErr)
+			} else {
+				require.NoError(t, targetErr)
+				require.NoError(t, compatErr)
+
+				require.Empty(t, FetchAll(t, ctx, targetCursor))
+				require.Empty(t, FetchAll(t, ctx, compatCursor))
+			}
+
+			// existing documents are kept on errors
+			var projection bson.D
+			if tc.skipIDCheck {
+				projection = bson.D{{"_id", false}}
+			}
+
+			opts := options.Find().SetSort(bson.D{{"_id", 1}}).SetProjection(projection)
+
+			targetRes, err := outputs[0].Find(ctx, bson.D{}, opts)
+			require.NoError(t, err)
+
+			compatRes, err := outputs[1].Find(ctx, bson.D{}, opts)
+			require.NoError(t, err)
+
+			AssertEqualDocumentsSlice(t, FetchAll(t, ctx, compatRes), FetchAll(t, ctx, targetRes))
+		})
+	}
+}
@@ -340,6 +340,16 @@ func TestAggregateCompatStages(t *testing.T) {
 				bson.D{{"$sort", bson.D{{"_id", 1}}}},
 			},
 		},
+		"MatchSortSkipLimitProject": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$ne", int32(0)}}}}}},
+				bson.D{{"$sort", bson.D{{"v", -1}, {"_id", 1}}}},
+				bson.D{{"$skip", int32(1)}},
+				bson.D{{"$limit", int32(3)}},
+				bson.D{{"$project", bson.D{{"_id", false}, {"v", true}, {"foo", "$v.foo"}}}},
+			},
+			resultPushdown: pgPushdown,
+		},
 	}
 
 	testAggregateStagesCompat(t, testCases)
@@ -393,6 +403,44 @@ func TestAggregateCompatCount(t *testing.T) {
 			pipeline:   bson.A{bson.D{{"$count", "$foo"}}},
 			resultType: emptyResult,
 		},
+		"AfterSkipLimit": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$skip", 1}},
+				bson.D{{"$limit", 2}},
+				bson.D{{"$count", "v"}},
+			},
+		},
+		"AfterMatchNoDocuments": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"_id", "count-id-not-exists"}}}},
+				bson.D{{"$count", "v"}},
+			},
+			resultType:     emptyResult,
+			resultPushdown: allPushdown,
+		},
+		"Twice": {
+			pipeline: bson.A{
+				bson.D{{"$count", "v"}},
+				bson.D{{"$count", "v"}},
+			},
+		},
+		"AfterMatch": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", int32(42)}}}},
+				bson.D{{"$count", "n"}},
+			},
+			resultPushdown: pgPushdown,
+		},
+		"CountDocuments": {
+			// the same pipeline is used by drivers' countDocuments helpers
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{}}},
+				bson.D{{"$skip", int64(1)}},
+				bson.D{{"$limit", int64(5)}},
+				bson.D{{"$group", bson.D{{"_id", int32(1)}, {"n", bson.D{{"$sum", int32(1)}}}}}},
+			},
+		},
 	}
 
 	testAggregateStagesCompat(t, testCases)
@@ -1242,6 +1290,344 @@ func TestAggregateCompatGroupSum(t *testing.T) {
 	testAggregateStagesCompatWithProviders(t, providers, testCases)
 }
 
+func TestAggregateCompatGroupAccumulators(t *testing.T) {
+	t.Parallel()
+
+	providers := []shareddata.Provider{
+		shareddata.Int32s,
+		shareddata.Int64s,
+		shareddata.Strings,
+		shareddata.Nulls,
+		shareddata.Bools,
+		shareddata.ObjectIDs,
+	}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"MinMax": {
+			pipeline: bson.A{
+				bson.D{{"$group", bson.D{
+					{"_id", nil},
+					{"min", bson.D{{"$min", "$v"}}},
+					{"max", bson.D{{"$max", "$v"}}},
+				}}},
+			},
+		},
+		"Avg": {
+			pipeline: bson.A{
+				bson.D{{"$group", bson.D{
+					{"_id", nil},
+					{"avg", bson.D{{"$avg", "$v"}}},
+				}}},
+			},
+		},
+		"Push": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$group", bson.D{
+					{"_id", nil},
+					{"push", bson.D{{"$push", "$v"}}},
+					{"pushExpression", bson.D{{"$push", bson.D{{"id", "$_id"}}}}},
+				}}},
+			},
+		},
+		"AddToSet": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$group", bson.D{
+					{"_id", "$v"},
+					{"set", bson.D{{"$addToSet", "$v"}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"_id", -1}}}},
+			},
+		},
+		"PushNonExistent": {
+			pipeline: bson.A{
+				bson.D{{"$group", bson.D{
+					{"_id", nil},
+					{"push", bson.D{{"$push", "$non-existent"}}},
+				}}},
+			},
+		},
+		"MinNotUnary": {
+			pipeline: bson.A{
+				bson.D{{"$group", bson.D{
+					{"_id", nil},
+					{"min", bson.D{{"$min", bson.A{"$v", "$_id"}}}},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, providers, testCases)
+}
+
+func TestAggregateCompatGroupFirstLast(t *testing.T) {
+	t.Parallel()
+
+	// groups contains documents with a missing value in the first group.
+	groups := shareddata.NewTopLevelFieldsProvider(
+		"Groups",
+		nil,
+		map[int32]shareddata.Fields{
+			1: {{Key: "g", Value: "a"}, {Key: "v", Value: int32(3)}},
+			2: {{Key: "g", Value: "a"}, {Key: "v", Value: int32(1)}},
+			3: {{Key: "g", Value: "a"}},
+			4: {{Key: "g", Value: "a"}, {Key: "v", Value: int32(2)}},
+			5: {{Key: "g", Value: "b"}, {Key: "v", Value: int32(5)}},
+		},
+	)
+
+	group := func(accumulator bson.D) bson.A {
+		return bson.A{bson.D{{"$group", bson.D{{"_id", "$g"}, {"r", accumulator}}}}}
+	}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Accumulators": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$group", bson.D{
+					{"_id", "$g"},
+					{"count", bson.D{{"$count", bson.D{}}}},
+					{"first", bson.D{{"$first", "$v"}}},
+					{"last", bson.D{{"$last", "$v"}}},
+					{"firstN", bson.D{{"$firstN", bson.D{{"input", "$v"}, {"n", int32(2)}}}}},
+					{"lastN", bson.D{{"$lastN", bson.D{{"input", "$v"}, {"n", int64(2)}}}}},
+					{"topN", bson.D{{"$topN", bson.D{
+						{"n", int32(2)},
+						{"sortBy", bson.D{{"v", -1}}},
+						{"output", "$_id"},
+					}}}},
+					{"bottomN", bson.D{{"$bottomN", bson.D{
+						{"n", 2.0},
+						{"sortBy", bson.D{{"v", -1}}},
+						{"output", bson.D{{"id", "$_id"}}},
+					}}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+		},
+		"FirstNotUnary": {
+			pipeline:   group(bson.D{{"$first", bson.A{"$v", "$g"}}}),
+			resultType: emptyResult,
+		},
+		"FirstNNotObject": {
+			pipeline:   group(bson.D{{"$firstN", "$v"}}),
+			resultType: emptyResult,
+		},
+		"FirstNMissingN": {
+			pipeline:   group(bson.D{{"$firstN", bson.D{{"input", "$v"}}}}),
+			resultType: emptyResult,
+		},
+		"LastNMissingInput": {
+			pipeline:   group(bson.D{{"$lastN", bson.D{{"n", int32(1)}}}}),
+			resultType: emptyResult,
+		},
+		"LastNNotIntegral": {
+			pipeline:   group(bson.D{{"$lastN", bson.D{{"input", "$v"}, {"n", 1.5}}}}),
+			resultType: emptyResult,
+		},
+		"TopNNotPositive": {
+			pipeline:   group(bson.D{{"$topN", bson.D{{"n", int32(0)}, {"sortBy", bson.D{{"v", 1}}}, {"output", "$v"}}}}),
+			resultType: emptyResult,
+		},
+		"TopNUnknownArgument": {
+			pipeline: group(bson.D{{"$topN", bson.D{
+				{"n", int32(1)},
+				{"sortBy", bson.D{{"v", 1}}},
+				{"output", "$v"},
+				{"input", "$v"},
+			}}}),
+			resultType: emptyResult,
+		},
+		"BottomNMissingSortBy": {
+			pipeline:   group(bson.D{{"$bottomN", bson.D{{"n", int32(1)}, {"output", "$v"}}}}),
+			resultType: emptyResult,
+		},
+		"BottomNMissingOutput": {
+			pipeline:   group(bson.D{{"$bottomN", bson.D{{"n", int32(1)}, {"sortBy", bson.D{{"v", 1}}}}}}),
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, shareddata.Providers{groups}, testCases)
+}
+
+func TestAggregateCompatGroupAccumulatorsMixedTypes(t *testing.T) {
+	t.Parallel()
+
+	// groups contains documents with values of different types in the same groups.
+	groups := shareddata.NewTopLevelFieldsProvider(
+		"Groups",
+		nil,
+		map[int32]shareddata.Fields{
+			1: {{Key: "g", Value: "a"}, {Key: "v", Value: int32(3)}},
+			2: {{Key: "g", Value: "a"}, {Key: "v", Value: int32(1)}},
+			3: {{Key: "g", Value: "a"}},
+			4: {{Key: "g", Value: "a"}, {Key: "v", Value: 2.0}},
+			5: {{Key: "g", Value: "a"}, {Key: "v", Value: nil}},
+			6: {{Key: "g", Value: "b"}, {Key: "v", Value: "x"}},
+			7: {{Key: "g", Value: "b"}, {Key: "v", Value: int64(1)}},
+			8: {{Key: "g", Value: "b"}, {Key: "v", Value: int32(1)}},
+		},
+	)
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Accumulators": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$group", bson.D{
+					{"_id", "$g"},
+					{"sum", bson.D{{"$sum", "$v"}}},
+					{"avg", bson.D{{"$avg", "$v"}}},
+					{"min", bson.D{{"$min", "$v"}}},
+					{"max", bson.D{{"$max", "$v"}}},
+					{"first", bson.D{{"$first", "$v"}}},
+					{"last", bson.D{{"$last", "$v"}}},
+					{"push", bson.D{{"$push", "$v"}}},
+					{"pushDocument", bson.D{{"$push", bson.D{{"id", "$_id"}}}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+		},
+		"AddToSet": {
+			// the order of set elements is not defined, so only their number is compared
+			pipeline: bson.A{
+				bson.D{{"$group", bson.D{
+					{"_id", "$g"},
+					{"set", bson.D{{"$addToSet", "$v"}}},
+				}}},
+				bson.D{{"$project", bson.D{{"n", bson.D{{"$size", "$set"}}}}}},
+			},
+		},
+		"PushNotUnary": {
+			pipeline: bson.A{bson.D{{"$group", bson.D{
+				{"_id", "$g"},
+				{"v", bson.D{{"$push", bson.A{"$v", "$g"}}}},
+			}}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, shareddata.Providers{groups}, testCases)
+}
+
+func TestAggregateCompatGroupStdDev(t *testing.T) {
+	t.Parallel()
+
+	// numbers contains documents with values of different numeric types,
+	// large values that cancel each other, and non-numeric values.
+	numbers := shareddata.NewTopLevelFieldsProvider(
+		"Numbers",
+		nil,
+		map[int32]shareddata.Fields{
+			0:   {{Key: "g", Value: "a"}, {Key: "v", Value: int32(2)}},
+			1:   {{Key: "g", Value: "a"}, {Key: "v", Value: int32(4)}},
+			2:   {{Key: "g", Value: "a"}, {Key: "v", Value: int64(4)}},
+			3:   {{Key: "g", Value: "a"}, {Key: "v", Value: 4.0}},
+			4:   {{Key: "g", Value: "a"}, {Key: "v", Value: int32(5)}},
+			5:   {{Key: "g", Value: "a"}, {Key: "v", Value: int32(5)}},
+			6:   {{Key: "g", Value: "a"}, {Key: "v", Value: int32(7)}},
+			7:   {{Key: "g", Value: "a"}, {Key: "v", Value: int64(9)}},
+			8:   {{Key: "g", Value: "a"}, {Key: "v", Value: "foo"}},
+			100: {{Key: "g", Value: "b"}, {Key: "v", Value: 1e17}, {Key: "w", Value: bson.A{1e17, 1.0, -1e17}}},
+			101: {{Key: "g", Value: "b"}, {Key: "v", Value: 1.0}},
+			102: {{Key: "g", Value: "b"}, {Key: "v", Value: -1e17}},
+			103: {{Key: "g", Value: "c"}, {Key: "v", Value: "bar"}},
+		},
+	)
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Group": {
+			pipeline: bson.A{
+				bson.D{{"$group", bson.D{
+					{"_id", "$g"},
+					{"avg", bson.D{{"$avg", "$v"}}},
+					{"pop", bson.D{{"$stdDevPop", "$v"}}},
+					{"samp", bson.D{{"$stdDevSamp", "$v"}}},
+				}}},
+			},
+		},
+		"Project": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"_id", int32(100)}}}},
+				bson.D{{"$project", bson.D{
+					{"avgArray", bson.D{{"$avg", "$w"}}},
+					{"avgArgs", bson.D{{"$avg", bson.A{int32(1), int64(2), "$v", "foo"}}}},
+					{"popArgs", bson.D{{"$stdDevPop", bson.A{int32(1), int32(3)}}}},
+					{"sampOne", bson.D{{"$stdDevSamp", bson.A{int32(1)}}}},
+				}}},
+			},
+			resultPushdown: pgPushdown,
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, shareddata.Providers{numbers}, testCases)
+}
+
+func TestAggregateCompatSample(t *testing.T) {
+	t.Parallel()
+
+	// documents are selected randomly, so only deterministic results are compared;
+	// see TestAggregateSample for the rest
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"All": {
+			pipeline: bson.A{
+				bson.D{{"$sample", bson.D{{"size", int64(1000)}}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+		},
+		"Zero": {
+			pipeline:   bson.A{bson.D{{"$sample", bson.D{{"size", int32(0)}}}}},
+			resultType: emptyResult,
+		},
+		"NotObject": {
+			pipeline:   bson.A{bson.D{{"$sample", int32(1)}}},
+			resultType: emptyResult,
+		},
+		"MissingSize": {
+			pipeline:   bson.A{bson.D{{"$sample", bson.D{}}}},
+			resultType: emptyResult,
+		},
+		"SizeNotNumber": {
+			pipeline:   bson.A{bson.D{{"$sample", bson.D{{"size", "1"}}}}},
+			resultType: emptyResult,
+		},
+		"SizeNegative": {
+			pipeline:   bson.A{bson.D{{"$sample", bson.D{{"size", int32(-1)}}}}},
+			resultType: emptyResult,
+		},
+		"UnknownOption": {
+			pipeline:   bson.A{bson.D{{"$sample", bson.D{{"size", int32(1)}, {"foo", int32(1)}}}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompat(t, testCases)
+}
+
+func TestAggregateCompatRand(t *testing.T) {
+	t.Parallel()
+
+	// generated values are random, so only deterministic results are compared;
+	// see TestAggregateRand for the rest
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"SampleRateZero": {
+			pipeline:   bson.A{bson.D{{"$match", bson.D{{"$sampleRate", int32(0)}}}}},
+			resultType: emptyResult,
+		},
+		"SampleRateOne": {
+			pipeline: bson.A{bson.D{{"$match", bson.D{{"$sampleRate", int32(1)}}}}},
+		},
+		"RandArgument": {
+			pipeline:   bson.A{bson.D{{"$addFields", bson.D{{"r", bson.D{{"$rand", int32(1)}}}}}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompat(t, testCases)
+}
+
 func TestAggregateCompatMatch(t *testing.T) {
 	t.Parallel()
 
@@ -1498,6 +1884,78 @@ func TestAggregateCompatUnwind(t *testing.T) {
 			pipeline:   bson.A{bson.D{{"$unwind", bson.A{"$v"}}}},
 			resultType: emptyResult,
 		},
+		"OptionsPath": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$unwind", bson.D{{"path", "$v"}}}},
+			},
+		},
+		"IncludeArrayIndex": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$unwind", bson.D{{"path", "$v"}, {"includeArrayIndex", "idx"}}}},
+			},
+		},
+		"PreserveNullAndEmptyArrays": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$unwind", bson.D{{"path", "$v"}, {"preserveNullAndEmptyArrays", true}}}},
+			},
+		},
+		"PreserveNonExistentWithIndex": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$unwind", bson.D{
+					{"path", "$non-existent"},
+					{"includeArrayIndex", "idx"},
+					{"preserveNullAndEmptyArrays", true},
+				}}},
+			},
+		},
+		"OptionsMissingPath": {
+			pipeline:   bson.A{bson.D{{"$unwind", bson.D{{"includeArrayIndex", "idx"}}}}},
+			resultType: emptyResult,
+		},
+		"OptionsPathNotString": {
+			pipeline:   bson.A{bson.D{{"$unwind", bson.D{{"path", 1}}}}},
+			resultType: emptyResult,
+		},
+		"OptionsUnknown": {
+			pipeline:   bson.A{bson.D{{"$unwind", bson.D{{"path", "$v"}, {"foo", 1}}}}},
+			resultType: emptyResult,
+		},
+		"IncludeArrayIndexEmpty": {
+			pipeline:   bson.A{bson.D{{"$unwind", bson.D{{"path", "$v"}, {"includeArrayIndex", ""}}}}},
+			resultType: emptyResult,
+		},
+		"IncludeArrayIndexPrefix": {
+			pipeline:   bson.A{bson.D{{"$unwind", bson.D{{"path", "$v"}, {"includeArrayIndex", "$idx"}}}}},
+			resultType: emptyResult,
+		},
+		"PreserveNotBool": {
+			pipeline:   bson.A{bson.D{{"$unwind", bson.D{{"path", "$v"}, {"preserveNullAndEmptyArrays", "true"}}}}},
+			resultType: emptyResult,
+		},
+		"IncludeArrayIndexNotString": {
+			pipeline:   bson.A{bson.D{{"$unwind", bson.D{{"path", "$v"}, {"includeArrayIndex", int32(1)}}}}},
+			resultType: emptyResult,
+		},
+		"PreserveWithIndex": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$unwind", bson.D{
+					{"path", "$v"},
+					{"includeArrayIndex", "idx"},
+					{"preserveNullAndEmptyArrays", true},
+				}}},
+			},
+		},
+		"Leading": {
+			pipeline: bson.A{bson.D{{"$unwind", "$v"}}},
+		},
+		"LeadingOptionsPath": {
+			pipeline: bson.A{bson.D{{"$unwind", bson.D{{"path", "$v"}}}}},
+		},
 	}
 
 	testAggregateStagesCompat(t, testCases)
@@ -2500,3 +2958,97 @@ func TestAggregateCompatUnset(t *testing.T) {
 	}
 	testAggregateStagesCompat(t, testCases)
 }
+
+func TestAggregateCompatFacet(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Pagination": {
+			pipeline: bson.A{
+				bson.D{{"$facet", bson.D{
+					{"metadata", bson.A{bson.D{{"$count", "total"}}}},
+					{"data", bson.A{
+						bson.D{{"$sort", bson.D{{"_id", 1}}}},
+						bson.D{{"$skip", 1}},
+						bson.D{{"$limit", 5}},
+					}},
+				}}},
+			},
+		},
+		"EmptySubPipeline": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$facet", bson.D{{"all", bson.A{}}}}},
+			},
+		},
+		"Group": {
+			pipeline: bson.A{
+				bson.D{{"$facet", bson.D{
+					{"count", bson.A{
+						bson.D{{"$group", bson.D{{"_id", nil}, {"count", bson.D{{"$sum", 1}}}}}},
+					}},
+				}}},
+			},
+		},
+		"NotObject": {
+			pipeline:   bson.A{bson.D{{"$facet", 1}}},
+			resultType: emptyResult,
+		},
+		"Empty": {
+			pipeline:   bson.A{bson.D{{"$facet", bson.D{}}}},
+			resultType: emptyResult,
+		},
+		"NotArray": {
+			pipeline:   bson.A{bson.D{{"$facet", bson.D{{"a", "b"}}}}},
+			resultType: emptyResult,
+		},
+		"Nested": {
+			pipeline: bson.A{bson.D{{"$facet", bson.D{
+				{"a", bson.A{bson.D{{"$facet", bson.D{{"b", bson.A{}}}}}}},
+			}}}},
+			resultType: emptyResult,
+		},
+		"Independent": {
+			pipeline: bson.A{
+				bson.D{{"$facet", bson.D{
+					{"set", bson.A{
+						bson.D{{"$sort", bson.D{{"_id", 1}}}},
+						bson.D{{"$set", bson.D{{"v", "c"}}}},
+					}},
+					{"unset", bson.A{
+						bson.D{{"$sort", bson.D{{"_id", 1}}}},
+						bson.D{{"$unset", "v"}},
+					}},
+				}}},
+			},
+		},
+		"EmptyInput": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"_id", "non-existent"}}}},
+				bson.D{{"$facet", bson.D{
+					{"data", bson.A{}},
+					{"count", bson.A{bson.D{{"$count", "n"}}}},
+				}}},
+			},
+			resultPushdown: allPushdown,
+		},
+		"ElementNotObject": {
+			pipeline:   bson.A{bson.D{{"$facet", bson.D{{"a", bson.A{int32(1)}}}}}},
+			resultType: emptyResult,
+		},
+		"DollarName": {
+			pipeline:   bson.A{bson.D{{"$facet", bson.D{{"$a", bson.A{}}}}}},
+			resultType: emptyResult,
+		},
+		"DotName": {
+			pipeline:   bson.A{bson.D{{"$facet", bson.D{{"a.b", bson.A{}}}}}},
+			resultType: emptyResult,
+		},
+		"CollStats": {
+			pipeline:   bson.A{bson.D{{"$facet", bson.D{{"a", bson.A{bson.D{{"$collStats", bson.D{}}}}}}}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompat(t, testCases)
+}
@@ -23,9 +23,13 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/FerretDB/FerretDB/integration/setup"
 	"github.com/FerretDB/FerretDB/integration/shareddata"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
 )
 
 func TestAggregateAddFieldsErrors(t *testing.T) {
@@ -1298,3 +1302,184 @@ func TestAggregateCommandCursor(t *testing.T) {
 		})
 	}
 }
+
+func TestAggregateOutMerge(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", 1}, {"v", "a"}, {"n", int32(1)}},
+		bson.D{{"_id", 2}, {"v", "b"}, {"n", int32(2)}},
+		bson.D{{"_id", 3}, {"v", "a"}, {"n", int32(3)}},
+	})
+	require.NoError(t, err)
+
+	target := collection.Database().Collection(collection.Name() + "_target")
+
+	_, err = target.InsertOne(ctx, bson.D{{"_id", "c"}, {"total", int32(0)}})
+	require.NoError(t, err)
+
+	find := func(t *testing.T) []bson.D {
+		t.Helper()
+
+		cursor, err := target.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"_id", 1}}))
+		require.NoError(t, err)
+
+		var res []bson.D
+		require.NoError(t, cursor.All(ctx, &res))
+
+		return res
+	}
+
+	group := bson.D{{"$group", bson.D{{"_id", "$v"}, {"total", bson.D{{"$sum", "$n"}}}}}}
+
+	cursor, err := collection.Aggregate(ctx, bson.A{group, bson.D{{"$merge", target.Name()}}})
+	require.NoError(t, err)
+	assert.False(t, cursor.Next(ctx))
+
+	expected := []bson.D{
+		{{"_id", "a"}, {"total", int32(4)}},
+		{{"_id", "b"}, {"total", int32(2)}},
+		{{"_id", "c"}, {"total", int32(0)}},
+	}
+	assert.Equal(t, expected, find(t))
+
+	cursor, err = collection.Aggregate(ctx, bson.A{group, bson.D{{"$out", target.Name()}}})
+	require.NoError(t, err)
+	assert.False(t, cursor.Next(ctx))
+
+	assert.Equal(t, expected[:2], find(t))
+}
+
+func TestAggregateSample(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	arr, _ := generateDocuments(0, 10)
+	_, err := collection.InsertMany(ctx, arr)
+	require.NoError(t, err)
+
+	cursor, err := collection.Aggregate(ctx, bson.A{bson.D{{"$sample", bson.D{{"size", 3.0}}}}})
+	require.NoError(t, err)
+
+	res := FetchAll(t, ctx, cursor)
+	require.Len(t, res, 3)
+
+	ids := map[any]struct{}{}
+	for _, id := range CollectIDs(t, res) {
+		ids[id] = struct{}{}
+	}
+
+	assert.Len(t, ids, 3, "documents should not be repeated")
+}
+
+func TestAggregateRand(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	arr, _ := generateDocuments(0, 10)
+	_, err := collection.InsertMany(ctx, arr)
+	require.NoError(t, err)
+
+	cursor, err := collection.Aggregate(ctx, bson.A{
+		bson.D{{"$addFields", bson.D{{"r", bson.D{{"$rand", bson.D{}}}}}}},
+	})
+	require.NoError(t, err)
+
+	res := FetchAll(t, ctx, cursor)
+	require.Len(t, res, 10)
+
+	values := map[float64]struct{}{}
+
+	for _, doc := range res {
+		r, ok := doc.Map()["r"].(float64)
+		require.True(t, ok)
+		assert.GreaterOrEqual(t, r, float64(0))
+		assert.Less(t, r, float64(1))
+
+		values[r] = struct{}{}
+	}
+
+	assert.Greater(t, len(values), 1, "each document should get its own value")
+}
+
+func TestAggregatePipelineOptimization(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "a"}, {"v", int32(3)}, {"w", int32(1)}},
+		bson.D{{"_id", "b"}, {"v", int32(2)}, {"w", int32(2)}},
+		bson.D{{"_id", "c"}, {"v", int32(1)}, {"w", int32(1)}},
+	})
+	require.NoError(t, err)
+
+	// stages are reordered and merged by FerretDB, but the result must not change
+	pipeline := bson.A{
+		bson.D{{"$project", bson.D{{"v", true}, {"w", true}}}},
+		bson.D{{"$sort", bson.D{{"v", int32(1)}}}},
+		bson.D{{"$match", bson.D{{"w", int32(1)}}}},
+		bson.D{{"$match", bson.D{{"v", bson.D{{"$gt", int32(1)}}}}}},
+		bson.D{{"$limit", int32(5)}},
+	}
+
+	t.Run("Aggregate", func(t *testing.T) {
+		t.Parallel()
+
+		cursor, err := collection.Aggregate(ctx, pipeline)
+		require.NoError(t, err)
+
+		expected := []bson.D{{{"_id", "a"}, {"v", int32(3)}, {"w", int32(1)}}}
+		AssertEqualDocumentsSlice(t, expected, FetchAll(t, ctx, cursor))
+	})
+
+	t.Run("ExplainStages", func(t *testing.T) {
+		setup.SkipForMongoDB(t, "optimized stages are FerretDB specific explain extension")
+
+		t.Parallel()
+
+		var res bson.D
+		err := collection.Database().RunCommand(ctx, bson.D{{"explain", bson.D{
+			{"aggregate", collection.Name()},
+			{"pipeline", pipeline},
+		}}}).Decode(&res)
+		require.NoError(t, err)
+
+		expected := ConvertDocument(t, bson.D{{"stages", bson.A{
+			bson.D{{"$match", bson.D{{"$and", bson.A{
+				bson.D{{"w", int32(1)}},
+				bson.D{{"v", bson.D{{"$gt", int32(1)}}}},
+			}}}}},
+			bson.D{{"$project", bson.D{{"v", true}, {"w", true}}}},
+			bson.D{{"$sort", bson.D{{"v", int32(1)}}}},
+			bson.D{{"$limit", int32(5)}},
+		}}})
+
+		stages, ok := must.NotFail(ConvertDocument(t, res).Get("stages")).(*types.Array)
+		require.True(t, ok)
+		testutil.AssertEqual(t, must.NotFail(expected.Get("stages")).(*types.Array), stages)
+	})
+
+	t.Run("ExplainLimitPushdown", func(t *testing.T) {
+		setup.SkipForMongoDB(t, "pushdown is FerretDB specific feature")
+
+		t.Parallel()
+
+		var res bson.D
+		err := collection.Database().RunCommand(ctx, bson.D{{"explain", bson.D{
+			{"aggregate", collection.Name()},
+			{"pipeline", bson.A{
+				bson.D{{"$limit", int32(2)}},
+				bson.D{{"$project", bson.D{{"v", true}}}},
+			}},
+		}}}).Decode(&res)
+		require.NoError(t, err)
+
+		limitPushdown, _ := ConvertDocument(t, res).Get("limitPushdown")
+		assert.Equal(t, true, limitPushdown)
+	})
+}
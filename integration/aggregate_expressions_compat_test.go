@@ -0,0 +1,222 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+)
+
+func TestAggregateExpressionsCompatRegex(t *testing.T) {
+	t.Parallel()
+
+	// strings contains a document with a string value and a document without it.
+	strings := shareddata.NewTopLevelFieldsProvider(
+		"Strings",
+		nil,
+		map[string]shareddata.Fields{
+			"string":  {{Key: "v", Value: "Café Cafe cafe"}},
+			"missing": {},
+		},
+	)
+
+	args := func(options string) bson.D {
+		return bson.D{{"input", "$v"}, {"regex", "c a (f) (x)? e # comment"}, {"options", options}}
+	}
+
+	addFields := func(args bson.D) bson.A {
+		return bson.A{bson.D{{"$addFields", bson.D{{"r", bson.D{{"$regexMatch", args}}}}}}}
+	}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Operators": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{
+				{"match", bson.D{{"$regexMatch", args("x")}}},
+				{"find", bson.D{{"$regexFind", args("ix")}}},
+				{"all", bson.D{{"$regexFindAll", args("x")}}},
+			}}}},
+		},
+		"BadOption": {
+			pipeline:   addFields(args("g")),
+			resultType: emptyResult,
+		},
+		"InvalidRegex": {
+			pipeline:   addFields(bson.D{{"input", "$v"}, {"regex", "("}}),
+			resultType: emptyResult,
+		},
+		"MissingRegex": {
+			pipeline:   addFields(bson.D{{"input", "$v"}}),
+			resultType: emptyResult,
+		},
+		"InputType": {
+			pipeline:   addFields(bson.D{{"input", int32(42)}, {"regex", "a"}}),
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, shareddata.Providers{strings}, testCases)
+}
+
+func TestAggregateExpressionsCompatArray(t *testing.T) {
+	t.Parallel()
+
+	// arrays contains a document with arrays and a document without them.
+	arrays := shareddata.NewTopLevelFieldsProvider(
+		"Arrays",
+		nil,
+		map[string]shareddata.Fields{
+			"arrays": {
+				{Key: "a", Value: bson.A{int32(1), int32(2), int32(3)}},
+				{Key: "b", Value: bson.A{"x", "y"}},
+				{Key: "c", Value: bson.A{int32(0), int32(1), nil, int32(2)}},
+			},
+			"missing": {},
+		},
+	)
+
+	addFields := func(expr bson.D) bson.A {
+		return bson.A{bson.D{{"$addFields", bson.D{{"r", expr}}}}}
+	}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Operators": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{
+				{"mapped", bson.D{{"$map", bson.D{
+					{"input", "$a"},
+					{"as", "n"},
+					{"in", bson.D{{"$sum", bson.A{"$$n", int32(10)}}}},
+				}}}},
+				{"filtered", bson.D{{"$filter", bson.D{{"input", "$c"}, {"cond", "$$this"}}}}},
+				{"limited", bson.D{{"$filter", bson.D{{"input", "$c"}, {"cond", "$$this"}, {"limit", int32(1)}}}}},
+				{"reduced", bson.D{{"$reduce", bson.D{
+					{"input", "$a"},
+					{"initialValue", int32(0)},
+					{"in", bson.D{{"$sum", bson.A{"$$value", "$$this"}}}},
+				}}}},
+				{"zipped", bson.D{{"$zip", bson.D{
+					{"inputs", bson.A{"$a", "$b"}},
+					{"useLongestLength", true},
+					{"defaults", bson.A{int32(0), "z"}},
+				}}}},
+				{"nested", bson.D{{"$map", bson.D{
+					{"input", "$b"},
+					{"as", "x"},
+					{"in", bson.D{{"$map", bson.D{{"input", "$a"}, {"in", bson.A{"$$x", "$$this"}}}}}},
+				}}}},
+				{"literal", bson.D{{"$literal", "$a"}}},
+			}}}},
+		},
+		"MapNotObject": {
+			pipeline:   addFields(bson.D{{"$map", "$a"}}),
+			resultType: emptyResult,
+		},
+		"MapMissingIn": {
+			pipeline:   addFields(bson.D{{"$map", bson.D{{"input", "$a"}}}}),
+			resultType: emptyResult,
+		},
+		"FilterInputType": {
+			pipeline:   addFields(bson.D{{"$filter", bson.D{{"input", "$_id"}, {"cond", true}}}}),
+			resultType: emptyResult,
+		},
+		"FilterLimit": {
+			pipeline:   addFields(bson.D{{"$filter", bson.D{{"input", "$a"}, {"cond", true}, {"limit", int32(0)}}}}),
+			resultType: emptyResult,
+		},
+		"ReduceMissingInitialValue": {
+			pipeline:   addFields(bson.D{{"$reduce", bson.D{{"input", "$a"}, {"in", "$$this"}}}}),
+			resultType: emptyResult,
+		},
+		"ZipDefaultsWithoutLongest": {
+			pipeline:   addFields(bson.D{{"$zip", bson.D{{"inputs", bson.A{"$a"}}, {"defaults", bson.A{int32(0)}}}}}),
+			resultType: emptyResult,
+		},
+		"ZipInputType": {
+			pipeline:   addFields(bson.D{{"$zip", bson.D{{"inputs", bson.A{"$_id"}}}}}),
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, shareddata.Providers{arrays}, testCases)
+}
+
+func TestAggregateExpressionsCompatObject(t *testing.T) {
+	t.Parallel()
+
+	// objects contains a document with arrays and documents, and a document without them.
+	objects := shareddata.NewTopLevelFieldsProvider(
+		"Objects",
+		nil,
+		map[string]shareddata.Fields{
+			"objects": {
+				{Key: "list", Value: bson.A{"a", "b"}},
+				{Key: "kv", Value: bson.A{bson.D{{"k", "x"}, {"v", true}}, bson.D{{"v", "y"}, {"k", "z"}}}},
+				{Key: "metrics", Value: bson.D{{"cpu", int32(10)}, {"mem", int32(20)}}},
+				{Key: "defaults", Value: bson.D{{"cpu", int32(0)}, {"disk", int32(0)}}},
+			},
+			"missing": {},
+		},
+	)
+
+	addFields := func(expr bson.D) bson.A {
+		return bson.A{bson.D{{"$addFields", bson.D{{"r", expr}}}}}
+	}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Operators": {
+			pipeline: bson.A{bson.D{{"$project", bson.D{
+				{"fromPairs", bson.D{{"$arrayToObject", bson.D{{"$zip", bson.D{{"inputs", bson.A{"$list", "$list"}}}}}}}},
+				{"fromKV", bson.D{{"$arrayToObject", bson.A{"$kv"}}}},
+				{"toArray", bson.D{{"$objectToArray", "$metrics"}}},
+				{"merged", bson.D{{"$mergeObjects", bson.A{"$defaults", "$metrics", bson.D{{"net", "$_id"}}}}}},
+			}}}},
+		},
+		"ArrayToObjectNotArray": {
+			pipeline:   addFields(bson.D{{"$arrayToObject", "$metrics"}}),
+			resultType: emptyResult,
+		},
+		"ArrayToObjectArraySize": {
+			pipeline:   addFields(bson.D{{"$arrayToObject", bson.D{{"$literal", bson.A{bson.A{"a"}}}}}}),
+			resultType: emptyResult,
+		},
+		"ArrayToObjectInconsistent": {
+			pipeline: addFields(bson.D{{"$arrayToObject", bson.D{{"$literal", bson.A{
+				bson.D{{"k", "a"}, {"v", int32(1)}},
+				bson.A{"b", int32(2)},
+			}}}}}),
+			resultType: emptyResult,
+		},
+		"ArrayToObjectMissingKV": {
+			pipeline:   addFields(bson.D{{"$arrayToObject", bson.D{{"$literal", bson.A{bson.D{{"k", "a"}, {"w", int32(1)}}}}}}}),
+			resultType: emptyResult,
+		},
+		"ArrayToObjectKeyType": {
+			pipeline:   addFields(bson.D{{"$arrayToObject", bson.D{{"$literal", bson.A{bson.A{int32(1), int32(2)}}}}}}),
+			resultType: emptyResult,
+		},
+		"ObjectToArrayNotObject": {
+			pipeline:   addFields(bson.D{{"$objectToArray", "$list"}}),
+			resultType: emptyResult,
+		},
+		"MergeObjectsType": {
+			pipeline:   addFields(bson.D{{"$mergeObjects", bson.A{"$metrics", "$list"}}}),
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, shareddata.Providers{objects}, testCases)
+}
@@ -38,7 +38,6 @@ func TestAggregateCompatMatchExpr(t *testing.T) {
 			pipeline: bson.A{bson.D{{"$match", bson.D{
 				{"$expr", bson.D{{"$gt", bson.A{"$v", 2}}}},
 			}}}},
-			skip: "https://github.com/FerretDB/FerretDB/issues/1456",
 		},
 	}
 
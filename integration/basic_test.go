@@ -28,6 +28,7 @@ import (
 
 	"github.com/FerretDB/FerretDB/integration/setup"
 	"github.com/FerretDB/FerretDB/integration/shareddata"
+	"github.com/FerretDB/FerretDB/internal/util/testutil/testfixtures"
 )
 
 func TestMostCommandsAreCaseSensitive(t *testing.T) {
@@ -100,6 +101,42 @@ func TestInsertFind(t *testing.T) {
 	}
 }
 
+func TestInsertFindFixtures(t *testing.T) {
+	t.Parallel()
+
+	providers := []shareddata.Provider{
+		shareddata.NewFixtureProvider(testfixtures.Scalars),
+		shareddata.NewFixtureProvider(testfixtures.Composites),
+	}
+	ctx, collection := setup.Setup(t, providers...)
+
+	var expected []bson.D
+	for _, p := range providers {
+		expected = append(expected, p.Docs()...)
+	}
+
+	actual := FindAll(t, ctx, collection)
+	require.Len(t, actual, len(expected))
+
+	for _, e := range expected {
+		id, ok := e.Map()["_id"]
+		require.True(t, ok)
+
+		var found bool
+
+		for _, a := range actual {
+			if a.Map()["_id"] == id {
+				AssertEqualDocuments(t, e, a)
+				found = true
+
+				break
+			}
+		}
+
+		assert.True(t, found, "document with _id %v not found", id)
+	}
+}
+
 //nolint:paralleltest // we test a global list of databases
 func TestFindCommentMethod(t *testing.T) {
 	ctx, collection := setup.Setup(t, shareddata.Scalars)
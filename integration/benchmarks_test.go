@@ -21,6 +21,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/FerretDB/FerretDB/integration/setup"
 	"github.com/FerretDB/FerretDB/integration/shareddata"
@@ -82,6 +83,50 @@ func BenchmarkFind(b *testing.B) {
 	})
 }
 
+func BenchmarkFindBatchSize(b *testing.B) {
+	provider := shareddata.BenchmarkSmallDocuments
+
+	b.Run(provider.Name(), func(b *testing.B) {
+		s := setup.SetupWithOpts(b, &setup.SetupOpts{
+			BenchmarkProvider: provider,
+		})
+
+		for _, batchSize := range []int32{1, 10, 100, 1000} {
+			b.Run(fmt.Sprintf("Batch%d", batchSize), func(b *testing.B) {
+				var firstDocs, docs int
+
+				opts := options.Find().SetBatchSize(batchSize)
+
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					cursor, err := s.Collection.Find(s.Ctx, bson.D{}, opts)
+					require.NoError(b, err)
+
+					docs = 0
+					for cursor.Next(s.Ctx) {
+						docs++
+					}
+
+					require.NoError(b, cursor.Close(s.Ctx))
+					require.NoError(b, cursor.Err())
+					require.Positive(b, docs)
+
+					if firstDocs == 0 {
+						firstDocs = docs
+					}
+				}
+
+				b.StopTimer()
+
+				require.Equal(b, firstDocs, docs)
+
+				b.ReportMetric(float64(docs), "docs-returned")
+			})
+		}
+	})
+}
+
 func BenchmarkReplaceOne(b *testing.B) {
 	provider := shareddata.BenchmarkSettingsDocuments
 
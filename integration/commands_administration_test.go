@@ -192,6 +192,61 @@ func TestCommandsAdministrationListCollections(t *testing.T) {
 	assert.Equal(t, compat, target)
 }
 
+func TestCommandsAdministrationListCollectionsFields(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	require.NoError(t, db.CreateCollection(ctx, "plain"))
+
+	opts := options.CreateCollection().SetCapped(true).SetSizeInBytes(1024).SetMaxDocuments(10)
+	require.NoError(t, db.CreateCollection(ctx, "capped", opts))
+
+	listCollections := func(t *testing.T, filter bson.D) []bson.D {
+		t.Helper()
+
+		cursor, err := db.ListCollections(ctx, filter)
+		require.NoError(t, err)
+
+		res := FetchAll(t, ctx, cursor)
+		require.Len(t, res, 1)
+
+		return res
+	}
+
+	idIndex := bson.D{{"v", int32(2)}, {"key", bson.D{{"_id", int32(1)}}}, {"name", "_id_"}}
+
+	capped := listCollections(t, bson.D{{"options.capped", true}})
+	assert.Equal(t, "capped", capped[0].Map()["name"])
+
+	plain := listCollections(t, bson.D{{"options.capped", bson.D{{"$ne", true}}}, {"info.readOnly", false}, {"name", "plain"}})
+	assert.Equal(t, "plain", plain[0].Map()["name"])
+
+	if setup.IsMongoDB(t) {
+		// MongoDB also returns collection UUID in info
+		return
+	}
+
+	expected := []bson.D{{
+		{"name", "capped"},
+		{"type", "collection"},
+		{"options", bson.D{{"capped", true}, {"size", int64(1024)}, {"max", int64(10)}}},
+		{"info", bson.D{{"readOnly", false}}},
+		{"idIndex", idIndex},
+	}}
+	AssertEqualDocumentsSlice(t, expected, capped)
+
+	expected = []bson.D{{
+		{"name", "plain"},
+		{"type", "collection"},
+		{"options", bson.D{}},
+		{"info", bson.D{{"readOnly", false}}},
+		{"idIndex", idIndex},
+	}}
+	AssertEqualDocumentsSlice(t, expected, plain)
+}
+
 func TestCommandsAdministrationGetParameter(t *testing.T) {
 	t.Parallel()
 	s := setup.SetupWithOpts(t, &setup.SetupOpts{
@@ -1523,6 +1578,179 @@ func TestCommandsAdministrationCurrentOp(t *testing.T) {
 	assert.True(t, ok)
 }
 
+func TestCommandsAdministrationValidateDBMetadata(t *testing.T) {
+	setup.SkipForMongoDB(t, "FerretDB's validateDBMetadata checks backend metadata instead of API version compatibility")
+
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", int32(1)}})
+	require.NoError(t, err)
+
+	var res bson.D
+	err = collection.Database().Client().Database("admin").RunCommand(ctx, bson.D{
+		{"validateDBMetadata", int32(1)},
+		{"db", collection.Database().Name()},
+		{"repair", true},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	expected := must.NotFail(types.NewDocument(
+		"apiVersionErrors", types.MakeArray(0),
+		"problems", types.MakeArray(0),
+		"ok", float64(1),
+	))
+	testutil.AssertEqual(t, expected, ConvertDocument(t, res))
+}
+
+func TestCommandsAdministrationSystemCollections(t *testing.T) {
+	setup.SkipForMongoDB(t, "FerretDB-specific materialized views and system collections emulation")
+
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	_, err := db.Collection("orders").InsertOne(ctx, bson.D{{"_id", int32(1)}})
+	require.NoError(t, err)
+
+	err = db.RunCommand(ctx, bson.D{
+		{"create", "totals"},
+		{"viewOn", "orders"},
+		{"pipeline", bson.A{bson.D{{"$count", "n"}}}},
+		{"materialized", true},
+	}).Err()
+	require.NoError(t, err)
+
+	t.Run("Views", func(t *testing.T) {
+		t.Parallel()
+
+		cursor, err := db.Collection("system.views").Find(ctx, bson.D{})
+		require.NoError(t, err)
+
+		expected := []bson.D{{
+			{"_id", db.Name() + ".totals"},
+			{"viewOn", "orders"},
+			{"pipeline", bson.A{bson.D{{"$count", "n"}}}},
+		}}
+		AssertEqualDocumentsSlice(t, expected, FetchAll(t, ctx, cursor))
+
+		cursor, err = db.Collection("system.views").Find(ctx, bson.D{{"viewOn", "other"}})
+		require.NoError(t, err)
+		assert.Empty(t, FetchAll(t, ctx, cursor))
+
+		cursor, err = db.Collection("system.views").Aggregate(ctx, bson.A{bson.D{{"$project", bson.D{{"viewOn", true}}}}})
+		require.NoError(t, err)
+
+		expected = []bson.D{{{"_id", db.Name() + ".totals"}, {"viewOn", "orders"}}}
+		AssertEqualDocumentsSlice(t, expected, FetchAll(t, ctx, cursor))
+	})
+
+	t.Run("JS", func(t *testing.T) {
+		t.Parallel()
+
+		n, err := db.Collection("system.js").CountDocuments(ctx, bson.D{})
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("Version", func(t *testing.T) {
+		t.Parallel()
+
+		cursor, err := db.Client().Database("admin").Collection("system.version").Find(ctx, bson.D{})
+		require.NoError(t, err)
+
+		expected := []bson.D{{{"_id", "featureCompatibilityVersion"}, {"version", "6.0"}}}
+		AssertEqualDocumentsSlice(t, expected, FetchAll(t, ctx, cursor))
+	})
+
+	t.Run("ReadOnly", func(t *testing.T) {
+		t.Parallel()
+
+		err := db.RunCommand(ctx, bson.D{{"insert", "system.views"}, {"documents", bson.A{bson.D{{"_id", "x"}}}}}).Err()
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    73,
+			Name:    "InvalidNamespace",
+			Message: "Invalid collection name: system.views",
+		}, err)
+	})
+}
+
+func TestCommandsAdministrationDocumentHistory(t *testing.T) {
+	setup.SkipForMongoDB(t, "FerretDB-specific document history")
+
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	require.NoError(t, db.CreateCollection(ctx, collection.Name()))
+
+	require.NoError(t, db.RunCommand(ctx, bson.D{{"collMod", collection.Name()}, {"history", true}}).Err())
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"v", int32(1)}},
+		bson.D{{"_id", int32(2)}, {"v", int32(2)}},
+		bson.D{{"_id", int32(3)}, {"v", int32(3)}},
+	})
+	require.NoError(t, err)
+
+	_, err = collection.UpdateOne(ctx, bson.D{{"_id", int32(1)}}, bson.D{{"$set", bson.D{{"v", int32(10)}}}})
+	require.NoError(t, err)
+
+	_, err = collection.DeleteOne(ctx, bson.D{{"_id", int32(2)}})
+	require.NoError(t, err)
+
+	require.NoError(t, collection.FindOneAndDelete(ctx, bson.D{{"_id", int32(1)}}).Err())
+
+	var res bson.D
+	err = db.RunCommand(ctx, bson.D{{"documentHistory", "list"}, {"collection", collection.Name()}}).Decode(&res)
+	require.NoError(t, err)
+
+	versions, ok := res.Map()["versions"].(bson.A)
+	require.True(t, ok)
+	require.Len(t, versions, 3)
+
+	expected := []struct {
+		id any
+		op string
+		v  int32
+	}{
+		{int32(1), "update", 1},
+		{int32(2), "delete", 2},
+		{int32(1), "delete", 10},
+	}
+
+	for i, e := range expected {
+		m := versions[i].(bson.D).Map()
+		assert.Equal(t, e.id, m["documentId"])
+		assert.Equal(t, e.op, m["op"])
+		assert.Equal(t, e.v, m["doc"].(bson.D).Map()["v"])
+	}
+
+	cursor, err := collection.Aggregate(ctx, bson.A{
+		bson.D{{"$documentHistory", bson.D{{"documentId", int32(1)}}}},
+		bson.D{{"$project", bson.D{{"_id", int32(0)}, {"v", "$doc.v"}}}},
+	})
+	require.NoError(t, err)
+	AssertEqualDocumentsSlice(t, []bson.D{{{"v", int32(1)}}, {{"v", int32(10)}}}, FetchAll(t, ctx, cursor))
+
+	res = nil
+	err = db.RunCommand(ctx, bson.D{
+		{"documentHistory", "purge"},
+		{"collection", collection.Name()},
+		{"documentId", int32(1)},
+	}).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), res.Map()["purged"])
+
+	require.NoError(t, db.RunCommand(ctx, bson.D{{"collMod", collection.Name()}, {"history", false}}).Err())
+
+	_, err = collection.Aggregate(ctx, bson.A{bson.D{{"$documentHistory", bson.D{}}}})
+	assert.ErrorContains(t, err, "document history is not enabled")
+}
+
 func TestCommandsAdministrationKillCursors(t *testing.T) {
 	t.Parallel()
 
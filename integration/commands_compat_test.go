@@ -0,0 +1,218 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+// commandsCompatTestCase describes a sequence of commands that are sent to both target and compat,
+// with their whole responses compared.
+type commandsCompatTestCase struct {
+	// Commands are run in order against a new empty collection.
+	// The value of the first field (the command name) is replaced with the collection name.
+	commands []bson.D
+
+	skip string // skips test if non-empty
+}
+
+// testCommandsCompat tests commands compatibility test cases.
+//
+// Unlike other compat tests that check results returned by the driver,
+// it compares normalized response documents, see normalizeCommandResponse.
+func testCommandsCompat(t *testing.T, testCases map[string]commandsCompatTestCase) {
+	t.Helper()
+
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+
+			if tc.skip != "" {
+				t.Skip(tc.skip)
+			}
+
+			t.Parallel()
+
+			require.NotEmpty(t, tc.commands, "commands must be set")
+
+			s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+				AddNonExistentCollection: true,
+			})
+			ctx, targetCollection, compatCollection := s.Ctx, s.TargetCollections[0], s.CompatCollections[0]
+
+			for _, command := range tc.commands {
+				command := append(bson.D{{command[0].Key, targetCollection.Name()}}, command[1:]...)
+
+				t.Logf("Command: %v", command)
+
+				targetResult := targetCollection.Database().RunCommand(ctx, command)
+				compatResult := compatCollection.Database().RunCommand(ctx, command)
+
+				targetErr := targetResult.Err()
+				compatErr := compatResult.Err()
+
+				if targetErr != nil {
+					t.Logf("Target error: %v", targetErr)
+					t.Logf("Compat error: %v", compatErr)
+
+					// write errors are returned with the rest of the response that is compared below
+					if targetWE, ok := targetErr.(mongo.WriteException); ok { //nolint:errorlint // do not inspect error chain
+						compatWE, ok := compatErr.(mongo.WriteException) //nolint:errorlint // do not inspect error chain
+						require.Truef(t, ok, "compat error is %T, not mongo.WriteException", compatErr)
+
+						var targetRes, compatRes bson.D
+						require.NoError(t, bson.Unmarshal(targetWE.Raw, &targetRes))
+						require.NoError(t, bson.Unmarshal(compatWE.Raw, &compatRes))
+
+						testutil.AssertEqual(t, normalizeCommandResponse(t, compatRes), normalizeCommandResponse(t, targetRes))
+
+						continue
+					}
+
+					// error messages are intentionally not compared
+					AssertMatchesCommandError(t, compatErr, targetErr)
+
+					continue
+				}
+				require.NoError(t, compatErr, "compat error; target returned no error")
+
+				var targetRes, compatRes bson.D
+				require.NoError(t, targetResult.Decode(&targetRes))
+				require.NoError(t, compatResult.Decode(&compatRes))
+
+				testutil.AssertEqual(t, normalizeCommandResponse(t, compatRes), normalizeCommandResponse(t, targetRes))
+			}
+		})
+	}
+}
+
+// normalizeCommandResponse converts the given response and removes or replaces fields
+// that are expected to differ between target and compat:
+// cluster and operation times, non-zero cursor IDs, and write errors' messages.
+func normalizeCommandResponse(t testing.TB, res bson.D) *types.Document {
+	t.Helper()
+
+	doc := ConvertDocument(t, res)
+
+	for _, k := range []string{"$clusterTime", "operationTime", "electionId", "opTime"} {
+		doc.Remove(k)
+	}
+
+	if cursor, _ := doc.Get("cursor"); cursor != nil {
+		cursor := cursor.(*types.Document)
+		if id, _ := cursor.Get("id"); id != int64(0) {
+			cursor.Set("id", int64(1))
+		}
+	}
+
+	if writeErrors, _ := doc.Get("writeErrors"); writeErrors != nil {
+		arr := writeErrors.(*types.Array)
+		for i := 0; i < arr.Len(); i++ {
+			must.NotFail(arr.Get(i)).(*types.Document).Remove("errmsg")
+		}
+	}
+
+	return doc
+}
+
+func TestCommandsCompat(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]commandsCompatTestCase{
+		"NumericOperators": {
+			commands: []bson.D{
+				{{"insert", ""}, {"documents", bson.A{
+					bson.D{{"_id", int32(1)}, {"i", int32(math.MaxInt32)}, {"m", int32(3)}, {"min", int32(5)}, {"max", int32(5)}},
+					bson.D{{"_id", int32(2)}, {"l", int64(math.MaxInt64)}, {"s", "foo"}},
+				}}},
+				{{"update", ""}, {"updates", bson.A{bson.D{
+					{"q", bson.D{{"_id", int32(1)}}},
+					{"u", bson.D{
+						{"$inc", bson.D{{"i", int32(1)}}},
+						{"$mul", bson.D{{"m", 1.5}}},
+						{"$min", bson.D{{"min", int64(2)}}},
+						{"$max", bson.D{{"max", int64(2)}}},
+					}},
+				}}}},
+				{{"find", ""}, {"filter", bson.D{{"_id", int32(1)}}}},
+			},
+		},
+		"WriteErrors": {
+			commands: []bson.D{
+				{{"insert", ""}, {"documents", bson.A{
+					bson.D{{"_id", int32(1)}, {"v", int32(1)}},
+					bson.D{{"_id", int32(2)}, {"l", int64(math.MaxInt64)}, {"s", "foo"}},
+				}}},
+				{{"update", ""}, {"updates", bson.A{
+					bson.D{{"q", bson.D{{"_id", int32(2)}}}, {"u", bson.D{{"$inc", bson.D{{"l", int64(1)}}}}}},
+					bson.D{{"q", bson.D{{"_id", int32(2)}}}, {"u", bson.D{{"$mul", bson.D{{"s", int32(2)}}}}}},
+					bson.D{{"q", bson.D{{"_id", int32(1)}}}, {"u", bson.D{{"$inc", bson.D{{"v", int32(1)}}}}}},
+				}}, {"ordered", false}},
+				{{"find", ""}, {"sort", bson.D{{"_id", int32(1)}}}},
+			},
+		},
+		"InsertDeleteCount": {
+			commands: []bson.D{
+				{{"insert", ""}, {"documents", bson.A{
+					bson.D{{"_id", int32(1)}, {"v", "a"}},
+					bson.D{{"_id", int32(2)}, {"v", "b"}},
+					bson.D{{"_id", int32(3)}, {"v", "a"}},
+				}}},
+				{{"delete", ""}, {"deletes", bson.A{bson.D{{"q", bson.D{{"v", "a"}}}, {"limit", int32(0)}}}}},
+				{{"count", ""}},
+				{{"distinct", ""}, {"key", "v"}},
+			},
+		},
+		"FindAndModify": {
+			commands: []bson.D{
+				{{"insert", ""}, {"documents", bson.A{bson.D{{"_id", int32(1)}, {"v", int32(1)}}}}},
+				{{"findAndModify", ""}, {"query", bson.D{{"_id", int32(1)}}}, {"update", bson.D{{"$inc", bson.D{{"v", int32(1)}}}}}, {"new", true}},
+				{{"findAndModify", ""}, {"query", bson.D{{"_id", int32(2)}}}, {"update", bson.D{{"v", int32(3)}}}, {"upsert", true}},
+				{{"findAndModify", ""}, {"query", bson.D{{"_id", int32(1)}}}, {"remove", true}},
+				{{"find", ""}},
+			},
+		},
+		"BatchSize": {
+			commands: []bson.D{
+				{{"insert", ""}, {"documents", bson.A{
+					bson.D{{"_id", int32(1)}},
+					bson.D{{"_id", int32(2)}},
+					bson.D{{"_id", int32(3)}},
+				}}},
+				{{"find", ""}, {"sort", bson.D{{"_id", int32(1)}}}, {"batchSize", int32(2)}},
+				{{"aggregate", ""}, {"pipeline", bson.A{bson.D{{"$sort", bson.D{{"_id", int32(-1)}}}}}}, {"cursor", bson.D{}}},
+			},
+		},
+		"CommandError": {
+			commands: []bson.D{
+				{{"find", ""}, {"filter", int32(42)}},
+				{{"count", ""}, {"query", "foo"}},
+			},
+		},
+	}
+
+	testCommandsCompat(t, testCases)
+}
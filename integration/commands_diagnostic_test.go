@@ -114,6 +114,10 @@ func TestCommandsDiagnosticExplain(t *testing.T) {
 
 			assert.NotEmpty(t, explainResult["queryPlanner"])
 			assert.IsType(t, bson.D{}, explainResult["queryPlanner"])
+
+			queryPlanner := explainResult["queryPlanner"].(bson.D).Map()
+			assert.Equal(t, collection.Database().Name()+"."+collection.Name(), queryPlanner["namespace"])
+			assert.IsType(t, bson.D{}, queryPlanner["winningPlan"])
 		})
 	}
 }
@@ -262,6 +266,52 @@ func TestCommandsDiagnosticListCommands(t *testing.T) {
 	assert.NotEmpty(t, listCommands.Map()["help"].(string))
 }
 
+func TestCommandsDiagnosticTop(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", int32(1)}})
+	require.NoError(t, err)
+
+	t.Run("Admin", func(t *testing.T) {
+		t.Parallel()
+
+		var res bson.D
+		err := collection.Database().Client().Database("admin").RunCommand(ctx, bson.D{{"top", int32(1)}}).Decode(&res)
+		require.NoError(t, err)
+
+		totals, ok := must.NotFail(ConvertDocument(t, res).Get("totals")).(*types.Document)
+		require.True(t, ok)
+		assert.Equal(t, "all times in microseconds", must.NotFail(totals.Get("note")))
+
+		ns, ok := must.NotFail(totals.Get(collection.Database().Name() + "." + collection.Name())).(*types.Document)
+		require.True(t, ok)
+
+		expectedKeys := []string{"total", "readLock", "writeLock", "queries", "getmore", "insert", "update", "remove", "commands"}
+		assert.Equal(t, expectedKeys, ns.Keys())
+
+		if setup.IsMongoDB(t) {
+			return
+		}
+
+		// FerretDB does not track usage yet
+		expected := must.NotFail(types.NewDocument("time", int64(0), "count", int64(0)))
+		testutil.AssertEqual(t, expected, must.NotFail(ns.Get("total")).(*types.Document))
+	})
+
+	t.Run("NonAdmin", func(t *testing.T) {
+		t.Parallel()
+
+		err := collection.Database().RunCommand(ctx, bson.D{{"top", int32(1)}}).Err()
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    13,
+			Name:    "Unauthorized",
+			Message: "top may only be run against the admin database.",
+		}, err)
+	})
+}
+
 func TestCommandsDiagnosticValidate(t *testing.T) {
 	t.Parallel()
 	ctx, collection := setup.Setup(t, shareddata.Doubles)
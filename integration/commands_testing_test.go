@@ -0,0 +1,190 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+)
+
+//nolint:paralleltest // fail points are global
+func TestCommandsTestingConfigureFailPointFailCommand(t *testing.T) {
+	ctx, collection := setup.Setup(t, shareddata.Scalars)
+	admin := collection.Database().Client().Database("admin")
+
+	t.Cleanup(func() {
+		command := bson.D{{"configureFailPoint", "failCommand"}, {"mode", "off"}}
+		require.NoError(t, admin.RunCommand(ctx, command).Err())
+	})
+
+	t.Run("ErrorCode", func(t *testing.T) {
+		command := bson.D{
+			{"configureFailPoint", "failCommand"},
+			{"mode", bson.D{{"times", int32(1)}}},
+			{"data", bson.D{
+				{"failCommands", bson.A{"distinct"}},
+				{"errorCode", int32(2)}, // not retryable by the driver
+			}},
+		}
+		require.NoError(t, admin.RunCommand(ctx, command).Err())
+
+		_, err := collection.Distinct(ctx, "v", bson.D{})
+		AssertMatchesCommandError(t, mongo.CommandError{Code: 2, Name: "BadValue"}, err)
+
+		// fail point is disabled after being triggered once
+		_, err = collection.Distinct(ctx, "v", bson.D{})
+		require.NoError(t, err)
+	})
+
+	t.Run("RetryableErrorCode", func(t *testing.T) {
+		// the driver retries distinct once, so fail both attempts
+		command := bson.D{
+			{"configureFailPoint", "failCommand"},
+			{"mode", bson.D{{"times", int32(2)}}},
+			{"data", bson.D{
+				{"failCommands", bson.A{"distinct"}},
+				{"errorCode", int32(91)},
+			}},
+		}
+		require.NoError(t, admin.RunCommand(ctx, command).Err())
+
+		_, err := collection.Distinct(ctx, "v", bson.D{})
+		AssertMatchesCommandError(t, mongo.CommandError{Code: 91, Name: "ShutdownInProgress"}, err)
+
+		_, err = collection.Distinct(ctx, "v", bson.D{})
+		require.NoError(t, err)
+	})
+
+	t.Run("EmptyFailCommands", func(t *testing.T) {
+		command := bson.D{
+			{"configureFailPoint", "failCommand"},
+			{"mode", "alwaysOn"},
+			{"data", bson.D{{"errorCode", int32(2)}}},
+		}
+		err := admin.RunCommand(ctx, command).Err()
+		require.Error(t, err)
+
+		if !setup.IsMongoDB(t) {
+			AssertMatchesCommandError(t, mongo.CommandError{Code: 2, Name: "BadValue"}, err)
+		}
+
+		// other commands are not affected
+		_, err = collection.Distinct(ctx, "v", bson.D{})
+		require.NoError(t, err)
+	})
+
+	t.Run("WriteConcernError", func(t *testing.T) {
+		command := bson.D{
+			{"configureFailPoint", "failCommand"},
+			{"mode", bson.D{{"times", int32(1)}}},
+			{"data", bson.D{
+				{"failCommands", bson.A{"insert"}},
+				{"writeConcernError", bson.D{{"code", int32(64)}, {"errmsg", "waiting for replication timed out"}}},
+			}},
+		}
+		require.NoError(t, admin.RunCommand(ctx, command).Err())
+
+		_, err := collection.InsertOne(ctx, bson.D{{"_id", "wce"}})
+
+		var we mongo.WriteException
+		require.ErrorAs(t, err, &we)
+		require.NotNil(t, we.WriteConcernError)
+		assert.Equal(t, 64, we.WriteConcernError.Code)
+
+		// document is inserted anyway
+		count, err := collection.CountDocuments(ctx, bson.D{{"_id", "wce"}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("CloseConnection", func(t *testing.T) {
+		command := bson.D{
+			{"configureFailPoint", "failCommand"},
+			{"mode", bson.D{{"times", int32(1)}}},
+			{"data", bson.D{
+				{"failCommands", bson.A{"count"}},
+				{"closeConnection", true},
+			}},
+		}
+		require.NoError(t, admin.RunCommand(ctx, command).Err())
+
+		err := collection.Database().RunCommand(ctx, bson.D{{"count", collection.Name()}}).Err()
+		require.Error(t, err)
+		assert.True(t, mongo.IsNetworkError(err), "%v", err)
+
+		// driver reconnects
+		require.NoError(t, collection.Database().RunCommand(ctx, bson.D{{"count", collection.Name()}}).Err())
+	})
+}
+
+//nolint:paralleltest // fail points are global
+func TestCommandsTestingConfigureFailPointGetMore(t *testing.T) {
+	ctx, collection := setup.Setup(t, shareddata.Scalars)
+	admin := collection.Database().Client().Database("admin")
+
+	command := bson.D{
+		{"configureFailPoint", "failGetMoreAfterCursorCheckout"},
+		{"mode", bson.D{{"times", int32(1)}}},
+		{"data", bson.D{{"errorCode", int32(280)}}},
+	}
+	require.NoError(t, admin.RunCommand(ctx, command).Err())
+
+	t.Cleanup(func() {
+		command := bson.D{{"configureFailPoint", "failGetMoreAfterCursorCheckout"}, {"mode", "off"}}
+		require.NoError(t, admin.RunCommand(ctx, command).Err())
+	})
+
+	// fail point is not triggered if the cursor could not be checked out
+	err := collection.Database().RunCommand(ctx, bson.D{
+		{"getMore", int64(1 << 40)},
+		{"collection", collection.Name()},
+	}).Err()
+
+	var notFoundErr mongo.CommandError
+	require.ErrorAs(t, err, &notFoundErr)
+	assert.Equal(t, int32(43), notFoundErr.Code)
+
+	cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetBatchSize(1))
+	require.NoError(t, err)
+
+	defer cursor.Close(ctx)
+
+	require.True(t, cursor.Next(ctx))
+	require.False(t, cursor.Next(ctx))
+
+	var cmdErr mongo.CommandError
+	require.ErrorAs(t, cursor.Err(), &cmdErr)
+	assert.Equal(t, int32(280), cmdErr.Code)
+}
+
+func TestCommandsTestingConfigureFailPointErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	err := collection.Database().RunCommand(ctx, bson.D{{"configureFailPoint", "failCommand"}, {"mode", "off"}}).Err()
+
+	var cmdErr mongo.CommandError
+	require.ErrorAs(t, err, &cmdErr)
+	assert.Equal(t, int32(13), cmdErr.Code)
+}
@@ -0,0 +1,141 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+)
+
+// TestCompass replays commands that MongoDB Compass sends when a collection is opened:
+// collection statistics for the header, schema sampling, documents and indexes tabs.
+//
+// Commands were recorded from Compass traffic, with namespaces replaced.
+func TestCompass(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t, shareddata.Scalars, shareddata.Composites)
+
+	db := collection.Database()
+	cName := collection.Name()
+
+	for _, tc := range []struct { //nolint:vet // for readability
+		name    string
+		command bson.D
+		check   func(t *testing.T, res bson.D) // optional
+	}{
+		{
+			name: "ListCollections",
+			command: bson.D{
+				{"listCollections", 1},
+				{"filter", bson.D{{"name", cName}}},
+				{"nameOnly", false},
+				{"authorizedCollections", true},
+			},
+		},
+		{
+			name: "CollStatsStage",
+			command: bson.D{
+				{"aggregate", cName},
+				{"pipeline", bson.A{
+					bson.D{{"$collStats", bson.D{{"storageStats", bson.D{}}}}},
+				}},
+				{"cursor", bson.D{}},
+			},
+		},
+		{
+			name:    "CollStats",
+			command: bson.D{{"collStats", cName}, {"scale", 1}, {"verbose", false}},
+			check: func(t *testing.T, res bson.D) {
+				assert.Equal(t, int32(1), res.Map()["scaleFactor"])
+			},
+		},
+		{
+			name: "SampleSchema",
+			command: bson.D{
+				{"aggregate", cName},
+				{"pipeline", bson.A{
+					bson.D{{"$match", bson.D{}}},
+					bson.D{{"$sample", bson.D{{"size", 1000}}}},
+				}},
+				{"allowDiskUse", true},
+				{"maxTimeMS", 60000},
+				{"cursor", bson.D{}},
+			},
+			check: func(t *testing.T, res bson.D) {
+				cursor := res.Map()["cursor"].(bson.D).Map()
+				assert.NotEmpty(t, cursor["firstBatch"])
+			},
+		},
+		{
+			name: "CountDocuments",
+			command: bson.D{
+				{"aggregate", cName},
+				{"pipeline", bson.A{
+					bson.D{{"$match", bson.D{}}},
+					bson.D{{"$skip", 0}},
+					bson.D{{"$group", bson.D{{"_id", 1}, {"n", bson.D{{"$sum", 1}}}}}},
+				}},
+				{"maxTimeMS", 500},
+				{"cursor", bson.D{}},
+			},
+		},
+		{
+			name: "FindDocuments",
+			command: bson.D{
+				{"find", cName},
+				{"filter", bson.D{}},
+				{"sort", bson.D{{"v", -1}, {"_id", 1}}},
+				{"projection", bson.D{}},
+				{"skip", 0},
+				{"limit", 20},
+				{"maxTimeMS", 60000},
+			},
+		},
+		{
+			name: "ListIndexes",
+			command: bson.D{
+				{"listIndexes", cName},
+				{"cursor", bson.D{}},
+			},
+		},
+		{
+			name: "IndexStats",
+			command: bson.D{
+				{"aggregate", cName},
+				{"pipeline", bson.A{
+					bson.D{{"$collStats", bson.D{{"count", bson.D{}}}}},
+				}},
+				{"cursor", bson.D{}},
+			},
+		},
+	} {
+		// run sequentially, in the order Compass sends commands
+		var res bson.D
+		err := db.RunCommand(ctx, tc.command).Decode(&res)
+		require.NoError(t, err, tc.name)
+		assert.Equal(t, float64(1), res.Map()["ok"], tc.name)
+
+		if tc.check != nil {
+			tc.check(t, res)
+		}
+	}
+}
@@ -17,11 +17,13 @@ package integration
 import (
 	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/FerretDB/FerretDB/integration/setup"
 	"github.com/FerretDB/FerretDB/integration/shareddata"
@@ -151,6 +153,35 @@ func TestCountCompat(t *testing.T) {
 			filter:  bson.D{{"v", bson.D{{"$type", "array"}}}},
 			optSkip: 0,
 		},
+		"FieldInt32": {
+			filter: bson.D{{"v", int32(42)}},
+		},
+		"FieldDouble": {
+			filter: bson.D{{"v", 42.13}},
+		},
+		"FieldString": {
+			filter: bson.D{{"v", bson.D{{"$eq", "foo"}}}},
+		},
+		"FieldDate": {
+			filter: bson.D{{"v", primitive.NewDateTimeFromTime(time.Date(2021, 11, 1, 10, 18, 42, 123000000, time.UTC))}},
+		},
+		"FieldExists": {
+			filter: bson.D{{"v", bson.D{{"$exists", false}}}},
+		},
+		"FieldSize": {
+			filter: bson.D{{"v", bson.D{{"$size", 1}}}},
+		},
+		"FieldsAndComment": {
+			filter: bson.D{{"v", bson.D{{"$exists", true}}}, {"_id", "int32"}, {"$comment", "foo"}},
+		},
+		"FieldPartiallySupported": {
+			filter: bson.D{{"v", int32(42)}, {"_id", bson.D{{"$gt", "int32"}}}},
+		},
+		"FieldIntSkipLimit": {
+			filter:  bson.D{{"v", int32(42)}},
+			optSkip: 1,
+			limit:   1,
+		},
 
 		"LimitAlmostAll": {
 			filter: bson.D{},
@@ -221,3 +252,159 @@ func TestCountCompat(t *testing.T) {
 
 	testCountCompat(t, testCases)
 }
+
+// TestCountDocumentsCompat checks that CountDocuments driver helper,
+// which uses aggregation pipeline with $match, $skip, $limit and $group stages, returns the same results.
+func TestCountDocumentsCompat(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+		Providers:                shareddata.AllProviders(),
+		AddNonExistentCollection: true,
+	})
+	ctx, targetCollections, compatCollections := s.Ctx, s.TargetCollections, s.CompatCollections
+
+	testCases := map[string]struct {
+		filter bson.D                // required, filter for the helper
+		opts   *options.CountOptions // optional, options for the helper
+	}{
+		"Empty": {
+			filter: bson.D{},
+		},
+		"IDString": {
+			filter: bson.D{{"_id", "string"}},
+		},
+		"IDNotExists": {
+			filter: bson.D{{"_id", "count-id-not-exists"}},
+		},
+		"FieldTypeArrays": {
+			filter: bson.D{{"v", bson.D{{"$type", "array"}}}},
+		},
+		"Skip": {
+			filter: bson.D{},
+			opts:   options.Count().SetSkip(1),
+		},
+		"SkipAll": {
+			filter: bson.D{},
+			opts:   options.Count().SetSkip(1000),
+		},
+		"Limit": {
+			filter: bson.D{},
+			opts:   options.Count().SetLimit(2),
+		},
+		"SkipLimit": {
+			filter: bson.D{{"v", bson.D{{"$exists", true}}}},
+			opts:   options.Count().SetSkip(1).SetLimit(2),
+		},
+		"SkipNegative": {
+			filter: bson.D{},
+			opts:   options.Count().SetSkip(-1),
+		},
+	}
+
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+
+			t.Parallel()
+
+			require.NotNil(t, tc.filter, "filter should be set")
+
+			for i := range targetCollections {
+				targetCollection := targetCollections[i]
+				compatCollection := compatCollections[i]
+				t.Run(targetCollection.Name(), func(t *testing.T) {
+					t.Helper()
+
+					targetRes, targetErr := targetCollection.CountDocuments(ctx, tc.filter, tc.opts)
+					compatRes, compatErr := compatCollection.CountDocuments(ctx, tc.filter, tc.opts)
+
+					if targetErr != nil {
+						t.Logf("Target error: %v", targetErr)
+						t.Logf("Compat error: %v", compatErr)
+
+						// error messages are intentionally not compared
+						AssertMatchesCommandError(t, compatErr, targetErr)
+
+						return
+					}
+					require.NoError(t, compatErr, "compat error; target returned no error")
+
+					assert.Equal(t, compatRes, targetRes)
+				})
+			}
+		})
+	}
+}
+
+// TestEstimatedDocumentCountCompat checks that EstimatedDocumentCount driver helper,
+// which uses count command without a query, returns the same results.
+func TestEstimatedDocumentCountCompat(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+		Providers:                shareddata.AllProviders(),
+		AddNonExistentCollection: true,
+	})
+	ctx, targetCollections, compatCollections := s.Ctx, s.TargetCollections, s.CompatCollections
+
+	for i := range targetCollections {
+		targetCollection := targetCollections[i]
+		compatCollection := compatCollections[i]
+		t.Run(targetCollection.Name(), func(t *testing.T) {
+			t.Helper()
+
+			t.Parallel()
+
+			targetRes, targetErr := targetCollection.EstimatedDocumentCount(ctx)
+			compatRes, compatErr := compatCollection.EstimatedDocumentCount(ctx)
+
+			require.NoError(t, compatErr)
+			require.NoError(t, targetErr)
+
+			assert.Equal(t, compatRes, targetRes)
+		})
+	}
+}
+
+func TestCountExplainPushdown(t *testing.T) {
+	setup.SkipForMongoDB(t, "pushdown is FerretDB specific feature")
+
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t, shareddata.Scalars, shareddata.Composites)
+
+	for name, tc := range map[string]struct {
+		filter        bson.D
+		countPushdown resultPushdown
+	}{
+		"Empty": {
+			filter:        bson.D{},
+			countPushdown: pgPushdown,
+		},
+		"Equal": {
+			filter:        bson.D{{"v", int32(42)}},
+			countPushdown: pgPushdown,
+		},
+		"Gt": {
+			filter:        bson.D{{"v", bson.D{{"$gt", int32(42)}}}},
+			countPushdown: noPushdown,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var res bson.D
+			err := collection.Database().RunCommand(ctx, bson.D{{"explain", bson.D{
+				{"count", collection.Name()},
+				{"query", tc.filter},
+			}}}).Decode(&res)
+			require.NoError(t, err)
+
+			countPushdown, _ := ConvertDocument(t, res).Get("countPushdown")
+			assert.Equal(t, tc.countPushdown.FilterPushdownExpected(t), countPushdown)
+		})
+	}
+}
@@ -24,7 +24,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/FerretDB/FerretDB/integration/setup"
 )
@@ -355,3 +357,97 @@ func TestCreateCappedCommandInvalidSpec(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateCollModDefaultIDType(t *testing.T) {
+	setup.SkipForMongoDB(t, "FerretDB-specific defaultIdType collection option")
+
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+	cName := collection.Name() + "_ids"
+
+	require.NoError(t, db.RunCommand(ctx, bson.D{{"create", cName}, {"defaultIdType", "uuid"}}).Err())
+
+	insertAndFind := func(t *testing.T, v string) any {
+		t.Helper()
+
+		// use the raw command so that the driver does not generate _id
+		err := db.RunCommand(ctx, bson.D{{"insert", cName}, {"documents", bson.A{bson.D{{"v", v}}}}}).Err()
+		require.NoError(t, err)
+
+		var doc bson.D
+		require.NoError(t, db.Collection(cName).FindOne(ctx, bson.D{{"v", v}}).Decode(&doc))
+		require.NotEmpty(t, doc)
+		require.Equal(t, "_id", doc[0].Key)
+
+		return doc[0].Value
+	}
+
+	id := insertAndFind(t, "uuid")
+	require.IsType(t, primitive.Binary{}, id)
+	assert.Equal(t, bson.TypeBinaryUUID, id.(primitive.Binary).Subtype)
+	assert.Len(t, id.(primitive.Binary).Data, 16)
+
+	require.NoError(t, db.RunCommand(ctx, bson.D{{"collMod", cName}, {"defaultIdType", "objectId"}}).Err())
+
+	assert.IsType(t, primitive.ObjectID{}, insertAndFind(t, "objectId"))
+
+	err := db.RunCommand(ctx, bson.D{{"collMod", cName}, {"defaultIdType", "int"}}).Err()
+	AssertMatchesCommandError(t, mongo.CommandError{Code: 2, Name: "BadValue"}, err)
+	assert.ErrorContains(t, err, `'defaultIdType' must be "objectId" or "uuid", not "int"`)
+
+	err = db.RunCommand(ctx, bson.D{{"create", cName + "_bad"}, {"defaultIdType", int32(1)}}).Err()
+	AssertMatchesCommandError(t, mongo.CommandError{Code: 14, Name: "TypeMismatch"}, err)
+}
+
+func TestCreateCollModDefaults(t *testing.T) {
+	setup.SkipForMongoDB(t, "FerretDB-specific defaults collection option")
+
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	require.NoError(t, db.CreateCollection(ctx, collection.Name()))
+
+	err := db.RunCommand(ctx, bson.D{
+		{"collMod", collection.Name()},
+		{"defaults", bson.D{
+			{"status", "active"},
+			{"name", "$first"},
+			{"total", bson.D{{"$sum", bson.A{"$price", "$tax"}}}},
+		}},
+	}).Err()
+	require.NoError(t, err)
+
+	_, err = collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"first", "Ada"}, {"price", int32(10)}, {"tax", int32(2)}},
+		bson.D{{"_id", int32(2)}, {"first", "Alan"}, {"status", "retired"}, {"name", "A. T."}},
+	})
+	require.NoError(t, err)
+
+	cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"_id", int32(1)}}))
+	require.NoError(t, err)
+
+	expected := []bson.D{
+		{
+			{"_id", int32(1)}, {"first", "Ada"}, {"price", int32(10)}, {"tax", int32(2)},
+			{"status", "active"}, {"name", "Ada"}, {"total", int32(12)},
+		},
+		{{"_id", int32(2)}, {"first", "Alan"}, {"status", "retired"}, {"name", "A. T."}, {"total", int32(0)}},
+	}
+	AssertEqualDocumentsSlice(t, expected, FetchAll(t, ctx, cursor))
+
+	require.NoError(t, db.RunCommand(ctx, bson.D{{"collMod", collection.Name()}, {"defaults", "off"}}).Err())
+
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", int32(3)}, {"first", "Grace"}})
+	require.NoError(t, err)
+
+	var doc bson.D
+	require.NoError(t, collection.FindOne(ctx, bson.D{{"_id", int32(3)}}).Decode(&doc))
+	AssertEqualDocuments(t, bson.D{{"_id", int32(3)}, {"first", "Grace"}}, doc)
+
+	err = db.RunCommand(ctx, bson.D{{"collMod", collection.Name()}, {"defaults", bson.D{{"_id", int32(1)}}}}).Err()
+	assert.ErrorContains(t, err, "invalid default field name")
+}
@@ -23,6 +23,9 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 
 	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
 )
 
 func TestExplainCommandQueryErrors(t *testing.T) {
@@ -90,6 +93,28 @@ func TestExplainCommandQueryErrors(t *testing.T) {
 				Message: "BSON field 'skip' value must be >= 0, actual value '-1'",
 			},
 		},
+		"VerbosityType": {
+			command: bson.D{
+				{"explain", bson.D{{"find", collection.Name()}}},
+				{"verbosity", int32(1)},
+			},
+			err: &mongo.CommandError{
+				Code:    14,
+				Name:    "TypeMismatch",
+				Message: "BSON field 'explain.verbosity' is the wrong type 'int', expected type 'string'",
+			},
+		},
+		"VerbosityUnknown": {
+			command: bson.D{
+				{"explain", bson.D{{"find", collection.Name()}}},
+				{"verbosity", "all"},
+			},
+			err: &mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: "verbosity string must be one of {'queryPlanner', 'executionStats', 'allPlansExecution'}",
+			},
+		},
 	} {
 		name, tc := name, tc
 		t.Run(name, func(t *testing.T) {
@@ -143,3 +168,69 @@ func TestExplainLimitInt(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, res)
 }
+
+func TestExplainQueryPlanner(t *testing.T) {
+	setup.SkipForMongoDB(t, "FerretDB builds simplified query plans")
+
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{bson.D{{"_id", "a"}}, bson.D{{"_id", "b"}}})
+	require.NoError(t, err)
+
+	expectedPushdown := !setup.FilterPushdownDisabled()
+
+	t.Run("Find", func(t *testing.T) {
+		t.Parallel()
+
+		var res bson.D
+		err := collection.Database().RunCommand(ctx, bson.D{
+			{"explain", bson.D{
+				{"find", collection.Name()},
+				{"filter", bson.D{{"_id", "a"}}},
+				{"sort", bson.D{{"v", int32(1)}}},
+				{"limit", int64(1)},
+			}},
+			{"verbosity", "queryPlanner"},
+		}).Decode(&res)
+		require.NoError(t, err)
+
+		expected := ConvertDocument(t, bson.D{
+			{"namespace", collection.Database().Name() + "." + collection.Name()},
+			{"indexFilterSet", false},
+			{"parsedQuery", bson.D{{"_id", "a"}}},
+			{"winningPlan", bson.D{
+				{"stage", "LIMIT"},
+				{"limitAmount", int64(1)},
+				{"inputStage", bson.D{
+					{"stage", "SORT"},
+					{"sortPattern", bson.D{{"v", int32(1)}}},
+					{"inputStage", bson.D{{"stage", "COLLSCAN"}, {"filter", bson.D{{"_id", "a"}}}, {"direction", "forward"}}},
+				}},
+			}},
+			{"rejectedPlans", bson.A{}},
+		})
+
+		doc := ConvertDocument(t, res)
+		testutil.AssertEqual(t, expected, must.NotFail(doc.Get("queryPlanner")).(*types.Document))
+		assert.True(t, doc.Has("backendPlan"))
+		assert.Equal(t, expectedPushdown, must.NotFail(doc.Get("pushdown")))
+	})
+
+	t.Run("Count", func(t *testing.T) {
+		t.Parallel()
+
+		// count uses `query` for the filter
+		var res bson.D
+		err := collection.Database().RunCommand(ctx, bson.D{
+			{"explain", bson.D{{"count", collection.Name()}, {"query", bson.D{{"_id", "b"}}}}},
+		}).Decode(&res)
+		require.NoError(t, err)
+
+		doc := ConvertDocument(t, res)
+		planner := must.NotFail(doc.Get("queryPlanner")).(*types.Document)
+		assert.Equal(t, "COUNT", must.NotFail(must.NotFail(planner.Get("winningPlan")).(*types.Document).Get("stage")))
+		assert.Equal(t, expectedPushdown, must.NotFail(doc.Get("pushdown")))
+	})
+}
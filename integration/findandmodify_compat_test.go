@@ -774,6 +774,56 @@ func TestFindAndModifyCompatRemove(t *testing.T) {
 	testFindAndModifyCompat(t, testCases)
 }
 
+func TestFindAndModifyCompatFields(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]findAndModifyCompatTestCase{
+		"SortIncNew": {
+			command: bson.D{
+				{"query", bson.D{{"v", bson.D{{"$gt", int32(0)}}}}},
+				{"sort", bson.D{{"v", -1}, {"_id", 1}}},
+				{"update", bson.D{{"$inc", bson.D{{"v", int32(10)}}}}},
+				{"new", true},
+				{"fields", bson.D{{"_id", false}, {"v", true}}},
+			},
+		},
+		"UpsertNew": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "upsert-fields"}}},
+				{"update", bson.D{{"v", int32(3)}, {"w", "a"}}},
+				{"upsert", true},
+				{"new", true},
+				{"fields", bson.D{{"w", false}}},
+			},
+		},
+		"Remove": {
+			command: bson.D{
+				{"query", bson.D{}},
+				{"remove", true},
+				{"fields", bson.D{{"v", false}}},
+			},
+		},
+	}
+
+	testFindAndModifyCompat(t, testCases)
+}
+
+func TestFindAndModifyCompatArrayFilters(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]findAndModifyCompatTestCase{
+		"Mul": {
+			command: bson.D{
+				{"query", bson.D{{"v", bson.D{{"$elemMatch", bson.D{{"$lt", int32(90)}}}}}}},
+				{"update", bson.D{{"$mul", bson.D{{"v.$[low]", int32(0)}}}}},
+				{"arrayFilters", bson.A{bson.D{{"low", bson.D{{"$lt", int32(90)}}}}}},
+			},
+		},
+	}
+
+	testFindAndModifyCompat(t, testCases)
+}
+
 // findAndModifyCompatTestCase describes findAndModify compatibility test case.
 type findAndModifyCompatTestCase struct {
 	command bson.D
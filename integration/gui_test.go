@@ -0,0 +1,160 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+)
+
+// guiCommand represents a single command sent by GUI client.
+type guiCommand struct {
+	name    string
+	admin   bool // run against admin database
+	command bson.D
+	check   func(t *testing.T, res bson.D) // optional
+}
+
+// TestGUIClients replays commands that Mongo Express and Studio 3T send
+// when their server status, database and collection pages are opened.
+//
+// Commands were recorded from clients' traffic, with namespaces replaced.
+func TestGUIClients(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t, shareddata.Scalars, shareddata.Composites)
+
+	db := collection.Database()
+	admin := db.Client().Database("admin")
+	dbName := db.Name()
+	cName := collection.Name()
+
+	for client, commands := range map[string][]guiCommand{
+		"MongoExpress": {
+			{
+				name:    "ServerStatus",
+				admin:   true,
+				command: bson.D{{"serverStatus", 1}},
+				check: func(t *testing.T, res bson.D) {
+					m := res.Map()
+
+					for _, section := range []string{"connections", "mem", "network", "opcounters"} {
+						assert.IsType(t, bson.D{}, m[section], section)
+					}
+
+					assert.Contains(t, m["connections"].(bson.D).Map(), "current")
+					assert.Contains(t, m["opcounters"].(bson.D).Map(), "query")
+				},
+			},
+			{
+				name:    "ListDatabases",
+				admin:   true,
+				command: bson.D{{"listDatabases", 1}},
+			},
+			{
+				name:    "DBStats",
+				command: bson.D{{"dbStats", 1}, {"scale", 1}},
+			},
+			{
+				name:    "ListCollections",
+				command: bson.D{{"listCollections", 1}, {"filter", bson.D{}}, {"nameOnly", true}},
+			},
+			{
+				name:    "CollStats",
+				command: bson.D{{"collStats", cName}},
+			},
+			{
+				name:    "Find",
+				command: bson.D{{"find", cName}, {"filter", bson.D{}}, {"sort", bson.D{{"_id", -1}}}, {"skip", 0}, {"limit", 10}},
+			},
+			{
+				name:    "Count",
+				command: bson.D{{"count", cName}, {"query", bson.D{}}},
+			},
+		},
+		"Studio3T": {
+			{
+				name:    "ServerStatusSections",
+				admin:   true,
+				command: bson.D{{"serverStatus", 1}, {"metrics", 0}, {"repl", 0}, {"locks", 0}},
+				check: func(t *testing.T, res bson.D) {
+					assert.NotContains(t, res.Map(), "metrics")
+				},
+			},
+			{
+				name:    "Top",
+				admin:   true,
+				command: bson.D{{"top", 1}},
+				check: func(t *testing.T, res bson.D) {
+					totals := res.Map()["totals"].(bson.D).Map()
+					assert.Contains(t, totals, dbName+"."+cName)
+				},
+			},
+			{
+				name:    "DBStatsScaled",
+				command: bson.D{{"dbStats", 1}, {"scale", 1024}},
+				check: func(t *testing.T, res bson.D) {
+					assert.Equal(t, float64(1024), res.Map()["scaleFactor"])
+				},
+			},
+			{
+				name: "ListCollectionsFilter",
+				command: bson.D{
+					{"listCollections", 1},
+					{"filter", bson.D{{"type", "collection"}, {"options.capped", bson.D{{"$ne", true}}}}},
+					{"nameOnly", false},
+					{"authorizedCollections", true},
+				},
+				check: func(t *testing.T, res bson.D) {
+					cursor := res.Map()["cursor"].(bson.D).Map()
+					assert.NotEmpty(t, cursor["firstBatch"])
+				},
+			},
+			{
+				name:    "ListIndexes",
+				command: bson.D{{"listIndexes", cName}, {"cursor", bson.D{}}},
+			},
+		},
+	} {
+		client, commands := client, commands
+
+		t.Run(client, func(t *testing.T) {
+			t.Parallel()
+
+			// run sequentially, in the order clients send commands
+			for _, c := range commands {
+				target := db
+				if c.admin {
+					target = admin
+				}
+
+				var res bson.D
+				err := target.RunCommand(ctx, c.command).Decode(&res)
+				require.NoError(t, err, c.name)
+				assert.Equal(t, float64(1), res.Map()["ok"], c.name)
+
+				if c.check != nil {
+					c.check(t, res)
+				}
+			}
+		})
+	}
+}
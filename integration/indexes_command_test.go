@@ -21,6 +21,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/FerretDB/FerretDB/integration/setup"
 	"github.com/FerretDB/FerretDB/integration/shareddata"
@@ -62,6 +63,85 @@ func TestListIndexesCommandNonExistentNS(t *testing.T) {
 	AssertEqualCommandError(t, expected, err)
 }
 
+// TestListIndexesCommandBatchSize tests that listIndexes returns index specifications
+// in multiple batches.
+func TestListIndexesCommandBatchSize(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{"z", 1}, {"a", -1}},
+			// simple collation is the default one, so it is not returned by listIndexes
+			Options: options.Index().SetName("compound").SetCollation(&options.Collation{Locale: "simple"}),
+		},
+		{Keys: bson.D{{"u", -1}}, Options: options.Index().SetName("unique").SetUnique(true)},
+		{
+			Keys:    bson.D{{"v", 1}},
+			Options: options.Index().SetName("partial").SetPartialFilterExpression(bson.D{{"v", bson.D{{"$gt", int32(42)}}}}),
+		},
+	})
+	require.NoError(t, err)
+
+	var res bson.D
+	err = collection.Database().RunCommand(ctx, bson.D{
+		{"listIndexes", collection.Name()},
+		{"cursor", bson.D{{"batchSize", int32(1)}}},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	cursor := res.Map()["cursor"].(bson.D).Map()
+	assert.NotZero(t, cursor["id"])
+	assert.Len(t, cursor["firstBatch"], 1)
+
+	c, err := collection.Indexes().List(ctx, options.ListIndexes().SetBatchSize(1))
+	require.NoError(t, err)
+
+	var specs []bson.D
+	require.NoError(t, c.All(ctx, &specs))
+
+	expected := map[string]bson.D{
+		"_id_":     {{"v", int32(2)}, {"key", bson.D{{"_id", int32(1)}}}, {"name", "_id_"}},
+		"compound": {{"v", int32(2)}, {"key", bson.D{{"z", int32(1)}, {"a", int32(-1)}}}, {"name", "compound"}},
+		"unique":   {{"v", int32(2)}, {"key", bson.D{{"u", int32(-1)}}}, {"name", "unique"}, {"unique", true}},
+		"partial": {
+			{"v", int32(2)},
+			{"key", bson.D{{"v", int32(1)}}},
+			{"name", "partial"},
+			{"partialFilterExpression", bson.D{{"v", bson.D{{"$gt", int32(42)}}}}},
+		},
+	}
+
+	require.Len(t, specs, len(expected))
+
+	for _, spec := range specs {
+		name := spec.Map()["name"].(string)
+		AssertEqualDocuments(t, expected[name], spec)
+	}
+
+	res = nil
+	err = collection.Database().RunCommand(ctx, bson.D{
+		{"listIndexes", collection.Name()},
+		{"cursor", bson.D{{"batchSize", int32(len(expected))}}},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	cursor = res.Map()["cursor"].(bson.D).Map()
+	assert.Equal(t, int64(0), cursor["id"])
+	assert.Len(t, cursor["firstBatch"], len(expected))
+
+	err = collection.Database().RunCommand(ctx, bson.D{
+		{"listIndexes", collection.Name()},
+		{"cursor", int32(1)},
+	}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    14,
+		Name:    "TypeMismatch",
+		Message: "BSON field 'listIndexes.cursor' is the wrong type 'int', expected type 'object'",
+	}, err)
+}
+
 func TestDropIndexesCommandErrors(t *testing.T) {
 	t.Parallel()
 
@@ -597,3 +677,36 @@ func TestDropIndexesCommandInvalidCollection(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateIndexesCommandUniqueViolations(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{"v", int32(1)}},
+		Options: options.Index().SetName("v_1").SetUnique(true),
+	})
+	require.NoError(t, err)
+
+	_, err = collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "a"}, {"v", int32(1)}},
+		bson.D{{"_id", "b"}, {"v", int32(2)}},
+	})
+	require.NoError(t, err)
+
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", "c"}, {"v", int32(1)}})
+	assert.True(t, mongo.IsDuplicateKeyError(err), "%v", err)
+
+	_, err = collection.UpdateOne(ctx, bson.D{{"_id", "b"}}, bson.D{{"$set", bson.D{{"v", int32(1)}}}})
+	assert.True(t, mongo.IsDuplicateKeyError(err), "%v", err)
+
+	err = collection.FindOneAndUpdate(ctx, bson.D{{"_id", "b"}}, bson.D{{"$set", bson.D{{"v", int32(1)}}}}).Err()
+	assert.True(t, mongo.IsDuplicateKeyError(err), "%v", err)
+	assert.ErrorContains(t, err, "E11000 duplicate key error collection: "+collection.Database().Name()+"."+collection.Name())
+
+	// the document is not modified
+	var doc bson.D
+	require.NoError(t, collection.FindOne(ctx, bson.D{{"_id", "b"}}).Decode(&doc))
+	AssertEqualDocuments(t, bson.D{{"_id", "b"}, {"v", int32(2)}}, doc)
+}
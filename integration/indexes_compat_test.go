@@ -141,6 +141,27 @@ func TestCreateIndexesCompat(tt *testing.T) {
 				},
 			},
 		},
+		"PartialFilterExpression": {
+			models: []mongo.IndexModel{
+				{
+					Keys: bson.D{{"v", 1}},
+					Options: options.Index().SetPartialFilterExpression(bson.D{
+						{"v", bson.D{{"$gte", 42}}},
+						{"foo", bson.D{{"$exists", true}}},
+					}),
+				},
+			},
+		},
+		"PartialFilterExpressionAnd": {
+			models: []mongo.IndexModel{
+				{
+					Keys: bson.D{{"v", 1}},
+					Options: options.Index().SetPartialFilterExpression(bson.D{
+						{"$and", bson.A{bson.D{{"foo", "bar"}}, bson.D{{"v", bson.D{{"$lt", 10}}}}}},
+					}),
+				},
+			},
+		},
 
 		"MultiDirectionDifferentIndexes": {
 			models: []mongo.IndexModel{
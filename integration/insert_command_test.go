@@ -22,6 +22,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/FerretDB/FerretDB/integration/setup"
 	"github.com/FerretDB/FerretDB/integration/shareddata"
@@ -195,3 +196,53 @@ func TestInsertIDDifferentTypes(t *testing.T) {
 		err,
 	)
 }
+
+func TestInsertCommandSkipDuplicates(t *testing.T) {
+	setup.SkipForMongoDB(t, "FerretDB-specific skipDuplicates insert option")
+
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{"v", int32(1)}},
+		Options: options.Index().SetName("v_1").SetUnique(true),
+	})
+	require.NoError(t, err)
+
+	var res bson.D
+	err = collection.Database().RunCommand(ctx, bson.D{
+		{"insert", collection.Name()},
+		{"documents", bson.A{bson.D{{"_id", "a"}, {"v", int32(1)}}, bson.D{{"_id", "b"}, {"v", int32(2)}}}},
+	}).Decode(&res)
+	require.NoError(t, err)
+	assert.NotContains(t, res.Map(), "nSkipped")
+
+	res = nil
+	err = collection.Database().RunCommand(ctx, bson.D{
+		{"insert", collection.Name()},
+		{"documents", bson.A{
+			bson.D{{"_id", "a"}, {"v", int32(3)}},
+			bson.D{{"_id", "c"}, {"v", int32(2)}},
+			bson.D{{"_id", "d"}, {"v", int32(4)}},
+			bson.D{{"_id", "d"}, {"v", int32(5)}},
+		}},
+		{"skipDuplicates", true},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	m := res.Map()
+	assert.NotContains(t, m, "writeErrors")
+	assert.Equal(t, int32(1), m["n"])
+	assert.Equal(t, int32(3), m["nSkipped"])
+
+	cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"_id", int32(1)}}))
+	require.NoError(t, err)
+
+	expected := []bson.D{
+		{{"_id", "a"}, {"v", int32(1)}},
+		{{"_id", "b"}, {"v", int32(2)}},
+		{{"_id", "d"}, {"v", int32(4)}},
+	}
+	AssertEqualDocumentsSlice(t, expected, FetchAll(t, ctx, cursor))
+}
@@ -0,0 +1,384 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+// odmCommand represents a single command sent by ODM or ORM.
+type odmCommand struct {
+	feature string
+	admin   bool // run against admin database
+	session bool // run in explicit session, so lsid is sent
+	command bson.D
+}
+
+// TestODMCompatibility replays commands that minimal Prisma, Mongoose and Spring Data applications send
+// when they create a schema, then create, read, update and delete a single entity.
+//
+// Commands were recorded from applications' traffic, with namespaces and identifiers replaced.
+// The compatibility matrix is logged at the end.
+func TestODMCompatibility(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	db := collection.Database()
+	admin := db.Client().Database("admin")
+	cName := collection.Name()
+
+	id := primitive.NewObjectID()
+
+	odms := map[string][]odmCommand{
+		"Prisma": {
+			{
+				feature: "createIndexes",
+				command: bson.D{
+					{"createIndexes", cName + "_prisma"},
+					{"indexes", bson.A{bson.D{
+						{"key", bson.D{{"email", int32(1)}}},
+						{"unique", true},
+						{"name", "User_email_key"},
+					}}},
+				},
+			},
+			{
+				feature: "insert",
+				session: true,
+				command: bson.D{
+					{"insert", cName + "_prisma"},
+					{"documents", bson.A{bson.D{{"_id", id}, {"email", "alice@example.com"}, {"name", "Alice"}}}},
+					{"ordered", true},
+				},
+			},
+			{
+				feature: "aggregate",
+				session: true,
+				command: bson.D{
+					{"aggregate", cName + "_prisma"},
+					{"pipeline", bson.A{
+						bson.D{{"$match", bson.D{{"email", bson.D{{"$eq", "alice@example.com"}}}}}},
+						bson.D{{"$limit", int32(1)}},
+						bson.D{{"$project", bson.D{{"_id", int32(1)}, {"email", int32(1)}, {"name", int32(1)}}}},
+					}},
+					{"cursor", bson.D{}},
+				},
+			},
+			{
+				feature: "findAndModify",
+				session: true,
+				command: bson.D{
+					{"findAndModify", cName + "_prisma"},
+					{"query", bson.D{{"_id", id}}},
+					{"update", bson.D{{"$set", bson.D{{"name", "Alice Smith"}}}}},
+					{"new", true},
+				},
+			},
+			{
+				feature: "delete",
+				session: true,
+				command: bson.D{
+					{"delete", cName + "_prisma"},
+					{"deletes", bson.A{bson.D{{"q", bson.D{{"_id", id}}}, {"limit", int32(1)}}}},
+					{"ordered", true},
+				},
+			},
+		},
+		"Mongoose": {
+			{
+				feature: "createIndexes",
+				command: bson.D{
+					{"createIndexes", cName + "_mongoose"},
+					{"indexes", bson.A{bson.D{
+						{"key", bson.D{{"email", int32(1)}}},
+						{"name", "email_1"},
+						{"unique", true},
+						{"background", true},
+					}}},
+				},
+			},
+			{
+				feature: "insert",
+				session: true,
+				command: bson.D{
+					{"insert", cName + "_mongoose"},
+					{"documents", bson.A{bson.D{{"email", "bob@example.com"}, {"_id", id}, {"__v", int32(0)}}}},
+				},
+			},
+			{
+				feature: "find",
+				session: true,
+				command: bson.D{
+					{"find", cName + "_mongoose"},
+					{"filter", bson.D{{"email", "bob@example.com"}}},
+					{"projection", bson.D{}},
+					{"limit", int32(1)},
+					{"singleBatch", true},
+					{"batchSize", int32(1)},
+				},
+			},
+			{
+				feature: "countDocuments",
+				command: bson.D{
+					{"aggregate", cName + "_mongoose"},
+					{"pipeline", bson.A{
+						bson.D{{"$match", bson.D{}}},
+						bson.D{{"$group", bson.D{{"_id", int32(1)}, {"n", bson.D{{"$sum", int32(1)}}}}}},
+					}},
+					{"cursor", bson.D{}},
+				},
+			},
+			{
+				feature: "update",
+				session: true,
+				command: bson.D{
+					{"update", cName + "_mongoose"},
+					{"updates", bson.A{bson.D{
+						{"q", bson.D{{"_id", id}}},
+						{"u", bson.D{{"$set", bson.D{{"email", "bob@example.org"}}}, {"$inc", bson.D{{"__v", int32(1)}}}}},
+					}}},
+				},
+			},
+			{
+				feature: "endSessions",
+				admin:   true,
+				command: bson.D{{"endSessions", bson.A{bson.D{{"id", primitive.Binary{Subtype: 4, Data: make([]byte, 16)}}}}}},
+			},
+		},
+		"SpringData": {
+			{
+				feature: "createIndexes",
+				command: bson.D{
+					{"createIndexes", cName + "_spring"},
+					{"indexes", bson.A{bson.D{
+						{"key", bson.D{{"lastName", int32(1)}}},
+						{"name", "lastName"},
+						{"unique", false},
+						{"sparse", false},
+						{"background", false},
+						{"v", int32(2)},
+					}}},
+				},
+			},
+			{
+				feature: "insert",
+				session: true,
+				command: bson.D{
+					{"insert", cName + "_spring"},
+					{"documents", bson.A{bson.D{{"_id", id}, {"lastName", "Carol"}, {"_class", "com.example.Person"}}}},
+					{"ordered", true},
+				},
+			},
+			{
+				feature: "find with let",
+				session: true,
+				command: bson.D{
+					{"find", cName + "_spring"},
+					{"filter", bson.D{{"lastName", "Carol"}}},
+					{"let", bson.D{{"minAge", int32(18)}}},
+				},
+			},
+			{
+				feature: "aggregate with let",
+				command: bson.D{
+					{"aggregate", cName + "_spring"},
+					{"pipeline", bson.A{bson.D{{"$match", bson.D{{"lastName", "Carol"}}}}}},
+					{"let", bson.D{}},
+					{"cursor", bson.D{}},
+				},
+			},
+			{
+				feature: "update with let",
+				session: true,
+				command: bson.D{
+					{"update", cName + "_spring"},
+					{"updates", bson.A{bson.D{
+						{"q", bson.D{{"_id", id}}},
+						{"u", bson.D{{"$set", bson.D{{"lastName", "Carroll"}}}}},
+					}}},
+					{"let", bson.D{}},
+				},
+			},
+			{
+				feature: "delete with let",
+				session: true,
+				command: bson.D{
+					{"delete", cName + "_spring"},
+					{"deletes", bson.A{bson.D{{"q", bson.D{{"_id", id}}}, {"limit", int32(0)}}}},
+					{"let", bson.D{}},
+				},
+			},
+		},
+	}
+
+	// ODM -> feature -> result
+	matrix := make(map[string]map[string]bool, len(odms))
+
+	for odm, commands := range odms {
+		matrix[odm] = make(map[string]bool, len(commands))
+
+		// run sequentially, in the order applications send commands
+		for _, c := range commands {
+			c := c
+
+			matrix[odm][c.feature] = t.Run(odm+"/"+c.feature, func(t *testing.T) {
+				target := db
+				if c.admin {
+					target = admin
+				}
+
+				var res bson.D
+
+				if !c.session {
+					require.NoError(t, target.RunCommand(ctx, c.command).Decode(&res))
+					assert.Equal(t, float64(1), res.Map()["ok"])
+
+					return
+				}
+
+				sess, err := db.Client().StartSession()
+				require.NoError(t, err)
+
+				defer sess.EndSession(ctx)
+
+				err = mongo.WithSession(ctx, sess, func(sctx mongo.SessionContext) error {
+					return target.RunCommand(sctx, c.command).Decode(&res)
+				})
+				require.NoError(t, err)
+				assert.Equal(t, float64(1), res.Map()["ok"])
+			})
+		}
+	}
+
+	t.Log("\n" + formatODMMatrix(matrix))
+}
+
+// TestODMEdgeCases checks replies that ODMs rely on besides successful commands.
+func TestODMEdgeCases(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	t.Run("Hello", func(t *testing.T) {
+		t.Parallel()
+
+		var res bson.D
+		require.NoError(t, collection.Database().RunCommand(ctx, bson.D{{"hello", int32(1)}}).Decode(&res))
+		assert.Equal(t, int32(30), res.Map()["logicalSessionTimeoutMinutes"])
+	})
+
+	t.Run("LetType", func(t *testing.T) {
+		t.Parallel()
+
+		err := collection.Database().RunCommand(ctx, bson.D{
+			{"aggregate", collection.Name()},
+			{"pipeline", bson.A{}},
+			{"let", int32(1)},
+			{"cursor", bson.D{}},
+		}).Err()
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    14,
+			Name:    "TypeMismatch",
+			Message: "BSON field 'aggregate.let' is the wrong type 'int', expected type 'object'",
+		}, err)
+	})
+
+	t.Run("IndexOptions", func(t *testing.T) {
+		setup.SkipForMongoDB(t, "MongoDB supports all index options")
+
+		t.Parallel()
+
+		for name, tc := range map[string]struct { //nolint:vet // used for testing only
+			opt   string
+			value any
+			err   mongo.CommandError
+		}{
+			"Sparse": {
+				opt:   "sparse",
+				value: true,
+				err: mongo.CommandError{
+					Code:    238,
+					Name:    "NotImplemented",
+					Message: `Index option "sparse" is not implemented yet`,
+				},
+			},
+			"Version": {
+				opt:   "v",
+				value: int32(1),
+				err: mongo.CommandError{
+					Code:    238,
+					Name:    "NotImplemented",
+					Message: "Index version 1 is not implemented yet",
+				},
+			},
+		} {
+			name, tc := name, tc
+
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				err := collection.Database().RunCommand(ctx, bson.D{
+					{"createIndexes", collection.Name()},
+					{"indexes", bson.A{bson.D{{"key", bson.D{{"v", int32(1)}}}, {"name", "v_1"}, {tc.opt, tc.value}}}},
+				}).Err()
+				AssertEqualCommandError(t, tc.err, err)
+			})
+		}
+	})
+}
+
+// formatODMMatrix returns a compatibility matrix as a text table.
+func formatODMMatrix(matrix map[string]map[string]bool) string {
+	odms := make([]string, 0, len(matrix))
+	for odm := range matrix {
+		odms = append(odms, odm)
+	}
+
+	sort.Strings(odms)
+
+	var sb strings.Builder
+
+	for _, odm := range odms {
+		features := make([]string, 0, len(matrix[odm]))
+		for f := range matrix[odm] {
+			features = append(features, f)
+		}
+
+		sort.Strings(features)
+
+		for _, f := range features {
+			res := "PASS"
+			if !matrix[odm][f] {
+				res = "FAIL"
+			}
+
+			fmt.Fprintf(&sb, "%-12s %-20s %s\n", odm, f, res)
+		}
+	}
+
+	return sb.String()
+}
@@ -298,6 +298,27 @@ func TestQueryArrayCompatAll(t *testing.T) {
 		"Nil": {
 			filter: bson.D{{"v", bson.D{{"$all", bson.A{nil}}}}},
 		},
+		"ElemMatch": {
+			filter: bson.D{{"v", bson.D{{"$all", bson.A{
+				bson.D{{"$elemMatch", bson.D{{"$gt", int32(41)}}}},
+				bson.D{{"$elemMatch", bson.D{{"$lt", int32(44)}}}},
+			}}}}},
+		},
+		"ElemMatchNotFound": {
+			filter: bson.D{{"v", bson.D{{"$all", bson.A{
+				bson.D{{"$elemMatch", bson.D{{"$gt", int32(41)}}}},
+				bson.D{{"$elemMatch", bson.D{{"$eq", "not-found"}}}},
+			}}}}},
+			resultType: emptyResult,
+		},
+		"ElemMatchMixed": {
+			filter:     bson.D{{"v", bson.D{{"$all", bson.A{bson.D{{"$elemMatch", bson.D{{"$gt", int32(41)}}}}, int32(42)}}}}},
+			resultType: emptyResult,
+		},
+		"Operator": {
+			filter:     bson.D{{"v", bson.D{{"$all", bson.A{bson.D{{"$gt", int32(41)}}}}}}},
+			resultType: emptyResult,
+		},
 		"NilRepeated": {
 			filter: bson.D{{"v", bson.D{{"$all", bson.A{nil, nil, nil}}}}},
 		},
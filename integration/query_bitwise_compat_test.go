@@ -0,0 +1,62 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestQueryBitwiseCompat(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]queryCompatTestCase{}
+
+	for _, op := range []string{"$bitsAllClear", "$bitsAllSet", "$bitsAnyClear", "$bitsAnySet"} {
+		for name, tc := range map[string]struct {
+			mask       any
+			resultType compatTestCaseResultType
+		}{
+			"Int":          {mask: int32(42)},
+			"Long":         {mask: int64(1 << 40)},
+			"Double":       {mask: float64(13)},
+			"Zero":         {mask: int32(0)},
+			"Positions":    {mask: bson.A{int32(1), int32(5)}},
+			"HighPosition": {mask: bson.A{int32(63)}},
+			"SignExtended": {mask: bson.A{int32(64), int32(200)}},
+			"Binary":       {mask: primitive.Binary{Data: []byte{42, 0, 13}}},
+			"BinaryLong":   {mask: primitive.Binary{Data: []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 1}}},
+			"BinaryEmpty":  {mask: primitive.Binary{Data: []byte{}}},
+			"EmptyPositions": {
+				mask: bson.A{},
+			},
+		} {
+			if (op == "$bitsAnyClear" || op == "$bitsAnySet") &&
+				(name == "Zero" || name == "BinaryEmpty" || name == "EmptyPositions") {
+				// no bits to check, nothing matches
+				tc.resultType = emptyResult
+			}
+
+			testCases[op[1:]+name] = queryCompatTestCase{
+				filter:     bson.D{{"v", bson.D{{op, tc.mask}}}},
+				resultType: tc.resultType,
+			}
+		}
+	}
+
+	testQueryCompat(t, testCases)
+}
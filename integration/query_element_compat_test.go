@@ -45,6 +45,17 @@ func TestQueryElementCompatExist(t *testing.T) {
 		"NonBool": {
 			filter: bson.D{{"_id", bson.D{{"$exists", -123}}}},
 		},
+		"NonBoolZero": {
+			filter:     bson.D{{"_id", bson.D{{"$exists", 0}}}},
+			resultType: emptyResult,
+		},
+		"NonBoolNull": {
+			filter:     bson.D{{"_id", bson.D{{"$exists", nil}}}},
+			resultType: emptyResult,
+		},
+		"NonBoolString": {
+			filter: bson.D{{"v", bson.D{{"$exists", "false"}}}},
+		},
 	}
 
 	testQueryCompat(t, testCases)
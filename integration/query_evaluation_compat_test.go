@@ -264,6 +264,14 @@ func TestQueryEvaluationCompatMod(t *testing.T) {
 		"RemainderSmallestNonzeroFloat64": {
 			filter: bson.D{{"v", bson.D{{"$mod", bson.A{23456789, math.SmallestNonzeroFloat64}}}}},
 		},
+		"NotArray": {
+			filter:     bson.D{{"v", bson.D{{"$mod", int32(4)}}}},
+			resultType: emptyResult,
+		},
+		"Document": {
+			filter:     bson.D{{"v", bson.D{{"$mod", bson.D{{"divisor", 4}, {"remainder", 0}}}}}},
+			resultType: emptyResult,
+		},
 		"EmptyArray": {
 			filter:     bson.D{{"v", bson.D{{"$mod", bson.A{}}}}},
 			resultType: emptyResult,
@@ -388,7 +396,51 @@ func TestQueryEvaluationCompatExpr(t *testing.T) {
 		},
 		"Gt": {
 			filter: bson.D{{"$expr", bson.D{{"$gt", bson.A{"$v", 2}}}}},
-			skip:   "https://github.com/FerretDB/FerretDB/issues/1456",
+		},
+		"GtFields": {
+			filter: bson.D{{"$expr", bson.D{{"$gt", bson.A{"$v", "$_id"}}}}},
+		},
+		"GteString": {
+			filter: bson.D{{"$expr", bson.D{{"$gte", bson.A{"$v", "foo"}}}}},
+		},
+		"Lt": {
+			filter: bson.D{{"$expr", bson.D{{"$lt", bson.A{"$v", int64(0)}}}}},
+		},
+		"LteNull": {
+			filter: bson.D{{"$expr", bson.D{{"$lte", bson.A{"$v", nil}}}}},
+		},
+		"Eq": {
+			filter: bson.D{{"$expr", bson.D{{"$eq", bson.A{"$v", 42.0}}}}},
+		},
+		"EqArray": {
+			filter: bson.D{{"$expr", bson.D{{"$eq", bson.A{"$v", bson.A{int32(42), "foo", nil}}}}}},
+		},
+		"EqFields": {
+			filter: bson.D{{"$expr", bson.D{{"$eq", bson.A{"$v", "$v"}}}}},
+		},
+		"Ne": {
+			filter: bson.D{{"$expr", bson.D{{"$ne", bson.A{"$v", int32(42)}}}}},
+		},
+		"Cmp": {
+			filter: bson.D{{"$expr", bson.D{{"$cmp", bson.A{"$v", int32(42)}}}}},
+		},
+		"NestedGt": {
+			filter: bson.D{{"$expr", bson.D{{"$gt", bson.A{bson.D{{"$sum", "$v"}}, int32(1)}}}}},
+		},
+		"And": {
+			filter: bson.D{{"$expr", bson.D{{"$and", bson.A{
+				bson.D{{"$gt", bson.A{"$v", int32(0)}}},
+				bson.D{{"$lt", bson.A{"$v", int32(100)}}},
+			}}}}},
+		},
+		"Or": {
+			filter: bson.D{{"$expr", bson.D{{"$or", bson.A{
+				bson.D{{"$eq", bson.A{"$v", "foo"}}},
+				bson.D{{"$eq", bson.A{"$v", int32(42)}}},
+			}}}}},
+		},
+		"Not": {
+			filter: bson.D{{"$expr", bson.D{{"$not", bson.A{bson.D{{"$eq", bson.A{"$v", int32(42)}}}}}}}},
 		},
 	}
 
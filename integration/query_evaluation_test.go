@@ -189,7 +189,6 @@ func TestQueryEvaluationExprErrors(t *testing.T) {
 				Name:    "Location16020",
 				Message: "Expression $gt takes exactly 2 arguments. 1 were passed in.",
 			},
-			skip: "https://github.com/FerretDB/FerretDB/issues/1456",
 		},
 		"GtOneParameter": {
 			filter: bson.D{{"$expr", bson.D{{"$gt", bson.A{1}}}}},
@@ -198,7 +197,6 @@ func TestQueryEvaluationExprErrors(t *testing.T) {
 				Name:    "Location16020",
 				Message: "Expression $gt takes exactly 2 arguments. 1 were passed in.",
 			},
-			skip: "https://github.com/FerretDB/FerretDB/issues/1456",
 		},
 		"GtThreeParameters": {
 			filter: bson.D{{"$expr", bson.D{{"$gt", bson.A{1, 2, 3}}}}},
@@ -207,7 +205,6 @@ func TestQueryEvaluationExprErrors(t *testing.T) {
 				Name:    "Location16020",
 				Message: "Expression $gt takes exactly 2 arguments. 3 were passed in.",
 			},
-			skip: "https://github.com/FerretDB/FerretDB/issues/1456",
 		},
 	} {
 		name, tc := name, tc
@@ -225,3 +222,135 @@ func TestQueryEvaluationExprErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryEvaluationText(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"title", "Coffee shop"}, {"tags", bson.A{"cake"}}},
+		bson.D{{"_id", int32(2)}, {"title", "Coffee and cake shop"}},
+		bson.D{{"_id", int32(3)}, {"title", "Tea shop"}},
+		bson.D{{"_id", int32(4)}, {"title", int32(42)}},
+	})
+	require.NoError(t, err)
+
+	find := func(t *testing.T, text bson.D) ([]bson.D, error) {
+		t.Helper()
+
+		opts := options.Find().
+			SetSort(bson.D{{"_id", int32(1)}}).
+			SetProjection(bson.D{{"_id", int32(1)}, {"score", bson.D{{"$meta", "textScore"}}}})
+
+		cursor, err := collection.Find(ctx, bson.D{{"$text", text}}, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		return FetchAll(t, ctx, cursor), nil
+	}
+
+	_, err = find(t, bson.D{{"$search", "coffee"}})
+	AssertMatchesCommandError(t, mongo.CommandError{Code: 27, Name: "IndexNotFound"}, err)
+
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{"title", "text"}, {"tags", "text"}},
+		Options: options.Index().SetName("text_idx"),
+	})
+	require.NoError(t, err)
+
+	t.Run("ListIndexes", func(t *testing.T) {
+		cursor, err := collection.Indexes().List(ctx)
+		require.NoError(t, err)
+
+		indexes := FetchAll(t, ctx, cursor)
+		require.Len(t, indexes, 2)
+
+		m := indexes[1].Map()
+		assert.Equal(t, "text_idx", m["name"])
+		assert.Equal(t, bson.D{{"_fts", "text"}, {"_ftsx", int32(1)}}, m["key"])
+
+		if setup.IsMongoDB(t) {
+			return
+		}
+
+		expected := bson.D{
+			{"v", int32(2)},
+			{"key", bson.D{{"_fts", "text"}, {"_ftsx", int32(1)}}},
+			{"name", "text_idx"},
+			{"weights", bson.D{{"title", int32(1)}, {"tags", int32(1)}}},
+			{"default_language", "english"},
+			{"language_override", "language"},
+			{"textIndexVersion", int32(3)},
+		}
+		AssertEqualDocuments(t, expected, indexes[1])
+	})
+
+	for name, tc := range map[string]struct {
+		search   string
+		expected []any // pairs of _id and score
+	}{
+		"Term":        {search: "coffee", expected: []any{int32(1), float64(1), int32(2), float64(1)}},
+		"AnyTerm":     {search: "tea CAKE", expected: []any{int32(1), float64(1), int32(2), float64(1), int32(3), float64(1)}},
+		"Phrase":      {search: `"cake shop" coffee`, expected: []any{int32(2), float64(3)}},
+		"Negation":    {search: "shop -cake", expected: []any{int32(3), float64(1)}},
+		"OnlyNegated": {search: "-cake"},
+		"NoMatch":     {search: "42"},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			res, err := find(t, bson.D{{"$search", tc.search}})
+			require.NoError(t, err)
+
+			var ids, actual []any
+
+			for _, doc := range res {
+				m := doc.Map()
+				ids = append(ids, m["_id"])
+				actual = append(actual, m["_id"], m["score"])
+			}
+
+			var expectedIDs []any
+			for i := 0; i < len(tc.expected); i += 2 {
+				expectedIDs = append(expectedIDs, tc.expected[i])
+			}
+
+			assert.Equal(t, expectedIDs, ids)
+
+			if setup.IsMongoDB(t) {
+				// MongoDB uses a different scoring algorithm
+				return
+			}
+
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+
+	t.Run("Language", func(t *testing.T) {
+		_, err := find(t, bson.D{{"$search", "coffee"}, {"$language", "klingon"}})
+		AssertMatchesCommandError(t, mongo.CommandError{Code: 2, Name: "BadValue"}, err)
+	})
+
+	t.Run("MissingSearch", func(t *testing.T) {
+		_, err := find(t, bson.D{{"$language", "english"}})
+		AssertMatchesCommandError(t, mongo.CommandError{Code: 9, Name: "FailedToParse"}, err)
+	})
+
+	t.Run("Count", func(t *testing.T) {
+		var res bson.D
+		err := collection.Database().RunCommand(ctx, bson.D{
+			{"count", collection.Name()},
+			{"query", bson.D{{"$text", bson.D{{"$search", "shop"}}}}},
+		}).Decode(&res)
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), res.Map()["n"])
+	})
+
+	t.Run("ScoreWithoutText", func(t *testing.T) {
+		opts := options.Find().SetProjection(bson.D{{"score", bson.D{{"$meta", "textScore"}}}})
+		_, err := collection.Find(ctx, bson.D{}, opts)
+		assert.ErrorContains(t, err, "query requires text score metadata")
+	})
+}
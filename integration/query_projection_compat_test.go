@@ -225,8 +225,24 @@ func TestQueryProjectionCompat(t *testing.T) {
 func TestQueryProjectionPositionalOperatorCompat(t *testing.T) {
 	t.Parallel()
 
+	// grades contains a document with arrays of scalars and documents.
+	grades := shareddata.NewTopLevelFieldsProvider(
+		"Grades",
+		nil,
+		map[string]shareddata.Fields{
+			"grades": {
+				{Key: "grades", Value: bson.A{
+					bson.D{{"grade", int32(80)}, {"mean", int32(75)}},
+					bson.D{{"grade", int32(85)}, {"mean", int32(90)}},
+					bson.D{{"grade", int32(85)}, {"mean", int32(85)}},
+				}},
+				{Key: "scores", Value: bson.A{int32(70), int32(85), int32(90)}},
+			},
+		},
+	)
+
 	// TODO https://github.com/FerretDB/FerretDB/issues/3053
-	providers := shareddata.AllProviders().Remove(shareddata.ArrayAndDocuments)
+	providers := append(shareddata.AllProviders().Remove(shareddata.ArrayAndDocuments), grades)
 
 	testCases := map[string]queryCompatTestCase{
 		"IDFilter": {
@@ -331,6 +347,10 @@ func TestQueryProjectionPositionalOperatorCompat(t *testing.T) {
 			projection: bson.D{{"v.$", true}},
 			resultType: emptyResult,
 		},
+		"ElemMatch": {
+			filter:     bson.D{{"v", bson.D{{"$elemMatch", bson.D{{"$gt", 42}}}}}},
+			projection: bson.D{{"v.$", true}},
+		},
 		"PartialFilter": {
 			filter: bson.D{
 				{"v", bson.D{{"$gt", 42}}},
@@ -349,6 +369,211 @@ func TestQueryProjectionPositionalOperatorCompat(t *testing.T) {
 			},
 			skip: "https://github.com/FerretDB/FerretDB/issues/835",
 		},
+		"ImplicitScalars": {
+			filter:         bson.D{{"scores", int32(85)}},
+			projection:     bson.D{{"scores.$", true}},
+			resultPushdown: pgPushdown,
+		},
+		"GteScalarsExcludeID": {
+			filter:      bson.D{{"scores", bson.D{{"$gte", int32(80)}}}},
+			projection:  bson.D{{"_id", false}, {"scores.$", true}},
+			skipIDCheck: true,
+		},
+		"DotNotationDocuments": {
+			filter:     bson.D{{"grades.grade", int32(85)}},
+			projection: bson.D{{"grades.$", true}},
+		},
+		"DotNotationDocumentsGt": {
+			filter:     bson.D{{"grades.mean", bson.D{{"$gt", int32(85)}}}},
+			projection: bson.D{{"grades.$", true}},
+		},
+		"ElemMatchScalars": {
+			filter:     bson.D{{"scores", bson.D{{"$elemMatch", bson.D{{"$gt", int32(80)}, {"$lt", int32(90)}}}}}},
+			projection: bson.D{{"scores.$", true}},
+		},
+		"SizeGt": {
+			filter:         bson.D{{"scores", bson.D{{"$size", int32(3)}, {"$gt", int32(70)}}}},
+			projection:     bson.D{{"scores.$", true}},
+			resultPushdown: pgPushdown,
+		},
+	}
+
+	testQueryCompatWithProviders(t, providers, testCases)
+}
+
+func TestQueryProjectionSliceCompat(t *testing.T) {
+	t.Parallel()
+
+	// arrays contains a document with arrays of scalars and documents.
+	arrays := shareddata.NewTopLevelFieldsProvider(
+		"Arrays",
+		nil,
+		map[string]shareddata.Fields{
+			"arrays": {
+				{Key: "a", Value: bson.A{int32(1), int32(2), int32(3), int32(4)}},
+				{Key: "b", Value: "foo"},
+				{Key: "c", Value: bson.A{bson.D{{"d", bson.A{int32(1), int32(2)}}}, bson.D{{"d", int32(3)}}}},
+			},
+		},
+	)
+
+	providers := append(shareddata.AllProviders(), arrays)
+
+	testCases := map[string]queryCompatTestCase{
+		"Positive": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", int32(2)}}}},
+		},
+		"Negative": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", int64(-2)}}}},
+		},
+		"Zero": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", 0.0}}}},
+		},
+		"Large": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", int64(math.MaxInt64)}}}},
+		},
+		"SkipLimit": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", bson.A{int32(1), int32(2)}}}}},
+		},
+		"NegativeSkipLimit": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", bson.A{int32(-2), int32(1)}}}}},
+		},
+		"SkipBeyond": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", bson.A{int32(100), int32(1)}}}}},
+		},
+		"NegativeSkipBeyond": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", bson.A{int32(-100), int32(2)}}}}},
+		},
+		"Inclusion": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", int32(1)}}}, {"foo", int32(1)}},
+		},
+		"Exclusion": {
+			filter:     bson.D{},
+			projection: bson.D{{"foo", int32(0)}, {"v", bson.D{{"$slice", int32(1)}}}},
+		},
+		"ExcludeID": {
+			filter:     bson.D{},
+			projection: bson.D{{"_id", false}, {"v", bson.D{{"$slice", int32(-1)}}}},
+		},
+		"DotNotation": {
+			filter:     bson.D{},
+			projection: bson.D{{"v.foo", bson.D{{"$slice", int32(1)}}}},
+		},
+		"NegativeWithInclusion": {
+			filter:     bson.D{},
+			projection: bson.D{{"b", true}, {"a", bson.D{{"$slice", int64(-1)}}}},
+		},
+		"NegativeSkipLimitExcludeID": {
+			filter:      bson.D{},
+			projection:  bson.D{{"_id", false}, {"a", bson.D{{"$slice", bson.A{int32(-3), int32(2)}}}}, {"b", int32(1)}},
+			skipIDCheck: true,
+		},
+		"NotArrayWithExclusion": {
+			filter:     bson.D{},
+			projection: bson.D{{"b", bson.D{{"$slice", int32(1)}}}, {"c", false}},
+		},
+		"DotNotationArrayOfDocuments": {
+			filter:     bson.D{},
+			projection: bson.D{{"c.d", bson.D{{"$slice", int32(-1)}}}, {"b", true}},
+		},
+		"ZeroLimit": {
+			filter:     bson.D{},
+			projection: bson.D{{"a", bson.D{{"$slice", bson.A{int32(1), int32(0)}}}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testQueryCompatWithProviders(t, providers, testCases)
+}
+
+func TestQueryProjectionElemMatchCompat(t *testing.T) {
+	t.Parallel()
+
+	// students contains a document with arrays of scalars and documents.
+	students := shareddata.NewTopLevelFieldsProvider(
+		"Students",
+		nil,
+		map[string]shareddata.Fields{
+			"students": {
+				{Key: "students", Value: bson.A{
+					bson.D{{"name", "john"}, {"school", int32(102)}, {"age", int32(10)}},
+					bson.D{{"name", "jess"}, {"school", int32(102)}, {"age", int32(11)}},
+					bson.D{{"name", "jeff"}, {"school", int32(108)}, {"age", int32(15)}},
+				}},
+				{Key: "scores", Value: bson.A{int32(70), int32(85), int32(90)}},
+				{Key: "zipcode", Value: "63109"},
+			},
+		},
+	)
+
+	providers := append(shareddata.AllProviders(), students)
+
+	testCases := map[string]queryCompatTestCase{
+		"Document": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$elemMatch", bson.D{{"foo", bson.D{{"$exists", true}}}}}}}},
+		},
+		"Operator": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$elemMatch", bson.D{{"$gt", int32(0)}}}}}},
+		},
+		"NoMatch": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$elemMatch", bson.D{{"$eq", "non-existent"}}}}}},
+		},
+		"Inclusion": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$elemMatch", bson.D{{"$gte", int32(42)}}}}}, {"foo", int32(1)}},
+		},
+		"Exclusion": {
+			filter:     bson.D{},
+			projection: bson.D{{"foo", int32(0)}, {"v", bson.D{{"$elemMatch", bson.D{{"$lt", int32(42)}}}}}},
+		},
+		"ExcludeID": {
+			filter:     bson.D{},
+			projection: bson.D{{"_id", false}, {"v", bson.D{{"$elemMatch", bson.D{{"$type", "string"}}}}}},
+		},
+		"DocumentField": {
+			filter:     bson.D{},
+			projection: bson.D{{"students", bson.D{{"$elemMatch", bson.D{{"school", int32(102)}}}}}},
+		},
+		"DocumentFields": {
+			filter: bson.D{},
+			projection: bson.D{{"students", bson.D{{"$elemMatch", bson.D{
+				{"school", int32(102)},
+				{"age", bson.D{{"$gt", int32(10)}}},
+			}}}}},
+		},
+		"DocumentFieldNoMatch": {
+			filter:     bson.D{},
+			projection: bson.D{{"students", bson.D{{"$elemMatch", bson.D{{"school", int32(1)}}}}}},
+		},
+		"Range": {
+			filter:      bson.D{},
+			projection:  bson.D{{"_id", false}, {"scores", bson.D{{"$elemMatch", bson.D{{"$gte", int32(80)}, {"$lt", int32(90)}}}}}},
+			skipIDCheck: true,
+		},
+		"NotArray": {
+			filter:     bson.D{},
+			projection: bson.D{{"zipcode", bson.D{{"$elemMatch", bson.D{{"$eq", "63109"}}}}}},
+		},
+		"InclusionOrder": {
+			filter:     bson.D{},
+			projection: bson.D{{"scores", bson.D{{"$elemMatch", bson.D{{"$gt", int32(80)}}}}}, {"zipcode", int32(1)}},
+		},
+		"ExclusionDocuments": {
+			filter:     bson.D{},
+			projection: bson.D{{"students", false}, {"scores", bson.D{{"$elemMatch", bson.D{{"$lt", int32(80)}}}}}},
+		},
 	}
 
 	testQueryCompatWithProviders(t, providers, testCases)
@@ -76,6 +76,51 @@ func TestQueryProjectionErrors(t *testing.T) {
 				Message: "Cannot do exclusion on field bar in inclusion projection",
 			},
 		},
+		"SliceString": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", "foo"}}}},
+			err: &mongo.CommandError{
+				Code:    31273,
+				Name:    "Location31273",
+				Message: "$slice only supports numbers and [skip, limit] arrays",
+			},
+		},
+		"SliceArrayLen": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", bson.A{int32(1)}}}}},
+			err: &mongo.CommandError{
+				Code:    31272,
+				Name:    "Location31272",
+				Message: "$slice array argument should be of form [skip, limit]",
+			},
+		},
+		"SliceLimitZero": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", bson.A{int32(1), int32(0)}}}}},
+			err: &mongo.CommandError{
+				Code:    31257,
+				Name:    "Location31257",
+				Message: "$slice limit must be positive",
+			},
+		},
+		"ElemMatchNested": {
+			filter:     bson.D{},
+			projection: bson.D{{"v.foo", bson.D{{"$elemMatch", bson.D{{"$eq", 42}}}}}},
+			err: &mongo.CommandError{
+				Code:    31275,
+				Name:    "Location31275",
+				Message: "Cannot use $elemMatch projection on a nested field.",
+			},
+		},
+		"ElemMatchNotDocument": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$elemMatch", int32(42)}}}},
+			err: &mongo.CommandError{
+				Code:    31274,
+				Name:    "Location31274",
+				Message: "elemMatch: Invalid argument, object required, but got int",
+			},
+		},
 		"PositionalOperatorMultiple": {
 			filter:     bson.D{{"_id", "array-numbers-asc"}},
 			projection: bson.D{{"v.$.foo.$", true}},
@@ -600,6 +600,158 @@ func TestQueryNonExistingCollection(t *testing.T) {
 	require.Len(t, actual, 0)
 }
 
+func TestQuerySortNatural(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(2)}, {"v", "a"}},
+		bson.D{{"_id", int32(3)}, {"v", "c"}},
+		bson.D{{"_id", int32(1)}, {"v", "b"}},
+	})
+	require.NoError(t, err)
+
+	find := func(t *testing.T, opts *options.FindOptions) []bson.D {
+		t.Helper()
+
+		cursor, err := collection.Find(ctx, bson.D{}, opts)
+		require.NoError(t, err)
+
+		return FetchAll(t, ctx, cursor)
+	}
+
+	t.Run("Natural", func(t *testing.T) {
+		t.Parallel()
+
+		opts := options.Find().SetSort(bson.D{{"$natural", int32(-1)}}).SetProjection(bson.D{{"_id", int32(1)}})
+		expected := []bson.D{{{"_id", int32(1)}}, {{"_id", int32(3)}}, {{"_id", int32(2)}}}
+		AssertEqualDocumentsSlice(t, expected, find(t, opts))
+	})
+
+	t.Run("SortKey", func(t *testing.T) {
+		t.Parallel()
+
+		opts := options.Find().SetSort(bson.D{{"v", int32(1)}}).SetProjection(bson.D{
+			{"v", int32(1)},
+			{"sk", bson.D{{"$meta", "sortKey"}}},
+		})
+
+		actual := find(t, opts)
+		require.Len(t, actual, 3)
+		AssertEqualDocuments(t, bson.D{{"_id", int32(2)}, {"v", "a"}, {"sk", bson.D{{"", "a"}}}}, actual[0])
+	})
+
+	t.Run("NaturalCombined", func(t *testing.T) {
+		setup.SkipForMongoDB(t, "FerretDB does not support $natural combined with other sort fields")
+
+		t.Parallel()
+
+		_, err := collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"$natural", int32(1)}, {"v", int32(1)}}))
+		AssertMatchesCommandError(t, mongo.CommandError{Code: 2, Name: "BadValue"}, err)
+		assert.ErrorContains(t, err, "$natural sort cannot be combined")
+	})
+}
+
+func TestQueryGeoSQLite(t *testing.T) {
+	t.Parallel()
+
+	if !setup.IsSQLite(t) {
+		t.Skip("geospatial queries are unavailable only with SQLite backend")
+	}
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", int32(1)}})
+	require.NoError(t, err)
+
+	polygon := bson.D{
+		{"type", "Polygon"},
+		{"coordinates", bson.A{bson.A{bson.A{0, 0}, bson.A{3, 6}, bson.A{6, 1}, bson.A{0, 0}}}},
+	}
+
+	_, err = collection.Find(ctx, bson.D{{"loc", bson.D{{"$geoWithin", bson.D{{"$geometry", polygon}}}}}})
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code: 238,
+		Name: "NotImplemented",
+		Message: `$geoWithin requires "postgis" capability that is not available in SQLite; ` +
+			`check ferretdbCapabilities in buildInfo output`,
+	}, err)
+
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{"loc", "2dsphere"}},
+		Options: options.Index().SetName("loc_2dsphere"),
+	})
+	AssertMatchesCommandError(t, mongo.CommandError{Code: 238, Name: "NotImplemented"}, err)
+	assert.ErrorContains(t, err, `2dsphere index requires "postgis" capability`)
+}
+
+func TestQueryHintNatural(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "b"}, {"v", int32(1)}},
+		bson.D{{"_id", "c"}, {"v", int32(2)}},
+		bson.D{{"_id", "a"}, {"v", int32(1)}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		filter   bson.D
+		hint     any
+		expected []any
+	}{
+		"Forward":   {filter: bson.D{}, hint: bson.D{{"$natural", int32(1)}}, expected: []any{"b", "c", "a"}},
+		"Backward":  {filter: bson.D{}, hint: bson.D{{"$natural", int32(-1)}}, expected: []any{"a", "c", "b"}},
+		"Filter":    {filter: bson.D{{"v", int32(1)}}, hint: bson.D{{"$natural", int32(1)}}, expected: []any{"b", "a"}},
+		"FilterID":  {filter: bson.D{{"_id", "a"}}, hint: bson.D{{"$natural", int32(1)}}, expected: []any{"a"}},
+		"IndexName": {filter: bson.D{{"_id", "a"}}, hint: "_id_", expected: []any{"a"}},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := options.Find().SetHint(tc.hint).SetProjection(bson.D{{"_id", int32(1)}})
+
+			cursor, err := collection.Find(ctx, tc.filter, opts)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, CollectIDs(t, FetchAll(t, ctx, cursor)))
+		})
+	}
+
+	t.Run("Explain", func(t *testing.T) {
+		setup.SkipForMongoDB(t, "pushdown is FerretDB specific feature")
+
+		t.Parallel()
+
+		var res bson.D
+		err := collection.Database().RunCommand(ctx, bson.D{{"explain", bson.D{
+			{"find", collection.Name()},
+			{"filter", bson.D{{"_id", "a"}}},
+			{"hint", bson.D{{"$natural", int32(1)}}},
+		}}}).Decode(&res)
+		require.NoError(t, err)
+
+		// $natural hint disables filter and sort pushdown
+		m := res.Map()
+		assert.Equal(t, false, m["pushdown"])
+		assert.Equal(t, false, m["sortingPushdown"])
+	})
+
+	t.Run("NaturalCombined", func(t *testing.T) {
+		setup.SkipForMongoDB(t, "FerretDB rejects $natural hint combined with other fields")
+
+		t.Parallel()
+
+		_, err := collection.Find(ctx, bson.D{}, options.Find().SetHint(bson.D{{"$natural", int32(1)}, {"v", int32(1)}}))
+		assert.ErrorContains(t, err, "$natural hint cannot be combined")
+	})
+}
+
 func TestQueryCommandBatchSize(t *testing.T) {
 	t.Parallel()
 	ctx, collection := setup.Setup(t)
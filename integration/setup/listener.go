@@ -26,6 +26,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/FerretDB/FerretDB/internal/clientconn"
+	"github.com/FerretDB/FerretDB/internal/clientconn/failpoints"
 	"github.com/FerretDB/FerretDB/internal/handlers/registry"
 	"github.com/FerretDB/FerretDB/internal/util/observability"
 	"github.com/FerretDB/FerretDB/internal/util/state"
@@ -149,10 +150,13 @@ func setupListener(tb testtb.TB, ctx context.Context, logger *zap.Logger) string
 	sp, err := state.NewProvider("")
 	require.NoError(tb, err)
 
+	failPoints := failpoints.NewRegistry()
+
 	handlerOpts := &registry.NewHandlerOpts{
 		Logger:        logger,
 		ConnMetrics:   listenerMetrics.ConnMetrics,
 		StateProvider: sp,
+		FailPoints:    failPoints,
 
 		PostgreSQLURL: postgreSQLURLF,
 		SQLiteURL:     sqliteURL,
@@ -171,6 +175,7 @@ func setupListener(tb testtb.TB, ctx context.Context, logger *zap.Logger) string
 		ProxyAddr:      *targetProxyAddrF,
 		Mode:           clientconn.NormalMode,
 		Metrics:        listenerMetrics,
+		FailPoints:     failPoints,
 		Handler:        h,
 		Logger:         logger,
 		TestRecordsDir: filepath.Join("..", "tmp", "records"),
@@ -186,14 +191,14 @@ func setupListener(tb testtb.TB, ctx context.Context, logger *zap.Logger) string
 
 	switch {
 	case *targetTLSF:
-		listenerOpts.TLS = "127.0.0.1:0"
+		listenerOpts.TLS = []string{"127.0.0.1:0"}
 		listenerOpts.TLSCertFile = filepath.Join(CertsRoot, "server-cert.pem")
 		listenerOpts.TLSKeyFile = filepath.Join(CertsRoot, "server-key.pem")
 		listenerOpts.TLSCAFile = filepath.Join(CertsRoot, "rootCA-cert.pem")
 	case *targetUnixSocketF:
-		listenerOpts.Unix = unixSocketPath(tb)
+		listenerOpts.Unix = []string{unixSocketPath(tb)}
 	default:
-		listenerOpts.TCP = "127.0.0.1:0"
+		listenerOpts.TCP = []string{"127.0.0.1:0"}
 	}
 
 	l := clientconn.NewListener(&listenerOpts)
@@ -57,7 +57,11 @@ func Startup() {
 	prometheus.DefaultRegisterer.MustRegister(listenerMetrics)
 
 	// use any available port to allow running different configurations in parallel
-	go debug.RunHandler(context.Background(), "127.0.0.1:0", prometheus.DefaultRegisterer, zap.L().Named("debug"))
+	go debug.RunHandler(context.Background(), &debug.RunHandlerOpts{
+		Addr: "127.0.0.1:0",
+		R:    prometheus.DefaultRegisterer,
+		L:    zap.L().Named("debug"),
+	})
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -0,0 +1,57 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shareddata
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+
+	fbson "github.com/FerretDB/FerretDB/internal/bson"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil/testfixtures"
+)
+
+// fixtureProvider implements Provider for testfixtures.Fixture.
+type fixtureProvider struct {
+	f *testfixtures.Fixture
+}
+
+// NewFixtureProvider returns a Provider for the given fixture,
+// so the same deterministic data set could be used by unit and integration tests.
+func NewFixtureProvider(f *testfixtures.Fixture) Provider {
+	return &fixtureProvider{f: f}
+}
+
+// Name implements Provider interface.
+func (fp *fixtureProvider) Name() string {
+	return fp.f.Name()
+}
+
+// Docs implements Provider interface.
+func (fp *fixtureProvider) Docs() []bson.D {
+	docs := fp.f.Docs()
+	res := make([]bson.D, len(docs))
+
+	for i, doc := range docs {
+		raw := must.NotFail(must.NotFail(fbson.ConvertDocument(doc)).MarshalBinary())
+		must.NoError(bson.Unmarshal(raw, &res[i]))
+	}
+
+	return res
+}
+
+// check interfaces
+var (
+	_ Provider = (*fixtureProvider)(nil)
+)
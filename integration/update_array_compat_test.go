@@ -18,6 +18,9 @@ import (
 	"testing"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/shareddata"
 )
 
 func TestUpdateArrayCompatPop(t *testing.T) {
@@ -75,6 +78,11 @@ func TestUpdateArrayCompatPop(t *testing.T) {
 			update:     bson.D{{"$pop", bson.D{{"v.foo", 1}}}},
 			resultType: emptyResult,
 		},
+		"DotNotationTraverseScalar": {
+			filter:     bson.D{{"_id", "array-documents-nested"}},
+			update:     bson.D{{"$pop", bson.D{{"v.0.foo.0.bar.x", 1}}}},
+			resultType: emptyResult,
+		},
 	}
 
 	testUpdateCompat(t, testCases)
@@ -363,3 +371,166 @@ func TestUpdateArrayCompatPull(t *testing.T) {
 
 	testUpdateCompat(t, testCases)
 }
+
+func TestUpdateArrayCompatPushModifiers(t *testing.T) {
+	t.Parallel()
+
+	providers := []shareddata.Provider{
+		shareddata.NewTopLevelFieldsProvider(
+			"Arrays",
+			nil,
+			map[int32]shareddata.Fields{
+				1: {
+					{Key: "scores", Value: bson.A{int32(5), int32(8)}},
+					{Key: "position", Value: bson.A{int32(1), int32(2), int32(3)}},
+					{Key: "items", Value: bson.A{
+						bson.D{{"name", "a"}, {"qty", int32(3)}},
+						bson.D{{"name", "b"}, {"qty", int32(1)}},
+					}},
+					{Key: "pull", Value: bson.A{int32(1), int32(6), int32(7), int32(2)}},
+					{Key: "pullDocs", Value: bson.A{
+						bson.D{{"item", "a"}, {"score", int32(8)}},
+						bson.D{{"item", "b"}, {"score", int32(8)}},
+					}},
+				},
+			},
+		),
+	}
+
+	testCases := map[string]updateCompatTestCase{
+		"Modifiers": {
+			update: bson.D{
+				{"$push", bson.D{
+					{"scores", bson.D{{"$each", bson.A{int32(9), int32(1)}}, {"$sort", -1}, {"$slice", 3}}},
+					{"position", bson.D{{"$each", bson.A{int32(10), int32(20)}}, {"$position", -1}}},
+					{"items", bson.D{{"$each", bson.A{bson.D{{"name", "c"}, {"qty", int32(2)}}}}, {"$sort", bson.D{{"qty", 1}}}}},
+					{"sliced", bson.D{{"$each", bson.A{int32(1), int32(2), int32(3)}}, {"$slice", -2}}},
+				}},
+				{"$pull", bson.D{
+					{"pull", bson.D{{"$gte", int32(6)}}},
+					{"pullDocs", bson.D{{"item", "b"}}},
+				}},
+			},
+			providers: providers,
+		},
+		"PushUnknownModifier": {
+			update:     bson.D{{"$push", bson.D{{"scores", bson.D{{"$each", bson.A{int32(1)}}, {"$foo", 1}}}}}},
+			resultType: emptyResult,
+			providers:  providers,
+		},
+		"PushSliceType": {
+			update:     bson.D{{"$push", bson.D{{"scores", bson.D{{"$each", bson.A{int32(1)}}, {"$slice", "1"}}}}}},
+			resultType: emptyResult,
+			providers:  providers,
+		},
+		"PushPositionType": {
+			update:     bson.D{{"$push", bson.D{{"scores", bson.D{{"$each", bson.A{int32(1)}}, {"$position", 1.5}}}}}},
+			resultType: emptyResult,
+			providers:  providers,
+		},
+		"PushSortInvalid": {
+			update:     bson.D{{"$push", bson.D{{"scores", bson.D{{"$each", bson.A{int32(1)}}, {"$sort", 2}}}}}},
+			resultType: emptyResult,
+			providers:  providers,
+		},
+		"PushSortFieldInvalid": {
+			update:     bson.D{{"$push", bson.D{{"items", bson.D{{"$each", bson.A{}}, {"$sort", bson.D{{"qty", "asc"}}}}}}}},
+			resultType: emptyResult,
+			providers:  providers,
+		},
+		"AddToSetUnexpectedFields": {
+			update:     bson.D{{"$addToSet", bson.D{{"scores", bson.D{{"$each", bson.A{int32(1)}}, {"$slice", 1}}}}}},
+			resultType: emptyResult,
+			providers:  providers,
+		},
+	}
+
+	testUpdateCompat(t, testCases)
+}
+
+func TestUpdateArrayCompatArrayFilters(t *testing.T) {
+	t.Parallel()
+
+	providers := []shareddata.Provider{
+		shareddata.NewTopLevelFieldsProvider(
+			"Arrays",
+			nil,
+			map[int32]shareddata.Fields{
+				1: {
+					{Key: "grades", Value: bson.A{int32(95), int32(80), int32(100)}},
+					{Key: "counts", Value: bson.A{int32(1), int32(2)}},
+					{Key: "items", Value: bson.A{
+						bson.D{{"name", "a"}, {"qty", int32(3)}},
+						bson.D{{"name", "b"}, {"qty", int32(1)}},
+					}},
+					{Key: "v", Value: int32(1)},
+				},
+			},
+		),
+	}
+
+	arrayFilters := func(filters ...any) *options.UpdateOptions {
+		return options.Update().SetArrayFilters(options.ArrayFilters{Filters: filters})
+	}
+
+	testCases := map[string]updateCompatTestCase{
+		"Filters": {
+			update: bson.D{
+				{"$set", bson.D{{"grades.$[high]", int32(100)}}},
+				{"$inc", bson.D{{"counts.$[]", int32(10)}, {"items.$[item].qty", int32(5)}}},
+			},
+			updateOpts: arrayFilters(
+				bson.D{{"high", bson.D{{"$gte", int32(90)}}}},
+				bson.D{{"item.name", "b"}},
+			),
+			providers: providers,
+		},
+		"NoFilter": {
+			update:     bson.D{{"$set", bson.D{{"grades.$[x]", int32(1)}}}},
+			resultType: emptyResult,
+			providers:  providers,
+		},
+		"UnusedFilter": {
+			update:     bson.D{{"$set", bson.D{{"v", int32(2)}}}},
+			updateOpts: arrayFilters(bson.D{{"x", int32(1)}}),
+			resultType: emptyResult,
+			providers:  providers,
+		},
+		"DuplicateFilter": {
+			update:     bson.D{{"$set", bson.D{{"grades.$[x]", int32(1)}}}},
+			updateOpts: arrayFilters(bson.D{{"x", int32(1)}}, bson.D{{"x", int32(2)}}),
+			resultType: emptyResult,
+			providers:  providers,
+		},
+		"InvalidIdentifier": {
+			update:     bson.D{{"$set", bson.D{{"grades.$[X]", int32(1)}}}},
+			updateOpts: arrayFilters(bson.D{{"X", int32(1)}}),
+			resultType: emptyResult,
+			providers:  providers,
+		},
+		"MultipleIdentifiers": {
+			update:     bson.D{{"$set", bson.D{{"grades.$[x]", int32(1)}}}},
+			updateOpts: arrayFilters(bson.D{{"x", int32(1)}, {"y", int32(2)}}),
+			resultType: emptyResult,
+			providers:  providers,
+		},
+		"EmptyFilter": {
+			update:     bson.D{{"$set", bson.D{{"grades.$[x]", int32(1)}}}},
+			updateOpts: arrayFilters(bson.D{}),
+			resultType: emptyResult,
+			providers:  providers,
+		},
+		"NonArray": {
+			update:     bson.D{{"$set", bson.D{{"v.$[]", int32(1)}}}},
+			resultType: emptyResult,
+			providers:  providers,
+		},
+		"MissingPath": {
+			update:     bson.D{{"$set", bson.D{{"missing.$[]", int32(1)}}}},
+			resultType: emptyResult,
+			providers:  providers,
+		},
+	}
+
+	testUpdateCompat(t, testCases)
+}
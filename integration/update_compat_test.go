@@ -619,6 +619,12 @@ func TestUpdateCompat(t *testing.T) {
 			updateOpts: options.Update().SetUpsert(true),
 			resultType: emptyResult,
 		},
+		"ReplaceUpsertQueryFields": {
+			filter:      bson.D{{"_id", "new"}, {"x", int32(1)}},
+			replace:     bson.D{{"y", int32(2)}},
+			replaceOpts: options.Replace().SetUpsert(true),
+			resultType:  emptyResult,
+		},
 	}
 
 	testUpdateCompat(t, testCases)
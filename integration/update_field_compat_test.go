@@ -103,6 +103,16 @@ func TestUpdateFieldCompatCurrentDate(t *testing.T) {
 			}}},
 			resultType: emptyResult,
 		},
+		"DotNotation": {
+			update: bson.D{{"$currentDate", bson.D{
+				{"meta.updated", true},
+				{"items.0.ts", bson.D{{"$type", "timestamp"}}},
+			}}},
+			paths: []types.Path{
+				types.NewStaticPath("meta", "updated"),
+				types.NewStaticPath("items", "0", "ts"),
+			},
+		},
 	}
 
 	testUpdateCurrentDateCompat(t, testCases)
@@ -1208,6 +1218,95 @@ func TestUpdateFieldCompatMul(t *testing.T) {
 	testUpdateCompat(t, testCases)
 }
 
+func TestUpdateFieldCompatNumericMixed(t *testing.T) {
+	t.Parallel()
+
+	providers := []shareddata.Provider{
+		shareddata.NewTopLevelFieldsProvider(
+			"Numbers",
+			nil,
+			map[int32]shareddata.Fields{
+				1: {
+					{Key: "i", Value: int32(math.MaxInt32)},
+					{Key: "m", Value: int32(3)},
+					{Key: "min", Value: int32(5)},
+					{Key: "max", Value: int32(5)},
+				},
+				2: {
+					{Key: "l", Value: int64(math.MaxInt64)},
+					{Key: "s", Value: "foo"},
+				},
+			},
+		),
+	}
+
+	testCases := map[string]updateCompatTestCase{
+		"Operators": {
+			update: bson.D{
+				{"$inc", bson.D{{"i", int32(1)}}},
+				{"$mul", bson.D{{"m", 1.5}}},
+				{"$min", bson.D{{"min", int64(2)}}},
+				{"$max", bson.D{{"max", int64(2)}}},
+			},
+			providers: providers,
+		},
+		"IncOverflow": {
+			update:    bson.D{{"$inc", bson.D{{"l", int64(1)}}}},
+			providers: providers,
+		},
+		"IncNonNumeric": {
+			update:    bson.D{{"$inc", bson.D{{"s", int32(1)}}}},
+			providers: providers,
+		},
+		"MulNonNumeric": {
+			update:    bson.D{{"$mul", bson.D{{"s", int32(2)}}}},
+			providers: providers,
+		},
+	}
+
+	testUpdateCompat(t, testCases)
+}
+
+func TestUpdateFieldCompatUpsertQueryFields(t *testing.T) {
+	t.Parallel()
+
+	providers := []shareddata.Provider{shareddata.Int32s}
+
+	testCases := map[string]testUpdateManyCompatTestCase{
+		"Fields": {
+			filter: bson.D{
+				{"_id", int32(10)},
+				{"a.b", int32(1)},
+				{"c", bson.D{{"$eq", int32(2)}}},
+				{"d", bson.D{{"$gt", int32(1)}}},
+				{"e", bson.D{{"f", int32(3)}}},
+				{"$and", bson.A{bson.D{{"g", int32(4)}}}},
+				{"$or", bson.A{bson.D{{"h", int32(5)}}, bson.D{{"i", int32(6)}}}},
+			},
+			update:     bson.D{{"$set", bson.D{{"v", int32(3)}}}},
+			updateOpts: options.Update().SetUpsert(true),
+			resultType: emptyResult, // upserted documents are not counted as modified
+			providers:  providers,
+		},
+		"MatchedTwice": {
+			filter:     bson.D{{"a", int32(1)}, {"$and", bson.A{bson.D{{"a", int32(2)}}}}},
+			update:     bson.D{{"$set", bson.D{{"v", int32(1)}}}},
+			updateOpts: options.Update().SetUpsert(true),
+			resultType: emptyResult,
+			providers:  providers,
+		},
+		"Overlapping": {
+			filter:     bson.D{{"a", int32(1)}, {"a.b", int32(2)}},
+			update:     bson.D{{"$set", bson.D{{"v", int32(1)}}}},
+			updateOpts: options.Update().SetUpsert(true),
+			resultType: emptyResult,
+			providers:  providers,
+		},
+	}
+
+	testUpdateManyCompat(t, testCases)
+}
+
 func TestUpdateFieldCompatBit(t *testing.T) {
 	t.Parallel()
 
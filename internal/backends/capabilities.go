@@ -0,0 +1,41 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backends
+
+// Backend capabilities (extensions, etc.) that are detected on connection
+// and stored in the [state.State] as BackendCapabilities.
+//
+// Features that depend on them should be gated with clear errors instead of failing with raw backend errors.
+const (
+	// CapabilityPostGIS is the PostGIS extension for geospatial queries.
+	CapabilityPostGIS = "postgis"
+
+	// CapabilityTrigram is the pg_trgm extension for similarity and substring searches.
+	CapabilityTrigram = "pg_trgm"
+
+	// CapabilityICU is the ICU collations support.
+	CapabilityICU = "icu"
+
+	// CapabilityTablespaces is the support for placing collections into tablespaces.
+	CapabilityTablespaces = "tablespaces"
+)
+
+// Capabilities contains all known backend capabilities in the order they are reported.
+var Capabilities = []string{
+	CapabilityPostGIS,
+	CapabilityTrigram,
+	CapabilityICU,
+	CapabilityTablespaces,
+}
@@ -41,9 +41,11 @@ const DefaultIndexName = "_id_"
 // See collectionContract and its methods for additional details.
 type Collection interface {
 	Query(context.Context, *QueryParams) (*QueryResult, error)
+	Count(context.Context, *CountParams) (*CountResult, error)
 	InsertAll(context.Context, *InsertAllParams) (*InsertAllResult, error)
 	UpdateAll(context.Context, *UpdateAllParams) (*UpdateAllResult, error)
 	DeleteAll(context.Context, *DeleteAllParams) (*DeleteAllResult, error)
+	FindAndModify(context.Context, *FindAndModifyParams) (*FindAndModifyResult, error)
 	Explain(context.Context, *ExplainParams) (*ExplainResult, error)
 
 	Stats(context.Context, *CollectionStatsParams) (*CollectionStatsResult, error)
@@ -71,6 +73,11 @@ func CollectionContract(c Collection) Collection {
 	}
 }
 
+// NaturalSortKey is a special SortField key for the natural order of documents.
+//
+// Unlike other sort fields that may be ignored by backends, it should be always applied.
+const NaturalSortKey = "$natural"
+
 // SortField consists of a field name and a sort order that are used in queries.
 type SortField struct {
 	Key        string
@@ -84,17 +91,98 @@ type QueryParams struct {
 	Limit         int64
 	OnlyRecordIDs bool
 	Comment       string // TODO https://github.com/FerretDB/FerretDB/issues/3573
+
+	// TextSearch, if set, limits results to documents matching it using the collection's text index.
+	// Handlers should check that the text index exists.
+	TextSearch *TextSearch
+
+	// Geo, if set, limits results to documents matching that geospatial query.
+	// Handlers should check that the backend has [CapabilityPostGIS].
+	Geo *GeoQuery
+
+	// SeqScan, if set, makes the backend read the whole table sequentially in its physical order,
+	// without using indexes and without sorting, so rows are streamed as they are read.
+	// Handlers set it for `{$natural: 1}` hint; Filter, Sort, TextSearch, and Geo are not set in that case.
+	SeqScan bool
+
+	// Projection, if set, lists top-level fields the handler needs from returned documents
+	// (including the ones used by filtering and sorting).
+	// Backends may omit other fields or ignore it completely.
+	Projection []string
+
+	// Distinct, if set, is a top-level field name for the distinct command.
+	// Backends may return only one of the documents that have the same value of that field
+	// (with the same types, including types of nested values), or ignore it completely.
+	// Handlers set it only when Filter is empty and Sort, TextSearch, and Geo are not set,
+	// because the chosen document should not be filtered out later.
+	Distinct string
+
+	// Unwind, if set, is a top-level field name of the leading $unwind stage without options.
+	// Backends that support it return a document for each element of that field's array value
+	// (documents with other values are returned as is; documents with missing, null, or empty array values are skipped)
+	// and set QueryResult.Unwound.
+	// Handlers set it only when Filter, Sort, Limit, TextSearch, Geo, SeqScan, and Projection are not set.
+	Unwind string
+}
+
+// TextSearch represents parsed $text query operator.
+//
+// Documents match if they contain at least one of the Terms (if any), all Phrases,
+// and none of the Negations in the fields of the text index.
+// Backends return text scores of returned documents in [QueryResult.TextScores].
+type TextSearch struct {
+	Terms     []string
+	Phrases   []string
+	Negations []string
+	Language  string // empty for the text index's default language
+}
+
+// GeoOperator represents a geospatial query operator.
+type GeoOperator string
+
+// Supported geospatial query operators.
+const (
+	GeoWithin     = GeoOperator("$geoWithin")
+	GeoIntersects = GeoOperator("$geoIntersects")
+	GeoNear       = GeoOperator("$near")
+)
+
+// GeoQuery represents parsed $geoWithin, $geoIntersects, $near, or $nearSphere query operator
+// for a single field.
+//
+// Field values are GeoJSON objects or legacy coordinate pairs; other values never match.
+// All calculations use spherical geometry, like 2dsphere indexes do.
+type GeoQuery struct {
+	Field    string // may contain dots
+	Operator GeoOperator
+	Geometry string // GeoJSON geometry
+
+	// MinDistance and MaxDistance are in meters; nil if unset.
+	// For GeoWithin, MaxDistance is set for $centerSphere, and Geometry is a center point.
+	// For GeoNear, documents are ordered by the distance unless QueryParams.Sort is set.
+	MinDistance *float64
+	MaxDistance *float64
 }
 
 // QueryResult represents the results of Collection.Query method.
 type QueryResult struct {
 	Iter types.DocumentsIterator
+
+	// Unwound is true if documents were unwound by QueryParams.Unwind field.
+	Unwound bool
+
+	// TextScores is set for queries with QueryParams.TextSearch and nil for other queries.
+	// Text scores are not document fields, so they are returned there, keyed by documents returned by Iter.
+	// Iter adds scores to that map as it returns documents.
+	TextScores map[*types.Document]float64
 }
 
 // Query executes a query against the collection.
 //
 // If database or collection does not exist it returns empty iterator.
 //
+// Query results with TextSearch set are not ordered by text score; handlers sort them if needed.
+//
 // The passed context should be used for canceling the initial query.
 // It also can be used to close the returned iterator and free underlying resources,
 // but doing so is not necessary - the handler will do that anyway.
@@ -107,13 +195,66 @@ func (cc *collectionContract) Query(ctx context.Context, params *QueryParams) (*
 	return res, err
 }
 
+// CountParams represents the parameters of Collection.Count method.
+type CountParams struct {
+	Filter *types.Document
+
+	// GroupBy, if set, is a top-level field name; documents are counted separately
+	// for each value of that field, see CountResult.Groups.
+	GroupBy string
+}
+
+// CountResult represents the results of Collection.Count method.
+type CountResult struct {
+	Count int64
+
+	// Groups contains counts for values of CountParams.GroupBy field, if it was set.
+	// Equal values of different types (like 1 and 1.0) may be counted separately;
+	// documents without that field are counted with nil value.
+	Groups []CountGroup
+
+	// Pushdown is true if Count (or Groups) is exact.
+	// If it is false, Count and Groups should be ignored, and handlers should count documents returned by Query.
+	Pushdown bool
+}
+
+// CountGroup represents the number of documents with the given value of the field.
+type CountGroup struct {
+	Value any
+	Count int64
+}
+
+// Count returns the number of documents in the collection matching the given filter.
+//
+// Backends count documents themselves only if the whole filter can be evaluated exactly;
+// otherwise, they return CountResult with Pushdown set to false.
+// The same applies to grouping.
+//
+// Database or collection may not exist; that's not an error, and zero (with no groups) is returned.
+func (cc *collectionContract) Count(ctx context.Context, params *CountParams) (*CountResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	res, err := cc.c.Count(ctx, params)
+	checkError(err)
+
+	return res, err
+}
+
 // InsertAllParams represents the parameters of Collection.InsertAll method.
 type InsertAllParams struct {
 	Docs []*types.Document
+
+	// SkipDuplicates makes documents that violate unique indexes to be skipped
+	// instead of failing the whole operation.
+	SkipDuplicates bool
 }
 
 // InsertAllResult represents the results of Collection.InsertAll method.
-type InsertAllResult struct{}
+type InsertAllResult struct {
+	// Skipped contains indexes of skipped documents in InsertAllParams.Docs.
+	// It is set only if SkipDuplicates is true.
+	Skipped []int
+}
 
 // InsertAll inserts documents into the collection.
 //
@@ -121,6 +262,9 @@ type InsertAllResult struct{}
 // If some documents cannot be inserted, the operation should be rolled back,
 // and the first encountered error should be returned.
 //
+// If SkipDuplicates is true, documents that violate unique indexes (including the one on _id)
+// are skipped without an error, and their indexes are returned.
+//
 // All documents are expected to be valid and include _id fields.
 // They will be frozen.
 //
@@ -156,8 +300,12 @@ type UpdateAllResult struct {
 // If some documents cannot be updated, the operation should be rolled back,
 // and the first encountered error should be returned.
 //
+// If SkipDuplicates is true, documents that violate unique indexes (including the one on _id)
+// are skipped without an error, and their indexes are returned.
+//
 // All documents are expected to be valid and include _id fields.
 // They will be frozen.
+// ErrorCodeInsertDuplicateID is returned if an updated document violates a unique index.
 //
 // Database or collection may not exist; that's not an error.
 func (cc *collectionContract) UpdateAll(ctx context.Context, params *UpdateAllParams) (*UpdateAllResult, error) {
@@ -168,7 +316,7 @@ func (cc *collectionContract) UpdateAll(ctx context.Context, params *UpdateAllPa
 	}
 
 	res, err := cc.c.UpdateAll(ctx, params)
-	checkError(err)
+	checkError(err, ErrorCodeInsertDuplicateID)
 
 	return res, err
 }
@@ -204,19 +352,115 @@ func (cc *collectionContract) DeleteAll(ctx context.Context, params *DeleteAllPa
 	return res, err
 }
 
+// FindAndModifyParams represents the parameters of Collection.FindAndModify method.
+type FindAndModifyParams struct {
+	Filter *types.Document
+
+	// Modify is called with documents that may match Filter; backend may return more documents,
+	// but not less. It returns a single change to apply or nil if nothing should be changed.
+	//
+	// Backend may lock only the document chosen for update or delete
+	// and call Modify again with just the locked version of that document,
+	// so the filter is re-checked and the change is computed from the version that is changed.
+	// Only the change returned by the last call is applied.
+	//
+	// Backend may call it more than once, also if the transaction is retried,
+	// so it should not have side effects besides overwriting its own results.
+	Modify func(iter types.DocumentsIterator) (*FindAndModifyChange, error)
+}
+
+// FindAndModifyChange represents a change returned by FindAndModifyParams.Modify function.
+// Only one of the fields should be set.
+type FindAndModifyChange struct {
+	Update   *types.Document
+	Insert   *types.Document
+	DeleteID any
+}
+
+// FindAndModifyResult represents the results of Collection.FindAndModify method.
+type FindAndModifyResult struct {
+	Updated  int32
+	Deleted  int32
+	Inserted int32
+}
+
+// FindAndModify atomically finds a single document and updates or deletes it,
+// or inserts a new document if nothing was found.
+//
+// Documents passed to Modify and the change it returns are read and written in a single transaction.
+// If Modify returns an error, no changes are made, and that error is returned as is.
+//
+// Documents to update and insert are expected to be valid and include _id fields.
+// They will be frozen.
+//
+// Database or collection may not exist; that's not an error, Modify gets an empty iterator.
+// They should be created automatically if a document should be inserted.
+func (cc *collectionContract) FindAndModify(ctx context.Context, params *FindAndModifyParams) (*FindAndModifyResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	must.BeTrue(params.Modify != nil)
+
+	modify := params.Modify
+
+	p := *params
+	p.Modify = func(iter types.DocumentsIterator) (*FindAndModifyChange, error) {
+		change, err := modify(iter)
+		if err != nil || change == nil {
+			return change, err
+		}
+
+		var set int
+		for _, ok := range []bool{change.Update != nil, change.Insert != nil, change.DeleteID != nil} {
+			if ok {
+				set++
+			}
+		}
+
+		must.BeTrue(set == 1)
+
+		if change.Update != nil {
+			change.Update.Freeze()
+		}
+
+		if change.Insert != nil {
+			change.Insert.SetRecordID(types.NextTimestamp(time.Now()))
+			change.Insert.Freeze()
+		}
+
+		return change, nil
+	}
+
+	res, err := cc.c.FindAndModify(ctx, &p)
+	checkError(err, ErrorCodeInsertDuplicateID)
+
+	return res, err
+}
+
 // ExplainParams represents the parameters of Collection.Explain method.
 type ExplainParams struct {
 	Filter *types.Document
 	Sort   *SortField
 	Limit  int64
+
+	// Analyze executes the query to include actual run-time statistics in the plan.
+	// Backends that can't do that ignore it.
+	Analyze bool
+
+	// Count explains the query that Collection.Count would use instead, if any.
+	Count bool
+
+	// SeqScan explains the sequential scan, see QueryParams.SeqScan.
+	SeqScan bool
 }
 
 // ExplainResult represents the results of Collection.Explain method.
 type ExplainResult struct {
 	QueryPlanner        *types.Document
 	QueryPushdown       bool
+	RegexPushdown       bool // some regex filters use trigram indexes
 	UnsafeSortPushdown  bool
 	UnsafeLimitPushdown bool
+	CountPushdown       bool // documents are counted by the backend, see Collection.Count
 }
 
 // Explain return a backend-specific execution plan for the given query.
@@ -301,6 +545,21 @@ type IndexInfo struct {
 	Name   string
 	Key    []IndexKeyPair
 	Unique bool
+
+	// PartialFilterExpression limits the index to documents matching that filter; nil for regular indexes.
+	// Only top-level fields with equality, $eq, $exists: true, numeric $gt, $gte, $lt, $lte, and $and are supported;
+	// handlers should validate it before passing it to the backend.
+	PartialFilterExpression *types.Document
+
+	// Text is true for text indexes; Key fields are indexed for $text queries, Descending is always false.
+	// A collection has at most one text index.
+	Text bool
+
+	// DefaultLanguage is a default language of the text index.
+	DefaultLanguage string
+
+	// Sphere is true for 2dsphere indexes; Key has a single field, Descending is always false.
+	Sphere bool
 }
 
 // IndexKeyPair consists of a field name and a sort order that are part of the index.
@@ -15,7 +15,9 @@
 package backends_test // to avoid import cycle
 
 import (
+	"errors"
 	"slices"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -197,6 +199,120 @@ func TestCollectionInsertAllQueryExplain(t *testing.T) {
 	}
 }
 
+func TestCollectionInsertAllSkipDuplicates(t *testing.T) {
+	t.Parallel()
+
+	ctx := conninfo.Ctx(testutil.Ctx(t), conninfo.New())
+
+	for name, b := range testBackends(t) {
+		name, b := name, b
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbName, collName := testutil.DatabaseName(t), testutil.CollectionName(t)
+
+			db, err := b.Database(dbName)
+			require.NoError(t, err)
+
+			coll, err := db.Collection(collName)
+			require.NoError(t, err)
+
+			_, err = coll.CreateIndexes(ctx, &backends.CreateIndexesParams{
+				Indexes: []backends.IndexInfo{{
+					Name:   "v_1",
+					Key:    []backends.IndexKeyPair{{Field: "v"}},
+					Unique: true,
+				}},
+			})
+			require.NoError(t, err)
+
+			_, err = coll.InsertAll(ctx, &backends.InsertAllParams{
+				Docs: []*types.Document{must.NotFail(types.NewDocument("_id", int32(1), "v", "a"))},
+			})
+			require.NoError(t, err)
+
+			insertRes, err := coll.InsertAll(ctx, &backends.InsertAllParams{
+				Docs: []*types.Document{
+					must.NotFail(types.NewDocument("_id", int32(2), "v", "b")),
+					must.NotFail(types.NewDocument("_id", int32(1), "v", "c")), // duplicate _id
+					must.NotFail(types.NewDocument("_id", int32(3), "v", "a")), // duplicate v
+					must.NotFail(types.NewDocument("_id", int32(2), "v", "d")), // duplicate _id in the same call
+					must.NotFail(types.NewDocument("_id", int32(4), "v", "e")),
+				},
+				SkipDuplicates: true,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []int{1, 2, 3}, insertRes.Skipped)
+
+			queryRes, err := coll.Query(ctx, new(backends.QueryParams))
+			require.NoError(t, err)
+
+			docs, err := iterator.ConsumeValues[struct{}, *types.Document](queryRes.Iter)
+			require.NoError(t, err)
+			require.Len(t, docs, 3)
+
+			_, err = coll.InsertAll(ctx, &backends.InsertAllParams{
+				Docs: []*types.Document{must.NotFail(types.NewDocument("_id", int32(1)))},
+			})
+			assert.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID))
+		})
+	}
+}
+
+func TestCollectionQueryProjection(t *testing.T) {
+	t.Parallel()
+
+	ctx := conninfo.Ctx(testutil.Ctx(t), conninfo.New())
+
+	for name, b := range testBackends(t) {
+		name, b := name, b
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbName, collName := testutil.DatabaseName(t), testutil.CollectionName(t)
+
+			db, err := b.Database(dbName)
+			require.NoError(t, err)
+
+			coll, err := db.Collection(collName)
+			require.NoError(t, err)
+
+			doc := must.NotFail(types.NewDocument(
+				"_id", int32(1),
+				"a", "foo",
+				"b", must.NotFail(types.NewDocument("c", int32(42))),
+				"d", types.Null,
+				"e", must.NotFail(types.NewArray(int32(1), "bar")),
+			))
+
+			_, err = coll.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{doc}})
+			require.NoError(t, err)
+
+			queryRes, err := coll.Query(ctx, &backends.QueryParams{
+				Projection: []string{"e", "_id", "d", "missing"},
+			})
+			require.NoError(t, err)
+
+			docs, err := iterator.ConsumeValues[struct{}, *types.Document](queryRes.Iter)
+			require.NoError(t, err)
+			require.Len(t, docs, 1)
+
+			expected := doc
+
+			// other backends ignore projection
+			if name == "postgresql" {
+				expected = must.NotFail(types.NewDocument(
+					"_id", int32(1),
+					"d", types.Null,
+					"e", must.NotFail(types.NewArray(int32(1), "bar")),
+				))
+			}
+
+			testutil.AssertEqual(t, expected, docs[0])
+		})
+	}
+}
+
 func TestCollectionUpdateAll(t *testing.T) {
 	t.Parallel()
 
@@ -300,6 +416,106 @@ func TestCollectionUpdateAll(t *testing.T) {
 	}
 }
 
+func TestCollectionFindAndModify(t *testing.T) {
+	t.Parallel()
+
+	ctx := conninfo.Ctx(testutil.Ctx(t), conninfo.New())
+
+	for name, b := range testBackends(t) {
+		name, b := name, b
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbName, collName := testutil.DatabaseName(t), testutil.CollectionName(t)
+
+			db, err := b.Database(dbName)
+			require.NoError(t, err)
+
+			coll, err := db.Collection(collName)
+			require.NoError(t, err)
+
+			// upsert into non-existing collection
+			res, err := coll.FindAndModify(ctx, &backends.FindAndModifyParams{
+				Modify: func(iter types.DocumentsIterator) (*backends.FindAndModifyChange, error) {
+					docs, err := iterator.ConsumeValues(iter)
+					require.NoError(t, err)
+					require.Empty(t, docs)
+
+					return &backends.FindAndModifyChange{
+						Insert: must.NotFail(types.NewDocument("_id", "counter", "v", int32(0))),
+					}, nil
+				},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, int32(1), res.Inserted)
+
+			// concurrent increments should not lose updates
+			const n = 10
+
+			var wg sync.WaitGroup
+			for i := 0; i < n; i++ {
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+
+					res, err := coll.FindAndModify(ctx, &backends.FindAndModifyParams{
+						Filter: must.NotFail(types.NewDocument("_id", "counter")),
+						Modify: func(iter types.DocumentsIterator) (*backends.FindAndModifyChange, error) {
+							docs, err := iterator.ConsumeValues(iter)
+							if err != nil {
+								return nil, err
+							}
+
+							doc := docs[0].DeepCopy()
+							doc.Set("v", must.NotFail(doc.Get("v")).(int32)+1)
+
+							return &backends.FindAndModifyChange{Update: doc}, nil
+						},
+					})
+					assert.NoError(t, err)
+					assert.Equal(t, int32(1), res.Updated)
+				}()
+			}
+
+			wg.Wait()
+
+			queryRes, err := coll.Query(ctx, nil)
+			require.NoError(t, err)
+
+			docs, err := iterator.ConsumeValues(queryRes.Iter)
+			require.NoError(t, err)
+			require.Len(t, docs, 1)
+			assert.Equal(t, int32(n), must.NotFail(docs[0].Get("v")))
+
+			// error from Modify is returned as is, nothing is changed
+			modifyErr := errors.New("modify error")
+
+			_, err = coll.FindAndModify(ctx, &backends.FindAndModifyParams{
+				Modify: func(iter types.DocumentsIterator) (*backends.FindAndModifyChange, error) {
+					return nil, modifyErr
+				},
+			})
+			require.ErrorIs(t, err, modifyErr)
+
+			res, err = coll.FindAndModify(ctx, &backends.FindAndModifyParams{
+				Modify: func(iter types.DocumentsIterator) (*backends.FindAndModifyChange, error) {
+					return &backends.FindAndModifyChange{DeleteID: "counter"}, nil
+				},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, int32(1), res.Deleted)
+
+			queryRes, err = coll.Query(ctx, nil)
+			require.NoError(t, err)
+
+			docs, err = iterator.ConsumeValues(queryRes.Iter)
+			require.NoError(t, err)
+			assert.Empty(t, docs)
+		})
+	}
+}
+
 func TestCollectionStats(t *testing.T) {
 	t.Parallel()
 
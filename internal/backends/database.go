@@ -18,7 +18,9 @@ import (
 	"cmp"
 	"context"
 	"slices"
+	"time"
 
+	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/util/observability"
 )
@@ -39,8 +41,13 @@ type Database interface {
 	CreateCollection(context.Context, *CreateCollectionParams) error
 	DropCollection(context.Context, *DropCollectionParams) error
 	RenameCollection(context.Context, *RenameCollectionParams) error
+	SetCollectionSettings(context.Context, *SetCollectionSettingsParams) error
 
 	Stats(context.Context, *DatabaseStatsParams) (*DatabaseStatsResult, error)
+
+	ValidateMetadata(context.Context, *ValidateMetadataParams) (*ValidateMetadataResult, error)
+
+	QueryRaw(context.Context, *QueryRawParams) (*QueryRawResult, error)
 }
 
 // databaseContract implements Database interface.
@@ -66,7 +73,7 @@ func DatabaseContract(db Database) Database {
 func (dbc *databaseContract) Collection(name string) (Collection, error) {
 	var res Collection
 
-	err := validateCollectionName(name)
+	err := validateCollectionName(name, false)
 	if err == nil {
 		res, err = dbc.db.Collection(name)
 	}
@@ -77,7 +84,9 @@ func (dbc *databaseContract) Collection(name string) (Collection, error) {
 }
 
 // ListCollectionsParams represents the parameters of Database.ListCollections method.
-type ListCollectionsParams struct{}
+type ListCollectionsParams struct {
+	Name string // if set, only the collection with that name is returned
+}
 
 // ListCollectionsResult represents the results of Database.ListCollections method.
 type ListCollectionsResult struct {
@@ -89,7 +98,12 @@ type CollectionInfo struct {
 	Name            string
 	CappedSize      int64
 	CappedDocuments int64
-	_               struct{} // prevent unkeyed literals
+
+	// Settings set by Database.SetCollectionSettings; nil if not set.
+	// It can be safely modified by a caller.
+	Settings *types.Document
+
+	_ struct{} // prevent unkeyed literals
 }
 
 // Capped returns true if collection is capped.
@@ -98,6 +112,7 @@ func (ci *CollectionInfo) Capped() bool {
 }
 
 // ListCollections returns a list collections in the database sorted by name.
+// If Name is set, the list contains at most one collection with that name.
 //
 // Database may not exist; that's not an error.
 //
@@ -114,6 +129,10 @@ func (dbc *databaseContract) ListCollections(ctx context.Context, params *ListCo
 		}))
 	}
 
+	if res != nil && params != nil && params.Name != "" {
+		must.BeTrue(len(res.Collections) <= 1)
+	}
+
 	return res, err
 }
 
@@ -122,7 +141,12 @@ type CreateCollectionParams struct {
 	Name            string
 	CappedSize      int64
 	CappedDocuments int64
-	_               struct{} // prevent unkeyed literals
+
+	// Tablespace to create the collection in; empty for the default one.
+	// Handlers should check that the backend has [CapabilityTablespaces].
+	Tablespace string
+
+	_ struct{} // prevent unkeyed literals
 }
 
 // Capped returns true if capped collection creation is requested.
@@ -140,7 +164,7 @@ func (dbc *databaseContract) CreateCollection(ctx context.Context, params *Creat
 	must.BeTrue(params.CappedSize%256 == 0)
 	must.BeTrue(params.CappedDocuments >= 0)
 
-	err := validateCollectionName(params.Name)
+	err := validateCollectionName(params.Name, false)
 	if err == nil {
 		err = dbc.db.CreateCollection(ctx, params)
 	}
@@ -153,6 +177,9 @@ func (dbc *databaseContract) CreateCollection(ctx context.Context, params *Creat
 // DropCollectionParams represents the parameters of Database.DropCollection method.
 type DropCollectionParams struct {
 	Name string
+
+	// Trash allows the name with backends.TrashPrefix; it is set only by the trash decorator.
+	Trash bool
 }
 
 // DropCollection drops existing collection with valid name in the database.
@@ -161,7 +188,7 @@ type DropCollectionParams struct {
 func (dbc *databaseContract) DropCollection(ctx context.Context, params *DropCollectionParams) error {
 	defer observability.FuncCall(ctx)()
 
-	err := validateCollectionName(params.Name)
+	err := validateCollectionName(params.Name, params.Trash)
 	if err == nil {
 		err = dbc.db.DropCollection(ctx, params)
 	}
@@ -175,6 +202,9 @@ func (dbc *databaseContract) DropCollection(ctx context.Context, params *DropCol
 type RenameCollectionParams struct {
 	OldName string
 	NewName string
+
+	// Trash allows names with backends.TrashPrefix; it is set only by the trash decorator.
+	Trash bool
 }
 
 // RenameCollection renames existing collection in the database.
@@ -184,10 +214,10 @@ type RenameCollectionParams struct {
 func (dbc *databaseContract) RenameCollection(ctx context.Context, params *RenameCollectionParams) error {
 	defer observability.FuncCall(ctx)()
 
-	err := validateCollectionName(params.OldName)
+	err := validateCollectionName(params.OldName, params.Trash)
 
 	if err == nil {
-		err = validateCollectionName(params.NewName)
+		err = validateCollectionName(params.NewName, params.Trash)
 	}
 
 	if err == nil {
@@ -198,6 +228,32 @@ func (dbc *databaseContract) RenameCollection(ctx context.Context, params *Renam
 	return err
 }
 
+// SetCollectionSettingsParams represents the parameters of Database.SetCollectionSettings method.
+type SetCollectionSettingsParams struct {
+	Name     string
+	Settings *types.Document // nil removes settings
+}
+
+// SetCollectionSettings replaces settings of existing collection with valid name in the database.
+//
+// Settings are opaque for the backend: they are defined by the handler and stored in the collection metadata.
+// They are kept when the collection is renamed and removed when it is dropped.
+// Settings are returned by ListCollections.
+//
+// The errors for non-existing database and non-existing collection are the same.
+func (dbc *databaseContract) SetCollectionSettings(ctx context.Context, params *SetCollectionSettingsParams) error {
+	defer observability.FuncCall(ctx)()
+
+	err := validateCollectionName(params.Name, false)
+	if err == nil {
+		err = dbc.db.SetCollectionSettings(ctx, params)
+	}
+
+	checkError(err, ErrorCodeCollectionNameIsInvalid, ErrorCodeCollectionDoesNotExist)
+
+	return err
+}
+
 // DatabaseStatsParams represents the parameters of Database.Stats method.
 type DatabaseStatsParams struct {
 	Refresh bool
@@ -223,6 +279,85 @@ func (dbc *databaseContract) Stats(ctx context.Context, params *DatabaseStatsPar
 	return res, err
 }
 
+// MetadataProblemType represents a kind of discrepancy between FerretDB metadata and the actual backend schema.
+type MetadataProblemType string
+
+const (
+	// MetadataProblemMissingTable indicates a collection whose table does not exist.
+	MetadataProblemMissingTable = MetadataProblemType("missingTable")
+
+	// MetadataProblemOrphanTable indicates a table that does not belong to any collection.
+	MetadataProblemOrphanTable = MetadataProblemType("orphanTable")
+
+	// MetadataProblemMissingIndex indicates a collection index that does not exist.
+	MetadataProblemMissingIndex = MetadataProblemType("missingIndex")
+)
+
+// MetadataProblem represents a single discrepancy between FerretDB metadata and the actual backend schema.
+type MetadataProblem struct {
+	Type       MetadataProblemType
+	Collection string // empty for orphan tables
+	Table      string
+	Index      string // only for missing indexes
+	Repaired   bool
+}
+
+// ValidateMetadataParams represents the parameters of Database.ValidateMetadata method.
+type ValidateMetadataParams struct {
+	Repair bool
+}
+
+// ValidateMetadataResult represents the results of Database.ValidateMetadata method.
+type ValidateMetadataResult struct {
+	Problems []MetadataProblem
+}
+
+// ValidateMetadata compares FerretDB metadata with the actual backend schema
+// and returns found problems.
+//
+// If Repair is true, problems that could be fixed without losing data are fixed:
+// collections without tables are removed from the metadata and missing indexes are recreated.
+// Orphan tables are never removed.
+//
+// Database may not exist; that's not an error.
+func (dbc *databaseContract) ValidateMetadata(ctx context.Context, params *ValidateMetadataParams) (*ValidateMetadataResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	res, err := dbc.db.ValidateMetadata(ctx, params)
+	checkError(err)
+
+	return res, err
+}
+
+// QueryRawParams represents the parameters of Database.QueryRaw method.
+type QueryRawParams struct {
+	SQL     string
+	Timeout time.Duration // 0 means no limit
+}
+
+// QueryRawResult represents the results of Database.QueryRaw method.
+type QueryRawResult struct {
+	Iter types.DocumentsIterator
+}
+
+// QueryRaw executes the given raw SQL query in the database and returns documents.
+//
+// Each returned row should have a single column with a JSON object
+// that is converted to a document like the import command does for JSON data.
+//
+// The query is executed in a read-only mode; queries that modify data fail.
+// If Timeout is set, the query fails if it takes longer.
+//
+// Database may not exist; that's not an error, but an empty iterator is returned.
+func (dbc *databaseContract) QueryRaw(ctx context.Context, params *QueryRawParams) (*QueryRawResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	res, err := dbc.db.QueryRaw(ctx, params)
+	checkError(err)
+
+	return res, err
+}
+
 // check interfaces
 var (
 	_ Database = (*databaseContract)(nil)
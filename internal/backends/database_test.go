@@ -0,0 +1,90 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backends_test // to avoid import cycle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestDatabaseSetCollectionSettings(t *testing.T) {
+	t.Parallel()
+
+	ctx := conninfo.Ctx(testutil.Ctx(t), conninfo.New())
+
+	for name, b := range testBackends(t) {
+		name, b := name, b
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbName, collName := testutil.DatabaseName(t), testutil.CollectionName(t)
+			otherCollName := collName + "_other"
+
+			db, err := b.Database(dbName)
+			require.NoError(t, err)
+
+			settings := must.NotFail(types.NewDocument("foo", "bar"))
+
+			err = db.SetCollectionSettings(ctx, &backends.SetCollectionSettingsParams{Name: collName, Settings: settings})
+			assertErrorCode(t, err, backends.ErrorCodeCollectionDoesNotExist)
+
+			for _, name := range []string{collName, otherCollName} {
+				require.NoError(t, db.CreateCollection(ctx, &backends.CreateCollectionParams{Name: name}))
+			}
+
+			err = db.SetCollectionSettings(ctx, &backends.SetCollectionSettingsParams{Name: collName, Settings: settings})
+			require.NoError(t, err)
+
+			res, err := db.ListCollections(ctx, &backends.ListCollectionsParams{Name: collName})
+			require.NoError(t, err)
+			require.Len(t, res.Collections, 1)
+			assert.Equal(t, collName, res.Collections[0].Name)
+			testutil.AssertEqual(t, settings, res.Collections[0].Settings)
+
+			res, err = db.ListCollections(ctx, &backends.ListCollectionsParams{Name: otherCollName})
+			require.NoError(t, err)
+			require.Len(t, res.Collections, 1)
+			assert.Nil(t, res.Collections[0].Settings)
+
+			res, err = db.ListCollections(ctx, &backends.ListCollectionsParams{Name: "none"})
+			require.NoError(t, err)
+			assert.Empty(t, res.Collections)
+
+			err = db.RenameCollection(ctx, &backends.RenameCollectionParams{OldName: collName, NewName: collName + "_new"})
+			require.NoError(t, err)
+
+			res, err = db.ListCollections(ctx, &backends.ListCollectionsParams{Name: collName + "_new"})
+			require.NoError(t, err)
+			require.Len(t, res.Collections, 1)
+			testutil.AssertEqual(t, settings, res.Collections[0].Settings)
+
+			err = db.SetCollectionSettings(ctx, &backends.SetCollectionSettingsParams{Name: collName + "_new"})
+			require.NoError(t, err)
+
+			res, err = db.ListCollections(ctx, &backends.ListCollectionsParams{Name: collName + "_new"})
+			require.NoError(t, err)
+			require.Len(t, res.Collections, 1)
+			assert.Nil(t, res.Collections[0].Settings)
+		})
+	}
+}
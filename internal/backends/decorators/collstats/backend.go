@@ -0,0 +1,100 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collstats
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+)
+
+// Backend implements backends.Backend interface by delegating all methods to the wrapped backend
+// and tracking per-collection statistics.
+type Backend struct {
+	origB backends.Backend
+	r     *registry
+	l     *zap.Logger
+}
+
+// NewBackend creates a new backend that wraps the given backend.
+func NewBackend(origB backends.Backend, l *zap.Logger) *Backend {
+	return &Backend{
+		origB: origB,
+		r:     newRegistry(),
+		l:     l,
+	}
+}
+
+// Stats returns statistics of the given collection, or nil if there are none.
+func (b *Backend) Stats(db, collection string) *Stats {
+	return b.r.stats(db, collection)
+}
+
+// Close implements backends.Backend interface.
+func (b *Backend) Close() {
+	b.origB.Close()
+}
+
+// Status implements backends.Backend interface.
+func (b *Backend) Status(ctx context.Context, params *backends.StatusParams) (*backends.StatusResult, error) {
+	return b.origB.Status(ctx, params)
+}
+
+// Database implements backends.Backend interface.
+func (b *Backend) Database(name string) (backends.Database, error) {
+	origDB, err := b.origB.Database(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDatabase(origDB, name, b.r), nil
+}
+
+// ListDatabases implements backends.Backend interface.
+//
+//nolint:lll // for readability
+func (b *Backend) ListDatabases(ctx context.Context, params *backends.ListDatabasesParams) (*backends.ListDatabasesResult, error) {
+	return b.origB.ListDatabases(ctx, params)
+}
+
+// DropDatabase implements backends.Backend interface.
+func (b *Backend) DropDatabase(ctx context.Context, params *backends.DropDatabaseParams) error {
+	err := b.origB.DropDatabase(ctx, params)
+	if err == nil {
+		b.r.deleteDatabase(params.Name)
+	}
+
+	return err
+}
+
+// Describe implements prometheus.Collector.
+func (b *Backend) Describe(ch chan<- *prometheus.Desc) {
+	b.origB.Describe(ch)
+	b.r.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (b *Backend) Collect(ch chan<- prometheus.Metric) {
+	b.origB.Collect(ch)
+	b.r.Collect(ch)
+}
+
+// check interfaces
+var (
+	_ backends.Backend = (*Backend)(nil)
+)
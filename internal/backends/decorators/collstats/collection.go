@@ -0,0 +1,189 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collstats
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// collection implements backends.Collection interface by delegating all methods to the wrapped collection
+// and tracking its statistics.
+type collection struct {
+	origC  backends.Collection
+	dbName string
+	name   string
+	r      *registry
+}
+
+// newCollection creates a new collection that wraps the given collection.
+func newCollection(origC backends.Collection, dbName, name string, r *registry) backends.Collection {
+	return &collection{
+		origC:  origC,
+		dbName: dbName,
+		name:   name,
+		r:      r,
+	}
+}
+
+// Query implements backends.Collection interface.
+//
+// Only the initial query is timed; sizes of documents are recorded as they are iterated.
+func (c *collection) Query(ctx context.Context, params *backends.QueryParams) (*backends.QueryResult, error) {
+	start := time.Now()
+	res, err := c.origC.Query(ctx, params)
+	c.r.observeLatency(c.dbName, c.name, opRead, time.Since(start))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil && params.OnlyRecordIDs {
+		return res, nil
+	}
+
+	res.Iter = &queryIterator{
+		iter: res.Iter,
+		c:    c,
+	}
+
+	return res, nil
+}
+
+// Count implements backends.Collection interface.
+func (c *collection) Count(ctx context.Context, params *backends.CountParams) (*backends.CountResult, error) {
+	start := time.Now()
+	res, err := c.origC.Count(ctx, params)
+	c.r.observeLatency(c.dbName, c.name, opRead, time.Since(start))
+
+	return res, err
+}
+
+// InsertAll implements backends.Collection interface.
+func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllParams) (*backends.InsertAllResult, error) {
+	start := time.Now()
+	res, err := c.origC.InsertAll(ctx, params)
+	c.r.observeLatency(c.dbName, c.name, opWrite, time.Since(start))
+
+	if err == nil {
+		for i, doc := range params.Docs {
+			if slices.Contains(res.Skipped, i) {
+				continue
+			}
+
+			c.r.observeSize(c.dbName, c.name, opWrite, doc)
+		}
+	}
+
+	return res, err
+}
+
+// UpdateAll implements backends.Collection interface.
+func (c *collection) UpdateAll(ctx context.Context, params *backends.UpdateAllParams) (*backends.UpdateAllResult, error) {
+	start := time.Now()
+	res, err := c.origC.UpdateAll(ctx, params)
+	c.r.observeLatency(c.dbName, c.name, opWrite, time.Since(start))
+
+	if err == nil {
+		for _, doc := range params.Docs {
+			c.r.observeSize(c.dbName, c.name, opWrite, doc)
+		}
+	}
+
+	return res, err
+}
+
+// DeleteAll implements backends.Collection interface.
+func (c *collection) DeleteAll(ctx context.Context, params *backends.DeleteAllParams) (*backends.DeleteAllResult, error) {
+	start := time.Now()
+	res, err := c.origC.DeleteAll(ctx, params)
+	c.r.observeLatency(c.dbName, c.name, opWrite, time.Since(start))
+
+	return res, err
+}
+
+// FindAndModify implements backends.Collection interface.
+func (c *collection) FindAndModify(ctx context.Context, params *backends.FindAndModifyParams) (*backends.FindAndModifyResult, error) {
+	start := time.Now()
+	res, err := c.origC.FindAndModify(ctx, params)
+	c.r.observeLatency(c.dbName, c.name, opWrite, time.Since(start))
+
+	return res, err
+}
+
+// Explain implements backends.Collection interface.
+func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams) (*backends.ExplainResult, error) {
+	return c.origC.Explain(ctx, params)
+}
+
+// Stats implements backends.Collection interface.
+//
+//nolint:lll // for readability
+func (c *collection) Stats(ctx context.Context, params *backends.CollectionStatsParams) (*backends.CollectionStatsResult, error) {
+	return c.origC.Stats(ctx, params)
+}
+
+// Compact implements backends.Collection interface.
+func (c *collection) Compact(ctx context.Context, params *backends.CompactParams) (*backends.CompactResult, error) {
+	return c.origC.Compact(ctx, params)
+}
+
+// ListIndexes implements backends.Collection interface.
+func (c *collection) ListIndexes(ctx context.Context, params *backends.ListIndexesParams) (*backends.ListIndexesResult, error) {
+	return c.origC.ListIndexes(ctx, params)
+}
+
+// CreateIndexes implements backends.Collection interface.
+//
+//nolint:lll // for readability
+func (c *collection) CreateIndexes(ctx context.Context, params *backends.CreateIndexesParams) (*backends.CreateIndexesResult, error) {
+	return c.origC.CreateIndexes(ctx, params)
+}
+
+// DropIndexes implements backends.Collection interface.
+func (c *collection) DropIndexes(ctx context.Context, params *backends.DropIndexesParams) (*backends.DropIndexesResult, error) {
+	return c.origC.DropIndexes(ctx, params)
+}
+
+// queryIterator wraps query results iterator and records sizes of returned documents.
+type queryIterator struct {
+	iter types.DocumentsIterator
+	c    *collection
+}
+
+// Next implements iterator.Interface.
+func (iter *queryIterator) Next() (struct{}, *types.Document, error) {
+	k, doc, err := iter.iter.Next()
+	if err == nil {
+		iter.c.r.observeSize(iter.c.dbName, iter.c.name, opRead, doc)
+	}
+
+	return k, doc, err
+}
+
+// Close implements iterator.Interface.
+func (iter *queryIterator) Close() {
+	iter.iter.Close()
+}
+
+// check interfaces
+var (
+	_ backends.Collection     = (*collection)(nil)
+	_ types.DocumentsIterator = (*queryIterator)(nil)
+)
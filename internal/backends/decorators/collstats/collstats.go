@@ -0,0 +1,248 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package collstats provides decorators that track per-collection statistics:
+// read and write latencies and sizes of read and written documents.
+//
+// Statistics are exposed as Prometheus histograms and returned by collStats command.
+// Tracking sizes requires marshaling of all documents, so it is disabled by default.
+package collstats
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/FerretDB/FerretDB/internal/bson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// Parts of Prometheus metric names.
+const (
+	namespace = "ferretdb"
+	subsystem = "collection"
+)
+
+// Operation types used as `op` label values.
+const (
+	opRead  = "read"
+	opWrite = "write"
+)
+
+// namespaceKey identifies a collection.
+type namespaceKey struct {
+	db         string
+	collection string
+}
+
+// Bucket represents a single non-empty histogram bucket.
+type Bucket struct {
+	LowerBound float64 // upper bound of the previous bucket (exclusive), 0 for the first one
+	Count      uint64  // number of observations in this bucket only, not cumulative
+}
+
+// Histogram represents a snapshot of a single histogram.
+type Histogram struct {
+	Count   uint64
+	Sum     float64
+	Buckets []Bucket
+}
+
+// Stats represents per-collection statistics.
+//
+// Latencies are in seconds, sizes are in bytes.
+type Stats struct {
+	ReadLatency  Histogram
+	WriteLatency Histogram
+	ReadSizes    Histogram
+	WriteSizes   Histogram
+}
+
+// registry stores per-collection histograms.
+//
+//nolint:vet // for readability
+type registry struct {
+	rw    sync.RWMutex
+	known map[namespaceKey]struct{}
+
+	latency *prometheus.HistogramVec
+	sizes   *prometheus.HistogramVec
+}
+
+// newRegistry creates a new registry.
+func newRegistry() *registry {
+	return &registry{
+		known: map[namespaceKey]struct{}{},
+		latency: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "operation_duration_seconds",
+				Help:      "Storage operations latency by collection.",
+				Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 16), // 100µs - 3.3s
+			},
+			[]string{"db", "collection", "op"},
+		),
+		sizes: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "document_size_bytes",
+				Help:      "Sizes of read and written documents by collection.",
+				Buckets:   prometheus.ExponentialBuckets(64, 4, 10), // 64 B - 16 MiB
+			},
+			[]string{"db", "collection", "op"},
+		),
+	}
+}
+
+// observeLatency records the duration of the operation of the given type.
+func (r *registry) observeLatency(db, collection, op string, d time.Duration) {
+	r.markKnown(db, collection)
+	r.latency.WithLabelValues(db, collection, op).Observe(d.Seconds())
+}
+
+// observeSize records the size of the document read or written by the operation of the given type.
+func (r *registry) observeSize(db, collection, op string, doc *types.Document) {
+	r.markKnown(db, collection)
+	r.sizes.WithLabelValues(db, collection, op).Observe(float64(documentSize(doc)))
+}
+
+// markKnown remembers that the given collection has statistics.
+func (r *registry) markKnown(db, collection string) {
+	k := namespaceKey{db: db, collection: collection}
+
+	r.rw.RLock()
+	_, ok := r.known[k]
+	r.rw.RUnlock()
+
+	if ok {
+		return
+	}
+
+	r.rw.Lock()
+	r.known[k] = struct{}{}
+	r.rw.Unlock()
+}
+
+// deleteCollection removes statistics of the given collection.
+func (r *registry) deleteCollection(db, collection string) {
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	delete(r.known, namespaceKey{db: db, collection: collection})
+
+	l := prometheus.Labels{"db": db, "collection": collection}
+	r.latency.DeletePartialMatch(l)
+	r.sizes.DeletePartialMatch(l)
+}
+
+// deleteDatabase removes statistics of all collections of the given database.
+func (r *registry) deleteDatabase(db string) {
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	for k := range r.known {
+		if k.db == db {
+			delete(r.known, k)
+		}
+	}
+
+	l := prometheus.Labels{"db": db}
+	r.latency.DeletePartialMatch(l)
+	r.sizes.DeletePartialMatch(l)
+}
+
+// stats returns statistics of the given collection, or nil if there are none.
+func (r *registry) stats(db, collection string) *Stats {
+	r.rw.RLock()
+	defer r.rw.RUnlock()
+
+	if _, ok := r.known[namespaceKey{db: db, collection: collection}]; !ok {
+		return nil
+	}
+
+	return &Stats{
+		ReadLatency:  snapshot(r.latency.WithLabelValues(db, collection, opRead)),
+		WriteLatency: snapshot(r.latency.WithLabelValues(db, collection, opWrite)),
+		ReadSizes:    snapshot(r.sizes.WithLabelValues(db, collection, opRead)),
+		WriteSizes:   snapshot(r.sizes.WithLabelValues(db, collection, opWrite)),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (r *registry) Describe(ch chan<- *prometheus.Desc) {
+	r.latency.Describe(ch)
+	r.sizes.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (r *registry) Collect(ch chan<- prometheus.Metric) {
+	r.latency.Collect(ch)
+	r.sizes.Collect(ch)
+}
+
+// snapshot returns a snapshot of the given histogram with non-cumulative non-empty buckets.
+func snapshot(o prometheus.Observer) Histogram {
+	var m dto.Metric
+	must.NoError(o.(prometheus.Metric).Write(&m))
+
+	h := m.GetHistogram()
+	res := Histogram{
+		Count: h.GetSampleCount(),
+		Sum:   h.GetSampleSum(),
+	}
+
+	var prevCount uint64
+	var prevBound float64
+
+	for _, b := range h.GetBucket() {
+		if c := b.GetCumulativeCount() - prevCount; c > 0 {
+			res.Buckets = append(res.Buckets, Bucket{LowerBound: prevBound, Count: c})
+		}
+
+		prevCount = b.GetCumulativeCount()
+		prevBound = b.GetUpperBound()
+	}
+
+	// observations above the largest bound
+	if c := res.Count - prevCount; c > 0 {
+		res.Buckets = append(res.Buckets, Bucket{LowerBound: prevBound, Count: c})
+	}
+
+	return res
+}
+
+// documentSize returns the size of the document in BSON encoding.
+func documentSize(doc *types.Document) int {
+	d, err := bson.ConvertDocument(doc)
+	if err != nil {
+		return 0
+	}
+
+	b, err := d.MarshalBinary()
+	if err != nil {
+		return 0
+	}
+
+	return len(b)
+}
+
+// check interfaces
+var (
+	_ prometheus.Collector = (*registry)(nil)
+)
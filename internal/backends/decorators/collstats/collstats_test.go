@@ -0,0 +1,89 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collstats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/sqlite"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/state"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestBackend(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	origB, err := sqlite.NewBackend(&sqlite.NewBackendParams{URI: testutil.TestSQLiteURI(t, ""), L: testutil.Logger(t), P: sp})
+	require.NoError(t, err)
+
+	b := NewBackend(origB, testutil.Logger(t))
+	t.Cleanup(b.Close)
+
+	dbName := testutil.DatabaseName(t)
+	cName := testutil.CollectionName(t)
+
+	assert.Nil(t, b.Stats(dbName, cName))
+
+	db, err := b.Database(dbName)
+	require.NoError(t, err)
+
+	c, err := db.Collection(cName)
+	require.NoError(t, err)
+
+	doc := must.NotFail(types.NewDocument("_id", int32(1), "v", "foo"))
+	size := documentSize(doc)
+	require.Positive(t, size)
+
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{doc}})
+	require.NoError(t, err)
+
+	res, err := c.Query(ctx, nil)
+	require.NoError(t, err)
+
+	docs, err := iterator.ConsumeValues(iterator.Values(res.Iter))
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+
+	stats := b.Stats(dbName, cName)
+	require.NotNil(t, stats)
+
+	assert.Equal(t, uint64(1), stats.ReadLatency.Count)
+	assert.Equal(t, uint64(1), stats.WriteLatency.Count)
+
+	expected := Histogram{
+		Count:   1,
+		Sum:     float64(size),
+		Buckets: []Bucket{{LowerBound: 0, Count: 1}},
+	}
+	assert.Equal(t, expected, stats.ReadSizes)
+	assert.Equal(t, expected, stats.WriteSizes)
+
+	err = db.DropCollection(ctx, &backends.DropCollectionParams{Name: cName})
+	require.NoError(t, err)
+
+	assert.Nil(t, b.Stats(dbName, cName))
+}
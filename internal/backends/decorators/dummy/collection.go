@@ -35,6 +35,11 @@ func (c *collection) Query(ctx context.Context, params *backends.QueryParams) (*
 	return c.c.Query(ctx, params)
 }
 
+// Count implements backends.Collection interface.
+func (c *collection) Count(ctx context.Context, params *backends.CountParams) (*backends.CountResult, error) {
+	return c.c.Count(ctx, params)
+}
+
 // InsertAll implements backends.Collection interface.
 func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllParams) (*backends.InsertAllResult, error) {
 	return c.c.InsertAll(ctx, params)
@@ -50,6 +55,11 @@ func (c *collection) DeleteAll(ctx context.Context, params *backends.DeleteAllPa
 	return c.c.DeleteAll(ctx, params)
 }
 
+// FindAndModify implements backends.Collection interface.
+func (c *collection) FindAndModify(ctx context.Context, params *backends.FindAndModifyParams) (*backends.FindAndModifyResult, error) {
+	return c.c.FindAndModify(ctx, params)
+}
+
 // Explain implements backends.Collection interface.
 func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams) (*backends.ExplainResult, error) {
 	return c.c.Explain(ctx, params)
@@ -62,11 +62,28 @@ func (db *database) RenameCollection(ctx context.Context, params *backends.Renam
 	return db.db.RenameCollection(ctx, params)
 }
 
+// SetCollectionSettings implements backends.Database interface.
+func (db *database) SetCollectionSettings(ctx context.Context, params *backends.SetCollectionSettingsParams) error {
+	return db.db.SetCollectionSettings(ctx, params)
+}
+
 // Stats implements backends.Database interface.
 func (db *database) Stats(ctx context.Context, params *backends.DatabaseStatsParams) (*backends.DatabaseStatsResult, error) {
 	return db.db.Stats(ctx, params)
 }
 
+// ValidateMetadata implements backends.Database interface.
+//
+//nolint:lll // for readability
+func (db *database) ValidateMetadata(ctx context.Context, params *backends.ValidateMetadataParams) (*backends.ValidateMetadataResult, error) {
+	return db.db.ValidateMetadata(ctx, params)
+}
+
+// QueryRaw implements backends.Database interface.
+func (db *database) QueryRaw(ctx context.Context, params *backends.QueryRawParams) (*backends.QueryRawResult, error) {
+	return db.db.QueryRaw(ctx, params)
+}
+
 // check interfaces
 var (
 	_ backends.Database = (*database)(nil)
@@ -0,0 +1,343 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/observability"
+)
+
+// collection implements backends.Collection interface by adding history functionality to the wrapped collection.
+//
+// Recording is best-effort: backends do not provide transactions spanning several collections,
+// so previous document versions are read before the change and recorded after it is applied.
+// If recording fails, the error is logged, but the change is not failed because it is already applied.
+// Concurrent changes of the same documents may be recorded with versions that were not the previous ones.
+type collection struct {
+	origC   backends.Collection
+	name    string
+	dbName  string
+	origB   backends.Backend
+	history CollectionFunc
+	l       *zap.Logger
+}
+
+// newCollection creates a new collection that wraps the given collection.
+//
+//nolint:lll // for readability
+func newCollection(origC backends.Collection, name, dbName string, origB backends.Backend, history CollectionFunc, l *zap.Logger) backends.Collection {
+	return &collection{
+		origC:   origC,
+		name:    name,
+		dbName:  dbName,
+		origB:   origB,
+		history: history,
+		l:       l,
+	}
+}
+
+// Query implements backends.Collection interface.
+func (c *collection) Query(ctx context.Context, params *backends.QueryParams) (*backends.QueryResult, error) {
+	return c.origC.Query(ctx, params)
+}
+
+// Count implements backends.Collection interface.
+func (c *collection) Count(ctx context.Context, params *backends.CountParams) (*backends.CountResult, error) {
+	return c.origC.Count(ctx, params)
+}
+
+// InsertAll implements backends.Collection interface.
+func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllParams) (*backends.InsertAllResult, error) {
+	return c.origC.InsertAll(ctx, params)
+}
+
+// UpdateAll implements backends.Collection interface.
+func (c *collection) UpdateAll(ctx context.Context, params *backends.UpdateAllParams) (*backends.UpdateAllResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	hc, err := c.historyCollection(ctx)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if hc == nil || len(params.Docs) == 0 {
+		return c.origC.UpdateAll(ctx, params)
+	}
+
+	ids := make([]any, len(params.Docs))
+	for i, doc := range params.Docs {
+		ids[i] = must.NotFail(doc.Get("_id"))
+	}
+
+	prev, err := c.previous(ctx, ids)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res, err := c.origC.UpdateAll(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	c.record(ctx, hc, prev, OpUpdate)
+
+	return res, nil
+}
+
+// DeleteAll implements backends.Collection interface.
+//
+// Documents deleted by record IDs (by capped collections cleanup) are not recorded.
+func (c *collection) DeleteAll(ctx context.Context, params *backends.DeleteAllParams) (*backends.DeleteAllResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	hc, err := c.historyCollection(ctx)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if hc == nil || len(params.IDs) == 0 {
+		return c.origC.DeleteAll(ctx, params)
+	}
+
+	prev, err := c.previous(ctx, params.IDs)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res, err := c.origC.DeleteAll(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	c.record(ctx, hc, prev, OpDelete)
+
+	return res, nil
+}
+
+// FindAndModify implements backends.Collection interface.
+func (c *collection) FindAndModify(ctx context.Context, params *backends.FindAndModifyParams) (*backends.FindAndModifyResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	hc, err := c.historyCollection(ctx)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if hc == nil {
+		return c.origC.FindAndModify(ctx, params)
+	}
+
+	var change *backends.FindAndModifyChange
+	var scanned []*types.Document
+
+	p := *params
+	p.Modify = func(iter types.DocumentsIterator) (*backends.FindAndModifyChange, error) {
+		si := &scanIterator{DocumentsIterator: iter}
+		defer func() { scanned = si.docs }()
+
+		var err error
+		change, err = params.Modify(si)
+
+		return change, err
+	}
+
+	res, err := c.origC.FindAndModify(ctx, &p)
+	if err != nil || change == nil {
+		return res, err
+	}
+
+	var id any
+	var op string
+
+	switch {
+	case change.Update != nil:
+		id, op = must.NotFail(change.Update.Get("_id")), OpUpdate
+	case change.DeleteID != nil:
+		id, op = change.DeleteID, OpDelete
+	default:
+		return res, nil
+	}
+
+	for _, doc := range scanned {
+		if !types.Identical(must.NotFail(doc.Get("_id")), id) {
+			continue
+		}
+
+		c.record(ctx, hc, []*types.Document{doc}, op)
+
+		break
+	}
+
+	return res, nil
+}
+
+// Explain implements backends.Collection interface.
+func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams) (*backends.ExplainResult, error) {
+	return c.origC.Explain(ctx, params)
+}
+
+// Stats implements backends.Collection interface.
+func (c *collection) Stats(ctx context.Context, params *backends.CollectionStatsParams) (*backends.CollectionStatsResult, error) {
+	return c.origC.Stats(ctx, params)
+}
+
+// Compact implements backends.Collection interface.
+func (c *collection) Compact(ctx context.Context, params *backends.CompactParams) (*backends.CompactResult, error) {
+	return c.origC.Compact(ctx, params)
+}
+
+// ListIndexes implements backends.Collection interface.
+func (c *collection) ListIndexes(ctx context.Context, params *backends.ListIndexesParams) (*backends.ListIndexesResult, error) {
+	return c.origC.ListIndexes(ctx, params)
+}
+
+// CreateIndexes implements backends.Collection interface.
+func (c *collection) CreateIndexes(ctx context.Context, params *backends.CreateIndexesParams) (*backends.CreateIndexesResult, error) { //nolint:lll // for readability
+	return c.origC.CreateIndexes(ctx, params)
+}
+
+// DropIndexes implements backends.Collection interface.
+func (c *collection) DropIndexes(ctx context.Context, params *backends.DropIndexesParams) (*backends.DropIndexesResult, error) {
+	return c.origC.DropIndexes(ctx, params)
+}
+
+// historyCollection returns the history collection, or nil if history is disabled.
+func (c *collection) historyCollection(ctx context.Context) (backends.Collection, error) {
+	db, err := c.origB.Database(c.dbName)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	name, err := c.history(ctx, db, c.name)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if name == "" {
+		return nil, nil
+	}
+
+	hc, err := db.Collection(name)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return hc, nil
+}
+
+// previous returns current versions of documents with the given _id values.
+func (c *collection) previous(ctx context.Context, ids []any) ([]*types.Document, error) {
+	res := make([]*types.Document, 0, len(ids))
+
+	for _, id := range ids {
+		qr, err := c.origC.Query(ctx, &backends.QueryParams{
+			Filter: must.NotFail(types.NewDocument("_id", id)),
+		})
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		// the backend may return more documents than matched by the filter
+		for {
+			var doc *types.Document
+
+			if _, doc, err = qr.Iter.Next(); err != nil {
+				break
+			}
+
+			if types.Identical(must.NotFail(doc.Get("_id")), id) {
+				res = append(res, doc)
+				break
+			}
+		}
+
+		qr.Iter.Close()
+
+		if err != nil && !errors.Is(err, iterator.ErrIteratorDone) {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	return res, nil
+}
+
+// record inserts history records for the given previous document versions.
+//
+// It is called after the change is applied, so errors are logged and not returned.
+func (c *collection) record(ctx context.Context, hc backends.Collection, prev []*types.Document, op string) {
+	if len(prev) == 0 {
+		return
+	}
+
+	ns := c.dbName + "." + c.name
+
+	if err := c.insertRecords(ctx, hc, prev, op); err != nil {
+		c.l.Error("Failed to record document history", zap.String("ns", ns), zap.Int("count", len(prev)), zap.Error(err))
+		return
+	}
+
+	c.l.Debug("Recorded document history", zap.String("ns", ns), zap.Int("count", len(prev)))
+}
+
+// insertRecords inserts history records for the given previous document versions.
+func (c *collection) insertRecords(ctx context.Context, hc backends.Collection, prev []*types.Document, op string) error {
+	now := time.Now()
+	docs := make([]*types.Document, len(prev))
+
+	for i, doc := range prev {
+		var err error
+		if docs[i], err = record(doc, op, now); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	if _, err := hc.InsertAll(ctx, &backends.InsertAllParams{Docs: docs}); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// scanIterator wraps documents iterator and remembers all returned documents.
+type scanIterator struct {
+	types.DocumentsIterator
+	docs []*types.Document
+}
+
+// Next implements iterator.Interface.
+func (si *scanIterator) Next() (struct{}, *types.Document, error) {
+	k, doc, err := si.DocumentsIterator.Next()
+	if err == nil {
+		si.docs = append(si.docs, doc)
+	}
+
+	return k, doc, err
+}
+
+// check interfaces
+var (
+	_ backends.Collection     = (*collection)(nil)
+	_ types.DocumentsIterator = (*scanIterator)(nil)
+)
@@ -0,0 +1,70 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package history provides decorators that keep previous versions of updated and deleted documents.
+//
+// For collections with enabled history, every updated or deleted document is copied
+// into the history collection of the same database as it was before the change.
+// History collections are regular collections; records are never removed automatically.
+package history
+
+import (
+	"context"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// CollectionFunc returns the name of the history collection for the given collection of the given database,
+// or empty string if history is disabled for it.
+//
+// The database is the wrapped one, so the function could read collection settings without recursion.
+type CollectionFunc func(ctx context.Context, db backends.Database, cName string) (string, error)
+
+// Fields of history records.
+const (
+	FieldDocumentID = "documentId" // _id of the changed document
+	FieldOp         = "op"         // OpUpdate or OpDelete
+	FieldTime       = "ts"         // time of the change
+	FieldDocument   = "doc"        // document version before the change
+)
+
+// Operations of history records.
+const (
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+// record returns a history record for the given previous document version.
+func record(prev *types.Document, op string, t time.Time) (*types.Document, error) {
+	id, err := prev.Get("_id")
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res, err := types.NewDocument(
+		"_id", types.NewObjectID(),
+		FieldDocumentID, id,
+		FieldOp, op,
+		FieldTime, t,
+		FieldDocument, prev,
+	)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestRecord(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	prev := must.NotFail(types.NewDocument("_id", "a", "v", int32(1)))
+
+	res, err := record(prev, OpDelete, now)
+	require.NoError(t, err)
+
+	assert.IsType(t, types.ObjectID{}, must.NotFail(res.Get("_id")))
+	assert.Equal(t, "a", must.NotFail(res.Get(FieldDocumentID)))
+	assert.Equal(t, OpDelete, must.NotFail(res.Get(FieldOp)))
+	assert.Equal(t, now, must.NotFail(res.Get(FieldTime)))
+	assert.Same(t, prev, must.NotFail(res.Get(FieldDocument)))
+
+	_, err = record(must.NotFail(types.NewDocument("v", int32(1))), OpUpdate, now)
+	assert.Error(t, err)
+}
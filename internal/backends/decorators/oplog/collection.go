@@ -59,6 +59,11 @@ func (c *collection) Query(ctx context.Context, params *backends.QueryParams) (*
 	return c.origC.Query(ctx, params)
 }
 
+// Count implements backends.Collection interface.
+func (c *collection) Count(ctx context.Context, params *backends.CountParams) (*backends.CountResult, error) {
+	return c.origC.Count(ctx, params)
+}
+
 // InsertAll implements backends.Collection interface.
 func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllParams) (*backends.InsertAllResult, error) {
 	defer observability.FuncCall(ctx)()
@@ -69,12 +74,17 @@ func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllPa
 	}
 
 	if oplogC := c.oplogCollection(ctx); oplogC != nil {
-		oplogDocs := make([]*types.Document, len(params.Docs))
+		oplogDocs := make([]*types.Document, 0, len(params.Docs))
 
 		var oplogDoc *types.Document
 		now := time.Now()
 
 		for i, doc := range params.Docs {
+			// skipped duplicates were not inserted
+			if slices.Contains(res.Skipped, i) {
+				continue
+			}
+
 			d := &document{
 				o:  doc,
 				ns: c.dbName + "." + c.name,
@@ -85,7 +95,7 @@ func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllPa
 				return res, nil
 			}
 
-			oplogDocs[i] = oplogDoc
+			oplogDocs = append(oplogDocs, oplogDoc)
 		}
 
 		_, err = oplogC.InsertAll(ctx, &backends.InsertAllParams{
@@ -184,6 +194,63 @@ func (c *collection) DeleteAll(ctx context.Context, params *backends.DeleteAllPa
 	return res, nil
 }
 
+// FindAndModify implements backends.Collection interface.
+func (c *collection) FindAndModify(ctx context.Context, params *backends.FindAndModifyParams) (*backends.FindAndModifyResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	var change *backends.FindAndModifyChange
+
+	p := *params
+	p.Modify = func(iter types.DocumentsIterator) (*backends.FindAndModifyChange, error) {
+		var err error
+		change, err = params.Modify(iter)
+
+		return change, err
+	}
+
+	res, err := c.origC.FindAndModify(ctx, &p)
+	if err != nil || change == nil {
+		return res, err
+	}
+
+	oplogC := c.oplogCollection(ctx)
+	if oplogC == nil {
+		return res, nil
+	}
+
+	d := &document{
+		ns: c.dbName + "." + c.name,
+	}
+
+	switch {
+	case change.Update != nil:
+		d.o, d.op = change.Update, "u"
+	case change.Insert != nil:
+		d.o, d.op = change.Insert, "i"
+	case change.DeleteID != nil:
+		if d.o, err = types.NewDocument("_id", change.DeleteID); err != nil {
+			c.l.Error("Failed to create _id document", zap.Error(err))
+			return res, nil
+		}
+
+		d.op = "d"
+	}
+
+	oplogDoc, err := d.marshal(time.Now())
+	if err != nil {
+		c.l.Error("Failed to create document", zap.Error(err))
+		return res, nil
+	}
+
+	if _, err = oplogC.InsertAll(ctx, &backends.InsertAllParams{
+		Docs: []*types.Document{oplogDoc},
+	}); err != nil {
+		c.l.Error("Failed to insert documents", zap.Error(err))
+	}
+
+	return res, nil
+}
+
 // Explain implements backends.Collection interface.
 func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams) (*backends.ExplainResult, error) {
 	return c.origC.Explain(ctx, params)
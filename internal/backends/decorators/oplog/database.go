@@ -72,11 +72,28 @@ func (db *database) RenameCollection(ctx context.Context, params *backends.Renam
 	return db.origDB.RenameCollection(ctx, params)
 }
 
+// SetCollectionSettings implements backends.Database interface.
+func (db *database) SetCollectionSettings(ctx context.Context, params *backends.SetCollectionSettingsParams) error {
+	return db.origDB.SetCollectionSettings(ctx, params)
+}
+
 // Stats implements backends.Database interface.
 func (db *database) Stats(ctx context.Context, params *backends.DatabaseStatsParams) (*backends.DatabaseStatsResult, error) {
 	return db.origDB.Stats(ctx, params)
 }
 
+// ValidateMetadata implements backends.Database interface.
+//
+//nolint:lll // for readability
+func (db *database) ValidateMetadata(ctx context.Context, params *backends.ValidateMetadataParams) (*backends.ValidateMetadataResult, error) {
+	return db.origDB.ValidateMetadata(ctx, params)
+}
+
+// QueryRaw implements backends.Database interface.
+func (db *database) QueryRaw(ctx context.Context, params *backends.QueryRawParams) (*backends.QueryRawResult, error) {
+	return db.origDB.QueryRaw(ctx, params)
+}
+
 // check interfaces
 var (
 	_ backends.Database = (*database)(nil)
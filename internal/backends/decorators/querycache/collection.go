@@ -0,0 +1,261 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package querycache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/types/fjson"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// collection implements backends.Collection interface by delegating all methods to the wrapped collection
+// and caching query results.
+type collection struct {
+	origC  backends.Collection
+	dbName string
+	name   string
+	c      *cache
+}
+
+// newCollection creates a new collection that wraps the given collection.
+func newCollection(origC backends.Collection, dbName, name string, c *cache) backends.Collection {
+	return &collection{
+		origC:  origC,
+		dbName: dbName,
+		name:   name,
+		c:      c,
+	}
+}
+
+// queryKey returns the cache key for the given username and query parameters.
+//
+// The username is a part of the key because backends may return different documents
+// for different users of the same collection (for example, with PostgreSQL tenant isolation).
+func queryKey(username string, params *backends.QueryParams) (string, error) {
+	var key strings.Builder
+
+	fmt.Fprintf(&key, "user:%q|", username)
+
+	if params == nil {
+		return key.String(), nil
+	}
+
+	if params.Filter != nil {
+		b, err := fjson.Marshal(params.Filter)
+		if err != nil {
+			return "", lazyerrors.Error(err)
+		}
+
+		key.Write(b)
+	}
+
+	if params.Sort != nil {
+		fmt.Fprintf(&key, "|sort:%q:%t", params.Sort.Key, params.Sort.Descending)
+	}
+
+	if params.Limit != 0 {
+		fmt.Fprintf(&key, "|limit:%d", params.Limit)
+	}
+
+	if params.Distinct != "" {
+		fmt.Fprintf(&key, "|distinct:%q", params.Distinct)
+	}
+
+	if params.Projection != nil {
+		fmt.Fprintf(&key, "|projection:%q", params.Projection)
+	}
+
+	if g := params.Geo; g != nil {
+		fmt.Fprintf(&key, "|geo:%q:%q:%q", g.Field, g.Operator, g.Geometry)
+
+		if g.MinDistance != nil {
+			fmt.Fprintf(&key, ":min=%v", *g.MinDistance)
+		}
+
+		if g.MaxDistance != nil {
+			fmt.Fprintf(&key, ":max=%v", *g.MaxDistance)
+		}
+	}
+
+	return key.String(), nil
+}
+
+// Query implements backends.Collection interface.
+//
+// Results are cached unless only record IDs, sequential scan, unwinding, or text search are requested,
+// or there are more than maxEntryDocuments documents.
+func (c *collection) Query(ctx context.Context, params *backends.QueryParams) (*backends.QueryResult, error) {
+	// sequential scans are used to stream whole tables, so they are not cached;
+	// unwound results and text search results are not cached because QueryResult.Unwound
+	// and QueryResult.TextScores are not stored
+	if params != nil && (params.OnlyRecordIDs || params.SeqScan || params.Unwind != "" || params.TextSearch != nil) {
+		return c.origC.Query(ctx, params)
+	}
+
+	username, _ := conninfo.Get(ctx).Auth()
+
+	key, err := queryKey(username, params)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if docs, ok := c.c.get(c.dbName, c.name, key); ok {
+		return &backends.QueryResult{
+			Iter: iterator.Values(iterator.ForSlice(docs)),
+		}, nil
+	}
+
+	gen := c.c.generation(c.dbName, c.name)
+
+	res, err := c.origC.Query(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := iterator.ConsumeValuesN(res.Iter, maxEntryDocuments+1)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if len(docs) > maxEntryDocuments {
+		res.Iter = &prefixIterator{
+			docs: docs,
+			iter: res.Iter,
+		}
+
+		return res, nil
+	}
+
+	// the iterator is done and closed by ConsumeValuesN
+
+	// cached documents are shared between goroutines; freeze them to catch accidental modifications
+	cached := make([]*types.Document, len(docs))
+	for i, doc := range docs {
+		cached[i] = doc.DeepCopy()
+		cached[i].DeepFreeze()
+	}
+
+	c.c.set(c.dbName, c.name, key, gen, cached)
+
+	res.Iter = iterator.Values(iterator.ForSlice(docs))
+
+	return res, nil
+}
+
+// Count implements backends.Collection interface.
+//
+// Counts are not cached.
+func (c *collection) Count(ctx context.Context, params *backends.CountParams) (*backends.CountResult, error) {
+	return c.origC.Count(ctx, params)
+}
+
+// InsertAll implements backends.Collection interface.
+func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllParams) (*backends.InsertAllResult, error) {
+	defer c.c.invalidate(c.dbName, c.name)
+
+	return c.origC.InsertAll(ctx, params)
+}
+
+// UpdateAll implements backends.Collection interface.
+func (c *collection) UpdateAll(ctx context.Context, params *backends.UpdateAllParams) (*backends.UpdateAllResult, error) {
+	defer c.c.invalidate(c.dbName, c.name)
+
+	return c.origC.UpdateAll(ctx, params)
+}
+
+// DeleteAll implements backends.Collection interface.
+func (c *collection) DeleteAll(ctx context.Context, params *backends.DeleteAllParams) (*backends.DeleteAllResult, error) {
+	defer c.c.invalidate(c.dbName, c.name)
+
+	return c.origC.DeleteAll(ctx, params)
+}
+
+// FindAndModify implements backends.Collection interface.
+func (c *collection) FindAndModify(ctx context.Context, params *backends.FindAndModifyParams) (*backends.FindAndModifyResult, error) {
+	defer c.c.invalidate(c.dbName, c.name)
+
+	return c.origC.FindAndModify(ctx, params)
+}
+
+// Explain implements backends.Collection interface.
+func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams) (*backends.ExplainResult, error) {
+	return c.origC.Explain(ctx, params)
+}
+
+// Stats implements backends.Collection interface.
+//
+//nolint:lll // for readability
+func (c *collection) Stats(ctx context.Context, params *backends.CollectionStatsParams) (*backends.CollectionStatsResult, error) {
+	return c.origC.Stats(ctx, params)
+}
+
+// Compact implements backends.Collection interface.
+func (c *collection) Compact(ctx context.Context, params *backends.CompactParams) (*backends.CompactResult, error) {
+	return c.origC.Compact(ctx, params)
+}
+
+// ListIndexes implements backends.Collection interface.
+func (c *collection) ListIndexes(ctx context.Context, params *backends.ListIndexesParams) (*backends.ListIndexesResult, error) {
+	return c.origC.ListIndexes(ctx, params)
+}
+
+// CreateIndexes implements backends.Collection interface.
+//
+//nolint:lll // for readability
+func (c *collection) CreateIndexes(ctx context.Context, params *backends.CreateIndexesParams) (*backends.CreateIndexesResult, error) {
+	return c.origC.CreateIndexes(ctx, params)
+}
+
+// DropIndexes implements backends.Collection interface.
+func (c *collection) DropIndexes(ctx context.Context, params *backends.DropIndexesParams) (*backends.DropIndexesResult, error) {
+	return c.origC.DropIndexes(ctx, params)
+}
+
+// prefixIterator returns already consumed documents first, then the rest of wrapped query results.
+type prefixIterator struct {
+	docs []*types.Document
+	iter types.DocumentsIterator
+}
+
+// Next implements iterator.Interface.
+func (iter *prefixIterator) Next() (struct{}, *types.Document, error) {
+	if len(iter.docs) > 0 {
+		doc := iter.docs[0]
+		iter.docs = iter.docs[1:]
+
+		return struct{}{}, doc, nil
+	}
+
+	return iter.iter.Next()
+}
+
+// Close implements iterator.Interface.
+func (iter *prefixIterator) Close() {
+	iter.docs = nil
+	iter.iter.Close()
+}
+
+// check interfaces
+var (
+	_ backends.Collection     = (*collection)(nil)
+	_ types.DocumentsIterator = (*prefixIterator)(nil)
+)
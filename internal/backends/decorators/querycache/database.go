@@ -0,0 +1,108 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package querycache
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+)
+
+// database implements backends.Database interface by delegating all methods to the wrapped database
+// and invalidating cached query results.
+type database struct {
+	origDB backends.Database
+	name   string
+	c      *cache
+}
+
+// newDatabase creates a new database that wraps the given database.
+func newDatabase(origDB backends.Database, name string, c *cache) backends.Database {
+	return &database{
+		origDB: origDB,
+		name:   name,
+		c:      c,
+	}
+}
+
+// Collection implements backends.Database interface.
+func (db *database) Collection(name string) (backends.Collection, error) {
+	origC, err := db.origDB.Collection(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return newCollection(origC, db.name, name, db.c), nil
+}
+
+// ListCollections implements backends.Database interface.
+//
+//nolint:lll // for readability
+func (db *database) ListCollections(ctx context.Context, params *backends.ListCollectionsParams) (*backends.ListCollectionsResult, error) {
+	return db.origDB.ListCollections(ctx, params)
+}
+
+// CreateCollection implements backends.Database interface.
+func (db *database) CreateCollection(ctx context.Context, params *backends.CreateCollectionParams) error {
+	defer db.c.invalidate(db.name, params.Name)
+
+	return db.origDB.CreateCollection(ctx, params)
+}
+
+// DropCollection implements backends.Database interface.
+func (db *database) DropCollection(ctx context.Context, params *backends.DropCollectionParams) error {
+	defer db.c.invalidate(db.name, params.Name)
+
+	return db.origDB.DropCollection(ctx, params)
+}
+
+// RenameCollection implements backends.Database interface.
+func (db *database) RenameCollection(ctx context.Context, params *backends.RenameCollectionParams) error {
+	defer db.c.invalidate(db.name, params.NewName)
+	defer db.c.invalidate(db.name, params.OldName)
+
+	return db.origDB.RenameCollection(ctx, params)
+}
+
+// SetCollectionSettings implements backends.Database interface.
+func (db *database) SetCollectionSettings(ctx context.Context, params *backends.SetCollectionSettingsParams) error {
+	return db.origDB.SetCollectionSettings(ctx, params)
+}
+
+// Stats implements backends.Database interface.
+func (db *database) Stats(ctx context.Context, params *backends.DatabaseStatsParams) (*backends.DatabaseStatsResult, error) {
+	return db.origDB.Stats(ctx, params)
+}
+
+// ValidateMetadata implements backends.Database interface.
+//
+//nolint:lll // for readability
+func (db *database) ValidateMetadata(ctx context.Context, params *backends.ValidateMetadataParams) (*backends.ValidateMetadataResult, error) {
+	return db.origDB.ValidateMetadata(ctx, params)
+}
+
+// QueryRaw implements backends.Database interface.
+//
+// Raw SQL queries could modify any collection, so all cached results of the database are invalidated.
+func (db *database) QueryRaw(ctx context.Context, params *backends.QueryRawParams) (*backends.QueryRawResult, error) {
+	defer db.c.invalidate(db.name, "")
+
+	return db.origDB.QueryRaw(ctx, params)
+}
+
+// check interfaces
+var (
+	_ backends.Database = (*database)(nil)
+)
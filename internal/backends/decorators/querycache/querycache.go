@@ -0,0 +1,288 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package querycache provides decorators that cache query results.
+//
+// Results of Collection.Query calls are cached by collection, authenticated user, and query parameters
+// (filter, sort, and limit) and invalidated on any write to the collection
+// made through the decorator.
+// Writes made by other FerretDB instances or directly to the backend are not tracked,
+// so the cache should be enabled only if FerretDB is the sole writer.
+package querycache
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// Parts of Prometheus metric names.
+const (
+	namespace = "ferretdb"
+	subsystem = "query_cache"
+)
+
+// maxEntryDocuments is the maximum number of documents in a single cached result.
+// Larger results are returned as usual, but not cached.
+const maxEntryDocuments = 1000
+
+// Store stores cached query results.
+//
+// Stored documents are owned by the store and should not be modified.
+// Implementations should be safe for concurrent use.
+// The in-memory implementation is provided by NewMemoryStore;
+// others (for example, Redis-based) could be plugged in with NewBackend.
+type Store interface {
+	// Get returns cached documents for the given key of the given collection, or false if there is no entry.
+	Get(db, collection, key string) ([]*types.Document, bool)
+
+	// Set stores documents for the given key of the given collection.
+	Set(db, collection, key string, docs []*types.Document)
+
+	// Invalidate removes all entries of the given collection,
+	// or of all collections of the given database if collection is empty.
+	Invalidate(db, collection string)
+}
+
+// memoryEntry represents a single entry of the memory store.
+type memoryEntry struct {
+	db         string
+	collection string
+	key        string
+	docs       []*types.Document
+}
+
+// memoryStore is an in-memory LRU Store.
+//
+//nolint:vet // for readability
+type memoryStore struct {
+	m       sync.Mutex
+	lru     *list.List                                     // of *memoryEntry, most recently used first
+	entries map[string]map[string]map[string]*list.Element // db -> collection -> key -> element
+
+	maxEntries int
+}
+
+// NewMemoryStore creates a new in-memory Store with the given maximum number of entries.
+// The least recently used entries are evicted first.
+func NewMemoryStore(maxEntries int) Store {
+	return &memoryStore{
+		lru:        list.New(),
+		entries:    map[string]map[string]map[string]*list.Element{},
+		maxEntries: maxEntries,
+	}
+}
+
+// Get implements Store interface.
+func (s *memoryStore) Get(db, collection, key string) ([]*types.Document, bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	el := s.entries[db][collection][key]
+	if el == nil {
+		return nil, false
+	}
+
+	s.lru.MoveToFront(el)
+
+	return el.Value.(*memoryEntry).docs, true
+}
+
+// Set implements Store interface.
+func (s *memoryStore) Set(db, collection, key string, docs []*types.Document) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if el := s.entries[db][collection][key]; el != nil {
+		el.Value.(*memoryEntry).docs = docs
+		s.lru.MoveToFront(el)
+
+		return
+	}
+
+	for s.lru.Len() >= s.maxEntries && s.lru.Len() > 0 {
+		s.remove(s.lru.Back())
+	}
+
+	if s.entries[db] == nil {
+		s.entries[db] = map[string]map[string]*list.Element{}
+	}
+
+	if s.entries[db][collection] == nil {
+		s.entries[db][collection] = map[string]*list.Element{}
+	}
+
+	s.entries[db][collection][key] = s.lru.PushFront(&memoryEntry{
+		db:         db,
+		collection: collection,
+		key:        key,
+		docs:       docs,
+	})
+}
+
+// Invalidate implements Store interface.
+func (s *memoryStore) Invalidate(db, collection string) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	for c, keys := range s.entries[db] {
+		if collection != "" && c != collection {
+			continue
+		}
+
+		for _, el := range keys {
+			s.remove(el)
+		}
+	}
+}
+
+// remove removes the given element.
+//
+// It should be called with the lock held.
+func (s *memoryStore) remove(el *list.Element) {
+	e := s.lru.Remove(el).(*memoryEntry)
+
+	delete(s.entries[e.db][e.collection], e.key)
+
+	if len(s.entries[e.db][e.collection]) == 0 {
+		delete(s.entries[e.db], e.collection)
+	}
+
+	if len(s.entries[e.db]) == 0 {
+		delete(s.entries, e.db)
+	}
+}
+
+// cache wraps Store with generation tracking and metrics.
+//
+// Generations prevent storing results of queries that were running concurrently with writes.
+//
+//nolint:vet // for readability
+type cache struct {
+	store Store
+
+	rw          sync.RWMutex
+	generations map[string]map[string]uint64 // db -> collection -> generation
+	dbGens      map[string]uint64            // db -> generation
+
+	requests      *prometheus.CounterVec
+	invalidations prometheus.Counter
+}
+
+// newCache creates a new cache with the given store.
+func newCache(store Store) *cache {
+	return &cache{
+		store:       store,
+		generations: map[string]map[string]uint64{},
+		dbGens:      map[string]uint64{},
+		requests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "requests_total",
+				Help:      "Total number of query result cache requests.",
+			},
+			[]string{"result"},
+		),
+		invalidations: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "invalidations_total",
+				Help:      "Total number of query result cache invalidations.",
+			},
+		),
+	}
+}
+
+// generation returns the current generation of the given collection.
+func (c *cache) generation(db, collection string) uint64 {
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+
+	return c.dbGens[db] + c.generations[db][collection]
+}
+
+// get returns deep copies of cached documents, or false if there is no entry.
+func (c *cache) get(db, collection, key string) ([]*types.Document, bool) {
+	docs, ok := c.store.Get(db, collection, key)
+	if !ok {
+		c.requests.WithLabelValues("miss").Inc()
+		return nil, false
+	}
+
+	c.requests.WithLabelValues("hit").Inc()
+
+	res := make([]*types.Document, len(docs))
+	for i, doc := range docs {
+		res[i] = doc.DeepCopy()
+	}
+
+	return res, true
+}
+
+// set stores documents unless the collection was modified since the given generation.
+// The caller should not use documents after that call.
+func (c *cache) set(db, collection, key string, gen uint64, docs []*types.Document) {
+	// hold the read lock so invalidate can't run between the check and the store
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+
+	if c.dbGens[db]+c.generations[db][collection] != gen {
+		return
+	}
+
+	c.store.Set(db, collection, key, docs)
+}
+
+// invalidate removes cached results of the given collection,
+// or of all collections of the given database if collection is empty.
+func (c *cache) invalidate(db, collection string) {
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	if collection == "" {
+		c.dbGens[db]++
+	} else {
+		if c.generations[db] == nil {
+			c.generations[db] = map[string]uint64{}
+		}
+
+		c.generations[db][collection]++
+	}
+
+	c.store.Invalidate(db, collection)
+	c.invalidations.Inc()
+}
+
+// Describe implements prometheus.Collector.
+func (c *cache) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.invalidations.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *cache) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.invalidations.Collect(ch)
+}
+
+// check interfaces
+var (
+	_ Store                = (*memoryStore)(nil)
+	_ prometheus.Collector = (*cache)(nil)
+)
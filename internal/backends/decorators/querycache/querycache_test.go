@@ -0,0 +1,281 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package querycache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/sqlite"
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/state"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestMemoryStore(t *testing.T) {
+	t.Parallel()
+
+	s := NewMemoryStore(2)
+
+	_, ok := s.Get("db", "c1", "k")
+	assert.False(t, ok)
+
+	doc := must.NotFail(types.NewDocument("v", int32(1)))
+	s.Set("db", "c1", "k", []*types.Document{doc})
+	s.Set("db", "c2", "k", nil)
+
+	docs, ok := s.Get("db", "c1", "k")
+	require.True(t, ok)
+	assert.Equal(t, []*types.Document{doc}, docs)
+
+	// c1 was used more recently, so c2 is evicted
+	s.Set("db", "c3", "k", nil)
+
+	_, ok = s.Get("db", "c2", "k")
+	assert.False(t, ok)
+
+	_, ok = s.Get("db", "c3", "k")
+	assert.True(t, ok)
+
+	s.Invalidate("db", "c1")
+
+	_, ok = s.Get("db", "c1", "k")
+	assert.False(t, ok)
+
+	_, ok = s.Get("db", "c3", "k")
+	assert.True(t, ok)
+
+	s.Invalidate("db", "")
+
+	_, ok = s.Get("db", "c3", "k")
+	assert.False(t, ok)
+	assert.Empty(t, s.(*memoryStore).entries)
+	assert.Zero(t, s.(*memoryStore).lru.Len())
+}
+
+func TestCacheGeneration(t *testing.T) {
+	t.Parallel()
+
+	c := newCache(NewMemoryStore(10))
+
+	gen := c.generation("db", "c")
+	c.invalidate("db", "c")
+
+	// results of a query started before the write are not stored
+	c.set("db", "c", "k", gen, nil)
+	_, ok := c.get("db", "c", "k")
+	assert.False(t, ok)
+
+	gen = c.generation("db", "c")
+	c.invalidate("db", "")
+
+	c.set("db", "c", "k", gen, nil)
+	_, ok = c.get("db", "c", "k")
+	assert.False(t, ok)
+
+	c.set("db", "c", "k", c.generation("db", "c"), nil)
+	_, ok = c.get("db", "c", "k")
+	assert.True(t, ok)
+}
+
+func TestBackend(t *testing.T) {
+	t.Parallel()
+
+	ctx := conninfo.Ctx(testutil.Ctx(t), conninfo.New())
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	origB, err := sqlite.NewBackend(&sqlite.NewBackendParams{URI: testutil.TestSQLiteURI(t, ""), L: testutil.Logger(t), P: sp})
+	require.NoError(t, err)
+
+	b := NewBackend(origB, NewMemoryStore(10), testutil.Logger(t))
+	t.Cleanup(b.Close)
+
+	dbName := testutil.DatabaseName(t)
+	cName := testutil.CollectionName(t)
+
+	db, err := b.Database(dbName)
+	require.NoError(t, err)
+
+	coll, err := db.Collection(cName)
+	require.NoError(t, err)
+
+	query := func() []*types.Document {
+		t.Helper()
+
+		res, err := coll.Query(ctx, new(backends.QueryParams))
+		require.NoError(t, err)
+
+		docs, err := iterator.ConsumeValues(res.Iter)
+		require.NoError(t, err)
+
+		return docs
+	}
+
+	_, err = coll.InsertAll(ctx, &backends.InsertAllParams{
+		Docs: []*types.Document{must.NotFail(types.NewDocument("_id", int32(1), "v", "foo"))},
+	})
+	require.NoError(t, err)
+
+	docs := query()
+	require.Len(t, docs, 1)
+
+	// modifying returned documents does not affect the cache
+	docs[0].Set("v", "bar")
+
+	docs = query()
+	require.Len(t, docs, 1)
+	assert.Equal(t, "foo", must.NotFail(docs[0].Get("v")))
+
+	// writes behind the decorator's back are not visible
+	origColl, err := must.NotFail(origB.Database(dbName)).Collection(cName)
+	require.NoError(t, err)
+
+	_, err = origColl.InsertAll(ctx, &backends.InsertAllParams{
+		Docs: []*types.Document{must.NotFail(types.NewDocument("_id", int32(2), "v", "bar"))},
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, query(), 1)
+
+	// but writes through the decorator invalidate the cache
+	_, err = coll.DeleteAll(ctx, &backends.DeleteAllParams{IDs: []any{int32(1)}})
+	require.NoError(t, err)
+
+	docs = query()
+	require.Len(t, docs, 1)
+	assert.Equal(t, int32(2), must.NotFail(docs[0].Get("_id")))
+
+	err = b.DropDatabase(ctx, &backends.DropDatabaseParams{Name: dbName})
+	require.NoError(t, err)
+
+	assert.Empty(t, query())
+}
+
+func TestBackendTextSearch(t *testing.T) {
+	t.Parallel()
+
+	ctx := conninfo.Ctx(testutil.Ctx(t), conninfo.New())
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	origB, err := sqlite.NewBackend(&sqlite.NewBackendParams{URI: testutil.TestSQLiteURI(t, ""), L: testutil.Logger(t), P: sp})
+	require.NoError(t, err)
+
+	b := NewBackend(origB, NewMemoryStore(10), testutil.Logger(t))
+	t.Cleanup(b.Close)
+
+	db, err := b.Database(testutil.DatabaseName(t))
+	require.NoError(t, err)
+
+	coll, err := db.Collection(testutil.CollectionName(t))
+	require.NoError(t, err)
+
+	_, err = coll.CreateIndexes(ctx, &backends.CreateIndexesParams{
+		Indexes: []backends.IndexInfo{{
+			Name: "v_text",
+			Key:  []backends.IndexKeyPair{{Field: "v"}},
+			Text: true,
+		}},
+	})
+	require.NoError(t, err)
+
+	_, err = coll.InsertAll(ctx, &backends.InsertAllParams{
+		Docs: []*types.Document{
+			must.NotFail(types.NewDocument("_id", int32(1), "v", "foo bar")),
+			must.NotFail(types.NewDocument("_id", int32(2), "v", "baz")),
+		},
+	})
+	require.NoError(t, err)
+
+	params := &backends.QueryParams{TextSearch: &backends.TextSearch{Terms: []string{"foo"}}}
+
+	// the same query is repeated to check that text scores are returned every time
+	for i := 0; i < 2; i++ {
+		res, err := coll.Query(ctx, params)
+		require.NoError(t, err)
+
+		docs, err := iterator.ConsumeValues(res.Iter)
+		require.NoError(t, err)
+		require.Len(t, docs, 1)
+
+		assert.Contains(t, res.TextScores, docs[0])
+	}
+}
+
+func TestBackendUsers(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	origB, err := sqlite.NewBackend(&sqlite.NewBackendParams{URI: testutil.TestSQLiteURI(t, ""), L: testutil.Logger(t), P: sp})
+	require.NoError(t, err)
+
+	b := NewBackend(origB, NewMemoryStore(10), testutil.Logger(t))
+	t.Cleanup(b.Close)
+
+	dbName := testutil.DatabaseName(t)
+	cName := testutil.CollectionName(t)
+
+	coll, err := must.NotFail(b.Database(dbName)).Collection(cName)
+	require.NoError(t, err)
+
+	origColl, err := must.NotFail(origB.Database(dbName)).Collection(cName)
+	require.NoError(t, err)
+
+	query := func(username string) []*types.Document {
+		t.Helper()
+
+		ci := conninfo.New()
+		ci.SetAuth(username, "password")
+
+		res, err := coll.Query(conninfo.Ctx(ctx, ci), new(backends.QueryParams))
+		require.NoError(t, err)
+
+		docs, err := iterator.ConsumeValues(res.Iter)
+		require.NoError(t, err)
+
+		return docs
+	}
+
+	_, err = origColl.InsertAll(conninfo.Ctx(ctx, conninfo.New()), &backends.InsertAllParams{
+		Docs: []*types.Document{must.NotFail(types.NewDocument("_id", int32(1)))},
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, query("alice"), 1)
+
+	// backends with tenant isolation return different documents for different users;
+	// simulate that with a write behind the decorator's back
+	_, err = origColl.InsertAll(conninfo.Ctx(ctx, conninfo.New()), &backends.InsertAllParams{
+		Docs: []*types.Document{must.NotFail(types.NewDocument("_id", int32(2)))},
+	})
+	require.NoError(t, err)
+
+	// results cached for one user are not returned to another
+	assert.Len(t, query("bob"), 2)
+	assert.Len(t, query("alice"), 1)
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sizecache provides decorators that cache database size estimations.
+//
+// Computing database sizes is expensive for some backends (notably, PostgreSQL),
+// while drivers and monitoring tools call listDatabases frequently.
+// Cached estimations are refreshed periodically
+// and are never returned if they are older than the configured maximum age.
+package sizecache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/exp/maps"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+)
+
+// Parts of Prometheus metric names.
+const (
+	namespace = "ferretdb"
+	subsystem = "size_cache"
+)
+
+// entry represents a cached database size estimation.
+type entry struct {
+	stats   backends.DatabaseStatsResult
+	fetched time.Time
+}
+
+// cache stores database size estimations.
+//
+//nolint:vet // for readability
+type cache struct {
+	rw sync.RWMutex
+	m  map[string]*entry
+
+	maxAge time.Duration
+	now    func() time.Time
+
+	requests *prometheus.CounterVec
+}
+
+// newCache creates a new cache with the given maximum age of entries.
+func newCache(maxAge time.Duration) *cache {
+	return &cache{
+		m:      map[string]*entry{},
+		maxAge: maxAge,
+		now:    time.Now,
+		requests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "requests_total",
+				Help:      "Total number of database size estimation requests.",
+			},
+			[]string{"result"},
+		),
+	}
+}
+
+// get returns a copy of the cached estimation for the given database,
+// or nil if there is no entry or it is older than the maximum age.
+func (c *cache) get(name string) *backends.DatabaseStatsResult {
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+
+	e := c.m[name]
+	if e == nil || c.now().Sub(e.fetched) > c.maxAge {
+		c.requests.WithLabelValues("miss").Inc()
+		return nil
+	}
+
+	c.requests.WithLabelValues("hit").Inc()
+
+	res := e.stats
+
+	return &res
+}
+
+// set stores a copy of the given estimation for the given database.
+func (c *cache) set(name string, stats *backends.DatabaseStatsResult) {
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	c.m[name] = &entry{
+		stats:   *stats,
+		fetched: c.now(),
+	}
+}
+
+// delete removes the entry for the given database.
+func (c *cache) delete(name string) {
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	delete(c.m, name)
+}
+
+// names returns names of all cached databases.
+func (c *cache) names() []string {
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+
+	return maps.Keys(c.m)
+}
+
+// Describe implements prometheus.Collector.
+func (c *cache) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *cache) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+}
+
+// check interfaces
+var (
+	_ prometheus.Collector = (*cache)(nil)
+)
@@ -0,0 +1,107 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sizecache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/sqlite"
+	"github.com/FerretDB/FerretDB/internal/util/state"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	c := newCache(time.Minute)
+	c.now = func() time.Time { return now }
+
+	assert.Nil(t, c.get("db"))
+
+	stats := &backends.DatabaseStatsResult{SizeTotal: 42}
+	c.set("db", stats)
+
+	stats.SizeTotal = 0
+	assert.Equal(t, &backends.DatabaseStatsResult{SizeTotal: 42}, c.get("db"), "cache should store a copy")
+
+	now = now.Add(time.Minute)
+	assert.NotNil(t, c.get("db"))
+
+	now = now.Add(time.Second)
+	assert.Nil(t, c.get("db"), "stale entry should not be returned")
+	assert.Equal(t, []string{"db"}, c.names())
+
+	c.delete("db")
+	assert.Empty(t, c.names())
+}
+
+func TestBackend(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	origB, err := sqlite.NewBackend(&sqlite.NewBackendParams{URI: testutil.TestSQLiteURI(t, ""), L: testutil.Logger(t), P: sp})
+	require.NoError(t, err)
+
+	b := NewBackend(origB, time.Hour, testutil.Logger(t))
+	t.Cleanup(b.Close)
+
+	dbName := testutil.DatabaseName(t)
+
+	db, err := b.Database(dbName)
+	require.NoError(t, err)
+
+	_, err = db.Stats(ctx, nil)
+	require.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseDoesNotExist), "errors should not be cached")
+
+	err = db.CreateCollection(ctx, &backends.CreateCollectionParams{Name: testutil.CollectionName(t)})
+	require.NoError(t, err)
+
+	expected, err := db.Stats(ctx, &backends.DatabaseStatsParams{Refresh: true})
+	require.NoError(t, err)
+
+	c := b.c
+	assert.Equal(t, expected, c.get(dbName))
+
+	actual, err := db.Stats(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	// database dropped behind the decorator's back is removed by the refresh
+	err = origB.DropDatabase(ctx, &backends.DropDatabaseParams{Name: dbName})
+	require.NoError(t, err)
+	assert.NotNil(t, c.get(dbName))
+
+	require.NoError(t, b.Refresh(ctx))
+	assert.Nil(t, c.get(dbName))
+
+	c.set(dbName, expected)
+	err = b.DropDatabase(ctx, &backends.DropDatabaseParams{Name: dbName})
+	require.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseDoesNotExist))
+	assert.Nil(t, c.get(dbName))
+
+	_, err = db.Stats(ctx, nil)
+	require.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseDoesNotExist))
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trash
+
+import (
+	"context"
+	"slices"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+)
+
+// database implements backends.Database interface by delegating all methods to the wrapped database
+// and moving dropped collections to the trash.
+type database struct {
+	origDB backends.Database
+	name   string
+	b      *Backend
+}
+
+// newDatabase creates a new database that wraps the given database.
+func newDatabase(origDB backends.Database, name string, b *Backend) backends.Database {
+	return &database{
+		origDB: origDB,
+		name:   name,
+		b:      b,
+	}
+}
+
+// Collection implements backends.Database interface.
+func (db *database) Collection(name string) (backends.Collection, error) {
+	if isTrashName(name) {
+		return nil, newTrashNameError(name)
+	}
+
+	return db.origDB.Collection(name)
+}
+
+// ListCollections implements backends.Database interface.
+//
+// Collections in the trash are not returned.
+//
+//nolint:lll // for readability
+func (db *database) ListCollections(ctx context.Context, params *backends.ListCollectionsParams) (*backends.ListCollectionsResult, error) {
+	res, err := db.origDB.ListCollections(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Collections = slices.DeleteFunc(res.Collections, func(c backends.CollectionInfo) bool {
+		return isTrashName(c.Name)
+	})
+
+	return res, nil
+}
+
+// CreateCollection implements backends.Database interface.
+func (db *database) CreateCollection(ctx context.Context, params *backends.CreateCollectionParams) error {
+	if isTrashName(params.Name) {
+		return newTrashNameError(params.Name)
+	}
+
+	return db.origDB.CreateCollection(ctx, params)
+}
+
+// DropCollection implements backends.Database interface.
+//
+// The collection is moved to the trash instead.
+func (db *database) DropCollection(ctx context.Context, params *backends.DropCollectionParams) error {
+	if isTrashName(params.Name) {
+		return newTrashNameError(params.Name)
+	}
+
+	return db.b.drop(ctx, db.origDB, db.name, params.Name)
+}
+
+// RenameCollection implements backends.Database interface.
+func (db *database) RenameCollection(ctx context.Context, params *backends.RenameCollectionParams) error {
+	if isTrashName(params.OldName) {
+		return newTrashNameError(params.OldName)
+	}
+
+	if isTrashName(params.NewName) {
+		return newTrashNameError(params.NewName)
+	}
+
+	return db.origDB.RenameCollection(ctx, params)
+}
+
+// SetCollectionSettings implements backends.Database interface.
+func (db *database) SetCollectionSettings(ctx context.Context, params *backends.SetCollectionSettingsParams) error {
+	return db.origDB.SetCollectionSettings(ctx, params)
+}
+
+// Stats implements backends.Database interface.
+func (db *database) Stats(ctx context.Context, params *backends.DatabaseStatsParams) (*backends.DatabaseStatsResult, error) {
+	return db.origDB.Stats(ctx, params)
+}
+
+// ValidateMetadata implements backends.Database interface.
+//
+//nolint:lll // for readability
+func (db *database) ValidateMetadata(ctx context.Context, params *backends.ValidateMetadataParams) (*backends.ValidateMetadataResult, error) {
+	return db.origDB.ValidateMetadata(ctx, params)
+}
+
+// QueryRaw implements backends.Database interface.
+func (db *database) QueryRaw(ctx context.Context, params *backends.QueryRawParams) (*backends.QueryRawResult, error) {
+	return db.origDB.QueryRaw(ctx, params)
+}
+
+// check interfaces
+var (
+	_ backends.Database = (*database)(nil)
+)
@@ -0,0 +1,305 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package trash provides decorators that keep dropped collections in the trash.
+//
+// Dropped collections are renamed to names with backends.TrashPrefix and hidden from users.
+// They can be restored or purged; expired collections are purged periodically.
+// Dropped databases are not kept.
+package trash
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// Entry represents a dropped collection in the trash.
+type Entry struct {
+	Name       string // name in the trash
+	Collection string // original name
+	Dropped    time.Time
+}
+
+// trashName returns the trash name for the collection dropped at the given time.
+func trashName(collection string, dropped time.Time) string {
+	return backends.TrashPrefix + strconv.FormatInt(dropped.UnixNano(), 10) + "_" + collection
+}
+
+// isTrashName returns true if the given collection name is used for the trash.
+func isTrashName(name string) bool {
+	return strings.HasPrefix(name, backends.TrashPrefix)
+}
+
+// parseTrashName returns the trash entry for the given name, or nil if it is not a valid trash name.
+func parseTrashName(name string) *Entry {
+	if !isTrashName(name) {
+		return nil
+	}
+
+	ts, collection, ok := strings.Cut(strings.TrimPrefix(name, backends.TrashPrefix), "_")
+	if !ok || collection == "" {
+		return nil
+	}
+
+	ns, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	return &Entry{
+		Name:       name,
+		Collection: collection,
+		Dropped:    time.Unix(0, ns).UTC(),
+	}
+}
+
+// Backend implements backends.Backend interface by delegating all methods to the wrapped backend
+// and keeping dropped collections in the trash.
+type Backend struct {
+	origB     backends.Backend
+	retention time.Duration
+	l         *zap.Logger
+	now       func() time.Time
+}
+
+// NewBackend creates a new backend that wraps the given backend.
+//
+// Dropped collections are kept in the trash for the given retention period that should be positive.
+// PurgeExpired should be called periodically to drop them after that.
+func NewBackend(origB backends.Backend, retention time.Duration, l *zap.Logger) *Backend {
+	return &Backend{
+		origB:     origB,
+		retention: retention,
+		l:         l,
+		now:       time.Now,
+	}
+}
+
+// PurgeExpired drops collections that were kept in the trash longer than the retention period.
+func (b *Backend) PurgeExpired(ctx context.Context) error {
+	res, err := b.origB.ListDatabases(ctx, nil)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	expired := b.now().Add(-b.retention)
+
+	for _, dbInfo := range res.Databases {
+		entries, err := b.List(ctx, dbInfo.Name)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		for _, e := range entries {
+			if e.Dropped.After(expired) {
+				continue
+			}
+
+			b.l.Info("Purging expired collection", zap.String("db", dbInfo.Name), zap.String("collection", e.Collection))
+
+			if err = b.Purge(ctx, dbInfo.Name, e.Name); err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// List returns all collections in the trash of the given database, sorted by name.
+func (b *Backend) List(ctx context.Context, dbName string) ([]Entry, error) {
+	db, err := b.origB.Database(dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := db.ListCollections(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []Entry
+
+	for _, c := range list.Collections {
+		if e := parseTrashName(c.Name); e != nil {
+			res = append(res, *e)
+		}
+	}
+
+	return res, nil
+}
+
+// get returns the trash entry with the given name,
+// or backends.ErrorCodeCollectionDoesNotExist error.
+func (b *Backend) get(ctx context.Context, dbName, name string) (*Entry, error) {
+	entries, err := b.List(ctx, dbName)
+	if err != nil && !backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseDoesNotExist) {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if e.Name == name {
+			return &e, nil
+		}
+	}
+
+	return nil, backends.NewError(
+		backends.ErrorCodeCollectionDoesNotExist,
+		lazyerrors.Errorf("no collection %s.%s in the trash", dbName, name),
+	)
+}
+
+// Restore renames the collection with the given trash name back to its original name,
+// or to the given name if it is not empty.
+// It returns the restored collection name.
+func (b *Backend) Restore(ctx context.Context, dbName, name, to string) (string, error) {
+	e, err := b.get(ctx, dbName, name)
+	if err != nil {
+		return "", err
+	}
+
+	if to == "" {
+		to = e.Collection
+	}
+
+	if isTrashName(to) {
+		return "", backends.NewError(backends.ErrorCodeCollectionNameIsInvalid, nil)
+	}
+
+	db, err := b.origB.Database(dbName)
+	if err != nil {
+		return "", err
+	}
+
+	err = db.RenameCollection(ctx, &backends.RenameCollectionParams{
+		OldName: e.Name,
+		NewName: to,
+		Trash:   true,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return to, nil
+}
+
+// Purge drops the collection with the given trash name.
+func (b *Backend) Purge(ctx context.Context, dbName, name string) error {
+	if !isTrashName(name) {
+		return backends.NewError(
+			backends.ErrorCodeCollectionDoesNotExist,
+			lazyerrors.Errorf("no collection %s.%s in the trash", dbName, name),
+		)
+	}
+
+	db, err := b.origB.Database(dbName)
+	if err != nil {
+		return err
+	}
+
+	return db.DropCollection(ctx, &backends.DropCollectionParams{Name: name, Trash: true})
+}
+
+// drop moves the given collection to the trash.
+// Collections with names that are too long for the trash are dropped permanently.
+func (b *Backend) drop(ctx context.Context, db backends.Database, dbName, name string) error {
+	newName := trashName(name, b.now())
+
+	err := db.RenameCollection(ctx, &backends.RenameCollectionParams{
+		OldName: name,
+		NewName: newName,
+		Trash:   true,
+	})
+
+	// the trash name is invalid, but the original name is not
+	if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) && !isTrashName(name) {
+		b.l.Warn(
+			"Collection name is too long for the trash, dropping permanently",
+			zap.String("db", dbName), zap.String("collection", name),
+		)
+
+		return db.DropCollection(ctx, &backends.DropCollectionParams{Name: name})
+	}
+
+	if err != nil {
+		return err
+	}
+
+	b.l.Info("Collection moved to the trash", zap.String("db", dbName), zap.String("collection", name), zap.String("name", newName))
+
+	return nil
+}
+
+// Close implements backends.Backend interface.
+func (b *Backend) Close() {
+	b.origB.Close()
+}
+
+// Status implements backends.Backend interface.
+func (b *Backend) Status(ctx context.Context, params *backends.StatusParams) (*backends.StatusResult, error) {
+	return b.origB.Status(ctx, params)
+}
+
+// Database implements backends.Backend interface.
+func (b *Backend) Database(name string) (backends.Database, error) {
+	origDB, err := b.origB.Database(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDatabase(origDB, name, b), nil
+}
+
+// ListDatabases implements backends.Backend interface.
+//
+//nolint:lll // for readability
+func (b *Backend) ListDatabases(ctx context.Context, params *backends.ListDatabasesParams) (*backends.ListDatabasesResult, error) {
+	return b.origB.ListDatabases(ctx, params)
+}
+
+// DropDatabase implements backends.Backend interface.
+func (b *Backend) DropDatabase(ctx context.Context, params *backends.DropDatabaseParams) error {
+	return b.origB.DropDatabase(ctx, params)
+}
+
+// Describe implements prometheus.Collector.
+func (b *Backend) Describe(ch chan<- *prometheus.Desc) {
+	b.origB.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (b *Backend) Collect(ch chan<- prometheus.Metric) {
+	b.origB.Collect(ch)
+}
+
+// newTrashNameError returns an error for the collection name reserved for the trash.
+func newTrashNameError(name string) error {
+	return backends.NewError(
+		backends.ErrorCodeCollectionNameIsInvalid,
+		lazyerrors.Errorf("collection name %s is reserved for the trash", name),
+	)
+}
+
+// check interfaces
+var (
+	_ backends.Backend = (*Backend)(nil)
+)
@@ -0,0 +1,173 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trash
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/sqlite"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/state"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestTrashName(t *testing.T) {
+	t.Parallel()
+
+	dropped := time.Date(2023, time.October, 1, 12, 0, 0, 42, time.UTC)
+
+	name := trashName("users.archive_2023", dropped)
+	assert.Equal(t, "_ferretdb_trash_1696161600000000042_users.archive_2023", name)
+
+	expected := &Entry{
+		Name:       name,
+		Collection: "users.archive_2023",
+		Dropped:    dropped,
+	}
+	assert.Equal(t, expected, parseTrashName(name))
+
+	assert.Nil(t, parseTrashName("users"))
+	assert.Nil(t, parseTrashName(backends.TrashPrefix+"users"))
+	assert.Nil(t, parseTrashName(backends.TrashPrefix+"123_"))
+}
+
+func TestBackend(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	origB, err := sqlite.NewBackend(&sqlite.NewBackendParams{URI: testutil.TestSQLiteURI(t, ""), L: testutil.Logger(t), P: sp})
+	require.NoError(t, err)
+
+	b := NewBackend(origB, time.Hour, testutil.Logger(t))
+	t.Cleanup(b.Close)
+
+	now := time.Date(2023, time.October, 1, 12, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+
+	dbName := testutil.DatabaseName(t)
+	cName := testutil.CollectionName(t)
+
+	db, err := b.Database(dbName)
+	require.NoError(t, err)
+
+	c, err := db.Collection(cName)
+	require.NoError(t, err)
+
+	doc := must.NotFail(types.NewDocument("_id", int32(1)))
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{doc}})
+	require.NoError(t, err)
+
+	listCollections := func(t *testing.T) []string {
+		t.Helper()
+
+		res, err := db.ListCollections(ctx, nil)
+		require.NoError(t, err)
+
+		var names []string
+		for _, c := range res.Collections {
+			names = append(names, c.Name)
+		}
+
+		return names
+	}
+
+	require.NoError(t, db.DropCollection(ctx, &backends.DropCollectionParams{Name: cName}))
+	assert.Empty(t, listCollections(t))
+
+	entries, err := b.List(ctx, dbName)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, cName, entries[0].Collection)
+	assert.Equal(t, now, entries[0].Dropped)
+
+	t.Run("Hidden", func(t *testing.T) {
+		_, err := db.Collection(entries[0].Name)
+		assert.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid))
+
+		err = db.DropCollection(ctx, &backends.DropCollectionParams{Name: entries[0].Name})
+		assert.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid))
+	})
+
+	restored, err := b.Restore(ctx, dbName, entries[0].Name, "")
+	require.NoError(t, err)
+	assert.Equal(t, cName, restored)
+	assert.Equal(t, []string{cName}, listCollections(t))
+
+	res, err := c.Query(ctx, nil)
+	require.NoError(t, err)
+
+	docs, err := iterator.ConsumeValues(res.Iter)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	testutil.AssertEqual(t, doc, docs[0])
+
+	_, err = b.Restore(ctx, dbName, entries[0].Name, "")
+	assert.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist))
+
+	require.NoError(t, db.DropCollection(ctx, &backends.DropCollectionParams{Name: cName}))
+
+	// not expired yet
+	require.NoError(t, b.PurgeExpired(ctx))
+
+	entries, err = b.List(ctx, dbName)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	now = now.Add(time.Hour)
+	require.NoError(t, b.PurgeExpired(ctx))
+
+	entries, err = b.List(ctx, dbName)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestReservedNamesWithoutTrash(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	// trash decorator is not used
+	b, err := sqlite.NewBackend(&sqlite.NewBackendParams{URI: testutil.TestSQLiteURI(t, ""), L: testutil.Logger(t), P: sp})
+	require.NoError(t, err)
+	t.Cleanup(b.Close)
+
+	db, err := b.Database(testutil.DatabaseName(t))
+	require.NoError(t, err)
+
+	name := trashName(testutil.CollectionName(t), time.Now())
+
+	_, err = db.Collection(name)
+	assert.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid))
+
+	err = db.CreateCollection(ctx, &backends.CreateCollectionParams{Name: name})
+	assert.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid))
+
+	err = db.RenameCollection(ctx, &backends.RenameCollectionParams{OldName: "foo", NewName: name})
+	assert.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid))
+}
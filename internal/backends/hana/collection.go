@@ -29,6 +29,11 @@ func (c *collection) Query(ctx context.Context, params *backends.QueryParams) (*
 	return nil, lazyerrors.New("not implemented yet")
 }
 
+// Count implements backends.Collection interface.
+func (c *collection) Count(ctx context.Context, params *backends.CountParams) (*backends.CountResult, error) {
+	return nil, lazyerrors.New("not implemented yet")
+}
+
 // InsertAll implements backends.Collection interface.
 func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllParams) (*backends.InsertAllResult, error) {
 	return nil, lazyerrors.New("not implemented yet")
@@ -44,6 +49,11 @@ func (c *collection) DeleteAll(ctx context.Context, params *backends.DeleteAllPa
 	return nil, lazyerrors.New("not implemented yet")
 }
 
+// FindAndModify implements backends.Collection interface.
+func (c *collection) FindAndModify(ctx context.Context, params *backends.FindAndModifyParams) (*backends.FindAndModifyResult, error) {
+	return nil, lazyerrors.New("not implemented yet")
+}
+
 // Explain implements backends.Collection interface.
 func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams) (*backends.ExplainResult, error) {
 	return nil, lazyerrors.New("not implemented yet")
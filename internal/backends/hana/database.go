@@ -51,11 +51,28 @@ func (db *database) RenameCollection(ctx context.Context, params *backends.Renam
 	return lazyerrors.New("not implemented yet")
 }
 
+// SetCollectionSettings implements backends.Database interface.
+func (db *database) SetCollectionSettings(ctx context.Context, params *backends.SetCollectionSettingsParams) error {
+	return lazyerrors.New("not implemented yet")
+}
+
 // Stats implements backends.Database interface.
 func (db *database) Stats(ctx context.Context, params *backends.DatabaseStatsParams) (*backends.DatabaseStatsResult, error) {
 	return nil, lazyerrors.New("not implemented yet")
 }
 
+// ValidateMetadata implements backends.Database interface.
+//
+//nolint:lll // for readability
+func (db *database) ValidateMetadata(ctx context.Context, params *backends.ValidateMetadataParams) (*backends.ValidateMetadataResult, error) {
+	return nil, lazyerrors.New("not implemented yet")
+}
+
+// QueryRaw implements backends.Database interface.
+func (db *database) QueryRaw(ctx context.Context, params *backends.QueryRawParams) (*backends.QueryRawResult, error) {
+	return nil, lazyerrors.New("not implemented yet")
+}
+
 // check interfaces
 var (
 	_ backends.Database = (*database)(nil)
@@ -16,11 +16,17 @@ package postgresql
 
 import (
 	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 
 	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/postgresql/faults"
 	"github.com/FerretDB/FerretDB/internal/backends/postgresql/metadata"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/state"
@@ -38,12 +44,33 @@ type NewBackendParams struct {
 	URI string
 	L   *zap.Logger
 	P   *state.Provider
-	_   struct{} // prevent unkeyed literals
+
+	// RelationalViews enables relational views over collections for SQL access.
+	RelationalViews bool
+
+	// StatementTimeout and LockTimeout set default statement_timeout and lock_timeout
+	// for all connections, unless they are set in the URI; 0 keeps the server's settings.
+	StatementTimeout time.Duration
+	LockTimeout      time.Duration
+
+	// TenantIsolation makes new collections store documents of each PostgreSQL user separately
+	// using row-level security.
+	TenantIsolation bool
+
+	// for testing only
+	Faults *faults.Injector
+
+	_ struct{} // prevent unkeyed literals
 }
 
 // NewBackend creates a new backend.
 func NewBackend(params *NewBackendParams) (backends.Backend, error) {
-	r, err := metadata.NewRegistry(params.URI, params.L, params.P)
+	uri, err := setTimeouts(params.URI, params.StatementTimeout, params.LockTimeout)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	r, err := metadata.NewRegistry(uri, params.L, params.P, params.Faults, params.RelationalViews, params.TenantIsolation)
 	if err != nil {
 		return nil, err
 	}
@@ -53,6 +80,60 @@ func NewBackend(params *NewBackendParams) (backends.Backend, error) {
 	}), nil
 }
 
+// setTimeouts returns URI with statement_timeout and lock_timeout runtime parameters
+// that are sent to PostgreSQL for every new connection.
+//
+// Both URLs and keyword/value connection strings are supported.
+// Parameters already set in the URI take precedence; zero durations are not set.
+func setTimeouts(uri string, statementTimeout, lockTimeout time.Duration) (string, error) {
+	if statementTimeout == 0 && lockTimeout == 0 {
+		return uri, nil
+	}
+
+	config, err := pgconn.ParseConfig(uri)
+	if err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	var set [][2]string
+
+	for _, p := range []struct {
+		k string
+		d time.Duration
+	}{
+		{"lock_timeout", lockTimeout},
+		{"statement_timeout", statementTimeout},
+	} {
+		if _, ok := config.RuntimeParams[p.k]; p.d > 0 && !ok {
+			set = append(set, [2]string{p.k, strconv.FormatInt(p.d.Milliseconds(), 10)})
+		}
+	}
+
+	// the same check as in pgconn.ParseConfig
+	if !strings.HasPrefix(uri, "postgres://") && !strings.HasPrefix(uri, "postgresql://") {
+		for _, kv := range set {
+			uri += " " + kv[0] + "=" + kv[1]
+		}
+
+		return uri, nil
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	values := u.Query()
+
+	for _, kv := range set {
+		values.Set(kv[0], kv[1])
+	}
+
+	u.RawQuery = values.Encode()
+
+	return u.String(), nil
+}
+
 // Close implements backends.Backend interface.
 func (b *backend) Close() {
 	b.r.Close()
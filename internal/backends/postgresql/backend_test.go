@@ -0,0 +1,73 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetTimeouts(t *testing.T) {
+	t.Parallel()
+
+	const base = "postgres://127.0.0.1:5432/ferretdb"
+
+	for name, tc := range map[string]struct {
+		uri       string
+		statement time.Duration
+		lock      time.Duration
+		expected  string
+	}{
+		"Disabled": {
+			uri:      base + "?pool_max_conns=10",
+			expected: base + "?pool_max_conns=10",
+		},
+		"Both": {
+			uri:       base,
+			statement: 30 * time.Second,
+			lock:      500 * time.Millisecond,
+			expected:  base + "?lock_timeout=500&statement_timeout=30000",
+		},
+		"URITakesPrecedence": {
+			uri:       base + "?statement_timeout=100",
+			statement: time.Minute,
+			expected:  base + "?statement_timeout=100",
+		},
+		"KeywordValue": {
+			uri:       "host=127.0.0.1 port=5432 dbname=ferretdb",
+			statement: 30 * time.Second,
+			lock:      500 * time.Millisecond,
+			expected:  "host=127.0.0.1 port=5432 dbname=ferretdb lock_timeout=500 statement_timeout=30000",
+		},
+		"KeywordValueTakesPrecedence": {
+			uri:       "host=127.0.0.1 dbname=ferretdb statement_timeout=100",
+			statement: time.Minute,
+			lock:      time.Second,
+			expected:  "host=127.0.0.1 dbname=ferretdb statement_timeout=100 lock_timeout=1000",
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := setTimeouts(tc.uri, tc.statement, tc.lock)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -24,12 +24,14 @@ import (
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/backends/postgresql/metadata"
 	"github.com/FerretDB/FerretDB/internal/backends/postgresql/metadata/pool"
 	"github.com/FerretDB/FerretDB/internal/handlers/sjson"
 	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 )
@@ -61,9 +63,15 @@ func (c *collection) Query(ctx context.Context, params *backends.QueryParams) (*
 		params = new(backends.QueryParams)
 	}
 
+	var textScores map[*types.Document]float64
+	if params.TextSearch != nil {
+		textScores = map[*types.Document]float64{}
+	}
+
 	if p == nil {
 		return &backends.QueryResult{
-			Iter: newQueryIterator(ctx, nil, params.OnlyRecordIDs),
+			Iter:       newQueryIterator(ctx, nil, params.OnlyRecordIDs),
+			TextScores: textScores,
 		}, nil
 	}
 
@@ -74,40 +82,232 @@ func (c *collection) Query(ctx context.Context, params *backends.QueryParams) (*
 
 	if meta == nil {
 		return &backends.QueryResult{
-			Iter: newQueryIterator(ctx, nil, params.OnlyRecordIDs),
+			Iter:       newQueryIterator(ctx, nil, params.OnlyRecordIDs),
+			TextScores: textScores,
 		}, nil
 	}
 
-	q := prepareSelectClause(c.dbName, meta.TableName, meta.Capped(), params.OnlyRecordIDs)
-
 	var placeholder metadata.Placeholder
 
-	where, args, err := prepareWhereClause(&placeholder, params.Filter)
+	var textCond, textScore string
+	var args []any
+
+	if params.TextSearch != nil {
+		textIndex := meta.Indexes.TextIndex()
+		if textIndex == nil {
+			return nil, lazyerrors.Errorf("no text index for %s.%s", c.dbName, c.name)
+		}
+
+		if textCond, textScore, args = prepareTextSearch(&placeholder, params.TextSearch, textIndex); textCond == "" {
+			return &backends.QueryResult{
+				Iter:       newQueryIterator(ctx, nil, params.OnlyRecordIDs),
+				TextScores: textScores,
+			}, nil
+		}
+	}
+
+	var geoCond, geoDistance string
+
+	if params.Geo != nil {
+		var geoArgs []any
+		geoCond, geoDistance, geoArgs = prepareGeoQuery(&placeholder, params.Geo)
+		args = append(args, geoArgs...)
+	}
+
+	var document string
+
+	if params.Projection != nil && !params.OnlyRecordIDs {
+		var projectionArgs []any
+		document, projectionArgs = prepareProjection(&placeholder, params.Projection)
+		args = append(args, projectionArgs...)
+	}
+
+	var unwindJoin, unwindCond string
+
+	unwind := params.Unwind != "" && document == "" && !params.OnlyRecordIDs && !strings.ContainsRune(params.Unwind, '.')
+	if unwind {
+		var unwindArgs []any
+		document, unwindJoin, unwindCond, unwindArgs = prepareUnwind(&placeholder, params.Unwind)
+		args = append(args, unwindArgs...)
+	}
+
+	q := prepareSelectClause(c.dbName, meta.TableName, meta.Capped(), params.OnlyRecordIDs, document, textScore)
+	q += unwindJoin
+
+	distinct := params.Distinct != "" && !strings.ContainsRune(params.Distinct, '.')
+	if distinct {
+		distinctOn, distinctArgs := prepareDistinctOnClause(&placeholder, params.Distinct)
+		q = "SELECT " + distinctOn + strings.TrimPrefix(q, "SELECT ")
+		args = append(args, distinctArgs...)
+	}
+
+	where, whereArgs, err := prepareWhereClause(&placeholder, params.Filter, meta.Indexes)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
+	args = append(args, whereArgs...)
+
+	for _, cond := range []string{textCond, geoCond, unwindCond} {
+		switch {
+		case cond == "":
+			// nothing
+		case where == "":
+			where = " WHERE " + cond
+		default:
+			where += " AND " + cond
+		}
+	}
+
 	q += where
 
-	sort, sortArgs := prepareOrderByClause(&placeholder, params.Sort, meta.Capped())
-	q += sort
-	args = append(args, sortArgs...)
+	switch {
+	case params.SeqScan:
+		// physical order, no ORDER BY to keep the plan a plain Seq Scan
+	case distinct:
+		// DISTINCT ON requires ORDER BY to start with the same expressions; handlers sort values anyway
+	case geoDistance != "" && params.Sort == nil:
+		q += " ORDER BY " + geoDistance
+	default:
+		sort, sortArgs := prepareOrderByClause(&placeholder, params.Sort, meta.OrderColumn())
+		if unwind && sort != "" {
+			// keep the order of array elements
+			sort += ", " + unwindOrdinality
+		}
+
+		q += sort
+		args = append(args, sortArgs...)
+	}
 
 	if params.Limit != 0 {
 		q += fmt.Sprintf(` LIMIT %s`, placeholder.Next())
 		args = append(args, params.Limit)
 	}
 
-	rows, err := p.Query(ctx, q, args...)
+	rows, err := pool.Query(ctx, p, q, args...)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
+	var iter types.DocumentsIterator
+	if textScores != nil {
+		iter = newTextSearchQueryIterator(ctx, rows, params.OnlyRecordIDs, textScores)
+	} else {
+		iter = newQueryIterator(ctx, rows, params.OnlyRecordIDs)
+	}
+
 	return &backends.QueryResult{
-		Iter: newQueryIterator(ctx, rows, params.OnlyRecordIDs),
+		Iter:       iter,
+		Unwound:    unwind,
+		TextScores: textScores,
+	}, nil
+}
+
+// Count implements backends.Collection interface.
+func (c *collection) Count(ctx context.Context, params *backends.CountParams) (*backends.CountResult, error) {
+	if params == nil {
+		params = new(backends.CountParams)
+	}
+
+	var placeholder metadata.Placeholder
+
+	where, args, ok := prepareCountWhereClause(&placeholder, params.Filter)
+	if !ok || strings.ContainsRune(params.GroupBy, '.') {
+		return new(backends.CountResult), nil
+	}
+
+	p, err := c.r.DatabaseGetExisting(ctx, c.dbName)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if p == nil {
+		return &backends.CountResult{Pushdown: true}, nil
+	}
+
+	meta, err := c.r.CollectionGet(ctx, c.dbName, c.name)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if meta == nil {
+		return &backends.CountResult{Pushdown: true}, nil
+	}
+
+	if params.GroupBy != "" {
+		return c.countGroups(ctx, p, meta, &placeholder, where, args, params.GroupBy)
+	}
+
+	q := prepareCountClause(c.dbName, meta.TableName) + where
+
+	var count int64
+	if err = p.QueryRow(ctx, q, args...).Scan(&count); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &backends.CountResult{
+		Count:    count,
+		Pushdown: true,
 	}, nil
 }
 
+// countGroups counts documents matching the given WHERE clause for each value of the given top-level field.
+func (c *collection) countGroups(ctx context.Context, p *pgxpool.Pool, meta *metadata.Collection, placeholder *metadata.Placeholder, where string, args []any, field string) (*backends.CountResult, error) { //nolint:lll // for readability
+	q, groupArgs := prepareGroupCountClause(placeholder, c.dbName, meta.TableName, field)
+	q += where + ` GROUP BY 1, 2`
+	args = append(args, groupArgs...)
+
+	rows, err := p.Query(ctx, q, args...)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+	defer rows.Close()
+
+	res := &backends.CountResult{Pushdown: true}
+
+	for rows.Next() {
+		var value, schema []byte
+		var count int64
+
+		if err = rows.Scan(&value, &schema, &count); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		var v any
+
+		if value != nil {
+			if v, err = unmarshalField(value, schema); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+		}
+
+		res.Count += count
+		res.Groups = append(res.Groups, backends.CountGroup{Value: v, Count: count})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
+
+// unmarshalField decodes a single field value stored in the default column using its schema.
+func unmarshalField(value, schema []byte) (any, error) {
+	b := []byte(`{"$s":{"$k":["v"],"p":{"v":`)
+	b = append(b, schema...)
+	b = append(b, `}},"v":`...)
+	b = append(b, value...)
+	b = append(b, '}')
+
+	doc, err := sjson.Unmarshal(b)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return doc.Get("v")
+}
+
 // InsertAll implements backends.Collection interface.
 func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllParams) (*backends.InsertAllResult, error) {
 	if _, err := c.r.CollectionCreate(ctx, &metadata.CollectionCreateParams{
@@ -127,7 +327,19 @@ func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllPa
 		return nil, lazyerrors.Error(err)
 	}
 
-	err = pool.InTransaction(ctx, p, func(tx pgx.Tx) error {
+	var skipped []int
+
+	err = c.r.InTransactionRetry(ctx, p, func(tx pgx.Tx) error {
+		if params.SkipDuplicates {
+			skipped, err = insertDocumentsSkipDuplicates(ctx, tx, c.dbName, meta.TableName, meta.Capped(), params.Docs)
+			return err
+		}
+
+		// COPY FROM is not supported for tables with row-level security
+		if len(params.Docs) >= copyThreshold && !meta.Tenant {
+			return copyDocuments(ctx, tx, c.dbName, meta.TableName, meta.Capped(), params.Docs)
+		}
+
 		var batch []*types.Document
 		docs := params.Docs
 		const batchSize = 100
@@ -161,7 +373,11 @@ func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllPa
 		return nil, err
 	}
 
-	return new(backends.InsertAllResult), nil
+	c.r.ViewsUpdate(ctx, c.dbName, c.name, params.Docs)
+
+	return &backends.InsertAllResult{
+		Skipped: skipped,
+	}, nil
 }
 
 // UpdateAll implements backends.Collection interface.
@@ -192,7 +408,10 @@ func (c *collection) UpdateAll(ctx context.Context, params *backends.UpdateAllPa
 		metadata.IDColumn,
 	)
 
-	err = pool.InTransaction(ctx, p, func(tx pgx.Tx) error {
+	err = c.r.InTransactionRetry(ctx, p, func(tx pgx.Tx) error {
+		// the function is called again if the transaction is retried
+		res.Updated = 0
+
 		for _, doc := range params.Docs {
 			var b []byte
 			if b, err = sjson.Marshal(doc); err != nil {
@@ -206,6 +425,11 @@ func (c *collection) UpdateAll(ctx context.Context, params *backends.UpdateAllPa
 
 			var tag pgconn.CommandTag
 			if tag, err = tx.Exec(ctx, q, b, arg); err != nil {
+				var pgErr *pgconn.PgError
+				if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+					return backends.NewError(backends.ErrorCodeInsertDuplicateID, err)
+				}
+
 				return lazyerrors.Error(err)
 			}
 
@@ -215,9 +439,11 @@ func (c *collection) UpdateAll(ctx context.Context, params *backends.UpdateAllPa
 		return nil
 	})
 	if err != nil {
-		return nil, lazyerrors.Error(err)
+		return nil, err
 	}
 
+	c.r.ViewsUpdate(ctx, c.dbName, c.name, params.Docs)
+
 	return &res, nil
 }
 
@@ -269,6 +495,226 @@ func (c *collection) DeleteAll(ctx context.Context, params *backends.DeleteAllPa
 	}, nil
 }
 
+// FindAndModify implements backends.Collection interface.
+func (c *collection) FindAndModify(ctx context.Context, params *backends.FindAndModifyParams) (*backends.FindAndModifyResult, error) {
+	var res backends.FindAndModifyResult
+
+	p, err := c.r.DatabaseGetExisting(ctx, c.dbName)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var meta *metadata.Collection
+
+	if p != nil {
+		if meta, err = c.r.CollectionGet(ctx, c.dbName, c.name); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	if meta == nil {
+		var change *backends.FindAndModifyChange
+		if change, err = params.Modify(newQueryIterator(ctx, nil, false)); err != nil {
+			return nil, err
+		}
+
+		if change == nil {
+			return &res, nil
+		}
+
+		// there is nothing to update or delete, but a document could be upserted
+		must.NotBeZero(change.Insert)
+
+		if _, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{change.Insert}}); err != nil {
+			return nil, err
+		}
+
+		res.Inserted = 1
+
+		return &res, nil
+	}
+
+	var placeholder metadata.Placeholder
+
+	where, args, err := prepareWhereClause(&placeholder, params.Filter, meta.Indexes)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	q := prepareSelectClause(c.dbName, meta.TableName, meta.Capped(), false, "") + where
+
+	// only the chosen document is locked, not all documents matching the pushed down filter
+	lockQ := prepareSelectClause(c.dbName, meta.TableName, meta.Capped(), false, "") +
+		fmt.Sprintf(` WHERE %s = $1 FOR UPDATE`, metadata.IDColumn)
+
+	var change *backends.FindAndModifyChange
+
+	err = c.r.InTransactionRetry(ctx, p, func(tx pgx.Tx) error {
+		// the function is called again if the transaction is retried
+		res = backends.FindAndModifyResult{}
+
+		var err error
+		if change, err = c.findAndLock(ctx, tx, params, q, args, lockQ); err != nil || change == nil {
+			return err
+		}
+
+		var tag pgconn.CommandTag
+
+		switch {
+		case change.Update != nil:
+			var b []byte
+			if b, err = sjson.Marshal(change.Update); err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			updateQ := fmt.Sprintf(
+				`UPDATE %s SET %s = $1 WHERE %s = $2`,
+				pgx.Identifier{c.dbName, meta.TableName}.Sanitize(),
+				metadata.DefaultColumn,
+				metadata.IDColumn,
+			)
+
+			id := must.NotFail(sjson.MarshalSingleValue(must.NotFail(change.Update.Get("_id"))))
+
+			if tag, err = tx.Exec(ctx, updateQ, b, id); err != nil {
+				var pgErr *pgconn.PgError
+				if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+					return backends.NewError(backends.ErrorCodeInsertDuplicateID, err)
+				}
+
+				return lazyerrors.Error(err)
+			}
+
+			res.Updated = int32(tag.RowsAffected())
+
+		case change.DeleteID != nil:
+			deleteQ := fmt.Sprintf(
+				`DELETE FROM %s WHERE %s = $1`,
+				pgx.Identifier{c.dbName, meta.TableName}.Sanitize(),
+				metadata.IDColumn,
+			)
+
+			id := string(must.NotFail(sjson.MarshalSingleValue(change.DeleteID)))
+
+			if tag, err = tx.Exec(ctx, deleteQ, id); err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			res.Deleted = int32(tag.RowsAffected())
+
+		case change.Insert != nil:
+			var insertQ string
+			var insertArgs []any
+
+			insertQ, insertArgs, err = prepareInsertStatement(c.dbName, meta.TableName, meta.Capped(), []*types.Document{change.Insert})
+			if err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			if _, err = tx.Exec(ctx, insertQ, insertArgs...); err != nil {
+				var pgErr *pgconn.PgError
+				if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+					return backends.NewError(backends.ErrorCodeInsertDuplicateID, err)
+				}
+
+				return lazyerrors.Error(err)
+			}
+
+			res.Inserted = 1
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case change == nil:
+	case change.Update != nil:
+		c.r.ViewsUpdate(ctx, c.dbName, c.name, []*types.Document{change.Update})
+	case change.Insert != nil:
+		c.r.ViewsUpdate(ctx, c.dbName, c.name, []*types.Document{change.Insert})
+	}
+
+	return &res, nil
+}
+
+// maxFindAndLockAttempts is the maximal number of attempts to lock the document chosen by FindAndModify
+// that is concurrently modified.
+const maxFindAndLockAttempts = 100
+
+// findAndLock calls Modify with documents returned by the given query,
+// locks the chosen document to update or delete, and calls Modify again with its locked version
+// to re-check the filter and compute the change from that version.
+//
+// If the chosen document was concurrently deleted or does not match anymore, everything is repeated.
+// Documents are streamed to Modify and not locked; each statement of the READ COMMITTED transaction
+// sees changes committed before it, so the next attempt sees concurrent changes.
+//
+//nolint:lll // for readability
+func (c *collection) findAndLock(ctx context.Context, tx pgx.Tx, params *backends.FindAndModifyParams, q string, args []any, lockQ string) (*backends.FindAndModifyChange, error) {
+	for attempt := 0; attempt < maxFindAndLockAttempts; attempt++ {
+		rows, err := tx.Query(ctx, q, args...)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		iter := newQueryIterator(ctx, rows, false)
+		change, err := params.Modify(iter)
+
+		// rows should be closed before the next query
+		iter.Close()
+
+		if err != nil || change == nil {
+			return change, err
+		}
+
+		var id any
+
+		switch {
+		case change.Update != nil:
+			id = must.NotFail(change.Update.Get("_id"))
+		case change.DeleteID != nil:
+			id = change.DeleteID
+		default:
+			// nothing was chosen, a new document is inserted
+			return change, nil
+		}
+
+		if rows, err = tx.Query(ctx, lockQ, must.NotFail(sjson.MarshalSingleValue(id))); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		locked, err := iterator.ConsumeValues(newQueryIterator(ctx, rows, false))
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if len(locked) == 0 {
+			// deleted concurrently
+			continue
+		}
+
+		if change, err = params.Modify(iterator.Values(iterator.ForSlice(locked))); err != nil {
+			return nil, err
+		}
+
+		switch {
+		case change == nil:
+			// does not match anymore
+		case change.Update != nil && types.Identical(must.NotFail(change.Update.Get("_id")), id):
+			return change, nil
+		case change.DeleteID != nil && types.Identical(change.DeleteID, id):
+			return change, nil
+		default:
+			// does not match anymore, and a new document would be inserted
+		}
+	}
+
+	return nil, lazyerrors.Errorf("failed to lock the document after %d attempts", maxFindAndLockAttempts)
+}
+
 // Explain implements backends.Collection interface.
 func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams) (*backends.ExplainResult, error) {
 	p, err := c.r.DatabaseGetExisting(ctx, c.dbName)
@@ -297,23 +743,44 @@ func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams
 
 	res := new(backends.ExplainResult)
 
-	q := `EXPLAIN (VERBOSE true, FORMAT JSON) ` + prepareSelectClause(c.dbName, meta.TableName, meta.Capped(), false)
+	q := `EXPLAIN (VERBOSE true, FORMAT JSON) `
+	if params.Analyze {
+		q = `EXPLAIN (ANALYZE true, VERBOSE true, FORMAT JSON) `
+	}
+
+	if params.Count {
+		var countWhere string
+		var countArgs []any
+
+		if countWhere, countArgs, res.CountPushdown = prepareCountWhereClause(new(metadata.Placeholder), params.Filter); res.CountPushdown {
+			res.QueryPushdown = countWhere != ""
+
+			return runExplain(ctx, p, res, q+prepareCountClause(c.dbName, meta.TableName)+countWhere, countArgs)
+		}
+	}
+
+	q += prepareSelectClause(c.dbName, meta.TableName, meta.Capped(), false, "")
 
 	var placeholder metadata.Placeholder
 
-	where, args, err := prepareWhereClause(&placeholder, params.Filter)
+	where, args, err := prepareWhereClause(&placeholder, params.Filter, meta.Indexes)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
 	res.QueryPushdown = where != ""
 
+	regexFilters, _ := prepareRegexFilters(new(metadata.Placeholder), params.Filter, meta.Indexes)
+	res.RegexPushdown = len(regexFilters) > 0
+
 	q += where
 
-	sort, sortArgs := prepareOrderByClause(&placeholder, params.Sort, meta.Capped())
-	q += sort
-	args = append(args, sortArgs...)
-	res.UnsafeSortPushdown = sort != ""
+	if !params.SeqScan {
+		sort, sortArgs := prepareOrderByClause(&placeholder, params.Sort, meta.OrderColumn())
+		q += sort
+		args = append(args, sortArgs...)
+		res.UnsafeSortPushdown = sort != ""
+	}
 
 	if params.Limit != 0 {
 		q += fmt.Sprintf(` LIMIT %s`, placeholder.Next())
@@ -321,8 +788,13 @@ func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams
 		res.UnsafeLimitPushdown = true
 	}
 
+	return runExplain(ctx, p, res, q, args)
+}
+
+// runExplain runs the given EXPLAIN query and sets the query plan of the given result.
+func runExplain(ctx context.Context, p *pgxpool.Pool, res *backends.ExplainResult, q string, args []any) (*backends.ExplainResult, error) { //nolint:lll // for readability
 	var b []byte
-	if err = p.QueryRow(ctx, q, args...).Scan(&b); err != nil {
+	if err := p.QueryRow(ctx, q, args...).Scan(&b); err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
@@ -499,6 +971,13 @@ func (c *collection) ListIndexes(ctx context.Context, params *backends.ListIndex
 			Name:   index.Name,
 			Unique: index.Unique,
 			Key:    make([]backends.IndexKeyPair, len(index.Key)),
+
+			PartialFilterExpression: index.PartialFilterExpression,
+
+			Text:            index.Text,
+			DefaultLanguage: index.DefaultLanguage,
+
+			Sphere: index.Sphere,
 		}
 
 		for j, key := range index.Key {
@@ -524,6 +1003,13 @@ func (c *collection) CreateIndexes(ctx context.Context, params *backends.CreateI
 			Name:   index.Name,
 			Key:    make([]metadata.IndexKeyPair, len(index.Key)),
 			Unique: index.Unique,
+
+			PartialFilterExpression: index.PartialFilterExpression,
+
+			Text:            index.Text,
+			DefaultLanguage: index.DefaultLanguage,
+
+			Sphere: index.Sphere,
 		}
 
 		for j, key := range index.Key {
@@ -0,0 +1,220 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/postgresql/faults"
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/state"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestCollectionInsertAllFaults(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in -short mode")
+	}
+
+	t.Parallel()
+
+	ctx := conninfo.Ctx(testutil.Ctx(t), conninfo.New())
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	fi := faults.New()
+
+	params := NewBackendParams{
+		URI:    testutil.TestPostgreSQLURI(t, ctx, ""),
+		L:      testutil.Logger(t),
+		P:      sp,
+		Faults: fi,
+	}
+	b, err := NewBackend(&params)
+	require.NoError(t, err)
+	t.Cleanup(b.Close)
+
+	db, err := b.Database(testutil.DatabaseName(t))
+	require.NoError(t, err)
+
+	c, err := db.Collection(testutil.CollectionName(t))
+	require.NoError(t, err)
+
+	t.Run("SerializationFailureRetried", func(t *testing.T) {
+		fi.FailSerialization(2)
+
+		_, err = c.InsertAll(ctx, &backends.InsertAllParams{
+			Docs: []*types.Document{must.NotFail(types.NewDocument("_id", "retried"))},
+		})
+		require.NoError(t, err)
+		assert.False(t, fi.Pending())
+
+		res, err := c.Query(ctx, &backends.QueryParams{Filter: must.NotFail(types.NewDocument("_id", "retried"))})
+		require.NoError(t, err)
+
+		docs, err := iterator.ConsumeValues(res.Iter)
+		require.NoError(t, err)
+		assert.Len(t, docs, 1)
+	})
+
+	t.Run("SerializationFailureExhausted", func(t *testing.T) {
+		fi.FailSerialization(3)
+
+		_, err = c.InsertAll(ctx, &backends.InsertAllParams{
+			Docs: []*types.Document{must.NotFail(types.NewDocument("_id", "exhausted"))},
+		})
+
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		assert.Equal(t, pgerrcode.SerializationFailure, pgErr.Code)
+		assert.False(t, fi.Pending())
+
+		res, err := c.Query(ctx, &backends.QueryParams{Filter: must.NotFail(types.NewDocument("_id", "exhausted"))})
+		require.NoError(t, err)
+
+		docs, err := iterator.ConsumeValues(res.Iter)
+		require.NoError(t, err)
+		assert.Empty(t, docs, "transaction should be rolled back")
+	})
+
+	t.Run("NotRetried", func(t *testing.T) {
+		fi.FailTransactions(1, pgerrcode.DiskFull)
+
+		_, err = c.InsertAll(ctx, &backends.InsertAllParams{
+			Docs: []*types.Document{must.NotFail(types.NewDocument("_id", "disk"))},
+		})
+
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		assert.Equal(t, pgerrcode.DiskFull, pgErr.Code)
+	})
+
+	t.Run("DroppedConnection", func(t *testing.T) {
+		fi.DropConnections(1)
+
+		_, err = c.InsertAll(ctx, &backends.InsertAllParams{
+			Docs: []*types.Document{must.NotFail(types.NewDocument("_id", "dropped"))},
+		})
+		require.Error(t, err)
+		assert.False(t, fi.Pending())
+
+		// pool should recover by opening a new connection
+		_, err = c.InsertAll(ctx, &backends.InsertAllParams{
+			Docs: []*types.Document{must.NotFail(types.NewDocument("_id", "recovered"))},
+		})
+		require.NoError(t, err)
+	})
+}
+
+func TestCollectionRetriedTransactions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in -short mode")
+	}
+
+	t.Parallel()
+
+	ctx := conninfo.Ctx(testutil.Ctx(t), conninfo.New())
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	fi := faults.New()
+
+	params := NewBackendParams{
+		URI:    testutil.TestPostgreSQLURI(t, ctx, ""),
+		L:      testutil.Logger(t),
+		P:      sp,
+		Faults: fi,
+	}
+	b, err := NewBackend(&params)
+	require.NoError(t, err)
+	t.Cleanup(b.Close)
+
+	db, err := b.Database(testutil.DatabaseName(t))
+	require.NoError(t, err)
+
+	c, err := db.Collection(testutil.CollectionName(t))
+	require.NoError(t, err)
+
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{
+		Docs: []*types.Document{
+			must.NotFail(types.NewDocument("_id", int32(1), "v", int32(1))),
+			must.NotFail(types.NewDocument("_id", int32(2), "v", int32(1))),
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("UpdateAll", func(t *testing.T) {
+		fi.FailSerialization(2)
+
+		res, err := c.UpdateAll(ctx, &backends.UpdateAllParams{
+			Docs: []*types.Document{
+				must.NotFail(types.NewDocument("_id", int32(1), "v", int32(2))),
+				must.NotFail(types.NewDocument("_id", int32(2), "v", int32(2))),
+			},
+		})
+		require.NoError(t, err)
+		assert.False(t, fi.Pending())
+		assert.Equal(t, int32(2), res.Updated, "retries should not inflate the counter")
+	})
+
+	t.Run("FindAndModify", func(t *testing.T) {
+		fi.FailSerialization(2)
+
+		var calls int
+
+		res, err := c.FindAndModify(ctx, &backends.FindAndModifyParams{
+			Filter: must.NotFail(types.NewDocument("_id", int32(1))),
+			Modify: func(iter types.DocumentsIterator) (*backends.FindAndModifyChange, error) {
+				calls++
+
+				docs, err := iterator.ConsumeValues(iter)
+				if err != nil {
+					return nil, err
+				}
+
+				require.Len(t, docs, 1)
+
+				// the same change is returned for every call
+				doc := docs[0].DeepCopy()
+				doc.Set("v", int32(3))
+
+				return &backends.FindAndModifyChange{Update: doc}, nil
+			},
+		})
+		require.NoError(t, err)
+		assert.False(t, fi.Pending())
+		assert.Equal(t, 3, calls, "Modify is called for every attempt")
+		assert.Equal(t, &backends.FindAndModifyResult{Updated: 1}, res)
+
+		qr, err := c.Query(ctx, &backends.QueryParams{Filter: must.NotFail(types.NewDocument("_id", int32(1)))})
+		require.NoError(t, err)
+
+		docs, err := iterator.ConsumeValues(qr.Iter)
+		require.NoError(t, err)
+		require.Len(t, docs, 1)
+		assert.Equal(t, int32(3), must.NotFail(docs[0].Get("v")))
+	})
+}
@@ -16,6 +16,9 @@ package postgresql
 
 import (
 	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
 
 	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/backends/postgresql/metadata"
@@ -45,9 +48,22 @@ func (db *database) Collection(name string) (backends.Collection, error) {
 //
 //nolint:lll // for readability
 func (db *database) ListCollections(ctx context.Context, params *backends.ListCollectionsParams) (*backends.ListCollectionsResult, error) {
-	list, err := db.r.CollectionList(ctx, db.name)
-	if err != nil {
-		return nil, lazyerrors.Error(err)
+	var list []*metadata.Collection
+
+	if params != nil && params.Name != "" {
+		c, err := db.r.CollectionGet(ctx, db.name, params.Name)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if c != nil {
+			list = []*metadata.Collection{c}
+		}
+	} else {
+		var err error
+		if list, err = db.r.CollectionList(ctx, db.name); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
 	}
 
 	res := make([]backends.CollectionInfo, len(list))
@@ -56,6 +72,7 @@ func (db *database) ListCollections(ctx context.Context, params *backends.ListCo
 			Name:            c.Name,
 			CappedSize:      c.CappedSize,
 			CappedDocuments: c.CappedDocuments,
+			Settings:        c.Settings,
 		}
 	}
 
@@ -71,6 +88,7 @@ func (db *database) CreateCollection(ctx context.Context, params *backends.Creat
 		Name:            params.Name,
 		CappedSize:      params.CappedSize,
 		CappedDocuments: params.CappedDocuments,
+		Tablespace:      params.Tablespace,
 	})
 	if err != nil {
 		return lazyerrors.Error(err)
@@ -135,6 +153,20 @@ func (db *database) RenameCollection(ctx context.Context, params *backends.Renam
 	return nil
 }
 
+// SetCollectionSettings implements backends.Database interface.
+func (db *database) SetCollectionSettings(ctx context.Context, params *backends.SetCollectionSettingsParams) error {
+	set, err := db.r.CollectionSetSettings(ctx, db.name, params.Name, params.Settings)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if !set {
+		return backends.NewError(backends.ErrorCodeCollectionDoesNotExist, err)
+	}
+
+	return nil
+}
+
 // Stats implements backends.Database interface.
 func (db *database) Stats(ctx context.Context, params *backends.DatabaseStatsParams) (*backends.DatabaseStatsResult, error) {
 	if params == nil {
@@ -185,6 +217,67 @@ func (db *database) Stats(ctx context.Context, params *backends.DatabaseStatsPar
 	}, nil
 }
 
+// ValidateMetadata implements backends.Database interface.
+//
+//nolint:lll // for readability
+func (db *database) ValidateMetadata(ctx context.Context, params *backends.ValidateMetadataParams) (*backends.ValidateMetadataResult, error) {
+	if params == nil {
+		params = new(backends.ValidateMetadataParams)
+	}
+
+	problems, err := db.r.ValidateMetadata(ctx, db.name, params.Repair)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &backends.ValidateMetadataResult{
+		Problems: problems,
+	}, nil
+}
+
+// QueryRaw implements backends.Database interface.
+func (db *database) QueryRaw(ctx context.Context, params *backends.QueryRawParams) (*backends.QueryRawResult, error) {
+	p, err := db.r.DatabaseGetExisting(ctx, db.name)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if p == nil {
+		return &backends.QueryRawResult{
+			Iter: newRawQueryIterator(ctx, nil, nil),
+		}, nil
+	}
+
+	tx, err := p.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	// nothing is written, so the transaction is always rolled back
+	rollback := func() {
+		_ = tx.Rollback(context.Background())
+	}
+
+	if params.Timeout > 0 {
+		// 0 disables the timeout, so round up
+		q := fmt.Sprintf("SET LOCAL statement_timeout = %d", max(params.Timeout.Milliseconds(), 1))
+		if _, err = tx.Exec(ctx, q); err != nil {
+			rollback()
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	rows, err := tx.Query(ctx, params.SQL)
+	if err != nil {
+		rollback()
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &backends.QueryRawResult{
+		Iter: newRawQueryIterator(ctx, rows, rollback),
+	}, nil
+}
+
 // check interfaces
 var (
 	_ backends.Database = (*database)(nil)
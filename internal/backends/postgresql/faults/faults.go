@@ -0,0 +1,246 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package faults provides fault injection for PostgreSQL connections and transactions.
+//
+// It is used only by tests to check retries and error mapping deterministically.
+// Faults are not random: tests arm a fixed number of them, and they are triggered in order.
+package faults
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/FerretDB/FerretDB/internal/util/ctxutil"
+)
+
+// ErrDropped is returned by connections dropped by the Injector.
+var ErrDropped = errors.New("faults: connection dropped")
+
+// Injector injects faults into PostgreSQL connections and transactions.
+//
+// Nil value is valid and injects nothing.
+// Methods are safe for concurrent use.
+//
+//nolint:vet // for readability
+type Injector struct {
+	m sync.Mutex
+
+	latency       time.Duration
+	drops         int
+	partialWrites int
+	txErrors      []string // PostgreSQL error codes
+}
+
+// New returns a new Injector that does not inject anything until faults are armed.
+func New() *Injector {
+	return new(Injector)
+}
+
+// SetLatency sets the latency added to every connection read and write.
+func (i *Injector) SetLatency(d time.Duration) {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	i.latency = d
+}
+
+// DropConnections arms the Injector to drop connections on the next n writes.
+func (i *Injector) DropConnections(n int) {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	i.drops += n
+}
+
+// PartialWrites arms the Injector to write only a half of the data on the next n writes,
+// and then to drop the connection.
+func (i *Injector) PartialWrites(n int) {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	i.partialWrites += n
+}
+
+// FailTransactions arms the Injector to fail the next n transactions with the given PostgreSQL error code
+// before they are committed.
+func (i *Injector) FailTransactions(n int, code string) {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	for j := 0; j < n; j++ {
+		i.txErrors = append(i.txErrors, code)
+	}
+}
+
+// FailSerialization is a shortcut for FailTransactions with serialization failure code.
+func (i *Injector) FailSerialization(n int) {
+	i.FailTransactions(n, pgerrcode.SerializationFailure)
+}
+
+// Reset disarms all faults.
+func (i *Injector) Reset() {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	i.latency = 0
+	i.drops = 0
+	i.partialWrites = 0
+	i.txErrors = nil
+}
+
+// Pending returns true if some faults are armed but were not triggered yet.
+func (i *Injector) Pending() bool {
+	if i == nil {
+		return false
+	}
+
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	return i.drops > 0 || i.partialWrites > 0 || len(i.txErrors) > 0
+}
+
+// TxError returns an armed transaction error, or nil.
+//
+// Returned errors are *pgconn.PgError values, so they are handled exactly like real PostgreSQL errors.
+func (i *Injector) TxError() error {
+	if i == nil {
+		return nil
+	}
+
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	if len(i.txErrors) == 0 {
+		return nil
+	}
+
+	code := i.txErrors[0]
+	i.txErrors = i.txErrors[1:]
+
+	return &pgconn.PgError{
+		Severity: "ERROR",
+		Code:     code,
+		Message:  "injected fault",
+	}
+}
+
+// DialFunc wraps the given dial function so returned connections are affected by the Injector.
+//
+// If the Injector is nil, it returns dial as is.
+func (i *Injector) DialFunc(dial pgconn.DialFunc) pgconn.DialFunc {
+	if i == nil {
+		return dial
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		c, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		return &conn{Conn: c, i: i}, nil
+	}
+}
+
+// writeFault represents a fault that should be applied to the next write.
+type writeFault int
+
+const (
+	writeOK writeFault = iota
+	writeDrop
+	writePartial
+)
+
+// nextWrite returns the latency and the fault for the next write.
+func (i *Injector) nextWrite() (time.Duration, writeFault) {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	switch {
+	case i.drops > 0:
+		i.drops--
+		return i.latency, writeDrop
+	case i.partialWrites > 0:
+		i.partialWrites--
+		return i.latency, writePartial
+	default:
+		return i.latency, writeOK
+	}
+}
+
+// getLatency returns the current latency.
+func (i *Injector) getLatency() time.Duration {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	return i.latency
+}
+
+// conn is a net.Conn affected by the Injector.
+type conn struct {
+	net.Conn
+	i *Injector
+}
+
+// Read implements net.Conn.
+func (c *conn) Read(b []byte) (int, error) {
+	if d := c.i.getLatency(); d > 0 {
+		ctxutil.Sleep(context.Background(), d)
+	}
+
+	return c.Conn.Read(b)
+}
+
+// Write implements net.Conn.
+func (c *conn) Write(b []byte) (int, error) {
+	d, f := c.i.nextWrite()
+	if d > 0 {
+		ctxutil.Sleep(context.Background(), d)
+	}
+
+	switch f {
+	case writeDrop:
+		c.Conn.Close()
+		return 0, ErrDropped
+
+	case writePartial:
+		n, err := c.Conn.Write(b[:len(b)/2])
+		c.Conn.Close()
+
+		if err == nil {
+			err = ErrDropped
+		}
+
+		return n, err
+
+	case writeOK:
+		fallthrough
+
+	default:
+		return c.Conn.Write(b)
+	}
+}
+
+// check interfaces
+var (
+	_ net.Conn = (*conn)(nil)
+)
@@ -0,0 +1,148 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package faults
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dialPipe returns a connection wrapped by the injector and the other end of the pipe.
+func dialPipe(t *testing.T, i *Injector) (net.Conn, net.Conn) {
+	t.Helper()
+
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+
+	dial := i.DialFunc(func(context.Context, string, string) (net.Conn, error) {
+		return client, nil
+	})
+
+	c, err := dial(context.Background(), "tcp", "127.0.0.1:5432")
+	require.NoError(t, err)
+
+	return c, server
+}
+
+func TestNil(t *testing.T) {
+	t.Parallel()
+
+	var i *Injector
+
+	assert.False(t, i.Pending())
+	assert.NoError(t, i.TxError())
+
+	c, server := dialPipe(t, i)
+	assert.NotPanics(t, func() {
+		go server.Read(make([]byte, 3))
+
+		_, err := c.Write([]byte("foo"))
+		assert.NoError(t, err)
+	})
+}
+
+func TestDropConnections(t *testing.T) {
+	t.Parallel()
+
+	i := New()
+	i.DropConnections(1)
+	assert.True(t, i.Pending())
+
+	c, _ := dialPipe(t, i)
+
+	n, err := c.Write([]byte("foo"))
+	assert.ErrorIs(t, err, ErrDropped)
+	assert.Zero(t, n)
+	assert.False(t, i.Pending())
+
+	_, err = c.Write([]byte("foo"))
+	assert.ErrorIs(t, err, io.ErrClosedPipe)
+}
+
+func TestPartialWrites(t *testing.T) {
+	t.Parallel()
+
+	i := New()
+	i.PartialWrites(1)
+
+	c, server := dialPipe(t, i)
+
+	done := make(chan []byte)
+
+	go func() {
+		b, _ := io.ReadAll(server)
+		done <- b
+	}()
+
+	n, err := c.Write([]byte("foobar"))
+	assert.ErrorIs(t, err, ErrDropped)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []byte("foo"), <-done)
+}
+
+func TestLatency(t *testing.T) {
+	t.Parallel()
+
+	i := New()
+	i.SetLatency(50 * time.Millisecond)
+
+	c, server := dialPipe(t, i)
+
+	go server.Read(make([]byte, 3))
+
+	start := time.Now()
+	_, err := c.Write([]byte("foo"))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	i.Reset()
+
+	go server.Write([]byte("bar"))
+
+	start = time.Now()
+	_, err = c.Read(make([]byte, 3))
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestTxError(t *testing.T) {
+	t.Parallel()
+
+	i := New()
+	i.FailSerialization(1)
+	i.FailTransactions(1, pgerrcode.DeadlockDetected)
+
+	var pgErr *pgconn.PgError
+
+	require.ErrorAs(t, i.TxError(), &pgErr)
+	assert.Equal(t, pgerrcode.SerializationFailure, pgErr.Code)
+
+	require.ErrorAs(t, i.TxError(), &pgErr)
+	assert.Equal(t, pgerrcode.DeadlockDetected, pgErr.Code)
+
+	assert.NoError(t, i.TxError())
+	assert.False(t, i.Pending())
+}
@@ -15,17 +15,25 @@
 package postgresql
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 
+	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/backends/postgresql/metadata"
 	"github.com/FerretDB/FerretDB/internal/handlers/sjson"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 )
 
+// copyThreshold is the minimal number of documents inserted with COPY instead of INSERT statements.
+const copyThreshold = 1000
+
 // prepareInsertStatement returns a statement and arguments for inserting the given documents.
 //
 // If capped is true, it returns a statement and arguments for inserting record IDs and documents.
@@ -63,3 +71,80 @@ func prepareInsertStatement(schema, tableName string, capped bool, docs []*types
 		strings.Join(rows, ", "),
 	), args, nil
 }
+
+// copyDocuments inserts the given documents using COPY protocol,
+// which is much faster than INSERT statements for large number of documents.
+//
+// If capped is true, record IDs are inserted too.
+func copyDocuments(ctx context.Context, tx pgx.Tx, schema, tableName string, capped bool, docs []*types.Document) error {
+	columns := []string{metadata.DefaultColumn}
+	if capped {
+		columns = []string{metadata.RecordIDColumn, metadata.DefaultColumn}
+	}
+
+	rows := make([][]any, len(docs))
+
+	for i, doc := range docs {
+		b, err := sjson.Marshal(doc)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if capped {
+			rows[i] = []any{doc.RecordID(), string(b)}
+			continue
+		}
+
+		rows[i] = []any{string(b)}
+	}
+
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{schema, tableName}, columns, pgx.CopyFromRows(rows)); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return backends.NewError(backends.ErrorCodeInsertDuplicateID, err)
+		}
+
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// insertDocumentsSkipDuplicates inserts the given documents one by one in a single batch,
+// skipping documents that violate unique indexes with ON CONFLICT DO NOTHING.
+//
+// It returns indexes of skipped documents.
+func insertDocumentsSkipDuplicates(ctx context.Context, tx pgx.Tx, schema, tableName string, capped bool, docs []*types.Document) ([]int, error) { //nolint:lll // for readability
+	var batch pgx.Batch
+
+	for _, doc := range docs {
+		q, args, err := prepareInsertStatement(schema, tableName, capped, []*types.Document{doc})
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		batch.Queue(q+` ON CONFLICT DO NOTHING`, args...)
+	}
+
+	br := tx.SendBatch(ctx, &batch)
+
+	var skipped []int
+
+	for i := range docs {
+		tag, err := br.Exec()
+		if err != nil {
+			_ = br.Close()
+			return nil, lazyerrors.Error(err)
+		}
+
+		if tag.RowsAffected() == 0 {
+			skipped = append(skipped, i)
+		}
+	}
+
+	if err := br.Close(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return skipped, nil
+}
@@ -29,10 +29,18 @@ type Indexes []IndexInfo
 
 // IndexInfo represents information about a single index.
 type IndexInfo struct {
-	Name    string
-	PgIndex string
-	Key     []IndexKeyPair
-	Unique  bool
+	Name      string
+	PgIndex   string
+	TrgmIndex string // trigram GIN index for substring search; empty if not created
+	Key       []IndexKeyPair
+	Unique    bool
+
+	PartialFilterExpression *types.Document // nil for regular indexes
+
+	Text            bool   // true for text indexes
+	DefaultLanguage string // text index's default language
+
+	Sphere bool // true for 2dsphere indexes
 }
 
 // IndexKeyPair consists of a field name and a sort order that are part of the index.
@@ -47,16 +55,44 @@ func (indexes Indexes) deepCopy() Indexes {
 
 	for i, index := range indexes {
 		res[i] = IndexInfo{
-			Name:    index.Name,
-			PgIndex: index.PgIndex,
-			Key:     slices.Clone(index.Key),
-			Unique:  index.Unique,
+			Name:      index.Name,
+			PgIndex:   index.PgIndex,
+			TrgmIndex: index.TrgmIndex,
+			Key:       slices.Clone(index.Key),
+			Unique:    index.Unique,
+
+			Text:            index.Text,
+			DefaultLanguage: index.DefaultLanguage,
+
+			Sphere: index.Sphere,
+		}
+
+		if index.PartialFilterExpression != nil {
+			res[i].PartialFilterExpression = index.PartialFilterExpression.DeepCopy()
 		}
 	}
 
 	return res
 }
 
+// TrigramIndexed returns true if there is a trigram index for the given top-level field.
+func (indexes Indexes) TrigramIndexed(field string) bool {
+	return slices.ContainsFunc(indexes, func(index IndexInfo) bool {
+		return index.TrgmIndex != "" && index.Key[0].Field == field
+	})
+}
+
+// TextIndex returns the text index, or nil if there is none.
+func (indexes Indexes) TextIndex() *IndexInfo {
+	for i, index := range indexes {
+		if index.Text {
+			return &indexes[i]
+		}
+	}
+
+	return nil
+}
+
 // marshal returns [*types.Array] for indexes.
 func (indexes Indexes) marshal() *types.Array {
 	res := types.MakeArray(len(indexes))
@@ -73,12 +109,31 @@ func (indexes Indexes) marshal() *types.Array {
 			key.Set(pair.Field, order)
 		}
 
-		res.Append(must.NotFail(types.NewDocument(
+		doc := must.NotFail(types.NewDocument(
 			"pgindex", index.PgIndex,
 			"name", index.Name,
 			"key", key,
 			"unique", index.Unique,
-		)))
+		))
+
+		if index.TrgmIndex != "" {
+			doc.Set("trgmindex", index.TrgmIndex)
+		}
+
+		if index.PartialFilterExpression != nil {
+			doc.Set("partialFilterExpression", index.PartialFilterExpression)
+		}
+
+		if index.Text {
+			doc.Set("text", true)
+			doc.Set("default_language", index.DefaultLanguage)
+		}
+
+		if index.Sphere {
+			doc.Set("sphere", true)
+		}
+
+		res.Append(doc)
 	}
 
 	return res
@@ -124,11 +179,37 @@ func (s *Indexes) unmarshal(a *types.Array) error {
 		v, _ = index.Get("unique")
 		unique, _ := v.(bool)
 
+		// it is not set for indexes without trigram index
+		v, _ = index.Get("trgmindex")
+		trgmIndex, _ := v.(string)
+
+		// it is not set for regular indexes
+		v, _ = index.Get("partialFilterExpression")
+		partialFilterExpression, _ := v.(*types.Document)
+
+		// they are not set for regular indexes
+		v, _ = index.Get("text")
+		text, _ := v.(bool)
+		v, _ = index.Get("default_language")
+		defaultLanguage, _ := v.(string)
+
+		// it is not set for regular indexes
+		v, _ = index.Get("sphere")
+		sphere, _ := v.(bool)
+
 		res[i] = IndexInfo{
-			Name:    must.NotFail(index.Get("name")).(string),
-			PgIndex: must.NotFail(index.Get("pgindex")).(string),
-			Key:     key,
-			Unique:  unique,
+			Name:      must.NotFail(index.Get("name")).(string),
+			PgIndex:   must.NotFail(index.Get("pgindex")).(string),
+			TrgmIndex: trgmIndex,
+			Key:       key,
+			Unique:    unique,
+
+			PartialFilterExpression: partialFilterExpression,
+
+			Text:            text,
+			DefaultLanguage: defaultLanguage,
+
+			Sphere: sphere,
 		}
 	}
 
@@ -35,6 +35,14 @@ const (
 
 	// RecordIDColumn is a name for RecordID column to store capped collection record id.
 	RecordIDColumn = backends.ReservedPrefix + "record_id"
+
+	// SequenceColumn is a name for monotonic sequence column that preserves insertion order
+	// of documents in non-capped collections.
+	SequenceColumn = backends.ReservedPrefix + "seq"
+
+	// TenantColumn is a name for the column that stores the PostgreSQL user that inserted the document
+	// in collections with row-level security tenant isolation.
+	TenantColumn = backends.ReservedPrefix + "tenant"
 )
 
 // Collection represents collection metadata.
@@ -47,6 +55,11 @@ type Collection struct {
 	Indexes         Indexes
 	CappedSize      int64
 	CappedDocuments int64
+	Sequence        bool // true if the table has SequenceColumn
+	Tenant          bool // true if the table has TenantColumn and row-level security policy
+
+	// Settings contains settings defined by the handler, or nil.
+	Settings *types.Document
 }
 
 // deepCopy returns a deep copy.
@@ -55,12 +68,20 @@ func (c *Collection) deepCopy() *Collection {
 		return nil
 	}
 
+	var settings *types.Document
+	if c.Settings != nil {
+		settings = c.Settings.DeepCopy()
+	}
+
 	return &Collection{
 		Name:            c.Name,
 		TableName:       c.TableName,
 		Indexes:         c.Indexes.deepCopy(),
 		CappedSize:      c.CappedSize,
 		CappedDocuments: c.CappedDocuments,
+		Sequence:        c.Sequence,
+		Tenant:          c.Tenant,
+		Settings:        settings,
 	}
 }
 
@@ -69,6 +90,19 @@ func (c Collection) Capped() bool {
 	return c.CappedSize > 0
 }
 
+// OrderColumn returns the name of the column that defines the insertion order of documents,
+// or an empty string if there is no such column (for non-capped collections created by older versions).
+func (c Collection) OrderColumn() string {
+	switch {
+	case c.Capped():
+		return RecordIDColumn
+	case c.Sequence:
+		return SequenceColumn
+	default:
+		return ""
+	}
+}
+
 // Value implements driver.Valuer interface.
 func (c Collection) Value() (driver.Value, error) {
 	b, err := sjson.Marshal(c.marshal())
@@ -109,13 +143,21 @@ func (c *Collection) Scan(src any) error {
 
 // marshal returns [*types.Document] for that collection.
 func (c *Collection) marshal() *types.Document {
-	return must.NotFail(types.NewDocument(
+	res := must.NotFail(types.NewDocument(
 		"_id", c.Name,
 		"table", c.TableName,
 		"indexes", c.Indexes.marshal(),
 		"cappedSize", c.CappedSize,
 		"cappedDocs", c.CappedDocuments,
+		"seq", c.Sequence,
+		"tenant", c.Tenant,
 	))
+
+	if c.Settings != nil {
+		res.Set("settings", c.Settings)
+	}
+
+	return res
 }
 
 // unmarshal sets collection metadata from [*types.Document].
@@ -152,6 +194,15 @@ func (c *Collection) unmarshal(doc *types.Document) error {
 	if v, _ := doc.Get("cappedDocs"); v != nil {
 		c.CappedDocuments = v.(int64)
 	}
+	if v, _ := doc.Get("seq"); v != nil {
+		c.Sequence = v.(bool)
+	}
+	if v, _ := doc.Get("tenant"); v != nil {
+		c.Tenant = v.(bool)
+	}
+	if v, _ := doc.Get("settings"); v != nil {
+		c.Settings = v.(*types.Document)
+	}
 
 	return nil
 }
@@ -16,6 +16,8 @@ package pool
 
 import (
 	"context"
+	"errors"
+	"maps"
 	"slices"
 	"strings"
 	"time"
@@ -26,6 +28,8 @@ import (
 	"github.com/jackc/pgx/v5/tracelog"
 	"go.uber.org/zap"
 
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/postgresql/faults"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/state"
 )
@@ -40,7 +44,9 @@ var (
 
 // openDB creates a pool of connections to PostgreSQL database
 // and check that it works (authentication passes, settings are okay).
-func openDB(uri string, l *zap.Logger, sp *state.Provider) (*pgxpool.Pool, error) {
+//
+// Faults injector may be nil.
+func openDB(uri string, l *zap.Logger, sp *state.Provider, fi *faults.Injector) (*pgxpool.Pool, error) {
 	config, err := pgxpool.ParseConfig(uri)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -65,6 +71,22 @@ func openDB(uri string, l *zap.Logger, sp *state.Provider) (*pgxpool.Pool, error
 			}
 		}
 
+		// extensions could be installed or removed without FerretDB restart too;
+		// do not fail the connection if detection fails (for example, on PostgreSQL-compatible databases)
+		caps, err := detectCapabilities(ctx, conn)
+		if err != nil {
+			l.Warn("openDB: failed to detect backend capabilities", zap.Error(err))
+			return nil
+		}
+
+		if !maps.Equal(sp.Get().BackendCapabilities, caps) {
+			l.Info("Backend capabilities detected", zap.Any("capabilities", caps))
+
+			if err = sp.Update(func(s *state.State) { s.BackendCapabilities = caps }); err != nil {
+				l.Error("openDB: failed to update state", zap.Error(err))
+			}
+		}
+
 		return nil
 	}
 
@@ -72,13 +94,18 @@ func openDB(uri string, l *zap.Logger, sp *state.Provider) (*pgxpool.Pool, error
 	// TODO https://github.com/FerretDB/FerretDB/issues/3554
 
 	// try to log everything; logger's configuration will skip extra levels if needed
-	config.ConnConfig.Tracer = &tracelog.TraceLog{
-		Logger:   zapadapter.NewLogger(l),
-		LogLevel: tracelog.LogLevelTrace,
+	config.ConnConfig.Tracer = &tracer{
+		TraceLog: &tracelog.TraceLog{
+			Logger:   zapadapter.NewLogger(l),
+			LogLevel: tracelog.LogLevelTrace,
+		},
+		l: l,
 	}
 
 	config.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
 
+	config.ConnConfig.DialFunc = fi.DialFunc(config.ConnConfig.DialFunc)
+
 	// see https://github.com/jackc/pgx/issues/1726#issuecomment-1711612138
 	ctx := context.TODO()
 
@@ -98,6 +125,57 @@ func openDB(uri string, l *zap.Logger, sp *state.Provider) (*pgxpool.Pool, error
 	return p, nil
 }
 
+// detectCapabilities returns detected backend capabilities with their versions.
+//
+// See [backends.Capabilities].
+func detectCapabilities(ctx context.Context, conn *pgx.Conn) (map[string]string, error) {
+	res := map[string]string{}
+
+	extensions := []string{backends.CapabilityPostGIS, backends.CapabilityTrigram}
+
+	rows, err := conn.Query(ctx, `SELECT extname, extversion FROM pg_extension WHERE extname = ANY($1)`, extensions)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	for rows.Next() {
+		var name, version string
+		if err = rows.Scan(&name, &version); err != nil {
+			rows.Close()
+			return nil, lazyerrors.Error(err)
+		}
+
+		res[name] = version
+	}
+
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	// ICU collations are present only if PostgreSQL was built with ICU support
+	var icuVersion *string
+	q := `SELECT collversion FROM pg_collation WHERE collprovider = 'i' ORDER BY oid LIMIT 1`
+
+	switch err = conn.QueryRow(ctx, q).Scan(&icuVersion); {
+	case err == nil:
+		res[backends.CapabilityICU] = ""
+		if icuVersion != nil {
+			res[backends.CapabilityICU] = *icuVersion
+		}
+	case errors.Is(err, pgx.ErrNoRows):
+		// no ICU
+	default:
+		return nil, lazyerrors.Error(err)
+	}
+
+	// tablespaces are always supported
+	res[backends.CapabilityTablespaces] = ""
+
+	return res, nil
+}
+
 // simplify simplifies PostgreSQL setting value for comparison.
 func simplify(v string) string {
 	return strings.ToLower(strings.ReplaceAll(v, "-", ""))
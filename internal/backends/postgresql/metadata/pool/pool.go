@@ -25,6 +25,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 
+	"github.com/FerretDB/FerretDB/internal/backends/postgresql/faults"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/resource"
 	"github.com/FerretDB/FerretDB/internal/util/state"
@@ -43,6 +44,7 @@ type Pool struct {
 	baseURI url.URL
 	l       *zap.Logger
 	sp      *state.Provider
+	faults  *faults.Injector
 
 	rw    sync.RWMutex
 	pools map[string]*pgxpool.Pool // by full URI
@@ -51,7 +53,9 @@ type Pool struct {
 }
 
 // New creates a new Pool.
-func New(u string, l *zap.Logger, sp *state.Provider) (*Pool, error) {
+//
+// Faults injector may be nil; it is set only by tests.
+func New(u string, l *zap.Logger, sp *state.Provider, fi *faults.Injector) (*Pool, error) {
 	baseURI, err := url.Parse(u)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -65,6 +69,7 @@ func New(u string, l *zap.Logger, sp *state.Provider) (*Pool, error) {
 		baseURI: *baseURI,
 		l:       l,
 		sp:      sp,
+		faults:  fi,
 		pools:   map[string]*pgxpool.Pool{},
 		token:   resource.NewToken(),
 	}
@@ -122,7 +127,7 @@ func (p *Pool) Get(username, password string) (*pgxpool.Pool, error) {
 		return res, nil
 	}
 
-	res, err := openDB(u, p.l, p.sp)
+	res, err := openDB(u, p.l, p.sp, p.faults)
 	if err != nil {
 		p.l.Warn("Pool: connection failed", zap.String("username", username), zap.Error(err))
 		return nil, lazyerrors.Error(err)
@@ -134,6 +139,11 @@ func (p *Pool) Get(username, password string) (*pgxpool.Pool, error) {
 	return res, nil
 }
 
+// Faults returns faults injector; it is nil unless set by tests.
+func (p *Pool) Faults() *faults.Injector {
+	return p.faults
+}
+
 // Describe implements prometheus.Collector.
 func (p *Pool) Describe(ch chan<- *prometheus.Desc) {
 	prometheus.DescribeByCollect(p, ch)
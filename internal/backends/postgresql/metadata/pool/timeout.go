@@ -0,0 +1,92 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pool
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// setLocalTimeouts lowers statement_timeout and lock_timeout for the current transaction
+// to the time left until the context deadline (set by handlers for maxTimeMS).
+//
+// That way, PostgreSQL stops the statement itself instead of relying on client-side cancellation
+// that closes the connection. Timeouts that are already lower are kept.
+func setLocalTimeouts(ctx context.Context, tx pgx.Tx) error {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+
+	// 0 disables the timeout, so round up
+	timeout := fmt.Sprintf("%dms", max(time.Until(deadline).Milliseconds(), 1))
+
+	q := `SELECT set_config(name, $1::text, true) ` +
+		`FROM (VALUES ('statement_timeout'), ('lock_timeout')) AS t(name) ` +
+		`WHERE current_setting(name)::interval = '0' OR current_setting(name)::interval > $1::text::interval`
+
+	_, err := tx.Exec(ctx, q, timeout)
+
+	return err
+}
+
+// txRows rolls back the read-only transaction when rows are closed.
+type txRows struct {
+	pgx.Rows
+	rollback func()
+}
+
+// Close implements pgx.Rows.
+func (r *txRows) Close() {
+	r.Rows.Close()
+	r.rollback()
+}
+
+// Query runs the given query using pool p.
+//
+// If the context has a deadline, the query runs in a read-only transaction with timeouts set by setLocalTimeouts;
+// the transaction is rolled back when returned rows are closed.
+func Query(ctx context.Context, p *pgxpool.Pool, sql string, args ...any) (pgx.Rows, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		return p.Query(ctx, sql, args...)
+	}
+
+	tx, err := p.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+
+	// nothing is written, so the transaction is always rolled back
+	rollback := func() {
+		_ = tx.Rollback(context.Background())
+	}
+
+	if err = setLocalTimeouts(ctx, tx); err != nil {
+		rollback()
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		rollback()
+		return nil, err
+	}
+
+	return &txRows{Rows: rows, rollback: rollback}, nil
+}
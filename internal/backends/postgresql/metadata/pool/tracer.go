@@ -0,0 +1,99 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/tracelog"
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/util/sqllog"
+)
+
+// tracerKey is a named unexported type for the safe use of context.WithValue.
+type tracerKey struct{}
+
+// tracerData represents the statement that is being executed.
+type tracerData struct {
+	start time.Time
+	sql   string
+	args  []any
+}
+
+// tracer wraps [*tracelog.TraceLog] with per-request SQL statement logging.
+//
+// See [sqllog.Log].
+type tracer struct {
+	*tracelog.TraceLog
+	l *zap.Logger
+}
+
+// TraceQueryStart implements [pgx.QueryTracer].
+func (t *tracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx = t.TraceLog.TraceQueryStart(ctx, conn, data)
+
+	return context.WithValue(ctx, tracerKey{}, &tracerData{
+		start: time.Now(),
+		sql:   data.SQL,
+		args:  data.Args,
+	})
+}
+
+// TraceQueryEnd implements [pgx.QueryTracer].
+func (t *tracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	t.TraceLog.TraceQueryEnd(ctx, conn, data)
+
+	if td, _ := ctx.Value(tracerKey{}).(*tracerData); td != nil {
+		sqllog.Log(ctx, t.l, td.sql, td.args, time.Since(td.start), data.Err)
+	}
+}
+
+// TraceCopyFromStart implements [pgx.CopyFromTracer].
+func (t *tracer) TraceCopyFromStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	ctx = t.TraceLog.TraceCopyFromStart(ctx, conn, data)
+
+	columns := make([]string, len(data.ColumnNames))
+	for i, c := range data.ColumnNames {
+		columns[i] = pgx.Identifier{c}.Sanitize()
+	}
+
+	return context.WithValue(ctx, tracerKey{}, &tracerData{
+		start: time.Now(),
+		sql:   fmt.Sprintf("COPY %s (%s) FROM STDIN", data.TableName.Sanitize(), strings.Join(columns, ", ")),
+	})
+}
+
+// TraceCopyFromEnd implements [pgx.CopyFromTracer].
+func (t *tracer) TraceCopyFromEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	t.TraceLog.TraceCopyFromEnd(ctx, conn, data)
+
+	if td, _ := ctx.Value(tracerKey{}).(*tracerData); td != nil {
+		sqllog.Log(ctx, t.l, td.sql, td.args, time.Since(td.start), data.Err)
+	}
+}
+
+// check interfaces
+var (
+	_ pgx.QueryTracer    = (*tracer)(nil)
+	_ pgx.BatchTracer    = (*tracer)(nil)
+	_ pgx.CopyFromTracer = (*tracer)(nil)
+	_ pgx.PrepareTracer  = (*tracer)(nil)
+	_ pgx.ConnectTracer  = (*tracer)(nil)
+)
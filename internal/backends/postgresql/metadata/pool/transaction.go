@@ -16,23 +16,88 @@ package pool
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/FerretDB/FerretDB/internal/util/ctxutil"
 	"github.com/FerretDB/FerretDB/internal/util/observability"
 )
 
+// maxTransactionAttempts is the maximum number of attempts to run a transaction in [InTransactionRetry].
+const maxTransactionAttempts = 3
+
 // InTransaction uses pool p and wraps the given function f in a transaction.
 //
 // If f returns an error or context is canceled, the transaction is rolled back.
+// The transaction is not retried; see [InTransactionRetry].
+//
+// If the context has a deadline, the transaction's timeouts are lowered to the time left.
 func InTransaction(ctx context.Context, p *pgxpool.Pool, f func(tx pgx.Tx) error) error {
 	defer observability.FuncCall(ctx)()
 
-	if err := pgx.BeginFunc(ctx, p, f); err != nil {
+	if err := pgx.BeginFunc(ctx, p, withLocalTimeouts(ctx, f)); err != nil {
 		// do not wrap error because the caller of f depends on it in some cases
 		return err
 	}
 
 	return nil
 }
+
+// InTransactionRetry is like [InTransaction], but if the transaction fails due to serialization failure
+// or deadlock, it is retried with backoff, so f could be called multiple times.
+//
+// For that reason, f must be idempotent: it should not have side effects outside the transaction,
+// and it should reset any results it accumulates (such as counters) at the start.
+func InTransactionRetry(ctx context.Context, p *pgxpool.Pool, f func(tx pgx.Tx) error) error {
+	defer observability.FuncCall(ctx)()
+
+	var err error
+
+	for attempt := int64(1); ; attempt++ {
+		if err = pgx.BeginFunc(ctx, p, withLocalTimeouts(ctx, f)); err == nil {
+			return nil
+		}
+
+		if attempt >= maxTransactionAttempts || !isRetryable(err) {
+			// do not wrap error because the caller of f depends on it in some cases
+			return err
+		}
+
+		ctxutil.SleepWithJitter(ctx, time.Millisecond*10, attempt)
+
+		if ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+// withLocalTimeouts returns a function that sets transaction's timeouts before calling f.
+func withLocalTimeouts(ctx context.Context, f func(tx pgx.Tx) error) func(tx pgx.Tx) error {
+	return func(tx pgx.Tx) error {
+		if err := setLocalTimeouts(ctx, tx); err != nil {
+			return err
+		}
+
+		return f(tx)
+	}
+}
+
+// isRetryable returns true if the transaction failed with the given error could be safely retried.
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	switch pgErr.Code {
+	case pgerrcode.SerializationFailure, pgerrcode.DeadlockDetected:
+		return true
+	default:
+		return false
+	}
+}
@@ -31,9 +31,11 @@ import (
 	"golang.org/x/exp/maps"
 
 	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/postgresql/faults"
 	"github.com/FerretDB/FerretDB/internal/backends/postgresql/metadata/pool"
 	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
 	"github.com/FerretDB/FerretDB/internal/handlers/sjson"
+	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/util/observability"
@@ -72,8 +74,9 @@ var specialCharacters = regexp.MustCompile("[^a-z][^a-z0-9_]*")
 //
 //nolint:vet // for readability
 type Registry struct {
-	p *pool.Pool
-	l *zap.Logger
+	p  *pool.Pool
+	l  *zap.Logger
+	sp *state.Provider
 
 	// rw protects colls but also acts like a global lock for the whole registry.
 	// The latter effectively replaces transactions (see the postgresql backend package description for more info).
@@ -82,18 +85,37 @@ type Registry struct {
 	// TODO https://github.com/FerretDB/FerretDB/issues/2755
 	rw    sync.RWMutex
 	colls map[string]map[string]*Collection // database name -> collection name -> collection
+
+	// viewsM protects views and serializes relational views DDL.
+	// If both locks are needed, rw should be acquired first.
+	viewsM sync.Mutex
+	views  map[string]map[string]viewSchema // nil if relational views are disabled
+
+	tenants bool // new collections use row-level security tenant isolation
 }
 
 // NewRegistry creates a registry for PostgreSQL databases with a given base URI.
-func NewRegistry(u string, l *zap.Logger, sp *state.Provider) (*Registry, error) {
-	p, err := pool.New(u, l, sp)
+//
+// Faults injector may be nil; it is set only by tests.
+//
+// If views is true, relational views with columns for top-level scalar fields are maintained for collections.
+// If tenants is true, new collections store documents of each PostgreSQL user separately
+// using row-level security; see [tenantQueries].
+func NewRegistry(u string, l *zap.Logger, sp *state.Provider, fi *faults.Injector, views, tenants bool) (*Registry, error) {
+	p, err := pool.New(u, l, sp, fi)
 	if err != nil {
 		return nil, err
 	}
 
 	r := &Registry{
-		p: p,
-		l: l,
+		p:       p,
+		l:       l,
+		sp:      sp,
+		tenants: tenants,
+	}
+
+	if views {
+		r.views = map[string]map[string]viewSchema{}
 	}
 
 	return r, nil
@@ -104,6 +126,33 @@ func (r *Registry) Close() {
 	r.p.Close()
 }
 
+// InTransaction uses pool p and wraps the given function f in a transaction.
+//
+// See [pool.InTransaction] for details.
+// Injected transaction faults are returned after f succeeds, just before commit.
+func (r *Registry) InTransaction(ctx context.Context, p *pgxpool.Pool, f func(tx pgx.Tx) error) error {
+	return pool.InTransaction(ctx, p, r.withTxFaults(f))
+}
+
+// InTransactionRetry is like [Registry.InTransaction], but retries the transaction
+// on serialization failure or deadlock.
+//
+// It should be used only if f is idempotent; see [pool.InTransactionRetry] for details.
+func (r *Registry) InTransactionRetry(ctx context.Context, p *pgxpool.Pool, f func(tx pgx.Tx) error) error {
+	return pool.InTransactionRetry(ctx, p, r.withTxFaults(f))
+}
+
+// withTxFaults returns a function that calls f and then returns injected transaction fault, if any.
+func (r *Registry) withTxFaults(f func(tx pgx.Tx) error) func(tx pgx.Tx) error {
+	return func(tx pgx.Tx) error {
+		if err := f(tx); err != nil {
+			return err
+		}
+
+		return r.p.Faults().TxError()
+	}
+}
+
 // getPool returns a pool of connections to PostgreSQL database
 // for the username/password combination in the context using [conninfo].
 //
@@ -233,6 +282,19 @@ func (r *Registry) initCollections(ctx context.Context, dbName string, p *pgxpoo
 
 	r.colls[dbName] = colls
 
+	if r.views == nil {
+		return nil
+	}
+
+	r.viewsM.Lock()
+	defer r.viewsM.Unlock()
+
+	for _, c := range colls {
+		if err = r.viewInit(ctx, p, dbName, c); err != nil {
+			r.l.Warn("Failed to create relational view", zap.String("db", dbName), zap.String("collection", c.Name), zap.Error(err))
+		}
+	}
+
 	return nil
 }
 
@@ -405,6 +467,12 @@ func (r *Registry) databaseDrop(ctx context.Context, p *pgxpool.Pool, dbName str
 
 	delete(r.colls, dbName)
 
+	if r.views != nil {
+		r.viewsM.Lock()
+		delete(r.views, dbName)
+		r.viewsM.Unlock()
+	}
+
 	return true, nil
 }
 
@@ -444,6 +512,7 @@ type CollectionCreateParams struct {
 	Name            string
 	CappedSize      int64
 	CappedDocuments int64
+	Tablespace      string   // empty for the default tablespace
 	_               struct{} // prevent unkeyed literals
 }
 
@@ -525,20 +594,44 @@ func (r *Registry) collectionCreate(ctx context.Context, p *pgxpool.Pool, params
 		TableName:       tableName,
 		CappedSize:      params.CappedSize,
 		CappedDocuments: params.CappedDocuments,
+		Sequence:        !params.Capped(),
+		Tenant:          r.tenants,
 	}
 
 	q := fmt.Sprintf(`CREATE TABLE %s (`, pgx.Identifier{dbName, tableName}.Sanitize())
 
 	if params.Capped() {
 		q += fmt.Sprintf(`%s bigint PRIMARY KEY, `, RecordIDColumn)
+	} else {
+		// capped collections are ordered by record ID
+		q += fmt.Sprintf(`%s bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY, `, SequenceColumn)
+	}
+
+	if c.Tenant {
+		q += fmt.Sprintf(`%s text NOT NULL DEFAULT current_user, `, TenantColumn)
 	}
 
 	q += fmt.Sprintf(`%s jsonb)`, DefaultColumn)
 
+	if params.Tablespace != "" {
+		q += fmt.Sprintf(` TABLESPACE %s`, pgx.Identifier{params.Tablespace}.Sanitize())
+	}
+
 	if _, err = p.Exec(ctx, q); err != nil {
 		return false, lazyerrors.Error(err)
 	}
 
+	if c.Tenant {
+		for _, q = range tenantQueries(dbName, tableName) {
+			if _, err = p.Exec(ctx, q); err != nil {
+				q = fmt.Sprintf(`DROP TABLE %s`, pgx.Identifier{dbName, tableName}.Sanitize())
+				_, _ = p.Exec(ctx, q)
+
+				return false, lazyerrors.Error(err)
+			}
+		}
+	}
+
 	q = fmt.Sprintf(
 		`INSERT INTO %s (%s) VALUES ($1)`,
 		pgx.Identifier{dbName, metadataTableName}.Sanitize(),
@@ -670,6 +763,13 @@ func (r *Registry) collectionDrop(ctx context.Context, p *pgxpool.Pool, dbName,
 
 	delete(r.colls[dbName], collectionName)
 
+	// the view, if any, was dropped with the table
+	if r.views != nil {
+		r.viewsM.Lock()
+		delete(r.views[dbName], collectionName)
+		r.viewsM.Unlock()
+	}
+
 	return true, nil
 }
 
@@ -728,6 +828,76 @@ func (r *Registry) CollectionRename(ctx context.Context, dbName, oldCollectionNa
 	r.colls[dbName][newCollectionName] = c
 	delete(r.colls[dbName], oldCollectionName)
 
+	if r.views != nil {
+		r.viewsM.Lock()
+		defer r.viewsM.Unlock()
+
+		s, ok := r.views[dbName][oldCollectionName]
+
+		if err = r.viewDrop(ctx, p, dbName, oldCollectionName); err == nil && ok {
+			err = r.viewCreate(ctx, p, dbName, c, s)
+		}
+
+		if err != nil {
+			r.l.Warn("Failed to rename relational view", zap.String("db", dbName), zap.String("collection", c.Name), zap.Error(err))
+		}
+	}
+
+	return true, nil
+}
+
+// CollectionSetSettings replaces handler-defined settings of the collection.
+//
+// Returned boolean value indicates whether the settings were replaced.
+// If database or collection did not exist, (false, nil) is returned.
+//
+// If the user is not authenticated, it returns error.
+//
+//nolint:lll // for readability
+func (r *Registry) CollectionSetSettings(ctx context.Context, dbName, collectionName string, settings *types.Document) (bool, error) {
+	defer observability.FuncCall(ctx)()
+
+	p, err := r.getPool(ctx)
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	c := r.collectionGet(dbName, collectionName)
+	if c == nil {
+		return false, nil
+	}
+
+	c.Settings = nil
+	if settings != nil {
+		c.Settings = settings.DeepCopy()
+	}
+
+	b, err := sjson.Marshal(c.marshal())
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	arg, err := sjson.MarshalSingleValue(collectionName)
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	q := fmt.Sprintf(
+		`UPDATE %s SET %s = $1 WHERE %s = $2`,
+		pgx.Identifier{dbName, metadataTableName}.Sanitize(),
+		DefaultColumn,
+		IDColumn,
+	)
+
+	if _, err = p.Exec(ctx, q, string(b), arg); err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	r.colls[dbName][collectionName] = c
+
 	return true, nil
 }
 
@@ -825,44 +995,29 @@ func (r *Registry) indexesCreate(ctx context.Context, p *pgxpool.Pool, dbName, c
 
 		index.PgIndex = pgIndexName
 
-		q := "CREATE "
-
-		if index.Unique {
-			q += "UNIQUE "
+		var q string
+		if q, err = indexQuery(dbName, c, index); err != nil {
+			_ = r.indexesDrop(ctx, p, dbName, collectionName, created)
+			return lazyerrors.Error(err)
 		}
 
-		q += "INDEX %s ON %s (%s)"
-
-		columns := make([]string, len(index.Key))
+		if _, err = p.Exec(ctx, q); err != nil {
+			_ = r.indexesDrop(ctx, p, dbName, collectionName, created)
+			return lazyerrors.Error(err)
+		}
 
-		for i, key := range index.Key {
-			// if the field is nested (e.g. foo.bar), it needs to be translated to the correct json path (foo -> bar)
-			fs := strings.Split(key.Field, ".")
-			transformedParts := make([]string, len(fs))
+		if r.trigramIndexable(index) {
+			// replace the suffix to keep the name unique and within the length limit
+			index.TrgmIndex = strings.TrimSuffix(pgIndexName, "_idx") + "_trgm"
 
-			for j, f := range fs {
-				// It's important to sanitize field.Field data here, as it's a user-provided value.
-				transformedParts[j] = quoteString(f)
-			}
+			if _, err = p.Exec(ctx, trigramIndexQuery(dbName, c.TableName, index)); err != nil {
+				_, _ = p.Exec(ctx, fmt.Sprintf("DROP INDEX %s", pgx.Identifier{dbName, index.PgIndex}.Sanitize()))
+				_ = r.indexesDrop(ctx, p, dbName, collectionName, created)
 
-			columns[i] = fmt.Sprintf("((%s->%s))", DefaultColumn, strings.Join(transformedParts, " -> "))
-			if key.Descending {
-				columns[i] += " DESC"
+				return lazyerrors.Error(err)
 			}
 		}
 
-		q = fmt.Sprintf(
-			q,
-			pgx.Identifier{index.PgIndex}.Sanitize(),
-			pgx.Identifier{dbName, c.TableName}.Sanitize(),
-			strings.Join(columns, ", "),
-		)
-
-		if _, err = p.Exec(ctx, q); err != nil {
-			_ = r.indexesDrop(ctx, p, dbName, collectionName, created)
-			return lazyerrors.Error(err)
-		}
-
 		created = append(created, index.Name)
 		c.Indexes = append(c.Indexes, index)
 		allIndexes[index.Name] = collectionName
@@ -895,6 +1050,184 @@ func (r *Registry) indexesCreate(ctx context.Context, p *pgxpool.Pool, dbName, c
 	return nil
 }
 
+// indexQuery returns a query that creates the given index on the given table.
+//
+// Index's PgIndex field should be set.
+// Index's PartialFilterExpression is translated to the WHERE clause of the partial index.
+// Text indexes are GIN indexes on [TextSearchVector].
+// 2dsphere indexes are GiST indexes on [GeographyExpression].
+// Unique indexes of tenant collections include [TenantColumn], so values are unique for each tenant.
+func indexQuery(dbName string, c *Collection, index IndexInfo) (string, error) {
+	tableName := c.TableName
+
+	if index.Sphere {
+		return fmt.Sprintf(
+			"CREATE INDEX %s ON %s USING gist ((%s))",
+			pgx.Identifier{index.PgIndex}.Sanitize(),
+			pgx.Identifier{dbName, tableName}.Sanitize(),
+			GeographyExpression(index.Key[0].Field),
+		), nil
+	}
+
+	if index.Text {
+		return fmt.Sprintf(
+			"CREATE INDEX %s ON %s USING gin (%s)",
+			pgx.Identifier{index.PgIndex}.Sanitize(),
+			pgx.Identifier{dbName, tableName}.Sanitize(),
+			TextSearchVector(index),
+		), nil
+	}
+
+	q := "CREATE "
+
+	if index.Unique {
+		q += "UNIQUE "
+	}
+
+	q += "INDEX %s ON %s (%s)"
+
+	columns := make([]string, len(index.Key), len(index.Key)+1)
+
+	for i, key := range index.Key {
+		// if the field is nested (e.g. foo.bar), it needs to be translated to the correct json path (foo -> bar)
+		fs := strings.Split(key.Field, ".")
+		transformedParts := make([]string, len(fs))
+
+		for j, f := range fs {
+			// It's important to sanitize field.Field data here, as it's a user-provided value.
+			transformedParts[j] = quoteString(f)
+		}
+
+		columns[i] = fmt.Sprintf("((%s->%s))", DefaultColumn, strings.Join(transformedParts, " -> "))
+		if key.Descending {
+			columns[i] += " DESC"
+		}
+	}
+
+	if index.Unique && c.Tenant {
+		columns = append([]string{TenantColumn}, columns...)
+	}
+
+	q = fmt.Sprintf(
+		q,
+		pgx.Identifier{index.PgIndex}.Sanitize(),
+		pgx.Identifier{dbName, tableName}.Sanitize(),
+		strings.Join(columns, ", "),
+	)
+
+	if index.PartialFilterExpression != nil {
+		cond, err := partialFilterCondition(index.PartialFilterExpression)
+		if err != nil {
+			return "", lazyerrors.Error(err)
+		}
+
+		q += " WHERE " + cond
+	}
+
+	return q, nil
+}
+
+// trigramIndexable returns true if a trigram index should be created in addition to the given index.
+//
+// That is done only if pg_trgm extension is available, and only for single-field indexes
+// on top-level fields other than _id, as only they are used by regex filter pushdown.
+func (r *Registry) trigramIndexable(index IndexInfo) bool {
+	if _, ok := r.sp.Get().BackendCapabilities[backends.CapabilityTrigram]; !ok {
+		return false
+	}
+
+	if len(index.Key) != 1 || index.Text || index.Sphere {
+		return false
+	}
+
+	field := index.Key[0].Field
+
+	return field != "_id" && !strings.Contains(field, ".")
+}
+
+// trigramIndexQuery returns a query that creates the trigram GIN index for the given index on the given table.
+//
+// Index's TrgmIndex field should be set.
+func trigramIndexQuery(dbName, tableName string, index IndexInfo) string {
+	return fmt.Sprintf(
+		"CREATE INDEX %s ON %s USING gin (%s gin_trgm_ops)",
+		pgx.Identifier{index.TrgmIndex}.Sanitize(),
+		pgx.Identifier{dbName, tableName}.Sanitize(),
+		TrigramExpression(index.Key[0].Field),
+	)
+}
+
+// TrigramExpression returns SQL expression for the text value of the given top-level field
+// that is used by trigram indexes.
+//
+// Queries should use exactly the same expression for PostgreSQL to use those indexes.
+func TrigramExpression(field string) string {
+	return fmt.Sprintf("(%s->>%s)", DefaultColumn, quoteString(field))
+}
+
+// ArrayLengthExpression returns SQL expression for the length of the given top-level field's array value.
+// It is NULL for other values.
+//
+// Queries use exactly the same expression, so PostgreSQL can use expression indexes on it.
+func ArrayLengthExpression(field string) string {
+	v := fmt.Sprintf("%s->%s", DefaultColumn, quoteString(field))
+	return fmt.Sprintf("(CASE WHEN jsonb_typeof(%[1]s) = 'array' THEN jsonb_array_length(%[1]s) END)", v)
+}
+
+// GeographyExpression returns SQL expression for the PostGIS geography of the given field's value.
+// GeoJSON objects and legacy coordinate pairs (arrays) are converted; other values are NULL.
+//
+// Queries should use exactly the same expression for PostgreSQL to use 2dsphere indexes.
+func GeographyExpression(field string) string {
+	fs := strings.Split(field, ".")
+	for i, f := range fs {
+		fs[i] = quoteString(f)
+	}
+
+	v := DefaultColumn + "->" + strings.Join(fs, "->")
+
+	return fmt.Sprintf(
+		"CASE jsonb_typeof(%[1]s) "+
+			"WHEN 'object' THEN ST_GeomFromGeoJSON((%[1]s)::text)::geography "+
+			"WHEN 'array' THEN ST_SetSRID(ST_MakePoint((%[1]s->>0)::float8, (%[1]s->>1)::float8), 4326)::geography "+
+			"END",
+		v,
+	)
+}
+
+// TextSearchVector returns SQL expression for the tsvector of the given text index's fields.
+// Only string values (including array elements) are indexed.
+//
+// Queries should use exactly the same expression for PostgreSQL to use text indexes.
+func TextSearchVector(index IndexInfo) string {
+	vectors := make([]string, len(index.Key))
+
+	for i, key := range index.Key {
+		fs := strings.Split(key.Field, ".")
+		for j, f := range fs {
+			fs[j] = quoteString(f)
+		}
+
+		// missing fields should not make the whole vector NULL
+		vectors[i] = fmt.Sprintf(
+			`coalesce(jsonb_to_tsvector(%s::regconfig, %s->%s, '["string"]'), '')`,
+			quoteString(TextSearchConfig(index.DefaultLanguage)), DefaultColumn, strings.Join(fs, "->"),
+		)
+	}
+
+	return "(" + strings.Join(vectors, " || ") + ")"
+}
+
+// TextSearchConfig returns PostgreSQL text search configuration name for the given text search language.
+func TextSearchConfig(language string) string {
+	switch language {
+	case "", "none":
+		return "simple"
+	default:
+		return language
+	}
+}
+
 // IndexesDrop removes given connection's indexes.
 //
 // Non-existing indexes are ignored.
@@ -942,6 +1275,13 @@ func (r *Registry) indexesDrop(ctx context.Context, p *pgxpool.Pool, dbName, col
 			return lazyerrors.Error(err)
 		}
 
+		if trgmIndex := c.Indexes[i].TrgmIndex; trgmIndex != "" {
+			q = fmt.Sprintf("DROP INDEX IF EXISTS %s", pgx.Identifier{dbName, trgmIndex}.Sanitize())
+			if _, err := p.Exec(ctx, q); err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+
 		c.Indexes = slices.Delete(c.Indexes, i, i+1)
 	}
 
@@ -29,6 +29,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/handlers/sjson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/util/state"
 	"github.com/FerretDB/FerretDB/internal/util/testutil"
 	"github.com/FerretDB/FerretDB/internal/util/testutil/teststress"
@@ -91,7 +94,7 @@ func createDatabase(t *testing.T, ctx context.Context) (*Registry, *pgxpool.Pool
 	sp, err := state.NewProvider("")
 	require.NoError(t, err)
 
-	r, err := NewRegistry(u, testutil.Logger(t), sp)
+	r, err := NewRegistry(u, testutil.Logger(t), sp, nil, false, false)
 	require.NoError(t, err)
 	t.Cleanup(r.Close)
 
@@ -140,7 +143,7 @@ func TestAuth(t *testing.T) {
 			sp, err := state.NewProvider("")
 			require.NoError(t, err)
 
-			r, err := NewRegistry(tc.uri, testutil.Logger(t), sp)
+			r, err := NewRegistry(tc.uri, testutil.Logger(t), sp, nil, false, false)
 			require.NoError(t, err)
 			t.Cleanup(r.Close)
 
@@ -360,6 +363,7 @@ func TestRenameCollection(t *testing.T) {
 			Name:      newCollectionName,
 			TableName: oldCollection.TableName,
 			Indexes:   oldCollection.Indexes,
+			Sequence:  true,
 		}
 
 		actual, err := r.CollectionGet(ctx, dbName, newCollectionName)
@@ -697,3 +701,174 @@ func TestLongIndexNames(t *testing.T) {
 		})
 	}
 }
+
+func TestViewColumn(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		field    string
+		types    []string
+		expected string
+	}{
+		"Int": {
+			field:    "v",
+			types:    []string{"int"},
+			expected: `CASE WHEN _jsonb->'$s'->'p'->'v'->>'t' IN ('int') THEN (_jsonb->>'v')::integer END AS "v"`,
+		},
+		"Date": {
+			field: "d",
+			types: []string{"date"},
+			expected: `CASE WHEN _jsonb->'$s'->'p'->'d'->>'t' IN ('date') ` +
+				`THEN to_timestamp((_jsonb->>'d')::bigint / 1000.0) END AS "d"`,
+		},
+		"Numbers": {
+			field: "n",
+			types: []string{"double", "int", "long"},
+			expected: `CASE WHEN _jsonb->'$s'->'p'->'n'->>'t' IN ('double', 'int', 'long') ` +
+				`THEN (_jsonb->>'n')::double precision END AS "n"`,
+		},
+		"Mixed": {
+			field: `it's "x"`,
+			types: []string{"int", "string"},
+			expected: `CASE WHEN _jsonb->'$s'->'p'->'it''s "x"'->>'t' IN ('int', 'string') ` +
+				`THEN _jsonb->>'it''s "x"' END AS "it's ""x"""`,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, viewColumn(tc.field, tc.types))
+		})
+	}
+}
+
+func TestRelationalViews(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in -short mode")
+	}
+
+	t.Parallel()
+
+	ctx := conninfo.Ctx(testutil.Ctx(t), conninfo.New())
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	r, err := NewRegistry(testutil.TestPostgreSQLURI(t, ctx, ""), testutil.Logger(t), sp, nil, true, false)
+	require.NoError(t, err)
+	t.Cleanup(r.Close)
+
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	p, err := r.DatabaseGetOrCreate(ctx, dbName)
+	require.NoError(t, err)
+
+	created, err := r.CollectionCreate(ctx, &CollectionCreateParams{DBName: dbName, Name: collectionName})
+	require.NoError(t, err)
+	require.True(t, created)
+
+	c, err := r.CollectionGet(ctx, dbName, collectionName)
+	require.NoError(t, err)
+
+	insert := func(doc *types.Document) {
+		q := fmt.Sprintf(`INSERT INTO %s (%s) VALUES($1)`, pgx.Identifier{dbName, c.TableName}.Sanitize(), DefaultColumn)
+		_, err = p.Exec(ctx, q, must.NotFail(sjson.Marshal(doc)))
+		require.NoError(t, err)
+
+		r.ViewsUpdate(ctx, dbName, collectionName, []*types.Document{doc})
+	}
+
+	insert(must.NotFail(types.NewDocument(
+		"_id", int32(1),
+		"v", int32(42),
+		"o", must.NotFail(types.NewDocument("x", "y")),
+	)))
+
+	var id, v int32
+	q := fmt.Sprintf(`SELECT * FROM %s`, pgx.Identifier{dbName, collectionName}.Sanitize())
+	require.NoError(t, p.QueryRow(ctx, q).Scan(&id, &v))
+	assert.Equal(t, int32(1), id)
+	assert.Equal(t, int32(42), v)
+
+	// schema drift: new field and new type of existing field
+	insert(must.NotFail(types.NewDocument("_id", int32(2), "v", "foo", "n", int64(7))))
+
+	var n *int64
+	var s string
+	q = fmt.Sprintf(`SELECT n, v FROM %s WHERE _id = 2`, pgx.Identifier{dbName, collectionName}.Sanitize())
+	require.NoError(t, p.QueryRow(ctx, q).Scan(&n, &s))
+	require.NotNil(t, n)
+	assert.Equal(t, int64(7), *n)
+	assert.Equal(t, "foo", s)
+
+	renamed, err := r.CollectionRename(ctx, dbName, collectionName, "renamed")
+	require.NoError(t, err)
+	require.True(t, renamed)
+
+	q = fmt.Sprintf(`SELECT count(*) FROM %s`, pgx.Identifier{dbName, "renamed"}.Sanitize())
+	var count int64
+	require.NoError(t, p.QueryRow(ctx, q).Scan(&count))
+	assert.Equal(t, int64(2), count)
+
+	dropped, err := r.CollectionDrop(ctx, dbName, "renamed")
+	require.NoError(t, err)
+	require.True(t, dropped)
+}
+
+func TestTenantIsolation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in -short mode")
+	}
+
+	t.Parallel()
+
+	ctx := conninfo.Ctx(testutil.Ctx(t), conninfo.New())
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	r, err := NewRegistry(testutil.TestPostgreSQLURI(t, ctx, ""), testutil.Logger(t), sp, nil, false, true)
+	require.NoError(t, err)
+	t.Cleanup(r.Close)
+
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	p, err := r.DatabaseGetOrCreate(ctx, dbName)
+	require.NoError(t, err)
+
+	created, err := r.CollectionCreate(ctx, &CollectionCreateParams{DBName: dbName, Name: collectionName})
+	require.NoError(t, err)
+	require.True(t, created)
+
+	c, err := r.CollectionGet(ctx, dbName, collectionName)
+	require.NoError(t, err)
+	require.True(t, c.Tenant)
+
+	table := pgx.Identifier{dbName, c.TableName}.Sanitize()
+
+	var enabled, forced bool
+	q := `SELECT relrowsecurity, relforcerowsecurity FROM pg_class WHERE oid = $1::regclass`
+	require.NoError(t, p.QueryRow(ctx, q, table).Scan(&enabled, &forced))
+	assert.True(t, enabled)
+	assert.True(t, forced)
+
+	doc := `{"$s": {"p": {"_id": {"t": "int"}}, "$k": ["_id"]}, "_id": 42}`
+
+	q = fmt.Sprintf(`INSERT INTO %s (%s) VALUES($1)`, table, DefaultColumn)
+	_, err = p.Exec(ctx, q, doc)
+	require.NoError(t, err)
+
+	var tenant, user string
+	q = fmt.Sprintf(`SELECT %s, current_user FROM %s`, TenantColumn, table)
+	require.NoError(t, p.QueryRow(ctx, q).Scan(&tenant, &user))
+	assert.Equal(t, user, tenant)
+
+	// _id is unique for each tenant only
+	q = `SELECT indexdef FROM pg_indexes WHERE schemaname = $1 AND indexname = $2`
+	var indexDef string
+	require.NoError(t, p.QueryRow(ctx, q, dbName, c.Indexes[0].PgIndex).Scan(&indexDef))
+	assert.Contains(t, indexDef, "("+TenantColumn+", ")
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// tenantPolicyName is the name of the row-level security policy of tenant collections.
+const tenantPolicyName = "ferretdb_tenant_isolation"
+
+// tenantQueries returns queries that enable row-level security tenant isolation for the given table.
+//
+// Documents are visible and writable only by the PostgreSQL user stored in [TenantColumn],
+// which defaults to the user that inserted them; that is the same user that authenticated
+// the client connection. Row-level security is forced, so the table owner is restricted too.
+// Data manipulation is granted to all users, so they can share the collection;
+// creating and dropping collections and indexes still requires ownership.
+func tenantQueries(dbName, tableName string) []string {
+	table := pgx.Identifier{dbName, tableName}.Sanitize()
+
+	return []string{
+		fmt.Sprintf(`ALTER TABLE %s ENABLE ROW LEVEL SECURITY`, table),
+		fmt.Sprintf(`ALTER TABLE %s FORCE ROW LEVEL SECURITY`, table),
+		fmt.Sprintf(
+			`CREATE POLICY %[1]s ON %[2]s USING (%[3]s = current_user) WITH CHECK (%[3]s = current_user)`,
+			pgx.Identifier{tenantPolicyName}.Sanitize(), table, TenantColumn,
+		),
+		fmt.Sprintf(`GRANT SELECT, INSERT, UPDATE, DELETE ON %s TO PUBLIC`, table),
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/exp/maps"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/sjson"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/observability"
+)
+
+// ValidateMetadata compares collections metadata with the actual PostgreSQL schema of the given database.
+//
+// If repair is true, collections without tables are removed from the metadata,
+// and missing indexes are recreated.
+// Orphan tables are only reported.
+//
+// If database does not exist, (nil, nil) is returned.
+//
+// If the user is not authenticated, it returns error.
+func (r *Registry) ValidateMetadata(ctx context.Context, dbName string, repair bool) ([]backends.MetadataProblem, error) {
+	defer observability.FuncCall(ctx)()
+
+	p, err := r.getPool(ctx)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	colls := r.colls[dbName]
+	if colls == nil {
+		return nil, nil
+	}
+
+	tables, err := schemaNames(ctx, p, "SELECT tablename FROM pg_tables WHERE schemaname = $1", dbName)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	indexes, err := schemaNames(ctx, p, "SELECT indexname FROM pg_indexes WHERE schemaname = $1", dbName)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var res []backends.MetadataProblem
+
+	used := map[string]struct{}{metadataTableName: {}}
+
+	names := maps.Keys(colls)
+	sort.Strings(names)
+
+	for _, name := range names {
+		c := colls[name]
+		used[c.TableName] = struct{}{}
+
+		if _, ok := tables[c.TableName]; !ok {
+			problem := backends.MetadataProblem{
+				Type:       backends.MetadataProblemMissingTable,
+				Collection: c.Name,
+				Table:      c.TableName,
+			}
+
+			if repair {
+				if err = r.metadataDelete(ctx, p, dbName, c.Name); err != nil {
+					return nil, lazyerrors.Error(err)
+				}
+
+				delete(colls, c.Name)
+				problem.Repaired = true
+			}
+
+			res = append(res, problem)
+
+			continue
+		}
+
+		for _, index := range c.Indexes {
+			if _, ok := indexes[index.PgIndex]; ok {
+				continue
+			}
+
+			problem := backends.MetadataProblem{
+				Type:       backends.MetadataProblemMissingIndex,
+				Collection: c.Name,
+				Table:      c.TableName,
+				Index:      index.Name,
+			}
+
+			if repair {
+				var q string
+				if q, err = indexQuery(dbName, c, index); err != nil {
+					return nil, lazyerrors.Error(err)
+				}
+
+				if _, err = p.Exec(ctx, q); err != nil {
+					return nil, lazyerrors.Error(err)
+				}
+
+				problem.Repaired = true
+			}
+
+			res = append(res, problem)
+		}
+	}
+
+	orphans := maps.Keys(tables)
+	sort.Strings(orphans)
+
+	for _, table := range orphans {
+		if _, ok := used[table]; ok {
+			continue
+		}
+
+		res = append(res, backends.MetadataProblem{
+			Type:  backends.MetadataProblemOrphanTable,
+			Table: table,
+		})
+	}
+
+	return res, nil
+}
+
+// metadataDelete removes the given collection's metadata without dropping its table.
+//
+// It does not hold the lock.
+func (r *Registry) metadataDelete(ctx context.Context, p *pgxpool.Pool, dbName, collectionName string) error {
+	arg, err := sjson.MarshalSingleValue(collectionName)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	q := fmt.Sprintf(
+		`DELETE FROM %s WHERE %s IN ($1)`,
+		pgx.Identifier{dbName, metadataTableName}.Sanitize(),
+		IDColumn,
+	)
+
+	if _, err = p.Exec(ctx, q, arg); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// schemaNames returns a set of names returned by the given query
+// that has a single schema name placeholder.
+func schemaNames(ctx context.Context, p *pgxpool.Pool, q, dbName string) (map[string]struct{}, error) {
+	rows, err := p.Query(ctx, q, dbName)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+	defer rows.Close()
+
+	res := map[string]struct{}{}
+
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		res[name] = struct{}{}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
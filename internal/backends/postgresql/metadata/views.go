@@ -0,0 +1,285 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/sjson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/observability"
+)
+
+// viewSampleSize is the number of documents used to infer columns of the relational view.
+const viewSampleSize = 1000
+
+// viewColumnTypes maps sjson types of scalar values to PostgreSQL types of relational view columns.
+var viewColumnTypes = map[string]string{
+	"bool":     "boolean",
+	"date":     "timestamptz",
+	"double":   "double precision",
+	"int":      "integer",
+	"long":     "bigint",
+	"objectId": "text",
+	"string":   "text",
+}
+
+// viewSchema maps top-level field names to sorted sjson types of their scalar values.
+type viewSchema map[string][]string
+
+// add adds the given field type to the schema.
+//
+// Non-scalar types and fields with names that are too long for PostgreSQL identifiers are ignored.
+// It returns true if the schema was changed.
+func (s viewSchema) add(field, typ string) bool {
+	if _, ok := viewColumnTypes[typ]; !ok {
+		return false
+	}
+
+	if len(field) > maxTableNameLength || slices.Contains(s[field], typ) {
+		return false
+	}
+
+	s[field] = append(s[field], typ)
+	slices.Sort(s[field])
+
+	return true
+}
+
+// viewColumn returns SQL expression of the relational view column for the given field and its sjson types.
+//
+// Values of types that were not seen during inference are NULL.
+// Fields with values of numeric types use the widest type; other fields with mixed types use text.
+func viewColumn(field string, typs []string) string {
+	typ := "text"
+
+	switch {
+	case len(typs) == 1:
+		typ = viewColumnTypes[typs[0]]
+
+	case !slices.ContainsFunc(typs, func(t string) bool { return t != "int" && t != "long" && t != "double" }):
+		typ = "bigint"
+		if slices.Contains(typs, "double") {
+			typ = "double precision"
+		}
+	}
+
+	value := fmt.Sprintf(`%s->>%s`, DefaultColumn, quoteString(field))
+
+	switch typ {
+	case "text":
+		// nothing
+	case "timestamptz":
+		value = fmt.Sprintf(`to_timestamp((%s)::bigint / 1000.0)`, value)
+	default:
+		value = fmt.Sprintf(`(%s)::%s`, value, typ)
+	}
+
+	quoted := make([]string, len(typs))
+	for i, t := range typs {
+		quoted[i] = quoteString(t)
+	}
+
+	return fmt.Sprintf(
+		`CASE WHEN %s->'$s'->'p'->%s->>'t' IN (%s) THEN %s END AS %s`,
+		DefaultColumn, quoteString(field), strings.Join(quoted, ", "), value, pgx.Identifier{field}.Sanitize(),
+	)
+}
+
+// viewQuery returns a query that creates the relational view over the given table.
+//
+// The `_id` column goes first, other columns are sorted by field name.
+func viewQuery(dbName, viewName, tableName string, s viewSchema) string {
+	fields := maps.Keys(s)
+	slices.SortFunc(fields, func(a, b string) int {
+		switch {
+		case a == b:
+			return 0
+		case a == "_id":
+			return -1
+		case b == "_id":
+			return 1
+		default:
+			return strings.Compare(a, b)
+		}
+	})
+
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = viewColumn(f, s[f])
+	}
+
+	return fmt.Sprintf(
+		`CREATE VIEW %s AS SELECT %s FROM %s`,
+		pgx.Identifier{dbName, viewName}.Sanitize(),
+		strings.Join(columns, ", "),
+		pgx.Identifier{dbName, tableName}.Sanitize(),
+	)
+}
+
+// viewInit infers the relational view schema of the collection from a sample of documents
+// and (re)creates the view.
+//
+// It does not hold the lock.
+func (r *Registry) viewInit(ctx context.Context, p *pgxpool.Pool, dbName string, c *Collection) error {
+	defer observability.FuncCall(ctx)()
+
+	q := fmt.Sprintf(
+		`SELECT DISTINCT f.key, f.value->>'t' FROM (SELECT %[1]s FROM %[2]s LIMIT %[3]d) AS s, `+
+			`jsonb_each(s.%[1]s->'$s'->'p') AS f`,
+		DefaultColumn,
+		pgx.Identifier{dbName, c.TableName}.Sanitize(),
+		viewSampleSize,
+	)
+
+	rows, err := p.Query(ctx, q)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+	defer rows.Close()
+
+	s := viewSchema{}
+
+	for rows.Next() {
+		var field, typ string
+		if err = rows.Scan(&field, &typ); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		s.add(field, typ)
+	}
+
+	if err = rows.Err(); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return r.viewCreate(ctx, p, dbName, c, s)
+}
+
+// viewCreate (re)creates the relational view of the collection with the given schema
+// and remembers that schema.
+//
+// Views are not created for empty schemas and collection names that are too long for PostgreSQL identifiers.
+//
+// It does not hold the lock.
+func (r *Registry) viewCreate(ctx context.Context, p *pgxpool.Pool, dbName string, c *Collection, s viewSchema) error {
+	defer observability.FuncCall(ctx)()
+
+	if r.views[dbName] == nil {
+		r.views[dbName] = map[string]viewSchema{}
+	}
+
+	r.views[dbName][c.Name] = s
+
+	if len(s) == 0 || len(c.Name) > maxTableNameLength {
+		return nil
+	}
+
+	return r.InTransaction(ctx, p, func(tx pgx.Tx) error {
+		q := fmt.Sprintf(`DROP VIEW IF EXISTS %s`, pgx.Identifier{dbName, c.Name}.Sanitize())
+		if _, err := tx.Exec(ctx, q); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if _, err := tx.Exec(ctx, viewQuery(dbName, c.Name, c.TableName, s)); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return nil
+	})
+}
+
+// viewDrop drops the relational view of the collection, if any.
+//
+// It does not hold the lock.
+func (r *Registry) viewDrop(ctx context.Context, p *pgxpool.Pool, dbName, collectionName string) error {
+	defer observability.FuncCall(ctx)()
+
+	delete(r.views[dbName], collectionName)
+
+	if len(collectionName) > maxTableNameLength {
+		return nil
+	}
+
+	q := fmt.Sprintf(`DROP VIEW IF EXISTS %s`, pgx.Identifier{dbName, collectionName}.Sanitize())
+	if _, err := p.Exec(ctx, q); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// ViewsUpdate updates the relational view of the collection
+// if the given written documents contain fields or types that are not present in the view.
+//
+// It does nothing if relational views are disabled.
+// Errors are logged, not returned, as views are not essential for FerretDB itself.
+func (r *Registry) ViewsUpdate(ctx context.Context, dbName, collectionName string, docs []*types.Document) {
+	defer observability.FuncCall(ctx)()
+
+	if r.views == nil {
+		return
+	}
+
+	p, err := r.getPool(ctx)
+	if err != nil {
+		return
+	}
+
+	c, err := r.CollectionGet(ctx, dbName, collectionName)
+	if err != nil || c == nil {
+		return
+	}
+
+	r.viewsM.Lock()
+	defer r.viewsM.Unlock()
+
+	s, ok := r.views[dbName][collectionName]
+	if !ok {
+		if err = r.viewInit(ctx, p, dbName, c); err != nil {
+			r.l.Warn("Failed to create relational view", zap.String("db", dbName), zap.String("collection", c.Name), zap.Error(err))
+		}
+
+		return
+	}
+
+	var changed bool
+
+	for _, doc := range docs {
+		for _, field := range doc.Keys() {
+			v, _ := doc.Get(field)
+			if s.add(field, sjson.GetTypeOfValue(v)) {
+				changed = true
+			}
+		}
+	}
+
+	if !changed {
+		return
+	}
+
+	if err = r.viewCreate(ctx, p, dbName, c, s); err != nil {
+		r.l.Warn("Failed to update relational view", zap.String("db", dbName), zap.String("collection", c.Name), zap.Error(err))
+	}
+}
@@ -15,8 +15,10 @@
 package postgresql
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -31,39 +33,225 @@ import (
 	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
+// textScoreColumn is the name of the column with text scores of $text queries.
+const textScoreColumn = "_ferretdb_text_score"
+
 // prepareSelectClause returns SELECT clause for default column of provided schema and table name.
 //
 // For capped collection with onlyRecordIDs, it returns select clause for recordID column.
 //
 // For capped collection, it returns select clause for recordID column and default column.
-func prepareSelectClause(schema, table string, capped, onlyRecordIDs bool) string {
-	if capped && onlyRecordIDs {
-		return fmt.Sprintf(
-			`SELECT %s FROM %s`,
-			metadata.RecordIDColumn,
-			pgx.Identifier{schema, table}.Sanitize(),
-		)
+//
+// If document expression is not empty, it is selected instead of the default column.
+//
+// If textScore expression is not empty, its value is selected as the last textScoreColumn column.
+func prepareSelectClause(schema, table string, capped, onlyRecordIDs bool, document string, textScore ...string) string {
+	if document == "" {
+		document = metadata.DefaultColumn
 	}
 
-	if capped {
-		return fmt.Sprintf(
-			`SELECT %s, %s FROM %s`,
-			metadata.RecordIDColumn,
-			metadata.DefaultColumn,
-			pgx.Identifier{schema, table}.Sanitize(),
-		)
+	var columns string
+
+	switch {
+	case capped && onlyRecordIDs:
+		columns = metadata.RecordIDColumn
+	case capped:
+		columns = metadata.RecordIDColumn + ", " + document
+	default:
+		// TODO https://github.com/FerretDB/FerretDB/issues/3573
+		columns = document
 	}
 
-	// TODO https://github.com/FerretDB/FerretDB/issues/3573
-	return fmt.Sprintf(
-		`SELECT %s FROM %s`,
-		metadata.DefaultColumn,
-		pgx.Identifier{schema, table}.Sanitize(),
+	if len(textScore) > 0 && textScore[0] != "" {
+		columns += fmt.Sprintf(", %s AS %s", textScore[0], textScoreColumn)
+	}
+
+	return fmt.Sprintf(`SELECT %s FROM %s`, columns, pgx.Identifier{schema, table}.Sanitize())
+}
+
+// prepareDistinctOnClause returns DISTINCT ON clause that keeps one row for each value of the given top-level field,
+// and arguments for it.
+//
+// The field's schema is a part of the clause, so values of different types
+// with the same JSON representation (like dates and integers) are kept.
+func prepareDistinctOnClause(p *metadata.Placeholder, key string) (string, []any) {
+	return fmt.Sprintf(`DISTINCT ON (%[1]s->%[2]s, %[1]s->'$s'->'p'->%[2]s) `, metadata.DefaultColumn, p.Next()), []any{key}
+}
+
+// unwindOrdinality is the column with 1-based index of the array element in the unwinding join.
+const unwindOrdinality = "_unwind.n"
+
+// prepareUnwind returns document expression, lateral join, and condition that return a row
+// for each element of the given top-level field's array value, and arguments for them.
+//
+// Rows with other values are returned as is; rows with missing, null, or empty array values are skipped,
+// like $unwind stage without options does.
+func prepareUnwind(p *metadata.Placeholder, field string) (document, join, cond string, args []any) {
+	col := metadata.DefaultColumn
+	ph := p.Next()
+
+	// arrays' schemas contain schemas of elements in the `i` field
+	isArray := fmt.Sprintf(`%[1]s->'$s'->'p'->%[2]s::text->>'t' = 'array'`, col, ph)
+
+	document = fmt.Sprintf(
+		`CASE WHEN %[3]s THEN jsonb_set(jsonb_set(%[1]s, ARRAY[%[2]s::text], _unwind.value), `+
+			`ARRAY['$s', 'p', %[2]s::text], %[1]s->'$s'->'p'->%[2]s::text->'i'->(%[4]s::int - 1)) `+
+			`ELSE %[1]s END AS %[1]s`,
+		col, ph, isArray, unwindOrdinality,
+	)
+
+	join = fmt.Sprintf(
+		` CROSS JOIN LATERAL jsonb_array_elements(CASE WHEN %[3]s THEN %[1]s->%[2]s::text `+
+			`ELSE jsonb_build_array(%[1]s->%[2]s::text) END) WITH ORDINALITY AS _unwind(value, n)`,
+		col, ph, isArray,
+	)
+
+	cond = fmt.Sprintf(`%[1]s->'$s'->'p'->%[2]s::text->>'t' <> 'null'`, col, ph)
+
+	args = []any{field}
+
+	return
+}
+
+// prepareProjection returns an expression that selects only given top-level fields
+// of the default column together with their schema, and arguments for it.
+//
+// Field order is preserved, and the `_id` field is not added automatically.
+func prepareProjection(p *metadata.Placeholder, fields []string) (string, []any) {
+	col := metadata.DefaultColumn
+	ph := p.Next()
+
+	expr := fmt.Sprintf(
+		`(COALESCE((SELECT jsonb_object_agg(f.key, f.value) FROM jsonb_each(%[1]s) AS f WHERE f.key = ANY(%[2]s)), '{}') || `+
+			`jsonb_build_object('$s', jsonb_build_object(`+
+			`'$k', COALESCE((SELECT jsonb_agg(k.key ORDER BY k.n) FROM jsonb_array_elements_text(%[1]s->'$s'->'$k') `+
+			`WITH ORDINALITY AS k(key, n) WHERE k.key = ANY(%[2]s)), '[]'), `+
+			`'p', COALESCE((SELECT jsonb_object_agg(s.key, s.value) FROM jsonb_each(%[1]s->'$s'->'p') AS s `+
+			`WHERE s.key = ANY(%[2]s)), '{}')))) AS %[1]s`,
+		col, ph,
+	)
+
+	return expr, []any{fields}
+}
+
+// prepareCountClause returns SELECT COUNT(*) clause for provided schema and table name.
+//
+// PostgreSQL could use an index-only scan for it if there are no filters.
+func prepareCountClause(schema, table string) string {
+	return fmt.Sprintf(`SELECT COUNT(*) FROM %s`, pgx.Identifier{schema, table}.Sanitize())
+}
+
+// prepareGroupCountClause returns SELECT clause that returns values of the given top-level field,
+// their schemas, and counts of documents for provided schema and table name, and arguments for it.
+//
+// Rows should be grouped by the first two columns.
+func prepareGroupCountClause(p *metadata.Placeholder, schema, table, field string) (string, []any) {
+	q := fmt.Sprintf(
+		`SELECT %[1]s->%[2]s, %[1]s->'$s'->'p'->%[2]s, COUNT(*) FROM %[3]s`,
+		metadata.DefaultColumn, p.Next(), pgx.Identifier{schema, table}.Sanitize(),
 	)
+
+	return q, []any{field}
+}
+
+// prepareTextSearch returns SQL condition and text score expression with arguments for the given text search
+// on the given text index.
+//
+// Documents should match all phrases, or any term if there are no phrases;
+// documents matching negations are excluded.
+// It returns empty condition if there are neither terms nor phrases, as nothing could match.
+func prepareTextSearch(p *metadata.Placeholder, ts *backends.TextSearch, index *metadata.IndexInfo) (string, string, []any) {
+	if len(ts.Terms) == 0 && len(ts.Phrases) == 0 {
+		return "", "", nil
+	}
+
+	language := ts.Language
+	if language == "" {
+		language = index.DefaultLanguage
+	}
+
+	config := p.Next() + "::regconfig"
+	args := []any{metadata.TextSearchConfig(language)}
+
+	var terms, phrases, negations []string
+
+	for _, term := range ts.Terms {
+		terms = append(terms, fmt.Sprintf("plainto_tsquery(%s, %s)", config, p.Next()))
+		args = append(args, term)
+	}
+
+	for _, phrase := range ts.Phrases {
+		phrases = append(phrases, fmt.Sprintf("phraseto_tsquery(%s, %s)", config, p.Next()))
+		args = append(args, phrase)
+	}
+
+	for _, negation := range ts.Negations {
+		negations = append(negations, fmt.Sprintf("!! plainto_tsquery(%s, %s)", config, p.Next()))
+		args = append(args, negation)
+	}
+
+	// if there are phrases, terms affect only the score
+	cond := phrases
+	if len(cond) == 0 {
+		cond = []string{"(" + strings.Join(terms, " || ") + ")"}
+	}
+
+	cond = append(cond, negations...)
+
+	vector := metadata.TextSearchVector(*index)
+	query := "(" + strings.Join(cond, " && ") + ")"
+	rankQuery := "(" + strings.Join(append(terms, phrases...), " || ") + ")"
+
+	return fmt.Sprintf("%s @@ %s", vector, query), fmt.Sprintf("ts_rank(%s, %s)::float8", vector, rankQuery), args
+}
+
+// prepareGeoQuery returns SQL condition and arguments for the given geospatial query.
+//
+// For $near, it also returns SQL expression for the distance that should be used for ordering.
+// It uses [metadata.GeographyExpression], so 2dsphere indexes are used.
+func prepareGeoQuery(p *metadata.Placeholder, g *backends.GeoQuery) (string, string, []any) {
+	field := metadata.GeographyExpression(g.Field)
+	geometry := fmt.Sprintf("ST_GeomFromGeoJSON(%s::text)::geography", p.Next())
+	args := []any{g.Geometry}
+
+	var conds []string
+	var distance string
+
+	switch g.Operator {
+	case backends.GeoWithin:
+		// $centerSphere is handled by MaxDistance below
+		if g.MaxDistance == nil {
+			conds = append(conds, fmt.Sprintf("ST_CoveredBy(%s, %s)", field, geometry))
+		}
+
+	case backends.GeoIntersects:
+		conds = append(conds, fmt.Sprintf("ST_Intersects(%s, %s)", field, geometry))
+
+	case backends.GeoNear:
+		conds = append(conds, fmt.Sprintf("(%s) IS NOT NULL", field))
+		distance = fmt.Sprintf("((%s) <-> %s)", field, geometry)
+
+	default:
+		panic(fmt.Sprintf("unexpected geo operator %q", g.Operator))
+	}
+
+	if g.MaxDistance != nil {
+		conds = append(conds, fmt.Sprintf("ST_DWithin(%s, %s, %s::float8)", field, geometry, p.Next()))
+		args = append(args, *g.MaxDistance)
+	}
+
+	if g.MinDistance != nil {
+		conds = append(conds, fmt.Sprintf("ST_Distance(%s, %s) >= %s::float8", field, geometry, p.Next()))
+		args = append(args, *g.MinDistance)
+	}
+
+	return strings.Join(conds, " AND "), distance, args
 }
 
 // prepareWhereClause adds WHERE clause with given filters to the query and returns the query and arguments.
-func prepareWhereClause(p *metadata.Placeholder, sqlFilters *types.Document) (string, []any, error) {
+//
+// Indexes are used to push down regex filters (see [prepareRegexFilters]); they may be nil.
+func prepareWhereClause(p *metadata.Placeholder, sqlFilters *types.Document, indexes metadata.Indexes) (string, []any, error) {
 	var filters []string
 	var args []any
 
@@ -177,8 +365,38 @@ func prepareWhereClause(p *metadata.Placeholder, sqlFilters *types.Document) (st
 						panic(fmt.Sprintf("Unexpected type of value: %v", v))
 					}
 
+				case "$exists":
+					if f, a := filterExists(p, rootKey, v); f != "" {
+						filters = append(filters, f)
+						args = append(args, a...)
+					}
+
+				case "$size":
+					if f, a := filterSize(p, rootKey, v); f != "" {
+						filters = append(filters, f)
+						args = append(args, a...)
+					}
+
+				case "$all":
+					if f, a := filterAll(p, rootKey, v); f != "" {
+						filters = append(filters, f)
+						args = append(args, a...)
+					}
+
+				case "$in":
+					if f, a := filterIn(p, rootKey, v); f != "" {
+						filters = append(filters, f)
+						args = append(args, a...)
+					}
+
+				case "$lt":
+					if f, a := filterLess(p, rootKey, v); f != "" {
+						filters = append(filters, f)
+						args = append(args, a...)
+					}
+
 				default:
-					// $gt and $lt
+					// $gt and $lt for other types
 					// TODO https://github.com/FerretDB/FerretDB/issues/1875
 					continue
 				}
@@ -198,6 +416,10 @@ func prepareWhereClause(p *metadata.Placeholder, sqlFilters *types.Document) (st
 		}
 	}
 
+	regexFilters, regexArgs := prepareRegexFilters(p, sqlFilters, indexes)
+	filters = append(filters, regexFilters...)
+	args = append(args, regexArgs...)
+
 	var filter string
 	if len(filters) > 0 {
 		filter = ` WHERE ` + strings.Join(filters, " AND ")
@@ -206,18 +428,198 @@ func prepareWhereClause(p *metadata.Placeholder, sqlFilters *types.Document) (st
 	return filter, args, nil
 }
 
+// prepareCountWhereClause returns WHERE clause with arguments that selects exactly
+// the documents matching the given filter, and true.
+//
+// Unlike [prepareWhereClause], it returns false if some part of the filter could not be
+// translated exactly, as documents are counted by PostgreSQL and not filtered again by handlers.
+// Only equality, boolean $exists, and $size conditions on top-level fields are supported.
+// Equality conditions use the same expressions as regular indexes,
+// so COUNT(*) queries could be satisfied with index scans without fetching documents.
+func prepareCountWhereClause(p *metadata.Placeholder, sqlFilters *types.Document) (string, []any, bool) {
+	var filters []string
+	var args []any
+
+	if sqlFilters == nil {
+		return "", nil, true
+	}
+
+	for _, rootKey := range sqlFilters.Keys() {
+		rootVal := must.NotFail(sqlFilters.Get(rootKey))
+
+		if rootKey == "$comment" {
+			continue
+		}
+
+		if rootKey == "" || strings.HasPrefix(rootKey, "$") || strings.Contains(rootKey, ".") {
+			return "", nil, false
+		}
+
+		cond, ok := rootVal.(*types.Document)
+		if !ok {
+			cond = must.NotFail(types.NewDocument("$eq", rootVal))
+		}
+
+		if cond.Len() == 0 {
+			return "", nil, false
+		}
+
+		for _, k := range cond.Keys() {
+			v := must.NotFail(cond.Get(k))
+
+			var f string
+			var a []any
+
+			switch k {
+			case "$eq":
+				f, a = filterCountEqual(p, rootKey, v)
+			case "$exists":
+				f, a = filterExists(p, rootKey, v)
+			case "$size":
+				f, a = filterSize(p, rootKey, v)
+			}
+
+			if f == "" {
+				return "", nil, false
+			}
+
+			filters = append(filters, f)
+			args = append(args, a...)
+		}
+	}
+
+	var filter string
+	if len(filters) > 0 {
+		filter = ` WHERE ` + strings.Join(filters, " AND ")
+	}
+
+	return filter, args, true
+}
+
+// prepareRegexFilters returns SQL filters with arguments for regex filters on top-level fields
+// that have trigram indexes.
+//
+// Only regular expressions that are plain substrings (see [regexLikePattern]) are pushed down
+// as LIKE or ILIKE conditions that PostgreSQL can execute using those indexes.
+// Returned filters match a superset of documents; handlers filter them again.
+func prepareRegexFilters(p *metadata.Placeholder, sqlFilters *types.Document, indexes metadata.Indexes) ([]string, []any) {
+	var filters []string
+	var args []any
+
+	for _, k := range sqlFilters.Keys() {
+		if strings.HasPrefix(k, "$") || !indexes.TrigramIndexed(k) {
+			continue
+		}
+
+		var re types.Regex
+
+		switch v := must.NotFail(sqlFilters.Get(k)).(type) {
+		case types.Regex:
+			re = v
+
+		case *types.Document:
+			switch pattern, _ := v.Get("$regex"); pattern := pattern.(type) {
+			case types.Regex:
+				re = pattern
+			case string:
+				re.Pattern = pattern
+			default:
+				continue
+			}
+
+			if options, _ := v.Get("$options"); options != nil {
+				o, ok := options.(string)
+				if !ok {
+					continue
+				}
+
+				re.Options += o
+			}
+
+		default:
+			continue
+		}
+
+		like, op := regexLikePattern(re)
+		if like == "" {
+			continue
+		}
+
+		filters = append(filters, fmt.Sprintf(`%s %s %s`, metadata.TrigramExpression(k), op, p.Next()))
+		args = append(args, like)
+	}
+
+	return filters, args
+}
+
+// regexLikePattern returns LIKE pattern and operator (LIKE or ILIKE) for the given regular expression
+// if it matches the same strings as a plain substring search, or empty string otherwise.
+//
+// The pattern should be at least three characters long (trigram indexes are not used otherwise),
+// and should contain only characters that are neither regex, LIKE, nor JSON special characters,
+// so the result is correct for both strings and arrays of strings (that are matched as JSON text).
+func regexLikePattern(re types.Regex) (string, string) {
+	op := "LIKE"
+
+	for _, o := range re.Options {
+		switch o {
+		case 'i':
+			op = "ILIKE"
+		case 'm', 's':
+			// they do not affect patterns without ^, $ and .
+		default:
+			return "", ""
+		}
+	}
+
+	if len(re.Pattern) < 3 {
+		return "", ""
+	}
+
+	for _, c := range re.Pattern {
+		switch {
+		case c >= '0' && c <= '9', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+			// Go's case folding matches k and s with non-ASCII characters (K, ſ) that ILIKE may not
+			if op == "ILIKE" && strings.ContainsRune("kKsS", c) {
+				return "", ""
+			}
+		case strings.ContainsRune(" -_,:@/", c):
+			// not special
+		default:
+			return "", ""
+		}
+	}
+
+	return "%" + strings.ReplaceAll(re.Pattern, "_", `\_`) + "%", op
+}
+
 // prepareOrderByClause returns ORDER BY clause for given sort field and returns the query and arguments.
 //
-// For capped collection, it returns ORDER BY recordID only if sort field is nil.
-func prepareOrderByClause(p *metadata.Placeholder, sort *backends.SortField, capped bool) (string, []any) {
+// orderColumn is the column that defines the insertion order of documents (see [metadata.Collection.OrderColumn]).
+// If sort field is nil, documents are returned in that order.
+// Natural sort uses that order too, or the physical order if there is no such column.
+func prepareOrderByClause(p *metadata.Placeholder, sort *backends.SortField, orderColumn string) (string, []any) {
 	if sort == nil {
-		if capped {
-			return fmt.Sprintf(" ORDER BY %s", metadata.RecordIDColumn), nil
+		if orderColumn != "" {
+			return fmt.Sprintf(" ORDER BY %s", orderColumn), nil
 		}
 
 		return "", nil
 	}
 
+	if sort.Key == backends.NaturalSortKey {
+		column := orderColumn
+		if column == "" {
+			column = "ctid"
+		}
+
+		if sort.Descending {
+			return fmt.Sprintf(" ORDER BY %s DESC", column), nil
+		}
+
+		return fmt.Sprintf(" ORDER BY %s", column), nil
+	}
+
 	// Skip sorting dot notation
 	if strings.ContainsRune(sort.Key, '.') {
 		return "", nil
@@ -302,3 +704,218 @@ func filterEqual(p *metadata.Placeholder, k string, v any) (filter string, args
 
 	return
 }
+
+// filterCountEqual returns SQL filter with arguments that selects exactly the documents
+// where the value under k, or one of its array elements, is equal to v.
+//
+// Unlike [filterEqual], value types are checked using the schema,
+// so dates do not match numbers, and object IDs do not match strings.
+// Filter is returned only for scalar values that are stored in JSON as is;
+// numbers should be within the safe range.
+func filterCountEqual(p *metadata.Placeholder, k string, v any) (filter string, args []any) {
+	var sjsonTypes string
+
+	switch v := v.(type) {
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) || math.Abs(v) > types.MaxSafeDouble {
+			return
+		}
+
+		sjsonTypes = `'int', 'long', 'double'`
+
+	case int64:
+		if v > int64(types.MaxSafeDouble) || v < -int64(types.MaxSafeDouble) {
+			return
+		}
+
+		sjsonTypes = `'int', 'long', 'double'`
+
+	case int32:
+		sjsonTypes = `'int', 'long', 'double'`
+
+	case string, types.ObjectID, bool, time.Time:
+		sjsonTypes = `'` + sjson.GetTypeOfValue(v) + `'`
+
+	default:
+		return
+	}
+
+	key, value := p.Next(), p.Next()
+
+	// the value itself
+	filter = fmt.Sprintf(
+		`(%[1]s->%[2]s = %[3]s AND %[1]s->'$s'->'p'->%[2]s->>'t' IN (%[4]s))`,
+		metadata.DefaultColumn, key, value, sjsonTypes,
+	)
+
+	// or one of array elements; _id can't be an array
+	if k != "_id" {
+		filter = `(` + filter + fmt.Sprintf(
+			` OR CASE WHEN jsonb_typeof(%[1]s->%[2]s) = 'array' THEN EXISTS (`+
+				`SELECT 1 FROM jsonb_array_elements(%[1]s->%[2]s) WITH ORDINALITY AS e(v, n) `+
+				`WHERE e.v = %[3]s AND %[1]s->'$s'->'p'->%[2]s->'i'->(e.n::int - 1)->>'t' IN (%[4]s)`+
+				`) ELSE false END)`,
+			metadata.DefaultColumn, key, value, sjsonTypes,
+		)
+	}
+
+	args = append(args, k, string(must.NotFail(sjson.MarshalSingleValue(v))))
+
+	return
+}
+
+// filterExists returns SQL filter with arguments that filters documents
+// where the key k exists (with any value including null) or does not exist.
+//
+// Filter is returned only for boolean v; handlers convert other values.
+func filterExists(p *metadata.Placeholder, k string, v any) (filter string, args []any) {
+	exists, ok := v.(bool)
+	if !ok {
+		return
+	}
+
+	filter = fmt.Sprintf(`%s ? %s`, metadata.DefaultColumn, p.Next())
+	if !exists {
+		filter = `NOT (` + filter + `)`
+	}
+
+	args = append(args, k)
+
+	return
+}
+
+// filterSize returns SQL filter with arguments that filters documents
+// where the value under k is an array of length v.
+//
+// Filter is returned only for valid non-negative whole numbers;
+// handlers return errors for other values.
+func filterSize(p *metadata.Placeholder, k string, v any) (filter string, args []any) {
+	var size int64
+
+	switch v := v.(type) {
+	case int32:
+		size = int64(v)
+	case int64:
+		size = v
+	case float64:
+		if v != math.Trunc(v) || v < 0 || v > types.MaxSafeDouble {
+			return
+		}
+
+		size = int64(v)
+	default:
+		return
+	}
+
+	if size < 0 {
+		return
+	}
+
+	filter = fmt.Sprintf(`%s = %s`, metadata.ArrayLengthExpression(k), p.Next())
+	args = append(args, size)
+
+	return
+}
+
+// filterAll returns SQL filter with arguments that filters documents
+// where the value under k contains all values of the given $all operand.
+//
+// It uses jsonb containment, so arrays with all values and scalars equal to all values are selected.
+// Filter is returned only if the operand is a non-empty array of scalars; otherwise, it is empty.
+func filterAll(p *metadata.Placeholder, k string, v any) (filter string, args []any) {
+	arr, ok := v.(*types.Array)
+	if !ok || arr.Len() == 0 {
+		return
+	}
+
+	for i := 0; i < arr.Len(); i++ {
+		switch must.NotFail(arr.Get(i)).(type) {
+		case float64, string, types.ObjectID, bool, time.Time, int32, int64:
+		default:
+			// type not supported for pushdown
+			return
+		}
+	}
+
+	filters := make([]string, 0, arr.Len())
+
+	for i := 0; i < arr.Len(); i++ {
+		f, a := filterEqual(p, k, must.NotFail(arr.Get(i)))
+		filters = append(filters, f)
+		args = append(args, a...)
+	}
+
+	filter = "(" + strings.Join(filters, " AND ") + ")"
+
+	return
+}
+
+// maxInPushdownValues is the maximal number of $in operand values that are pushed down.
+const maxInPushdownValues = 1000
+
+// filterIn returns SQL filter with arguments that filters documents
+// where the value under k is equal to one of values of the given $in operand.
+//
+// It combines [filterEqual] filters, so it selects more documents than needed for some values.
+// Filter is returned only if the operand is a non-empty array of scalars of supported types
+// with at most maxInPushdownValues values; otherwise, it is empty.
+func filterIn(p *metadata.Placeholder, k string, v any) (filter string, args []any) {
+	arr, ok := v.(*types.Array)
+	if !ok || arr.Len() == 0 || arr.Len() > maxInPushdownValues {
+		return
+	}
+
+	for i := 0; i < arr.Len(); i++ {
+		switch must.NotFail(arr.Get(i)).(type) {
+		case float64, string, types.ObjectID, bool, time.Time, int32, int64:
+		default:
+			// type not supported for pushdown
+			return
+		}
+	}
+
+	filters := make([]string, 0, arr.Len())
+
+	for i := 0; i < arr.Len(); i++ {
+		f, a := filterEqual(p, k, must.NotFail(arr.Get(i)))
+		filters = append(filters, f)
+		args = append(args, a...)
+	}
+
+	filter = "(" + strings.Join(filters, " OR ") + ")"
+
+	return
+}
+
+// filterLess returns SQL filter with arguments that filters out documents
+// where the value under k is a scalar of the same type as v that is not less than v.
+//
+// Documents with values of other types (including arrays) are selected; handlers filter them.
+// Filter is returned only for dates and ObjectIDs; that is enough for archiving policies.
+func filterLess(p *metadata.Placeholder, k string, v any) (filter string, args []any) {
+	var expr string
+
+	switch v := v.(type) {
+	case time.Time:
+		expr = `(%[1]s->>%[2]s)::bigint < %[3]s`
+		args = append(args, k, v.UnixMilli())
+
+	case types.ObjectID:
+		// ObjectIDs are stored as lowercase hex strings, so byte-wise comparison works
+		expr = `(%[1]s->>%[2]s) COLLATE "C" < %[3]s`
+		args = append(args, k, hex.EncodeToString(v[:]))
+
+	default:
+		return
+	}
+
+	key, value := p.Next(), p.Next()
+
+	// CASE guarantees that the cast is evaluated only for values of the right type
+	filter = fmt.Sprintf(
+		`CASE WHEN %[1]s->'$s'->'p'->%[2]s->>'t' = '%[4]s' THEN `+expr+` ELSE true END`,
+		metadata.DefaultColumn, key, value, sjson.GetTypeOfValue(v),
+	)
+
+	return
+}
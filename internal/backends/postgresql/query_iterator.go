@@ -39,6 +39,9 @@ type queryIterator struct {
 	ctx           context.Context
 	rows          pgx.Rows // protected by m
 	onlyRecordIDs bool
+	raw           bool
+	closeF        func()                      // called after rows are closed; may be nil
+	textScores    map[*types.Document]float64 // protected by m; set only for text search queries
 	token         *resource.Token
 	m             sync.Mutex
 }
@@ -64,6 +67,42 @@ func newQueryIterator(ctx context.Context, rows pgx.Rows, onlyRecordIDs bool) ty
 	return iter
 }
 
+// newTextSearchQueryIterator returns a new queryIterator for the given rows of the $text query.
+//
+// Each row should have the last textScoreColumn column; its values are added to the given map
+// with returned documents as keys.
+// Closing rules are the same as for newQueryIterator.
+func newTextSearchQueryIterator(ctx context.Context, rows pgx.Rows, onlyRecordIDs bool, textScores map[*types.Document]float64) types.DocumentsIterator { //nolint:lll // for readability
+	iter := &queryIterator{
+		ctx:           ctx,
+		rows:          rows,
+		onlyRecordIDs: onlyRecordIDs,
+		textScores:    textScores,
+		token:         resource.NewToken(),
+	}
+	resource.Track(iter, iter.token)
+
+	return iter
+}
+
+// newRawQueryIterator returns a new queryIterator for the given rows of the raw SQL query.
+//
+// Each row should have a single column with a JSON object.
+// Closing rules are the same as for newQueryIterator;
+// non-nil closeF is called once after rows are closed.
+func newRawQueryIterator(ctx context.Context, rows pgx.Rows, closeF func()) types.DocumentsIterator {
+	iter := &queryIterator{
+		ctx:    ctx,
+		rows:   rows,
+		raw:    true,
+		closeF: closeF,
+		token:  resource.NewToken(),
+	}
+	resource.Track(iter, iter.token)
+
+	return iter
+}
+
 // Next implements iterator.Interface.
 func (iter *queryIterator) Next() (struct{}, *types.Document, error) {
 	defer observability.FuncCall(iter.ctx)()
@@ -101,10 +140,27 @@ func (iter *queryIterator) Next() (struct{}, *types.Document, error) {
 	}
 
 	var recordID types.Timestamp
+	var textScore float64
 	var b []byte
 	var dest []any
 
+	hasTextScore := iter.textScores != nil
+	if hasTextScore {
+		if columns[len(columns)-1] != textScoreColumn {
+			panic(fmt.Sprintf("cannot scan text scores from columns: %v", columns))
+		}
+
+		columns = columns[:len(columns)-1]
+	}
+
 	switch {
+	case iter.raw:
+		if len(columns) != 1 {
+			iter.close()
+			return unused, nil, lazyerrors.Errorf("raw query should return a single column, got %d", len(columns))
+		}
+
+		dest = []any{&b}
 	case slices.Equal(columns, []string{metadata.RecordIDColumn, metadata.DefaultColumn}):
 		dest = []any{&recordID, &b}
 	case slices.Equal(columns, []string{metadata.RecordIDColumn}):
@@ -115,11 +171,25 @@ func (iter *queryIterator) Next() (struct{}, *types.Document, error) {
 		panic(fmt.Sprintf("cannot scan unknown columns: %v", columns))
 	}
 
+	if hasTextScore {
+		dest = append(dest, &textScore)
+	}
+
 	if err := iter.rows.Scan(dest...); err != nil {
 		iter.close()
 		return unused, nil, lazyerrors.Error(err)
 	}
 
+	if iter.raw {
+		doc, err := sjson.UnmarshalRelaxed(b)
+		if err != nil {
+			iter.close()
+			return unused, nil, lazyerrors.Error(err)
+		}
+
+		return unused, doc, nil
+	}
+
 	var err error
 	doc := must.NotFail(types.NewDocument())
 
@@ -132,6 +202,10 @@ func (iter *queryIterator) Next() (struct{}, *types.Document, error) {
 
 	doc.SetRecordID(recordID)
 
+	if hasTextScore {
+		iter.textScores[doc] = textScore
+	}
+
 	return unused, doc, nil
 }
 
@@ -156,6 +230,11 @@ func (iter *queryIterator) close() {
 		iter.rows = nil
 	}
 
+	if iter.closeF != nil {
+		iter.closeF()
+		iter.closeF = nil
+	}
+
 	resource.Untrack(iter, iter.token)
 }
 
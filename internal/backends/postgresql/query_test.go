@@ -19,6 +19,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/AlekSi/pointer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -230,6 +231,102 @@ func TestPrepareWhereClause(t *testing.T) {
 			expected: whereNotEq + `'"objectId"' )`,
 		},
 
+		"Exists": {
+			filter: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$exists", true)),
+			)),
+			args:     []any{`v`},
+			expected: ` WHERE _jsonb ? $1`,
+		},
+		"NotExists": {
+			filter: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$exists", false)),
+			)),
+			args:     []any{`v`},
+			expected: ` WHERE NOT (_jsonb ? $1)`,
+		},
+		"ExistsNotBool": {
+			filter: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$exists", int32(1))),
+			)),
+		},
+		"Size": {
+			filter: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$size", 2.0)),
+			)),
+			args:     []any{int64(2)},
+			expected: ` WHERE (CASE WHEN jsonb_typeof(_jsonb->'v') = 'array' THEN jsonb_array_length(_jsonb->'v') END) = $1`,
+		},
+		"SizeNegative": {
+			filter: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$size", int32(-1))),
+			)),
+		},
+		"SizeNotWhole": {
+			filter: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$size", 1.5)),
+			)),
+		},
+		"AllScalars": {
+			filter: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$all", must.NotFail(types.NewArray("foo", int32(42))))),
+			)),
+			args:     []any{`v`, `"foo"`, `v`, int32(42)},
+			expected: " WHERE (_jsonb->$1 @> $2 AND _jsonb->$3 @> $4)",
+		},
+		"AllDocument": {
+			filter: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$all", must.NotFail(types.NewArray(
+					"foo", must.NotFail(types.NewDocument("$elemMatch", must.NotFail(types.NewDocument("$gt", int32(1))))),
+				)))),
+			)),
+		},
+		"AllEmpty": {
+			filter: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$all", must.NotFail(types.NewArray()))),
+			)),
+		},
+
+		"InScalars": {
+			filter: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$in", must.NotFail(types.NewArray("foo", int32(42))))),
+			)),
+			args:     []any{`v`, `"foo"`, `v`, int32(42)},
+			expected: ` WHERE (_jsonb->$1 @> $2 OR _jsonb->$3 @> $4)`,
+		},
+		"InNull": {
+			filter: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$in", must.NotFail(types.NewArray("foo", types.Null)))),
+			)),
+		},
+		"InEmpty": {
+			filter: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$in", must.NotFail(types.NewArray()))),
+			)),
+		},
+
+		"LtDatetime": {
+			filter: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$lt", time.Date(2021, 11, 1, 10, 18, 42, 123000000, time.UTC))),
+			)),
+			args: []any{`v`, int64(1635761922123)},
+			expected: ` WHERE CASE WHEN _jsonb->'$s'->'p'->$1->>'t' = 'date' ` +
+				`THEN (_jsonb->>$1)::bigint < $2 ELSE true END`,
+		},
+		"LtObjectID": {
+			filter: must.NotFail(types.NewDocument(
+				"_id", must.NotFail(types.NewDocument("$lt", objectID)),
+			)),
+			args: []any{`_id`, `6256c5ba0badc0ffeeffffff`},
+			expected: ` WHERE CASE WHEN _jsonb->'$s'->'p'->$1->>'t' = 'objectId' ` +
+				`THEN (_jsonb->>$1) COLLATE "C" < $2 ELSE true END`,
+		},
+		"LtString": {
+			filter: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$lt", "foo")),
+			)),
+		},
+
 		"Comment": {
 			filter: must.NotFail(types.NewDocument("$comment", "I'm comment")),
 		},
@@ -242,7 +339,7 @@ func TestPrepareWhereClause(t *testing.T) {
 				t.Skip(tc.skip)
 			}
 
-			actual, args, err := prepareWhereClause(new(metadata.Placeholder), tc.filter)
+			actual, args, err := prepareWhereClause(new(metadata.Placeholder), tc.filter, nil)
 			require.NoError(t, err)
 
 			assert.Equal(t, tc.expected, actual)
@@ -256,12 +353,374 @@ func TestPrepareWhereClause(t *testing.T) {
 	}
 }
 
+func TestPrepareRegexFilters(t *testing.T) {
+	t.Parallel()
+
+	indexes := metadata.Indexes{{
+		Name:      "v_1",
+		PgIndex:   "test_v_1_00000000_idx",
+		TrgmIndex: "test_v_1_00000000_trgm",
+		Key:       []metadata.IndexKeyPair{{Field: "v"}},
+	}, {
+		Name:    "w_1",
+		PgIndex: "test_w_1_00000000_idx",
+		Key:     []metadata.IndexKeyPair{{Field: "w"}},
+	}}
+
+	for name, tc := range map[string]struct {
+		filter   *types.Document
+		expected []string
+		args     []any
+	}{
+		"Regex": {
+			filter:   must.NotFail(types.NewDocument("v", types.Regex{Pattern: "foo_bar"})),
+			expected: []string{`(_jsonb->>'v') LIKE $1`},
+			args:     []any{`%foo\_bar%`},
+		},
+		"RegexOperator": {
+			filter: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$regex", "foo bar", "$options", "i")),
+			)),
+			expected: []string{`(_jsonb->>'v') ILIKE $1`},
+			args:     []any{`%foo bar%`},
+		},
+		"RegexOperatorRegex": {
+			filter: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$regex", types.Regex{Pattern: "foo", Options: "m"})),
+			)),
+			expected: []string{`(_jsonb->>'v') LIKE $1`},
+			args:     []any{`%foo%`},
+		},
+		"NotIndexed": {
+			filter: must.NotFail(types.NewDocument("w", types.Regex{Pattern: "foo"})),
+		},
+		"Anchored": {
+			filter: must.NotFail(types.NewDocument("v", types.Regex{Pattern: "^foo"})),
+		},
+		"Short": {
+			filter: must.NotFail(types.NewDocument("v", types.Regex{Pattern: "fo"})),
+		},
+		"Special": {
+			filter: must.NotFail(types.NewDocument("v", types.Regex{Pattern: "fo%o"})),
+		},
+		"Extended": {
+			filter: must.NotFail(types.NewDocument("v", types.Regex{Pattern: "foo", Options: "x"})),
+		},
+		"CaseFolding": {
+			filter: must.NotFail(types.NewDocument("v", types.Regex{Pattern: "ask", Options: "i"})),
+		},
+		"String": {
+			filter: must.NotFail(types.NewDocument("v", "foo")),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			filters, args := prepareRegexFilters(new(metadata.Placeholder), tc.filter, indexes)
+			assert.Equal(t, tc.expected, filters)
+			assert.Equal(t, tc.args, args)
+		})
+	}
+}
+
+func TestPrepareCountWhereClause(t *testing.T) {
+	t.Parallel()
+
+	idEqual := ` WHERE (_jsonb->$1 = $2 AND _jsonb->'$s'->'p'->$1->>'t' IN ('string'))`
+
+	for name, tc := range map[string]struct {
+		filter   *types.Document
+		expected string
+		args     []any
+		ok       bool
+	}{
+		"Nil": {
+			ok: true,
+		},
+		"Empty": {
+			filter: must.NotFail(types.NewDocument()),
+			ok:     true,
+		},
+		"Comment": {
+			filter: must.NotFail(types.NewDocument("$comment", "foo")),
+			ok:     true,
+		},
+		"ID": {
+			filter:   must.NotFail(types.NewDocument("_id", "foo")),
+			expected: idEqual,
+			args:     []any{"_id", `"foo"`},
+			ok:       true,
+		},
+		"IDEq": {
+			filter:   must.NotFail(types.NewDocument("_id", must.NotFail(types.NewDocument("$eq", "foo")))),
+			expected: idEqual,
+			args:     []any{"_id", `"foo"`},
+			ok:       true,
+		},
+		"Number": {
+			filter: must.NotFail(types.NewDocument("v", int32(42))),
+			expected: ` WHERE ((_jsonb->$1 = $2 AND _jsonb->'$s'->'p'->$1->>'t' IN ('int', 'long', 'double')) OR ` +
+				`CASE WHEN jsonb_typeof(_jsonb->$1) = 'array' THEN EXISTS (` +
+				`SELECT 1 FROM jsonb_array_elements(_jsonb->$1) WITH ORDINALITY AS e(v, n) ` +
+				`WHERE e.v = $2 AND _jsonb->'$s'->'p'->$1->'i'->(e.n::int - 1)->>'t' IN ('int', 'long', 'double')` +
+				`) ELSE false END)`,
+			args: []any{"v", "42"},
+			ok:   true,
+		},
+		"ExistsSize": {
+			filter: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$exists", true)),
+				"w", must.NotFail(types.NewDocument("$size", int32(2))),
+			)),
+			expected: ` WHERE _jsonb ? $1 AND ` +
+				`(CASE WHEN jsonb_typeof(_jsonb->'w') = 'array' THEN jsonb_array_length(_jsonb->'w') END) = $2`,
+			args: []any{"v", int64(2)},
+			ok:   true,
+		},
+		"ExistsNotBool": {
+			filter: must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$exists", int32(1))))),
+		},
+		"UnsafeNumber": {
+			filter: must.NotFail(types.NewDocument("v", int64(math.MaxInt64))),
+		},
+		"NaN": {
+			filter: must.NotFail(types.NewDocument("v", math.NaN())),
+		},
+		"Null": {
+			filter: must.NotFail(types.NewDocument("v", types.Null)),
+		},
+		"Document": {
+			filter: must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("foo", "bar")))),
+		},
+		"EmptyDocument": {
+			filter: must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument()))),
+		},
+		"Gt": {
+			filter: must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$gt", int32(1))))),
+		},
+		"DotNotation": {
+			filter: must.NotFail(types.NewDocument("v.foo", "bar")),
+		},
+		"Or": {
+			filter: must.NotFail(types.NewDocument("$or", must.NotFail(types.NewArray()))),
+		},
+		"PartiallySupported": {
+			filter: must.NotFail(types.NewDocument(
+				"v", "foo",
+				"w", must.NotFail(types.NewDocument("$lt", int32(1))),
+			)),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, args, ok := prepareCountWhereClause(new(metadata.Placeholder), tc.filter)
+			require.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, actual)
+			assert.Equal(t, tc.args, args)
+		})
+	}
+}
+
+func TestPrepareTextSearch(t *testing.T) {
+	t.Parallel()
+
+	index := &metadata.IndexInfo{
+		Name:            "text_idx",
+		PgIndex:         "test_text_idx_00000000_idx",
+		Key:             []metadata.IndexKeyPair{{Field: "v"}},
+		Text:            true,
+		DefaultLanguage: "english",
+	}
+
+	vector := `(coalesce(jsonb_to_tsvector('english'::regconfig, _jsonb->'v', '["string"]'), ''))`
+
+	for name, tc := range map[string]struct {
+		ts    *backends.TextSearch
+		cond  string
+		score string
+		args  []any
+	}{
+		"Terms": {
+			ts:    &backends.TextSearch{Terms: []string{"foo", "bar"}},
+			cond:  vector + ` @@ ((plainto_tsquery($1::regconfig, $2) || plainto_tsquery($1::regconfig, $3)))`,
+			score: `ts_rank(` + vector + `, (plainto_tsquery($1::regconfig, $2) || plainto_tsquery($1::regconfig, $3)))::float8`,
+			args:  []any{"english", "foo", "bar"},
+		},
+		"PhraseNegation": {
+			ts: &backends.TextSearch{
+				Terms:     []string{"foo"},
+				Phrases:   []string{"bar baz"},
+				Negations: []string{"qux"},
+				Language:  "none",
+			},
+			cond: vector + ` @@ (phraseto_tsquery($1::regconfig, $3) && !! plainto_tsquery($1::regconfig, $4))`,
+			score: `ts_rank(` + vector +
+				`, (plainto_tsquery($1::regconfig, $2) || phraseto_tsquery($1::regconfig, $3)))::float8`,
+			args: []any{"simple", "foo", "bar baz", "qux"},
+		},
+		"OnlyNegations": {
+			ts: &backends.TextSearch{Negations: []string{"foo"}},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cond, score, args := prepareTextSearch(new(metadata.Placeholder), tc.ts, index)
+			assert.Equal(t, tc.cond, cond)
+			assert.Equal(t, tc.score, score)
+			assert.Equal(t, tc.args, args)
+		})
+	}
+}
+
+func TestPrepareGeoQuery(t *testing.T) {
+	t.Parallel()
+
+	field := metadata.GeographyExpression("loc")
+	geometry := `ST_GeomFromGeoJSON($1::text)::geography`
+	point := `{"coordinates":[1,2],"type":"Point"}`
+
+	for name, tc := range map[string]struct {
+		g        *backends.GeoQuery
+		cond     string
+		distance string
+		args     []any
+	}{
+		"GeoWithin": {
+			g:    &backends.GeoQuery{Field: "loc", Operator: backends.GeoWithin, Geometry: point},
+			cond: `ST_CoveredBy(` + field + `, ` + geometry + `)`,
+			args: []any{point},
+		},
+		"CenterSphere": {
+			g:    &backends.GeoQuery{Field: "loc", Operator: backends.GeoWithin, Geometry: point, MaxDistance: pointer.ToFloat64(10)},
+			cond: `ST_DWithin(` + field + `, ` + geometry + `, $2::float8)`,
+			args: []any{point, float64(10)},
+		},
+		"GeoIntersects": {
+			g:    &backends.GeoQuery{Field: "loc", Operator: backends.GeoIntersects, Geometry: point},
+			cond: `ST_Intersects(` + field + `, ` + geometry + `)`,
+			args: []any{point},
+		},
+		"Near": {
+			g: &backends.GeoQuery{
+				Field:       "loc",
+				Operator:    backends.GeoNear,
+				Geometry:    point,
+				MinDistance: pointer.ToFloat64(1),
+				MaxDistance: pointer.ToFloat64(10),
+			},
+			cond: `(` + field + `) IS NOT NULL AND ST_DWithin(` + field + `, ` + geometry + `, $2::float8) AND ` +
+				`ST_Distance(` + field + `, ` + geometry + `) >= $3::float8`,
+			distance: `((` + field + `) <-> ` + geometry + `)`,
+			args:     []any{point, float64(10), float64(1)},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cond, distance, args := prepareGeoQuery(new(metadata.Placeholder), tc.g)
+			assert.Equal(t, tc.cond, cond)
+			assert.Equal(t, tc.distance, distance)
+			assert.Equal(t, tc.args, args)
+		})
+	}
+}
+
+func TestPrepareDistinctOnClause(t *testing.T) {
+	t.Parallel()
+
+	var placeholder metadata.Placeholder
+	placeholder.Next()
+
+	clause, args := prepareDistinctOnClause(&placeholder, "v")
+	assert.Equal(t, `DISTINCT ON (_jsonb->$2, _jsonb->'$s'->'p'->$2) `, clause)
+	assert.Equal(t, []any{"v"}, args)
+}
+
+func TestPrepareGroupCountClause(t *testing.T) {
+	t.Parallel()
+
+	var placeholder metadata.Placeholder
+	placeholder.Next()
+
+	clause, args := prepareGroupCountClause(&placeholder, "db", "table", "v")
+	assert.Equal(t, `SELECT _jsonb->$2, _jsonb->'$s'->'p'->$2, COUNT(*) FROM "db"."table"`, clause)
+	assert.Equal(t, []any{"v"}, args)
+}
+
+func TestPrepareUnwind(t *testing.T) {
+	t.Parallel()
+
+	document, join, cond, args := prepareUnwind(new(metadata.Placeholder), "v")
+
+	isArray := `_jsonb->'$s'->'p'->$1::text->>'t' = 'array'`
+	assert.Equal(
+		t,
+		`CASE WHEN `+isArray+` THEN jsonb_set(jsonb_set(_jsonb, ARRAY[$1::text], _unwind.value), `+
+			`ARRAY['$s', 'p', $1::text], _jsonb->'$s'->'p'->$1::text->'i'->(_unwind.n::int - 1)) ELSE _jsonb END AS _jsonb`,
+		document,
+	)
+	assert.Equal(
+		t,
+		` CROSS JOIN LATERAL jsonb_array_elements(CASE WHEN `+isArray+` THEN _jsonb->$1::text `+
+			`ELSE jsonb_build_array(_jsonb->$1::text) END) WITH ORDINALITY AS _unwind(value, n)`,
+		join,
+	)
+	assert.Equal(t, `_jsonb->'$s'->'p'->$1::text->>'t' <> 'null'`, cond)
+	assert.Equal(t, []any{"v"}, args)
+}
+
+func TestUnmarshalField(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		value    string
+		schema   string
+		expected any
+	}{
+		"String": {
+			value:    `"foo"`,
+			schema:   `{"t":"string"}`,
+			expected: "foo",
+		},
+		"Int": {
+			value:    `42`,
+			schema:   `{"t":"int"}`,
+			expected: int32(42),
+		},
+		"Date": {
+			value:    `42`,
+			schema:   `{"t":"date"}`,
+			expected: time.UnixMilli(42),
+		},
+		"Null": {
+			value:    `null`,
+			schema:   `{"t":"null"}`,
+			expected: types.Null,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := unmarshalField([]byte(tc.value), []byte(tc.schema))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
 func TestPrepareOrderByClause(t *testing.T) {
 	t.Parallel()
 
 	for name, tc := range map[string]struct { //nolint:vet // used for test only
-		sort   *backends.SortField
-		capped bool
+		sort        *backends.SortField
+		orderColumn string
 
 		orderBy string
 		args    []any
@@ -286,28 +745,44 @@ func TestPrepareOrderByClause(t *testing.T) {
 			args:    nil,
 		},
 		"Capped": {
-			capped:  true,
-			orderBy: ` ORDER BY _ferretdb_record_id`,
-			args:    nil,
+			orderColumn: metadata.RecordIDColumn,
+			orderBy:     ` ORDER BY _ferretdb_record_id`,
+			args:        nil,
 		},
 		"CappedWithSort": {
-			sort:    &backends.SortField{Key: "field", Descending: true},
-			capped:  true,
-			orderBy: ` ORDER BY _jsonb->$1 DESC`,
-			args:    []any{"field"},
+			sort:        &backends.SortField{Key: "field", Descending: true},
+			orderColumn: metadata.RecordIDColumn,
+			orderBy:     ` ORDER BY _jsonb->$1 DESC`,
+			args:        []any{"field"},
+		},
+		"Sequence": {
+			orderColumn: metadata.SequenceColumn,
+			orderBy:     ` ORDER BY _ferretdb_seq`,
+			args:        nil,
+		},
+		"Natural": {
+			sort:        &backends.SortField{Key: backends.NaturalSortKey, Descending: true},
+			orderColumn: metadata.SequenceColumn,
+			orderBy:     ` ORDER BY _ferretdb_seq DESC`,
+			args:        nil,
+		},
+		"NaturalWithoutSequence": {
+			sort:    &backends.SortField{Key: backends.NaturalSortKey},
+			orderBy: ` ORDER BY ctid`,
+			args:    nil,
 		},
 		"CappedWithSortDotNotation": {
-			sort:    &backends.SortField{Key: "field.embedded", Descending: true},
-			capped:  true,
-			orderBy: "",
-			args:    nil,
+			sort:        &backends.SortField{Key: "field.embedded", Descending: true},
+			orderColumn: metadata.RecordIDColumn,
+			orderBy:     "",
+			args:        nil,
 		},
 	} {
 		name, tc := name, tc
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			orderBy, args := prepareOrderByClause(new(metadata.Placeholder), tc.sort, tc.capped)
+			orderBy, args := prepareOrderByClause(new(metadata.Placeholder), tc.sort, tc.orderColumn)
 			assert.Equal(t, tc.orderBy, orderBy)
 			assert.Equal(t, tc.args, args)
 		})
@@ -16,6 +16,7 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"sort"
@@ -29,6 +30,7 @@ import (
 	"github.com/FerretDB/FerretDB/internal/handlers/sjson"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/fsql"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 )
@@ -51,43 +53,68 @@ func newCollection(r *metadata.Registry, dbName, name string) backends.Collectio
 
 // Query implements backends.Collection interface.
 func (c *collection) Query(ctx context.Context, params *backends.QueryParams) (*backends.QueryResult, error) {
+	if params == nil {
+		params = new(backends.QueryParams)
+	}
+
+	var textScores map[*types.Document]float64
+	if params.TextSearch != nil {
+		textScores = map[*types.Document]float64{}
+	}
+
 	db := c.r.DatabaseGetExisting(ctx, c.dbName)
 	if db == nil {
 		return &backends.QueryResult{
-			Iter: newQueryIterator(ctx, nil, params.OnlyRecordIDs),
+			Iter:       newQueryIterator(ctx, nil, params.OnlyRecordIDs),
+			TextScores: textScores,
 		}, nil
 	}
 
 	meta := c.r.CollectionGet(ctx, c.dbName, c.name)
 	if meta == nil {
 		return &backends.QueryResult{
-			Iter: newQueryIterator(ctx, nil, params.OnlyRecordIDs),
+			Iter:       newQueryIterator(ctx, nil, params.OnlyRecordIDs),
+			TextScores: textScores,
 		}, nil
 	}
 
-	if params == nil {
-		params = new(backends.QueryParams)
+	// handlers check capabilities first
+	if params.Geo != nil {
+		return nil, lazyerrors.Errorf("geospatial queries are not supported by SQLite backend")
 	}
 
-	var whereClause string
-	var args []any
+	var textIndex *metadata.IndexInfo
 
-	// that logic should exist in one place
-	// TODO https://github.com/FerretDB/FerretDB/issues/3235
-	if params.Filter.Len() == 1 {
-		v, _ := params.Filter.Get("_id")
-		switch v.(type) {
-		case string, types.ObjectID:
-			whereClause = fmt.Sprintf(` WHERE %s = ?`, metadata.IDColumn)
-			args = []any{string(must.NotFail(sjson.MarshalSingleValue(v)))}
+	if params.TextSearch != nil {
+		for i, index := range meta.Settings.Indexes {
+			if index.Text {
+				textIndex = &meta.Settings.Indexes[i]
+				break
+			}
+		}
+
+		if textIndex == nil {
+			return nil, lazyerrors.Errorf("no text index for %s.%s", c.dbName, c.name)
 		}
 	}
 
-	q := prepareSelectClause(meta.TableName, meta.Capped(), params.OnlyRecordIDs) + whereClause
+	whereClause, args := prepareWhereClause(params.Filter)
 
-	q += prepareOrderByClause(params.Sort, meta.Capped())
+	// text search needs whole documents
+	onlyRecordIDs := params.OnlyRecordIDs && textIndex == nil
 
-	if params.Limit != 0 {
+	q := prepareSelectClause(meta.TableName, meta.Capped(), onlyRecordIDs)
+
+	if params.SeqScan {
+		// table scan is done in rowid order, so no sorting is needed
+		q += ` NOT INDEXED`
+		args = nil
+	} else {
+		q += whereClause + prepareOrderByClause(params.Sort, meta.Capped())
+	}
+
+	// text search is done after the query, so limit can't be pushed down
+	if params.Limit != 0 && textIndex == nil {
 		q += ` LIMIT ?`
 		args = append(args, params.Limit)
 	}
@@ -97,11 +124,31 @@ func (c *collection) Query(ctx context.Context, params *backends.QueryParams) (*
 		return nil, lazyerrors.Error(err)
 	}
 
+	iter := newQueryIterator(ctx, rows, onlyRecordIDs)
+
+	if textIndex != nil {
+		textIter, err := newTextSearchIterator(iter, params.TextSearch, textIndex, params.OnlyRecordIDs, textScores)
+		if err != nil {
+			iter.Close()
+			return nil, lazyerrors.Error(err)
+		}
+
+		iter = textIter
+	}
+
 	return &backends.QueryResult{
-		Iter: newQueryIterator(ctx, rows, params.OnlyRecordIDs),
+		Iter:       iter,
+		TextScores: textScores,
 	}, nil
 }
 
+// Count implements backends.Collection interface.
+//
+// Filters are not pushed down exactly, so documents are counted by handlers.
+func (c *collection) Count(ctx context.Context, params *backends.CountParams) (*backends.CountResult, error) {
+	return new(backends.CountResult), nil
+}
+
 // InsertAll implements backends.Collection interface.
 func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllParams) (*backends.InsertAllResult, error) {
 	if _, err := c.r.CollectionCreate(ctx, &metadata.CollectionCreateParams{DBName: c.dbName, Name: c.name}); err != nil {
@@ -111,7 +158,16 @@ func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllPa
 	db := c.r.DatabaseGetExisting(ctx, c.dbName)
 	meta := c.r.CollectionGet(ctx, c.dbName, c.name)
 
+	var skipped []int
+
 	err := db.InTransaction(ctx, func(tx *fsql.Tx) error {
+		if params.SkipDuplicates {
+			var err error
+			skipped, err = insertDocumentsSkipDuplicates(ctx, tx, meta.TableName, meta.Capped(), params.Docs)
+
+			return err
+		}
+
 		var batch []*types.Document
 		docs := params.Docs
 		const batchSize = 100
@@ -141,7 +197,9 @@ func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllPa
 		return nil, err
 	}
 
-	return new(backends.InsertAllResult), nil
+	return &backends.InsertAllResult{
+		Skipped: skipped,
+	}, nil
 }
 
 // UpdateAll implements backends.Collection interface.
@@ -173,6 +231,11 @@ func (c *collection) UpdateAll(ctx context.Context, params *backends.UpdateAllPa
 
 			r, err := tx.ExecContext(ctx, q, string(b), arg)
 			if err != nil {
+				var se *sqlite3.Error
+				if errors.As(err, &se) && se.Code() == sqlite3lib.SQLITE_CONSTRAINT_UNIQUE {
+					return backends.NewError(backends.ErrorCodeInsertDuplicateID, err)
+				}
+
 				return lazyerrors.Error(err)
 			}
 
@@ -187,7 +250,7 @@ func (c *collection) UpdateAll(ctx context.Context, params *backends.UpdateAllPa
 		return nil
 	})
 	if err != nil {
-		return nil, lazyerrors.Error(err)
+		return nil, err
 	}
 
 	return &res, nil
@@ -233,6 +296,135 @@ func (c *collection) DeleteAll(ctx context.Context, params *backends.DeleteAllPa
 	}, nil
 }
 
+// FindAndModify implements backends.Collection interface.
+func (c *collection) FindAndModify(ctx context.Context, params *backends.FindAndModifyParams) (*backends.FindAndModifyResult, error) {
+	var res backends.FindAndModifyResult
+
+	db := c.r.DatabaseGetExisting(ctx, c.dbName)
+
+	var meta *metadata.Collection
+	if db != nil {
+		meta = c.r.CollectionGet(ctx, c.dbName, c.name)
+	}
+
+	if meta == nil {
+		change, err := params.Modify(newQueryIterator(ctx, nil, false))
+		if err != nil {
+			return nil, err
+		}
+
+		if change == nil {
+			return &res, nil
+		}
+
+		// there is nothing to update or delete, but a document could be upserted
+		must.NotBeZero(change.Insert)
+
+		if _, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{change.Insert}}); err != nil {
+			return nil, err
+		}
+
+		res.Inserted = 1
+
+		return &res, nil
+	}
+
+	whereClause, args := prepareWhereClause(params.Filter)
+	q := prepareSelectClause(meta.TableName, meta.Capped(), false) + whereClause
+
+	err := db.InTransaction(ctx, func(tx *fsql.Tx) error {
+		// SQLite does not have SELECT ... FOR UPDATE;
+		// start a write transaction by a no-op update instead,
+		// so concurrent writers wait for this one to commit
+		noop := fmt.Sprintf(`UPDATE %q SET %s = %[2]s WHERE 0`, meta.TableName, metadata.DefaultColumn)
+		if _, err := tx.ExecContext(ctx, noop); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		rows, err := tx.QueryContext(ctx, q, args...)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		docs, err := iterator.ConsumeValues(newQueryIterator(ctx, rows, false))
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		change, err := params.Modify(iterator.Values(iterator.ForSlice(docs)))
+		if err != nil || change == nil {
+			return err
+		}
+
+		switch {
+		case change.Update != nil:
+			var b []byte
+			if b, err = sjson.Marshal(change.Update); err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			id := string(must.NotFail(sjson.MarshalSingleValue(must.NotFail(change.Update.Get("_id")))))
+
+			var r sql.Result
+			r, err = tx.ExecContext(
+				ctx,
+				fmt.Sprintf(`UPDATE %q SET %s = ? WHERE %s = ?`, meta.TableName, metadata.DefaultColumn, metadata.IDColumn),
+				string(b), id,
+			)
+			if err != nil {
+				var se *sqlite3.Error
+				if errors.As(err, &se) && se.Code() == sqlite3lib.SQLITE_CONSTRAINT_UNIQUE {
+					return backends.NewError(backends.ErrorCodeInsertDuplicateID, err)
+				}
+
+				return lazyerrors.Error(err)
+			}
+
+			ra, _ := r.RowsAffected()
+			res.Updated = int32(ra)
+
+		case change.DeleteID != nil:
+			id := string(must.NotFail(sjson.MarshalSingleValue(change.DeleteID)))
+
+			var r sql.Result
+			r, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %q WHERE %s = ?`, meta.TableName, metadata.IDColumn), id)
+			if err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			ra, _ := r.RowsAffected()
+			res.Deleted = int32(ra)
+
+		case change.Insert != nil:
+			var insertQ string
+			var insertArgs []any
+
+			insertQ, insertArgs, err = prepareInsertStatement(meta.TableName, meta.Capped(), []*types.Document{change.Insert})
+			if err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			if _, err = tx.ExecContext(ctx, insertQ, insertArgs...); err != nil {
+				var se *sqlite3.Error
+				if errors.As(err, &se) && se.Code() == sqlite3lib.SQLITE_CONSTRAINT_UNIQUE {
+					return backends.NewError(backends.ErrorCodeInsertDuplicateID, err)
+				}
+
+				return lazyerrors.Error(err)
+			}
+
+			res.Inserted = 1
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
 // Explain implements backends.Collection interface.
 func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams) (*backends.ExplainResult, error) {
 	db := c.r.DatabaseGetExisting(ctx, c.dbName)
@@ -255,25 +447,18 @@ func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams
 
 	selectClause := prepareSelectClause(meta.TableName, meta.Capped(), false)
 
-	var queryPushdown bool
-	var whereClause string
-	var args []any
-
-	// that logic should exist in one place
-	// TODO https://github.com/FerretDB/FerretDB/issues/3235
-	if params.Filter.Len() == 1 {
-		v, _ := params.Filter.Get("_id")
-		switch v.(type) {
-		case string, types.ObjectID:
-			queryPushdown = true
-			whereClause = fmt.Sprintf(` WHERE %s = ?`, metadata.IDColumn)
-			args = []any{string(must.NotFail(sjson.MarshalSingleValue(v)))}
-		}
-	}
+	whereClause, args := prepareWhereClause(params.Filter)
+	queryPushdown := whereClause != ""
 
 	orderByClause := prepareOrderByClause(params.Sort, meta.Capped())
 	unsafeSortPushdown := orderByClause != ""
 
+	if params.SeqScan {
+		whereClause, args, queryPushdown = ` NOT INDEXED`, nil, false
+		orderByClause, unsafeSortPushdown = "", false
+	}
+
+	// SQLite does not execute queries with EXPLAIN QUERY PLAN, so params.Analyze is ignored
 	q := `EXPLAIN QUERY PLAN ` + selectClause + whereClause + orderByClause
 
 	var unsafeLimitPushdown bool
@@ -464,6 +649,11 @@ func (c *collection) ListIndexes(ctx context.Context, params *backends.ListIndex
 			Name:   index.Name,
 			Unique: index.Unique,
 			Key:    make([]backends.IndexKeyPair, len(index.Key)),
+
+			PartialFilterExpression: index.PartialFilterExpression,
+
+			Text:            index.Text,
+			DefaultLanguage: index.DefaultLanguage,
 		}
 
 		for j, key := range index.Key {
@@ -489,6 +679,11 @@ func (c *collection) CreateIndexes(ctx context.Context, params *backends.CreateI
 			Name:   index.Name,
 			Key:    make([]metadata.IndexKeyPair, len(index.Key)),
 			Unique: index.Unique,
+
+			PartialFilterExpression: index.PartialFilterExpression,
+
+			Text:            index.Text,
+			DefaultLanguage: index.DefaultLanguage,
 		}
 
 		for j, key := range index.Key {
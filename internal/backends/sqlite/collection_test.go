@@ -15,6 +15,8 @@
 package sqlite
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -85,3 +87,161 @@ func TestCappedCollectionInsertAllQueryExplain(t *testing.T) {
 		assert.False(t, explainRes.UnsafeSortPushdown)
 	})
 }
+
+// setupBenchmarkCollection returns a collection with the given number of documents.
+//
+// Documents have string _id values, so filters by _id could be pushed down.
+func setupBenchmarkCollection(b *testing.B, docs int) backends.Collection {
+	b.Helper()
+
+	ctx := testutil.Ctx(b)
+
+	sp, err := state.NewProvider("")
+	require.NoError(b, err)
+
+	be, err := NewBackend(&NewBackendParams{URI: testutil.TestSQLiteURI(b, ""), L: testutil.Logger(b), P: sp})
+	require.NoError(b, err)
+	b.Cleanup(be.Close)
+
+	db, err := be.Database(testutil.DatabaseName(b))
+	require.NoError(b, err)
+
+	coll, err := db.Collection(testutil.CollectionName(b))
+	require.NoError(b, err)
+
+	insertDocs := make([]*types.Document, docs)
+	for i := range insertDocs {
+		insertDocs[i] = must.NotFail(types.NewDocument("_id", fmt.Sprintf("id%d", i), "v", int32(i)))
+	}
+
+	_, err = coll.InsertAll(ctx, &backends.InsertAllParams{Docs: insertDocs})
+	require.NoError(b, err)
+
+	return coll
+}
+
+func BenchmarkCollectionInsertAll(b *testing.B) {
+	for _, batchSize := range []int{1, 10, 100, 1000} {
+		batchSize := batchSize
+
+		b.Run(fmt.Sprintf("Batch%d", batchSize), func(b *testing.B) {
+			ctx := testutil.Ctx(b)
+			coll := setupBenchmarkCollection(b, 0)
+
+			var id int32
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+
+				insertDocs := make([]*types.Document, batchSize)
+				for j := range insertDocs {
+					insertDocs[j] = must.NotFail(types.NewDocument("_id", id, "v", "foo"))
+					id++
+				}
+
+				b.StartTimer()
+
+				_, err := coll.InsertAll(ctx, &backends.InsertAllParams{Docs: insertDocs})
+				require.NoError(b, err)
+			}
+
+			b.StopTimer()
+
+			b.ReportMetric(float64(batchSize*b.N)/b.Elapsed().Seconds(), "docs/s")
+		})
+	}
+}
+
+func BenchmarkCollectionQuery(b *testing.B) {
+	const docs = 1000
+
+	ctx := testutil.Ctx(b)
+	coll := setupBenchmarkCollection(b, docs)
+
+	id := fmt.Sprintf("id%d", docs-1)
+
+	// filter is pushed down to SQLite
+	b.Run("Pushdown", func(b *testing.B) {
+		var res []*types.Document
+
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			queryRes, err := coll.Query(ctx, &backends.QueryParams{
+				Filter: must.NotFail(types.NewDocument("_id", id)),
+			})
+			require.NoError(b, err)
+
+			res, err = iterator.ConsumeValues(queryRes.Iter)
+			require.NoError(b, err)
+		}
+
+		b.StopTimer()
+
+		require.Len(b, res, 1)
+	})
+
+	// all documents are fetched and filtered in Go, like handlers do when pushdown is disabled
+	b.Run("NoPushdown", func(b *testing.B) {
+		var res []*types.Document
+
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			queryRes, err := coll.Query(ctx, nil)
+			require.NoError(b, err)
+
+			res = res[:0]
+
+			for {
+				_, doc, err := queryRes.Iter.Next()
+				if errors.Is(err, iterator.ErrIteratorDone) {
+					break
+				}
+
+				require.NoError(b, err)
+
+				if must.NotFail(doc.Get("_id")) == id {
+					res = append(res, doc)
+				}
+			}
+
+			queryRes.Iter.Close()
+		}
+
+		b.StopTimer()
+
+		require.Len(b, res, 1)
+	})
+}
+
+func BenchmarkCollectionQueryIterate(b *testing.B) {
+	for _, docs := range []int{10, 100, 1000} {
+		docs := docs
+
+		b.Run(fmt.Sprintf("Docs%d", docs), func(b *testing.B) {
+			ctx := testutil.Ctx(b)
+			coll := setupBenchmarkCollection(b, docs)
+
+			var n int
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				queryRes, err := coll.Query(ctx, nil)
+				require.NoError(b, err)
+
+				n, err = iterator.ConsumeCount(queryRes.Iter)
+				require.NoError(b, err)
+			}
+
+			b.StopTimer()
+
+			require.Equal(b, docs, n)
+		})
+	}
+}
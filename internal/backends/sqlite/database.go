@@ -16,6 +16,7 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/backends/sqlite/metadata"
@@ -45,9 +46,17 @@ func (db *database) Collection(name string) (backends.Collection, error) {
 //
 //nolint:lll // for readability
 func (db *database) ListCollections(ctx context.Context, params *backends.ListCollectionsParams) (*backends.ListCollectionsResult, error) {
-	list, err := db.r.CollectionList(ctx, db.name)
-	if err != nil {
-		return nil, lazyerrors.Error(err)
+	var list []*metadata.Collection
+
+	if params != nil && params.Name != "" {
+		if c := db.r.CollectionGet(ctx, db.name, params.Name); c != nil {
+			list = []*metadata.Collection{c}
+		}
+	} else {
+		var err error
+		if list, err = db.r.CollectionList(ctx, db.name); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
 	}
 
 	res := make([]backends.CollectionInfo, len(list))
@@ -56,6 +65,7 @@ func (db *database) ListCollections(ctx context.Context, params *backends.ListCo
 			Name:            c.Name,
 			CappedSize:      c.Settings.CappedSize,
 			CappedDocuments: c.Settings.CappedDocuments,
+			Settings:        c.Settings.Custom,
 		}
 	}
 
@@ -66,6 +76,10 @@ func (db *database) ListCollections(ctx context.Context, params *backends.ListCo
 
 // CreateCollection implements backends.Database interface.
 func (db *database) CreateCollection(ctx context.Context, params *backends.CreateCollectionParams) error {
+	if params.Tablespace != "" {
+		return lazyerrors.New("tablespaces are not supported")
+	}
+
 	created, err := db.r.CollectionCreate(ctx, &metadata.CollectionCreateParams{
 		DBName:          db.name,
 		Name:            params.Name,
@@ -125,6 +139,20 @@ func (db *database) RenameCollection(ctx context.Context, params *backends.Renam
 	return nil
 }
 
+// SetCollectionSettings implements backends.Database interface.
+func (db *database) SetCollectionSettings(ctx context.Context, params *backends.SetCollectionSettingsParams) error {
+	set, err := db.r.CollectionSetSettings(ctx, db.name, params.Name, params.Settings)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if !set {
+		return backends.NewError(backends.ErrorCodeCollectionDoesNotExist, err)
+	}
+
+	return nil
+}
+
 // Stats implements backends.Database interface.
 func (db *database) Stats(ctx context.Context, params *backends.DatabaseStatsParams) (*backends.DatabaseStatsResult, error) {
 	if params == nil {
@@ -167,6 +195,70 @@ func (db *database) Stats(ctx context.Context, params *backends.DatabaseStatsPar
 	}, nil
 }
 
+// ValidateMetadata implements backends.Database interface.
+//
+//nolint:lll // for readability
+func (db *database) ValidateMetadata(ctx context.Context, params *backends.ValidateMetadataParams) (*backends.ValidateMetadataResult, error) {
+	if params == nil {
+		params = new(backends.ValidateMetadataParams)
+	}
+
+	problems, err := db.r.ValidateMetadata(ctx, db.name, params.Repair)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &backends.ValidateMetadataResult{
+		Problems: problems,
+	}, nil
+}
+
+// QueryRaw implements backends.Database interface.
+func (db *database) QueryRaw(ctx context.Context, params *backends.QueryRawParams) (*backends.QueryRawResult, error) {
+	d := db.r.DatabaseGetExisting(ctx, db.name)
+	if d == nil {
+		return &backends.QueryRawResult{
+			Iter: newRawQueryIterator(ctx, nil, nil),
+		}, nil
+	}
+
+	cancel := func() {}
+	if params.Timeout > 0 {
+		// SQLite has no statement timeout, so the timeout also limits fetching of results
+		ctx, cancel = context.WithTimeout(ctx, params.Timeout)
+	}
+
+	// deferred transaction does not take locks until the query is executed;
+	// it is not rolled back on context cancellation so query_only could be reset below
+	tx, err := d.BeginTx(context.WithoutCancel(ctx), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		cancel()
+		return nil, lazyerrors.Error(err)
+	}
+
+	// query_only is a connection setting, so it is reset before the connection is returned to the pool
+	closeTx := func() {
+		_, _ = tx.ExecContext(context.Background(), "PRAGMA query_only = OFF")
+		_ = tx.Rollback()
+		cancel()
+	}
+
+	if _, err = tx.ExecContext(ctx, "PRAGMA query_only = ON"); err != nil {
+		closeTx()
+		return nil, lazyerrors.Error(err)
+	}
+
+	rows, err := tx.QueryContext(ctx, params.SQL)
+	if err != nil {
+		closeTx()
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &backends.QueryRawResult{
+		Iter: newRawQueryIterator(ctx, rows, closeTx),
+	}, nil
+}
+
 // check interfaces
 var (
 	_ backends.Database = (*database)(nil)
@@ -16,11 +16,13 @@ package sqlite
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/util/state"
 	"github.com/FerretDB/FerretDB/internal/util/testutil"
@@ -124,3 +126,45 @@ func TestDatabaseStatsFreeStorage(t *testing.T) {
 		})
 	}
 }
+
+func TestDatabaseQueryRaw(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.Ctx(t)
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	b, err := NewBackend(&NewBackendParams{URI: testutil.TestSQLiteURI(t, ""), L: testutil.Logger(t), P: sp})
+	require.NoError(t, err)
+	t.Cleanup(b.Close)
+
+	db, err := b.Database(testutil.DatabaseName(t))
+	require.NoError(t, err)
+
+	err = db.CreateCollection(ctx, &backends.CreateCollectionParams{Name: testutil.CollectionName(t)})
+	require.NoError(t, err)
+
+	t.Run("ReadOnly", func(t *testing.T) {
+		res, err := db.QueryRaw(ctx, &backends.QueryRawParams{SQL: "CREATE TABLE t AS SELECT json_object('_id', 1)"})
+		if err == nil {
+			_, err = iterator.ConsumeValues(res.Iter)
+		}
+
+		require.ErrorContains(t, err, "readonly")
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		q := `WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c) ` +
+			`SELECT json_object('_id', x) FROM c WHERE x < 0`
+
+		res, err := db.QueryRaw(ctx, &backends.QueryRawParams{SQL: q, Timeout: 10 * time.Millisecond})
+		if err == nil {
+			_, err = iterator.ConsumeValues(res.Iter)
+		}
+
+		require.Error(t, err)
+		// the connection is not left in read-only mode
+		err = db.CreateCollection(ctx, &backends.CreateCollectionParams{Name: "timeout"})
+		require.NoError(t, err)
+	})
+}
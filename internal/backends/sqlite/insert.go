@@ -15,12 +15,14 @@
 package sqlite
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/FerretDB/FerretDB/internal/backends/sqlite/metadata"
 	"github.com/FerretDB/FerretDB/internal/handlers/sjson"
 	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/fsql"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 )
 
@@ -60,3 +62,34 @@ func prepareInsertStatement(tableName string, capped bool, docs []*types.Documen
 		strings.Join(rows, ", "),
 	), args, nil
 }
+
+// insertDocumentsSkipDuplicates inserts the given documents one by one,
+// skipping documents that violate unique indexes with ON CONFLICT DO NOTHING.
+//
+// It returns indexes of skipped documents.
+func insertDocumentsSkipDuplicates(ctx context.Context, tx *fsql.Tx, tableName string, capped bool, docs []*types.Document) ([]int, error) { //nolint:lll // for readability
+	var skipped []int
+
+	for i, doc := range docs {
+		q, args, err := prepareInsertStatement(tableName, capped, []*types.Document{doc})
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		res, err := tx.ExecContext(ctx, q+` ON CONFLICT DO NOTHING`, args...)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if n == 0 {
+			skipped = append(skipped, i)
+		}
+	}
+
+	return skipped, nil
+}
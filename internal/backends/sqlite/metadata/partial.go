@@ -0,0 +1,171 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/sjson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// partialFilterCondition returns SQL condition for the partial index WHERE clause
+// that matches documents matching the given partialFilterExpression.
+//
+// Values are inlined as literals, as CREATE INDEX does not accept query parameters.
+// Values are compared together with their types from the SJSON schema;
+// array elements are not matched.
+func partialFilterCondition(filter *types.Document) (string, error) {
+	var conds []string
+
+	iter := filter.Iterator()
+	defer iter.Close()
+
+	for {
+		k, v, err := iter.Next()
+		if err != nil {
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				break
+			}
+
+			return "", lazyerrors.Error(err)
+		}
+
+		if k == "$and" {
+			arr, ok := v.(*types.Array)
+			if !ok || arr.Len() == 0 {
+				return "", lazyerrors.Errorf("invalid $and value: %v", v)
+			}
+
+			for i := 0; i < arr.Len(); i++ {
+				var doc any
+				if doc, err = arr.Get(i); err != nil {
+					return "", lazyerrors.Error(err)
+				}
+
+				subDoc, ok := doc.(*types.Document)
+				if !ok {
+					return "", lazyerrors.Errorf("invalid $and element: %v", doc)
+				}
+
+				var cond string
+				if cond, err = partialFilterCondition(subDoc); err != nil {
+					return "", lazyerrors.Error(err)
+				}
+
+				conds = append(conds, cond)
+			}
+
+			continue
+		}
+
+		if k == "" || strings.HasPrefix(k, "$") || strings.Contains(k, ".") {
+			return "", lazyerrors.Errorf("unsupported field %q", k)
+		}
+
+		exprs, ok := v.(*types.Document)
+		if !ok {
+			var cond string
+			if cond, err = partialFilterFieldCondition(k, "$eq", v); err != nil {
+				return "", lazyerrors.Error(err)
+			}
+
+			conds = append(conds, cond)
+
+			continue
+		}
+
+		for _, op := range exprs.Keys() {
+			var cond string
+			if cond, err = partialFilterFieldCondition(k, op, must.NotFail(exprs.Get(op))); err != nil {
+				return "", lazyerrors.Error(err)
+			}
+
+			conds = append(conds, cond)
+		}
+	}
+
+	if len(conds) == 0 {
+		return "TRUE", nil
+	}
+
+	return "(" + strings.Join(conds, " AND ") + ")", nil
+}
+
+// partialFilterFieldCondition returns SQL condition for the given operator and value on the given top-level field.
+func partialFilterFieldCondition(field, op string, v any) (string, error) {
+	valueExpr := fmt.Sprintf("%s->>%s", DefaultColumn, quoteString("$."+field))
+	typeExpr := fmt.Sprintf(`%s->>%s`, DefaultColumn, quoteString(`$."$s".p.`+field+".t"))
+
+	switch op {
+	case "$exists":
+		if b, ok := v.(bool); !ok || !b {
+			return "", lazyerrors.Errorf("unsupported $exists value: %v", v)
+		}
+
+		return fmt.Sprintf("(json_type(%s, %s) IS NOT NULL)", DefaultColumn, quoteString("$."+field)), nil
+
+	case "$eq":
+		var typeNames, literal string
+
+		switch v := v.(type) {
+		case string:
+			typeNames, literal = `'string'`, quoteString(v)
+		case types.ObjectID:
+			typeNames, literal = `'objectId'`, quoteString(hex.EncodeToString(v[:]))
+		case bool:
+			typeNames, literal = `'bool'`, "0"
+			if v {
+				literal = "1"
+			}
+		case int32, int64, float64:
+			typeNames, literal = `'int', 'long', 'double'`, string(must.NotFail(sjson.MarshalSingleValue(v)))
+		default:
+			return "", lazyerrors.Errorf("unsupported $eq value type %T", v)
+		}
+
+		return fmt.Sprintf("(%s IN (%s) AND %s = %s)", typeExpr, typeNames, valueExpr, literal), nil
+
+	case "$gt", "$gte", "$lt", "$lte":
+		switch v.(type) {
+		case int32, int64, float64:
+		default:
+			return "", lazyerrors.Errorf("unsupported %s value type %T", op, v)
+		}
+
+		b, err := sjson.MarshalSingleValue(v)
+		if err != nil {
+			return "", lazyerrors.Error(err)
+		}
+
+		sqlOp := map[string]string{"$gt": ">", "$gte": ">=", "$lt": "<", "$lte": "<="}[op]
+
+		return fmt.Sprintf("(%s IN ('int', 'long', 'double') AND %s %s %s)", typeExpr, valueExpr, sqlOp, string(b)), nil
+
+	default:
+		return "", lazyerrors.Errorf("unsupported operator %q", op)
+	}
+}
+
+// quoteString returns SQLite string literal for the given string.
+func quoteString(str string) string {
+	return "'" + strings.ReplaceAll(str, "'", "''") + "'"
+}
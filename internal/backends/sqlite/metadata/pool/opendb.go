@@ -61,6 +61,9 @@ func openDB(name, uri string, memory bool, l *zap.Logger, sp *state.Provider) (*
 		err := sp.Update(func(s *state.State) {
 			s.BackendName = "SQLite"
 
+			// none of backends.Capabilities are available
+			s.BackendCapabilities = map[string]string{}
+
 			row := db.QueryRowContext(context.Background(), "SELECT sqlite_version()")
 			if err := row.Scan(&s.BackendVersion); err != nil {
 				l.Error("sqlite.metadata.pool.openDB: failed to query SQLite version", zap.Error(err))
@@ -29,6 +29,7 @@ import (
 
 	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/backends/sqlite/metadata/pool"
+	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/fsql"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
@@ -452,6 +453,42 @@ func (r *Registry) CollectionRename(ctx context.Context, dbName, oldCollectionNa
 	return true, nil
 }
 
+// CollectionSetSettings replaces custom settings of the collection.
+//
+// Returned boolean value indicates whether the settings were replaced.
+// If database or collection did not exist, (false, nil) is returned.
+func (r *Registry) CollectionSetSettings(ctx context.Context, dbName, collectionName string, custom *types.Document) (bool, error) {
+	defer observability.FuncCall(ctx)()
+
+	db := r.DatabaseGetExisting(ctx, dbName)
+	if db == nil {
+		return false, nil
+	}
+
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	c := r.collectionGet(dbName, collectionName)
+	if c == nil {
+		return false, nil
+	}
+
+	if custom != nil {
+		custom = custom.DeepCopy()
+	}
+
+	c.Settings.Custom = custom
+
+	q := fmt.Sprintf("UPDATE %q SET settings = ? WHERE name = ?", metadataTableName)
+	if _, err := db.ExecContext(ctx, q, c.Settings, collectionName); err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	r.colls[dbName][collectionName] = c
+
+	return true, nil
+}
+
 // IndexesCreate creates indexes in the collection.
 //
 // Existing indexes with given names are ignored.
@@ -494,26 +531,13 @@ func (r *Registry) indexesCreate(ctx context.Context, dbName, collectionName str
 			continue
 		}
 
-		q := "CREATE "
-
-		if index.Unique {
-			q += "UNIQUE "
-		}
-
-		// Find a better way to sanitize identifiers.
-		// TODO https://github.com/FerretDB/FerretDB/issues/3418
-		q += "INDEX %q ON %q (%s)"
-
-		columns := make([]string, len(index.Key))
-		for i, key := range index.Key {
-			columns[i] = fmt.Sprintf("%s->'$.%s'", DefaultColumn, key.Field)
-			if key.Descending {
-				columns[i] += " DESC"
-			}
+		q, err := indexQuery(c.TableName, index)
+		if err != nil {
+			_ = r.indexesDrop(ctx, dbName, collectionName, created)
+			return lazyerrors.Error(err)
 		}
 
-		q = fmt.Sprintf(q, c.TableName+"_"+index.Name, c.TableName, strings.Join(columns, ", "))
-		if _, err := db.ExecContext(ctx, q); err != nil {
+		if _, err = db.ExecContext(ctx, q); err != nil {
 			_ = r.indexesDrop(ctx, dbName, collectionName, created)
 			return lazyerrors.Error(err)
 		}
@@ -522,8 +546,8 @@ func (r *Registry) indexesCreate(ctx context.Context, dbName, collectionName str
 		c.Settings.Indexes = append(c.Settings.Indexes, index)
 	}
 
-	q := fmt.Sprintf("UPDATE %q SET settings = ?", metadataTableName)
-	if _, err := db.ExecContext(ctx, q, c.Settings); err != nil {
+	q := fmt.Sprintf("UPDATE %q SET settings = ? WHERE name = ?", metadataTableName)
+	if _, err := db.ExecContext(ctx, q, c.Settings, collectionName); err != nil {
 		_ = r.indexesDrop(ctx, dbName, collectionName, created)
 		return lazyerrors.Error(err)
 	}
@@ -533,6 +557,47 @@ func (r *Registry) indexesCreate(ctx context.Context, dbName, collectionName str
 	return nil
 }
 
+// indexQuery returns a query that creates the given index on the given table.
+//
+// Index's PartialFilterExpression is translated to the WHERE clause of the partial index.
+func indexQuery(tableName string, index IndexInfo) (string, error) {
+	q := "CREATE "
+
+	if index.Unique {
+		q += "UNIQUE "
+	}
+
+	// Find a better way to sanitize identifiers.
+	// TODO https://github.com/FerretDB/FerretDB/issues/3418
+	q += "INDEX %q ON %q (%s)"
+
+	columns := make([]string, len(index.Key))
+	for i, key := range index.Key {
+		columns[i] = fmt.Sprintf("%s->'$.%s'", DefaultColumn, key.Field)
+		if key.Descending {
+			columns[i] += " DESC"
+		}
+	}
+
+	q = fmt.Sprintf(q, indexName(tableName, index.Name), tableName, strings.Join(columns, ", "))
+
+	if index.PartialFilterExpression != nil {
+		cond, err := partialFilterCondition(index.PartialFilterExpression)
+		if err != nil {
+			return "", lazyerrors.Error(err)
+		}
+
+		q += " WHERE " + cond
+	}
+
+	return q, nil
+}
+
+// indexName returns SQLite index name for the given table and FerretDB index name.
+func indexName(tableName, name string) string {
+	return tableName + "_" + name
+}
+
 // IndexesDrop removes given connection's indexes.
 //
 // Non-existing indexes are ignored.
@@ -573,7 +638,7 @@ func (r *Registry) indexesDrop(ctx context.Context, dbName, collectionName strin
 			continue
 		}
 
-		q := fmt.Sprintf("DROP INDEX %q", c.TableName+"_"+name)
+		q := fmt.Sprintf("DROP INDEX %q", indexName(c.TableName, name))
 		if _, err := db.ExecContext(ctx, q); err != nil {
 			return lazyerrors.Error(err)
 		}
@@ -581,8 +646,8 @@ func (r *Registry) indexesDrop(ctx context.Context, dbName, collectionName strin
 		c.Settings.Indexes = slices.Delete(c.Settings.Indexes, i, i+1)
 	}
 
-	q := fmt.Sprintf("UPDATE %q SET settings = ?", metadataTableName)
-	if _, err := db.ExecContext(ctx, q, c.Settings); err != nil {
+	q := fmt.Sprintf("UPDATE %q SET settings = ? WHERE name = ?", metadataTableName)
+	if _, err := db.ExecContext(ctx, q, c.Settings, collectionName); err != nil {
 		return lazyerrors.Error(err)
 	}
 
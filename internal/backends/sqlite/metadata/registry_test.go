@@ -22,7 +22,10 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/fsql"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/util/state"
 	"github.com/FerretDB/FerretDB/internal/util/testutil"
 	"github.com/FerretDB/FerretDB/internal/util/testutil/teststress"
@@ -438,3 +441,236 @@ func TestIndexesCreateDrop(t *testing.T) {
 		require.Equal(t, 1, len(collection.Settings.Indexes))
 	})
 }
+
+func TestCollectionSetSettings(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.Ctx(t)
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	uri := testutil.TestSQLiteURI(t, "")
+
+	r, err := NewRegistry(uri, testutil.Logger(t), sp)
+	require.NoError(t, err)
+
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+	otherName := collectionName + "_other"
+
+	for _, name := range []string{collectionName, otherName} {
+		created, err := r.CollectionCreate(ctx, &CollectionCreateParams{DBName: dbName, Name: name, CappedSize: 8192})
+		require.NoError(t, err)
+		require.True(t, created)
+	}
+
+	settings := must.NotFail(types.NewDocument("foo", "bar"))
+
+	set, err := r.CollectionSetSettings(ctx, dbName, collectionName, settings)
+	require.NoError(t, err)
+	require.True(t, set)
+
+	set, err = r.CollectionSetSettings(ctx, dbName, "none", settings)
+	require.NoError(t, err)
+	require.False(t, set)
+
+	// settings of other collections should not be replaced
+	err = r.IndexesCreate(ctx, dbName, otherName, []IndexInfo{{
+		Name: "index",
+		Key:  []IndexKeyPair{{Field: "f"}},
+	}})
+	require.NoError(t, err)
+
+	r.Close()
+
+	r, err = NewRegistry(uri, testutil.Logger(t), sp)
+	require.NoError(t, err)
+	t.Cleanup(r.Close)
+
+	c := r.CollectionGet(ctx, dbName, collectionName)
+	require.NotNil(t, c)
+	testutil.AssertEqual(t, settings, c.Settings.Custom)
+	require.Len(t, c.Settings.Indexes, 1)
+	require.EqualValues(t, 8192, c.Settings.CappedSize)
+
+	c = r.CollectionGet(ctx, dbName, otherName)
+	require.NotNil(t, c)
+	require.Nil(t, c.Settings.Custom)
+	require.Len(t, c.Settings.Indexes, 2)
+
+	renamed, err := r.CollectionRename(ctx, dbName, collectionName, collectionName+"_new")
+	require.NoError(t, err)
+	require.True(t, renamed)
+
+	c = r.CollectionGet(ctx, dbName, collectionName+"_new")
+	require.NotNil(t, c)
+	testutil.AssertEqual(t, settings, c.Settings.Custom)
+
+	set, err = r.CollectionSetSettings(ctx, dbName, collectionName+"_new", nil)
+	require.NoError(t, err)
+	require.True(t, set)
+
+	c = r.CollectionGet(ctx, dbName, collectionName+"_new")
+	require.NotNil(t, c)
+	require.Nil(t, c.Settings.Custom)
+}
+
+func TestPartialIndexes(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.Ctx(t)
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	r, err := NewRegistry(testutil.TestSQLiteURI(t, ""), testutil.Logger(t), sp)
+	require.NoError(t, err)
+	t.Cleanup(r.Close)
+
+	dbName := testutil.DatabaseName(t)
+
+	db, err := r.DatabaseGetOrCreate(ctx, dbName)
+	require.NoError(t, err)
+	require.NotNil(t, db)
+
+	collectionName := testutil.CollectionName(t)
+
+	filter := must.NotFail(types.NewDocument(
+		"s", "foo",
+		"v", must.NotFail(types.NewDocument("$gt", int32(1))),
+	))
+
+	toCreate := []IndexInfo{{
+		Name:                    "index_partial",
+		Key:                     []IndexKeyPair{{Field: "v"}},
+		PartialFilterExpression: filter,
+	}}
+
+	err = r.IndexesCreate(ctx, dbName, collectionName, toCreate)
+	require.NoError(t, err)
+
+	collection := r.CollectionGet(ctx, dbName, collectionName)
+
+	q := fmt.Sprintf("INSERT INTO %q (%s) VALUES(?)", collection.TableName, DefaultColumn)
+	for _, doc := range []string{
+		`{"$s": {"p": {"_id": {"t": "int"}, "s": {"t": "string"}, "v": {"t": "int"}}, "$k": ["_id", "s", "v"]}, "_id": 1, "s": "foo", "v": 2}`,
+		`{"$s": {"p": {"_id": {"t": "int"}, "s": {"t": "string"}, "v": {"t": "int"}}, "$k": ["_id", "s", "v"]}, "_id": 2, "s": "foo", "v": 1}`,
+		`{"$s": {"p": {"_id": {"t": "int"}, "s": {"t": "string"}, "v": {"t": "string"}}, "$k": ["_id", "s", "v"]}, "_id": 3, "s": "foo", "v": "2"}`,
+		`{"$s": {"p": {"_id": {"t": "int"}, "s": {"t": "string"}}, "$k": ["_id", "s"]}, "_id": 4, "s": "bar"}`,
+	} {
+		_, err = db.ExecContext(ctx, q, doc)
+		require.NoError(t, err)
+	}
+
+	t.Run("Condition", func(t *testing.T) {
+		cond, err := partialFilterCondition(filter)
+		require.NoError(t, err)
+
+		q := fmt.Sprintf("SELECT %s->'$._id' FROM %q WHERE %s", DefaultColumn, collection.TableName, cond)
+		rows, err := db.QueryContext(ctx, q)
+		require.NoError(t, err)
+
+		defer rows.Close()
+
+		var ids []int
+		for rows.Next() {
+			var id int
+			require.NoError(t, rows.Scan(&id))
+			ids = append(ids, id)
+		}
+
+		require.NoError(t, rows.Err())
+		require.Equal(t, []int{1}, ids)
+	})
+
+	t.Run("CheckSettingsAfterCreation", func(t *testing.T) {
+		err = r.initCollections(ctx, dbName, db)
+		require.NoError(t, err)
+
+		collection = r.CollectionGet(ctx, dbName, collectionName)
+		require.Equal(t, 2, len(collection.Settings.Indexes))
+
+		index := collection.Settings.Indexes[1]
+		require.Equal(t, "index_partial", index.Name)
+		testutil.AssertEqual(t, filter, index.PartialFilterExpression)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		unsupported := []IndexInfo{{
+			Name:                    "index_unsupported",
+			Key:                     []IndexKeyPair{{Field: "v"}},
+			PartialFilterExpression: must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$ne", int32(1))))),
+		}}
+
+		err = r.IndexesCreate(ctx, dbName, collectionName, unsupported)
+		require.Error(t, err)
+	})
+}
+
+func TestValidateMetadata(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.Ctx(t)
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	r, err := NewRegistry(testutil.TestSQLiteURI(t, ""), testutil.Logger(t), sp)
+	require.NoError(t, err)
+	t.Cleanup(r.Close)
+
+	dbName := testutil.DatabaseName(t)
+
+	db, err := r.DatabaseGetOrCreate(ctx, dbName)
+	require.NoError(t, err)
+
+	problems, err := r.ValidateMetadata(ctx, dbName, false)
+	require.NoError(t, err)
+	require.Empty(t, problems)
+
+	for _, name := range []string{"dropped", "indexed"} {
+		_, err = r.CollectionCreate(ctx, &CollectionCreateParams{DBName: dbName, Name: name})
+		require.NoError(t, err)
+	}
+
+	dropped := r.CollectionGet(ctx, dbName, "dropped")
+	indexed := r.CollectionGet(ctx, dbName, "indexed")
+
+	_, err = db.ExecContext(ctx, fmt.Sprintf("DROP TABLE %q", dropped.TableName))
+	require.NoError(t, err)
+
+	_, err = db.ExecContext(ctx, fmt.Sprintf("DROP INDEX %q", indexed.TableName+"__id_"))
+	require.NoError(t, err)
+
+	_, err = db.ExecContext(ctx, `CREATE TABLE "orphan" (v TEXT)`)
+	require.NoError(t, err)
+
+	expected := []backends.MetadataProblem{{
+		Type:       backends.MetadataProblemMissingTable,
+		Collection: "dropped",
+		Table:      dropped.TableName,
+	}, {
+		Type:       backends.MetadataProblemMissingIndex,
+		Collection: "indexed",
+		Table:      indexed.TableName,
+		Index:      "_id_",
+	}, {
+		Type:  backends.MetadataProblemOrphanTable,
+		Table: "orphan",
+	}}
+
+	problems, err = r.ValidateMetadata(ctx, dbName, false)
+	require.NoError(t, err)
+	require.Equal(t, expected, problems)
+
+	problems, err = r.ValidateMetadata(ctx, dbName, true)
+	require.NoError(t, err)
+
+	expected[0].Repaired = true
+	expected[1].Repaired = true
+	require.Equal(t, expected, problems)
+
+	require.Nil(t, r.CollectionGet(ctx, dbName, "dropped"))
+
+	problems, err = r.ValidateMetadata(ctx, dbName, false)
+	require.NoError(t, err)
+	require.Equal(t, expected[2:], problems)
+}
@@ -20,6 +20,8 @@ import (
 	"encoding/json"
 	"slices"
 
+	"github.com/FerretDB/FerretDB/internal/handlers/sjson"
+	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 )
 
@@ -28,6 +30,63 @@ type Settings struct {
 	Indexes         []IndexInfo `json:"indexes"`
 	CappedSize      int64       `json:"cappedSize"`
 	CappedDocuments int64       `json:"cappedDocuments"`
+
+	// Custom contains settings defined by the handler, or nil.
+	// It is stored in SJSON format, see [Settings.MarshalJSON].
+	Custom *types.Document `json:"-"`
+}
+
+// settingsJSON is used to marshal and unmarshal Settings.
+type settingsJSON struct {
+	Indexes         []IndexInfo     `json:"indexes"`
+	CappedSize      int64           `json:"cappedSize"`
+	CappedDocuments int64           `json:"cappedDocuments"`
+	Custom          json.RawMessage `json:"custom,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (s Settings) MarshalJSON() ([]byte, error) {
+	v := settingsJSON{
+		Indexes:         s.Indexes,
+		CappedSize:      s.CappedSize,
+		CappedDocuments: s.CappedDocuments,
+	}
+
+	if s.Custom != nil {
+		b, err := sjson.Marshal(s.Custom)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		v.Custom = b
+	}
+
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (s *Settings) UnmarshalJSON(b []byte) error {
+	var v settingsJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	*s = Settings{
+		Indexes:         v.Indexes,
+		CappedSize:      v.CappedSize,
+		CappedDocuments: v.CappedDocuments,
+	}
+
+	if v.Custom != nil {
+		doc, err := sjson.Unmarshal(v.Custom)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		s.Custom = doc
+	}
+
+	return nil
 }
 
 // IndexInfo represents information about a single index.
@@ -35,6 +94,72 @@ type IndexInfo struct {
 	Name   string         `json:"name"`
 	Key    []IndexKeyPair `json:"key"`
 	Unique bool           `json:"unique"`
+
+	// PartialFilterExpression is nil for regular indexes.
+	// It is stored in SJSON format, see [IndexInfo.MarshalJSON].
+	PartialFilterExpression *types.Document `json:"-"`
+
+	Text            bool   `json:"-"` // true for text indexes
+	DefaultLanguage string `json:"-"` // text index's default language
+}
+
+// indexInfoJSON is used to marshal and unmarshal IndexInfo.
+type indexInfoJSON struct {
+	Name                    string          `json:"name"`
+	Key                     []IndexKeyPair  `json:"key"`
+	Unique                  bool            `json:"unique"`
+	PartialFilterExpression json.RawMessage `json:"partialFilterExpression,omitempty"`
+	Text                    bool            `json:"text,omitempty"`
+	DefaultLanguage         string          `json:"default_language,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (index IndexInfo) MarshalJSON() ([]byte, error) {
+	v := indexInfoJSON{
+		Name:            index.Name,
+		Key:             index.Key,
+		Unique:          index.Unique,
+		Text:            index.Text,
+		DefaultLanguage: index.DefaultLanguage,
+	}
+
+	if index.PartialFilterExpression != nil {
+		b, err := sjson.Marshal(index.PartialFilterExpression)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		v.PartialFilterExpression = b
+	}
+
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (index *IndexInfo) UnmarshalJSON(b []byte) error {
+	var v indexInfoJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	*index = IndexInfo{
+		Name:            v.Name,
+		Key:             v.Key,
+		Unique:          v.Unique,
+		Text:            v.Text,
+		DefaultLanguage: v.DefaultLanguage,
+	}
+
+	if v.PartialFilterExpression != nil {
+		doc, err := sjson.Unmarshal(v.PartialFilterExpression)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		index.PartialFilterExpression = doc
+	}
+
+	return nil
 }
 
 // IndexKeyPair consists of a field name and a sort order that are part of the index.
@@ -52,13 +177,26 @@ func (s Settings) deepCopy() Settings {
 			Name:   index.Name,
 			Key:    slices.Clone(index.Key),
 			Unique: index.Unique,
+
+			Text:            index.Text,
+			DefaultLanguage: index.DefaultLanguage,
+		}
+
+		if index.PartialFilterExpression != nil {
+			indexes[i].PartialFilterExpression = index.PartialFilterExpression.DeepCopy()
 		}
 	}
 
+	var custom *types.Document
+	if s.Custom != nil {
+		custom = s.Custom.DeepCopy()
+	}
+
 	return Settings{
 		Indexes:         indexes,
 		CappedSize:      s.CappedSize,
 		CappedDocuments: s.CappedDocuments,
+		Custom:          custom,
 	}
 }
 
@@ -96,6 +234,10 @@ func (s *Settings) Scan(src any) error {
 
 // check interfaces
 var (
-	_ driver.Valuer = Settings{}
-	_ sql.Scanner   = (*Settings)(nil)
+	_ json.Marshaler   = IndexInfo{}
+	_ json.Unmarshaler = (*IndexInfo)(nil)
+	_ json.Marshaler   = Settings{}
+	_ json.Unmarshaler = (*Settings)(nil)
+	_ driver.Valuer    = Settings{}
+	_ sql.Scanner      = (*Settings)(nil)
 )
@@ -0,0 +1,167 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/util/fsql"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/observability"
+)
+
+// ValidateMetadata compares collections metadata with the actual SQLite schema of the given database.
+//
+// If repair is true, collections without tables are removed from the metadata,
+// and missing indexes are recreated.
+// Orphan tables are only reported.
+//
+// If database does not exist, (nil, nil) is returned.
+func (r *Registry) ValidateMetadata(ctx context.Context, dbName string, repair bool) ([]backends.MetadataProblem, error) {
+	defer observability.FuncCall(ctx)()
+
+	db := r.DatabaseGetExisting(ctx, dbName)
+	if db == nil {
+		return nil, nil
+	}
+
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	tables, err := schemaNames(ctx, db, "table")
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	indexes, err := schemaNames(ctx, db, "index")
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var res []backends.MetadataProblem
+
+	used := map[string]struct{}{metadataTableName: {}}
+
+	names := maps.Keys(r.colls[dbName])
+	sort.Strings(names)
+
+	for _, name := range names {
+		c := r.colls[dbName][name]
+		used[c.TableName] = struct{}{}
+
+		if _, ok := tables[c.TableName]; !ok {
+			problem := backends.MetadataProblem{
+				Type:       backends.MetadataProblemMissingTable,
+				Collection: c.Name,
+				Table:      c.TableName,
+			}
+
+			if repair {
+				q := fmt.Sprintf("DELETE FROM %q WHERE name = ?", metadataTableName)
+				if _, err = db.ExecContext(ctx, q, c.Name); err != nil {
+					return nil, lazyerrors.Error(err)
+				}
+
+				delete(r.colls[dbName], c.Name)
+				problem.Repaired = true
+			}
+
+			res = append(res, problem)
+
+			continue
+		}
+
+		for _, index := range c.Settings.Indexes {
+			if _, ok := indexes[indexName(c.TableName, index.Name)]; ok {
+				continue
+			}
+
+			problem := backends.MetadataProblem{
+				Type:       backends.MetadataProblemMissingIndex,
+				Collection: c.Name,
+				Table:      c.TableName,
+				Index:      index.Name,
+			}
+
+			if repair {
+				var q string
+				if q, err = indexQuery(c.TableName, index); err != nil {
+					return nil, lazyerrors.Error(err)
+				}
+
+				if _, err = db.ExecContext(ctx, q); err != nil {
+					return nil, lazyerrors.Error(err)
+				}
+
+				problem.Repaired = true
+			}
+
+			res = append(res, problem)
+		}
+	}
+
+	orphans := maps.Keys(tables)
+	sort.Strings(orphans)
+
+	for _, table := range orphans {
+		if _, ok := used[table]; ok {
+			continue
+		}
+
+		res = append(res, backends.MetadataProblem{
+			Type:  backends.MetadataProblemOrphanTable,
+			Table: table,
+		})
+	}
+
+	return res, nil
+}
+
+// schemaNames returns a set of names of the given type (table or index) in the database schema,
+// excluding internal SQLite objects.
+func schemaNames(ctx context.Context, db *fsql.DB, typ string) (map[string]struct{}, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name FROM sqlite_schema WHERE type = ?", typ)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+	defer rows.Close()
+
+	res := map[string]struct{}{}
+
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if strings.HasPrefix(name, reservedTablePrefix) {
+			continue
+		}
+
+		res[name] = struct{}{}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
@@ -19,6 +19,9 @@ import (
 
 	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/backends/sqlite/metadata"
+	"github.com/FerretDB/FerretDB/internal/handlers/sjson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
 // prepareSelectClause returns SELECT clause for default column of provided table name.
@@ -38,14 +41,40 @@ func prepareSelectClause(table string, capped, onlyRecordIDs bool) string {
 	return fmt.Sprintf(`SELECT %s FROM %q`, metadata.DefaultColumn, table)
 }
 
+// prepareWhereClause returns WHERE clause and its arguments for the given filter.
+// Only filters by a single string or ObjectID _id value are pushed down,
+// for other filters it returns an empty clause.
+func prepareWhereClause(filter *types.Document) (string, []any) {
+	if filter.Len() != 1 {
+		return "", nil
+	}
+
+	v, _ := filter.Get("_id")
+	switch v.(type) {
+	case string, types.ObjectID:
+		return fmt.Sprintf(` WHERE %s = ?`, metadata.IDColumn), []any{string(must.NotFail(sjson.MarshalSingleValue(v)))}
+	}
+
+	return "", nil
+}
+
 // prepareOrderByClause returns ORDER BY clause.
 //
 // For capped collection, it returns ORDER BY recordID only if sort field is nil.
+// Natural order is the rowid order (recordID is an alias for rowid in capped collections).
 func prepareOrderByClause(sort *backends.SortField, capped bool) string {
 	if sort == nil && capped {
 		return fmt.Sprintf(` ORDER BY %s`, metadata.RecordIDColumn)
 	}
 
+	if sort != nil && sort.Key == backends.NaturalSortKey {
+		if sort.Descending {
+			return ` ORDER BY rowid DESC`
+		}
+
+		return ` ORDER BY rowid`
+	}
+
 	// TODO https://github.com/FerretDB/FerretDB/issues/3181
 	return ""
 }
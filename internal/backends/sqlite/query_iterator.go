@@ -38,6 +38,8 @@ type queryIterator struct {
 	ctx           context.Context
 	rows          *fsql.Rows // protected by m
 	onlyRecordIDs bool
+	raw           bool
+	closeF        func() // called after rows are closed; may be nil
 	token         *resource.Token
 	m             sync.Mutex
 }
@@ -63,6 +65,24 @@ func newQueryIterator(ctx context.Context, rows *fsql.Rows, onlyRecordIDs bool)
 	return iter
 }
 
+// newRawQueryIterator returns a new queryIterator for the given rows of the raw SQL query.
+//
+// Each row should have a single column with a JSON object.
+// Closing rules are the same as for newQueryIterator;
+// non-nil closeF is called once after rows are closed.
+func newRawQueryIterator(ctx context.Context, rows *fsql.Rows, closeF func()) types.DocumentsIterator {
+	iter := &queryIterator{
+		ctx:    ctx,
+		rows:   rows,
+		raw:    true,
+		closeF: closeF,
+		token:  resource.NewToken(),
+	}
+	resource.Track(iter, iter.token)
+
+	return iter
+}
+
 // Next implements iterator.Interface.
 func (iter *queryIterator) Next() (struct{}, *types.Document, error) {
 	defer observability.FuncCall(iter.ctx)()
@@ -105,6 +125,13 @@ func (iter *queryIterator) Next() (struct{}, *types.Document, error) {
 	var dest []any
 
 	switch {
+	case iter.raw:
+		if len(columns) != 1 {
+			iter.close()
+			return unused, nil, lazyerrors.Errorf("raw query should return a single column, got %d", len(columns))
+		}
+
+		dest = []any{&b}
 	case slices.Equal(columns, []string{metadata.RecordIDColumn, metadata.DefaultColumn}):
 		dest = []any{&recordID, &b}
 	case slices.Equal(columns, []string{metadata.RecordIDColumn}):
@@ -120,6 +147,16 @@ func (iter *queryIterator) Next() (struct{}, *types.Document, error) {
 		return unused, nil, lazyerrors.Error(err)
 	}
 
+	if iter.raw {
+		var doc *types.Document
+		if doc, err = sjson.UnmarshalRelaxed(b); err != nil {
+			iter.close()
+			return unused, nil, lazyerrors.Error(err)
+		}
+
+		return unused, doc, nil
+	}
+
 	doc := must.NotFail(types.NewDocument())
 
 	if !iter.onlyRecordIDs {
@@ -155,6 +192,11 @@ func (iter *queryIterator) close() {
 		iter.rows = nil
 	}
 
+	if iter.closeF != nil {
+		iter.closeF()
+		iter.closeF = nil
+	}
+
 	resource.Untrack(iter, iter.token)
 }
 
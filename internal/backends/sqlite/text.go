@@ -0,0 +1,205 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/sqlite/metadata"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// textSearchIterator filters documents returned by the underlying iterator by the text search
+// and adds their text scores to the scores map.
+//
+// SQLite does not provide full-text search for JSON values, so matching is done in Go.
+// Words are compared case-insensitively without stemming, regardless of the language;
+// phrases are matched as substrings.
+// The text score is the number of matched words.
+type textSearchIterator struct {
+	iter          types.DocumentsIterator
+	ts            *backends.TextSearch
+	scores        map[*types.Document]float64
+	paths         []types.Path
+	onlyRecordIDs bool
+}
+
+// newTextSearchIterator returns a new textSearchIterator for the given text index
+// that adds text scores to the given map.
+//
+// The underlying iterator should return whole documents even if onlyRecordIDs is true;
+// in that case, returned documents have only record IDs.
+func newTextSearchIterator(iter types.DocumentsIterator, ts *backends.TextSearch, index *metadata.IndexInfo, onlyRecordIDs bool, scores map[*types.Document]float64) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	paths := make([]types.Path, len(index.Key))
+
+	for i, key := range index.Key {
+		path, err := types.NewPathFromString(key.Field)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		paths[i] = path
+	}
+
+	return &textSearchIterator{
+		iter:          iter,
+		ts:            ts,
+		scores:        scores,
+		paths:         paths,
+		onlyRecordIDs: onlyRecordIDs,
+	}, nil
+}
+
+// Next implements iterator.Interface.
+func (iter *textSearchIterator) Next() (struct{}, *types.Document, error) {
+	var unused struct{}
+
+	for {
+		_, doc, err := iter.iter.Next()
+		if err != nil {
+			return unused, nil, lazyerrors.Error(err)
+		}
+
+		score, ok := textSearchMatch(iter.ts, iter.texts(doc))
+		if !ok {
+			continue
+		}
+
+		if iter.onlyRecordIDs {
+			recordID := doc.RecordID()
+			doc = must.NotFail(types.NewDocument())
+			doc.SetRecordID(recordID)
+		}
+
+		iter.scores[doc] = score
+
+		return unused, doc, nil
+	}
+}
+
+// Close implements iterator.Interface.
+func (iter *textSearchIterator) Close() {
+	iter.iter.Close()
+}
+
+// texts returns string values of the text index fields of the given document,
+// including strings in arrays.
+func (iter *textSearchIterator) texts(doc *types.Document) []string {
+	var res []string
+
+	for _, path := range iter.paths {
+		v, err := doc.GetByPath(path)
+		if err != nil {
+			continue
+		}
+
+		switch v := v.(type) {
+		case string:
+			res = append(res, v)
+
+		case *types.Array:
+			for i := 0; i < v.Len(); i++ {
+				if s, ok := must.NotFail(v.Get(i)).(string); ok {
+					res = append(res, s)
+				}
+			}
+		}
+	}
+
+	return res
+}
+
+// textSearchMatch returns the text score and true if the given texts match the text search.
+func textSearchMatch(ts *backends.TextSearch, texts []string) (float64, bool) {
+	if len(ts.Terms) == 0 && len(ts.Phrases) == 0 {
+		return 0, false
+	}
+
+	words := make(map[string]int)
+	lowerTexts := make([]string, len(texts))
+
+	for i, text := range texts {
+		lowerTexts[i] = strings.ToLower(text)
+
+		for _, word := range textSearchWords(text) {
+			words[word]++
+		}
+	}
+
+	for _, negation := range ts.Negations {
+		for _, word := range textSearchWords(negation) {
+			if words[word] > 0 {
+				return 0, false
+			}
+		}
+	}
+
+	var score float64
+
+	for _, phrase := range ts.Phrases {
+		phrase = strings.ToLower(phrase)
+
+		var found bool
+
+		for _, text := range lowerTexts {
+			if strings.Contains(text, phrase) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return 0, false
+		}
+
+		for _, word := range textSearchWords(phrase) {
+			score += float64(words[word])
+		}
+	}
+
+	var termFound bool
+
+	for _, term := range ts.Terms {
+		for _, word := range textSearchWords(term) {
+			if n := words[word]; n > 0 {
+				termFound = true
+				score += float64(n)
+			}
+		}
+	}
+
+	// if there are phrases, terms affect only the score
+	if len(ts.Phrases) == 0 && !termFound {
+		return 0, false
+	}
+
+	return score, true
+}
+
+// textSearchWords splits the given text into lowercase words.
+func textSearchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// check interfaces
+var (
+	_ types.DocumentsIterator = (*textSearchIterator)(nil)
+)
@@ -29,6 +29,10 @@ var collectionNameRe = regexp.MustCompile("^[^\\.$\x00][^$\x00]{0,234}$")
 // ReservedPrefix for names: databases, collections, schemas, tables, indexes, columns, etc.
 const ReservedPrefix = "_ferretdb_"
 
+// TrashPrefix for names of dropped collections kept in the trash.
+// Collection names with that prefix are allowed only in parameters with Trash field set.
+const TrashPrefix = ReservedPrefix + "trash_"
+
 // validateDatabaseName checks that database name is valid for FerretDB.
 //
 // It follows MongoDB restrictions plus
@@ -56,18 +60,23 @@ func validateDatabaseName(name string) error {
 // It follows MongoDB restrictions plus:
 //   - allows only UTF-8 characters;
 //   - disallows '.' prefix (MongoDB fails to work with such collections correctly too);
-//   - disallows `_ferretdb_` prefix.
+//   - disallows `_ferretdb_` prefix;
+//     `_ferretdb_trash_` prefix is allowed only if trash is true.
 //
 // That validation is quite lax because
 // we expect it to be hard for users to change collection names in their software.
 //
 // Backends can do their own additional validation.
-func validateCollectionName(name string) error {
+func validateCollectionName(name string, trash bool) error {
 	if !collectionNameRe.MatchString(name) {
 		return NewError(ErrorCodeCollectionNameIsInvalid, nil)
 	}
 
-	if strings.HasPrefix(name, ReservedPrefix) || strings.HasPrefix(name, "system.") {
+	if strings.HasPrefix(name, ReservedPrefix) && !(trash && strings.HasPrefix(name, TrashPrefix)) {
+		return NewError(ErrorCodeCollectionNameIsInvalid, nil)
+	}
+
+	if strings.HasPrefix(name, "system.") {
 		return NewError(ErrorCodeCollectionNameIsInvalid, nil)
 	}
 
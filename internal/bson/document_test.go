@@ -15,9 +15,14 @@
 package bson
 
 import (
+	"bufio"
+	"bytes"
+	"io"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/require"
+
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/util/testutil"
@@ -197,3 +202,41 @@ func FuzzDocument(f *testing.F) {
 func BenchmarkDocument(b *testing.B) {
 	benchmark(b, documentTestCases, func() bsontype { return new(Document) })
 }
+
+func BenchmarkDocumentLarge(b *testing.B) {
+	doc := must.NotFail(ConvertDocument(testutil.LargeDocument(1000)))
+	raw := must.NotFail(doc.MarshalBinary())
+
+	b.Run("MarshalBinary", func(b *testing.B) {
+		var err error
+
+		b.ReportAllocs()
+		b.SetBytes(int64(len(raw)))
+
+		for i := 0; i < b.N; i++ {
+			_, err = doc.MarshalBinary()
+		}
+
+		b.StopTimer()
+
+		require.NoError(b, err)
+	})
+
+	b.Run("ReadFrom", func(b *testing.B) {
+		br := bytes.NewReader(raw)
+		var readErr, seekErr error
+
+		b.ReportAllocs()
+		b.SetBytes(int64(len(raw)))
+
+		for i := 0; i < b.N; i++ {
+			_, seekErr = br.Seek(0, io.SeekStart)
+			readErr = new(Document).ReadFrom(bufio.NewReader(br))
+		}
+
+		b.StopTimer()
+
+		require.NoError(b, seekErr)
+		require.NoError(b, readErr)
+	})
+}
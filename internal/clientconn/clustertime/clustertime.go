@@ -0,0 +1,200 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clustertime provides cluster time for causal consistency.
+//
+// Drivers with causally consistent sessions read $clusterTime and operationTime fields of responses
+// and send them back in $clusterTime field and readConcern's afterClusterTime.
+// FerretDB is a single node, so all writes are visible to all following reads,
+// and cluster time is only used to keep drivers happy.
+//
+// Cluster times are signed with HMAC-SHA1 keys, like MongoDB does with keys stored in admin.system.keys.
+// Keys are kept in memory only, so cluster times signed before restart are not trusted after it.
+package clustertime
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// Clock is a hybrid logical clock that produces monotonically increasing cluster times.
+//
+// Cluster time is a BSON Timestamp: seconds of the wall clock and a counter within that second.
+// If the wall clock goes backward or an observed cluster time is ahead of it,
+// the seconds part stays the same and the counter is increased.
+//
+// Nil value is valid and disables cluster time.
+// Methods are safe for concurrent use.
+type Clock struct {
+	now func() time.Time
+
+	m    sync.Mutex
+	last types.Timestamp
+	key  *key           // current signing key
+	keys map[int64]*key // all keys by ID, including expired ones, for signature validation
+}
+
+// NewClock returns a new Clock that uses the given function (or time.Now if nil) as a wall clock.
+func NewClock(now func() time.Time) *Clock {
+	if now == nil {
+		now = time.Now
+	}
+
+	k := newKey(now())
+
+	return &Clock{
+		now:  now,
+		key:  k,
+		keys: map[int64]*key{k.id: k},
+	}
+}
+
+// Tick returns the next cluster time.
+func (c *Clock) Tick() types.Timestamp {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	wall := types.NewTimestamp(c.now(), 0)
+
+	if wall > c.last {
+		c.last = wall
+	}
+
+	// the counter starts at 1, like in MongoDB
+	c.last++
+
+	return c.last
+}
+
+// sign returns the signature of the given cluster time and the ID of the key used.
+//
+// The current key is replaced by a new one when the cluster time reaches its expiration time.
+func (c *Clock) sign(ts types.Timestamp) ([]byte, int64) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if ts >= c.key.expiresAt {
+		c.key = newKey(c.now())
+		c.keys[c.key.id] = c.key
+	}
+
+	return c.key.sign(ts), c.key.id
+}
+
+// verify returns true if the given signature of the cluster time was produced by one of the clock's keys.
+func (c *Clock) verify(ts types.Timestamp, hash []byte, keyID int64) bool {
+	c.m.Lock()
+	k := c.keys[keyID]
+	c.m.Unlock()
+
+	if k == nil {
+		return false
+	}
+
+	return bytes.Equal(k.sign(ts), hash)
+}
+
+// Observe advances the clock to the given cluster time received from the client, if it is ahead.
+func (c *Clock) Observe(ts types.Timestamp) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if ts > c.last {
+		c.last = ts
+	}
+}
+
+// ObserveRequest advances the clock to cluster times in the request document:
+// gossiped $clusterTime and readConcern's afterClusterTime.
+//
+// Gossiped cluster times with invalid signatures are ignored, so clients can't move the clock arbitrarily.
+// Fields of unexpected types are ignored too; handlers validate readConcern if they use it.
+func (c *Clock) ObserveRequest(doc *types.Document) {
+	if c == nil {
+		return
+	}
+
+	if ts, ok := timestampField(doc, "$clusterTime", "clusterTime"); ok && c.verifyGossiped(doc, ts) {
+		c.Observe(ts)
+	}
+
+	if ts, ok := timestampField(doc, "readConcern", "afterClusterTime"); ok {
+		c.Observe(ts)
+	}
+}
+
+// verifyGossiped returns true if the $clusterTime field of the request document
+// contains a valid signature of the given cluster time.
+func (c *Clock) verifyGossiped(doc *types.Document, ts types.Timestamp) bool {
+	v, _ := doc.Get("$clusterTime")
+	v, _ = v.(*types.Document).Get("signature")
+
+	signature, ok := v.(*types.Document)
+	if !ok {
+		return false
+	}
+
+	v, _ = signature.Get("hash")
+	hash, ok := v.(types.Binary)
+	if !ok {
+		return false
+	}
+
+	v, _ = signature.Get("keyId")
+	keyID, ok := v.(int64)
+	if !ok {
+		return false
+	}
+
+	return c.verify(ts, hash.B, keyID)
+}
+
+// timestampField returns the timestamp value of the given field of the given embedded document, if any.
+func timestampField(doc *types.Document, embedded, field string) (types.Timestamp, bool) {
+	v, _ := doc.Get(embedded)
+
+	d, ok := v.(*types.Document)
+	if !ok {
+		return 0, false
+	}
+
+	v, _ = d.Get(field)
+	ts, ok := v.(types.Timestamp)
+
+	return ts, ok
+}
+
+// SetResponse sets $clusterTime and operationTime fields of the response document to the next cluster time.
+// Cluster time is signed, so clients can gossip it back.
+func (c *Clock) SetResponse(doc *types.Document) {
+	if c == nil {
+		return
+	}
+
+	ts := c.Tick()
+	hash, keyID := c.sign(ts)
+
+	doc.Set("$clusterTime", must.NotFail(types.NewDocument(
+		"clusterTime", ts,
+		"signature", must.NotFail(types.NewDocument(
+			"hash", types.Binary{Subtype: types.BinaryGeneric, B: hash},
+			"keyId", keyID,
+		)),
+	)))
+	doc.Set("operationTime", ts)
+}
@@ -0,0 +1,145 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustertime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestClock(t *testing.T) {
+	t.Parallel()
+
+	wall := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	c := NewClock(func() time.Time { return wall })
+
+	assert.Equal(t, types.NewTimestamp(wall, 1), c.Tick())
+	assert.Equal(t, types.NewTimestamp(wall, 2), c.Tick())
+
+	// wall clock goes backward
+	wall = wall.Add(-time.Minute)
+	assert.Equal(t, types.NewTimestamp(wall.Add(time.Minute), 3), c.Tick())
+
+	// observed time is ahead
+	ahead := types.NewTimestamp(wall.Add(time.Hour), 42)
+	c.Observe(ahead)
+	assert.Equal(t, ahead+1, c.Tick())
+
+	// observed time is behind
+	c.Observe(types.NewTimestamp(wall, 1))
+	assert.Equal(t, ahead+2, c.Tick())
+
+	// wall clock catches up
+	wall = wall.Add(2 * time.Hour)
+	assert.Equal(t, types.NewTimestamp(wall, 1), c.Tick())
+}
+
+func TestRequestResponse(t *testing.T) {
+	t.Parallel()
+
+	wall := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	c := NewClock(func() time.Time { return wall })
+
+	gossiped := types.NewTimestamp(wall.Add(time.Second), 5)
+	after := types.NewTimestamp(wall.Add(time.Minute), 7)
+
+	gossip := func(ts types.Timestamp, hash []byte, keyID int64) *types.Document {
+		return must.NotFail(types.NewDocument(
+			"find", "test",
+			"$clusterTime", must.NotFail(types.NewDocument(
+				"clusterTime", ts,
+				"signature", must.NotFail(types.NewDocument(
+					"hash", types.Binary{Subtype: types.BinaryGeneric, B: hash},
+					"keyId", keyID,
+				)),
+			)),
+		))
+	}
+
+	// unsigned and wrongly signed cluster times are ignored
+	c.ObserveRequest(must.NotFail(types.NewDocument(
+		"find", "test",
+		"$clusterTime", must.NotFail(types.NewDocument("clusterTime", gossiped)),
+	)))
+	c.ObserveRequest(gossip(gossiped, make([]byte, 20), 0))
+
+	hash, keyID := c.sign(gossiped)
+	c.ObserveRequest(gossip(gossiped+1, hash, keyID))
+	assert.Equal(t, types.NewTimestamp(wall, 1), c.Tick())
+
+	c.ObserveRequest(gossip(gossiped, hash, keyID))
+	assert.Equal(t, gossiped+1, c.Tick())
+
+	c.ObserveRequest(must.NotFail(types.NewDocument(
+		"find", "test",
+		"readConcern", must.NotFail(types.NewDocument("afterClusterTime", after)),
+	)))
+
+	// invalid values are ignored
+	c.ObserveRequest(must.NotFail(types.NewDocument("find", "test", "readConcern", "local")))
+
+	doc := must.NotFail(types.NewDocument("ok", float64(1)))
+	c.SetResponse(doc)
+
+	assert.Equal(t, []string{"ok", "$clusterTime", "operationTime"}, doc.Keys())
+	assert.Equal(t, after+1, must.NotFail(doc.Get("operationTime")))
+	ct := must.NotFail(doc.Get("$clusterTime")).(*types.Document)
+	assert.Equal(t, after+1, must.NotFail(ct.Get("clusterTime")))
+
+	// response cluster time could be gossiped back
+	signature := must.NotFail(ct.Get("signature")).(*types.Document)
+	assert.True(t, c.verify(
+		after+1,
+		must.NotFail(signature.Get("hash")).(types.Binary).B,
+		must.NotFail(signature.Get("keyId")).(int64),
+	))
+
+	// nil clock does nothing
+	var nilClock *Clock
+	nilClock.ObserveRequest(doc)
+
+	doc = must.NotFail(types.NewDocument("ok", float64(1)))
+	nilClock.SetResponse(doc)
+	assert.Equal(t, []string{"ok"}, doc.Keys())
+}
+
+func TestKeyRotation(t *testing.T) {
+	t.Parallel()
+
+	wall := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	c := NewClock(func() time.Time { return wall })
+
+	ts := c.Tick()
+	hash, keyID := c.sign(ts)
+	assert.Equal(t, int64(types.NewTimestamp(wall, 0)), keyID)
+	assert.Len(t, hash, 20)
+
+	wall = wall.Add(keyValidity)
+
+	newTS := c.Tick()
+	newHash, newKeyID := c.sign(newTS)
+	assert.Equal(t, int64(types.NewTimestamp(wall, 0)), newKeyID)
+
+	// cluster times signed with both keys are valid
+	assert.True(t, c.verify(ts, hash, keyID))
+	assert.True(t, c.verify(newTS, newHash, newKeyID))
+	assert.False(t, c.verify(newTS, hash, keyID))
+	assert.False(t, c.verify(ts, hash, 42))
+}
@@ -0,0 +1,58 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustertime
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // HMAC-SHA1 is used by MongoDB for cluster time signatures
+	"encoding/binary"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// keyValidity is the time after which a new key is generated, the same as MongoDB's default.
+const keyValidity = 90 * 24 * time.Hour
+
+// key represents a cluster time signing key, like a document of MongoDB's admin.system.keys collection.
+type key struct {
+	id        int64
+	secret    []byte
+	expiresAt types.Timestamp
+}
+
+// newKey returns a new random key created at the given time.
+//
+// Like in MongoDB, key ID is the creation time as BSON Timestamp.
+func newKey(now time.Time) *key {
+	secret := make([]byte, sha1.Size)
+	must.NotFail(rand.Read(secret))
+
+	return &key{
+		id:        int64(types.NewTimestamp(now, 0)),
+		secret:    secret,
+		expiresAt: types.NewTimestamp(now.Add(keyValidity), 0),
+	}
+}
+
+// sign returns HMAC-SHA1 of the given cluster time.
+func (k *key) sign(ts types.Timestamp) []byte {
+	h := hmac.New(sha1.New, k.secret)
+	must.NotFail(h.Write(binary.LittleEndian.AppendUint64(nil, uint64(ts))))
+
+	return h.Sum(nil)
+}
@@ -27,6 +27,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime/pprof"
+	"slices"
 	"sync/atomic"
 	"time"
 
@@ -34,16 +35,20 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
+	"github.com/FerretDB/FerretDB/internal/clientconn/clustertime"
 	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
 	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
+	"github.com/FerretDB/FerretDB/internal/clientconn/failpoints"
 	"github.com/FerretDB/FerretDB/internal/handlers"
 	"github.com/FerretDB/FerretDB/internal/handlers/commoncommands"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
 	"github.com/FerretDB/FerretDB/internal/handlers/proxy"
 	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/ctxutil"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/util/observability"
+	"github.com/FerretDB/FerretDB/internal/util/sqllog"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
@@ -71,6 +76,9 @@ var AllModes = []string{
 	string(DiffProxyMode),
 }
 
+// errCloseConnection is returned by command handling when connection should be closed without response.
+var errCloseConnection = errors.New("connection closed by fail point")
+
 // conn represents client connection.
 type conn struct {
 	netConn        net.Conn
@@ -78,8 +86,11 @@ type conn struct {
 	l              *zap.SugaredLogger
 	h              handlers.Interface
 	m              *connmetrics.ConnMetrics
+	failPoints     *failpoints.Registry
+	clusterTime    *clustertime.Clock
 	proxy          *proxy.Router
 	lastRequestID  atomic.Int32
+	sqlLog         sqllog.Mode
 	testRecordsDir string // if empty, no records are created
 }
 
@@ -90,7 +101,10 @@ type newConnOpts struct {
 	l              *zap.Logger
 	handler        handlers.Interface
 	connMetrics    *connmetrics.ConnMetrics
+	failPoints     *failpoints.Registry
+	clusterTime    *clustertime.Clock
 	proxyAddr      string
+	sqlLog         sqllog.Mode
 	testRecordsDir string // if empty, no records are created
 }
 
@@ -117,7 +131,10 @@ func newConn(opts *newConnOpts) (*conn, error) {
 		l:              opts.l.Sugar(),
 		h:              opts.handler,
 		m:              opts.connMetrics,
+		failPoints:     opts.failPoints,
+		clusterTime:    opts.clusterTime,
 		proxy:          p,
+		sqlLog:         opts.sqlLog,
 		testRecordsDir: opts.testRecordsDir,
 	}, nil
 }
@@ -242,6 +259,22 @@ func (c *conn) run(ctx context.Context) (err error) {
 		var validationErr *wire.ValidationError
 
 		reqHeader, reqBody, err = wire.ReadMessage(bufr)
+
+		// decompress request; the response is compressed with the same compressor
+		var compressor wire.Compressor
+		var compressedReqLen int32
+		if compressed, ok := reqBody.(*wire.OpCompressed); ok && err == nil {
+			compressor = compressed.Compressor
+			compressedReqLen = reqHeader.MessageLength
+
+			if compressor != wire.CompressorNoop && !slices.Contains(connInfo.Compressors(), compressor.String()) {
+				err = lazyerrors.Errorf("received message compressed with %s that was not negotiated", compressor)
+				return
+			}
+
+			reqHeader, reqBody, err = wire.Decompress(reqHeader, compressed)
+		}
+
 		if err != nil && errors.As(err, &validationErr) {
 			// Currently, we respond with OP_MSG containing an error and don't close the connection.
 			// That's probably not right. First, we always respond with OP_MSG, even to OP_QUERY.
@@ -285,6 +318,12 @@ func (c *conn) run(ctx context.Context) (err error) {
 		c.l.Debugf("Request header: %s", reqHeader)
 		c.l.Debugf("Request message:\n%s\n\n\n", reqBody)
 
+		// clients set moreToCome flag for unacknowledged (w:0) writes and do not expect a reply
+		var moreToCome bool
+		if msg, ok := reqBody.(*wire.OpMsg); ok {
+			moreToCome = msg.FlagBits.FlagSet(wire.OpMsgMoreToCome)
+		}
+
 		// diffLogLevel provides the level of logging for the diff between the "normal" and "proxy" responses.
 		// It is set to the highest level of logging used to log response.
 		var diffLogLevel zapcore.Level
@@ -304,13 +343,31 @@ func (c *conn) run(ctx context.Context) (err error) {
 
 		// handle request unless we are in proxy mode
 		var resCloseConn bool
+		command := "unknown"
 		if c.mode != ProxyMode {
-			resHeader, resBody, resCloseConn = c.route(ctx, reqHeader, reqBody)
+			resHeader, resBody, command, resCloseConn = c.route(ctx, reqHeader, reqBody)
+
+			// there is no response to send or log, for example, because of the fail point
+			if resCloseConn && resBody == nil {
+				err = errCloseConnection
+				return
+			}
+
 			if level := c.logResponse("Response", resHeader, resBody, resCloseConn); level > diffLogLevel {
 				diffLogLevel = level
 			}
 		}
 
+		// the request was handled and counted in metrics, but there is no reply to send, log, or diff
+		if moreToCome {
+			if resCloseConn {
+				err = errors.New("fatal error")
+				return
+			}
+
+			continue
+		}
+
 		// log proxy response after the normal response to make it less confusing
 		if c.mode != NormalMode {
 			if level := c.logResponse("Proxy response", proxyHeader, proxyBody, false); level > diffLogLevel {
@@ -368,6 +425,23 @@ func (c *conn) run(ctx context.Context) (err error) {
 			panic("no response to send to client")
 		}
 
+		if compressor != wire.CompressorNoop {
+			uncompressedResLen := resHeader.MessageLength
+
+			if resHeader, resBody, err = wire.Compress(resHeader, resBody, compressor); err != nil {
+				return
+			}
+
+			c.m.MessageCompressed(
+				connmetrics.DirectionRequest, compressor.String(), command,
+				int(reqHeader.MessageLength), int(compressedReqLen),
+			)
+			c.m.MessageCompressed(
+				connmetrics.DirectionResponse, compressor.String(), command,
+				int(uncompressedResLen), int(resHeader.MessageLength),
+			)
+		}
+
 		if err = wire.WriteMessage(bufw, resHeader, resBody); err != nil {
 			return
 		}
@@ -391,8 +465,9 @@ func (c *conn) run(ctx context.Context) (err error) {
 // They also should not use recover(). That allows us to use fuzzing.
 //
 // Returned resBody can be nil.
-func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wire.MsgBody) (resHeader *wire.MsgHeader, resBody wire.MsgBody, closeConn bool) { //nolint:lll // argument list is too long
-	var command, result, argument string
+// Returned command is the name of the handled command, or "unknown".
+func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wire.MsgBody) (resHeader *wire.MsgHeader, resBody wire.MsgBody, command string, closeConn bool) { //nolint:lll // argument list is too long
+	var result, argument string
 	defer func() {
 		if result == "" {
 			result = "panic"
@@ -405,6 +480,9 @@ func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wir
 		c.m.Responses.WithLabelValues(resHeader.OpCode.String(), command, argument, result).Inc()
 	}()
 
+	// correlate SQL statements generated by backends with the request
+	ctx = sqllog.Ctx(ctx, reqHeader.RequestID, c.sqlLog)
+
 	resHeader = new(wire.MsgHeader)
 	var err error
 	switch reqHeader.OpCode {
@@ -418,6 +496,8 @@ func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wir
 		resHeader.OpCode = wire.OpCodeMsg
 
 		if err == nil {
+			c.clusterTime.ObserveRequest(document)
+
 			// do not store typed nil in interface, it makes it non-nil
 
 			var resMsg *wire.OpMsg
@@ -468,6 +548,13 @@ func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wir
 
 	c.m.Requests.WithLabelValues(reqHeader.OpCode.String(), command).Inc()
 
+	if errors.Is(err, errCloseConnection) {
+		closeConn = true
+		result = "fail-point"
+
+		return
+	}
+
 	// set body for error
 	if err != nil {
 		switch resHeader.OpCode {
@@ -493,9 +580,30 @@ func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wir
 				argument = info.Argument
 			}
 
-		case wire.OpCodeQuery:
-			fallthrough
 		case wire.OpCodeReply:
+			// legacy clients expect OP_QUERY commands to fail the same way as OP_MSG ones,
+			// with a single error document and without QueryFailure flag
+			protoErr := commonerrors.ProtocolError(err)
+
+			resBody = &wire.OpReply{
+				NumberReturned: 1,
+				Documents:      []*types.Document{protoErr.Document()},
+			}
+
+			switch protoErr := protoErr.(type) {
+			case *commonerrors.CommandError:
+				result = protoErr.Code().String()
+			case *commonerrors.WriteErrors:
+				result = "write-error"
+			default:
+				panic(fmt.Errorf("unexpected error type %T", protoErr))
+			}
+
+			if info := protoErr.Info(); info != nil {
+				argument = info.Argument
+			}
+
+		case wire.OpCodeQuery:
 			fallthrough
 		case wire.OpCodeUpdate:
 			fallthrough
@@ -533,6 +641,14 @@ func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wir
 		}
 	}
 
+	if resHeader.OpCode == wire.OpCodeMsg && c.clusterTime != nil {
+		resMsg := resBody.(*wire.OpMsg)
+		doc := must.NotFail(resMsg.Document())
+
+		c.clusterTime.SetResponse(doc)
+		must.NoError(resMsg.SetSections(wire.OpMsgSection{Documents: []*types.Document{doc}}))
+	}
+
 	// Don't call MarshalBinary there. Fix header in the caller?
 	// TODO https://github.com/FerretDB/FerretDB/issues/273
 	b, err := resBody.MarshalBinary()
@@ -542,6 +658,8 @@ func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wir
 	}
 	resHeader.MessageLength = int32(wire.MsgHeaderLen + len(b))
 
+	c.m.MessageHandled(reqHeader.OpCode.String(), command, int(reqHeader.MessageLength), int(resHeader.MessageLength))
+
 	resHeader.RequestID = c.lastRequestID.Add(1)
 	resHeader.ResponseTo = reqHeader.RequestID
 
@@ -564,6 +682,13 @@ func (c *conn) handleOpMsg(ctx context.Context, msg *wire.OpMsg, command string)
 			ctx = pprof.WithLabels(ctx, pprof.Labels("command", command))
 			pprof.SetGoroutineLabels(ctx)
 
+			// fail points can't be used to fail their own configuration
+			if command != "configureFailPoint" {
+				if data := c.failPoints.Check(failpoints.FailCommand, command); data != nil {
+					return c.failCommand(ctx, msg, cmd.Handler, data)
+				}
+			}
+
 			return cmd.Handler(c.h, ctx, msg)
 		}
 	}
@@ -573,6 +698,43 @@ func (c *conn) handleOpMsg(ctx context.Context, msg *wire.OpMsg, command string)
 	return nil, commonerrors.NewCommandErrorMsg(commonerrors.ErrCommandNotFound, errMsg)
 }
 
+// failCommand handles OP_MSG request affected by the failCommand fail point.
+func (c *conn) failCommand(ctx context.Context, msg *wire.OpMsg, h func(handlers.Interface, context.Context, *wire.OpMsg) (*wire.OpMsg, error), data *failpoints.Data) (*wire.OpMsg, error) { //nolint:lll // argument list is too long
+	if data.BlockConnection {
+		ctxutil.Sleep(ctx, data.BlockTime)
+	}
+
+	if data.CloseConnection {
+		return nil, errCloseConnection
+	}
+
+	if data.ErrorCode != 0 {
+		return nil, commonerrors.NewCommandErrorMsg(
+			commonerrors.ErrorCode(data.ErrorCode),
+			"Failing command via 'failCommand' failpoint",
+		)
+	}
+
+	res, err := h(c.h, ctx, msg)
+	if err != nil || data.WriteConcernError == nil {
+		return res, err
+	}
+
+	doc, err := res.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	doc.Set("writeConcernError", data.WriteConcernError.DeepCopy())
+
+	var reply wire.OpMsg
+	if err = reply.SetSections(wire.OpMsgSection{Documents: []*types.Document{doc}}); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &reply, nil
+}
+
 // logResponse logs response's header and body and returns the log level that was used.
 //
 // The param `who` will be used in logs and should represent the type of the response,
@@ -0,0 +1,196 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/clustertime"
+	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
+	"github.com/FerretDB/FerretDB/internal/clientconn/failpoints"
+	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// testHandler handles insert and ping commands; other methods are not implemented.
+type testHandler struct {
+	handlers.Interface
+	inserted atomic.Int32
+}
+
+// MsgInsert implements handlers.Interface.
+func (h *testHandler) MsgInsert(context.Context, *wire.OpMsg) (*wire.OpMsg, error) {
+	h.inserted.Add(1)
+
+	return okMsg(), nil
+}
+
+// MsgPing implements handlers.Interface.
+func (h *testHandler) MsgPing(context.Context, *wire.OpMsg) (*wire.OpMsg, error) {
+	return okMsg(), nil
+}
+
+// okMsg returns a reply with a single `{ok: 1}` document.
+func okMsg() *wire.OpMsg {
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument("ok", float64(1)))},
+	}))
+
+	return &reply
+}
+
+// sendMsg writes OP_MSG request with the given document to the connection.
+func sendMsg(t *testing.T, bufw *bufio.Writer, requestID int32, flags wire.OpMsgFlags, doc *types.Document) {
+	t.Helper()
+
+	msg := &wire.OpMsg{FlagBits: flags}
+	require.NoError(t, msg.SetSections(wire.OpMsgSection{Documents: []*types.Document{doc}}))
+
+	header := &wire.MsgHeader{
+		MessageLength: int32(wire.MsgHeaderLen + len(must.NotFail(msg.MarshalBinary()))),
+		RequestID:     requestID,
+		OpCode:        wire.OpCodeMsg,
+	}
+
+	require.NoError(t, wire.WriteMessage(bufw, header, msg))
+	require.NoError(t, bufw.Flush())
+}
+
+func TestMoreToCome(t *testing.T) {
+	t.Parallel()
+
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+
+	h := new(testHandler)
+	m := connmetrics.NewListenerMetrics().ConnMetrics
+
+	c, err := newConn(&newConnOpts{
+		netConn:     server,
+		mode:        NormalMode,
+		l:           zap.NewNop(),
+		handler:     h,
+		connMetrics: m,
+		failPoints:  failpoints.NewRegistry(),
+	})
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- c.run(context.Background()) }()
+
+	bufw := bufio.NewWriter(client)
+	send := func(requestID int32, flags wire.OpMsgFlags, doc *types.Document) {
+		sendMsg(t, bufw, requestID, flags, doc)
+	}
+
+	unacknowledged := must.NotFail(types.NewDocument(
+		"insert", "test",
+		"documents", must.NotFail(types.NewArray(must.NotFail(types.NewDocument("_id", int32(1))))),
+		"writeConcern", must.NotFail(types.NewDocument("w", int32(0))),
+		"$db", "test",
+	))
+
+	send(1, wire.OpMsgFlags(wire.OpMsgMoreToCome), unacknowledged)
+	send(2, wire.OpMsgFlags(wire.OpMsgMoreToCome), unacknowledged)
+	send(3, 0, must.NotFail(types.NewDocument("ping", int32(1), "$db", "test")))
+
+	// the first reply is for ping
+	header, _, err := wire.ReadMessage(bufio.NewReader(client))
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), header.ResponseTo)
+
+	assert.Equal(t, int32(2), h.inserted.Load())
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.Requests.WithLabelValues("OP_MSG", "insert")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.Responses.WithLabelValues("OP_MSG", "insert", "unknown", "ok")))
+
+	client.Close()
+	require.Error(t, <-done)
+}
+
+func TestClusterTime(t *testing.T) {
+	t.Parallel()
+
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+
+	wall := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	c, err := newConn(&newConnOpts{
+		netConn:     server,
+		mode:        NormalMode,
+		l:           zap.NewNop(),
+		handler:     new(testHandler),
+		connMetrics: connmetrics.NewListenerMetrics().ConnMetrics,
+		failPoints:  failpoints.NewRegistry(),
+		clusterTime: clustertime.NewClock(func() time.Time { return wall }),
+	})
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- c.run(context.Background()) }()
+
+	bufw := bufio.NewWriter(client)
+	bufr := bufio.NewReader(client)
+
+	// unsigned cluster time is ignored
+	sendMsg(t, bufw, 1, 0, must.NotFail(types.NewDocument(
+		"ping", int32(1),
+		"$clusterTime", must.NotFail(types.NewDocument("clusterTime", types.NewTimestamp(wall.Add(time.Hour), 1))),
+		"$db", "test",
+	)))
+
+	_, body, err := wire.ReadMessage(bufr)
+	require.NoError(t, err)
+
+	res := must.NotFail(body.(*wire.OpMsg).Document())
+	assert.Equal(t, []string{"ok", "$clusterTime", "operationTime"}, res.Keys())
+	assert.Equal(t, types.NewTimestamp(wall, 1), must.NotFail(res.Get("operationTime")))
+
+	// signed cluster time is gossiped back; errors have cluster time too
+	sendMsg(t, bufw, 2, 0, must.NotFail(types.NewDocument(
+		"noSuchCommand", int32(1),
+		"$clusterTime", must.NotFail(res.Get("$clusterTime")),
+		"$db", "test",
+	)))
+
+	_, body, err = wire.ReadMessage(bufr)
+	require.NoError(t, err)
+
+	res = must.NotFail(body.(*wire.OpMsg).Document())
+	assert.Equal(t, float64(0), must.NotFail(res.Get("ok")))
+	assert.Equal(t, types.NewTimestamp(wall, 2), must.NotFail(res.Get("operationTime")))
+
+	client.Close()
+	require.Error(t, <-done)
+}
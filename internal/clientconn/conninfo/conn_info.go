@@ -34,6 +34,7 @@ type ConnInfo struct {
 	username     string
 	password     string
 	metadataRecv bool
+	compressors  []string
 }
 
 // New returns a new ConnInfo.
@@ -74,6 +75,22 @@ func (connInfo *ConnInfo) SetMetadataRecv() {
 	connInfo.metadataRecv = true
 }
 
+// Compressors returns names of compressors negotiated in the handshake.
+func (connInfo *ConnInfo) Compressors() []string {
+	connInfo.rw.RLock()
+	defer connInfo.rw.RUnlock()
+
+	return connInfo.compressors
+}
+
+// SetCompressors stores names of compressors negotiated in the handshake.
+func (connInfo *ConnInfo) SetCompressors(compressors []string) {
+	connInfo.rw.Lock()
+	defer connInfo.rw.Unlock()
+
+	connInfo.compressors = compressors
+}
+
 // Ctx returns a derived context with the given ConnInfo.
 func Ctx(ctx context.Context, connInfo *ConnInfo) context.Context {
 	return context.WithValue(ctx, connInfoKey, connInfo)
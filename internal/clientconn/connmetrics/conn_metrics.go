@@ -16,7 +16,10 @@
 package connmetrics
 
 import (
+	"crypto/tls"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
@@ -26,8 +29,37 @@ import (
 
 // ConnMetrics represents metrics of an individual conn or a collection of conns.
 type ConnMetrics struct {
-	Requests  *prometheus.CounterVec
-	Responses *prometheus.CounterVec
+	Requests        *prometheus.CounterVec
+	Responses       *prometheus.CounterVec
+	Connections     *prometheus.CounterVec
+	OpenConnections prometheus.Gauge
+	Authentications *prometheus.CounterVec
+	TLSMode         *prometheus.GaugeVec
+	MessageSizes    *prometheus.HistogramVec
+	Compression     *prometheus.HistogramVec
+}
+
+// TLS modes, as reported by MongoDB.
+const (
+	TLSModeDisabled = "disabled"   // there are no TLS listeners
+	TLSModeAllow    = "allowTLS"   // there are both TLS and plaintext listeners
+	TLSModeRequire  = "requireTLS" // there are only TLS listeners
+)
+
+// authMechanisms contains authentication mechanisms that are reported as is;
+// other values are reported as "unknown" to limit metrics cardinality.
+var authMechanisms = []string{"PLAIN", "SCRAM-SHA-1", "SCRAM-SHA-256", "MONGODB-X509"}
+
+// Message directions for MessageSizes and Compression metrics.
+const (
+	DirectionRequest  = "request"
+	DirectionResponse = "response"
+)
+
+// histogramMetrics represents the number and the sum of observed values.
+type histogramMetrics struct {
+	Count int
+	Sum   float64
 }
 
 // commandMetrics represents command results metrics.
@@ -57,19 +89,142 @@ func newConnMetrics() *ConnMetrics {
 			},
 			[]string{"opcode", "command", "argument", "result"},
 		),
+		Connections: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "connections_total",
+				Help:      "Total number of established client connections by TLS version and cipher suite.",
+			},
+			[]string{"tls_version", "tls_cipher"},
+		),
+		OpenConnections: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "connections_open",
+				Help:      "Number of currently open client connections.",
+			},
+		),
+		Authentications: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "authentications_total",
+				Help:      "Total number of authentication attempts by mechanism.",
+			},
+			[]string{"mechanism", "result"},
+		),
+		TLSMode: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "tls_mode",
+				Help:      "TLS mode of listeners; the value is always 1.",
+			},
+			[]string{"mode"},
+		),
+		MessageSizes: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "message_size_bytes",
+				Help:      "Sizes of uncompressed request and response messages.",
+				Buckets:   prometheus.ExponentialBuckets(64, 4, 10), // 64B - 16MiB
+			},
+			[]string{"direction", "opcode", "command"},
+		),
+		Compression: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "compression_ratio",
+				Help:      "Ratios of uncompressed to compressed sizes of OP_COMPRESSED messages.",
+				Buckets:   prometheus.ExponentialBuckets(1, 1.5, 10), // 1 - 38
+			},
+			[]string{"direction", "compressor", "command"},
+		),
+	}
+}
+
+// ConnectionEstablished records a new client connection.
+//
+// State is nil for plaintext connections.
+func (cm *ConnMetrics) ConnectionEstablished(state *tls.ConnectionState) {
+	cm.OpenConnections.Inc()
+
+	if state == nil {
+		cm.Connections.WithLabelValues("none", "none").Inc()
+		return
+	}
+
+	version := strings.TrimPrefix(tls.VersionName(state.Version), "TLS ")
+	cm.Connections.WithLabelValues(version, tls.CipherSuiteName(state.CipherSuite)).Inc()
+}
+
+// ConnectionClosed records closing of the client connection previously recorded by ConnectionEstablished.
+func (cm *ConnMetrics) ConnectionClosed() {
+	cm.OpenConnections.Dec()
+}
+
+// AuthenticationAttempted records authentication attempt with the given mechanism.
+func (cm *ConnMetrics) AuthenticationAttempted(mechanism string, success bool) {
+	if !slices.Contains(authMechanisms, mechanism) {
+		mechanism = "unknown"
+	}
+
+	result := "failed"
+	if success {
+		result = "ok"
+	}
+
+	cm.Authentications.WithLabelValues(mechanism, result).Inc()
+}
+
+// SetTLSMode sets TLS mode of listeners, one of TLSModeXXX constants.
+func (cm *ConnMetrics) SetTLSMode(mode string) {
+	cm.TLSMode.Reset()
+	cm.TLSMode.WithLabelValues(mode).Set(1)
+}
+
+// MessageHandled records sizes of uncompressed request and response messages of the command.
+func (cm *ConnMetrics) MessageHandled(opcode, command string, reqSize, resSize int) {
+	cm.MessageSizes.WithLabelValues(DirectionRequest, opcode, command).Observe(float64(reqSize))
+	cm.MessageSizes.WithLabelValues(DirectionResponse, opcode, command).Observe(float64(resSize))
+}
+
+// MessageCompressed records the compression ratio of the command's message
+// in the given direction (one of DirectionXXX constants).
+func (cm *ConnMetrics) MessageCompressed(direction, compressor, command string, uncompressedSize, compressedSize int) {
+	if compressedSize <= 0 {
+		return
 	}
+
+	cm.Compression.WithLabelValues(direction, compressor, command).Observe(float64(uncompressedSize) / float64(compressedSize))
 }
 
 // Describe implements prometheus.Collector.
 func (cm *ConnMetrics) Describe(ch chan<- *prometheus.Desc) {
 	cm.Requests.Describe(ch)
 	cm.Responses.Describe(ch)
+	cm.Connections.Describe(ch)
+	cm.OpenConnections.Describe(ch)
+	cm.Authentications.Describe(ch)
+	cm.TLSMode.Describe(ch)
+	cm.MessageSizes.Describe(ch)
+	cm.Compression.Describe(ch)
 }
 
 // Collect implements prometheus.Collector.
 func (cm *ConnMetrics) Collect(ch chan<- prometheus.Metric) {
 	cm.Requests.Collect(ch)
 	cm.Responses.Collect(ch)
+	cm.Connections.Collect(ch)
+	cm.OpenConnections.Collect(ch)
+	cm.Authentications.Collect(ch)
+	cm.TLSMode.Collect(ch)
+	cm.MessageSizes.Collect(ch)
+	cm.Compression.Collect(ch)
 }
 
 // GetResponses returns a map with all response metrics:
@@ -138,6 +293,157 @@ func (cm *ConnMetrics) GetResponses() map[string]map[string]map[string]commandMe
 	return res
 }
 
+// GetConnections returns a map with connection counts:
+//
+// TLS version (e.g. "1.3"; or "none" for plaintext connections) ->
+// cipher suite (e.g. "TLS_AES_128_GCM_SHA256"; or "none") ->
+// count.
+func (cm *ConnMetrics) GetConnections() map[string]map[string]int {
+	res := map[string]map[string]int{}
+
+	for _, m := range collect(cm.Connections) {
+		version, cipher := m.labels["tls_version"], m.labels["tls_cipher"]
+
+		if res[version] == nil {
+			res[version] = map[string]int{}
+		}
+
+		res[version][cipher] += int(m.value)
+	}
+
+	return res
+}
+
+// GetOpenConnections returns the number of currently open client connections.
+func (cm *ConnMetrics) GetOpenConnections() int {
+	for _, m := range collect(cm.OpenConnections) {
+		return int(m.value)
+	}
+
+	return 0
+}
+
+// GetAuthentications returns a map with authentication attempts counts:
+//
+// mechanism (e.g. "PLAIN"; or "unknown") ->
+// result ("ok" or "failed") ->
+// count.
+func (cm *ConnMetrics) GetAuthentications() map[string]map[string]int {
+	res := map[string]map[string]int{}
+
+	for _, m := range collect(cm.Authentications) {
+		mechanism, result := m.labels["mechanism"], m.labels["result"]
+
+		if res[mechanism] == nil {
+			res[mechanism] = map[string]int{}
+		}
+
+		res[mechanism][result] += int(m.value)
+	}
+
+	return res
+}
+
+// GetTLSMode returns TLS mode set by SetTLSMode, or empty string if it was not set.
+func (cm *ConnMetrics) GetTLSMode() string {
+	for _, m := range collect(cm.TLSMode) {
+		return m.labels["mode"]
+	}
+
+	return ""
+}
+
+// GetMessageSizes returns a map with message sizes:
+//
+// direction ("request" or "response") ->
+// command (e.g. "find", "insert"; or "unknown") ->
+// number and sum of sizes in bytes.
+func (cm *ConnMetrics) GetMessageSizes() map[string]map[string]histogramMetrics {
+	return collectHistograms(cm.MessageSizes, "direction", "command")
+}
+
+// GetCompressionRatios returns a map with compression ratios:
+//
+// direction ("request" or "response") ->
+// compressor (e.g. "snappy", "zstd") ->
+// number and sum of ratios.
+func (cm *ConnMetrics) GetCompressionRatios() map[string]map[string]histogramMetrics {
+	return collectHistograms(cm.Compression, "direction", "compressor")
+}
+
+// collectHistograms returns all values of the given histogram vector grouped by two labels.
+func collectHistograms(c prometheus.Collector, label1, label2 string) map[string]map[string]histogramMetrics {
+	metrics := make(chan prometheus.Metric)
+	go func() {
+		c.Collect(metrics)
+		close(metrics)
+	}()
+
+	res := map[string]map[string]histogramMetrics{}
+
+	for m := range metrics {
+		var content dto.Metric
+		must.NoError(m.Write(&content))
+
+		var v1, v2 string
+		for _, label := range content.GetLabel() {
+			switch label.GetName() {
+			case label1:
+				v1 = label.GetValue()
+			case label2:
+				v2 = label.GetValue()
+			}
+		}
+
+		if res[v1] == nil {
+			res[v1] = map[string]histogramMetrics{}
+		}
+
+		h := res[v1][v2]
+		h.Count += int(content.GetHistogram().GetSampleCount())
+		h.Sum += content.GetHistogram().GetSampleSum()
+		res[v1][v2] = h
+	}
+
+	return res
+}
+
+// metricValue represents a single collected counter or gauge value with labels.
+type metricValue struct {
+	labels map[string]string
+	value  float64
+}
+
+// collect returns all values of the given counter or gauge vector.
+func collect(c prometheus.Collector) []metricValue {
+	metrics := make(chan prometheus.Metric)
+	go func() {
+		c.Collect(metrics)
+		close(metrics)
+	}()
+
+	var res []metricValue
+
+	for m := range metrics {
+		var content dto.Metric
+		must.NoError(m.Write(&content))
+
+		labels := make(map[string]string, len(content.GetLabel()))
+		for _, label := range content.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+
+		v := content.GetCounter().GetValue()
+		if g := content.GetGauge(); g != nil {
+			v = g.GetValue()
+		}
+
+		res = append(res, metricValue{labels: labels, value: v})
+	}
+
+	return res
+}
+
 // check interfaces
 var (
 	_ prometheus.Collector = (*ConnMetrics)(nil)
@@ -15,6 +15,7 @@
 package connmetrics
 
 import (
+	"crypto/tls"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -38,3 +39,66 @@ func TestGetResponses(t *testing.T) {
 	}
 	assert.Equal(t, expected, m.GetResponses())
 }
+
+func TestSecurityMetrics(t *testing.T) {
+	m := newConnMetrics()
+
+	assert.Equal(t, "", m.GetTLSMode())
+	m.SetTLSMode(TLSModeAllow)
+	m.SetTLSMode(TLSModeRequire)
+	assert.Equal(t, TLSModeRequire, m.GetTLSMode())
+
+	m.ConnectionEstablished(nil)
+	m.ConnectionEstablished(&tls.ConnectionState{Version: tls.VersionTLS13, CipherSuite: tls.TLS_AES_128_GCM_SHA256})
+	m.ConnectionEstablished(&tls.ConnectionState{Version: tls.VersionTLS13, CipherSuite: tls.TLS_AES_128_GCM_SHA256})
+
+	expectedConnections := map[string]map[string]int{
+		"none": {"none": 1},
+		"1.3":  {"TLS_AES_128_GCM_SHA256": 2},
+	}
+	assert.Equal(t, expectedConnections, m.GetConnections())
+	assert.Equal(t, 3, m.GetOpenConnections())
+
+	m.ConnectionClosed()
+	assert.Equal(t, 2, m.GetOpenConnections())
+
+	m.AuthenticationAttempted("PLAIN", true)
+	m.AuthenticationAttempted("PLAIN", false)
+	m.AuthenticationAttempted("GSSAPI", false)
+
+	expectedAuthentications := map[string]map[string]int{
+		"PLAIN":   {"ok": 1, "failed": 1},
+		"unknown": {"failed": 1},
+	}
+	assert.Equal(t, expectedAuthentications, m.GetAuthentications())
+}
+
+func TestMessageMetrics(t *testing.T) {
+	m := newConnMetrics()
+
+	m.MessageHandled("OP_MSG", "find", 100, 1000)
+	m.MessageHandled("OP_MSG", "find", 200, 3000)
+	m.MessageHandled("OP_QUERY", "isMaster", 50, 300)
+
+	expectedSizes := map[string]map[string]histogramMetrics{
+		DirectionRequest: {
+			"find":     {Count: 2, Sum: 300},
+			"isMaster": {Count: 1, Sum: 50},
+		},
+		DirectionResponse: {
+			"find":     {Count: 2, Sum: 4000},
+			"isMaster": {Count: 1, Sum: 300},
+		},
+	}
+	assert.Equal(t, expectedSizes, m.GetMessageSizes())
+
+	m.MessageCompressed(DirectionRequest, "zstd", "find", 100, 50)
+	m.MessageCompressed(DirectionResponse, "zstd", "find", 3000, 1000)
+	m.MessageCompressed(DirectionResponse, "zstd", "find", 1000, 0)
+
+	expectedRatios := map[string]map[string]histogramMetrics{
+		DirectionRequest:  {"zstd": {Count: 1, Sum: 2}},
+		DirectionResponse: {"zstd": {Count: 1, Sum: 3}},
+	}
+	assert.Equal(t, expectedRatios, m.GetCompressionRatios())
+}
@@ -28,9 +28,11 @@ const (
 
 // ListenerMetrics represents listener metrics.
 type ListenerMetrics struct {
-	Accepts     *prometheus.CounterVec
-	Durations   *prometheus.HistogramVec
-	ConnMetrics *ConnMetrics
+	Accepts            *prometheus.CounterVec
+	Durations          *prometheus.HistogramVec
+	HandshakeDurations *prometheus.HistogramVec
+	HandshakeFailures  *prometheus.CounterVec
+	ConnMetrics        *ConnMetrics
 }
 
 // NewListenerMetrics creates new listener metrics.
@@ -64,6 +66,25 @@ func NewListenerMetrics() *ListenerMetrics {
 			},
 			[]string{"error"},
 		),
+		HandshakeDurations: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "handshake_duration_seconds",
+				Help:      "Client connection establishment (PROXY protocol header and TLS handshake) duration in seconds.",
+				Buckets:   []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5},
+			},
+			[]string{"tls", "error"},
+		),
+		HandshakeFailures: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "handshake_failures_total",
+				Help:      "Total number of failed client connection establishments by reason.",
+			},
+			[]string{"reason"},
+		),
 
 		ConnMetrics: newConnMetrics(),
 	}
@@ -73,6 +94,8 @@ func NewListenerMetrics() *ListenerMetrics {
 func (lm *ListenerMetrics) Describe(ch chan<- *prometheus.Desc) {
 	lm.Accepts.Describe(ch)
 	lm.Durations.Describe(ch)
+	lm.HandshakeDurations.Describe(ch)
+	lm.HandshakeFailures.Describe(ch)
 	lm.ConnMetrics.Describe(ch)
 }
 
@@ -80,6 +103,8 @@ func (lm *ListenerMetrics) Describe(ch chan<- *prometheus.Desc) {
 func (lm *ListenerMetrics) Collect(ch chan<- prometheus.Metric) {
 	lm.Accepts.Collect(ch)
 	lm.Durations.Collect(ch)
+	lm.HandshakeDurations.Collect(ch)
+	lm.HandshakeFailures.Collect(ch)
 	lm.ConnMetrics.Collect(ch)
 }
 
@@ -0,0 +1,174 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package failpoints provides fail points configured by the `configureFailPoint` command.
+//
+// They are used by drivers' specification tests and applications' test suites
+// to trigger errors and closed connections deterministically.
+package failpoints
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// Supported fail point names.
+const (
+	// FailCommand fails or delays commands listed in Data.FailCommands.
+	FailCommand = "failCommand"
+
+	// FailGetMoreAfterCursorCheckout fails getMore commands after the cursor is found.
+	FailGetMoreAfterCursorCheckout = "failGetMoreAfterCursorCheckout"
+)
+
+// IsKnown returns true if the fail point with the given name is supported.
+func IsKnown(name string) bool {
+	switch name {
+	case FailCommand, FailGetMoreAfterCursorCheckout:
+		return true
+	default:
+		return false
+	}
+}
+
+// Mode represents fail point activation mode.
+//
+// Zero value represents "alwaysOn" mode.
+type Mode struct {
+	Times int64 // if positive, fail point is disabled after being triggered that many times
+	Skip  int64 // number of times the fail point is not triggered before being enabled
+}
+
+// Data represents fail point data.
+//
+//nolint:vet // for readability
+type Data struct {
+	FailCommands []string
+
+	ErrorCode         int32
+	CloseConnection   bool
+	BlockConnection   bool
+	BlockTime         time.Duration
+	WriteConcernError *types.Document
+}
+
+// failPoint represents a configured fail point.
+type failPoint struct {
+	mode    Mode
+	data    *Data
+	entered int64
+	off     bool
+}
+
+// Registry stores fail points.
+//
+// Nil value is valid and has all fail points disabled.
+// Methods are safe for concurrent use.
+type Registry struct {
+	m  sync.Mutex
+	fp map[string]*failPoint
+}
+
+// NewRegistry returns a new Registry with all fail points disabled.
+func NewRegistry() *Registry {
+	return &Registry{
+		fp: map[string]*failPoint{},
+	}
+}
+
+// Enable enables fail point with the given name, mode and data,
+// replacing the previous configuration.
+//
+// It returns the number of times the fail point was triggered with the previous configuration.
+func (r *Registry) Enable(name string, mode Mode, data *Data) int64 {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	count := r.count(name)
+
+	r.fp[name] = &failPoint{
+		mode: mode,
+		data: data,
+	}
+
+	return count
+}
+
+// Disable disables fail point with the given name.
+//
+// It returns the number of times the fail point was triggered with the previous configuration.
+func (r *Registry) Disable(name string) int64 {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	fp := r.fp[name]
+	if fp == nil {
+		return 0
+	}
+
+	fp.off = true
+
+	return fp.entered
+}
+
+// count returns the number of times the fail point was triggered.
+//
+// The caller must hold the lock.
+func (r *Registry) count(name string) int64 {
+	if fp := r.fp[name]; fp != nil {
+		return fp.entered
+	}
+
+	return 0
+}
+
+// Check returns fail point data if the fail point with the given name is enabled
+// and should be triggered for the given command.
+// Otherwise, it returns nil.
+//
+// Every non-nil result counts as a fail point trigger.
+func (r *Registry) Check(name, command string) *Data {
+	if r == nil {
+		return nil
+	}
+
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	fp := r.fp[name]
+	if fp == nil || fp.off {
+		return nil
+	}
+
+	// failCommand always has a non-empty list; other fail points are not limited to specific commands
+	if len(fp.data.FailCommands) > 0 && !slices.Contains(fp.data.FailCommands, command) {
+		return nil
+	}
+
+	if fp.mode.Skip > 0 {
+		fp.mode.Skip--
+		return nil
+	}
+
+	fp.entered++
+
+	if fp.mode.Times > 0 && fp.entered >= fp.mode.Times {
+		fp.off = true
+	}
+
+	return fp.data
+}
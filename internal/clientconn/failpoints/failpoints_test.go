@@ -0,0 +1,76 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package failpoints
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Nil", func(t *testing.T) {
+		t.Parallel()
+
+		var r *Registry
+		assert.Nil(t, r.Check(FailCommand, "find"))
+	})
+
+	t.Run("AlwaysOn", func(t *testing.T) {
+		t.Parallel()
+
+		r := NewRegistry()
+		data := &Data{FailCommands: []string{"find"}, ErrorCode: 2}
+
+		assert.Zero(t, r.Enable(FailCommand, Mode{}, data))
+
+		assert.Nil(t, r.Check(FailCommand, "insert"))
+		assert.Nil(t, r.Check(FailGetMoreAfterCursorCheckout, "find"))
+
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, data, r.Check(FailCommand, "find"))
+		}
+
+		assert.Equal(t, int64(3), r.Disable(FailCommand))
+		assert.Nil(t, r.Check(FailCommand, "find"))
+	})
+
+	t.Run("TimesSkip", func(t *testing.T) {
+		t.Parallel()
+
+		r := NewRegistry()
+		data := &Data{CloseConnection: true}
+
+		r.Enable(FailGetMoreAfterCursorCheckout, Mode{Times: 2, Skip: 1}, data)
+
+		assert.Nil(t, r.Check(FailGetMoreAfterCursorCheckout, "getMore"))
+		assert.Equal(t, data, r.Check(FailGetMoreAfterCursorCheckout, "getMore"))
+		assert.Equal(t, data, r.Check(FailGetMoreAfterCursorCheckout, "getMore"))
+		assert.Nil(t, r.Check(FailGetMoreAfterCursorCheckout, "getMore"))
+
+		assert.Equal(t, int64(2), r.Enable(FailGetMoreAfterCursorCheckout, Mode{}, data))
+		assert.Equal(t, data, r.Check(FailGetMoreAfterCursorCheckout, "getMore"))
+	})
+}
+
+func TestIsKnown(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsKnown(FailCommand))
+	assert.True(t, IsKnown(FailGetMoreAfterCursorCheckout))
+	assert.False(t, IsKnown("hangBeforeWriting"))
+}
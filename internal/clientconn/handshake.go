@@ -0,0 +1,151 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// tlsHandshakeTimeout is the time given to the client to complete TLS handshake.
+	tlsHandshakeTimeout = 10 * time.Second
+
+	// slowHandshakeThreshold is the connection establishment duration after which a warning is logged.
+	slowHandshakeThreshold = time.Second
+)
+
+// handshake establishes accepted client connection:
+// it reads PROXY protocol header if proxyProtocol is true and performs TLS handshake if tlsConfig is not nil.
+//
+// Durations and failures are recorded in listener metrics and logged if needed.
+// The returned connection should be used instead of the given one.
+func handshake(ctx context.Context, netConn net.Conn, tlsConfig *tls.Config, proxyProtocol bool, l *Listener, logger *zap.Logger) (net.Conn, error) { //nolint:lll // argument list is too long
+	start := time.Now()
+	addr := netConn.RemoteAddr()
+
+	var trusted []*net.IPNet
+	if proxyProtocol {
+		trusted = l.ProxyProtocolTrustedNetworks
+	}
+
+	conn, tlsState, reason, err := establish(ctx, netConn, tlsConfig, proxyProtocol, trusted)
+
+	d := time.Since(start)
+
+	lv := "0"
+	if err != nil {
+		lv = "1"
+	}
+
+	l.Metrics.HandshakeDurations.WithLabelValues(strconv.FormatBool(tlsConfig != nil), lv).Observe(d.Seconds())
+
+	if err != nil {
+		l.Metrics.HandshakeFailures.WithLabelValues(reason).Inc()
+
+		logger.Warn(
+			"Failed to establish connection",
+			zap.Stringer("addr", addr), zap.String("reason", reason), zap.Duration("duration", d), zap.Error(err),
+		)
+
+		return nil, err
+	}
+
+	if d > slowHandshakeThreshold {
+		logger.Warn(
+			"Slow connection establishment",
+			zap.Stringer("addr", conn.RemoteAddr()), zap.Bool("tls", tlsConfig != nil), zap.Duration("duration", d),
+		)
+	}
+
+	l.Metrics.ConnMetrics.ConnectionEstablished(tlsState)
+
+	return conn, nil
+}
+
+// establish reads PROXY protocol header and performs TLS handshake.
+//
+// If trusted networks are given, PROXY protocol header is accepted only from peers in them.
+// If an error is returned, reason describes it for metrics and logs.
+func establish(ctx context.Context, conn net.Conn, tlsConfig *tls.Config, proxyProtocol bool, trusted []*net.IPNet) (net.Conn, *tls.ConnectionState, string, error) { //nolint:lll // argument list is too long
+	if proxyProtocol {
+		if !proxyTrusted(conn.RemoteAddr(), trusted) {
+			return nil, nil, "proxy_protocol_untrusted", fmt.Errorf("PROXY protocol peer %s is not trusted", conn.RemoteAddr())
+		}
+
+		pc, err := readProxyHeader(conn)
+		if err != nil {
+			return nil, nil, "proxy_protocol", err
+		}
+
+		conn = pc
+	}
+
+	if tlsConfig == nil {
+		return conn, nil, "", nil
+	}
+
+	tlsConn := tls.Server(conn, tlsConfig)
+
+	hsCtx, hsCancel := context.WithTimeout(ctx, tlsHandshakeTimeout)
+	defer hsCancel()
+
+	if err := tlsConn.HandshakeContext(hsCtx); err != nil {
+		return nil, nil, tlsFailureReason(err), err
+	}
+
+	state := tlsConn.ConnectionState()
+
+	return tlsConn, &state, "", nil
+}
+
+// tlsFailureReason returns a short reason of TLS handshake failure for metrics and logs.
+func tlsFailureReason(err error) string {
+	var netErr net.Error
+	var recordErr tls.RecordHeaderError
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "tls_timeout"
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, syscall.ECONNRESET):
+		return "tls_client_closed"
+	case errors.As(err, &recordErr):
+		return "tls_not_tls"
+	}
+
+	// crypto/tls does not export most handshake errors
+	msg := err.Error()
+
+	switch {
+	case strings.Contains(msg, "unsupported versions"), strings.Contains(msg, "protocol version"):
+		return "tls_version"
+	case strings.Contains(msg, "cipher suite"):
+		return "tls_cipher"
+	case strings.Contains(msg, "certificate"):
+		return "tls_certificate"
+	default:
+		return "tls_other"
+	}
+}
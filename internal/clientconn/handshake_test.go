@@ -0,0 +1,251 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
+)
+
+func TestHandshake(t *testing.T) {
+	t.Parallel()
+
+	certs := filepath.Join("..", "..", "build", "certs")
+	tlsConfig, err := setupTLSConfig(&setupTLSConfigOpts{
+		certFile: filepath.Join(certs, "server-cert.pem"),
+		keyFile:  filepath.Join(certs, "server-key.pem"),
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		proxyProtocol bool
+		trusted       []*net.IPNet
+		client        func(conn net.Conn)
+		reason        string // empty if no error is expected
+	}{
+		"TLS": {
+			client: func(conn net.Conn) {
+				_ = tls.Client(conn, &tls.Config{InsecureSkipVerify: true}).Handshake()
+			},
+		},
+		"NotTLS": {
+			client: func(conn net.Conn) {
+				_, _ = conn.Write([]byte("\x3a\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\xdd\x07\x00\x00"))
+			},
+			reason: "tls_not_tls",
+		},
+		"Version": {
+			client: func(conn net.Conn) {
+				config := &tls.Config{
+					InsecureSkipVerify: true,
+					MinVersion:         tls.VersionTLS10, //nolint:gosec // unsupported version is tested
+					MaxVersion:         tls.VersionTLS10,
+				}
+				_ = tls.Client(conn, config).Handshake()
+			},
+			reason: "tls_version",
+		},
+		"ClientClosed": {
+			client: func(conn net.Conn) {
+				conn.Close()
+			},
+			reason: "tls_client_closed",
+		},
+		"ProxyProtocol": {
+			proxyProtocol: true,
+			client: func(conn net.Conn) {
+				_ = tls.Client(conn, &tls.Config{InsecureSkipVerify: true}).Handshake()
+			},
+			reason: "proxy_protocol",
+		},
+		"ProxyProtocolUntrusted": {
+			proxyProtocol: true,
+			trusted:       []*net.IPNet{{IP: net.IPv4(10, 0, 0, 0), Mask: net.CIDRMask(8, 32)}},
+			client: func(conn net.Conn) {
+				_ = tls.Client(conn, &tls.Config{InsecureSkipVerify: true}).Handshake()
+			},
+			reason: "proxy_protocol_untrusted",
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			l := NewListener(&NewListenerOpts{
+				ProxyProtocol:                tc.proxyProtocol,
+				ProxyProtocolTrustedNetworks: tc.trusted,
+				Metrics:                      connmetrics.NewListenerMetrics(),
+			})
+
+			client, server := net.Pipe()
+			t.Cleanup(func() {
+				client.Close()
+				server.Close()
+			})
+
+			go tc.client(client)
+
+			conn, err := handshake(context.Background(), server, tlsConfig, tc.proxyProtocol, l, zap.NewNop())
+
+			if tc.reason == "" {
+				require.NoError(t, err)
+				require.IsType(t, new(tls.Conn), conn)
+
+				assert.Equal(t, float64(1), testutil.ToFloat64(l.Metrics.ConnMetrics.Connections))
+				assert.Equal(t, 0, testutil.CollectAndCount(l.Metrics.HandshakeFailures))
+
+				return
+			}
+
+			require.Error(t, err)
+			assert.Nil(t, conn)
+
+			assert.Equal(t, float64(1), testutil.ToFloat64(l.Metrics.HandshakeFailures.WithLabelValues(tc.reason)))
+			assert.Equal(t, 0, testutil.CollectAndCount(l.Metrics.ConnMetrics.Connections))
+		})
+	}
+}
+
+// generateClientCert returns a new client certificate signed by a new CA written to the returned file.
+func generateClientCert(t *testing.T) (tls.Certificate, string) {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	err = os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0o666)
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caTemplate, &key.PublicKey, caKey)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, caFile
+}
+
+func TestHandshakeClientCertificates(t *testing.T) {
+	t.Parallel()
+
+	certs := filepath.Join("..", "..", "build", "certs")
+
+	clientCert, caFile := generateClientCert(t)
+	untrustedCert, _ := generateClientCert(t)
+
+	for name, tc := range map[string]struct {
+		optional bool
+		cert     *tls.Certificate
+		fail     bool
+	}{
+		"Required":          {cert: &clientCert},
+		"RequiredMissing":   {fail: true},
+		"RequiredUntrusted": {cert: &untrustedCert, fail: true},
+		"Optional":          {optional: true, cert: &clientCert},
+		"OptionalMissing":   {optional: true},
+		"OptionalUntrusted": {optional: true, cert: &untrustedCert, fail: true},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tlsConfig, err := setupTLSConfig(&setupTLSConfigOpts{
+				certFile:           filepath.Join(certs, "server-cert.pem"),
+				keyFile:            filepath.Join(certs, "server-key.pem"),
+				caFile:             caFile,
+				clientCertOptional: tc.optional,
+			})
+			require.NoError(t, err)
+
+			l := NewListener(&NewListenerOpts{
+				Metrics: connmetrics.NewListenerMetrics(),
+			})
+
+			client, server := net.Pipe()
+			t.Cleanup(func() {
+				client.Close()
+				server.Close()
+			})
+
+			go func() {
+				config := &tls.Config{InsecureSkipVerify: true}
+				if tc.cert != nil {
+					config.Certificates = []tls.Certificate{*tc.cert}
+				}
+
+				// with TLS 1.3, the server verifies the certificate after the client's handshake is done,
+				// so read its alert to unblock the pipe
+				tlsConn := tls.Client(client, config)
+				if err := tlsConn.Handshake(); err == nil {
+					_, _ = io.Copy(io.Discard, tlsConn)
+				}
+			}()
+
+			conn, err := handshake(context.Background(), server, tlsConfig, false, l, zap.NewNop())
+			if tc.fail {
+				require.Error(t, err)
+				assert.Nil(t, conn)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.IsType(t, new(tls.Conn), conn)
+		})
+	}
+}
@@ -30,10 +30,13 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 
+	"github.com/FerretDB/FerretDB/internal/clientconn/clustertime"
 	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
+	"github.com/FerretDB/FerretDB/internal/clientconn/failpoints"
 	"github.com/FerretDB/FerretDB/internal/handlers"
 	"github.com/FerretDB/FerretDB/internal/util/ctxutil"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/sqllog"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
@@ -42,9 +45,12 @@ import (
 type Listener struct {
 	*NewListenerOpts
 
-	tcpListener  net.Listener
-	unixListener net.Listener
-	tlsListener  net.Listener
+	tcpListeners     []net.Listener
+	unixListeners    []net.Listener
+	tlsListeners     []net.Listener // TLS is handled by connections; see tlsConfig
+	systemdListeners []systemdListener
+
+	tlsConfig *tls.Config // nil if there are no TLS listeners
 
 	tcpListenerReady  chan struct{}
 	unixListenerReady chan struct{}
@@ -53,19 +59,40 @@ type Listener struct {
 
 // NewListenerOpts represents listener configuration.
 type NewListenerOpts struct {
-	TCP         string
-	Unix        string
-	TLS         string
+	TCP         []string // empty values are ignored
+	Unix        []string // empty values are ignored
+	TLS         []string // empty values are ignored
 	TLSCertFile string
 	TLSKeyFile  string
 	TLSCAFile   string
 
+	// TLSClientCertOptional makes client certificates optional when TLSCAFile is set.
+	// Presented certificates are still verified.
+	TLSClientCertOptional bool
+
+	// Systemd enables inheriting listening sockets passed by systemd socket activation.
+	// Sockets with the "tls" name (FileDescriptorName=tls) are used for TLS connections.
+	Systemd bool
+
+	// ProxyProtocol requires PROXY protocol v1 or v2 header on incoming connections to TCP and TLS listeners.
+	// Unix and systemd listeners are not affected.
+	// It is not related to ProxyAddr and proxy operation modes.
+	ProxyProtocol bool
+
+	// ProxyProtocolTrustedNetworks restricts ProxyProtocol to connections from those networks;
+	// connections from other peers are closed.
+	// Empty value allows connections from all peers.
+	ProxyProtocolTrustedNetworks []*net.IPNet
+
 	ProxyAddr      string
 	Mode           Mode
 	Metrics        *connmetrics.ListenerMetrics
+	FailPoints     *failpoints.Registry // nil disables fail points
+	ClusterTime    *clustertime.Clock   // nil disables $clusterTime and operationTime in responses
 	Handler        handlers.Interface
 	Logger         *zap.Logger
-	TestRecordsDir string // if empty, no records are created
+	SQLLog         sqllog.Mode // empty disables SQL statement logging
+	TestRecordsDir string      // if empty, no records are created
 }
 
 // NewListener returns a new listener, configured by the NewListenerOpts argument.
@@ -81,126 +108,238 @@ func NewListener(opts *NewListenerOpts) *Listener {
 // Run runs the listener until ctx is canceled or some unrecoverable error occurs.
 //
 // When this method returns, listener and all connections, as well as handler are closed.
-func (l *Listener) Run(ctx context.Context) error {
+func (l *Listener) Run(ctx context.Context) (err error) {
 	defer l.Handler.Close()
 
 	logger := l.Logger.Named("listener")
 
-	if l.TCP != "" {
-		var err error
-		if l.tcpListener, err = net.Listen("tcp", l.TCP); err != nil {
-			return err
+	defer func() {
+		if err == nil {
+			return
 		}
 
-		close(l.tcpListenerReady)
+		for _, lis := range l.listeners() {
+			lis.Close()
+		}
+	}()
 
-		logger.Sugar().Infof("Listening on TCP %s ...", l.TCPAddr())
+	if err = l.listen(logger); err != nil {
+		return err
 	}
 
-	if l.Unix != "" {
-		var err error
-		if l.unixListener, err = net.Listen("unix", l.Unix); err != nil {
-			return err
+	l.Metrics.ConnMetrics.SetTLSMode(l.tlsMode())
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		<-ctx.Done()
+
+		for _, lis := range l.listeners() {
+			lis.Close()
+		}
+	}()
+
+	for _, lis := range l.listeners() {
+		var tlsConfig *tls.Config
+		if lis.tls {
+			tlsConfig = l.tlsConfig
 		}
 
-		close(l.unixListenerReady)
+		proxyProtocol := l.ProxyProtocol && lis.tcp
+
+		wg.Add(1)
+
+		go func(lis net.Listener) {
+			defer func() {
+				logger.Sugar().Infof("%s stopped.", lis.Addr())
+				wg.Done()
+			}()
 
-		logger.Sugar().Infof("Listening on Unix %s ...", l.UnixAddr())
+			acceptLoop(ctx, lis, tlsConfig, proxyProtocol, &wg, l, logger)
+		}(lis.Listener)
 	}
 
-	if l.TLS != "" {
-		var err error
-		if l.tlsListener, err = setupTLSListener(&setupTLSListenerOpts{
-			addr:     l.TLS,
-			certFile: l.TLSCertFile,
-			keyFile:  l.TLSKeyFile,
-			caFile:   l.TLSCAFile,
-		}); err != nil {
+	<-ctx.Done()
+	logger.Info("Waiting for all connections to stop...")
+	wg.Wait()
+
+	return context.Cause(ctx)
+}
+
+// listen creates all configured listeners.
+//
+// Listeners created before an error should be closed by the caller.
+func (l *Listener) listen(logger *zap.Logger) error {
+	for _, addr := range l.TCP {
+		if addr == "" {
+			continue
+		}
+
+		lis, err := net.Listen("tcp", addr)
+		if err != nil {
 			return err
 		}
 
-		close(l.tlsListenerReady)
+		l.tcpListeners = append(l.tcpListeners, lis)
 
-		logger.Sugar().Infof("Listening on TLS %s ...", l.TLSAddr())
+		logger.Sugar().Infof("Listening on TCP %s ...", lis.Addr())
 	}
 
-	var wg sync.WaitGroup
+	if len(l.tcpListeners) > 0 {
+		close(l.tcpListenerReady)
+	}
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
+	for _, addr := range l.Unix {
+		if addr == "" {
+			continue
+		}
 
-		<-ctx.Done()
+		lis, err := net.Listen("unix", addr)
+		if err != nil {
+			return err
+		}
+
+		l.unixListeners = append(l.unixListeners, lis)
+
+		logger.Sugar().Infof("Listening on Unix %s ...", lis.Addr())
+	}
+
+	if len(l.unixListeners) > 0 {
+		close(l.unixListenerReady)
+	}
 
-		if l.tcpListener != nil {
-			l.tcpListener.Close()
+	for _, addr := range l.TLS {
+		if addr == "" {
+			continue
 		}
 
-		if l.unixListener != nil {
-			l.unixListener.Close()
+		if l.tlsConfig == nil {
+			var err error
+			if l.tlsConfig, err = l.loadTLSConfig(); err != nil {
+				return err
+			}
 		}
 
-		if l.tlsListener != nil {
-			l.tlsListener.Close()
+		lis, err := net.Listen("tcp", addr)
+		if err != nil {
+			return lazyerrors.Error(err)
 		}
-	}()
 
-	if l.TCP != "" {
-		wg.Add(1)
+		l.tlsListeners = append(l.tlsListeners, lis)
 
-		go func() {
-			defer func() {
-				logger.Sugar().Infof("%s stopped.", l.TCPAddr())
-				wg.Done()
-			}()
+		logger.Sugar().Infof("Listening on TLS %s ...", lis.Addr())
+	}
 
-			acceptLoop(ctx, l.tcpListener, &wg, l, logger)
-		}()
+	if len(l.tlsListeners) > 0 {
+		close(l.tlsListenerReady)
 	}
 
-	if l.Unix != "" {
-		wg.Add(1)
+	if !l.Systemd {
+		return nil
+	}
 
-		go func() {
-			defer func() {
-				logger.Sugar().Infof("%s stopped.", l.UnixAddr())
-				wg.Done()
-			}()
+	sockets, err := systemdListeners(os.Getenv, os.Getpid(), systemdFirstFD)
+	if err != nil {
+		return err
+	}
 
-			acceptLoop(ctx, l.unixListener, &wg, l, logger)
-		}()
+	if len(sockets) == 0 {
+		logger.Warn("No listening sockets were passed by systemd")
 	}
 
-	if l.TLS != "" {
-		wg.Add(1)
+	// add all sockets first, so they are closed by the caller on error
+	l.systemdListeners = sockets
 
-		go func() {
-			defer func() {
-				logger.Sugar().Infof("%s stopped.", l.tlsListener.Addr())
-				wg.Done()
-			}()
+	for _, s := range sockets {
+		if s.name == "tls" && l.tlsConfig == nil {
+			if l.tlsConfig, err = l.loadTLSConfig(); err != nil {
+				return err
+			}
+		}
 
-			acceptLoop(ctx, l.tlsListener, &wg, l, logger)
-		}()
+		logger.Sugar().Infof("Listening on systemd socket %q %s ...", s.name, s.Addr())
 	}
 
-	<-ctx.Done()
-	logger.Info("Waiting for all connections to stop...")
-	wg.Wait()
+	return nil
+}
 
-	return context.Cause(ctx)
+// listener represents a created listener.
+type listener struct {
+	net.Listener
+	tls bool // if true, TLS handshake is performed for accepted connections
+	tcp bool // if true, the listener was created by FerretDB for TCP or TLS address
 }
 
-// setupTLSListenerOpts represents TLS listener setup options.
-type setupTLSListenerOpts struct {
-	addr     string
+// listeners returns all created listeners.
+func (l *Listener) listeners() []listener {
+	res := make([]listener, 0, len(l.tcpListeners)+len(l.unixListeners)+len(l.tlsListeners)+len(l.systemdListeners))
+
+	for _, lis := range l.tcpListeners {
+		res = append(res, listener{Listener: lis, tcp: true})
+	}
+
+	for _, lis := range l.unixListeners {
+		res = append(res, listener{Listener: lis})
+	}
+
+	for _, lis := range l.tlsListeners {
+		res = append(res, listener{Listener: lis, tls: true, tcp: true})
+	}
+
+	for _, lis := range l.systemdListeners {
+		res = append(res, listener{Listener: lis.Listener, tls: lis.name == "tls"})
+	}
+
+	return res
+}
+
+// tlsMode returns TLS mode for created listeners.
+func (l *Listener) tlsMode() string {
+	var plain, secure bool
+
+	for _, lis := range l.listeners() {
+		if lis.tls {
+			secure = true
+		} else {
+			plain = true
+		}
+	}
+
+	switch {
+	case !secure:
+		return connmetrics.TLSModeDisabled
+	case plain:
+		return connmetrics.TLSModeAllow
+	default:
+		return connmetrics.TLSModeRequire
+	}
+}
+
+// loadTLSConfig returns TLS configuration for TLS listeners.
+func (l *Listener) loadTLSConfig() (*tls.Config, error) {
+	return setupTLSConfig(&setupTLSConfigOpts{
+		certFile: l.TLSCertFile,
+		keyFile:  l.TLSKeyFile,
+		caFile:   l.TLSCAFile,
+
+		clientCertOptional: l.TLSClientCertOptional,
+	})
+}
+
+// setupTLSConfigOpts represents TLS configuration setup options.
+type setupTLSConfigOpts struct {
 	certFile string
 	keyFile  string
 	caFile   string // may be empty to skip client's certificate validation
+
+	clientCertOptional bool // if true, only certificates presented by clients are verified
 }
 
-// setupTLSListener returns a new TLS listener or and error.
-func setupTLSListener(opts *setupTLSListenerOpts) (net.Listener, error) {
+// setupTLSConfig returns a new TLS configuration or and error.
+func setupTLSConfig(opts *setupTLSConfigOpts) (*tls.Config, error) {
 	if _, err := os.Stat(opts.certFile); err != nil {
 		return nil, fmt.Errorf("TLS certificate file: %w", err)
 	}
@@ -235,19 +374,21 @@ func setupTLSListener(opts *setupTLSListenerOpts) (net.Listener, error) {
 		}
 
 		config.ClientAuth = tls.RequireAndVerifyClientCert
-		config.ClientCAs = roots
-	}
+		if opts.clientCertOptional {
+			config.ClientAuth = tls.VerifyClientCertIfGiven
+		}
 
-	listener, err := tls.Listen("tcp", opts.addr, &config)
-	if err != nil {
-		return nil, lazyerrors.Error(err)
+		config.ClientCAs = roots
 	}
 
-	return listener, nil
+	return &config, nil
 }
 
 // acceptLoop runs listener's connection accepting loop until context is canceled.
-func acceptLoop(ctx context.Context, listener net.Listener, wg *sync.WaitGroup, l *Listener, logger *zap.Logger) {
+//
+// If tlsConfig is not nil, accepted connections use TLS.
+// If proxyProtocol is true, accepted connections should start with PROXY protocol header.
+func acceptLoop(ctx context.Context, listener net.Listener, tlsConfig *tls.Config, proxyProtocol bool, wg *sync.WaitGroup, l *Listener, logger *zap.Logger) { //nolint:lll // argument list is too long
 	var retry int64
 	for {
 		netConn, err := listener.Accept()
@@ -285,6 +426,15 @@ func acceptLoop(ctx context.Context, listener net.Listener, wg *sync.WaitGroup,
 				wg.Done()
 			}()
 
+			var hsConn net.Conn
+			if hsConn, connErr = handshake(ctx, netConn, tlsConfig, proxyProtocol, l, logger); connErr != nil {
+				return
+			}
+
+			defer l.Metrics.ConnMetrics.ConnectionClosed()
+
+			netConn = hsConn
+
 			remoteAddr := netConn.RemoteAddr().String()
 			if netConn.RemoteAddr().Network() == "unix" {
 				// otherwise, all of them would be "" or "@"
@@ -307,7 +457,10 @@ func acceptLoop(ctx context.Context, listener net.Listener, wg *sync.WaitGroup,
 				l:              l.Logger.Named("// " + connID + " "), // derive from the original unnamed logger
 				handler:        l.Handler,
 				connMetrics:    l.Metrics.ConnMetrics, // share between all conns
+				failPoints:     l.FailPoints,
+				clusterTime:    l.ClusterTime,
 				proxyAddr:      l.ProxyAddr,
+				sqlLog:         l.SQLLog,
 				testRecordsDir: l.TestRecordsDir,
 			}
 
@@ -330,24 +483,24 @@ func acceptLoop(ctx context.Context, listener net.Listener, wg *sync.WaitGroup,
 	}
 }
 
-// TCPAddr returns TCP listener's address.
+// TCPAddr returns the first TCP listener's address.
 // It can be used to determine an actually used port, if it was zero.
 func (l *Listener) TCPAddr() net.Addr {
 	<-l.tcpListenerReady
-	return l.tcpListener.Addr()
+	return l.tcpListeners[0].Addr()
 }
 
-// UnixAddr returns Unix domain socket listener's address.
+// UnixAddr returns the first Unix domain socket listener's address.
 func (l *Listener) UnixAddr() net.Addr {
 	<-l.unixListenerReady
-	return l.unixListener.Addr()
+	return l.unixListeners[0].Addr()
 }
 
-// TLSAddr returns TLS listener's address.
+// TLSAddr returns the first TLS listener's address.
 // It can be used to determine an actually used port, if it was zero.
 func (l *Listener) TLSAddr() net.Addr {
 	<-l.tlsListenerReady
-	return l.tlsListener.Addr()
+	return l.tlsListeners[0].Addr()
 }
 
 // Describe implements prometheus.Collector.
@@ -0,0 +1,224 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PROXY protocol header signatures.
+//
+// See https://www.haproxy.org/download/2.8/doc/proxy-protocol.txt.
+var (
+	proxyV1Signature = []byte("PROXY ")
+	proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+)
+
+const (
+	// proxyV1MaxLen is the maximal length of PROXY protocol v1 header, including CRLF.
+	proxyV1MaxLen = 107
+
+	// proxyHeaderTimeout is the time given to the client (load balancer) to send the PROXY protocol header.
+	proxyHeaderTimeout = 5 * time.Second
+)
+
+// proxyConn wraps net.Conn with the client address received in the PROXY protocol header.
+type proxyConn struct {
+	net.Conn
+	remoteAddr net.Addr
+}
+
+// RemoteAddr implements net.Conn.
+func (c *proxyConn) RemoteAddr() net.Addr {
+	return c.remoteAddr
+}
+
+// proxyTrusted returns true if the given peer address belongs to one of trusted networks,
+// or if there are no trusted networks.
+func proxyTrusted(addr net.Addr, trusted []*net.IPNet) bool {
+	if len(trusted) == 0 {
+		return true
+	}
+
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+
+	for _, n := range trusted {
+		if n.Contains(tcpAddr.IP) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readProxyHeader reads the PROXY protocol v1 or v2 header from the given connection.
+//
+// It returns a connection with the original client address.
+// The header is required; an error is returned if it is missing or invalid.
+// For LOCAL (v2) and UNKNOWN (v1) commands, the address of the connection is kept.
+func readProxyHeader(conn net.Conn) (net.Conn, error) {
+	if err := conn.SetReadDeadline(time.Now().Add(proxyHeaderTimeout)); err != nil {
+		return nil, err
+	}
+
+	// 12 bytes is v2 signature length, and v1 header is always longer
+	prefix := make([]byte, len(proxyV2Signature))
+	if _, err := io.ReadFull(conn, prefix); err != nil {
+		return nil, fmt.Errorf("failed to read PROXY protocol header: %w", err)
+	}
+
+	var addr net.Addr
+	var err error
+
+	switch {
+	case bytes.Equal(prefix, proxyV2Signature):
+		addr, err = readProxyV2(conn)
+	case bytes.HasPrefix(prefix, proxyV1Signature):
+		addr, err = readProxyV1(conn, prefix)
+	default:
+		err = errors.New("PROXY protocol header is missing")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = conn.SetReadDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+
+	if addr == nil {
+		return conn, nil
+	}
+
+	return &proxyConn{Conn: conn, remoteAddr: addr}, nil
+}
+
+// readProxyV1 reads the rest of the PROXY protocol v1 header after already read prefix.
+//
+// It returns nil address for UNKNOWN protocol.
+func readProxyV1(r io.Reader, prefix []byte) (net.Addr, error) {
+	line := bytes.NewBuffer(prefix)
+	b := make([]byte, 1)
+
+	// read byte by byte to not consume client's data after the header
+	for !bytes.HasSuffix(line.Bytes(), []byte("\r\n")) {
+		if line.Len() >= proxyV1MaxLen {
+			return nil, errors.New("PROXY protocol v1 header is too long")
+		}
+
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, fmt.Errorf("failed to read PROXY protocol v1 header: %w", err)
+		}
+
+		line.Write(b)
+	}
+
+	return parseProxyV1(strings.TrimSuffix(line.String(), "\r\n"))
+}
+
+// parseProxyV1 parses PROXY protocol v1 header line without CRLF.
+func parseProxyV1(line string) (net.Addr, error) {
+	fields := strings.Split(line, " ")
+
+	if len(fields) < 2 || fields[0] != "PROXY" {
+		return nil, fmt.Errorf("invalid PROXY protocol v1 header %q", line)
+	}
+
+	switch fields[1] {
+	case "UNKNOWN":
+		return nil, nil
+	case "TCP4", "TCP6":
+		// PROXY TCP4 <src> <dst> <src port> <dst port>
+	default:
+		return nil, fmt.Errorf("unsupported PROXY protocol v1 protocol %q", fields[1])
+	}
+
+	if len(fields) != 6 {
+		return nil, fmt.Errorf("invalid PROXY protocol v1 header %q", line)
+	}
+
+	ip := net.ParseIP(fields[2])
+	if ip == nil || (fields[1] == "TCP4") != (ip.To4() != nil) {
+		return nil, fmt.Errorf("invalid PROXY protocol v1 source address %q", fields[2])
+	}
+
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PROXY protocol v1 source port %q", fields[4])
+	}
+
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// readProxyV2 reads the rest of the PROXY protocol v2 header after the signature.
+//
+// It returns nil address for LOCAL command and unspecified or Unix address families.
+func readProxyV2(r io.Reader) (net.Addr, error) {
+	h := make([]byte, 4)
+	if _, err := io.ReadFull(r, h); err != nil {
+		return nil, fmt.Errorf("failed to read PROXY protocol v2 header: %w", err)
+	}
+
+	if h[0]>>4 != 2 {
+		return nil, fmt.Errorf("unsupported PROXY protocol version %d", h[0]>>4)
+	}
+
+	// always read the whole header, including TLVs, to not leave them for the wire protocol
+	data := make([]byte, binary.BigEndian.Uint16(h[2:]))
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("failed to read PROXY protocol v2 addresses: %w", err)
+	}
+
+	switch cmd := h[0] & 0x0f; cmd {
+	case 0x00: // LOCAL
+		return nil, nil
+	case 0x01: // PROXY
+	default:
+		return nil, fmt.Errorf("unsupported PROXY protocol v2 command %d", cmd)
+	}
+
+	var ipLen int
+
+	switch family := h[1] >> 4; family {
+	case 0x1: // AF_INET
+		ipLen = net.IPv4len
+	case 0x2: // AF_INET6
+		ipLen = net.IPv6len
+	default: // AF_UNSPEC, AF_UNIX
+		return nil, nil
+	}
+
+	// source address, destination address, source port, destination port
+	if len(data) < 2*ipLen+4 {
+		return nil, fmt.Errorf("PROXY protocol v2 addresses are too short: %d bytes", len(data))
+	}
+
+	return &net.TCPAddr{
+		IP:   net.IP(bytes.Clone(data[:ipLen])),
+		Port: int(binary.BigEndian.Uint16(data[2*ipLen:])),
+	}, nil
+}
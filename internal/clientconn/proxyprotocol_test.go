@@ -0,0 +1,132 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadProxyHeader(t *testing.T) {
+	t.Parallel()
+
+	v2 := func(cmd, family byte, addrs ...byte) []byte {
+		b := append([]byte(nil), proxyV2Signature...)
+		b = append(b, 0x20|cmd, family, 0, byte(len(addrs)))
+		return append(b, addrs...)
+	}
+
+	for name, tc := range map[string]struct {
+		header []byte
+		addr   string // empty if the original address should be kept
+		err    string
+	}{
+		"V1TCP4": {
+			header: []byte("PROXY TCP4 192.0.2.1 198.51.100.1 51000 27017\r\n"),
+			addr:   "192.0.2.1:51000",
+		},
+		"V1TCP6": {
+			header: []byte("PROXY TCP6 2001:db8::1 2001:db8::2 51000 27017\r\n"),
+			addr:   "[2001:db8::1]:51000",
+		},
+		"V1Unknown": {
+			header: []byte("PROXY UNKNOWN ffff::1 ffff::2 1 2\r\n"),
+		},
+		"V1Mismatch": {
+			header: []byte("PROXY TCP4 2001:db8::1 198.51.100.1 51000 27017\r\n"),
+			err:    `invalid PROXY protocol v1 source address "2001:db8::1"`,
+		},
+		"V1TooLong": {
+			header: append([]byte("PROXY TCP4 "), make([]byte, 200)...),
+			err:    "PROXY protocol v1 header is too long",
+		},
+		"V2TCP4": {
+			header: v2(0x1, 0x11, 192, 0, 2, 1, 198, 51, 100, 1, 0xc7, 0x38, 0x69, 0x89),
+			addr:   "192.0.2.1:51000",
+		},
+		"V2Local": {
+			header: v2(0x0, 0x00),
+		},
+		"V2Short": {
+			header: v2(0x1, 0x11, 192, 0, 2, 1),
+			err:    "PROXY protocol v2 addresses are too short: 4 bytes",
+		},
+		"Missing": {
+			header: []byte("\x3a\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00"),
+			err:    "PROXY protocol header is missing",
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client, server := net.Pipe()
+			t.Cleanup(func() {
+				client.Close()
+				server.Close()
+			})
+
+			payload := []byte("wire protocol message")
+
+			go func() {
+				client.Write(tc.header)
+				client.Write(payload)
+			}()
+
+			conn, err := readProxyHeader(server)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			if tc.addr == "" {
+				assert.Equal(t, server.RemoteAddr(), conn.RemoteAddr())
+			} else {
+				assert.Equal(t, tc.addr, conn.RemoteAddr().String())
+			}
+
+			// data after the header should not be consumed
+			b := make([]byte, len(payload))
+			_, err = io.ReadFull(conn, b)
+			require.NoError(t, err)
+			assert.Equal(t, payload, b)
+		})
+	}
+}
+
+func TestProxyTrusted(t *testing.T) {
+	t.Parallel()
+
+	_, n4, err := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+
+	_, n6, err := net.ParseCIDR("fd00::/8")
+	require.NoError(t, err)
+
+	trusted := []*net.IPNet{n4, n6}
+
+	assert.True(t, proxyTrusted(&net.TCPAddr{IP: net.ParseIP("10.1.2.3"), Port: 1234}, trusted))
+	assert.True(t, proxyTrusted(&net.TCPAddr{IP: net.ParseIP("fd00::1"), Port: 1234}, trusted))
+	assert.False(t, proxyTrusted(&net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1234}, trusted))
+	assert.False(t, proxyTrusted(&net.UnixAddr{Name: "/tmp/ferretdb.sock", Net: "unix"}, trusted))
+
+	assert.True(t, proxyTrusted(&net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1234}, nil))
+}
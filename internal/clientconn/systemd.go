@@ -0,0 +1,84 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// systemdFirstFD is the first file descriptor passed by systemd socket activation (SD_LISTEN_FDS_START).
+const systemdFirstFD = 3
+
+// systemdListener represents a listening socket passed by systemd.
+type systemdListener struct {
+	net.Listener
+	name string // FileDescriptorName= from the socket unit
+}
+
+// systemdListeners returns listening sockets passed by systemd socket activation.
+//
+// See https://www.freedesktop.org/software/systemd/man/sd_listen_fds.html for protocol details.
+// It returns nil if sockets were not passed to the process with the given pid.
+// Environment is accessed with the given getenv function, and descriptors start with firstFD.
+func systemdListeners(getenv func(string) string, pid, firstFD int) ([]systemdListener, error) {
+	if getenv("LISTEN_PID") != strconv.Itoa(pid) {
+		return nil, nil
+	}
+
+	n, err := strconv.Atoi(getenv("LISTEN_FDS"))
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS value %q", getenv("LISTEN_FDS"))
+	}
+
+	var names []string
+	if v := getenv("LISTEN_FDNAMES"); v != "" {
+		names = strings.Split(v, ":")
+	}
+
+	res := make([]systemdListener, 0, n)
+
+	for i := 0; i < n; i++ {
+		name := "unknown"
+		if i < len(names) {
+			name = names[i]
+		}
+
+		f := os.NewFile(uintptr(firstFD+i), name)
+
+		var lis net.Listener
+		lis, err = net.FileListener(f)
+
+		// FileListener duplicates the descriptor
+		f.Close()
+
+		if err != nil {
+			for _, l := range res {
+				l.Close()
+			}
+
+			return nil, lazyerrors.Errorf("systemd socket %d (%q): %w", firstFD+i, name, err)
+		}
+
+		res = append(res, systemdListener{Listener: lis, name: name})
+	}
+
+	return res, nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unix
+
+package clientconn
+
+import (
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemdListeners(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NotActivated", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"LISTEN_PID": "1",
+			"LISTEN_FDS": "1",
+		}
+
+		res, err := systemdListeners(func(k string) string { return env[k] }, 42, systemdFirstFD)
+		require.NoError(t, err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("InvalidFDs", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"LISTEN_PID": "42",
+			"LISTEN_FDS": "many",
+		}
+
+		_, err := systemdListeners(func(k string) string { return env[k] }, 42, systemdFirstFD)
+		require.Error(t, err)
+	})
+
+	t.Run("Socket", func(t *testing.T) {
+		t.Parallel()
+
+		orig, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		t.Cleanup(func() { orig.Close() })
+
+		f, err := orig.(*net.TCPListener).File()
+		require.NoError(t, err)
+
+		t.Cleanup(func() { f.Close() })
+
+		// systemdListeners closes passed descriptor, so pass a duplicate
+		fd, err := syscall.Dup(int(f.Fd()))
+		require.NoError(t, err)
+
+		env := map[string]string{
+			"LISTEN_PID":     "42",
+			"LISTEN_FDS":     "1",
+			"LISTEN_FDNAMES": "tls",
+		}
+
+		res, err := systemdListeners(func(k string) string { return env[k] }, 42, fd)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+
+		t.Cleanup(func() { res[0].Close() })
+
+		assert.Equal(t, "tls", res[0].name)
+		assert.Equal(t, orig.Addr().String(), res[0].Addr().String())
+
+		c, err := net.Dial("tcp", res[0].Addr().String())
+		require.NoError(t, err)
+
+		t.Cleanup(func() { c.Close() })
+
+		s, err := res[0].Accept()
+		require.NoError(t, err)
+
+		t.Cleanup(func() { s.Close() })
+
+		assert.Equal(t, c.LocalAddr().String(), s.RemoteAddr().String())
+	})
+}
@@ -27,3 +27,36 @@ type Stage interface {
 	// Process applies an aggregate stage on documents from iterator.
 	Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error)
 }
+
+// CollectionQuery returns an iterator over documents of the given collection of the current database.
+// It is used by stages that read other collections, such as $lookup.
+//
+// The filter, if set, is pushed down to the backend where possible;
+// returned documents may not match it, so callers should filter them.
+type CollectionQuery func(ctx context.Context, collection string, filter *types.Document) (types.DocumentsIterator, error)
+
+// CollectionWriter reads and writes documents of collections.
+// Empty database name means the current database.
+// It is used by stages that write results to collections, such as $out and $merge.
+type CollectionWriter interface {
+	// Query returns an iterator over all documents of the given collection.
+	Query(ctx context.Context, db, collection string) (types.DocumentsIterator, error)
+
+	// Write writes documents to the given collection, creating it if it does not exist.
+	Write(ctx context.Context, params *WriteParams) error
+}
+
+// WriteParams represents the parameters of CollectionWriter.Write method.
+type WriteParams struct {
+	DB         string
+	Collection string
+
+	// Insert contains documents to insert; _id is generated for documents without it.
+	Insert []*types.Document
+
+	// Update contains documents that replace existing documents with the same _id.
+	Update []*types.Document
+
+	// Replace removes all existing documents before Insert documents are inserted.
+	Replace bool
+}
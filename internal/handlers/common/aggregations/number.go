@@ -17,6 +17,8 @@ package aggregations
 import (
 	"math"
 	"math/big"
+
+	"github.com/FerretDB/FerretDB/internal/types"
 )
 
 // SumNumbers accumulate numbers and returns the result of summation.
@@ -69,3 +71,99 @@ func SumNumbers(vs ...any) any {
 
 	return integer
 }
+
+// AvgNumbers returns the average of numbers as float64. It ignores non-number values.
+// For `vs` without numbers, it returns null.
+//
+// Numbers are summed exactly, so the result is rounded only once.
+func AvgNumbers(vs ...any) any {
+	// enough precision to sum any doubles exactly
+	sum := new(big.Float).SetPrec(2200)
+
+	var count int
+	var nan, posInf, negInf bool
+
+	for _, v := range vs {
+		switch v := v.(type) {
+		case float64:
+			switch {
+			case math.IsNaN(v):
+				nan = true
+			case math.IsInf(v, 1):
+				posInf = true
+			case math.IsInf(v, -1):
+				negInf = true
+			default:
+				sum.Add(sum, big.NewFloat(v))
+			}
+		case int32:
+			sum.Add(sum, new(big.Float).SetInt64(int64(v)))
+		case int64:
+			sum.Add(sum, new(big.Float).SetInt64(v))
+		default:
+			// ignore non-number
+			continue
+		}
+
+		count++
+	}
+
+	switch {
+	case count == 0:
+		return types.Null
+	case nan || (posInf && negInf):
+		return math.NaN()
+	case posInf:
+		return math.Inf(1)
+	case negInf:
+		return math.Inf(-1)
+	}
+
+	res, _ := sum.Quo(sum, new(big.Float).SetInt64(int64(count))).Float64()
+
+	return res
+}
+
+// StdDevNumbers returns the population standard deviation of numbers as float64,
+// or the sample standard deviation if sample is true. It ignores non-number values.
+// For `vs` without numbers, or with less than two numbers for sample, it returns null.
+//
+// It uses Welford's algorithm that is numerically stable.
+func StdDevNumbers(sample bool, vs ...any) any {
+	var mean, m2 float64
+
+	var count int
+
+	for _, v := range vs {
+		var x float64
+
+		switch v := v.(type) {
+		case float64:
+			x = v
+		case int32:
+			x = float64(v)
+		case int64:
+			x = float64(v)
+		default:
+			// ignore non-number
+			continue
+		}
+
+		count++
+
+		delta := x - mean
+		mean += delta / float64(count)
+		m2 += delta * (x - mean)
+	}
+
+	switch {
+	case count == 0:
+		return types.Null
+	case sample && count == 1:
+		return types.Null
+	case sample:
+		return math.Sqrt(m2 / float64(count-1))
+	default:
+		return math.Sqrt(m2 / float64(count))
+	}
+}
@@ -103,7 +103,20 @@ func NewAccumulator(stage, key string, value any) (Accumulator, error) {
 // Accumulators maps all aggregation accumulators.
 var Accumulators = map[string]newAccumulatorFunc{
 	// sorted alphabetically
-	"$count": newCount,
-	"$sum":   newSum,
+	"$addToSet":   newAddToSet,
+	"$avg":        newAvg,
+	"$bottomN":    newBottomN,
+	"$count":      newCount,
+	"$first":      newFirst,
+	"$firstN":     newFirstN,
+	"$last":       newLast,
+	"$lastN":      newLastN,
+	"$max":        newMax,
+	"$min":        newMin,
+	"$push":       newPush,
+	"$stdDevPop":  newStdDevPop,
+	"$stdDevSamp": newStdDevSamp,
+	"$sum":        newSum,
+	"$topN":       newTopN,
 	// please keep sorted alphabetically
 }
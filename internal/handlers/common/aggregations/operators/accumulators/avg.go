@@ -0,0 +1,94 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accumulators
+
+import (
+	"errors"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// avg represents $avg accumulator.
+type avg struct {
+	expression operators.Operator
+}
+
+// newAvg creates a new $avg accumulator.
+func newAvg(args ...any) (Accumulator, error) {
+	if len(args) != 1 {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageGroupUnaryOperator,
+			"The $avg accumulator is a unary operator",
+			"$avg (accumulator)",
+		)
+	}
+
+	expression, err := operators.NewExpression(args[0], "$avg (accumulator)")
+	if err != nil {
+		return nil, err
+	}
+
+	return &avg{
+		expression: expression,
+	}, nil
+}
+
+// Accumulate implements Accumulator interface.
+// It returns the average of numeric expression values of the group documents,
+// or null if there are none.
+func (a *avg) Accumulate(iter types.DocumentsIterator) (any, error) {
+	values, err := accumulateValues(iter, a.expression)
+	if err != nil {
+		return nil, err
+	}
+
+	return aggregations.AvgNumbers(values...), nil
+}
+
+// accumulateValues returns expression values for all documents of the iterator.
+// It closes the iterator.
+func accumulateValues(iter types.DocumentsIterator, expression operators.Operator) ([]any, error) {
+	defer iter.Close()
+
+	var values []any
+
+	for {
+		_, doc, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			return values, nil
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		v, err := expression.Process(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, v)
+	}
+}
+
+// check interfaces
+var (
+	_ Accumulator = (*avg)(nil)
+)
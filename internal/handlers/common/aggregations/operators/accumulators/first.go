@@ -0,0 +1,73 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accumulators
+
+import (
+	"errors"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// first represents $first accumulator.
+type first struct {
+	expression operators.Operator
+}
+
+// newFirst creates a new $first accumulator.
+func newFirst(args ...any) (Accumulator, error) {
+	if len(args) != 1 {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageGroupUnaryOperator,
+			"The $first accumulator is a unary operator",
+			"$first (accumulator)",
+		)
+	}
+
+	expression, err := operators.NewExpression(args[0], "$first (accumulator)")
+	if err != nil {
+		return nil, err
+	}
+
+	return &first{
+		expression: expression,
+	}, nil
+}
+
+// Accumulate implements Accumulator interface.
+// It returns the expression value of the first document in the group,
+// null is returned for missing field.
+func (f *first) Accumulate(iter types.DocumentsIterator) (any, error) {
+	defer iter.Close()
+
+	_, doc, err := iter.Next()
+	if errors.Is(err, iterator.ErrIteratorDone) {
+		return types.Null, nil
+	}
+
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return f.expression.Process(doc)
+}
+
+// check interfaces
+var (
+	_ Accumulator = (*first)(nil)
+)
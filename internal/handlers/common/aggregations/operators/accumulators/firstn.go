@@ -0,0 +1,176 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accumulators
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// firstN represents $firstN and $lastN accumulators.
+type firstN struct {
+	input operators.Operator
+	n     int
+	last  bool
+}
+
+// newFirstN creates a new $firstN accumulator.
+func newFirstN(args ...any) (Accumulator, error) {
+	return newFirstOrLastN("$firstN", false, args...)
+}
+
+// newLastN creates a new $lastN accumulator.
+func newLastN(args ...any) (Accumulator, error) {
+	return newFirstOrLastN("$lastN", true, args...)
+}
+
+// newFirstOrLastN validates `{input: <expression>, n: <number>}` arguments
+// and creates a new $firstN or $lastN accumulator.
+func newFirstOrLastN(operator string, last bool, args ...any) (Accumulator, error) {
+	n, fields, err := nArgs(operator, args, "input")
+	if err != nil {
+		return nil, err
+	}
+
+	v, ok := fields["input"]
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrAccumulatorNMissingInput,
+			fmt.Sprintf("Missing value for 'input' in %s", operator),
+			operator+" (accumulator)",
+		)
+	}
+
+	input, err := operators.NewExpression(v, operator+" (accumulator)")
+	if err != nil {
+		return nil, err
+	}
+
+	return &firstN{
+		input: input,
+		n:     n,
+		last:  last,
+	}, nil
+}
+
+// Accumulate implements Accumulator interface.
+// It returns an array of input expression values of the first (or last) n documents in the group.
+// Unlike $first and $last, missing fields are included as null values.
+func (f *firstN) Accumulate(iter types.DocumentsIterator) (any, error) {
+	defer iter.Close()
+
+	docs, err := iterator.ConsumeValues(iter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(docs) > f.n {
+		if f.last {
+			docs = docs[len(docs)-f.n:]
+		} else {
+			docs = docs[:f.n]
+		}
+	}
+
+	res := types.MakeArray(len(docs))
+
+	for _, doc := range docs {
+		v, err := f.input.Process(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Append(v)
+	}
+
+	return res, nil
+}
+
+// nArgs validates arguments of N-variant accumulators: a document with `n` and given keys.
+// It returns `n` value and other fields of the arguments document.
+func nArgs(operator string, args []any, keys ...string) (int, map[string]any, error) {
+	var doc *types.Document
+
+	if len(args) == 1 {
+		doc, _ = args[0].(*types.Document)
+	}
+
+	if doc == nil {
+		var found any = types.MakeArray(0)
+		if len(args) == 1 {
+			found = args[0]
+		}
+
+		return 0, nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrAccumulatorNNotObject,
+			fmt.Sprintf("specification must be an object; found %s", types.FormatAnyValue(found)),
+			operator+" (accumulator)",
+		)
+	}
+
+	fields := make(map[string]any, doc.Len())
+
+	for _, key := range doc.Keys() {
+		if key != "n" && !slices.Contains(keys, key) {
+			return 0, nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrAccumulatorNUnknownArgument,
+				fmt.Sprintf("Unknown argument for 'n' operator: %s", key),
+				operator+" (accumulator)",
+			)
+		}
+
+		fields[key] = must.NotFail(doc.Get(key))
+	}
+
+	v, ok := fields["n"]
+	if !ok {
+		return 0, nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrAccumulatorNMissingN,
+			fmt.Sprintf("Missing value for 'n' in %s", operator),
+			operator+" (accumulator)",
+		)
+	}
+
+	n, err := commonparams.GetWholeNumberParam(v)
+	if err != nil {
+		return 0, nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrAccumulatorNNotIntegral,
+			fmt.Sprintf("Value for 'n' must be of integral type, but found %s", types.FormatAnyValue(v)),
+			operator+" (accumulator)",
+		)
+	}
+
+	if n <= 0 {
+		return 0, nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrAccumulatorNNotPositive,
+			fmt.Sprintf("'n' must be greater than 0, found %d", n),
+			operator+" (accumulator)",
+		)
+	}
+
+	return int(n), fields, nil
+}
+
+// check interfaces
+var (
+	_ Accumulator = (*firstN)(nil)
+)
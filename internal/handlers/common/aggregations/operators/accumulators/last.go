@@ -0,0 +1,83 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accumulators
+
+import (
+	"errors"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// last represents $last accumulator.
+type last struct {
+	expression operators.Operator
+}
+
+// newLast creates a new $last accumulator.
+func newLast(args ...any) (Accumulator, error) {
+	if len(args) != 1 {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageGroupUnaryOperator,
+			"The $last accumulator is a unary operator",
+			"$last (accumulator)",
+		)
+	}
+
+	expression, err := operators.NewExpression(args[0], "$last (accumulator)")
+	if err != nil {
+		return nil, err
+	}
+
+	return &last{
+		expression: expression,
+	}, nil
+}
+
+// Accumulate implements Accumulator interface.
+// It returns the expression value of the last document in the group,
+// null is returned for missing field.
+func (l *last) Accumulate(iter types.DocumentsIterator) (any, error) {
+	defer iter.Close()
+
+	var lastDoc *types.Document
+
+	for {
+		_, doc, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		lastDoc = doc
+	}
+
+	if lastDoc == nil {
+		return types.Null, nil
+	}
+
+	return l.expression.Process(lastDoc)
+}
+
+// check interfaces
+var (
+	_ Accumulator = (*last)(nil)
+)
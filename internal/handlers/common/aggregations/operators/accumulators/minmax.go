@@ -0,0 +1,93 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accumulators
+
+import (
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// minMax represents $min and $max accumulators.
+type minMax struct {
+	expression operators.Operator
+	maximum    bool
+}
+
+// newMin creates a new $min accumulator.
+func newMin(args ...any) (Accumulator, error) {
+	return newMinMax("$min", false, args...)
+}
+
+// newMax creates a new $max accumulator.
+func newMax(args ...any) (Accumulator, error) {
+	return newMinMax("$max", true, args...)
+}
+
+// newMinMax creates a new $min or $max accumulator.
+func newMinMax(operator string, maximum bool, args ...any) (Accumulator, error) {
+	if len(args) != 1 {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageGroupUnaryOperator,
+			"The "+operator+" accumulator is a unary operator",
+			operator+" (accumulator)",
+		)
+	}
+
+	expression, err := operators.NewExpression(args[0], operator+" (accumulator)")
+	if err != nil {
+		return nil, err
+	}
+
+	return &minMax{
+		expression: expression,
+		maximum:    maximum,
+	}, nil
+}
+
+// Accumulate implements Accumulator interface.
+// It returns the minimal or maximal expression value of the group documents
+// using BSON comparison order. Nulls and missing fields are ignored;
+// null is returned if there are no other values.
+func (m *minMax) Accumulate(iter types.DocumentsIterator) (any, error) {
+	values, err := accumulateValues(iter, m.expression)
+	if err != nil {
+		return nil, err
+	}
+
+	want := types.Less
+	if m.maximum {
+		want = types.Greater
+	}
+
+	var res any = types.Null
+
+	for _, v := range values {
+		if v == types.Null {
+			continue
+		}
+
+		if res == types.Null || types.CompareForAggregation(v, res) == want {
+			res = v
+		}
+	}
+
+	return res, nil
+}
+
+// check interfaces
+var (
+	_ Accumulator = (*minMax)(nil)
+)
@@ -0,0 +1,137 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accumulators
+
+import (
+	"errors"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// push represents $push and $addToSet accumulators.
+type push struct {
+	expression operators.Operator
+	fieldPath  *aggregations.Expression // nil if the argument is not a field path
+	unique     bool
+}
+
+// newPush creates a new $push accumulator.
+func newPush(args ...any) (Accumulator, error) {
+	return newPushOrAddToSet("$push", false, args...)
+}
+
+// newAddToSet creates a new $addToSet accumulator.
+func newAddToSet(args ...any) (Accumulator, error) {
+	return newPushOrAddToSet("$addToSet", true, args...)
+}
+
+// newPushOrAddToSet creates a new $push or $addToSet accumulator.
+func newPushOrAddToSet(operator string, unique bool, args ...any) (Accumulator, error) {
+	if len(args) != 1 {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageGroupUnaryOperator,
+			"The "+operator+" accumulator is a unary operator",
+			operator+" (accumulator)",
+		)
+	}
+
+	expression, err := operators.NewExpression(args[0], operator+" (accumulator)")
+	if err != nil {
+		return nil, err
+	}
+
+	p := &push{
+		expression: expression,
+		unique:     unique,
+	}
+
+	if s, ok := args[0].(string); ok {
+		// missing fields are not added to the result,
+		// so field path is evaluated separately to tell them from nulls
+		if fieldPath, err := aggregations.NewExpression(s, nil); err == nil {
+			p.fieldPath = fieldPath
+		}
+	}
+
+	return p, nil
+}
+
+// Accumulate implements Accumulator interface.
+// It returns an array of expression values of the group documents.
+// For $addToSet, duplicate values are added only once.
+// Missing fields are not added.
+func (p *push) Accumulate(iter types.DocumentsIterator) (any, error) {
+	defer iter.Close()
+
+	res := types.MakeArray(0)
+
+	for {
+		_, doc, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			return res, nil
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		var v any
+
+		if p.fieldPath != nil {
+			if v, err = p.fieldPath.Evaluate(doc); err != nil {
+				// missing field
+				continue
+			}
+		} else {
+			if v, err = p.expression.Process(doc); err != nil {
+				return nil, err
+			}
+		}
+
+		if p.unique && containsEqual(res, v) {
+			continue
+		}
+
+		res.Append(v)
+	}
+}
+
+// containsEqual returns true if the array contains a value equal to the given one.
+// Numbers of different types are equal if they have the same value.
+func containsEqual(arr *types.Array, v any) bool {
+	iter := arr.Iterator()
+	defer iter.Close()
+
+	for {
+		_, elem, err := iter.Next()
+		if err != nil {
+			return false
+		}
+
+		if types.CompareForAggregation(elem, v) == types.Equal {
+			return true
+		}
+	}
+}
+
+// check interfaces
+var (
+	_ Accumulator = (*push)(nil)
+)
@@ -0,0 +1,76 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accumulators
+
+import (
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// stdDev represents $stdDevPop and $stdDevSamp accumulators.
+type stdDev struct {
+	expression operators.Operator
+	sample     bool
+}
+
+// newStdDevPop creates a new $stdDevPop accumulator.
+func newStdDevPop(args ...any) (Accumulator, error) {
+	return newStdDev("$stdDevPop", false, args...)
+}
+
+// newStdDevSamp creates a new $stdDevSamp accumulator.
+func newStdDevSamp(args ...any) (Accumulator, error) {
+	return newStdDev("$stdDevSamp", true, args...)
+}
+
+// newStdDev creates a new $stdDevPop or $stdDevSamp accumulator.
+func newStdDev(operator string, sample bool, args ...any) (Accumulator, error) {
+	if len(args) != 1 {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageGroupUnaryOperator,
+			"The "+operator+" accumulator is a unary operator",
+			operator+" (accumulator)",
+		)
+	}
+
+	expression, err := operators.NewExpression(args[0], operator+" (accumulator)")
+	if err != nil {
+		return nil, err
+	}
+
+	return &stdDev{
+		expression: expression,
+		sample:     sample,
+	}, nil
+}
+
+// Accumulate implements Accumulator interface.
+// It returns the population or sample standard deviation of numeric expression values
+// of the group documents, or null if there are not enough of them.
+func (s *stdDev) Accumulate(iter types.DocumentsIterator) (any, error) {
+	values, err := accumulateValues(iter, s.expression)
+	if err != nil {
+		return nil, err
+	}
+
+	return aggregations.StdDevNumbers(s.sample, values...), nil
+}
+
+// check interfaces
+var (
+	_ Accumulator = (*stdDev)(nil)
+)
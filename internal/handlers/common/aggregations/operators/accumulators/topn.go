@@ -0,0 +1,143 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accumulators
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// topN represents $topN and $bottomN accumulators.
+type topN struct {
+	sortBy *types.Document
+	output operators.Operator
+	n      int
+	bottom bool
+}
+
+// newTopN creates a new $topN accumulator.
+func newTopN(args ...any) (Accumulator, error) {
+	return newTopOrBottomN("$topN", false, args...)
+}
+
+// newBottomN creates a new $bottomN accumulator.
+func newBottomN(args ...any) (Accumulator, error) {
+	return newTopOrBottomN("$bottomN", true, args...)
+}
+
+// newTopOrBottomN validates `{n: <number>, sortBy: <sort specification>, output: <expression>}` arguments
+// and creates a new $topN or $bottomN accumulator.
+func newTopOrBottomN(operator string, bottom bool, args ...any) (Accumulator, error) {
+	n, fields, err := nArgs(operator, args, "sortBy", "output")
+	if err != nil {
+		return nil, err
+	}
+
+	v, ok := fields["sortBy"]
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrAccumulatorTopMissingSortBy,
+			fmt.Sprintf("Missing value for 'sortBy' in %s", operator),
+			operator+" (accumulator)",
+		)
+	}
+
+	sortBy, ok := v.(*types.Document)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrAccumulatorTopSortByType,
+			fmt.Sprintf("expected 'sortBy' to be an object, found %s", types.FormatAnyValue(v)),
+			operator+" (accumulator)",
+		)
+	}
+
+	for _, key := range sortBy.Keys() {
+		if _, err = common.GetSortType(key, must.NotFail(sortBy.Get(key))); err != nil {
+			return nil, err
+		}
+	}
+
+	if v, ok = fields["output"]; !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrAccumulatorTopMissingOutput,
+			fmt.Sprintf("Missing value for 'output' in %s", operator),
+			operator+" (accumulator)",
+		)
+	}
+
+	output, err := operators.NewExpression(v, operator+" (accumulator)")
+	if err != nil {
+		return nil, err
+	}
+
+	return &topN{
+		sortBy: sortBy,
+		output: output,
+		n:      n,
+		bottom: bottom,
+	}, nil
+}
+
+// Accumulate implements Accumulator interface.
+// It sorts documents of the group by sortBy and returns an array of output expression values
+// of the first (for $topN) or last (for $bottomN) n documents in that order.
+func (t *topN) Accumulate(iter types.DocumentsIterator) (any, error) {
+	defer iter.Close()
+
+	docs, err := iterator.ConsumeValues(iter)
+	if err != nil {
+		return nil, err
+	}
+
+	// do not reorder documents of the group, they are shared with other accumulators
+	docs = slices.Clone(docs)
+
+	if err = common.SortDocuments(docs, t.sortBy); err != nil {
+		return nil, err
+	}
+
+	if len(docs) > t.n {
+		if t.bottom {
+			docs = docs[len(docs)-t.n:]
+		} else {
+			docs = docs[:t.n]
+		}
+	}
+
+	res := types.MakeArray(len(docs))
+
+	for _, doc := range docs {
+		v, err := t.output.Process(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Append(v)
+	}
+
+	return res, nil
+}
+
+// check interfaces
+var (
+	_ Accumulator = (*topN)(nil)
+)
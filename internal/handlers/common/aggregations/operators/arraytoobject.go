@@ -0,0 +1,191 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package operators provides aggregation operators.
+package operators
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// arrayToObject represents `$arrayToObject` operator.
+type arrayToObject struct {
+	input any
+}
+
+// newArrayToObject returns `$arrayToObject` operator.
+func newArrayToObject(args ...any) (Operator, error) {
+	if len(args) != 1 {
+		return nil, newOperatorError(
+			ErrArgsInvalidLen,
+			"$arrayToObject",
+			fmt.Sprintf("Expression $arrayToObject takes exactly 1 arguments. %d were passed in.", len(args)),
+		)
+	}
+
+	return &arrayToObject{
+		input: args[0],
+	}, nil
+}
+
+// Process implements Operator interface.
+// It converts an array of `[<key>, <value>]` pairs or of `{k: <key>, v: <value>}` documents to a document.
+// If the same key is used more than once, the last value is used.
+// It returns null if input is null or missing.
+func (a *arrayToObject) Process(doc *types.Document) (any, error) {
+	input, err := evaluateExpression(a.input, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var arr *types.Array
+
+	switch input := input.(type) {
+	case *types.Array:
+		arr = input
+	case types.NullType:
+		return types.Null, nil
+	default:
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrArrayToObjectNotArray,
+			"$arrayToObject requires an array input, found: "+commonparams.AliasFromType(input),
+			"$arrayToObject",
+		)
+	}
+
+	res := new(types.Document)
+
+	if arr.Len() == 0 {
+		return res, nil
+	}
+
+	_, pairs := must.NotFail(arr.Get(0)).(*types.Array)
+
+	for i := 0; i < arr.Len(); i++ {
+		var k string
+		var v any
+
+		if pairs {
+			k, v, err = arrayToObjectPair(must.NotFail(arr.Get(i)))
+		} else {
+			k, v, err = arrayToObjectKV(must.NotFail(arr.Get(i)))
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.ContainsRune(k, 0) {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrArrayToObjectKeyNullByte,
+				"Key field cannot contain an embedded null byte",
+				"$arrayToObject",
+			)
+		}
+
+		res.Set(k, v)
+	}
+
+	return res, nil
+}
+
+// arrayToObjectPair returns key and value of `[<key>, <value>]` element.
+func arrayToObjectPair(elem any) (string, any, error) {
+	pair, ok := elem.(*types.Array)
+	if !ok {
+		return "", nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrArrayToObjectInconsistentArray,
+			"$arrayToObject requires a consistent input format. Elements must all be arrays or all be objects. "+
+				"Array was detected, now found: "+commonparams.AliasFromType(elem),
+			"$arrayToObject",
+		)
+	}
+
+	if pair.Len() != 2 {
+		return "", nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrArrayToObjectArraySize,
+			fmt.Sprintf("$arrayToObject requires an array of size 2 arrays,found array of size: %d", pair.Len()),
+			"$arrayToObject",
+		)
+	}
+
+	key := must.NotFail(pair.Get(0))
+
+	k, ok := key.(string)
+	if !ok {
+		return "", nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrArrayToObjectArrayKeyType,
+			"$arrayToObject requires an array of key-value pairs, where the key must be of type string. Found key type: "+
+				commonparams.AliasFromType(key),
+			"$arrayToObject",
+		)
+	}
+
+	return k, must.NotFail(pair.Get(1)), nil
+}
+
+// arrayToObjectKV returns key and value of `{k: <key>, v: <value>}` element.
+func arrayToObjectKV(elem any) (string, any, error) {
+	kv, ok := elem.(*types.Document)
+	if !ok {
+		return "", nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrArrayToObjectInconsistentObject,
+			"$arrayToObject requires a consistent input format. Elements must all be arrays or all be objects. "+
+				"Object was detected, now found: "+commonparams.AliasFromType(elem),
+			"$arrayToObject",
+		)
+	}
+
+	if kv.Len() != 2 {
+		return "", nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrArrayToObjectKeysLen,
+			fmt.Sprintf("$arrayToObject requires an object keys of 'k' and 'v'. Found incorrect number of keys:%d", kv.Len()),
+			"$arrayToObject",
+		)
+	}
+
+	if !kv.Has("k") || !kv.Has("v") {
+		return "", nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrArrayToObjectMissingKV,
+			"$arrayToObject requires an object with keys 'k' and 'v'. Missing either or both keys from: "+
+				types.FormatAnyValue(kv),
+			"$arrayToObject",
+		)
+	}
+
+	key := must.NotFail(kv.Get("k"))
+
+	k, ok := key.(string)
+	if !ok {
+		return "", nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrArrayToObjectObjectKeyType,
+			"$arrayToObject requires an object with keys 'k' and 'v', where the value of 'k' must be of type string. Found type: "+
+				commonparams.AliasFromType(key),
+			"$arrayToObject",
+		)
+	}
+
+	return k, must.NotFail(kv.Get("v")), nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*arrayToObject)(nil)
+)
@@ -0,0 +1,81 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package operators provides aggregation operators.
+package operators
+
+import (
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// avg represents `$avg` operator.
+type avg struct {
+	args []any
+}
+
+// newAvg returns `$avg` operator.
+func newAvg(args ...any) (Operator, error) {
+	return &avg{
+		args: args,
+	}, nil
+}
+
+// Process implements Operator interface.
+// It returns the average of numeric values ignoring other types, or null if there are none.
+func (a *avg) Process(doc *types.Document) (any, error) {
+	values, err := statisticsValues(a.args, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return aggregations.AvgNumbers(values...), nil
+}
+
+// statisticsValues evaluates arguments of statistical operators such as `$avg` and `$stdDevPop`.
+// A single argument that evaluates to an array is expanded to its elements.
+func statisticsValues(args []any, doc *types.Document) ([]any, error) {
+	values := make([]any, 0, len(args))
+
+	for _, arg := range args {
+		v, err := evaluateExpression(arg, doc)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, v)
+	}
+
+	if len(values) != 1 {
+		return values, nil
+	}
+
+	arr, ok := values[0].(*types.Array)
+	if !ok {
+		return values, nil
+	}
+
+	values = make([]any, arr.Len())
+	for i := range values {
+		values[i] = must.NotFail(arr.Get(i))
+	}
+
+	return values, nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*avg)(nil)
+)
@@ -0,0 +1,111 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package operators provides aggregation operators.
+package operators
+
+import (
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// boolean represents boolean operators `$and`, `$or`, and `$not`.
+type boolean struct {
+	operator string
+	exprs    []any
+}
+
+// newAnd returns `$and` operator.
+func newAnd(args ...any) (Operator, error) {
+	return &boolean{
+		operator: "$and",
+		exprs:    args,
+	}, nil
+}
+
+// newOr returns `$or` operator.
+func newOr(args ...any) (Operator, error) {
+	return &boolean{
+		operator: "$or",
+		exprs:    args,
+	}, nil
+}
+
+// newNot returns `$not` operator.
+func newNot(args ...any) (Operator, error) {
+	if len(args) != 1 {
+		return nil, newOperatorError(
+			ErrArgsInvalidLen,
+			"$not",
+			fmt.Sprintf("Expression $not takes exactly 1 arguments. %d were passed in.", len(args)),
+		)
+	}
+
+	return &boolean{
+		operator: "$not",
+		exprs:    args,
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// `$and` and `$or` evaluate expressions until the result is known;
+// `$and` of no expressions is true, and `$or` of no expressions is false.
+func (b *boolean) Process(doc *types.Document) (any, error) {
+	for _, e := range b.exprs {
+		v, err := evaluateExpression(e, doc)
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := IsTrue(v); b.operator {
+		case "$and":
+			if !t {
+				return false, nil
+			}
+		case "$or":
+			if t {
+				return true, nil
+			}
+		case "$not":
+			return !t, nil
+		default:
+			panic(fmt.Sprintf("unexpected boolean operator %q", b.operator))
+		}
+	}
+
+	return b.operator == "$and", nil
+}
+
+// IsTrue returns true if the given result of expression evaluation is considered true.
+//
+// Null, false, and zero numbers are false; all other values (including empty arrays) are true.
+func IsTrue(v any) bool {
+	switch v := v.(type) {
+	case float64, int32, int64:
+		return types.Compare(v, int32(0)) != types.Equal
+	case bool:
+		return v
+	case types.NullType:
+		return false
+	default:
+		return true
+	}
+}
+
+// check interfaces
+var (
+	_ Operator = (*boolean)(nil)
+)
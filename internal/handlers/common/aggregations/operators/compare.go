@@ -0,0 +1,127 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package operators provides aggregation operators.
+package operators
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// compare represents comparison operators `$cmp`, `$eq`, `$gt`, `$gte`, `$lt`, `$lte`, and `$ne`.
+type compare struct {
+	operator string
+	exprs    [2]any
+}
+
+// newCompareFunc returns a function that validates exactly two arguments
+// and returns the given comparison operator.
+func newCompareFunc(operator string) newOperatorFunc {
+	return func(args ...any) (Operator, error) {
+		if len(args) != 2 {
+			return nil, newOperatorError(
+				ErrArgsInvalidLen,
+				operator,
+				fmt.Sprintf("Expression %s takes exactly 2 arguments. %d were passed in.", operator, len(args)),
+			)
+		}
+
+		return &compare{
+			operator: operator,
+			exprs:    [2]any{args[0], args[1]},
+		}, nil
+	}
+}
+
+// Process implements Operator interface.
+//
+// Both expressions are evaluated and compared using BSON comparison order,
+// so values of different types are never equal (apart from numbers).
+// `$cmp` returns -1, 0, or 1; other operators return boolean.
+func (c *compare) Process(doc *types.Document) (any, error) {
+	var values [2]any
+
+	for i, e := range c.exprs {
+		v, err := evaluateExpression(e, doc)
+		if err != nil {
+			return nil, err
+		}
+
+		values[i] = v
+	}
+
+	res := compareValues(values[0], values[1])
+
+	switch c.operator {
+	case "$cmp":
+		return int32(res), nil
+	case "$eq":
+		return res == types.Equal, nil
+	case "$ne":
+		return res != types.Equal, nil
+	case "$gt":
+		return res == types.Greater, nil
+	case "$gte":
+		return res != types.Less, nil
+	case "$lt":
+		return res == types.Less, nil
+	case "$lte":
+		return res != types.Greater, nil
+	default:
+		panic(fmt.Sprintf("unexpected comparison operator %q", c.operator))
+	}
+}
+
+// compareValues compares two values using BSON comparison order.
+//
+// Unlike query filters, arrays are compared as a whole.
+// NaN is equal to NaN and less than all other numbers.
+func compareValues(a, b any) types.CompareResult {
+	aNaN, bNaN := isNaN(a), isNaN(b)
+
+	switch {
+	case aNaN && bNaN:
+		return types.Equal
+	case aNaN && isNumber(b):
+		return types.Less
+	case bNaN && isNumber(a):
+		return types.Greater
+	}
+
+	return types.CompareForAggregation(a, b)
+}
+
+// isNaN returns true if v is a NaN double.
+func isNaN(v any) bool {
+	f, ok := v.(float64)
+	return ok && math.IsNaN(f)
+}
+
+// isNumber returns true if v is a BSON number.
+func isNumber(v any) bool {
+	switch v.(type) {
+	case float64, int32, int64:
+		return true
+	default:
+		return false
+	}
+}
+
+// check interfaces
+var (
+	_ Operator = (*compare)(nil)
+)
@@ -52,9 +52,27 @@ func NewExpr(exprValue *types.Document, errArgument string) (Operator, error) {
 	return e, nil
 }
 
+// NewExpression validates and creates operator that evaluates the given aggregation expression.
+// Unlike NewExpr, the expression is not wrapped into $expr document.
+// It is used by accumulators that take arbitrary expressions as arguments.
+//
+// It returns CommandError for invalid expression.
+func NewExpression(exprValue any, errArgument string) (Operator, error) {
+	e := &expr{
+		exprValue:   exprValue,
+		errArgument: errArgument,
+	}
+
+	if err := e.validateExpr(exprValue); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
 // Process implements Operator interface.
 func (e *expr) Process(doc *types.Document) (any, error) {
-	return e.processExpr(e.exprValue, doc)
+	return evaluateExpression(e.exprValue, doc)
 }
 
 // processExpr recursively validates operators and expressions.
@@ -130,24 +148,24 @@ func (e *expr) validateExpr(exprValue any) error {
 	return nil
 }
 
-// processExpr recursively processes operators and expressions and returns processed `exprValue`.
+// evaluateExpression recursively processes operators and expressions and returns processed `exprValue`.
 //
 // Each array values and document fields are processed recursively.
 // String expression is evaluated if any, and Null is returned if field is missing.
 // Any value that does not require processing, it returns the original value.
-func (e *expr) processExpr(exprValue any, doc *types.Document) (any, error) {
+//
+// It is used by $expr and by operators that take arbitrary expressions as arguments.
+func evaluateExpression(exprValue any, doc *types.Document) (any, error) {
 	switch exprValue := exprValue.(type) {
 	case *types.Document:
 		if IsOperator(exprValue) {
 			op, err := NewOperator(exprValue)
 			if err != nil {
-				// $expr was validated in NewExpr
 				return nil, lazyerrors.Error(err)
 			}
 
 			v, err := op.Process(doc)
 			if err != nil {
-				// Process does not return error for existing operators
 				return nil, lazyerrors.Error(err)
 			}
 
@@ -169,7 +187,7 @@ func (e *expr) processExpr(exprValue any, doc *types.Document) (any, error) {
 				return nil, lazyerrors.Error(err)
 			}
 
-			processed, err := e.processExpr(v, doc)
+			processed, err := evaluateExpression(v, doc)
 			if err != nil {
 				return nil, lazyerrors.Error(err)
 			}
@@ -194,7 +212,7 @@ func (e *expr) processExpr(exprValue any, doc *types.Document) (any, error) {
 				return nil, lazyerrors.Error(err)
 			}
 
-			processed, err := e.processExpr(v, doc)
+			processed, err := evaluateExpression(v, doc)
 			if err != nil {
 				return nil, lazyerrors.Error(err)
 			}
@@ -213,7 +231,6 @@ func (e *expr) processExpr(exprValue any, doc *types.Document) (any, error) {
 		}
 
 		if err != nil {
-			// expression error was validated in NewExpr
 			return nil, lazyerrors.Error(err)
 		}
 
@@ -0,0 +1,218 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package operators provides aggregation operators.
+package operators
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// filterOp represents `$filter` operator.
+type filterOp struct {
+	input any
+	as    string
+	cond  any
+	limit any
+}
+
+// newFilter validates `{input: <expression>, as: <string>, cond: <expression>, limit: <expression>}`
+// arguments and returns `$filter` operator.
+func newFilter(args ...any) (Operator, error) {
+	doc, ok := singleDocumentArg(args)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFilterNotObject,
+			"$filter only supports an object as its argument",
+			"$filter",
+		)
+	}
+
+	var as any
+
+	op := new(filterOp)
+
+	for _, key := range doc.Keys() {
+		v := must.NotFail(doc.Get(key))
+
+		switch key {
+		case "input":
+			op.input = v
+		case "as":
+			as = v
+		case "cond":
+			op.cond = v
+		case "limit":
+			op.limit = v
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrFilterUnknownArgument,
+				"Unrecognized parameter to $filter: "+key,
+				"$filter",
+			)
+		}
+	}
+
+	if op.input == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFilterMissingInput,
+			"Missing 'input' parameter to $filter",
+			"$filter",
+		)
+	}
+
+	if op.cond == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFilterMissingCond,
+			"Missing 'cond' parameter to $filter",
+			"$filter",
+		)
+	}
+
+	var err error
+	if op.as, err = variableName("$filter", as, "this"); err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// Process implements Operator interface.
+// It returns an array of `input` elements for which `cond` expression is true,
+// or null if `input` is null or missing.
+// If `limit` is set, at most that number of elements is returned.
+func (f *filterOp) Process(doc *types.Document) (any, error) {
+	input, err := evaluateExpression(f.input, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var arr *types.Array
+
+	switch input := input.(type) {
+	case *types.Array:
+		arr = input
+	case types.NullType:
+		return types.Null, nil
+	default:
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFilterInputType,
+			fmt.Sprintf("input to $filter must be an array not %s", commonparams.AliasFromType(input)),
+			"$filter",
+		)
+	}
+
+	limit := arr.Len()
+
+	if f.limit != nil {
+		var l any
+		if l, err = evaluateExpression(f.limit, doc); err != nil {
+			return nil, err
+		}
+
+		if limit, err = filterLimit(l, limit); err != nil {
+			return nil, err
+		}
+	}
+
+	res := types.MakeArray(0)
+
+	iter := arr.Iterator()
+	defer iter.Close()
+
+	for res.Len() < limit {
+		_, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		cond, err := evaluateWithVariables(f.cond, doc, map[string]any{f.as: v})
+		if err != nil {
+			return nil, err
+		}
+
+		if isTrue(cond) {
+			res.Append(v)
+		}
+	}
+
+	return res, nil
+}
+
+// filterLimit returns `$filter` limit for the evaluated `limit` value.
+// Null value means no limit, so the given default is returned.
+func filterLimit(v any, def int) (int, error) {
+	var limit int64
+
+	switch v := v.(type) {
+	case types.NullType:
+		return def, nil
+	case int32:
+		limit = int64(v)
+	case int64:
+		limit = v
+	case float64:
+		if v != math.Trunc(v) || v > math.MaxInt32 || v < math.MinInt32 {
+			return 0, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrFilterLimitType,
+				"$filter: limit must be represented as a 32-bit integral value",
+				"$filter",
+			)
+		}
+
+		limit = int64(v)
+	default:
+		return 0, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFilterLimitType,
+			"$filter: limit must be represented as a 32-bit integral value",
+			"$filter",
+		)
+	}
+
+	if limit > math.MaxInt32 || limit < math.MinInt32 {
+		return 0, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFilterLimitType,
+			"$filter: limit must be represented as a 32-bit integral value",
+			"$filter",
+		)
+	}
+
+	if limit <= 0 {
+		return 0, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFilterLimitValue,
+			"$filter: limit must be greater than 0",
+			"$filter",
+		)
+	}
+
+	return min(int(limit), def), nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*filterOp)(nil)
+)
@@ -0,0 +1,148 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package operators provides aggregation operators.
+package operators
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// mapOp represents `$map` operator.
+type mapOp struct {
+	input any
+	as    string
+	in    any
+}
+
+// newMap validates `{input: <expression>, as: <string>, in: <expression>}` arguments
+// and returns `$map` operator.
+func newMap(args ...any) (Operator, error) {
+	doc, ok := singleDocumentArg(args)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrMapNotObject,
+			"$map only supports an object as its argument",
+			"$map",
+		)
+	}
+
+	var as any
+
+	op := new(mapOp)
+
+	for _, key := range doc.Keys() {
+		v := must.NotFail(doc.Get(key))
+
+		switch key {
+		case "input":
+			op.input = v
+		case "as":
+			as = v
+		case "in":
+			op.in = v
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrMapUnknownArgument,
+				"Unrecognized parameter to $map: "+key,
+				"$map",
+			)
+		}
+	}
+
+	if op.input == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrMapMissingInput,
+			"Missing 'input' parameter to $map",
+			"$map",
+		)
+	}
+
+	if op.in == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrMapMissingIn,
+			"Missing 'in' parameter to $map",
+			"$map",
+		)
+	}
+
+	var err error
+	if op.as, err = variableName("$map", as, "this"); err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// Process implements Operator interface.
+// It returns an array of `in` expression results evaluated for each element of `input` array,
+// or null if `input` is null or missing.
+func (m *mapOp) Process(doc *types.Document) (any, error) {
+	input, err := evaluateExpression(m.input, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var arr *types.Array
+
+	switch input := input.(type) {
+	case *types.Array:
+		arr = input
+	case types.NullType:
+		return types.Null, nil
+	default:
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrMapInputType,
+			fmt.Sprintf("input to $map must be an array not %s", commonparams.AliasFromType(input)),
+			"$map",
+		)
+	}
+
+	res := types.MakeArray(arr.Len())
+
+	iter := arr.Iterator()
+	defer iter.Close()
+
+	for {
+		_, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if v, err = evaluateWithVariables(m.in, doc, map[string]any{m.as: v}); err != nil {
+			return nil, err
+		}
+
+		res.Append(v)
+	}
+
+	return res, nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*mapOp)(nil)
+)
@@ -0,0 +1,77 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package operators provides aggregation operators.
+package operators
+
+import (
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// mergeObjects represents `$mergeObjects` operator.
+type mergeObjects struct {
+	inputs []any
+}
+
+// newMergeObjects returns `$mergeObjects` operator.
+func newMergeObjects(args ...any) (Operator, error) {
+	return &mergeObjects{
+		inputs: args,
+	}, nil
+}
+
+// Process implements Operator interface.
+// It combines all input documents into a single document.
+// If the same field is present in several documents, the value from the last one is used.
+// Null and missing inputs are ignored.
+func (m *mergeObjects) Process(doc *types.Document) (any, error) {
+	res := new(types.Document)
+
+	for _, expr := range m.inputs {
+		input, err := evaluateExpression(expr, doc)
+		if err != nil {
+			return nil, err
+		}
+
+		switch input := input.(type) {
+		case *types.Document:
+			for _, k := range input.Keys() {
+				res.Set(k, must.NotFail(input.Get(k)))
+			}
+		case types.NullType:
+			continue
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrMergeObjectsType,
+				fmt.Sprintf(
+					"$mergeObjects requires object inputs, but input %s is of type %s",
+					types.FormatAnyValue(input), commonparams.AliasFromType(input),
+				),
+				"$mergeObjects",
+			)
+		}
+	}
+
+	return res, nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*mergeObjects)(nil)
+)
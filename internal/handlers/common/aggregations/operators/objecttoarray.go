@@ -0,0 +1,83 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package operators provides aggregation operators.
+package operators
+
+import (
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// objectToArray represents `$objectToArray` operator.
+type objectToArray struct {
+	input any
+}
+
+// newObjectToArray returns `$objectToArray` operator.
+func newObjectToArray(args ...any) (Operator, error) {
+	if len(args) != 1 {
+		return nil, newOperatorError(
+			ErrArgsInvalidLen,
+			"$objectToArray",
+			fmt.Sprintf("Expression $objectToArray takes exactly 1 arguments. %d were passed in.", len(args)),
+		)
+	}
+
+	return &objectToArray{
+		input: args[0],
+	}, nil
+}
+
+// Process implements Operator interface.
+// It converts a document to an array of `{k: <key>, v: <value>}` documents.
+// It returns null if input is null or missing.
+func (o *objectToArray) Process(doc *types.Document) (any, error) {
+	input, err := evaluateExpression(o.input, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var d *types.Document
+
+	switch input := input.(type) {
+	case *types.Document:
+		d = input
+	case types.NullType:
+		return types.Null, nil
+	default:
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrObjectToArrayNotObject,
+			"$objectToArray requires a document input, found: "+commonparams.AliasFromType(input),
+			"$objectToArray",
+		)
+	}
+
+	res := types.MakeArray(d.Len())
+
+	for _, k := range d.Keys() {
+		res.Append(must.NotFail(types.NewDocument("k", k, "v", must.NotFail(d.Get(k)))))
+	}
+
+	return res, nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*objectToArray)(nil)
+)
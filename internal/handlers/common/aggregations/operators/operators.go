@@ -72,6 +72,17 @@ func IsOperator(doc *types.Document) bool {
 	return false
 }
 
+// singleDocumentArg returns the document if args consist of a single document.
+func singleDocumentArg(args []any) (*types.Document, bool) {
+	if len(args) != 1 {
+		return nil, false
+	}
+
+	doc, ok := args[0].(*types.Document)
+
+	return doc, ok
+}
+
 // NewOperator returns operator from provided document.
 // The document should look like: `{<$operator>: <operator-value>}`.
 //
@@ -101,7 +112,8 @@ func NewOperator(doc *types.Document) (Operator, error) {
 
 	var args []any
 
-	if arr, ok := expr.(*types.Array); ok {
+	// $literal argument is never evaluated, so its array is not treated as a list of arguments
+	if arr, ok := expr.(*types.Array); ok && operator != "$literal" {
 		iter := arr.Iterator()
 		defer iter.Close()
 
@@ -139,8 +151,33 @@ func NewOperator(doc *types.Document) (Operator, error) {
 // Operators maps all standard aggregation operators.
 var Operators = map[string]newOperatorFunc{
 	// sorted alphabetically
-	"$sum":  newSum,
-	"$type": newType,
+	"$and":           newAnd,
+	"$arrayToObject": newArrayToObject,
+	"$avg":           newAvg,
+	"$cmp":           newCompareFunc("$cmp"),
+	"$eq":            newCompareFunc("$eq"),
+	"$filter":        newFilter,
+	"$gt":            newCompareFunc("$gt"),
+	"$gte":           newCompareFunc("$gte"),
+	"$literal":       newLiteral,
+	"$lt":            newCompareFunc("$lt"),
+	"$lte":           newCompareFunc("$lte"),
+	"$map":           newMap,
+	"$mergeObjects":  newMergeObjects,
+	"$ne":            newCompareFunc("$ne"),
+	"$not":           newNot,
+	"$objectToArray": newObjectToArray,
+	"$or":            newOr,
+	"$rand":          newRand,
+	"$reduce":        newReduce,
+	"$regexFind":     newRegexFind,
+	"$regexFindAll":  newRegexFindAll,
+	"$regexMatch":    newRegexMatch,
+	"$stdDevPop":     newStdDevPop,
+	"$stdDevSamp":    newStdDevSamp,
+	"$sum":           newSum,
+	"$type":          newType,
+	"$zip":           newZip,
 	// please keep sorted alphabetically
 }
 
@@ -152,20 +189,16 @@ var unsupportedOperators = map[string]struct{}{
 	"$acosh":            {},
 	"$add":              {},
 	"$allElementsTrue":  {},
-	"$and":              {},
 	"$anyElementTrue":   {},
 	"$arrayElemAt":      {},
-	"$arrayToObject":    {},
 	"$asin":             {},
 	"$asinh":            {},
 	"$atan":             {},
 	"$atan2":            {},
 	"$atanh":            {},
-	"$avg":              {},
 	"$binarySize":       {},
 	"$bsonSize":         {},
 	"$ceil":             {},
-	"$cmp":              {},
 	"$concat":           {},
 	"$concatArrays":     {},
 	"$cond":             {},
@@ -190,15 +223,11 @@ var unsupportedOperators = map[string]struct{}{
 	"$derivative":       {},
 	"$divide":           {},
 	"$documentNumber":   {},
-	"$eq":               {},
 	"$exp":              {},
 	"$expMovingAvg":     {},
-	"$filter":           {},
 	"$floor":            {},
 	"$function":         {},
 	"$getField":         {},
-	"$gt":               {},
-	"$gte":              {},
 	"$hour":             {},
 	"$ifNull":           {},
 	"$in":               {},
@@ -213,15 +242,11 @@ var unsupportedOperators = map[string]struct{}{
 	"$isoWeekYear":      {},
 	"$let":              {},
 	"$linearFill":       {},
-	"$literal":          {},
 	"$ln":               {},
 	"$locf":             {},
 	"$log":              {},
 	"$log10":            {},
-	"$lt":               {},
-	"$lte":              {},
 	"$ltrim":            {},
-	"$map":              {},
 	"$max":              {},
 	"$meta":             {},
 	"$min":              {},
@@ -231,25 +256,15 @@ var unsupportedOperators = map[string]struct{}{
 	"$mod":              {},
 	"$month":            {},
 	"$multiply":         {},
-	"$ne":               {},
-	"$not":              {},
-	"$objectToArray":    {},
-	"$or":               {},
 	"$pow":              {},
 	"$radiansToDegrees": {},
-	"$rand":             {},
 	"$range":            {},
 	"$rank":             {},
-	"$reduce":           {},
-	"$regexFind":        {},
-	"$regexFindAll":     {},
-	"$regexMatch":       {},
 	"$replaceOne":       {},
 	"$replaceAll":       {},
 	"$reverseArray":     {},
 	"$round":            {},
 	"$rtrim":            {},
-	"$sampleRate":       {},
 	"$second":           {},
 	"$setDifference":    {},
 	"$setEquals":        {},
@@ -265,8 +280,6 @@ var unsupportedOperators = map[string]struct{}{
 	"$sortArray":        {},
 	"$split":            {},
 	"$sqrt":             {},
-	"$stdDevPop":        {},
-	"$stdDevSamp":       {},
 	"$strcasecmp":       {},
 	"$strLenBytes":      {},
 	"$strLenCP":         {},
@@ -294,6 +307,5 @@ var unsupportedOperators = map[string]struct{}{
 	"$unsetField":       {},
 	"$week":             {},
 	"$year":             {},
-	"$zip":              {},
 	// please keep sorted alphabetically
 }
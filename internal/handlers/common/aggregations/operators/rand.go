@@ -0,0 +1,52 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// randOp represents `$rand` operator.
+type randOp struct{}
+
+// newRand returns `$rand` operator.
+func newRand(args ...any) (Operator, error) {
+	if len(args) == 1 {
+		if doc, ok := args[0].(*types.Document); ok && doc.Len() == 0 {
+			return new(randOp), nil
+		}
+	}
+
+	return nil, newOperatorError(
+		ErrArgsInvalidLen,
+		"$rand",
+		fmt.Sprintf("Expression $rand takes exactly 0 arguments. %d were passed in.", len(args)),
+	)
+}
+
+// Process implements Operator interface.
+//
+// It returns a random float64 in [0, 1) for every document.
+func (r *randOp) Process(*types.Document) (any, error) {
+	return rand.Float64(), nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*randOp)(nil)
+)
@@ -0,0 +1,151 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package operators provides aggregation operators.
+package operators
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// reduce represents `$reduce` operator.
+type reduce struct {
+	input        any
+	initialValue any
+	in           any
+}
+
+// newReduce validates `{input: <expression>, initialValue: <expression>, in: <expression>}`
+// arguments and returns `$reduce` operator.
+func newReduce(args ...any) (Operator, error) {
+	doc, ok := singleDocumentArg(args)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrReduceNotObject,
+			"$reduce only supports an object as its argument",
+			"$reduce",
+		)
+	}
+
+	op := new(reduce)
+
+	for _, key := range doc.Keys() {
+		v := must.NotFail(doc.Get(key))
+
+		switch key {
+		case "input":
+			op.input = v
+		case "initialValue":
+			op.initialValue = v
+		case "in":
+			op.in = v
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrReduceUnknownArgument,
+				"$reduce found an unknown argument: "+key,
+				"$reduce",
+			)
+		}
+	}
+
+	if op.input == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrReduceMissingInput,
+			"$reduce requires 'input' to be specified",
+			"$reduce",
+		)
+	}
+
+	if op.initialValue == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrReduceMissingInitialValue,
+			"$reduce requires 'initialValue' to be specified",
+			"$reduce",
+		)
+	}
+
+	if op.in == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrReduceMissingIn,
+			"$reduce requires 'in' to be specified",
+			"$reduce",
+		)
+	}
+
+	return op, nil
+}
+
+// Process implements Operator interface.
+// It evaluates `in` expression for each element of `input` array with `$$this` set to the element
+// and `$$value` set to the previous result, starting from `initialValue`.
+// It returns null if `input` is null or missing.
+func (r *reduce) Process(doc *types.Document) (any, error) {
+	input, err := evaluateExpression(r.input, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var arr *types.Array
+
+	switch input := input.(type) {
+	case *types.Array:
+		arr = input
+	case types.NullType:
+		return types.Null, nil
+	default:
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrReduceInputType,
+			fmt.Sprintf("$reduce requires that 'input' be an array, found: %s", commonparams.AliasFromType(input)),
+			"$reduce",
+		)
+	}
+
+	value, err := evaluateExpression(r.initialValue, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	iter := arr.Iterator()
+	defer iter.Close()
+
+	for {
+		_, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if value, err = evaluateWithVariables(r.in, doc, map[string]any{"this": v, "value": value}); err != nil {
+			return nil, err
+		}
+	}
+
+	return value, nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*reduce)(nil)
+)
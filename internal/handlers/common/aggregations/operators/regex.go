@@ -0,0 +1,300 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package operators provides aggregation operators.
+package operators
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// regexMode represents the kind of result returned by regex expression operator.
+type regexMode int
+
+const (
+	regexMatch regexMode = iota
+	regexFind
+	regexFindAll
+)
+
+// regexOp represents `$regexMatch`, `$regexFind` and `$regexFindAll` operators.
+type regexOp struct {
+	name    string
+	mode    regexMode
+	input   any
+	regex   any
+	options any
+}
+
+// newRegexMatch returns `$regexMatch` operator.
+func newRegexMatch(args ...any) (Operator, error) {
+	return newRegexOp("$regexMatch", regexMatch, args...)
+}
+
+// newRegexFind returns `$regexFind` operator.
+func newRegexFind(args ...any) (Operator, error) {
+	return newRegexOp("$regexFind", regexFind, args...)
+}
+
+// newRegexFindAll returns `$regexFindAll` operator.
+func newRegexFindAll(args ...any) (Operator, error) {
+	return newRegexOp("$regexFindAll", regexFindAll, args...)
+}
+
+// newRegexOp validates `{input: <expression>, regex: <expression>, options: <expression>}`
+// arguments document and returns regex expression operator with the given name.
+func newRegexOp(name string, mode regexMode, args ...any) (Operator, error) {
+	if len(args) != 1 {
+		return nil, newOperatorError(
+			ErrArgsInvalidLen,
+			name,
+			fmt.Sprintf("Expression %s takes exactly 1 arguments. %d were passed in.", name, len(args)),
+		)
+	}
+
+	doc, ok := args[0].(*types.Document)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrRegexNotObject,
+			fmt.Sprintf("%s expects an object of named arguments but found: %s", name, commonparams.AliasFromType(args[0])),
+			name,
+		)
+	}
+
+	op := &regexOp{
+		name: name,
+		mode: mode,
+	}
+
+	for _, key := range doc.Keys() {
+		v := must.NotFail(doc.Get(key))
+
+		switch key {
+		case "input":
+			op.input = v
+		case "regex":
+			op.regex = v
+		case "options":
+			op.options = v
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrRegexUnknownArgument,
+				fmt.Sprintf("%s found an unknown argument: %s", name, key),
+				name,
+			)
+		}
+	}
+
+	if op.input == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrRegexMissingInput,
+			fmt.Sprintf("%s requires 'input' parameter", name),
+			name,
+		)
+	}
+
+	if op.regex == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrRegexMissingRegex,
+			fmt.Sprintf("%s requires 'regex' parameter", name),
+			name,
+		)
+	}
+
+	return op, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns a bool for `$regexMatch`, a match document or null for `$regexFind`
+// and an array of match documents for `$regexFindAll`.
+// Null or missing input or regex is treated as no match.
+func (r *regexOp) Process(doc *types.Document) (any, error) {
+	re, err := r.compile(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var input string
+
+	if re != nil {
+		var v any
+
+		if v, err = evaluateExpression(r.input, doc); err != nil {
+			return nil, err
+		}
+
+		switch v := v.(type) {
+		case string:
+			input = v
+		case types.NullType:
+			re = nil
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrRegexInputType,
+				fmt.Sprintf("%s needs 'input' to be of type string", r.name),
+				r.name,
+			)
+		}
+	}
+
+	switch r.mode {
+	case regexMatch:
+		return re != nil && re.MatchString(input), nil
+
+	case regexFind:
+		if re == nil {
+			return types.Null, nil
+		}
+
+		loc := re.FindStringSubmatchIndex(input)
+		if loc == nil {
+			return types.Null, nil
+		}
+
+		return regexMatchDocument(input, loc), nil
+
+	case regexFindAll:
+		res := types.MakeArray(0)
+
+		if re == nil {
+			return res, nil
+		}
+
+		for _, loc := range re.FindAllStringSubmatchIndex(input, -1) {
+			res.Append(regexMatchDocument(input, loc))
+		}
+
+		return res, nil
+
+	default:
+		panic(fmt.Sprintf("unexpected regex mode %d", r.mode))
+	}
+}
+
+// compile evaluates regex and options arguments and compiles the regular expression.
+// It returns nil regexp if regex argument is null or missing.
+func (r *regexOp) compile(doc *types.Document) (*regexp.Regexp, error) {
+	regexValue, err := evaluateExpression(r.regex, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var options string
+
+	if r.options != nil {
+		var optionsValue any
+
+		if optionsValue, err = evaluateExpression(r.options, doc); err != nil {
+			return nil, err
+		}
+
+		switch optionsValue := optionsValue.(type) {
+		case string:
+			options = optionsValue
+		case types.NullType:
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrRegexOptionsType,
+				fmt.Sprintf("%s needs 'options' to be of type string", r.name),
+				r.name,
+			)
+		}
+	}
+
+	var regex types.Regex
+
+	switch regexValue := regexValue.(type) {
+	case string:
+		regex = types.Regex{Pattern: regexValue, Options: options}
+	case types.Regex:
+		if regexValue.Options != "" && options != "" {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrRegexOptionsConflict,
+				fmt.Sprintf("%s: found regex option(s) specified in both 'regex' and 'option' fields", r.name),
+				r.name,
+			)
+		}
+
+		regex = regexValue
+		if options != "" {
+			regex.Options = options
+		}
+	case types.NullType:
+		return nil, nil
+	default:
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrRegexType,
+			fmt.Sprintf("%s needs 'regex' to be of type string or regex", r.name),
+			r.name,
+		)
+	}
+
+	if option, ok := regex.UnknownOption(); ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadRegexOption,
+			fmt.Sprintf("%s invalid flag in regex options: %c", r.name, option),
+			r.name,
+		)
+	}
+
+	re, err := regex.Compile()
+	if err != nil {
+		msg := strings.TrimPrefix(err.Error(), "Regular expression is invalid: ")
+
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrRegexInvalid,
+			fmt.Sprintf("Invalid Regex in %s: %s", r.name, msg),
+			r.name,
+		)
+	}
+
+	return re, nil
+}
+
+// regexMatchDocument returns `{match: <string>, idx: <int>, captures: <array>}` document
+// for the given submatch indexes.
+// The index is the number of code points before the match;
+// captures that did not participate in the match are null.
+func regexMatchDocument(input string, loc []int) *types.Document {
+	captures := types.MakeArray(len(loc)/2 - 1)
+
+	for i := 2; i < len(loc); i += 2 {
+		if loc[i] < 0 {
+			captures.Append(types.Null)
+			continue
+		}
+
+		captures.Append(input[loc[i]:loc[i+1]])
+	}
+
+	return must.NotFail(types.NewDocument(
+		"match", input[loc[0]:loc[1]],
+		"idx", int32(utf8.RuneCountInString(input[:loc[0]])),
+		"captures", captures,
+	))
+}
+
+// check interfaces
+var (
+	_ Operator = (*regexOp)(nil)
+)
@@ -0,0 +1,59 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package operators provides aggregation operators.
+package operators
+
+import (
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// stdDev represents `$stdDevPop` and `$stdDevSamp` operators.
+type stdDev struct {
+	args   []any
+	sample bool
+}
+
+// newStdDevPop returns `$stdDevPop` operator.
+func newStdDevPop(args ...any) (Operator, error) {
+	return &stdDev{
+		args: args,
+	}, nil
+}
+
+// newStdDevSamp returns `$stdDevSamp` operator.
+func newStdDevSamp(args ...any) (Operator, error) {
+	return &stdDev{
+		args:   args,
+		sample: true,
+	}, nil
+}
+
+// Process implements Operator interface.
+// It returns the population or sample standard deviation of numeric values ignoring other types,
+// or null if there are not enough of them.
+func (s *stdDev) Process(doc *types.Document) (any, error) {
+	values, err := statisticsValues(s.args, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return aggregations.StdDevNumbers(s.sample, values...), nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*stdDev)(nil)
+)
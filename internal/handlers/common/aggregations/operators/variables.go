@@ -0,0 +1,240 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package operators provides aggregation operators.
+package operators
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// variableName returns the name of the variable defined by `as` argument of the given operator.
+// It returns the default name if `as` is not set.
+//
+// It returns CommandError if the name is not a valid user variable name.
+func variableName(operator string, as any, defaultName string) (string, error) {
+	if as == nil {
+		return defaultName, nil
+	}
+
+	name, _ := as.(string)
+	if name == "" {
+		return "", commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFailedToParse,
+			"empty variable names are not allowed",
+			operator,
+		)
+	}
+
+	for i, r := range name {
+		valid := r > unicode.MaxASCII || unicode.IsLower(r)
+		if i > 0 {
+			valid = valid || unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+		}
+
+		if !valid {
+			return "", commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrFailedToParse,
+				fmt.Sprintf("'%s' starts with an invalid character for a user variable name", name),
+				operator,
+			)
+		}
+	}
+
+	return name, nil
+}
+
+// bindVariables returns a copy of the expression where references to the given variables
+// (`$$name` and `$$name.path`) are replaced with `{$literal: <value>}` documents,
+// so the expression could be evaluated by evaluateExpression and nested operators.
+//
+// Variables rebound by nested $map, $filter and $reduce operators are not replaced in their scopes.
+func bindVariables(expression any, vars map[string]any) (any, error) {
+	switch expression := expression.(type) {
+	case string:
+		name, ok := strings.CutPrefix(expression, "$$")
+		if !ok {
+			return expression, nil
+		}
+
+		name, path, _ := strings.Cut(name, ".")
+
+		v, ok := vars[name]
+		if !ok {
+			return expression, nil
+		}
+
+		if path != "" {
+			ex, err := aggregations.NewExpression("$v."+path, nil)
+			if err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			if v, err = ex.Evaluate(must.NotFail(types.NewDocument("v", v))); err != nil {
+				v = types.Null
+			}
+		}
+
+		return must.NotFail(types.NewDocument("$literal", v)), nil
+
+	case *types.Array:
+		res := types.MakeArray(expression.Len())
+
+		iter := expression.Iterator()
+		defer iter.Close()
+
+		for {
+			_, v, err := iter.Next()
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				break
+			}
+
+			if err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			if v, err = bindVariables(v, vars); err != nil {
+				return nil, err
+			}
+
+			res.Append(v)
+		}
+
+		return res, nil
+
+	case *types.Document:
+		if expression.Len() == 1 && expression.Command() == "$literal" {
+			return expression, nil
+		}
+
+		// variables that are rebound by nested operator in their scoped arguments
+		var scoped []string
+		var shadowed []string
+
+		if args, ok := must.NotFail(expression.Get(expression.Command())).(*types.Document); ok && expression.Len() == 1 {
+			switch expression.Command() {
+			case "$map", "$filter":
+				scoped = []string{"in", "cond"}
+				shadowed = []string{"this"}
+
+				if as, _ := args.Get("as"); as != nil {
+					name, _ := as.(string)
+					shadowed = []string{name}
+				}
+			case "$reduce":
+				scoped = []string{"in"}
+				shadowed = []string{"this", "value"}
+			}
+		}
+
+		res := new(types.Document)
+
+		for _, k := range expression.Keys() {
+			v := must.NotFail(expression.Get(k))
+
+			var err error
+
+			if args, ok := v.(*types.Document); ok && scoped != nil {
+				v, err = bindScopedVariables(args, scoped, shadowed, vars)
+			} else {
+				v, err = bindVariables(v, vars)
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			res.Set(k, v)
+		}
+
+		return res, nil
+
+	default:
+		return expression, nil
+	}
+}
+
+// bindScopedVariables binds variables in arguments document of $map, $filter or $reduce operator.
+// Scoped arguments do not see shadowed variables, other arguments see all of them.
+func bindScopedVariables(args *types.Document, scoped, shadowed []string, vars map[string]any) (*types.Document, error) {
+	inner := make(map[string]any, len(vars))
+
+	for name, v := range vars {
+		inner[name] = v
+	}
+
+	for _, name := range shadowed {
+		delete(inner, name)
+	}
+
+	res := new(types.Document)
+
+	for _, k := range args.Keys() {
+		v := must.NotFail(args.Get(k))
+
+		scope := vars
+		for _, s := range scoped {
+			if k == s {
+				scope = inner
+			}
+		}
+
+		v, err := bindVariables(v, scope)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Set(k, v)
+	}
+
+	return res, nil
+}
+
+// evaluateWithVariables binds variables in the expression and evaluates it against the document.
+func evaluateWithVariables(expression any, doc *types.Document, vars map[string]any) (any, error) {
+	bound, err := bindVariables(expression, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	return evaluateExpression(bound, doc)
+}
+
+// isTrue returns true if the value of evaluated expression is considered true
+// by aggregation operators: null, false and zero numbers are false, everything else is true.
+func isTrue(v any) bool {
+	switch v := v.(type) {
+	case *types.Document, *types.Array, string, types.Binary, types.ObjectID, time.Time, types.Regex, types.Timestamp:
+		return true
+	case float64, int32, int64:
+		return types.Compare(v, int32(0)) != types.Equal
+	case bool:
+		return v
+	case types.NullType:
+		return false
+	default:
+		panic(fmt.Sprintf("operators.isTrue: unexpected type %[1]T (%#[1]v)", v))
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package operators provides aggregation operators.
+package operators
+
+import (
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// zip represents `$zip` operator.
+type zip struct {
+	inputs           *types.Array
+	defaults         *types.Array
+	useLongestLength bool
+}
+
+// newZip validates `{inputs: [<expression>, ...], useLongestLength: <bool>, defaults: [<expression>, ...]}`
+// arguments and returns `$zip` operator.
+func newZip(args ...any) (Operator, error) {
+	doc, ok := singleDocumentArg(args)
+	if !ok {
+		var found any = types.MakeArray(0)
+		if len(args) == 1 {
+			found = args[0]
+		}
+
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrZipNotObject,
+			fmt.Sprintf("$zip only supports an object as an argument, found %s", commonparams.AliasFromType(found)),
+			"$zip",
+		)
+	}
+
+	op := new(zip)
+
+	for _, key := range doc.Keys() {
+		v := must.NotFail(doc.Get(key))
+
+		switch key {
+		case "inputs":
+			if op.inputs, ok = v.(*types.Array); !ok {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrZipInputsType,
+					fmt.Sprintf("inputs must be an array of expressions, found %s", commonparams.AliasFromType(v)),
+					"$zip",
+				)
+			}
+		case "defaults":
+			if op.defaults, ok = v.(*types.Array); !ok {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrZipDefaultsType,
+					fmt.Sprintf("defaults must be an array of expressions, found %s", commonparams.AliasFromType(v)),
+					"$zip",
+				)
+			}
+		case "useLongestLength":
+			if op.useLongestLength, ok = v.(bool); !ok {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrZipUseLongestLengthType,
+					fmt.Sprintf("useLongestLength must be a bool, found %s", commonparams.AliasFromType(v)),
+					"$zip",
+				)
+			}
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrZipUnknownArgument,
+				"$zip found an unknown argument: "+key,
+				"$zip",
+			)
+		}
+	}
+
+	if op.inputs == nil || op.inputs.Len() == 0 {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrZipMissingInputs,
+			"$zip requires at least one input array",
+			"$zip",
+		)
+	}
+
+	if op.defaults != nil && !op.useLongestLength {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrZipDefaultsWithoutLongest,
+			"cannot specify defaults unless useLongestLength is true",
+			"$zip",
+		)
+	}
+
+	if op.defaults != nil && op.defaults.Len() != op.inputs.Len() {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrZipDefaultsLength,
+			"defaults and inputs must have the same length",
+			"$zip",
+		)
+	}
+
+	return op, nil
+}
+
+// Process implements Operator interface.
+// It returns an array of arrays, where the n-th array contains the n-th elements of all inputs.
+// The result has the length of the shortest input, or of the longest input if `useLongestLength` is set;
+// missing elements are taken from `defaults` or set to null.
+// It returns null if any input is null or missing.
+func (z *zip) Process(doc *types.Document) (any, error) {
+	inputs := make([]*types.Array, z.inputs.Len())
+
+	var length int
+
+	for i := range inputs {
+		v, err := evaluateExpression(must.NotFail(z.inputs.Get(i)), doc)
+		if err != nil {
+			return nil, err
+		}
+
+		switch v := v.(type) {
+		case *types.Array:
+			inputs[i] = v
+		case types.NullType:
+			return types.Null, nil
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrZipInputType,
+				fmt.Sprintf("$zip found a non-array expression in input: %s", commonparams.AliasFromType(v)),
+				"$zip",
+			)
+		}
+
+		switch {
+		case i == 0:
+			length = inputs[i].Len()
+		case z.useLongestLength:
+			length = max(length, inputs[i].Len())
+		default:
+			length = min(length, inputs[i].Len())
+		}
+	}
+
+	defaults := make([]any, len(inputs))
+
+	for i := range defaults {
+		defaults[i] = types.Null
+
+		if z.defaults == nil {
+			continue
+		}
+
+		v, err := evaluateExpression(must.NotFail(z.defaults.Get(i)), doc)
+		if err != nil {
+			return nil, err
+		}
+
+		defaults[i] = v
+	}
+
+	res := types.MakeArray(length)
+
+	for n := 0; n < length; n++ {
+		tuple := types.MakeArray(len(inputs))
+
+		for i, input := range inputs {
+			if n < input.Len() {
+				tuple.Append(must.NotFail(input.Get(n)))
+				continue
+			}
+
+			tuple.Append(defaults[i])
+		}
+
+		res.Append(tuple)
+	}
+
+	return res, nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*zip)(nil)
+)
@@ -0,0 +1,250 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// OptimizePipeline returns an equivalent pipeline that could be executed more efficiently.
+//
+// $match stages are moved before $sort stages, and before $project stages that
+// do not modify or remove fields used by $match.
+// Consecutive $match stages are merged into a single $match stage with $and.
+// That allows pushing down more filters together with sort and limit to the backend.
+//
+// Stages should be validated before calling it. The given stages are not modified.
+func OptimizePipeline(stagesDocs []any) []any {
+	res := make([]any, 0, len(stagesDocs))
+
+	for _, s := range stagesDocs {
+		filter := matchFilter(s)
+		if filter == nil {
+			res = append(res, s)
+			continue
+		}
+
+		i := len(res)
+		for i > 0 && canMoveMatchBefore(filter, res[i-1]) {
+			i--
+		}
+
+		if i > 0 {
+			if prev := matchFilter(res[i-1]); prev != nil {
+				res[i-1] = must.NotFail(types.NewDocument("$match", mergeFilters(prev, filter)))
+				continue
+			}
+		}
+
+		res = slices.Insert(res, i, s)
+	}
+
+	return res
+}
+
+// matchFilter returns the filter of the given $match stage, or nil for other stages.
+func matchFilter(s any) *types.Document {
+	stage, ok := s.(*types.Document)
+	if !ok || stage.Len() != 1 {
+		return nil
+	}
+
+	filter, _ := stage.Get("$match")
+	res, _ := filter.(*types.Document)
+
+	return res
+}
+
+// mergeFilters returns a filter that matches documents matched by both given filters.
+func mergeFilters(a, b *types.Document) *types.Document {
+	switch {
+	case a.Len() == 0:
+		return b
+	case b.Len() == 0:
+		return a
+	}
+
+	and := types.MakeArray(2)
+
+	// flatten filter produced by the previous merge
+	if prev, _ := a.Get("$and"); a.Len() == 1 && prev != nil {
+		if prevArr, ok := prev.(*types.Array); ok {
+			and = prevArr.DeepCopy()
+		} else {
+			and.Append(a)
+		}
+	} else {
+		and.Append(a)
+	}
+
+	and.Append(b)
+
+	return must.NotFail(types.NewDocument("$and", and))
+}
+
+// canMoveMatchBefore returns true if the $match stage with the given filter
+// produces the same result when executed before the given stage.
+func canMoveMatchBefore(filter *types.Document, s any) bool {
+	stage, ok := s.(*types.Document)
+	if !ok || stage.Len() != 1 {
+		return false
+	}
+
+	switch stage.Command() {
+	case "$sort":
+		return true
+
+	case "$project":
+		projection, ok := must.NotFail(stage.Get("$project")).(*types.Document)
+		if !ok {
+			return false
+		}
+
+		fields, ok := filterFields(filter)
+		if !ok {
+			return false
+		}
+
+		return projectionKeepsFields(projection, fields)
+
+	default:
+		return false
+	}
+}
+
+// filterFields returns paths of all fields used by the filter.
+// It returns false if the filter may use fields in a way that could not be determined,
+// for example, with $expr.
+func filterFields(filter *types.Document) ([]string, bool) {
+	var res []string
+
+	for _, key := range filter.Keys() {
+		switch key {
+		case "$and", "$or", "$nor":
+			exprs, ok := must.NotFail(filter.Get(key)).(*types.Array)
+			if !ok {
+				return nil, false
+			}
+
+			for i := 0; i < exprs.Len(); i++ {
+				expr, ok := must.NotFail(exprs.Get(i)).(*types.Document)
+				if !ok {
+					return nil, false
+				}
+
+				fields, ok := filterFields(expr)
+				if !ok {
+					return nil, false
+				}
+
+				res = append(res, fields...)
+			}
+
+		default:
+			if strings.HasPrefix(key, "$") {
+				return nil, false
+			}
+
+			res = append(res, key)
+		}
+	}
+
+	return res, true
+}
+
+// projectionKeepsFields returns true if all given fields have the same values
+// after applying the given $project stage.
+func projectionKeepsFields(projection *types.Document, fields []string) bool {
+	var inclusion bool
+
+	// kept contains included fields for inclusion projection, or excluded fields for exclusion projection
+	kept := map[string]bool{}
+
+	var modified []string
+
+	for _, key := range projection.Keys() {
+		switch v := must.NotFail(projection.Get(key)).(type) {
+		case bool:
+			kept[key] = v
+		case int32, int64, float64:
+			kept[key] = types.Compare(v, int32(0)) != types.Equal
+		default:
+			// computed fields and other expressions
+			modified = append(modified, key)
+
+			if key != "_id" {
+				inclusion = true
+			}
+
+			continue
+		}
+
+		if key != "_id" && kept[key] {
+			inclusion = true
+		}
+	}
+
+	for _, field := range fields {
+		for _, key := range modified {
+			if pathsOverlap(field, key) {
+				return false
+			}
+		}
+
+		for key, included := range kept {
+			if included {
+				continue
+			}
+
+			// excluded fields
+			if pathsOverlap(field, key) {
+				return false
+			}
+		}
+
+		if !inclusion {
+			continue
+		}
+
+		if field == "_id" || strings.HasPrefix(field, "_id.") {
+			// _id is included unless excluded explicitly which is handled above
+			continue
+		}
+
+		var found bool
+
+		for key, included := range kept {
+			if included && (field == key || strings.HasPrefix(field, key+".")) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// pathsOverlap returns true if given dot notation paths are equal or one is a prefix of another.
+func pathsOverlap(a, b string) bool {
+	return a == b || strings.HasPrefix(a, b+".") || strings.HasPrefix(b, a+".")
+}
@@ -0,0 +1,167 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestOptimizePipeline(t *testing.T) {
+	t.Parallel()
+
+	doc := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+	arr := func(values ...any) *types.Array { return must.NotFail(types.NewArray(values...)) }
+
+	for name, tc := range map[string]struct {
+		pipeline []any
+		expected []any
+		limit    int64
+	}{
+		"MatchBeforeSort": {
+			pipeline: []any{
+				doc("$sort", doc("v", int32(1))),
+				doc("$match", doc("v", int32(42))),
+				doc("$limit", int32(5)),
+			},
+			expected: []any{
+				doc("$match", doc("v", int32(42))),
+				doc("$sort", doc("v", int32(1))),
+				doc("$limit", int32(5)),
+			},
+			limit: 5,
+		},
+		"MergeMatches": {
+			pipeline: []any{
+				doc("$match", doc("a", int32(1))),
+				doc("$sort", doc("v", int32(1))),
+				doc("$match", doc("b", int32(2))),
+				doc("$match", doc("c", int32(3))),
+			},
+			expected: []any{
+				doc("$match", doc("$and", arr(doc("a", int32(1)), doc("b", int32(2)), doc("c", int32(3))))),
+				doc("$sort", doc("v", int32(1))),
+			},
+		},
+		"MergeEmptyMatch": {
+			pipeline: []any{
+				doc("$match", doc()),
+				doc("$match", doc("a", int32(1))),
+			},
+			expected: []any{
+				doc("$match", doc("a", int32(1))),
+			},
+		},
+		"InclusionProject": {
+			pipeline: []any{
+				doc("$project", doc("a", true, "b.c", int32(1))),
+				doc("$match", doc("a", int32(1), "$or", arr(doc("b.c.d", int32(2)), doc("_id", int32(3))))),
+			},
+			expected: []any{
+				doc("$match", doc("a", int32(1), "$or", arr(doc("b.c.d", int32(2)), doc("_id", int32(3))))),
+				doc("$project", doc("a", true, "b.c", int32(1))),
+			},
+		},
+		"InclusionProjectMissingField": {
+			pipeline: []any{
+				doc("$project", doc("a", true)),
+				doc("$match", doc("b", int32(1))),
+			},
+			expected: []any{
+				doc("$project", doc("a", true)),
+				doc("$match", doc("b", int32(1))),
+			},
+		},
+		"InclusionProjectParentField": {
+			pipeline: []any{
+				doc("$project", doc("a.b", true)),
+				doc("$match", doc("a", doc("b", int32(1)))),
+			},
+			expected: []any{
+				doc("$project", doc("a.b", true)),
+				doc("$match", doc("a", doc("b", int32(1)))),
+			},
+		},
+		"ExclusionProject": {
+			pipeline: []any{
+				doc("$project", doc("a", false)),
+				doc("$match", doc("b", int32(1))),
+			},
+			expected: []any{
+				doc("$match", doc("b", int32(1))),
+				doc("$project", doc("a", false)),
+			},
+		},
+		"ExclusionProjectExcludedField": {
+			pipeline: []any{
+				doc("$project", doc("_id", int32(0))),
+				doc("$match", doc("_id.a", int32(1))),
+			},
+			expected: []any{
+				doc("$project", doc("_id", int32(0))),
+				doc("$match", doc("_id.a", int32(1))),
+			},
+		},
+		"ComputedField": {
+			pipeline: []any{
+				doc("$project", doc("a", "$b", "b", true)),
+				doc("$match", doc("a", int32(1))),
+			},
+			expected: []any{
+				doc("$project", doc("a", "$b", "b", true)),
+				doc("$match", doc("a", int32(1))),
+			},
+		},
+		"Expr": {
+			pipeline: []any{
+				doc("$project", doc("a", true)),
+				doc("$match", doc("$expr", doc("$eq", arr("$a", int32(1))))),
+			},
+			expected: []any{
+				doc("$project", doc("a", true)),
+				doc("$match", doc("$expr", doc("$eq", arr("$a", int32(1))))),
+			},
+		},
+		"OtherStage": {
+			pipeline: []any{
+				doc("$sort", doc("v", int32(1))),
+				doc("$limit", int32(1)),
+				doc("$match", doc("v", int32(42))),
+			},
+			expected: []any{
+				doc("$sort", doc("v", int32(1))),
+				doc("$limit", int32(1)),
+				doc("$match", doc("v", int32(42))),
+			},
+			limit: 1,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := OptimizePipeline(tc.pipeline)
+			testutil.AssertEqual(t, must.NotFail(types.NewArray(tc.expected...)), must.NotFail(types.NewArray(actual...)))
+
+			_, _, limit := GetPushdownQuery(actual)
+			assert.Equal(t, tc.limit, limit)
+		})
+	}
+}
@@ -15,32 +15,28 @@
 package aggregations
 
 import (
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
-// GetPushdownQuery gets pushdown query ($match and $sort) for aggregation.
+// GetPushdownQuery gets pushdown query ($match, $sort and $limit) for aggregation.
 //
-// If the first two stages are either $match, $sort, or a combination of them, we can push them down.
+// If the leading stages are either $match, $sort, or a combination of them, we can push them down.
 // In this case, we return the first match and sort statements to pushdown.
 // If $match stage is not present, match is returned as nil.
 // If $sort stage is not present, sort is returned as nil.
-func GetPushdownQuery(stagesDocs []any) (match *types.Document, sort *types.Document) {
-	if len(stagesDocs) == 0 {
-		return
-	}
-
-	stagesToPushdown := []any{stagesDocs[0]}
-
-	if len(stagesDocs) > 1 {
-		stagesToPushdown = append(stagesToPushdown, stagesDocs[1])
-	}
-
-	for _, s := range stagesToPushdown {
+// If they are followed by $limit stage, its value is returned as limit, otherwise limit is 0.
+//
+// Pipeline should be optimized with OptimizePipeline first, so that $match stages are leading.
+func GetPushdownQuery(stagesDocs []any) (match *types.Document, sort *types.Document, limit int64) {
+	for _, s := range stagesDocs {
 		stage, isDoc := s.(*types.Document)
 
 		if !isDoc {
-			return nil, nil
+			return
 		}
 
 		switch {
@@ -49,7 +45,7 @@ func GetPushdownQuery(stagesDocs []any) (match *types.Document, sort *types.Docu
 			query, isDoc := matchQuery.(*types.Document)
 
 			if !isDoc || match != nil {
-				continue
+				return
 			}
 
 			match = query
@@ -59,16 +55,157 @@ func GetPushdownQuery(stagesDocs []any) (match *types.Document, sort *types.Docu
 			query, isDoc := sortQuery.(*types.Document)
 
 			if !isDoc || sort != nil {
-				continue
+				return
 			}
 
 			sort = query
 
+		case stage.Has("$limit"):
+			l, err := commonparams.GetWholeNumberParam(must.NotFail(stage.Get("$limit")))
+			if err == nil && l > 0 {
+				limit = l
+			}
+
+			return
+
 		default:
-			// not $match nor $sort, we shouldn't continue pushdown
+			// not $match, $sort nor $limit, we shouldn't continue pushdown
 			return
 		}
 	}
 
 	return
 }
+
+// GroupCountPushdown represents leading $match and $group stages that only count documents
+// for each value of a top-level field, like `{$group: {_id: "$v", count: {$sum: 1}}}`.
+type GroupCountPushdown struct {
+	Match  *types.Document // nil if there is no $match stage
+	Field  string          // top-level field of $group's _id expression
+	Fields []string        // output fields of counting accumulators, in order
+	Stages int             // the number of replaced stages
+}
+
+// GetGroupCountPushdown returns GroupCountPushdown for the leading stages, or nil if they could not be replaced.
+//
+// The pipeline should start with a single optional $match stage followed by $group stage
+// with `_id` set to a top-level field path and only `{$sum: 1}` and `{$count: {}}` accumulators.
+// Pipeline should be optimized with OptimizePipeline first, so that $match stages are leading.
+func GetGroupCountPushdown(stagesDocs []any) *GroupCountPushdown {
+	var res GroupCountPushdown
+
+	for _, s := range stagesDocs {
+		stage, isDoc := s.(*types.Document)
+		if !isDoc || stage.Len() != 1 {
+			return nil
+		}
+
+		res.Stages++
+
+		switch {
+		case stage.Has("$match") && res.Stages == 1:
+			if res.Match, isDoc = must.NotFail(stage.Get("$match")).(*types.Document); !isDoc {
+				return nil
+			}
+
+		case stage.Has("$group"):
+			group, isDoc := must.NotFail(stage.Get("$group")).(*types.Document)
+			if !isDoc {
+				return nil
+			}
+
+			if !groupCountFields(group, &res) {
+				return nil
+			}
+
+			return &res
+
+		default:
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// groupCountFields sets group field and output fields of res for the given $group stage fields,
+// and returns true if the stage only counts documents.
+func groupCountFields(group *types.Document, res *GroupCountPushdown) bool {
+	for _, k := range group.Keys() {
+		v := must.NotFail(group.Get(k))
+
+		if k == "_id" {
+			path, ok := v.(string)
+			if !ok || !strings.HasPrefix(path, "$") || strings.HasPrefix(path, "$$") {
+				return false
+			}
+
+			res.Field = strings.TrimPrefix(path, "$")
+
+			if res.Field == "" || strings.ContainsRune(res.Field, '.') {
+				return false
+			}
+
+			continue
+		}
+
+		acc, ok := v.(*types.Document)
+		if !ok || acc.Len() != 1 {
+			return false
+		}
+
+		switch {
+		case acc.Has("$sum"):
+			if n, ok := must.NotFail(acc.Get("$sum")).(int32); !ok || n != 1 {
+				return false
+			}
+
+		case acc.Has("$count"):
+			if d, ok := must.NotFail(acc.Get("$count")).(*types.Document); !ok || d.Len() != 0 {
+				return false
+			}
+
+		default:
+			return false
+		}
+
+		res.Fields = append(res.Fields, k)
+	}
+
+	return res.Field != ""
+}
+
+// GetUnwindPushdown returns the top-level field of the leading $unwind stage without options,
+// or empty string if there is no such stage.
+func GetUnwindPushdown(stagesDocs []any) string {
+	if len(stagesDocs) == 0 {
+		return ""
+	}
+
+	stage, isDoc := stagesDocs[0].(*types.Document)
+	if !isDoc || stage.Len() != 1 || !stage.Has("$unwind") {
+		return ""
+	}
+
+	path := must.NotFail(stage.Get("$unwind"))
+
+	if opts, ok := path.(*types.Document); ok {
+		if opts.Len() != 1 || !opts.Has("path") {
+			return ""
+		}
+
+		path = must.NotFail(opts.Get("path"))
+	}
+
+	p, ok := path.(string)
+	if !ok || !strings.HasPrefix(p, "$") || strings.HasPrefix(p, "$$") {
+		return ""
+	}
+
+	field := strings.TrimPrefix(p, "$")
+	if strings.ContainsRune(field, '.') {
+		return ""
+	}
+
+	return field
+}
@@ -0,0 +1,129 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestGetGroupCountPushdown(t *testing.T) {
+	t.Parallel()
+
+	doc := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+
+	for name, tc := range map[string]struct {
+		pipeline []any
+		expected *GroupCountPushdown // nil if the pipeline could not be pushed down
+	}{
+		"Group": {
+			pipeline: []any{
+				doc("$group", doc("_id", "$v", "count", doc("$sum", int32(1)))),
+				doc("$sort", doc("_id", int32(1))),
+			},
+			expected: &GroupCountPushdown{Field: "v", Fields: []string{"count"}, Stages: 1},
+		},
+		"MatchGroup": {
+			pipeline: []any{
+				doc("$match", doc("a", "foo")),
+				doc("$group", doc("_id", "$v", "n", doc("$count", doc()), "m", doc("$sum", int32(1)))),
+			},
+			expected: &GroupCountPushdown{Match: doc("a", "foo"), Field: "v", Fields: []string{"n", "m"}, Stages: 2},
+		},
+		"NoAccumulators": {
+			pipeline: []any{doc("$group", doc("_id", "$v"))},
+			expected: &GroupCountPushdown{Field: "v", Stages: 1},
+		},
+		"SumOtherNumber": {
+			pipeline: []any{doc("$group", doc("_id", "$v", "count", doc("$sum", int32(2))))},
+		},
+		"SumDouble": {
+			pipeline: []any{doc("$group", doc("_id", "$v", "count", doc("$sum", float64(1))))},
+		},
+		"OtherAccumulator": {
+			pipeline: []any{doc("$group", doc("_id", "$v", "max", doc("$max", "$w")))},
+		},
+		"DotNotation": {
+			pipeline: []any{doc("$group", doc("_id", "$v.foo", "count", doc("$sum", int32(1))))},
+		},
+		"Variable": {
+			pipeline: []any{doc("$group", doc("_id", "$$ROOT", "count", doc("$sum", int32(1))))},
+		},
+		"ConstantID": {
+			pipeline: []any{doc("$group", doc("_id", types.Null, "count", doc("$sum", int32(1))))},
+		},
+		"SortFirst": {
+			pipeline: []any{
+				doc("$sort", doc("_id", int32(1))),
+				doc("$group", doc("_id", "$v", "count", doc("$sum", int32(1)))),
+			},
+		},
+		"TwoMatches": {
+			pipeline: []any{
+				doc("$match", doc("a", "foo")),
+				doc("$match", doc("b", "bar")),
+				doc("$group", doc("_id", "$v", "count", doc("$sum", int32(1)))),
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, GetGroupCountPushdown(tc.pipeline))
+		})
+	}
+}
+
+func TestGetUnwindPushdown(t *testing.T) {
+	t.Parallel()
+
+	doc := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+
+	for name, tc := range map[string]struct {
+		pipeline []any
+		expected string
+	}{
+		"String": {
+			pipeline: []any{doc("$unwind", "$v"), doc("$sort", doc("v", int32(1)))},
+			expected: "v",
+		},
+		"Path": {
+			pipeline: []any{doc("$unwind", doc("path", "$v"))},
+			expected: "v",
+		},
+		"Options": {
+			pipeline: []any{doc("$unwind", doc("path", "$v", "preserveNullAndEmptyArrays", true))},
+		},
+		"DotNotation": {
+			pipeline: []any{doc("$unwind", "$v.foo")},
+		},
+		"NotLeading": {
+			pipeline: []any{doc("$match", doc("a", "foo")), doc("$unwind", "$v")},
+		},
+		"Empty": {},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, GetUnwindPushdown(tc.pipeline))
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// documentHistory represents FerretDB-specific $documentHistory stage.
+//
+// It is a source stage: previous versions of documents kept for the collection are returned
+// instead of collection documents.
+// That is done by the aggregate command handler; see DocumentHistoryID.
+type documentHistory struct{}
+
+// newDocumentHistory creates a new $documentHistory stage.
+func newDocumentHistory(stage *types.Document) (aggregations.Stage, error) {
+	if _, err := DocumentHistoryID(stage); err != nil {
+		return nil, err
+	}
+
+	return new(documentHistory), nil
+}
+
+// DocumentHistoryID returns the _id of the document which versions are requested by the given $documentHistory stage,
+// or nil if versions of all documents are requested.
+func DocumentHistoryID(stage *types.Document) (any, error) {
+	v := must.NotFail(stage.Get("$documentHistory"))
+
+	fields, ok := v.(*types.Document)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			fmt.Sprintf("$documentHistory must be an object, not %s", commonparams.AliasFromType(v)),
+			"$documentHistory (stage)",
+		)
+	}
+
+	for _, k := range fields.Keys() {
+		if k != "documentId" {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf("unknown $documentHistory option %q", k),
+				"$documentHistory (stage)",
+			)
+		}
+	}
+
+	id, _ := fields.Get("documentId")
+
+	return id, nil
+}
+
+// Process implements Stage interface.
+//
+// Input documents are already history records, so they are passed as-is.
+func (dh *documentHistory) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	return iter, nil
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*documentHistory)(nil)
+)
@@ -0,0 +1,241 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func init() {
+	// newFacet creates stages of $facet sub-pipelines with NewStage that uses Stages
+	Stages["$facet"] = newFacet
+}
+
+// facet represents $facet stage.
+//
+//	{
+//	  $facet: {
+//	    <outputField1>: [ <stage1>, <stage2>, ... ],
+//	    <outputField2>: [ <stage1>, <stage2>, ... ],
+//	    ...
+//	  }
+//	}
+type facet struct {
+	names     []string
+	pipelines [][]aggregations.Stage
+}
+
+// newFacet validates stage document and creates a new $facet stage.
+func newFacet(stage *types.Document) (aggregations.Stage, error) {
+	fields, err := common.GetRequiredParam[*types.Document](stage, "$facet")
+	if err != nil || fields.Len() == 0 {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFacetNotObject,
+			fmt.Sprintf(
+				"the $facet specification must be a non-empty object, but found: %s",
+				commonparams.AliasFromType(must.NotFail(stage.Get("$facet"))),
+			),
+			"$facet (stage)",
+		)
+	}
+
+	var f facet
+
+	for _, name := range fields.Keys() {
+		if err = validateFacetName(name); err != nil {
+			return nil, err
+		}
+
+		pipeline, err := newFacetPipeline(name, must.NotFail(fields.Get(name)))
+		if err != nil {
+			return nil, err
+		}
+
+		f.names = append(f.names, name)
+		f.pipelines = append(f.pipelines, pipeline)
+	}
+
+	return &f, nil
+}
+
+// validateFacetName returns an error if $facet output field name is not a valid field name.
+func validateFacetName(name string) error {
+	switch {
+	case name == "":
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrPathContainsEmptyElement,
+			"FieldPath field names may not be empty strings.",
+			"$facet (stage)",
+		)
+
+	case strings.HasPrefix(name, "$"):
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFieldPathInvalidName,
+			"FieldPath field names may not start with '$'. Consider using $getField or $setField.",
+			"$facet (stage)",
+		)
+
+	case strings.Contains(name, "."):
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFieldPathContainsDot,
+			"FieldPath field names may not contain '.'. Consider using $getField or $setField.",
+			"$facet (stage)",
+		)
+	}
+
+	return nil
+}
+
+// newFacetPipeline validates $facet sub-pipeline and creates its stages.
+func newFacetPipeline(name string, v any) ([]aggregations.Stage, error) {
+	pipeline, ok := v.(*types.Array)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFacetNotArray,
+			fmt.Sprintf("arguments to $facet must be arrays, %s is type %s", name, commonparams.AliasFromType(v)),
+			"$facet (stage)",
+		)
+	}
+
+	res := make([]aggregations.Stage, 0, pipeline.Len())
+
+	for i := 0; i < pipeline.Len(); i++ {
+		el := must.NotFail(pipeline.Get(i))
+
+		d, ok := el.(*types.Document)
+		if !ok {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrFacetElementNotObject,
+				fmt.Sprintf(
+					"elements of arrays in $facet spec must be non-empty objects, %s argument contained an element of type %s",
+					name, commonparams.AliasFromType(el),
+				),
+				"$facet (stage)",
+			)
+		}
+
+		switch d.Command() {
+		case "$collStats", "$facet", "$merge", "$out", "$sql":
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrFacetStageNotAllowed,
+				fmt.Sprintf("%s is not allowed to be used within a $facet stage", d.Command()),
+				"$facet (stage)",
+			)
+		}
+
+		s, err := NewStage(d)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, s)
+	}
+
+	return res, nil
+}
+
+// Process implements Stage interface.
+//
+// It returns a single document with a field for each sub-pipeline
+// that contains an array of documents produced by that sub-pipeline.
+func (f *facet) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](iter))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res := new(types.Document)
+
+	for i, name := range f.names {
+		arr, err := processFacetPipeline(ctx, f.pipelines[i], docs)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Set(name, arr)
+	}
+
+	iter = iterator.Values(iterator.ForSlice([]*types.Document{res}))
+	closer.Add(iter)
+
+	return iter, nil
+}
+
+// processFacetPipeline applies stages to copies of the given documents
+// and returns resulting documents.
+func processFacetPipeline(ctx context.Context, stages []aggregations.Stage, docs []*types.Document) (*types.Array, error) {
+	input := make([]*types.Document, len(docs))
+	for i, d := range docs {
+		input[i] = d.DeepCopy()
+	}
+
+	closer := iterator.NewMultiCloser()
+	defer closer.Close()
+
+	iter := iterator.Values(iterator.ForSlice(input))
+	closer.Add(iter)
+
+	var err error
+
+	for _, s := range stages {
+		if iter, err = s.Process(ctx, iter, closer); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](iter))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	arr := types.MakeArray(len(res))
+	for _, d := range res {
+		arr.Append(d)
+	}
+
+	return arr, nil
+}
+
+// setCollectionQuery implements collectionQuerier interface.
+func (f *facet) setCollectionQuery(query aggregations.CollectionQuery) {
+	for _, pipeline := range f.pipelines {
+		SetCollectionQuery(pipeline, query)
+	}
+}
+
+// setMemoryLimit implements memoryLimiter interface.
+func (f *facet) setMemoryLimit(limit int64, allowDiskUse bool) {
+	for _, pipeline := range f.pipelines {
+		SetMemoryLimit(pipeline, limit, allowDiskUse)
+	}
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*facet)(nil)
+	_ collectionQuerier  = (*facet)(nil)
+	_ memoryLimiter      = (*facet)(nil)
+)
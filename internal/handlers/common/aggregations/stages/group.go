@@ -46,6 +46,8 @@ import (
 type group struct {
 	groupExpression any
 	groupBy         []groupBy
+	memoryLimit     int64
+	allowDiskUse    bool
 }
 
 // groupBy represents accumulation to apply on the group.
@@ -118,45 +120,126 @@ func newGroup(stage *types.Document) (aggregations.Stage, error) {
 
 // Process implements Stage interface.
 func (g *group) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
-	groupedDocuments, err := g.groupDocuments(iter)
+	groupedDocuments, sorter, err := g.groupDocuments(iter)
 	if err != nil {
 		return nil, err
 	}
 
 	var res []*types.Document
 
-	for _, groupedDocument := range groupedDocuments {
-		doc := must.NotFail(types.NewDocument("_id", groupedDocument.groupID))
+	if sorter != nil {
+		if res, err = g.accumulateSorted(sorter); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, grouped := range groupedDocuments {
+		doc, err := g.accumulate(grouped)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, doc)
+	}
+
+	iter = iterator.Values(iterator.ForSlice(res))
+	closer.Add(iter)
+
+	return iter, nil
+}
+
+// setMemoryLimit implements memoryLimiter interface.
+func (g *group) setMemoryLimit(limit int64, allowDiskUse bool) {
+	g.memoryLimit = limit
+	g.allowDiskUse = allowDiskUse
+}
+
+// accumulate applies accumulators to the documents of a single group.
+func (g *group) accumulate(grouped groupedDocuments) (*types.Document, error) {
+	doc := must.NotFail(types.NewDocument("_id", grouped.groupID))
 
-		groupIter := iterator.Values(iterator.ForSlice(groupedDocument.documents))
+	for _, accumulation := range g.groupBy {
+		// each accumulator iterates over all documents of the group
+		groupIter := iterator.Values(iterator.ForSlice(grouped.documents))
 		defer groupIter.Close()
 
-		for _, accumulation := range g.groupBy {
-			out, err := accumulation.accumulator.Accumulate(groupIter)
-			if err != nil {
-				// existing accumulators do not return error
-				return nil, processGroupStageError(err)
-			}
+		out, err := accumulation.accumulator.Accumulate(groupIter)
+		if err != nil {
+			// existing accumulators do not return error
+			return nil, processGroupStageError(err)
+		}
+
+		if doc.Has(accumulation.outputField) {
+			// document has duplicate key
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrDuplicateField,
+				fmt.Sprintf("duplicate field: %s", accumulation.outputField),
+				"$group (stage)",
+			)
+		}
+
+		doc.Set(accumulation.outputField, out)
+	}
+
+	return doc, nil
+}
+
+// accumulateSorted applies accumulators to documents sorted by group key,
+// so only a single group of documents is held in memory at a time.
+func (g *group) accumulateSorted(sorter *spillSorter) ([]*types.Document, error) {
+	iter, err := sorter.iterator()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	defer iter.Close()
+
+	var res []*types.Document
+	var current *groupedDocuments
+
+	for {
+		_, entry, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		groupKey := must.NotFail(entry.Get("key"))
+		doc := must.NotFail(entry.Get("doc")).(*types.Document)
+
+		if current != nil && types.CompareForAggregation(groupKey, current.groupID) == types.Equal {
+			current.documents = append(current.documents, doc)
+			continue
+		}
 
-			if doc.Has(accumulation.outputField) {
-				// document has duplicate key
-				return nil, commonerrors.NewCommandErrorMsgWithArgument(
-					commonerrors.ErrDuplicateField,
-					fmt.Sprintf("duplicate field: %s", accumulation.outputField),
-					"$group (stage)",
-				)
+		if current != nil {
+			out, err := g.accumulate(*current)
+			if err != nil {
+				return nil, err
 			}
 
-			doc.Set(accumulation.outputField, out)
+			res = append(res, out)
 		}
 
-		res = append(res, doc)
+		current = &groupedDocuments{
+			groupID:   groupKey,
+			documents: []*types.Document{doc},
+		}
 	}
 
-	iter = iterator.Values(iterator.ForSlice(res))
-	closer.Add(iter)
+	if current != nil {
+		out, err := g.accumulate(*current)
+		if err != nil {
+			return nil, err
+		}
 
-	return iter, nil
+		res = append(res, out)
+	}
+
+	return res, nil
 }
 
 // validateGroupKey returns error on invalid group key.
@@ -226,8 +309,18 @@ func validateGroupKey(groupKey any) error {
 
 // groupDocuments groups documents into groups using group key. If group key contains expressions
 // or operators, they are evaluated before using it as the group key of documents.
-func (g *group) groupDocuments(iter types.DocumentsIterator) ([]groupedDocuments, error) {
+//
+// If documents exceed the memory limit and disk use is allowed, no groups are returned;
+// instead, all documents are added to the returned sorter ordered by group key.
+func (g *group) groupDocuments(iter types.DocumentsIterator) (groups []groupedDocuments, sorter *spillSorter, err error) {
+	defer func() {
+		if err != nil && sorter != nil {
+			sorter.close()
+		}
+	}()
+
 	var m groupMap
+	var size int64
 
 	for {
 		_, doc, err := iter.Next()
@@ -236,50 +329,116 @@ func (g *group) groupDocuments(iter types.DocumentsIterator) ([]groupedDocuments
 		}
 
 		if err != nil {
-			return nil, lazyerrors.Error(err)
+			return nil, sorter, lazyerrors.Error(err)
 		}
 
-		switch groupKey := g.groupExpression.(type) {
-		case *types.Document:
-			val, err := evaluateDocument(groupKey, doc, false)
-			if err != nil {
-				// operator and expression errors are validated in newGroup
-				return nil, lazyerrors.Error(err)
+		groupKey, err := g.groupKey(doc)
+		if err != nil {
+			return nil, sorter, err
+		}
+
+		if sorter != nil {
+			if err = sorter.add(newGroupEntry(groupKey, doc)); err != nil {
+				return nil, sorter, lazyerrors.Error(err)
 			}
 
-			m.addOrAppend(val, doc)
-		case *types.Array, float64, types.Binary, types.ObjectID, bool, time.Time, types.NullType,
-			types.Regex, int32, types.Timestamp, int64:
-			m.addOrAppend(groupKey, doc)
-		case string:
-			expression, err := aggregations.NewExpression(groupKey, nil)
-			if err != nil {
-				var exprErr *aggregations.ExpressionError
-				if errors.As(err, &exprErr) {
-					if exprErr.Code() == aggregations.ErrNotExpression {
-						m.addOrAppend(groupKey, doc)
-						continue
-					}
-
-					return nil, processGroupStageError(err)
-				}
+			continue
+		}
 
-				return nil, lazyerrors.Error(err)
+		m.addOrAppend(groupKey, doc)
+
+		if g.memoryLimit == 0 {
+			continue
+		}
+
+		docSize, err := documentSize(doc)
+		if err != nil {
+			return nil, sorter, lazyerrors.Error(err)
+		}
+
+		if size += docSize; size <= g.memoryLimit {
+			continue
+		}
+
+		if !g.allowDiskUse {
+			return nil, sorter, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrQueryExceededMemoryLimitNoDiskUseAllowed,
+				"Exceeded memory limit for $group, but didn't allow external sort. Pass allowDiskUse:true to opt in.",
+				"$group (stage)",
+			)
+		}
+
+		// groups do not fit into memory, sort all documents by group key using disk instead
+		sorter = &spillSorter{
+			less:         lessGroupEntry,
+			limit:        g.memoryLimit,
+			allowDiskUse: true,
+		}
+
+		for _, grouped := range m.docs {
+			for _, d := range grouped.documents {
+				if err = sorter.add(newGroupEntry(grouped.groupID, d)); err != nil {
+					return nil, sorter, lazyerrors.Error(err)
+				}
 			}
+		}
 
-			val, err := expression.Evaluate(doc)
-			if err != nil {
-				// $group treats non-existent fields as nulls
-				val = types.Null
+		m = groupMap{}
+	}
+
+	return m.docs, sorter, nil
+}
+
+// groupKey returns the group key of the document.
+func (g *group) groupKey(doc *types.Document) (any, error) {
+	switch groupKey := g.groupExpression.(type) {
+	case *types.Document:
+		val, err := evaluateDocument(groupKey, doc, false)
+		if err != nil {
+			// operator and expression errors are validated in newGroup
+			return nil, lazyerrors.Error(err)
+		}
+
+		return val, nil
+	case *types.Array, float64, types.Binary, types.ObjectID, bool, time.Time, types.NullType,
+		types.Regex, int32, types.Timestamp, int64:
+		return groupKey, nil
+	case string:
+		expression, err := aggregations.NewExpression(groupKey, nil)
+		if err != nil {
+			var exprErr *aggregations.ExpressionError
+			if errors.As(err, &exprErr) {
+				if exprErr.Code() == aggregations.ErrNotExpression {
+					return groupKey, nil
+				}
+
+				return nil, processGroupStageError(err)
 			}
 
-			m.addOrAppend(val, doc)
-		default:
-			panic(fmt.Sprintf("unexpected type %[1]T (%#[1]v)", groupKey))
+			return nil, lazyerrors.Error(err)
 		}
+
+		val, err := expression.Evaluate(doc)
+		if err != nil {
+			// $group treats non-existent fields as nulls
+			val = types.Null
+		}
+
+		return val, nil
+	default:
+		panic(fmt.Sprintf("unexpected type %[1]T (%#[1]v)", groupKey))
 	}
+}
+
+// newGroupEntry returns a document that holds the group key together with the grouped document,
+// so they can be sorted by the group key.
+func newGroupEntry(groupKey any, doc *types.Document) *types.Document {
+	return must.NotFail(types.NewDocument("key", groupKey, "doc", doc))
+}
 
-	return m.docs, nil
+// lessGroupEntry reports whether group entry a must sort before group entry b.
+func lessGroupEntry(a, b *types.Document) bool {
+	return types.CompareForAggregation(must.NotFail(a.Get("key")), must.NotFail(b.Get("key"))) == types.Less
 }
 
 // evaluateDocument recursively evaluates document's field expressions and operators.
@@ -468,4 +627,5 @@ func processGroupStageError(err error) error {
 // check interfaces
 var (
 	_ aggregations.Stage = (*group)(nil)
+	_ memoryLimiter      = (*group)(nil)
 )
@@ -0,0 +1,563 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonpath"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func init() {
+	// newLookup creates stages of $lookup pipeline with NewStage that uses Stages
+	Stages["$lookup"] = newLookup
+}
+
+// lookup represents $lookup stage.
+//
+//	{
+//	  $lookup: {
+//	    from: <collection>,
+//	    localField: <field>,
+//	    foreignField: <field>,
+//	    pipeline: [ <stage1>, ... ],
+//	    as: <output array field>
+//	  }
+//	}
+type lookup struct {
+	query        aggregations.CollectionQuery
+	from         string
+	localField   types.Path
+	foreignField string
+	as           types.Path
+	pipeline     []aggregations.Stage
+	memoryLimit  int64
+	hasFields    bool
+	allowDiskUse bool
+}
+
+// newLookup validates stage document and creates a new $lookup stage.
+func newLookup(stage *types.Document) (aggregations.Stage, error) {
+	fields, err := common.GetRequiredParam[*types.Document](stage, "$lookup")
+	if err != nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFailedToParse,
+			fmt.Sprintf(
+				"the $lookup stage specification must be an object, but found %s",
+				commonparams.AliasFromType(must.NotFail(stage.Get("$lookup"))),
+			),
+			"$lookup (stage)",
+		)
+	}
+
+	var l lookup
+	var localField, foreignField, as string
+	var hasLocal, hasForeign, hasPipeline bool
+
+	for _, key := range fields.Keys() {
+		v := must.NotFail(fields.Get(key))
+
+		switch key {
+		case "from":
+			if l.from, err = lookupStringArg(key, v); err != nil {
+				return nil, err
+			}
+
+		case "localField":
+			if localField, err = lookupStringArg(key, v); err != nil {
+				return nil, err
+			}
+
+			hasLocal = true
+
+		case "foreignField":
+			if foreignField, err = lookupStringArg(key, v); err != nil {
+				return nil, err
+			}
+
+			hasForeign = true
+
+		case "as":
+			if as, err = lookupStringArg(key, v); err != nil {
+				return nil, err
+			}
+
+		case "pipeline":
+			if l.pipeline, err = newLookupPipeline(v); err != nil {
+				return nil, err
+			}
+
+			hasPipeline = true
+
+		case "let":
+			// TODO https://github.com/FerretDB/FerretDB/issues/2275
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				"$lookup 'let' is not implemented yet",
+				"$lookup (stage)",
+			)
+
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrFailedToParse,
+				fmt.Sprintf("unknown argument to $lookup: %s", key),
+				"$lookup (stage)",
+			)
+		}
+	}
+
+	if as == "" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFailedToParse,
+			"must specify 'as' field for a $lookup",
+			"$lookup (stage)",
+		)
+	}
+
+	if l.from == "" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFailedToParse,
+			"must specify 'from' field for a $lookup",
+			"$lookup (stage)",
+		)
+	}
+
+	if hasLocal != hasForeign || (!hasLocal && !hasPipeline) {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFailedToParse,
+			"$lookup requires either 'pipeline' or both 'localField' and 'foreignField' to be specified",
+			"$lookup (stage)",
+		)
+	}
+
+	if l.as, err = lookupPath("as", as); err != nil {
+		return nil, err
+	}
+
+	if hasLocal {
+		if l.localField, err = lookupPath("localField", localField); err != nil {
+			return nil, err
+		}
+
+		if _, err = lookupPath("foreignField", foreignField); err != nil {
+			return nil, err
+		}
+
+		l.foreignField = foreignField
+		l.hasFields = true
+	}
+
+	return &l, nil
+}
+
+// lookupStringArg returns the string value of $lookup argument.
+func lookupStringArg(key string, v any) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFailedToParse,
+			fmt.Sprintf("$lookup argument '%s' must be a string, is type %s", key, commonparams.AliasFromType(v)),
+			"$lookup (stage)",
+		)
+	}
+
+	return s, nil
+}
+
+// lookupPath returns the path of $lookup field argument.
+func lookupPath(key, field string) (types.Path, error) {
+	path, err := types.NewPathFromString(field)
+	if err != nil || field[0] == '$' {
+		return types.Path{}, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFailedToParse,
+			fmt.Sprintf("$lookup argument '%s' must be a valid field path, got '%s'", key, field),
+			"$lookup (stage)",
+		)
+	}
+
+	return path, nil
+}
+
+// newLookupPipeline validates $lookup pipeline and creates its stages.
+func newLookupPipeline(v any) ([]aggregations.Stage, error) {
+	pipeline, ok := v.(*types.Array)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFailedToParse,
+			fmt.Sprintf("$lookup argument 'pipeline' must be an array, is type %s", commonparams.AliasFromType(v)),
+			"$lookup (stage)",
+		)
+	}
+
+	res := make([]aggregations.Stage, 0, pipeline.Len())
+
+	for i := 0; i < pipeline.Len(); i++ {
+		d, ok := must.NotFail(pipeline.Get(i)).(*types.Document)
+		if !ok {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrTypeMismatch,
+				"Each element of the 'pipeline' array must be an object",
+				"$lookup (stage)",
+			)
+		}
+
+		switch d.Command() {
+		case "$collStats", "$merge", "$out", "$sql":
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrLookupStageNotAllowed,
+				fmt.Sprintf("%s is not allowed to be used within a $lookup stage", d.Command()),
+				"$lookup (stage)",
+			)
+		}
+
+		s, err := NewStage(d)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, s)
+	}
+
+	return res, nil
+}
+
+// lookupBatchSize is the maximal number of documents for which foreign documents are queried at once.
+const lookupBatchSize = 100
+
+// Process implements Stage interface.
+func (l *lookup) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	if l.query == nil {
+		return nil, lazyerrors.New("$lookup collection query is not set")
+	}
+
+	// all documents are consumed before querying the foreign collection,
+	// so the backend is not queried while the previous query is in progress
+	docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](iter))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if l.hasFields {
+		err = l.joinFields(ctx, docs)
+	} else {
+		err = l.joinPipeline(ctx, docs)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	iter = iterator.Values(iterator.ForSlice(docs))
+	closer.Add(iter)
+
+	return iter, nil
+}
+
+// joinFields sets the `as` field of given documents to foreign documents matched by localField and foreignField.
+//
+// Foreign documents are queried for batches of documents with the filter
+// that selects foreignField values equal to any localField value of the batch; that filter is pushed down.
+// If the total size of foreign documents of the batch exceeds the memory limit and disk use is allowed,
+// they are queried for each document of that batch separately.
+func (l *lookup) joinFields(ctx context.Context, docs []*types.Document) error {
+	for len(docs) > 0 {
+		batch := docs[:min(len(docs), lookupBatchSize)]
+		docs = docs[len(batch):]
+
+		foreignDocs, err := l.queryForeign(ctx, batch, true)
+		if err != nil {
+			return err
+		}
+
+		if foreignDocs == nil {
+			if !l.allowDiskUse {
+				return commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrQueryExceededMemoryLimitNoDiskUseAllowed,
+					"Exceeded memory limit for $lookup, but didn't allow external sort. Pass allowDiskUse:true to opt in.",
+					"$lookup (stage)",
+				)
+			}
+
+			for _, doc := range batch {
+				if foreignDocs, err = l.queryForeign(ctx, []*types.Document{doc}, false); err != nil {
+					return err
+				}
+
+				if err = l.join(ctx, doc, foreignDocs); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		for _, doc := range batch {
+			if err = l.join(ctx, doc, foreignDocs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// queryForeign returns foreign documents with foreignField value equal to any localField value of given documents.
+//
+// If checkLimit is true and the total size of those foreign documents exceeds the memory limit, it returns nil.
+func (l *lookup) queryForeign(ctx context.Context, docs []*types.Document, checkLimit bool) ([]*types.Document, error) {
+	in := types.MakeArray(len(docs))
+
+	for _, doc := range docs {
+		values, err := l.localValues(doc)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		for i := 0; i < values.Len(); i++ {
+			in.Append(must.NotFail(values.Get(i)))
+		}
+	}
+
+	filter := must.NotFail(types.NewDocument(l.foreignField, must.NotFail(types.NewDocument("$in", in))))
+
+	foreignIter, err := l.query(ctx, l.from, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	defer foreignIter.Close()
+
+	res := []*types.Document{}
+
+	var size int64
+
+	for {
+		_, doc, err := foreignIter.Next()
+		if err != nil {
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				return res, nil
+			}
+
+			return nil, lazyerrors.Error(err)
+		}
+
+		matches, err := common.FilterDocument(doc, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		if !matches {
+			continue
+		}
+
+		res = append(res, doc)
+
+		if !checkLimit || l.memoryLimit == 0 {
+			continue
+		}
+
+		docSize, err := documentSize(doc)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if size += docSize; size > l.memoryLimit {
+			return nil, nil
+		}
+	}
+}
+
+// join sets the `as` field of the document to the given foreign documents
+// that match its localField values, processed by the pipeline.
+func (l *lookup) join(ctx context.Context, doc *types.Document, foreignDocs []*types.Document) error {
+	values, err := l.localValues(doc)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	filter := must.NotFail(types.NewDocument(l.foreignField, must.NotFail(types.NewDocument("$in", values))))
+
+	var matched []*types.Document
+
+	for _, foreignDoc := range foreignDocs {
+		matches, err := common.FilterDocument(foreignDoc, filter)
+		if err != nil {
+			return err
+		}
+
+		if matches {
+			matched = append(matched, foreignDoc)
+		}
+	}
+
+	joined, err := l.processPipeline(ctx, iterator.Values(iterator.ForSlice(matched)))
+	if err != nil {
+		return err
+	}
+
+	if err = doc.SetByPath(l.as, joined); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// joinPipeline sets the `as` field of given documents to the result of the pipeline
+// applied to all foreign documents.
+//
+// Without `let`, the pipeline does not depend on documents,
+// so it is applied once to streamed foreign documents; only its result is held in memory.
+func (l *lookup) joinPipeline(ctx context.Context, docs []*types.Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	foreignIter, err := l.query(ctx, l.from, nil)
+	if err != nil {
+		return err
+	}
+
+	joined, err := l.processPipeline(ctx, foreignIter)
+	if err != nil {
+		return err
+	}
+
+	if l.memoryLimit != 0 {
+		size, err := documentSize(must.NotFail(types.NewDocument("", joined)))
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if size > l.memoryLimit {
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrQueryExceededMemoryLimitNoDiskUseAllowed,
+				"Exceeded memory limit for $lookup pipeline result",
+				"$lookup (stage)",
+			)
+		}
+	}
+
+	for _, doc := range docs {
+		if err = doc.SetByPath(l.as, joined.DeepCopy()); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// localValues returns localField values of the document used to match foreign documents.
+// Array values are expanded; missing localField is represented by null.
+func (l *lookup) localValues(doc *types.Document) (*types.Array, error) {
+	values, err := commonpath.FindValues(doc, l.localField, &commonpath.FindValuesOpts{
+		FindArrayDocuments: true,
+		FindArrayIndex:     true,
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	in := types.MakeArray(len(values))
+
+	for _, v := range values {
+		arr, ok := v.(*types.Array)
+		if !ok {
+			in.Append(v)
+			continue
+		}
+
+		for i := 0; i < arr.Len(); i++ {
+			in.Append(must.NotFail(arr.Get(i)))
+		}
+	}
+
+	if in.Len() == 0 {
+		in.Append(types.Null)
+	}
+
+	return in, nil
+}
+
+// processPipeline applies $lookup pipeline to the given foreign documents
+// and returns resulting documents.
+//
+// Documents are deep-copied, so stages do not modify foreign documents shared between documents.
+// The iterator is closed.
+func (l *lookup) processPipeline(ctx context.Context, foreignIter types.DocumentsIterator) (*types.Array, error) {
+	closer := iterator.NewMultiCloser(foreignIter)
+	defer closer.Close()
+
+	var iter types.DocumentsIterator = iterator.ForFunc(func() (struct{}, *types.Document, error) {
+		_, doc, err := foreignIter.Next()
+		if err != nil {
+			return struct{}{}, nil, err
+		}
+
+		return struct{}{}, doc.DeepCopy(), nil
+	})
+	closer.Add(iter)
+
+	var err error
+
+	for _, s := range l.pipeline {
+		if iter, err = s.Process(ctx, iter, closer); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](iter))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	arr := types.MakeArray(len(res))
+	for _, d := range res {
+		arr.Append(d)
+	}
+
+	return arr, nil
+}
+
+// setCollectionQuery implements collectionQuerier interface.
+func (l *lookup) setCollectionQuery(query aggregations.CollectionQuery) {
+	l.query = query
+
+	SetCollectionQuery(l.pipeline, query)
+}
+
+// setMemoryLimit implements memoryLimiter interface.
+func (l *lookup) setMemoryLimit(limit int64, allowDiskUse bool) {
+	l.memoryLimit = limit
+	l.allowDiskUse = allowDiskUse
+
+	SetMemoryLimit(l.pipeline, limit, allowDiskUse)
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*lookup)(nil)
+	_ collectionQuerier  = (*lookup)(nil)
+	_ memoryLimiter      = (*lookup)(nil)
+)
@@ -0,0 +1,474 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// merge represents $merge stage.
+//
+//	{
+//	  $merge: {
+//	    into: <collection> -or- { db: <db>, coll: <collection> },
+//	    on: <identifier field> -or- [ <identifier field1>, ...],
+//	    whenMatched: <replace|keepExisting|merge|fail>,
+//	    whenNotMatched: <insert|discard|fail>
+//	  }
+//	}
+type merge struct {
+	writer         aggregations.CollectionWriter
+	db             string
+	collection     string
+	on             []types.Path
+	whenMatched    string
+	whenNotMatched string
+}
+
+// newMerge validates stage document and creates a new $merge stage.
+func newMerge(stage *types.Document) (aggregations.Stage, error) {
+	m := merge{
+		on:             []types.Path{types.NewStaticPath("_id")},
+		whenMatched:    "merge",
+		whenNotMatched: "insert",
+	}
+
+	switch v := must.NotFail(stage.Get("$merge")).(type) {
+	case string:
+		m.collection = v
+
+	case *types.Document:
+		if err := m.setOptions(v); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrMergeNotObject,
+			fmt.Sprintf("$merge only supports a string or object argument, not %s", commonparams.AliasFromType(v)),
+			"$merge (stage)",
+		)
+	}
+
+	if m.collection == "" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidNamespace,
+			"Invalid $merge target namespace, collection name must not be empty",
+			"$merge (stage)",
+		)
+	}
+
+	if strings.HasPrefix(m.collection, "system.") {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrMergeSpecialCollection,
+			fmt.Sprintf("Cannot $merge to special collection: %s", m.collection),
+			"$merge (stage)",
+		)
+	}
+
+	return &m, nil
+}
+
+// setOptions validates $merge options document and sets its values.
+func (m *merge) setOptions(options *types.Document) error {
+	var hasInto bool
+
+	for _, key := range options.Keys() {
+		v := must.NotFail(options.Get(key))
+
+		switch key {
+		case "into":
+			switch into := v.(type) {
+			case string:
+				m.collection = into
+			case *types.Document:
+				var err error
+				if m.db, m.collection, err = targetNamespace("$merge.into", into, "db", "coll"); err != nil {
+					return err
+				}
+			default:
+				return commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrMergeIntoWrongType,
+					fmt.Sprintf(
+						"$merge 'into' field must be either a string or an object, but found %s",
+						commonparams.AliasFromType(v),
+					),
+					"$merge (stage)",
+				)
+			}
+
+			hasInto = true
+
+		case "on":
+			if err := m.setOn(v); err != nil {
+				return err
+			}
+
+		case "whenMatched":
+			switch whenMatched := v.(type) {
+			case string:
+				m.whenMatched = whenMatched
+			case *types.Array:
+				return commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrNotImplemented,
+					"$merge 'whenMatched' pipeline is not implemented yet",
+					"$merge (stage)",
+				)
+			default:
+				return commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrTypeMismatch,
+					fmt.Sprintf(
+						"BSON field '$merge.whenMatched' is the wrong type '%s', expected types '[string, array]'",
+						commonparams.AliasFromType(v),
+					),
+					"$merge (stage)",
+				)
+			}
+
+			switch m.whenMatched {
+			case "replace", "keepExisting", "merge", "fail":
+			default:
+				return mergeEnumError("whenMatched", m.whenMatched)
+			}
+
+		case "whenNotMatched":
+			whenNotMatched, ok := v.(string)
+			if !ok {
+				return commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrTypeMismatch,
+					fmt.Sprintf(
+						"BSON field '$merge.whenNotMatched' is the wrong type '%s', expected type 'string'",
+						commonparams.AliasFromType(v),
+					),
+					"$merge (stage)",
+				)
+			}
+
+			switch whenNotMatched {
+			case "insert", "discard", "fail":
+				m.whenNotMatched = whenNotMatched
+			default:
+				return mergeEnumError("whenNotMatched", whenNotMatched)
+			}
+
+		case "let":
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				"$merge 'let' is not implemented yet",
+				"$merge (stage)",
+			)
+
+		default:
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrFailedToParseInput,
+				fmt.Sprintf("BSON field '$merge.%s' is an unknown field.", key),
+				"$merge (stage)",
+			)
+		}
+	}
+
+	if !hasInto {
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrMissingField,
+			"BSON field '$merge.into' is missing but a required field",
+			"$merge (stage)",
+		)
+	}
+
+	return nil
+}
+
+// setOn validates $merge 'on' field and sets identifier fields.
+func (m *merge) setOn(v any) error {
+	wrongType := commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrMergeOnWrongType,
+		fmt.Sprintf(
+			"$merge 'on' field must be either a string or an array of strings, but found %s",
+			commonparams.AliasFromType(v),
+		),
+		"$merge (stage)",
+	)
+
+	var fields []string
+
+	switch on := v.(type) {
+	case string:
+		fields = []string{on}
+
+	case *types.Array:
+		if on.Len() == 0 {
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrMergeOnEmpty,
+				"If explicitly specifying $merge 'on', must include at least one field",
+				"$merge (stage)",
+			)
+		}
+
+		for i := 0; i < on.Len(); i++ {
+			s, ok := must.NotFail(on.Get(i)).(string)
+			if !ok {
+				return wrongType
+			}
+
+			fields = append(fields, s)
+		}
+
+	default:
+		return wrongType
+	}
+
+	m.on = make([]types.Path, len(fields))
+
+	for i, field := range fields {
+		path, err := types.NewPathFromString(field)
+		if err != nil || strings.HasPrefix(field, "$") {
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrFailedToParse,
+				fmt.Sprintf("$merge 'on' field must be a valid field path, got '%s'", field),
+				"$merge (stage)",
+			)
+		}
+
+		m.on[i] = path
+	}
+
+	return nil
+}
+
+// mergeEnumError returns an error for invalid value of $merge enumeration field.
+func mergeEnumError(field, value string) error {
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrBadValue,
+		fmt.Sprintf("Enumeration value '%s' for field '$merge.%s' is not a valid value.", value, field),
+		"$merge (stage)",
+	)
+}
+
+// Process implements Stage interface.
+//
+// It writes the input documents to the target collection and returns no documents.
+func (m *merge) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	if m.writer == nil {
+		return nil, lazyerrors.New("$merge collection writer is not set")
+	}
+
+	docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](iter))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	targetIter, err := m.writer.Query(ctx, m.db, m.collection)
+	if err != nil {
+		return nil, err
+	}
+
+	targets, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](targetIter))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	// documents of targets starting from this index are inserted by this stage
+	inserted := len(targets)
+	updated := map[int]struct{}{}
+
+	for _, doc := range docs {
+		i, err := m.match(doc, targets)
+		if err != nil {
+			return nil, err
+		}
+
+		if i < 0 {
+			switch m.whenNotMatched {
+			case "insert":
+				targets = append(targets, doc)
+			case "discard":
+				// nothing
+			case "fail":
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrMergeStageNoMatchingDocument,
+					"$merge could not find a matching document in the target collection "+
+						"for at least one document in the source collection",
+					"$merge (stage)",
+				)
+			default:
+				panic(fmt.Sprintf("unexpected whenNotMatched %q", m.whenNotMatched))
+			}
+
+			continue
+		}
+
+		target := targets[i]
+
+		switch m.whenMatched {
+		case "replace", "merge":
+			if targets[i], err = m.mergeDocument(target, doc); err != nil {
+				return nil, err
+			}
+		case "keepExisting":
+			continue
+		case "fail":
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrDuplicateKeyInsert,
+				fmt.Sprintf(
+					"$merge with whenMatched: fail found an existing document with the same values for the 'on' field: %s",
+					types.FormatAnyValue(m.onValues(doc)),
+				),
+				"$merge (stage)",
+			)
+		default:
+			panic(fmt.Sprintf("unexpected whenMatched %q", m.whenMatched))
+		}
+
+		if i < inserted {
+			updated[i] = struct{}{}
+		}
+	}
+
+	params := &aggregations.WriteParams{
+		DB:         m.db,
+		Collection: m.collection,
+		Insert:     targets[inserted:],
+	}
+
+	for i := range targets[:inserted] {
+		if _, ok := updated[i]; ok {
+			params.Update = append(params.Update, targets[i])
+		}
+	}
+
+	if err = m.writer.Write(ctx, params); err != nil {
+		return nil, err
+	}
+
+	iter = iterator.Values(iterator.ForSlice([]*types.Document{}))
+	closer.Add(iter)
+
+	return iter, nil
+}
+
+// match returns the index of the target document with the same 'on' fields values
+// as the given document, or -1 if there is no such document.
+func (m *merge) match(doc *types.Document, targets []*types.Document) (int, error) {
+	values := make([]any, len(m.on))
+
+	for i, path := range m.on {
+		v, _ := doc.GetByPath(path)
+
+		switch v.(type) {
+		case nil:
+			// documents without _id are always inserted with a new _id
+			if len(m.on) == 1 && path.String() == "_id" {
+				return -1, nil
+			}
+
+			return 0, mergeOnFieldError()
+		case types.NullType, *types.Array:
+			return 0, mergeOnFieldError()
+		}
+
+		values[i] = v
+	}
+
+	for i, target := range targets {
+		matches := true
+
+		for j, path := range m.on {
+			v, err := target.GetByPath(path)
+			if err != nil || types.CompareForAggregation(v, values[j]) != types.Equal {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			return i, nil
+		}
+	}
+
+	return -1, nil
+}
+
+// onValues returns a document with 'on' fields values of the given document.
+func (m *merge) onValues(doc *types.Document) *types.Document {
+	res := new(types.Document)
+
+	for _, path := range m.on {
+		v, _ := doc.GetByPath(path)
+		res.Set(path.String(), v)
+	}
+
+	return res
+}
+
+// mergeOnFieldError returns an error for missing or invalid 'on' field value.
+func mergeOnFieldError() error {
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrMergeOnFieldMissing,
+		"$merge write error: 'on' field cannot be missing, null, undefined or an array",
+		"$merge (stage)",
+	)
+}
+
+// mergeDocument returns a new target document for whenMatched "replace" or "merge".
+// The _id of the target document is kept.
+func (m *merge) mergeDocument(target, doc *types.Document) (*types.Document, error) {
+	id := must.NotFail(target.Get("_id"))
+
+	if docID, _ := doc.Get("_id"); docID != nil && types.CompareForAggregation(docID, id) != types.Equal {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrImmutableField,
+			"$merge failed to update the matching document, did you attempt to modify the _id or the shard key? "+
+				":: caused by :: Performing an update on the path '_id' would modify the immutable field '_id'",
+			"$merge (stage)",
+		)
+	}
+
+	res := must.NotFail(types.NewDocument("_id", id))
+
+	if m.whenMatched == "merge" {
+		res = target.DeepCopy()
+	}
+
+	for _, key := range doc.Keys() {
+		if key == "_id" {
+			continue
+		}
+
+		res.Set(key, must.NotFail(doc.Get(key)))
+	}
+
+	return res, nil
+}
+
+// setCollectionWriter implements collectionWriter interface.
+func (m *merge) setCollectionWriter(writer aggregations.CollectionWriter) {
+	m.writer = writer
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*merge)(nil)
+	_ collectionWriter   = (*merge)(nil)
+)
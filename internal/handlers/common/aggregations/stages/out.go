@@ -0,0 +1,165 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// out represents $out stage.
+//
+//	{ $out: <collection> }
+//	{ $out: { db: <database>, coll: <collection> } }
+type out struct {
+	writer     aggregations.CollectionWriter
+	db         string
+	collection string
+}
+
+// newOut validates stage document and creates a new $out stage.
+func newOut(stage *types.Document) (aggregations.Stage, error) {
+	var o out
+
+	switch v := must.NotFail(stage.Get("$out")).(type) {
+	case string:
+		o.collection = v
+
+	case *types.Document:
+		var err error
+		if o.db, o.collection, err = targetNamespace("$out", v, "db", "coll"); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrOutNotString,
+			fmt.Sprintf("$out only supports a string or object argument, not %s", commonparams.AliasFromType(v)),
+			"$out (stage)",
+		)
+	}
+
+	if o.collection == "" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidNamespace,
+			"Invalid $out target namespace, collection name must not be empty",
+			"$out (stage)",
+		)
+	}
+
+	if strings.HasPrefix(o.collection, "system.") {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrOutSpecialCollection,
+			fmt.Sprintf("Can't $out to special collection: %s", o.collection),
+			"$out (stage)",
+		)
+	}
+
+	return &o, nil
+}
+
+// targetNamespace returns database and collection names from the target document of $out or $merge stage.
+// Both fields are required.
+func targetNamespace(stage string, doc *types.Document, dbField, collField string) (string, string, error) {
+	res := map[string]string{}
+
+	for _, key := range doc.Keys() {
+		if key != dbField && key != collField {
+			return "", "", commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrFailedToParseInput,
+				fmt.Sprintf("BSON field '%s.%s' is an unknown field.", stage, key),
+				stage+" (stage)",
+			)
+		}
+
+		v := must.NotFail(doc.Get(key))
+
+		s, ok := v.(string)
+		if !ok {
+			return "", "", commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrTypeMismatch,
+				fmt.Sprintf(
+					"BSON field '%s.%s' is the wrong type '%s', expected type 'string'",
+					stage, key, commonparams.AliasFromType(v),
+				),
+				stage+" (stage)",
+			)
+		}
+
+		res[key] = s
+	}
+
+	for _, key := range []string{dbField, collField} {
+		if _, ok := res[key]; !ok {
+			return "", "", commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrMissingField,
+				fmt.Sprintf("BSON field '%s.%s' is missing but a required field", stage, key),
+				stage+" (stage)",
+			)
+		}
+	}
+
+	return res[dbField], res[collField], nil
+}
+
+// Process implements Stage interface.
+//
+// It replaces all documents of the target collection with the input documents
+// and returns no documents.
+func (o *out) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	if o.writer == nil {
+		return nil, lazyerrors.New("$out collection writer is not set")
+	}
+
+	docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](iter))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	err = o.writer.Write(ctx, &aggregations.WriteParams{
+		DB:         o.db,
+		Collection: o.collection,
+		Insert:     docs,
+		Replace:    true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	iter = iterator.Values(iterator.ForSlice([]*types.Document{}))
+	closer.Add(iter)
+
+	return iter, nil
+}
+
+// setCollectionWriter implements collectionWriter interface.
+func (o *out) setCollectionWriter(writer aggregations.CollectionWriter) {
+	o.writer = writer
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*out)(nil)
+	_ collectionWriter   = (*out)(nil)
+)
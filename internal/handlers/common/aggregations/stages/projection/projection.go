@@ -175,7 +175,18 @@ func ValidateProjection(projection *types.Document) (*types.Document, bool, erro
 
 			result = true
 
-		case *types.Array, string, types.Binary, types.ObjectID,
+		case string:
+			if strings.HasPrefix(value, "$") {
+				// field path expression
+				if _, err = aggregations.NewExpression(value, nil); err != nil {
+					return nil, false, processOperatorError(err)
+				}
+			}
+
+			result = true
+
+			validated.Set(key, value)
+		case *types.Array, types.Binary, types.ObjectID,
 			time.Time, types.NullType, types.Regex, types.Timestamp: // all this types are treated as new fields value
 			result = true
 
@@ -275,7 +286,18 @@ func ProjectDocument(doc, projection *types.Document, inclusion bool) (*types.Do
 			set = true
 			projected.Set("_id", value)
 
-		case *types.Array, string, types.Binary, types.ObjectID,
+		case string:
+			var value any
+
+			if value, set, err = evaluateString(idValue, doc); err != nil {
+				return nil, err
+			}
+
+			if set {
+				projected.Set("_id", value)
+			}
+
+		case *types.Array, types.Binary, types.ObjectID,
 			time.Time, types.NullType, types.Regex, types.Timestamp: // all this types are treated as new fields value
 			projected.Set("_id", idValue)
 
@@ -362,7 +384,17 @@ func projectDocumentWithoutID(doc *types.Document, projection *types.Document, i
 
 			projected.Set(key, v)
 
-		case *types.Array, string, types.Binary, types.ObjectID,
+		case string:
+			v, ok, err := evaluateString(value, doc)
+			if err != nil {
+				return nil, err
+			}
+
+			if ok {
+				projected.Set(key, v)
+			}
+
+		case *types.Array, types.Binary, types.ObjectID,
 			time.Time, types.NullType, types.Regex, types.Timestamp: // all these types are treated as new fields value
 			projected.Set(key, value)
 
@@ -386,6 +418,27 @@ func projectDocumentWithoutID(doc *types.Document, projection *types.Document, i
 	return projected, nil
 }
 
+// evaluateString returns the value of the field path expression such as `$v.foo` for the given document,
+// or the string itself if it is not an expression.
+// It returns false if the expression refers to a missing field, such field is not projected.
+func evaluateString(s string, doc *types.Document) (any, bool, error) {
+	if !strings.HasPrefix(s, "$") {
+		return s, true, nil
+	}
+
+	expression, err := aggregations.NewExpression(s, nil)
+	if err != nil {
+		return nil, false, processOperatorError(err)
+	}
+
+	v, err := expression.Evaluate(doc)
+	if err != nil {
+		return nil, false, nil
+	}
+
+	return v, true, nil
+}
+
 // includeProjection copies the field on the path from source to projected.
 // When an array is on the path, it returns the array containing any document
 // with the same key. Dot notation with array index path does not include
@@ -0,0 +1,147 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// sample represents $sample stage.
+//
+//	{ $sample: { size: <positive integer N> } }
+type sample struct {
+	size int64
+}
+
+// newSample creates a new $sample stage.
+func newSample(stage *types.Document) (aggregations.Stage, error) {
+	fields, ok := must.NotFail(stage.Get("$sample")).(*types.Document)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrSampleNotObject,
+			fmt.Sprintf(
+				"the $sample stage specification must be an object, got %s",
+				commonparams.AliasFromType(must.NotFail(stage.Get("$sample"))),
+			),
+			"$sample (stage)",
+		)
+	}
+
+	var size int64
+	var sizeSet bool
+
+	for _, k := range fields.Keys() {
+		if k != "size" {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrSampleUnknownOption,
+				fmt.Sprintf("unrecognized option to $sample: %s", k),
+				"$sample (stage)",
+			)
+		}
+
+		switch v := must.NotFail(fields.Get(k)).(type) {
+		case float64:
+			size = int64(v)
+		case int32:
+			size = int64(v)
+		case int64:
+			size = v
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrSampleSizeNotNumber,
+				"size argument to $sample must be a number",
+				"$sample (stage)",
+			)
+		}
+
+		sizeSet = true
+	}
+
+	if !sizeSet {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrSampleMissingSize,
+			"$sample stage must specify a size",
+			"$sample (stage)",
+		)
+	}
+
+	if size < 0 {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrSampleSizeNegative,
+			"size argument to $sample must not be negative",
+			"$sample (stage)",
+		)
+	}
+
+	return &sample{
+		size: size,
+	}, nil
+}
+
+// Process implements Stage interface.
+//
+// It uses reservoir sampling, so at most size documents are held in memory.
+// Selected documents are returned in random order.
+func (s *sample) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	var res []*types.Document
+	var n int64
+
+	for {
+		_, doc, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		n++
+
+		if int64(len(res)) < s.size {
+			res = append(res, doc)
+			continue
+		}
+
+		if i := rand.Int63n(n); i < s.size {
+			res[i] = doc
+		}
+	}
+
+	rand.Shuffle(len(res), func(i, j int) {
+		res[i], res[j] = res[j], res[i]
+	})
+
+	iter = iterator.Values(iterator.ForSlice(res))
+	closer.Add(iter)
+
+	return iter, nil
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*sample)(nil)
+)
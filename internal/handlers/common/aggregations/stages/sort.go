@@ -17,6 +17,7 @@ package stages
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
@@ -28,7 +29,9 @@ import (
 
 // sort represents $sort stage.
 type sort struct {
-	fields *types.Document
+	fields       *types.Document
+	memoryLimit  int64
+	allowDiskUse bool
 }
 
 // newSort creates a new $sort stage.
@@ -61,25 +64,84 @@ func newSort(stage *types.Document) (aggregations.Stage, error) {
 //
 // If sort path is invalid, it returns a possibly wrapped types.PathError.
 func (s *sort) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
-	iter, err := common.SortIterator(iter, closer, s.fields)
+	if s.memoryLimit == 0 {
+		res, err := common.SortIterator(iter, closer, s.fields)
+		if err != nil {
+			return nil, processSortStageError(err)
+		}
+
+		return res, nil
+	}
+
+	less, err := common.SortLess(s.fields)
 	if err != nil {
-		// TODO https://github.com/FerretDB/FerretDB/issues/3125
-		var pathErr *types.PathError
-		if errors.As(err, &pathErr) && pathErr.Code() == types.ErrPathElementEmpty {
-			return nil, commonerrors.NewCommandErrorMsgWithArgument(
-				commonerrors.ErrPathContainsEmptyElement,
-				"FieldPath field names may not be empty strings.",
-				"$sort (stage)",
-			)
+		return nil, processSortStageError(err)
+	}
+
+	sorter := &spillSorter{
+		less: less,
+		exceededErr: commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrQueryExceededMemoryLimitNoDiskUseAllowed,
+			fmt.Sprintf(
+				"Sort exceeded memory limit of %d bytes, but did not opt in to external sorting.",
+				s.memoryLimit,
+			),
+			"$sort (stage)",
+		),
+		limit:        s.memoryLimit,
+		allowDiskUse: s.allowDiskUse,
+	}
+
+	for {
+		_, doc, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err == nil {
+			err = sorter.add(doc)
+		}
+
+		if err != nil {
+			sorter.close()
+			return nil, err
 		}
+	}
 
+	res, err := sorter.iterator()
+	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
-	return iter, nil
+	closer.Add(res)
+
+	return res, nil
+}
+
+// setMemoryLimit implements memoryLimiter interface.
+func (s *sort) setMemoryLimit(limit int64, allowDiskUse bool) {
+	s.memoryLimit = limit
+	s.allowDiskUse = allowDiskUse
+}
+
+// processSortStageError takes internal error related to sorting
+// and returns CommandError that can be returned by $sort aggregation stage.
+func processSortStageError(err error) error {
+	// TODO https://github.com/FerretDB/FerretDB/issues/3125
+	var pathErr *types.PathError
+	if errors.As(err, &pathErr) && pathErr.Code() == types.ErrPathElementEmpty {
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrPathContainsEmptyElement,
+			"FieldPath field names may not be empty strings.",
+			"$sort (stage)",
+		)
+	}
+
+	return lazyerrors.Error(err)
 }
 
 // check interfaces
 var (
 	_ aggregations.Stage = (*sort)(nil)
+	_ memoryLimiter      = (*sort)(nil)
 )
@@ -0,0 +1,289 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/FerretDB/FerretDB/internal/bson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// DefaultMemoryLimit is the default maximum size in bytes of documents
+// that blocking stages ($sort and $group) may hold in memory.
+const DefaultMemoryLimit = 100 * 1024 * 1024
+
+// memoryLimiter is implemented by blocking stages that hold documents in memory.
+type memoryLimiter interface {
+	setMemoryLimit(limit int64, allowDiskUse bool)
+}
+
+// spillSorter sorts documents using a bounded amount of memory.
+//
+// Documents are buffered in memory until their total size exceeds the limit.
+// Then, if disk use is allowed, buffered documents are sorted and written to a temporary file;
+// otherwise, exceededErr is returned.
+// Temporary files and remaining buffered documents are merged on iteration.
+//
+// Sorting is stable: documents that are equal according to less are returned in the order they were added.
+type spillSorter struct {
+	less         func(a, b *types.Document) bool
+	exceededErr  error
+	limit        int64 // 0 means no limit
+	allowDiskUse bool
+
+	buf   []*types.Document
+	size  int64
+	files []spillFile
+}
+
+// spillFile represents a temporary file with sorted documents.
+type spillFile struct {
+	f     *os.File
+	count int
+}
+
+// add adds a document to the sorter, spilling buffered documents to disk if needed.
+func (s *spillSorter) add(doc *types.Document) error {
+	s.buf = append(s.buf, doc)
+
+	if s.limit == 0 {
+		return nil
+	}
+
+	size, err := documentSize(doc)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	s.size += size
+
+	if s.size <= s.limit {
+		return nil
+	}
+
+	if !s.allowDiskUse {
+		return s.exceededErr
+	}
+
+	return s.spill()
+}
+
+// spill sorts buffered documents and writes them to a new temporary file.
+func (s *spillSorter) spill() error {
+	s.sort()
+
+	f, err := os.CreateTemp("", "ferretdb-spill-*")
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	// add file first, so it is removed by close even if writing fails
+	s.files = append(s.files, spillFile{f: f, count: len(s.buf)})
+
+	w := bufio.NewWriter(f)
+
+	for _, doc := range s.buf {
+		b, err := marshalDocument(doc)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if _, err = w.Write(b); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	if err = w.Flush(); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	s.buf = nil
+	s.size = 0
+
+	return nil
+}
+
+// sort sorts buffered documents in place.
+func (s *spillSorter) sort() {
+	slices.SortStableFunc(s.buf, func(a, b *types.Document) int {
+		switch {
+		case s.less(a, b):
+			return -1
+		case s.less(b, a):
+			return 1
+		default:
+			return 0
+		}
+	})
+}
+
+// iterator returns an iterator over all added documents in sorted order.
+//
+// The sorter should not be used after that.
+// Returned iterator should be closed to remove temporary files.
+func (s *spillSorter) iterator() (types.DocumentsIterator, error) {
+	s.sort()
+
+	if len(s.files) == 0 {
+		return iterator.Values(iterator.ForSlice(s.buf)), nil
+	}
+
+	// runs are ordered as documents were added: files first, then the buffer
+	runs := make([]func() (*types.Document, error), 0, len(s.files)+1)
+
+	for _, sf := range s.files {
+		if _, err := sf.f.Seek(0, io.SeekStart); err != nil {
+			s.close()
+			return nil, lazyerrors.Error(err)
+		}
+
+		r := bufio.NewReader(sf.f)
+		left := sf.count
+
+		runs = append(runs, func() (*types.Document, error) {
+			if left == 0 {
+				return nil, nil
+			}
+
+			left--
+
+			return readDocument(r)
+		})
+	}
+
+	buf := s.buf
+
+	runs = append(runs, func() (*types.Document, error) {
+		if len(buf) == 0 {
+			return nil, nil
+		}
+
+		doc := buf[0]
+		buf = buf[1:]
+
+		return doc, nil
+	})
+
+	// nil head means that run is exhausted
+	heads := make([]*types.Document, len(runs))
+
+	for i, next := range runs {
+		doc, err := next()
+		if err != nil {
+			s.close()
+			return nil, lazyerrors.Error(err)
+		}
+
+		heads[i] = doc
+	}
+
+	iter := iterator.ForFunc(func() (struct{}, *types.Document, error) {
+		var unused struct{}
+
+		first := -1
+
+		for i, doc := range heads {
+			if doc == nil {
+				continue
+			}
+
+			// strict comparison keeps documents from earlier runs first
+			if first == -1 || s.less(doc, heads[first]) {
+				first = i
+			}
+		}
+
+		if first == -1 {
+			return unused, nil, iterator.ErrIteratorDone
+		}
+
+		res := heads[first]
+
+		next, err := runs[first]()
+		if err != nil {
+			return unused, nil, lazyerrors.Error(err)
+		}
+
+		heads[first] = next
+
+		return unused, res, nil
+	})
+
+	return iterator.WithClose(iter, func() {
+		iter.Close()
+		s.close()
+	}), nil
+}
+
+// close removes temporary files.
+func (s *spillSorter) close() {
+	for _, sf := range s.files {
+		_ = sf.f.Close()
+		_ = os.Remove(sf.f.Name())
+	}
+
+	s.files = nil
+}
+
+// marshalDocument returns BSON representation of the document.
+func marshalDocument(doc *types.Document) ([]byte, error) {
+	d, err := bson.ConvertDocument(doc)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	b, err := d.MarshalBinary()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return b, nil
+}
+
+// documentSize returns the size of BSON representation of the document.
+func documentSize(doc *types.Document) (int64, error) {
+	b, err := marshalDocument(doc)
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	return int64(len(b)), nil
+}
+
+// readDocument reads a single BSON document from the reader.
+func readDocument(r *bufio.Reader) (*types.Document, error) {
+	var d bson.Document
+	if err := d.ReadFrom(r); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	doc, err := types.ConvertDocument(&d)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return doc, nil
+}
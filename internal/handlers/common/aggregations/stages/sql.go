@@ -0,0 +1,79 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// sql represents FerretDB-specific $sql stage.
+//
+// It is a source stage: documents are returned by the backend for the raw SQL query
+// instead of collection documents.
+// That is done by the aggregate command handler; see SQLQuery.
+type sql struct{}
+
+// newSQL creates a new $sql stage.
+func newSQL(stage *types.Document) (aggregations.Stage, error) {
+	if _, err := SQLQuery(stage); err != nil {
+		return nil, err
+	}
+
+	return new(sql), nil
+}
+
+// SQLQuery returns the raw SQL query of the given $sql stage.
+func SQLQuery(stage *types.Document) (string, error) {
+	v := must.NotFail(stage.Get("$sql"))
+
+	q, ok := v.(string)
+	if !ok {
+		return "", commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			fmt.Sprintf("$sql must be a string, not %s", commonparams.AliasFromType(v)),
+			"$sql (stage)",
+		)
+	}
+
+	if q == "" {
+		return "", commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"$sql must be a non-empty string",
+			"$sql (stage)",
+		)
+	}
+
+	return q, nil
+}
+
+// Process implements Stage interface.
+//
+// Input documents are already returned by the SQL query, so they are passed as-is.
+func (s *sql) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	return iter, nil
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*sql)(nil)
+)
@@ -27,20 +27,26 @@ import (
 type newStageFunc func(stage *types.Document) (aggregations.Stage, error)
 
 // Stages maps all supported aggregation Stages.
+// $facet and $lookup are added in facet.go and lookup.go to avoid initialization cycle.
 var Stages = map[string]newStageFunc{
 	// sorted alphabetically
-	"$addFields": newAddFields,
-	"$collStats": newCollStats,
-	"$count":     newCount,
-	"$group":     newGroup,
-	"$limit":     newLimit,
-	"$match":     newMatch,
-	"$project":   newProject,
-	"$set":       newSet,
-	"$skip":      newSkip,
-	"$sort":      newSort,
-	"$unset":     newUnset,
-	"$unwind":    newUnwind,
+	"$addFields":       newAddFields,
+	"$collStats":       newCollStats,
+	"$count":           newCount,
+	"$documentHistory": newDocumentHistory,
+	"$group":           newGroup,
+	"$limit":           newLimit,
+	"$match":           newMatch,
+	"$merge":           newMerge,
+	"$out":             newOut,
+	"$project":         newProject,
+	"$sample":          newSample,
+	"$set":             newSet,
+	"$skip":            newSkip,
+	"$sort":            newSort,
+	"$sql":             newSQL,
+	"$unset":           newUnset,
+	"$unwind":          newUnwind,
 	// please keep sorted alphabetically
 }
 
@@ -53,21 +59,16 @@ var unsupportedStages = map[string]struct{}{
 	"$currentOp":              {},
 	"$densify":                {},
 	"$documents":              {},
-	"$facet":                  {},
 	"$fill":                   {},
 	"$geoNear":                {},
 	"$graphLookup":            {},
 	"$indexStats":             {},
 	"$listLocalSessions":      {},
 	"$listSessions":           {},
-	"$lookup":                 {},
-	"$merge":                  {},
-	"$out":                    {},
 	"$planCacheStats":         {},
 	"$redact":                 {},
 	"$replaceRoot":            {},
 	"$replaceWith":            {},
-	"$sample":                 {},
 	"$search":                 {},
 	"$searchMeta":             {},
 	"$setWindowFields":        {},
@@ -116,3 +117,45 @@ func NewStage(stage *types.Document) (aggregations.Stage, error) {
 
 	panic("not reached")
 }
+
+// collectionQuerier is implemented by stages that read documents of other collections.
+type collectionQuerier interface {
+	setCollectionQuery(query aggregations.CollectionQuery)
+}
+
+// SetCollectionQuery sets the function used by given stages to read documents of other collections.
+// It should be called by the handler before processing stages.
+func SetCollectionQuery(stages []aggregations.Stage, query aggregations.CollectionQuery) {
+	for _, s := range stages {
+		if q, ok := s.(collectionQuerier); ok {
+			q.setCollectionQuery(query)
+		}
+	}
+}
+
+// collectionWriter is implemented by stages that write documents to collections.
+type collectionWriter interface {
+	setCollectionWriter(writer aggregations.CollectionWriter)
+}
+
+// SetCollectionWriter sets the writer used by given stages to write documents to collections.
+// It should be called by the handler before processing stages.
+func SetCollectionWriter(stages []aggregations.Stage, writer aggregations.CollectionWriter) {
+	for _, s := range stages {
+		if w, ok := s.(collectionWriter); ok {
+			w.setCollectionWriter(writer)
+		}
+	}
+}
+
+// SetMemoryLimit sets the maximum size in bytes of documents that given blocking stages may hold in memory.
+// If allowDiskUse is true, stages exceeding the limit write temporary data to disk;
+// otherwise, they return an error. Zero limit disables the check.
+// It should be called by the handler before processing stages.
+func SetMemoryLimit(stages []aggregations.Stage, limit int64, allowDiskUse bool) {
+	for _, s := range stages {
+		if l, ok := s.(memoryLimiter); ok {
+			l.setMemoryLimit(limit, allowDiskUse)
+		}
+	}
+}
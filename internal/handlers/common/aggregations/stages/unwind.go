@@ -18,10 +18,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
-	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonpath"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/iterator"
@@ -30,8 +31,18 @@ import (
 )
 
 // unwind represents $unwind stage.
+//
+//	{ $unwind: <path> }
+//	{ $unwind: {
+//		path: <path>,
+//		includeArrayIndex: <string>,
+//		preserveNullAndEmptyArrays: <boolean>,
+//	}}
 type unwind struct {
-	field *aggregations.Expression
+	field                      *aggregations.Expression
+	path                       types.Path
+	includeArrayIndex          *types.Path // nil if not set
+	preserveNullAndEmptyArrays bool
 }
 
 // newUnwind creates a new $unwind stage.
@@ -41,127 +52,297 @@ func newUnwind(stage *types.Document) (aggregations.Stage, error) {
 		return nil, err
 	}
 
-	var expr *aggregations.Expression
+	var u unwind
 
 	switch field := field.(type) {
 	case *types.Document:
-		return nil, common.Unimplemented(stage, "$unwind")
+		if err = u.setOptions(field); err != nil {
+			return nil, err
+		}
 	case string:
-		if field == "" {
-			return nil, commonerrors.NewCommandErrorMsgWithArgument(
-				commonerrors.ErrStageUnwindNoPath,
-				"no path specified to $unwind stage",
-				"$unwind (stage)",
-			)
+		if err = u.setPath(field); err != nil {
+			return nil, err
 		}
+	default:
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageUnwindWrongType,
+			fmt.Sprintf(
+				"expected either a string or an object as specification for $unwind stage, got %s",
+				types.FormatAnyValue(field),
+			),
+			"$unwind (Stage)",
+		)
+	}
 
-		// For $unwind to deconstruct an array from dot notation, array must be at the suffix.
-		// It returns empty result if array is found at other parts of dot notation,
-		// so it does not return value by index of array nor values for given key in array's document.
-		expr, err = aggregations.NewExpression(field, &commonpath.FindValuesOpts{
-			FindArrayIndex:     false,
-			FindArrayDocuments: false,
-		})
+	return &u, nil
+}
+
+// setOptions validates and sets $unwind options given as a document.
+func (u *unwind) setOptions(options *types.Document) error {
+	iter := options.Iterator()
+	defer iter.Close()
+
+	var pathSet bool
+
+	for {
+		k, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
 
 		if err != nil {
-			var exprErr *aggregations.ExpressionError
-			if !errors.As(err, &exprErr) {
-				return nil, lazyerrors.Error(err)
+			return lazyerrors.Error(err)
+		}
+
+		switch k {
+		case "path":
+			path, ok := v.(string)
+			if !ok {
+				return commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrStageUnwindPathNotString,
+					fmt.Sprintf("expected a string as the path for $unwind stage, got %s", commonparams.AliasFromType(v)),
+					"$unwind (stage)",
+				)
+			}
+
+			if err = u.setPath(path); err != nil {
+				return err
 			}
 
-			switch exprErr.Code() {
-			case aggregations.ErrNotExpression:
-				return nil, commonerrors.NewCommandErrorMsgWithArgument(
-					commonerrors.ErrStageUnwindNoPrefix,
-					fmt.Sprintf("path option to $unwind stage should be prefixed with a '$': %v", types.FormatAnyValue(field)),
+			pathSet = true
+
+		case "includeArrayIndex":
+			index, ok := v.(string)
+			if !ok || index == "" {
+				return commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrStageUnwindIndexNotString,
+					fmt.Sprintf(
+						"expected a non-empty string for the includeArrayIndex option to $unwind stage, got %s",
+						commonparams.AliasFromType(v),
+					),
 					"$unwind (stage)",
 				)
-			case aggregations.ErrEmptyFieldPath:
-				return nil, commonerrors.NewCommandErrorMsgWithArgument(
-					commonerrors.ErrEmptyFieldPath,
-					"Expression cannot be constructed with empty string",
+			}
+
+			if strings.HasPrefix(index, "$") {
+				return commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrStageUnwindIndexPrefix,
+					fmt.Sprintf("includeArrayIndex option to $unwind stage should not be prefixed with a '$': %s", index),
 					"$unwind (stage)",
 				)
-			case aggregations.ErrEmptyVariable, aggregations.ErrInvalidExpression, aggregations.ErrUndefinedVariable:
-				return nil, commonerrors.NewCommandErrorMsgWithArgument(
-					commonerrors.ErrFieldPathInvalidName,
-					"Expression field names may not start with '$'. Consider using $getField or $setField",
+			}
+
+			path, err := types.NewPathFromString(index)
+			if err != nil {
+				return commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrPathContainsEmptyElement,
+					"FieldPath field names may not be empty strings.",
+					"$unwind (stage)",
+				)
+			}
+
+			u.includeArrayIndex = &path
+
+		case "preserveNullAndEmptyArrays":
+			preserve, ok := v.(bool)
+			if !ok {
+				return commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrStageUnwindPreserveNotBool,
+					fmt.Sprintf(
+						"expected a boolean for the preserveNullAndEmptyArrays option to $unwind stage, got %s",
+						commonparams.AliasFromType(v),
+					),
 					"$unwind (stage)",
 				)
-			default:
-				return nil, lazyerrors.Error(err)
 			}
+
+			u.preserveNullAndEmptyArrays = preserve
+
+		default:
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrStageUnwindUnknownOption,
+				fmt.Sprintf("unrecognized option to $unwind stage: %s", k),
+				"$unwind (stage)",
+			)
 		}
-	default:
-		return nil, commonerrors.NewCommandErrorMsgWithArgument(
-			commonerrors.ErrStageUnwindWrongType,
-			fmt.Sprintf(
-				"expected either a string or an object as specification for $unwind stage, got %s",
-				types.FormatAnyValue(field),
-			),
-			"$unwind (Stage)",
+	}
+
+	if !pathSet {
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageUnwindNoPath,
+			"no path specified to $unwind stage",
+			"$unwind (stage)",
 		)
 	}
 
-	return &unwind{
-		field: expr,
-	}, nil
+	return nil
 }
 
-// Process implements Stage interface.
-func (u *unwind) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
-	// TODO https://github.com/FerretDB/FerretDB/issues/2490
-	docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](iter))
-	if err != nil {
-		return nil, lazyerrors.Error(err)
+// setPath validates and sets the path of array field to unwind.
+func (u *unwind) setPath(field string) error {
+	if field == "" {
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageUnwindNoPath,
+			"no path specified to $unwind stage",
+			"$unwind (stage)",
+		)
 	}
 
-	var out []*types.Document
+	// For $unwind to deconstruct an array from dot notation, array must be at the suffix.
+	// It returns empty result if array is found at other parts of dot notation,
+	// so it does not return value by index of array nor values for given key in array's document.
+	expr, err := aggregations.NewExpression(field, &commonpath.FindValuesOpts{
+		FindArrayIndex:     false,
+		FindArrayDocuments: false,
+	})
+	if err != nil {
+		var exprErr *aggregations.ExpressionError
+		if !errors.As(err, &exprErr) {
+			return lazyerrors.Error(err)
+		}
 
-	if u.field == nil {
-		return nil, nil
+		switch exprErr.Code() {
+		case aggregations.ErrNotExpression:
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrStageUnwindNoPrefix,
+				fmt.Sprintf("path option to $unwind stage should be prefixed with a '$': %v", types.FormatAnyValue(field)),
+				"$unwind (stage)",
+			)
+		case aggregations.ErrEmptyFieldPath:
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrEmptyFieldPath,
+				"Expression cannot be constructed with empty string",
+				"$unwind (stage)",
+			)
+		case aggregations.ErrEmptyVariable, aggregations.ErrInvalidExpression, aggregations.ErrUndefinedVariable:
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrFieldPathInvalidName,
+				"Expression field names may not start with '$'. Consider using $getField or $setField",
+				"$unwind (stage)",
+			)
+		default:
+			return lazyerrors.Error(err)
+		}
 	}
 
-	key := u.field.GetExpressionSuffix()
+	// expression is valid, so is the path
+	u.field = expr
+	u.path = must.NotFail(types.NewPathFromString(strings.TrimPrefix(field, "$")))
 
-	for _, doc := range docs {
-		d, err := u.field.Evaluate(doc)
-		if err != nil {
-			// Ignore non-existent values
-			continue
+	return nil
+}
+
+// Process implements Stage interface.
+//
+// Documents are unwound one by one as they are read from the iterator.
+func (u *unwind) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	var pending []*types.Document
+
+	res := iterator.ForFunc(func() (struct{}, *types.Document, error) {
+		var unused struct{}
+
+		for len(pending) == 0 {
+			_, doc, err := iter.Next()
+			if err != nil {
+				if errors.Is(err, iterator.ErrIteratorDone) {
+					return unused, nil, err
+				}
+
+				return unused, nil, lazyerrors.Error(err)
+			}
+
+			if pending, err = u.unwindDocument(doc); err != nil {
+				return unused, nil, lazyerrors.Error(err)
+			}
 		}
 
-		switch d := d.(type) {
-		case *types.Array:
-			iter := d.Iterator()
-			defer iter.Close()
+		doc := pending[0]
+		pending = pending[1:]
 
-			id := must.NotFail(doc.Get("_id"))
+		return unused, doc, nil
+	})
+	closer.Add(res)
 
-			for {
-				_, v, err := iter.Next()
-				if err != nil {
-					if errors.Is(err, iterator.ErrIteratorDone) {
-						break
-					}
+	return res, nil
+}
 
-					return nil, err
-				}
+// unwindDocument returns a document for each element of the array field of the given document.
+func (u *unwind) unwindDocument(doc *types.Document) ([]*types.Document, error) {
+	v, err := u.field.Evaluate(doc)
+	if err != nil {
+		// non-existent field
+		return u.preserve(doc, false)
+	}
+
+	switch v := v.(type) {
+	case *types.Array:
+		if v.Len() == 0 {
+			return u.preserve(doc, true)
+		}
+
+		res := make([]*types.Document, v.Len())
 
-				newDoc := must.NotFail(types.NewDocument("_id", id, key, v))
-				out = append(out, newDoc)
+		for i := 0; i < v.Len(); i++ {
+			d := doc.DeepCopy()
+
+			if err = d.SetByPath(u.path, must.NotFail(v.Get(i))); err != nil {
+				return nil, lazyerrors.Error(err)
 			}
-		case types.NullType:
-			// Ignore Nulls
-		default:
-			out = append(out, doc)
+
+			if u.includeArrayIndex != nil {
+				if err = d.SetByPath(*u.includeArrayIndex, int64(i)); err != nil {
+					return nil, lazyerrors.Error(err)
+				}
+			}
+
+			res[i] = d
+		}
+
+		return res, nil
+
+	case types.NullType:
+		return u.preserve(doc, false)
+
+	default:
+		// non-array value is treated as a single element array
+		if u.includeArrayIndex == nil {
+			return []*types.Document{doc}, nil
 		}
+
+		d := doc.DeepCopy()
+		if err = d.SetByPath(*u.includeArrayIndex, types.Null); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		return []*types.Document{d}, nil
+	}
+}
+
+// preserve returns the given document with null, missing or empty array field
+// if preserveNullAndEmptyArrays option is set, and nothing otherwise.
+// Empty array field is removed.
+func (u *unwind) preserve(doc *types.Document, emptyArray bool) ([]*types.Document, error) {
+	if !u.preserveNullAndEmptyArrays {
+		return nil, nil
 	}
 
-	iter = iterator.Values(iterator.ForSlice(out))
-	closer.Add(iter)
+	if !emptyArray && u.includeArrayIndex == nil {
+		return []*types.Document{doc}, nil
+	}
+
+	d := doc.DeepCopy()
+
+	if emptyArray {
+		d.RemoveByPath(u.path)
+	}
+
+	if u.includeArrayIndex != nil {
+		if err := d.SetByPath(*u.includeArrayIndex, types.Null); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
 
-	return iter, nil
+	return []*types.Document{d}, nil
 }
 
 // check interfaces
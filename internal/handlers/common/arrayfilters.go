@@ -0,0 +1,295 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// arrayFilterIdentifierRe matches valid identifiers of `$[<identifier>]` positional operator.
+var arrayFilterIdentifierRe = regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`)
+
+// ArrayFilters represents validated `arrayFilters` option of update and findAndModify commands.
+// It maps identifiers of `$[<identifier>]` filtered positional operators to their filters.
+type ArrayFilters map[string]*types.Document
+
+// NewArrayFilters validates `arrayFilters` option against the given update document and returns them.
+// Every filter must be used by the update, and every used identifier must have a filter.
+func NewArrayFilters(command string, arrayFilters *types.Array, update *types.Document) (ArrayFilters, error) {
+	res := ArrayFilters{}
+
+	if arrayFilters != nil {
+		for i := 0; i < arrayFilters.Len(); i++ {
+			v := must.NotFail(arrayFilters.Get(i))
+
+			filter, ok := v.(*types.Document)
+			if !ok {
+				return nil, newUpdateError(
+					commonerrors.ErrTypeMismatch,
+					fmt.Sprintf("Each array filter must be an object, found %s", commonparams.AliasFromType(v)),
+					command,
+				)
+			}
+
+			identifier, err := arrayFilterIdentifier(command, filter)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok = res[identifier]; ok {
+				return nil, newUpdateError(
+					commonerrors.ErrFailedToParse,
+					fmt.Sprintf("Found multiple array filters with the same top-level field name %s", identifier),
+					command,
+				)
+			}
+
+			res[identifier] = filter
+		}
+	}
+
+	used := map[string]struct{}{}
+
+	for _, op := range update.Keys() {
+		opDoc, ok := must.NotFail(update.Get(op)).(*types.Document)
+		if !strings.HasPrefix(op, "$") || !ok {
+			continue
+		}
+
+		for _, key := range opDoc.Keys() {
+			for _, part := range strings.Split(key, ".") {
+				identifier, ok := positionalIdentifier(part)
+				if !ok || identifier == "" {
+					continue
+				}
+
+				if _, ok = res[identifier]; !ok {
+					return nil, newUpdateError(
+						commonerrors.ErrBadValue,
+						fmt.Sprintf("No array filter found for identifier '%s' in path '%s'", identifier, key),
+						command,
+					)
+				}
+
+				used[identifier] = struct{}{}
+			}
+		}
+	}
+
+	identifiers := maps.Keys(res)
+	slices.Sort(identifiers)
+
+	for _, identifier := range identifiers {
+		if _, ok := used[identifier]; !ok {
+			return nil, newUpdateError(
+				commonerrors.ErrFailedToParse,
+				fmt.Sprintf(
+					"The array filter for identifier '%s' was not used in the update %s",
+					identifier, types.FormatAnyValue(update),
+				),
+				command,
+			)
+		}
+	}
+
+	return res, nil
+}
+
+// arrayFilterIdentifier returns the identifier used by all top-level fields of the array filter.
+func arrayFilterIdentifier(command string, filter *types.Document) (string, error) {
+	var identifier string
+
+	for _, key := range filter.Keys() {
+		if strings.HasPrefix(key, "$") {
+			return "", newUpdateError(
+				commonerrors.ErrFailedToParse,
+				"Cannot use an expression without a top-level field name in arrayFilters",
+				command,
+			)
+		}
+
+		id, _, _ := strings.Cut(key, ".")
+
+		if !arrayFilterIdentifierRe.MatchString(id) {
+			return "", newUpdateError(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf(
+					"Error parsing array filter :: caused by :: The top-level field name must be "+
+						"an alphanumeric string beginning with a lowercase letter, found '%s'",
+					id,
+				),
+				command,
+			)
+		}
+
+		if identifier != "" && identifier != id {
+			return "", newUpdateError(
+				commonerrors.ErrFailedToParse,
+				fmt.Sprintf(
+					"Error parsing array filter :: caused by :: Expected a single top-level field name, found '%s' and '%s'",
+					identifier, id,
+				),
+				command,
+			)
+		}
+
+		identifier = id
+	}
+
+	if identifier == "" {
+		return "", newUpdateError(
+			commonerrors.ErrFailedToParse,
+			"Cannot use an expression without a top-level field name in arrayFilters",
+			command,
+		)
+	}
+
+	return identifier, nil
+}
+
+// positionalIdentifier returns the identifier of `$[<identifier>]` path part,
+// or empty string for `$[]` all positional operator.
+// It returns false if the path part is not a positional operator.
+func positionalIdentifier(part string) (string, bool) {
+	if !strings.HasPrefix(part, "$[") || !strings.HasSuffix(part, "]") {
+		return "", false
+	}
+
+	return part[2 : len(part)-1], true
+}
+
+// expand returns the update document where update paths with `$[]` and `$[<identifier>]` positional operators
+// are replaced with paths to all or matching array elements of the document.
+// The update document is returned as is if it has no such paths.
+func (af ArrayFilters) expand(command string, doc, update *types.Document) (*types.Document, error) {
+	res := types.MakeDocument(update.Len())
+
+	var expanded bool
+
+	for _, op := range update.Keys() {
+		v := must.NotFail(update.Get(op))
+
+		opDoc, ok := v.(*types.Document)
+		if !strings.HasPrefix(op, "$") || !ok {
+			res.Set(op, v)
+			continue
+		}
+
+		expandedDoc := types.MakeDocument(opDoc.Len())
+
+		for _, key := range opDoc.Keys() {
+			value := must.NotFail(opDoc.Get(key))
+
+			if !strings.Contains(key, "$[") {
+				expandedDoc.Set(key, value)
+				continue
+			}
+
+			expanded = true
+
+			paths, err := af.expandPath(command, doc, strings.Split(key, "."))
+			if err != nil {
+				return nil, err
+			}
+
+			for _, path := range paths {
+				expandedDoc.Set(path, value)
+			}
+		}
+
+		res.Set(op, expandedDoc)
+	}
+
+	if !expanded {
+		return update, nil
+	}
+
+	return res, nil
+}
+
+// expandPath returns paths with the first positional operator of the given path parts
+// replaced with indexes of all or matching array elements, recursively.
+func (af ArrayFilters) expandPath(command string, doc *types.Document, parts []string) ([]string, error) {
+	for i, part := range parts {
+		identifier, ok := positionalIdentifier(part)
+		if !ok {
+			continue
+		}
+
+		arrayPath := strings.Join(parts[:i], ".")
+
+		v, err := doc.GetByPath(types.NewStaticPath(parts[:i]...))
+		if err != nil {
+			return nil, newUpdateError(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf("The path '%s' must exist in the document in order to apply array updates.", arrayPath),
+				command,
+			)
+		}
+
+		arr, ok := v.(*types.Array)
+		if !ok {
+			return nil, newUpdateError(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf("Cannot apply array updates to non-array element %s: %s", parts[i-1], types.FormatAnyValue(v)),
+				command,
+			)
+		}
+
+		var res []string
+
+		for j := 0; j < arr.Len(); j++ {
+			if identifier != "" {
+				elem := must.NotFail(types.NewDocument(identifier, must.NotFail(arr.Get(j))))
+
+				matches, err := FilterDocument(elem, af[identifier])
+				if err != nil {
+					return nil, err
+				}
+
+				if !matches {
+					continue
+				}
+			}
+
+			elemParts := make([]string, 0, len(parts))
+			elemParts = append(elemParts, parts[:i]...)
+			elemParts = append(elemParts, strconv.Itoa(j))
+			elemParts = append(elemParts, parts[i+1:]...)
+
+			paths, err := af.expandPath(command, doc, elemParts)
+			if err != nil {
+				return nil, err
+			}
+
+			res = append(res, paths...)
+		}
+
+		return res, nil
+	}
+
+	return []string{strings.Join(parts, ".")}, nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/util/state"
+)
+
+// CheckCapability returns commonerrors.ErrNotImplemented if the backend does not have
+// the given capability (see backends.Capabilities) required by the feature.
+//
+// Argument is used for the error's argument; it is typically a field or operator name.
+func CheckCapability(s *state.State, capability, feature, argument string) error {
+	if _, ok := s.BackendCapabilities[capability]; ok {
+		return nil
+	}
+
+	backend := "the backend"
+	if s.BackendName != "" {
+		backend = s.BackendName
+	}
+
+	msg := fmt.Sprintf(
+		"%s requires %q capability that is not available in %s; "+
+			"check ferretdbCapabilities in buildInfo output",
+		feature, capability, backend,
+	)
+
+	return commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrNotImplemented, msg, argument)
+}
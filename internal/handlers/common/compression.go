@@ -0,0 +1,107 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"slices"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// supportedCompressors contains names of OP_COMPRESSED compressors that could be negotiated.
+var supportedCompressors = []string{
+	wire.CompressorSnappy.String(),
+	wire.CompressorZlib.String(),
+	wire.CompressorZstd.String(),
+}
+
+// NegotiateCompression handles the compression field of hello and isMaster commands.
+//
+// Compressors requested by the client and supported by FerretDB are stored in the connection info
+// and returned in the client's order of preference.
+// Nil is returned if the client did not request compression.
+func NegotiateCompression(ctx context.Context, doc *types.Document) (*types.Array, error) {
+	v, _ := doc.Get("compression")
+	if v == nil {
+		return nil, nil
+	}
+
+	requested, ok := v.(*types.Array)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			"'compression' is the wrong type (expected array)",
+			"compression",
+		)
+	}
+
+	res := types.MakeArray(requested.Len())
+	var names []string
+
+	iter := requested.Iterator()
+	defer iter.Close()
+
+	for {
+		_, v, err := iter.Next()
+		if err != nil {
+			if err == iterator.ErrIteratorDone {
+				break
+			}
+
+			return nil, lazyerrors.Error(err)
+		}
+
+		name, ok := v.(string)
+		if !ok {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrTypeMismatch,
+				"'compression' must contain only strings",
+				"compression",
+			)
+		}
+
+		// unknown compressors are ignored, like MongoDB does
+		if !slices.Contains(supportedCompressors, name) || slices.Contains(names, name) {
+			continue
+		}
+
+		names = append(names, name)
+		res.Append(name)
+	}
+
+	conninfo.Get(ctx).SetCompressors(names)
+
+	return res, nil
+}
+
+// SetCompression adds the negotiated compressors to the handshake reply document, if any.
+func SetCompression(doc *types.Document, compression *types.Array) {
+	if compression == nil || compression.Len() == 0 {
+		return
+	}
+
+	// keep ok the last field
+	ok := must.NotFail(doc.Get("ok"))
+	doc.Remove("ok")
+	doc.Set("compression", compression)
+	doc.Set("ok", ok)
+}
@@ -30,6 +30,8 @@ type CountParams struct {
 	Skip  int64 `ferretdb:"skip,opt,positiveNumber"`
 	Limit int64 `ferretdb:"limit,opt,positiveNumber"`
 
+	MaxTimeMS int64 `ferretdb:"maxTimeMS,opt,wholePositiveNumber"`
+
 	Collation *types.Document `ferretdb:"collation,unimplemented"`
 
 	Fields any `ferretdb:"fields,ignored"` // legacy MongoDB shell adds it, but it is never actually used
@@ -32,7 +32,7 @@ type DeleteParams struct {
 	Comment string   `ferretdb:"comment,opt"`
 	Ordered bool     `ferretdb:"ordered,opt"`
 
-	Let *types.Document `ferretdb:"let,unimplemented"`
+	Let *types.Document `ferretdb:"let,opt"`
 
 	WriteConcern *types.Document `ferretdb:"writeConcern,ignored"`
 	LSID         any             `ferretdb:"lsid,ignored"`
@@ -40,6 +40,8 @@ type DistinctParams struct {
 
 	Query any `ferretdb:"query,opt"`
 
+	MaxTimeMS int64 `ferretdb:"maxTimeMS,opt,wholePositiveNumber"`
+
 	Collation *types.Document `ferretdb:"collation,unimplemented"`
 
 	ReadConcern *types.Document `ferretdb:"readConcern,ignored"`
@@ -15,6 +15,8 @@
 package common
 
 import (
+	"fmt"
+
 	"go.uber.org/zap"
 
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
@@ -41,7 +43,14 @@ type ExplainParams struct {
 	Aggregate  bool            `ferretdb:"-"`
 	Command    *types.Document `ferretdb:"-"`
 
-	Verbosity string `ferretdb:"verbosity,ignored"`
+	Verbosity string `ferretdb:"verbosity,opt"`
+
+	Hint any `ferretdb:"-"`
+}
+
+// Analyze returns true if the verbosity requires the query to be executed.
+func (p *ExplainParams) Analyze() bool {
+	return p.Verbosity != "queryPlanner"
 }
 
 // GetExplainParams returns the parameters for the explain command.
@@ -54,7 +63,33 @@ func GetExplainParams(document *types.Document, l *zap.Logger) (*ExplainParams,
 		return nil, lazyerrors.Error(err)
 	}
 
-	Ignored(document, l, "verbosity")
+	// that's the default of the command, but not of the shell
+	verbosity := "allPlansExecution"
+
+	if v, _ := document.Get("verbosity"); v != nil {
+		s, ok := v.(string)
+		if !ok {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrTypeMismatch,
+				fmt.Sprintf(
+					"BSON field 'explain.verbosity' is the wrong type '%s', expected type 'string'",
+					commonparams.AliasFromType(v),
+				),
+				document.Command(),
+			)
+		}
+
+		switch s {
+		case "queryPlanner", "executionStats", "allPlansExecution":
+			verbosity = s
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				"verbosity string must be one of {'queryPlanner', 'executionStats', 'allPlansExecution'}",
+				document.Command(),
+			)
+		}
+	}
 
 	var cmd *types.Document
 
@@ -79,7 +114,13 @@ func GetExplainParams(document *types.Document, l *zap.Logger) (*ExplainParams,
 		return nil, lazyerrors.Error(err)
 	}
 
-	filter, err = GetOptionalParam(explain, "filter", filter)
+	// count command uses `query` instead of `filter`
+	filterField := "filter"
+	if cmd.Command() == "count" {
+		filterField = "query"
+	}
+
+	filter, err = GetOptionalParam(explain, filterField, filter)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
@@ -107,6 +148,8 @@ func GetExplainParams(document *types.Document, l *zap.Logger) (*ExplainParams,
 		return nil, err
 	}
 
+	hint, _ := explain.Get("hint")
+
 	var stagesDocs []any
 
 	if cmd.Command() == "aggregate" {
@@ -143,5 +186,7 @@ func GetExplainParams(document *types.Document, l *zap.Logger) (*ExplainParams,
 		StagesDocs: stagesDocs,
 		Aggregate:  cmd.Command() == "aggregate",
 		Command:    cmd,
+		Verbosity:  verbosity,
+		Hint:       hint,
 	}, nil
 }
@@ -18,6 +18,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"math/rand"
 	"slices"
 	"strings"
 	"time"
@@ -139,6 +140,10 @@ func filterDocumentPair(doc *types.Document, filterKey string, filterValue any)
 			docs = append(docs, types.MakeDocument(0))
 		}
 
+		if len(docs) > 1 {
+			return filterFieldExprValues(docs, filterKey, filterSuffix, filterValue)
+		}
+
 		for _, doc := range docs {
 			// {field: {expr}} or {field: {document}}
 			ok, err := filterFieldExpr(doc, filterKey, filterSuffix, filterValue)
@@ -184,6 +189,94 @@ func filterDocumentPair(doc *types.Document, filterKey string, filterValue any)
 	return false, nil
 }
 
+// filterFieldExprValues handles {field: {expr}} or {field: {document}} filter
+// for multiple values of the dot notation path, one per document.
+//
+// Values are matched like array elements:
+// each operator may be satisfied by a different value,
+// and negated operators should be satisfied by all of them.
+func filterFieldExprValues(docs []*types.Document, filterKey, filterSuffix string, expr *types.Document) (bool, error) {
+	matchAny := func(cond *types.Document) (bool, error) {
+		for _, doc := range docs {
+			ok, err := filterFieldExpr(doc, filterKey, filterSuffix, cond)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+
+		return false, nil
+	}
+
+	matchAll := func(cond *types.Document) (bool, error) {
+		for _, doc := range docs {
+			ok, err := filterFieldExpr(doc, filterKey, filterSuffix, cond)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+
+		return true, nil
+	}
+
+	// documents without operators are compared as a whole
+	if expr.Len() == 0 || !strings.HasPrefix(expr.Keys()[0], "$") {
+		return matchAny(expr)
+	}
+
+	for _, exprKey := range expr.Keys() {
+		exprValue := must.NotFail(expr.Get(exprKey))
+		cond := must.NotFail(types.NewDocument(exprKey, exprValue))
+
+		var ok bool
+		var err error
+
+		switch exprKey {
+		case "$options":
+			// handled by $regex
+			continue
+
+		case "$regex":
+			if options, _ := expr.Get("$options"); options != nil {
+				cond.Set("$options", options)
+			}
+
+			ok, err = matchAny(cond)
+
+		case "$ne", "$nin", "$not":
+			ok, err = matchAll(cond)
+
+		case "$all":
+			query, isArray := exprValue.(*types.Array)
+			if !isArray || query.Len() == 0 {
+				ok, err = matchAny(cond)
+				break
+			}
+
+			// check that $elemMatch is not mixed with other values
+			if _, err = allElemMatches(query); err != nil {
+				return false, err
+			}
+
+			// each value of $all may be matched by a different path value
+			ok = true
+
+			for i := 0; i < query.Len() && ok; i++ {
+				item := must.NotFail(types.NewArray(must.NotFail(query.Get(i))))
+				ok, err = matchAny(must.NotFail(types.NewDocument(exprKey, item)))
+			}
+
+		default:
+			ok, err = matchAny(cond)
+		}
+
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
 // filterOperator handles a top-level operator filter {$operator: filterValue}.
 func filterOperator(doc *types.Document, operator string, filterValue any) (bool, error) {
 	switch operator {
@@ -324,6 +417,14 @@ func filterOperator(doc *types.Document, operator string, filterValue any) (bool
 
 	case "$expr":
 		return filterExprOperator(doc, must.NotFail(types.NewDocument(operator, filterValue)))
+
+	case "$sampleRate":
+		// {$sampleRate: rate}
+		return filterSampleRateOperator(filterValue)
+
+	case "$jsonSchema", "$text", "$where":
+		return false, newUnsupportedOperatorError(operator)
+
 	default:
 		msg := fmt.Sprintf(
 			`unknown top level operator: %s. `+
@@ -335,12 +436,43 @@ func filterOperator(doc *types.Document, operator string, filterValue any) (bool
 	}
 }
 
+// filterSampleRateOperator handles {$sampleRate: rate} filter.
+// It matches each document with the given probability, independently of other documents.
+func filterSampleRateOperator(rate any) (bool, error) {
+	var r float64
+
+	switch rate := rate.(type) {
+	case float64:
+		r = rate
+	case int32:
+		r = float64(rate)
+	case int64:
+		r = float64(rate)
+	default:
+		return false, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"argument to $sampleRate must be a numeric type",
+			"$sampleRate",
+		)
+	}
+
+	if r < 0 || r > 1 || math.IsNaN(r) {
+		return false, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"numeric argument to $sampleRate must be in [0, 1]",
+			"$sampleRate",
+		)
+	}
+
+	return rand.Float64() < r, nil
+}
+
 // filterExprOperator uses $expr operator to allow usage of aggregation expression.
 // It returns boolean indicating filter has matched.
 //
 // $expr is primary used by operators such as $gt and $cond which return boolean result.
 // However, if non-boolean result is returned from processing aggregation expression,
-// it returns false for null or zero value and true for all other values.
+// it returns false for null or zero value and true for all other values (see [operators.IsTrue]).
 func filterExprOperator(doc, filter *types.Document) (bool, error) {
 	// TODO https://github.com/FerretDB/FerretDB/issues/3170
 	op, err := operators.NewExpr(filter, "$expr")
@@ -353,18 +485,7 @@ func filterExprOperator(doc, filter *types.Document) (bool, error) {
 		return false, lazyerrors.Error(err)
 	}
 
-	switch v := v.(type) {
-	case *types.Document, *types.Array, string, types.Binary, types.ObjectID, time.Time, types.Regex, types.Timestamp:
-		return true, nil
-	case float64, int32, int64:
-		return types.Compare(v, int32(0)) != types.Equal, nil
-	case bool:
-		return v, nil
-	case types.NullType:
-		return false, nil
-	default:
-		panic(fmt.Sprintf("common.filterExprOperator: unexpected type %[1]T (%#[1]v)", v))
-	}
+	return operators.IsTrue(v), nil
 }
 
 // filterFieldExpr handles {field: {expr}} or {field: {document}} filter.
@@ -703,35 +824,14 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 
 		case "$all":
 			// {field: {$all: [value, another_value, ...]}}
-			res, err := filterFieldExprAll(fieldValue, exprValue)
+			res, err := filterFieldExprAll(doc, filterKey, filterSuffix, fieldValue, exprValue)
 			if !res || err != nil {
 				return false, err
 			}
 
-		case "$bitsAllClear":
+		case "$bitsAllClear", "$bitsAllSet", "$bitsAnyClear", "$bitsAnySet":
 			// {field: {$bitsAllClear: value}}
-			res, err := filterFieldExprBitsAllClear(fieldValue, exprValue)
-			if !res || err != nil {
-				return false, err
-			}
-
-		case "$bitsAllSet":
-			// {field: {$bitsAllSet: value}}
-			res, err := filterFieldExprBitsAllSet(fieldValue, exprValue)
-			if !res || err != nil {
-				return false, err
-			}
-
-		case "$bitsAnyClear":
-			// {field: {$bitsAnyClear: value}}
-			res, err := filterFieldExprBitsAnyClear(fieldValue, exprValue)
-			if !res || err != nil {
-				return false, err
-			}
-
-		case "$bitsAnySet":
-			// {field: {$bitsAnySet: value}}
-			res, err := filterFieldExprBitsAnySet(fieldValue, exprValue)
+			res, err := filterFieldExprBits(exprKey, fieldValue, exprValue)
 			if !res || err != nil {
 				return false, err
 			}
@@ -757,6 +857,9 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 				return false, err
 			}
 
+		case "$geoIntersects", "$geoWithin", "$near", "$nearSphere":
+			return false, newUnsupportedOperatorError(exprKey)
+
 		default:
 			return false, commonerrors.NewCommandErrorMsgWithArgument(
 				commonerrors.ErrBadValue,
@@ -769,27 +872,31 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 	return true, nil
 }
 
+// newUnsupportedOperatorError returns NotImplemented error for the valid query operator
+// that is not supported yet.
+//
+// The operator is used as the error argument, so it is recorded in the unsupported-feature telemetry
+// instead of the generic "$operator".
+func newUnsupportedOperatorError(operator string) error {
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrNotImplemented,
+		fmt.Sprintf("query operator %s is not implemented yet", operator),
+		operator,
+	)
+}
+
 // filterFieldRegex handles {field: /regex/} filter. Provides regular expression capabilities
 // for pattern matching strings in queries, even if the strings are in an array.
 func filterFieldRegex(fieldValue any, regex types.Regex) (bool, error) {
-	for _, option := range regex.Options {
-		if !slices.Contains([]rune{'i', 'm', 's', 'x'}, option) {
-			return false, commonerrors.NewCommandErrorMsgWithArgument(
-				commonerrors.ErrBadRegexOption,
-				fmt.Sprintf(" invalid flag in regex options: %c", option),
-				"$options",
-			)
-		}
-	}
-
-	re, err := regex.Compile()
-	if err != nil && err == types.ErrOptionNotImplemented {
+	if option, ok := regex.UnknownOption(); ok {
 		return false, commonerrors.NewCommandErrorMsgWithArgument(
-			commonerrors.ErrNotImplemented,
-			`option 'x' not implemented`,
+			commonerrors.ErrBadRegexOption,
+			fmt.Sprintf(" invalid flag in regex options: %c", option),
 			"$options",
 		)
 	}
+
+	re, err := regex.Compile()
 	if err != nil {
 		return false, commonerrors.NewCommandErrorMsgWithArgument(
 			commonerrors.ErrRegexMissingParen,
@@ -922,7 +1029,9 @@ func filterFieldExprSize(fieldValue any, sizeValue any) (bool, error) {
 // filterFieldExprAll handles {field: {$all: [value, another_value, ...]}} filter.
 // The main purpose of $all is to filter arrays.
 // It is possible to filter non-arrays: {field: {$all: [value]}}, but such statement is equivalent to {field: value}.
-func filterFieldExprAll(fieldValue any, allValue any) (bool, error) {
+//
+// If all values are {$elemMatch: expr} documents, each of them should match some element of the array.
+func filterFieldExprAll(doc *types.Document, filterKey, filterSuffix string, fieldValue, allValue any) (bool, error) {
 	query, ok := allValue.(*types.Array)
 	if !ok {
 		return false, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrBadValue, "$all needs an array", "$all")
@@ -932,6 +1041,22 @@ func filterFieldExprAll(fieldValue any, allValue any) (bool, error) {
 		return false, nil
 	}
 
+	elemMatches, err := allElemMatches(query)
+	if err != nil {
+		return false, err
+	}
+
+	if elemMatches != nil {
+		for _, elemMatch := range elemMatches {
+			res, err := filterFieldExprElemMatch(doc, filterKey, filterSuffix, elemMatch)
+			if !res || err != nil {
+				return false, err
+			}
+		}
+
+		return true, nil
+	}
+
 	switch value := fieldValue.(type) {
 	case *types.Document:
 		// For documents we return false as $all doesn't work on documents.
@@ -954,139 +1079,121 @@ func filterFieldExprAll(fieldValue any, allValue any) (bool, error) {
 	}
 }
 
-// filterFieldExprBitsAllClear handles {field: {$bitsAllClear: value}} filter.
-func filterFieldExprBitsAllClear(fieldValue, maskValue any) (bool, error) {
-	bitmask, err := getBinaryMaskParam("$bitsAllClear", maskValue)
-	if err != nil {
-		return false, err
-	}
+// allElemMatches returns $elemMatch expressions if all values of $all are {$elemMatch: expr} documents,
+// or nil if none of them are.
+//
+// Other operators, and mixing $elemMatch with other values, are not allowed.
+func allElemMatches(query *types.Array) ([]any, error) {
+	var res []any
+
+	for i := 0; i < query.Len(); i++ {
+		d, ok := must.NotFail(query.Get(i)).(*types.Document)
+		if !ok || d.Len() == 0 || !strings.HasPrefix(d.Keys()[0], "$") {
+			if res != nil {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrBadValue, "$all/$elemMatch has to be consistent", "$all",
+				)
+			}
 
-	switch value := fieldValue.(type) {
-	case float64:
-		if isInvalidBitwiseValue(value) {
-			return false, nil
+			continue
 		}
 
-		return (^uint64(value) & bitmask) == bitmask, nil
-
-	case types.Binary:
-		// TODO https://github.com/FerretDB/FerretDB/issues/508
-		return false, commonerrors.NewCommandErrorMsgWithArgument(
-			commonerrors.ErrNotImplemented,
-			"BinData() not supported yet",
-			"$bitsAllClear",
-		)
-
-	case int32:
-		return (^uint64(value) & bitmask) == bitmask, nil
+		if d.Keys()[0] != "$elemMatch" {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrBadValue, "no $ expressions in $all", "$all")
+		}
 
-	case int64:
-		return (^uint64(value) & bitmask) == bitmask, nil
+		if res == nil && i > 0 {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue, "$all/$elemMatch has to be consistent", "$all",
+			)
+		}
 
-	default:
-		return false, nil
+		res = append(res, must.NotFail(d.Get("$elemMatch")))
 	}
+
+	return res, nil
 }
 
-// filterFieldExprBitsAllSet handles {field: {$bitsAllSet: value}} filter.
-func filterFieldExprBitsAllSet(fieldValue, maskValue any) (bool, error) {
-	bitmask, err := getBinaryMaskParam("$bitsAllSet", maskValue)
+// filterFieldExprBits handles {field: {$bitsAllClear: value}}, {field: {$bitsAllSet: value}},
+// {field: {$bitsAnyClear: value}}, and {field: {$bitsAnySet: value}} filters.
+//
+// Only numbers representable as 64-bit integers and BinData values match.
+// Numbers are treated as two's complement values sign-extended to any number of bits;
+// BinData bits are numbered from the least significant bit of the first byte,
+// and bits past the end are clear.
+func filterFieldExprBits(operator string, fieldValue, maskValue any) (bool, error) {
+	positions, err := getBitPositionsParam(operator, maskValue)
 	if err != nil {
 		return false, err
 	}
 
+	var bitSet func(pos int64) bool
+
 	switch value := fieldValue.(type) {
 	case float64:
 		if isInvalidBitwiseValue(value) {
 			return false, nil
 		}
 
-		return (uint64(value) & bitmask) == bitmask, nil
-
-	case types.Binary:
-		// TODO https://github.com/FerretDB/FerretDB/issues/508
-		return false, commonerrors.NewCommandErrorMsgWithArgument(
-			commonerrors.ErrNotImplemented,
-			"BinData() not supported yet",
-			"$bitsAllSet",
-		)
+		bitSet = int64BitSet(int64(value))
 
 	case int32:
-		return (uint64(value) & bitmask) == bitmask, nil
+		bitSet = int64BitSet(int64(value))
 
 	case int64:
-		return (uint64(value) & bitmask) == bitmask, nil
-
-	default:
-		return false, nil
-	}
-}
-
-// filterFieldExprBitsAnyClear handles {field: {$bitsAnyClear: value}} filter.
-func filterFieldExprBitsAnyClear(fieldValue, maskValue any) (bool, error) {
-	bitmask, err := getBinaryMaskParam("$bitsAnyClear", maskValue)
-	if err != nil {
-		return false, err
-	}
-
-	switch value := fieldValue.(type) {
-	case float64:
-		if isInvalidBitwiseValue(value) {
-			return false, nil
-		}
-
-		return (^uint64(value) & bitmask) != 0, nil
+		bitSet = int64BitSet(value)
 
 	case types.Binary:
-		// TODO https://github.com/FerretDB/FerretDB/issues/508
-		return false, commonerrors.NewCommandErrorMsgWithArgument(
-			commonerrors.ErrNotImplemented,
-			"BinData() not supported yet",
-			"$bitsAnyClear",
-		)
-
-	case int32:
-		return (^uint64(value) & bitmask) != 0, nil
+		bitSet = func(pos int64) bool {
+			if pos/8 >= int64(len(value.B)) {
+				return false
+			}
 
-	case int64:
-		return (^uint64(value) & bitmask) != 0, nil
+			return value.B[pos/8]&(1<<(pos%8)) != 0
+		}
 
 	default:
 		return false, nil
 	}
-}
 
-// filterFieldExprBitsAnySet handles {field: {$bitsAnySet: value}} filter.
-func filterFieldExprBitsAnySet(fieldValue, maskValue any) (bool, error) {
-	bitmask, err := getBinaryMaskParam("$bitsAnySet", maskValue)
-	if err != nil {
-		return false, err
-	}
+	for _, pos := range positions {
+		set := bitSet(pos)
 
-	switch value := fieldValue.(type) {
-	case float64:
-		if isInvalidBitwiseValue(value) {
-			return false, nil
+		switch operator {
+		case "$bitsAllClear":
+			if set {
+				return false, nil
+			}
+		case "$bitsAllSet":
+			if !set {
+				return false, nil
+			}
+		case "$bitsAnyClear":
+			if !set {
+				return true, nil
+			}
+		case "$bitsAnySet":
+			if set {
+				return true, nil
+			}
+		default:
+			panic(fmt.Sprintf("unexpected bitwise operator %q", operator))
 		}
+	}
 
-		return (uint64(value) & bitmask) != 0, nil
-
-	case types.Binary:
-		// TODO https://github.com/FerretDB/FerretDB/issues/508
-		return false, commonerrors.NewCommandErrorMsgWithArgument(
-			commonerrors.ErrNotImplemented,
-			"BinData() not supported yet",
-			"$bitsAnySet",
-		)
-
-	case int32:
-		return (uint64(value) & bitmask) != 0, nil
+	// all positions were checked (or there were none)
+	return operator == "$bitsAllClear" || operator == "$bitsAllSet", nil
+}
 
-	case int64:
-		return (uint64(value) & bitmask) != 0, nil
+// int64BitSet returns a function that checks if the bit at the given position
+// of the sign-extended two's complement value v is set.
+func int64BitSet(v int64) func(pos int64) bool {
+	return func(pos int64) bool {
+		if pos >= 64 {
+			return v < 0
+		}
 
-	default:
-		return false, nil
+		return v&(1<<pos) != 0
 	}
 }
 
@@ -1105,7 +1212,15 @@ func isInvalidBitwiseValue(value float64) bool {
 
 // filterFieldMod handles {field: {$mod: [divisor, remainder]}} filter.
 func filterFieldMod(fieldValue, exprValue any) (bool, error) {
-	arr := exprValue.(*types.Array)
+	arr, ok := exprValue.(*types.Array)
+	if !ok {
+		return false, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			`malformed mod, needs to be an array`,
+			"$mod",
+		)
+	}
+
 	if arr.Len() < 2 {
 		return false, commonerrors.NewCommandErrorMsgWithArgument(
 			commonerrors.ErrBadValue,
@@ -1232,11 +1347,14 @@ func filterFieldMod(fieldValue, exprValue any) (bool, error) {
 }
 
 // filterFieldExprExists handles {field: {$exists: value}} filter.
+//
+// Like MongoDB, non-boolean values are converted: nulls and zero numbers are false, other values are true.
+// Null field values exist.
 func filterFieldExprExists(fieldExist bool, exprValue any) (bool, error) {
-	expr, ok := exprValue.(bool)
-	// return all documents if filter value is not bool type
-	if !ok {
-		return true, nil
+	expr, err := commonparams.GetBoolOptionalParam("$exists", exprValue)
+	if err != nil {
+		// documents, arrays, strings, etc.
+		expr = true
 	}
 
 	switch {
@@ -0,0 +1,436 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestFilterDocumentOperatorErrors(t *testing.T) {
+	t.Parallel()
+
+	doc := must.NotFail(types.NewDocument("_id", int32(1), "v", "foo"))
+
+	for name, tc := range map[string]struct {
+		filter   *types.Document
+		code     commonerrors.ErrorCode
+		argument string
+	}{
+		"UnsupportedTopLevel": {
+			filter:   must.NotFail(types.NewDocument("$where", "this.v == 'foo'")),
+			code:     commonerrors.ErrNotImplemented,
+			argument: "$where",
+		},
+		"UnsupportedField": {
+			filter: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$near", must.NotFail(types.NewArray(int32(0), int32(0))))),
+			)),
+			code:     commonerrors.ErrNotImplemented,
+			argument: "$near",
+		},
+		"UnknownTopLevel": {
+			filter:   must.NotFail(types.NewDocument("$foo", int32(1))),
+			code:     commonerrors.ErrBadValue,
+			argument: "$operator",
+		},
+		"UnknownField": {
+			filter:   must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$foo", int32(1))))),
+			code:     commonerrors.ErrBadValue,
+			argument: "$operator",
+		},
+		"AllOperator": {
+			filter: must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument(
+				"$all", must.NotFail(types.NewArray(must.NotFail(types.NewDocument("$gt", int32(1))))),
+			)))),
+			code:     commonerrors.ErrBadValue,
+			argument: "$all",
+		},
+		"AllElemMatchMixed": {
+			filter: must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument(
+				"$all", must.NotFail(types.NewArray(
+					must.NotFail(types.NewDocument("$elemMatch", must.NotFail(types.NewDocument("$gt", int32(1))))),
+					int32(2),
+				)),
+			)))),
+			code:     commonerrors.ErrBadValue,
+			argument: "$all",
+		},
+		"ModNotArray": {
+			filter:   must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$mod", int32(4))))),
+			code:     commonerrors.ErrBadValue,
+			argument: "$mod",
+		},
+		"ModNotEnoughElements": {
+			filter:   must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$mod", must.NotFail(types.NewArray(int32(4))))))),
+			code:     commonerrors.ErrBadValue,
+			argument: "$mod",
+		},
+		"ModTooManyElements": {
+			filter:   must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$mod", must.NotFail(types.NewArray(int32(4), int32(0), int32(1))))))),
+			code:     commonerrors.ErrBadValue,
+			argument: "$mod",
+		},
+		"ModDivisorZero": {
+			filter:   must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$mod", must.NotFail(types.NewArray(int32(0), int32(1))))))),
+			code:     commonerrors.ErrBadValue,
+			argument: "$mod",
+		},
+		"ModDivisorNotNumber": {
+			filter:   must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$mod", must.NotFail(types.NewArray("4", int32(1))))))),
+			code:     commonerrors.ErrBadValue,
+			argument: "$mod",
+		},
+		"ModRemainderNaN": {
+			filter:   must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$mod", must.NotFail(types.NewArray(int32(4), math.NaN())))))),
+			code:     commonerrors.ErrBadValue,
+			argument: "$mod",
+		},
+		"SampleRateType": {
+			filter:   must.NotFail(types.NewDocument("$sampleRate", "0.5")),
+			code:     commonerrors.ErrBadValue,
+			argument: "$sampleRate",
+		},
+		"SampleRateRange": {
+			filter:   must.NotFail(types.NewDocument("$sampleRate", 1.5)),
+			code:     commonerrors.ErrBadValue,
+			argument: "$sampleRate",
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := FilterDocument(doc, tc.filter)
+			require.Error(t, err)
+
+			var cErr *commonerrors.CommandError
+			require.ErrorAs(t, err, &cErr)
+			assert.Equal(t, tc.code, cErr.Code())
+			assert.Equal(t, tc.argument, cErr.Info().Argument)
+		})
+	}
+}
+
+func TestFilterDocumentSampleRate(t *testing.T) {
+	t.Parallel()
+
+	doc := must.NotFail(types.NewDocument("_id", int32(1)))
+
+	var matched int
+
+	for i := 0; i < 1000; i++ {
+		ok, err := FilterDocument(doc, must.NotFail(types.NewDocument("$sampleRate", 0.5)))
+		require.NoError(t, err)
+
+		if ok {
+			matched++
+		}
+
+		ok, err = FilterDocument(doc, must.NotFail(types.NewDocument("$sampleRate", int32(0))))
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		ok, err = FilterDocument(doc, must.NotFail(types.NewDocument("$sampleRate", int32(1))))
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	// the probability of failure is negligible
+	assert.InDelta(t, 500, matched, 150)
+}
+
+func TestFilterDocumentAllElemMatch(t *testing.T) {
+	t.Parallel()
+
+	doc := must.NotFail(types.NewDocument(
+		"_id", int32(1),
+		"v", must.NotFail(types.NewArray(int32(1), int32(5), int32(10))),
+	))
+
+	elemMatch := func(op string, v int32) *types.Document {
+		return must.NotFail(types.NewDocument("$elemMatch", must.NotFail(types.NewDocument(op, v))))
+	}
+
+	for name, tc := range map[string]struct {
+		all      *types.Array
+		expected bool
+	}{
+		"AllMatch": {
+			all:      must.NotFail(types.NewArray(elemMatch("$gt", 8), elemMatch("$lt", 2))),
+			expected: true,
+		},
+		"OneDoesNotMatch": {
+			all:      must.NotFail(types.NewArray(elemMatch("$gt", 8), elemMatch("$gt", 20))),
+			expected: false,
+		},
+		"Scalars": {
+			all:      must.NotFail(types.NewArray(int32(1), int32(10))),
+			expected: true,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			filter := must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$all", tc.all))))
+
+			actual, err := FilterDocument(doc, filter)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestFilterDocumentExists(t *testing.T) {
+	t.Parallel()
+
+	doc := must.NotFail(types.NewDocument("_id", int32(1), "v", types.Null))
+
+	for name, tc := range map[string]struct {
+		field    string
+		exists   any
+		expected bool
+	}{
+		"NullValue":      {field: "v", exists: true, expected: true},
+		"NullValueFalse": {field: "v", exists: false, expected: false},
+		"Missing":        {field: "foo", exists: false, expected: true},
+		"MissingTrue":    {field: "foo", exists: true, expected: false},
+		"Zero":           {field: "foo", exists: int32(0), expected: true},
+		"NonZero":        {field: "v", exists: 1.5, expected: true},
+		"Null":           {field: "foo", exists: types.Null, expected: true},
+		"String":         {field: "v", exists: "false", expected: true},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			filter := must.NotFail(types.NewDocument(tc.field, must.NotFail(types.NewDocument("$exists", tc.exists))))
+
+			actual, err := FilterDocument(doc, filter)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestFilterDocumentExpr(t *testing.T) {
+	t.Parallel()
+
+	doc := must.NotFail(types.NewDocument("_id", int32(1), "spent", int64(150), "budget", 100.5))
+
+	op := func(op string, args ...any) *types.Document {
+		return must.NotFail(types.NewDocument(op, must.NotFail(types.NewArray(args...))))
+	}
+
+	expr := func(v any) *types.Document {
+		return must.NotFail(types.NewDocument("$expr", v))
+	}
+
+	compare := func(name string, a, b any) *types.Document {
+		return expr(op(name, a, b))
+	}
+
+	for name, tc := range map[string]struct {
+		filter   *types.Document
+		expected bool
+	}{
+		"GtFields":      {filter: compare("$gt", "$spent", "$budget"), expected: true},
+		"LtFields":      {filter: compare("$lt", "$spent", "$budget"), expected: false},
+		"GteSame":       {filter: compare("$gte", "$spent", "$spent"), expected: true},
+		"EqNumbers":     {filter: compare("$eq", "$spent", 150.0), expected: true},
+		"EqTypes":       {filter: compare("$eq", "$spent", "150"), expected: false},
+		"NeTypes":       {filter: compare("$ne", "$spent", "150"), expected: true},
+		"LtTypeOrder":   {filter: compare("$lt", "$spent", "150"), expected: true},
+		"LteMissing":    {filter: compare("$lte", "$missing", types.Null), expected: true},
+		"CmpGreater":    {filter: compare("$cmp", "$spent", "$budget"), expected: true},
+		"CmpEqual":      {filter: compare("$cmp", "$spent", int32(150)), expected: false},
+		"GtNaN":         {filter: compare("$gt", "$budget", math.NaN()), expected: true},
+		"EqNaN":         {filter: compare("$eq", math.NaN(), math.NaN()), expected: true},
+		"EqArrays":      {filter: compare("$eq", must.NotFail(types.NewArray(int32(1))), must.NotFail(types.NewArray(1.0))), expected: true},
+		"EqArrayScalar": {filter: compare("$eq", must.NotFail(types.NewArray(int32(1))), int32(1)), expected: false},
+		"And":           {filter: expr(op("$and", op("$gt", "$spent", int32(0)), op("$lt", "$spent", int32(100)))), expected: false},
+		"AndEmpty":      {filter: expr(op("$and")), expected: true},
+		"Or":            {filter: expr(op("$or", op("$gt", "$spent", int32(0)), op("$lt", "$spent", int32(100)))), expected: true},
+		"OrEmpty":       {filter: expr(op("$or")), expected: false},
+		"Not":           {filter: expr(op("$not", op("$gt", "$spent", "$budget"))), expected: false},
+		"NotZero":       {filter: expr(op("$not", int32(0))), expected: true},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := FilterDocument(doc, tc.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestFilterDocumentBits(t *testing.T) {
+	t.Parallel()
+
+	positions := func(v ...any) *types.Array {
+		return must.NotFail(types.NewArray(v...))
+	}
+
+	for name, tc := range map[string]struct {
+		value    any
+		operator string
+		mask     any
+		expected bool
+	}{
+		"AllSetInt":             {value: int32(5), operator: "$bitsAllSet", mask: int32(5), expected: true},
+		"AllSetIntPartial":      {value: int32(4), operator: "$bitsAllSet", mask: int32(5), expected: false},
+		"AnyClearInt":           {value: int32(4), operator: "$bitsAnyClear", mask: int32(5), expected: true},
+		"AllClearPositions":     {value: int64(2), operator: "$bitsAllClear", mask: positions(int32(0), int32(2)), expected: true},
+		"SignExtendedNegative":  {value: int32(-1), operator: "$bitsAllSet", mask: positions(int32(63), int32(100)), expected: true},
+		"SignExtendedPositive":  {value: int64(1), operator: "$bitsAnySet", mask: positions(int32(64), int32(200)), expected: false},
+		"BinaryValue":           {value: types.Binary{B: []byte{0x00, 0x01}}, operator: "$bitsAllSet", mask: positions(int32(8)), expected: true},
+		"BinaryValuePastEnd":    {value: types.Binary{B: []byte{0xff}}, operator: "$bitsAllClear", mask: positions(int32(8)), expected: true},
+		"BinaryMask":            {value: int32(3), operator: "$bitsAllSet", mask: types.Binary{B: []byte{0x03}}, expected: true},
+		"BinaryMaskLong":        {value: int32(-1), operator: "$bitsAllSet", mask: types.Binary{B: make([]byte, 16)}, expected: true},
+		"BinaryMaskLongHighBit": {value: int64(1), operator: "$bitsAnySet", mask: types.Binary{B: []byte{0, 0, 0, 0, 0, 0, 0, 0, 0x01}}, expected: false},
+		"EmptyMaskAllSet":       {value: int32(0), operator: "$bitsAllSet", mask: int32(0), expected: true},
+		"EmptyMaskAnySet":       {value: int32(-1), operator: "$bitsAnySet", mask: positions(), expected: false},
+		"WholeDouble":           {value: 6.0, operator: "$bitsAllSet", mask: int32(6), expected: true},
+		"FractionalDouble":      {value: 6.5, operator: "$bitsAllClear", mask: int32(1), expected: false},
+		"String":                {value: "6", operator: "$bitsAllClear", mask: int32(1), expected: false},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			doc := must.NotFail(types.NewDocument("_id", int32(1), "v", tc.value))
+			filter := must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument(tc.operator, tc.mask))))
+
+			actual, err := FilterDocument(doc, filter)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestFilterDocumentDotNotation(t *testing.T) {
+	t.Parallel()
+
+	doc := must.NotFail(types.NewDocument(
+		"_id", int32(1),
+		"arr", must.NotFail(types.NewArray(
+			must.NotFail(types.NewDocument("x", int32(1))),
+			must.NotFail(types.NewDocument("x", int32(3), "tags", must.NotFail(types.NewArray("a", "b")))),
+		)),
+	))
+
+	cond := func(op string, v any) *types.Document {
+		return must.NotFail(types.NewDocument(op, v))
+	}
+
+	arr := func(values ...any) *types.Array {
+		return must.NotFail(types.NewArray(values...))
+	}
+
+	for name, tc := range map[string]struct {
+		key      string
+		value    any
+		expected bool
+	}{
+		"Eq":              {key: "arr.x", value: int32(3), expected: true},
+		"Index":           {key: "arr.1.x", value: int32(3), expected: true},
+		"IndexMismatch":   {key: "arr.0.x", value: int32(3), expected: false},
+		"Ne":              {key: "arr.x", value: cond("$ne", int32(3)), expected: false},
+		"NeMissing":       {key: "arr.x", value: cond("$ne", int32(2)), expected: true},
+		"Nin":             {key: "arr.x", value: cond("$nin", arr(int32(3))), expected: false},
+		"Not":             {key: "arr.x", value: cond("$not", cond("$gt", int32(2))), expected: false},
+		"RangeDifferent":  {key: "arr.x", value: must.NotFail(types.NewDocument("$gt", int32(2), "$lt", int32(2))), expected: true},
+		"RangeNone":       {key: "arr.x", value: must.NotFail(types.NewDocument("$gt", int32(3), "$lt", int32(1))), expected: false},
+		"All":             {key: "arr.x", value: cond("$all", arr(int32(1), int32(3))), expected: true},
+		"AllMissing":      {key: "arr.x", value: cond("$all", arr(int32(1), int32(2))), expected: false},
+		"AllNested":       {key: "arr.tags", value: cond("$all", arr("a", "b")), expected: true},
+		"Exists":          {key: "arr.x", value: cond("$exists", true), expected: true},
+		"ExistsFalse":     {key: "arr.x", value: cond("$exists", false), expected: false},
+		"NestedArrayElem": {key: "arr.tags", value: "b", expected: true},
+		"Size":            {key: "arr.tags", value: cond("$size", int32(2)), expected: true},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := FilterDocument(doc, must.NotFail(types.NewDocument(tc.key, tc.value)))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestFilterDocumentNumbersPrecision(t *testing.T) {
+	t.Parallel()
+
+	const maxSafe = 1 << 53
+
+	arr := func(values ...any) *types.Array {
+		return must.NotFail(types.NewArray(values...))
+	}
+
+	for name, tc := range map[string]struct {
+		value    any
+		operator string
+		arg      any
+		expected bool
+	}{
+		"EqLongDouble":          {value: int64(maxSafe + 1), operator: "$eq", arg: float64(maxSafe), expected: false},
+		"EqDoubleLong":          {value: float64(maxSafe), operator: "$eq", arg: int64(maxSafe + 1), expected: false},
+		"EqLongDoubleExact":     {value: int64(maxSafe + 2), operator: "$eq", arg: float64(maxSafe + 2), expected: true},
+		"EqLongLong":            {value: int64(maxSafe + 1), operator: "$eq", arg: int64(maxSafe + 1), expected: true},
+		"NeLongDouble":          {value: int64(maxSafe + 1), operator: "$ne", arg: float64(maxSafe), expected: true},
+		"GtLongDouble":          {value: int64(maxSafe + 1), operator: "$gt", arg: float64(maxSafe), expected: true},
+		"LtDoubleLong":          {value: float64(maxSafe), operator: "$lt", arg: int64(maxSafe + 1), expected: true},
+		"GteMaxLongDouble":      {value: int64(math.MaxInt64), operator: "$gte", arg: float64(math.MaxInt64), expected: false},
+		"LtMaxLongDouble":       {value: int64(math.MaxInt64), operator: "$lt", arg: float64(math.MaxInt64), expected: true},
+		"LteMinLongDouble":      {value: int64(math.MinInt64), operator: "$lte", arg: float64(math.MinInt64), expected: true},
+		"InLongDouble":          {value: int64(maxSafe + 1), operator: "$in", arg: arr(float64(maxSafe)), expected: false},
+		"InLongLong":            {value: int64(maxSafe + 1), operator: "$in", arg: arr(float64(maxSafe), int64(maxSafe+1)), expected: true},
+		"NinLongDouble":         {value: int64(maxSafe + 1), operator: "$nin", arg: arr(float64(maxSafe)), expected: true},
+		"EqNaNLong":             {value: math.NaN(), operator: "$eq", arg: int64(0), expected: false},
+		"EqLongNaN":             {value: int64(0), operator: "$eq", arg: math.NaN(), expected: false},
+		"EqNaNDouble":           {value: math.NaN(), operator: "$eq", arg: 0.0, expected: false},
+		"EqDoubleNaN":           {value: 0.0, operator: "$eq", arg: math.NaN(), expected: false},
+		"EqNaNNaN":              {value: math.NaN(), operator: "$eq", arg: math.NaN(), expected: true},
+		"NeNaNInt":              {value: math.NaN(), operator: "$ne", arg: int32(0), expected: true},
+		"InNaN":                 {value: math.NaN(), operator: "$in", arg: arr(int32(1), math.NaN()), expected: true},
+		"InNaNMissing":          {value: int64(1), operator: "$in", arg: arr(math.NaN()), expected: false},
+		"GtNaNLong":             {value: math.NaN(), operator: "$gt", arg: int64(math.MinInt64), expected: false},
+		"LtNaNNegativeInfinity": {value: math.NaN(), operator: "$lt", arg: math.Inf(-1), expected: false},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			doc := must.NotFail(types.NewDocument("_id", int32(1), "v", tc.value))
+			filter := must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument(tc.operator, tc.arg))))
+
+			actual, err := FilterDocument(doc, filter)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
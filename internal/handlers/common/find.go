@@ -39,15 +39,15 @@ type FindParams struct {
 	SingleBatch bool            `ferretdb:"singleBatch,opt"`
 	Comment     string          `ferretdb:"comment,opt"`
 	MaxTimeMS   int64           `ferretdb:"maxTimeMS,opt,wholePositiveNumber"`
+	Hint        any             `ferretdb:"hint,opt"` // only `$natural` is used, index hints are ignored
 
 	Collation *types.Document `ferretdb:"collation,unimplemented"`
-	Let       *types.Document `ferretdb:"let,unimplemented"`
+	Let       *types.Document `ferretdb:"let,opt"` // variables can't be referenced yet, see aggregations.NewExpression
 
 	AllowDiskUse bool            `ferretdb:"allowDiskUse,ignored"`
 	ReadConcern  *types.Document `ferretdb:"readConcern,ignored"`
 	Max          *types.Document `ferretdb:"max,ignored"`
 	Min          *types.Document `ferretdb:"min,ignored"`
-	Hint         any             `ferretdb:"hint,ignored"`
 	LSID         any             `ferretdb:"lsid,ignored"`
 
 	ReturnKey           bool `ferretdb:"returnKey,unimplemented-non-default"`
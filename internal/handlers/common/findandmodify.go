@@ -17,6 +17,7 @@ package common
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -59,10 +60,12 @@ type FindAndModifyParams struct {
 
 	HasUpdateOperators bool `ferretdb:"-"`
 
-	Let          *types.Document `ferretdb:"let,unimplemented"`
+	Let          *types.Document `ferretdb:"let,opt"`
 	Collation    *types.Document `ferretdb:"collation,unimplemented"`
-	Fields       *types.Document `ferretdb:"fields,unimplemented"`
-	ArrayFilters *types.Array    `ferretdb:"arrayFilters,unimplemented"`
+	Fields       *types.Document `ferretdb:"fields,opt"`
+	ArrayFilters *types.Array    `ferretdb:"arrayFilters,opt"`
+
+	ParsedArrayFilters ArrayFilters `ferretdb:"-"`
 
 	Hint                     string          `ferretdb:"hint,ignored"`
 	WriteConcern             *types.Document `ferretdb:"writeConcern,ignored"`
@@ -148,6 +151,13 @@ func GetFindAndModifyParams(doc *types.Document, l *zap.Logger) (*FindAndModifyP
 		)
 	}
 
+	if params.ArrayFilters != nil && params.Remove {
+		return nil, commonerrors.NewCommandErrorMsg(
+			commonerrors.ErrFailedToParse,
+			"Cannot specify arrayFilters and remove=true",
+		)
+	}
+
 	hasUpdateOperators, err := HasSupportedUpdateModifiers("findAndModify", params.Update)
 	if err != nil {
 		return nil, err
@@ -155,6 +165,12 @@ func GetFindAndModifyParams(doc *types.Document, l *zap.Logger) (*FindAndModifyP
 
 	params.HasUpdateOperators = hasUpdateOperators
 
+	if params.Update != nil {
+		if params.ParsedArrayFilters, err = NewArrayFilters("findAndModify", params.ArrayFilters, params.Update); err != nil {
+			return nil, err
+		}
+	}
+
 	return &params, nil
 }
 
@@ -162,13 +178,14 @@ func GetFindAndModifyParams(doc *types.Document, l *zap.Logger) (*FindAndModifyP
 // If docs is empty it prepares a document for insert using params.
 // Otherwise, it takes the first document of docs and prepare document for update.
 // It sets the value to return on the command response using ReturnNewDocument param.
-func PrepareDocumentForUpsert(docs []*types.Document, params *FindAndModifyParams) (*UpsertParams, error) {
+// The given time is used by $currentDate operator, and newID generates _id for inserted document.
+func PrepareDocumentForUpsert(docs []*types.Document, params *FindAndModifyParams, now time.Time, newID func() any) (*UpsertParams, error) {
 	res := new(UpsertParams)
 	var err error
 
 	if len(docs) == 0 {
 		res.Operation = UpsertOperationInsert
-		res.Upsert, err = prepareDocumentForInsert(params)
+		res.Upsert, err = prepareDocumentForInsert(params, now, newID)
 
 		// insert operation returns null since no document existed before upsert.
 		res.ReturnValue = types.Null
@@ -181,7 +198,7 @@ func PrepareDocumentForUpsert(docs []*types.Document, params *FindAndModifyParam
 	}
 
 	res.Operation = UpsertOperationUpdate
-	res.Upsert, err = prepareDocumentForUpdate(docs, params)
+	res.Upsert, err = prepareDocumentForUpdate(docs, params, now)
 
 	// update operation returns the document before updated was applied.
 	res.ReturnValue = docs[0]
@@ -196,11 +213,11 @@ func PrepareDocumentForUpsert(docs []*types.Document, params *FindAndModifyParam
 // prepareDocumentForInsert creates an insert document from the parameter.
 // When inserting new document we must check that `_id` is present, so we must extract `_id`
 // from query or generate a new one.
-func prepareDocumentForInsert(params *FindAndModifyParams) (*types.Document, error) {
+func prepareDocumentForInsert(params *FindAndModifyParams, now time.Time, newID func() any) (*types.Document, error) {
 	insert := must.NotFail(types.NewDocument())
 
 	if params.HasUpdateOperators {
-		if _, err := UpdateDocument("findAndModify", insert, params.Update); err != nil {
+		if _, err := UpdateDocument("findAndModify", insert, params.Update, params.ParsedArrayFilters, now); err != nil {
 			return nil, err
 		}
 	} else {
@@ -208,7 +225,7 @@ func prepareDocumentForInsert(params *FindAndModifyParams) (*types.Document, err
 	}
 
 	if !insert.Has("_id") {
-		id, err := getUpsertID(params.Query)
+		id, err := getUpsertID(params.Query, newID)
 		if err != nil {
 			return nil, err
 		}
@@ -220,11 +237,11 @@ func prepareDocumentForInsert(params *FindAndModifyParams) (*types.Document, err
 }
 
 // prepareDocumentForUpdate takes the first document of docs and apply update params.
-func prepareDocumentForUpdate(docs []*types.Document, params *FindAndModifyParams) (*types.Document, error) {
+func prepareDocumentForUpdate(docs []*types.Document, params *FindAndModifyParams, now time.Time) (*types.Document, error) {
 	update := docs[0].DeepCopy()
 
 	if params.HasUpdateOperators {
-		if _, err := UpdateDocument("findAndModify", update, params.Update); err != nil {
+		if _, err := UpdateDocument("findAndModify", update, params.Update, params.ParsedArrayFilters, now); err != nil {
 			return nil, err
 		}
 
@@ -262,11 +279,11 @@ func prepareDocumentForUpdate(docs []*types.Document, params *FindAndModifyParam
 }
 
 // getUpsertID gets the _id to use for upsert document. If query contains _id,
-// that _id is assigned unless _id contains operator. Otherwise, it generates an ID.
-func getUpsertID(query *types.Document) (any, error) {
+// that _id is assigned unless _id contains operator. Otherwise, it generates an ID with newID.
+func getUpsertID(query *types.Document, newID func() any) (any, error) {
 	id, err := query.Get("_id")
 	if err != nil {
-		return types.NewObjectID(), nil
+		return newID(), nil
 	}
 
 	idDoc, ok := id.(*types.Document)
@@ -282,7 +299,7 @@ func getUpsertID(query *types.Document) (any, error) {
 	if hasOp {
 		// if there is an operator in the query, the _id of the query cannot be used.
 		// generate a new one.
-		return types.NewObjectID(), nil
+		return newID(), nil
 	}
 
 	return id, nil
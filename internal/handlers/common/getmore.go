@@ -32,7 +32,11 @@ import (
 )
 
 // GetMore is a part of common implementation of the getMore command.
-func GetMore(ctx context.Context, msg *wire.OpMsg, registry *cursor.Registry) (*wire.OpMsg, error) {
+//
+// If checkout is not nil, it is called after the cursor is found and validated,
+// but before any documents are fetched.
+// If it returns an error, the cursor is closed, and the error is returned.
+func GetMore(ctx context.Context, msg *wire.OpMsg, registry *cursor.Registry, checkout func() error) (*wire.OpMsg, error) {
 	document, err := msg.Document()
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -179,6 +183,14 @@ func GetMore(ctx context.Context, msg *wire.OpMsg, registry *cursor.Registry) (*
 		)
 	}
 
+	if checkout != nil {
+		if err = checkout(); err != nil {
+			// like mongod, kill the checked out cursor on error
+			cursor.Close()
+			return nil, err
+		}
+	}
+
 	resDocs, err := iterator.ConsumeValuesN(iterator.Interface[struct{}, *types.Document](cursor), int(batchSize))
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -20,6 +20,7 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
 	"github.com/FerretDB/FerretDB/internal/types"
@@ -30,7 +31,9 @@ import (
 )
 
 // GetParameter is a part of common implementation of the getParameter command.
-func GetParameter(_ context.Context, msg *wire.OpMsg, l *zap.Logger) (*wire.OpMsg, error) {
+//
+// Current values of some parameters are taken from the given logger and connection metrics.
+func GetParameter(_ context.Context, msg *wire.OpMsg, l *zap.Logger, cm *connmetrics.ConnMetrics) (*wire.OpMsg, error) {
 	document, err := msg.Document()
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -45,7 +48,45 @@ func GetParameter(_ context.Context, msg *wire.OpMsg, l *zap.Logger) (*wire.OpMs
 
 	Ignored(document, l, "comment")
 
-	parameters := must.NotFail(types.NewDocument(
+	parameters := parametersCatalog(l, cm)
+
+	resDoc, err := selectParameters(document, parameters, showDetails, allParameters)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if resDoc.Len() < 1 {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrorCode(0),
+			"no option found to get",
+			document.Command(),
+		)
+	}
+
+	resDoc.Set("ok", float64(1))
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{resDoc},
+	}))
+
+	return &reply, nil
+}
+
+// parametersCatalog returns all supported parameters with their current values and details.
+func parametersCatalog(l *zap.Logger, cm *connmetrics.ConnMetrics) *types.Document {
+	// MongoDB uses verbosity levels where 0 is the default, and greater values include debug messages
+	var logLevel int32
+	if l.Level() == zap.DebugLevel {
+		logLevel = 1
+	}
+
+	tlsMode := cm.GetTLSMode()
+	if tlsMode == "" {
+		tlsMode = connmetrics.TLSModeDisabled
+	}
+
+	return must.NotFail(types.NewDocument(
 		// to add a new parameter, fill template and place it in the alphabetical order position
 		//"<name>", must.NotFail(types.NewDocument(
 		//	"value", <value>,
@@ -62,40 +103,34 @@ func GetParameter(_ context.Context, msg *wire.OpMsg, l *zap.Logger) (*wire.OpMs
 			"settableAtRuntime", true,
 			"settableAtStartup", true,
 		)),
+		"cursorTimeoutMillis", must.NotFail(types.NewDocument(
+			// cursors do not time out; they are closed together with the client connection
+			"value", int64(0),
+			"settableAtRuntime", false,
+			"settableAtStartup", false,
+		)),
 		"featureCompatibilityVersion", must.NotFail(types.NewDocument(
 			"value", must.NotFail(types.NewDocument("version", "6.0")),
 			"settableAtRuntime", false,
 			"settableAtStartup", false,
 		)),
+		"logLevel", must.NotFail(types.NewDocument(
+			"value", logLevel,
+			"settableAtRuntime", false,
+			"settableAtStartup", true,
+		)),
 		"quiet", must.NotFail(types.NewDocument(
 			"value", false,
 			"settableAtRuntime", true,
 			"settableAtStartup", true,
 		)),
+		"tlsMode", must.NotFail(types.NewDocument(
+			"value", tlsMode,
+			"settableAtRuntime", false,
+			"settableAtStartup", true,
+		)),
 		// parameters are alphabetically ordered
 	))
-
-	resDoc, err := selectParameters(document, parameters, showDetails, allParameters)
-	if err != nil {
-		return nil, lazyerrors.Error(err)
-	}
-
-	if resDoc.Len() < 1 {
-		return nil, commonerrors.NewCommandErrorMsgWithArgument(
-			commonerrors.ErrorCode(0),
-			"no option found to get",
-			document.Command(),
-		)
-	}
-
-	resDoc.Set("ok", float64(1))
-
-	var reply wire.OpMsg
-	must.NoError(reply.SetSections(wire.OpMsgSection{
-		Documents: []*types.Document{resDoc},
-	}))
-
-	return &reply, nil
 }
 
 // selectParameters makes a selection of requested parameters.
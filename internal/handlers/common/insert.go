@@ -32,6 +32,10 @@ type InsertParams struct {
 	Collection string       `ferretdb:"insert,collection"`
 	Ordered    bool         `ferretdb:"ordered,opt"`
 
+	// SkipDuplicates is a FerretDB-specific extension:
+	// documents that violate unique indexes are silently skipped instead of producing write errors.
+	SkipDuplicates bool `ferretdb:"skipDuplicates,opt"`
+
 	WriteConcern             any    `ferretdb:"writeConcern,ignored"`
 	BypassDocumentValidation bool   `ferretdb:"bypassDocumentValidation,ignored"`
 	Comment                  string `ferretdb:"comment,ignored"`
@@ -25,31 +25,45 @@ import (
 )
 
 // IsMaster is a common implementation of the isMaster command used by deprecated OP_QUERY message.
-func IsMaster(ctx context.Context, query *types.Document) (*wire.OpReply, error) {
+//
+// Now is returned as the server's local time.
+func IsMaster(ctx context.Context, query *types.Document, now time.Time) (*wire.OpReply, error) {
 	if err := CheckClientMetadata(ctx, query); err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
+	compression, err := NegotiateCompression(ctx, query)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
 	return &wire.OpReply{
 		NumberReturned: 1,
-		Documents:      IsMasterDocuments(),
+		Documents:      IsMasterDocuments(compression, now),
 	}, nil
 }
 
 // IsMasterDocuments returns isMaster's Documents field (identical for both OP_MSG and OP_QUERY).
-func IsMasterDocuments() []*types.Document {
-	return []*types.Document{must.NotFail(types.NewDocument(
+//
+// Compression contains negotiated compressors and may be nil.
+// Now is returned as the server's local time.
+func IsMasterDocuments(compression *types.Array, now time.Time) []*types.Document {
+	doc := must.NotFail(types.NewDocument(
 		"ismaster", true, // only lowercase
 		// topologyVersion
 		"maxBsonObjectSize", int32(types.MaxDocumentLen),
 		"maxMessageSizeBytes", int32(wire.MaxMsgLen),
 		"maxWriteBatchSize", int32(100000),
-		"localTime", time.Now(),
-		// logicalSessionTimeoutMinutes
+		"localTime", now,
+		"logicalSessionTimeoutMinutes", int32(30),
 		"connectionId", int32(42),
 		"minWireVersion", MinWireVersion,
 		"maxWireVersion", MaxWireVersion,
 		"readOnly", false,
 		"ok", float64(1),
-	))}
+	))
+
+	SetCompression(doc, compression)
+
+	return []*types.Document{doc}
 }
@@ -0,0 +1,244 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// Supported `$meta` keywords.
+const (
+	metaSortKey   = "sortKey"
+	metaRecordID  = "recordId"
+	metaTextScore = "textScore"
+)
+
+// MetaProjection extracts `{<field>: {$meta: <keyword>}}` expressions from the projection.
+//
+// It returns the projection without them and a document with extracted fields and keywords.
+// Metadata fields are neither inclusions nor exclusions;
+// if the rest of the projection is an inclusion projection, they are added to it as inclusions,
+// so they are kept after MetaIterator sets them.
+func MetaProjection(projection *types.Document) (*types.Document, *types.Document, error) {
+	rest := types.MakeDocument(projection.Len())
+	meta := types.MakeDocument(0)
+
+	iter := projection.Iterator()
+	defer iter.Close()
+
+	for {
+		key, value, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, nil, lazyerrors.Error(err)
+		}
+
+		expr, ok := value.(*types.Document)
+		if !ok || expr.Len() != 1 || !expr.Has("$meta") {
+			rest.Set(key, value)
+			continue
+		}
+
+		keyword, ok := must.NotFail(expr.Get("$meta")).(string)
+		if !ok {
+			return nil, nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf("Illegal argument to $meta: %s", types.FormatAnyValue(must.NotFail(expr.Get("$meta")))),
+				"projection",
+			)
+		}
+
+		switch keyword {
+		case metaSortKey, metaRecordID, metaTextScore:
+			meta.Set(key, keyword)
+
+		case "indexKey", "searchScore", "searchHighlights", "searchScoreDetails", "geoNearDistance", "geoNearPoint":
+			return nil, nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				fmt.Sprintf("$meta keyword %q is not supported", keyword),
+				"projection",
+			)
+
+		default:
+			return nil, nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf("Unsupported argument to $meta: %s", keyword),
+				"projection",
+			)
+		}
+	}
+
+	if meta.Len() == 0 || rest.Len() == 0 {
+		return rest, meta, nil
+	}
+
+	_, inclusion, err := ValidateProjection(rest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if inclusion {
+		for _, key := range meta.Keys() {
+			rest.Set(key, true)
+		}
+	}
+
+	return rest, meta, nil
+}
+
+// MetaIterator returns an iterator that sets metadata fields extracted by MetaProjection
+// to documents returned by the underlying iterator.
+// It will be added to the given closer.
+//
+// It should be applied after sorting, but before projection.
+// The given sort document is used for `sortKey` metadata.
+// The textScores map should be set to backends.QueryResult.TextScores for $text queries;
+// `textScore` metadata is available only for them.
+//
+// Next method returns the next document with metadata fields set.
+//
+// Close method closes the underlying iterator.
+func MetaIterator(iter types.DocumentsIterator, closer *iterator.MultiCloser, meta, sort *types.Document, textScores map[*types.Document]float64) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	if meta.Len() == 0 {
+		return iter, nil
+	}
+
+	var sortPaths []types.Path
+
+	for _, key := range sort.Keys() {
+		// natural order is not a part of the sort key
+		if strings.HasPrefix(key, "$") {
+			continue
+		}
+
+		path, err := types.NewPathFromString(key)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		sortPaths = append(sortPaths, path)
+	}
+
+	for _, keyword := range meta.Values() {
+		if keyword == metaSortKey && sort.Len() == 0 {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				"query requires sort key metadata, but it is not available",
+				"projection",
+			)
+		}
+
+		if keyword == metaTextScore && textScores == nil {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				"query requires text score metadata, but it is not available",
+				"projection",
+			)
+		}
+	}
+
+	res := &metaIterator{
+		iter:       iter,
+		meta:       meta,
+		sortPaths:  sortPaths,
+		textScores: textScores,
+	}
+	closer.Add(res)
+
+	return res, nil
+}
+
+// metaIterator is returned by MetaIterator.
+type metaIterator struct {
+	iter       types.DocumentsIterator
+	meta       *types.Document
+	sortPaths  []types.Path
+	textScores map[*types.Document]float64
+}
+
+// Next implements iterator.Interface. See MetaIterator for details.
+func (iter *metaIterator) Next() (struct{}, *types.Document, error) {
+	var unused struct{}
+
+	_, doc, err := iter.iter.Next()
+	if err != nil {
+		return unused, nil, lazyerrors.Error(err)
+	}
+
+	values := make(map[string]any, iter.meta.Len())
+
+	for _, key := range iter.meta.Keys() {
+		switch keyword := must.NotFail(iter.meta.Get(key)).(string); keyword {
+		case metaSortKey:
+			// the same format as MongoDB uses: fields with empty names
+			pairs := make([]any, 0, len(iter.sortPaths)*2)
+
+			for _, path := range iter.sortPaths {
+				v, err := doc.GetByPath(path)
+				if err != nil {
+					v = types.Null
+				}
+
+				pairs = append(pairs, "", v)
+			}
+
+			values[key] = must.NotFail(types.NewDocument(pairs...))
+
+		case metaRecordID:
+			values[key] = int64(doc.RecordID())
+
+		case metaTextScore:
+			values[key] = iter.textScores[doc]
+
+		default:
+			panic(fmt.Sprintf("unexpected $meta keyword %q", keyword))
+		}
+	}
+
+	// set values after computing all of them, so they do not affect each other
+	for _, key := range iter.meta.Keys() {
+		path, err := types.NewPathFromString(key)
+		if err != nil {
+			return unused, nil, lazyerrors.Error(err)
+		}
+
+		if err = doc.SetByPath(path, values[key]); err != nil {
+			return unused, nil, lazyerrors.Error(err)
+		}
+	}
+
+	return unused, doc, nil
+}
+
+// Close implements iterator.Interface. See MetaIterator for details.
+func (iter *metaIterator) Close() {
+	iter.iter.Close()
+}
+
+// check interfaces
+var (
+	_ types.DocumentsIterator = (*metaIterator)(nil)
+)
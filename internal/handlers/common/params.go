@@ -204,10 +204,13 @@ func GetSkipStageParam(value any) (int64, error) {
 	return limit, nil
 }
 
-// getBinaryMaskParam matches value type, returning bit mask and error if match failed.
-// Possible values are: position array ([1,3,5] == 010101), whole number value and types.Binary value.
-func getBinaryMaskParam(operator string, mask any) (uint64, error) {
-	var bitmask uint64
+// getBitPositionsParam matches value type, returning positions of bits set in the mask and error if match failed.
+// Possible values are: position array ([1,3,5] == 101010), whole number value and types.Binary value.
+//
+// Positions are not limited to 64 bits:
+// BinData masks and position arrays may refer to any bit.
+func getBitPositionsParam(operator string, mask any) ([]int64, error) {
+	var positions []int64
 
 	switch mask := mask.(type) {
 	case *types.Array:
@@ -220,13 +223,13 @@ func getBinaryMaskParam(operator string, mask any) (uint64, error) {
 				switch {
 				case errors.Is(err, commonparams.ErrNotWholeNumber), errors.Is(err, commonparams.ErrInfinity),
 					errors.Is(err, commonparams.ErrLongExceededPositive), errors.Is(err, commonparams.ErrLongExceededNegative):
-					return 0, commonerrors.NewCommandErrorMsgWithArgument(
+					return nil, commonerrors.NewCommandErrorMsgWithArgument(
 						commonerrors.ErrBadValue,
 						fmt.Sprintf(`Failed to parse bit position. Expected an integer: %d: %#v`, i, val),
 						operator,
 					)
 				default:
-					return 0, commonerrors.NewCommandErrorMsgWithArgument(
+					return nil, commonerrors.NewCommandErrorMsgWithArgument(
 						commonerrors.ErrBadValue,
 						fmt.Sprintf(`Failed to parse bit position. Expected a number in: %d: %#v`, i, val),
 						operator,
@@ -235,20 +238,20 @@ func getBinaryMaskParam(operator string, mask any) (uint64, error) {
 			}
 
 			if b < 0 {
-				return 0, commonerrors.NewCommandErrorMsgWithArgument(
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
 					commonerrors.ErrBadValue,
 					fmt.Sprintf("Failed to parse bit position. Expected a non-negative number in: %d: %d", i, b),
 					operator,
 				)
 			}
 
-			bitmask |= 1 << uint64(math.Min(float64(b), 63))
+			positions = append(positions, b)
 		}
 
 	case float64:
 		// {field: {$bitsAllClear: bitmask}}
 		if mask != math.Trunc(mask) || math.IsInf(mask, 0) {
-			return 0, commonerrors.NewCommandErrorMsgWithArgument(
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
 				commonerrors.ErrFailedToParse,
 				fmt.Sprintf("Expected an integer: %s: %#v", operator, mask),
 				operator,
@@ -256,64 +259,79 @@ func getBinaryMaskParam(operator string, mask any) (uint64, error) {
 		}
 
 		if mask < 0 {
-			return 0, commonerrors.NewCommandErrorMsgWithArgument(
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
 				commonerrors.ErrFailedToParse,
 				fmt.Sprintf(`Expected a non-negative number in: %s: %.1f`, operator, mask),
 				operator,
 			)
 		}
 
-		bitmask = uint64(mask)
+		if mask >= math.MaxInt64 {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrFailedToParse,
+				fmt.Sprintf("Cannot represent as a 64-bit integer: %s: %#v", operator, mask),
+				operator,
+			)
+		}
+
+		positions = uint64BitPositions(uint64(mask))
 
 	case types.Binary:
 		// {field: {$bitsAllClear: BinData()}}
-		for b := 0; b < len(mask.B); b++ {
-			byteAt := mask.B[b]
-
-			if byteAt == 0 {
-				continue
-			}
-
-			if b < 8 {
-				bitmask |= uint64(byteAt) << uint64(b*8)
-			} else {
-				bitmask |= 1 << 63
+		for i, byteAt := range mask.B {
+			for bit := 0; bit < 8; bit++ {
+				if byteAt&(1<<bit) != 0 {
+					positions = append(positions, int64(i*8+bit))
+				}
 			}
 		}
 
 	case int32:
 		// {field: {$bitsAllClear: bitmask}}
 		if mask < 0 {
-			return 0, commonerrors.NewCommandErrorMsgWithArgument(
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
 				commonerrors.ErrFailedToParse,
 				fmt.Sprintf(`Expected a non-negative number in: %s: %v`, operator, mask),
 				operator,
 			)
 		}
 
-		bitmask = uint64(mask)
+		positions = uint64BitPositions(uint64(mask))
 
 	case int64:
 		// {field: {$bitsAllClear: bitmask}}
 		if mask < 0 {
-			return 0, commonerrors.NewCommandErrorMsgWithArgument(
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
 				commonerrors.ErrFailedToParse,
 				fmt.Sprintf(`Expected a non-negative number in: %s: %v`, operator, mask),
 				operator,
 			)
 		}
 
-		bitmask = uint64(mask)
+		positions = uint64BitPositions(uint64(mask))
 
 	default:
-		return 0, commonerrors.NewCommandErrorMsgWithArgument(
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
 			commonerrors.ErrBadValue,
 			fmt.Sprintf(`value takes an Array, a number, or a BinData but received: %s: %#v`, operator, mask),
 			operator,
 		)
 	}
 
-	return bitmask, nil
+	return positions, nil
+}
+
+// uint64BitPositions returns positions of bits set in the given value.
+func uint64BitPositions(v uint64) []int64 {
+	var positions []int64
+
+	for bit := int64(0); v != 0; bit, v = bit+1, v>>1 {
+		if v&1 != 0 {
+			positions = append(positions, bit)
+		}
+	}
+
+	return positions
 }
 
 // addNumbers returns the result of v1 and v2 addition and error if addition failed.
@@ -337,15 +355,13 @@ func addNumbers(v1, v2 any) (any, error) {
 		case float64:
 			return v2 + float64(v1), nil
 		case int32:
-			if v2 == math.MaxInt32 && v1 > 0 {
-				return int64(v1) + int64(v2), nil
+			// int32 overflow promotes the result to int64
+			res := int64(v1) + int64(v2)
+			if res > math.MaxInt32 || res < math.MinInt32 {
+				return res, nil
 			}
 
-			if v2 == math.MinInt32 && v1 < 0 {
-				return int64(v1) + int64(v2), nil
-			}
-
-			return v1 + v2, nil
+			return int32(res), nil
 		case int64:
 			if v2 > 0 {
 				if int64(v1) > math.MaxInt64-v2 {
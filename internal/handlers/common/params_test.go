@@ -91,3 +91,63 @@ func TestMultiplyLongSafely(t *testing.T) {
 		})
 	}
 }
+
+func TestAddNumbers(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		v1, v2   any
+		expected any
+		err      error
+	}{
+		"Int32": {
+			v1:       int32(40),
+			v2:       int32(2),
+			expected: int32(42),
+		},
+		"Int32Overflow": {
+			v1:       int32(math.MaxInt32 - 1),
+			v2:       int32(2),
+			expected: int64(math.MaxInt32 + 1),
+		},
+		"Int32Underflow": {
+			v1:       int32(math.MinInt32 / 2),
+			v2:       int32(math.MinInt32/2 - 1),
+			expected: int64(math.MinInt32 - 1),
+		},
+		"Int32Int64": {
+			v1:       int32(1),
+			v2:       int64(41),
+			expected: int64(42),
+		},
+		"Int64Double": {
+			v1:       int64(1),
+			v2:       41.5,
+			expected: 42.5,
+		},
+		"Int64Overflow": {
+			v1:  int64(math.MaxInt64),
+			v2:  int64(1),
+			err: commonparams.ErrLongExceededPositive,
+		},
+		"Int64Int32Overflow": {
+			v1:  int64(math.MinInt64),
+			v2:  int32(-1),
+			err: commonparams.ErrIntExceeded,
+		},
+		"NonNumeric": {
+			v1:  int32(1),
+			v2:  "foo",
+			err: commonparams.ErrUnexpectedRightOpType,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actualRes, err := addNumbers(tc.v1, tc.v2)
+			assert.Equal(t, tc.err, err)
+			assert.Equal(t, tc.expected, actualRes)
+		})
+	}
+}
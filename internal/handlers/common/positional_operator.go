@@ -16,6 +16,7 @@ package common
 
 import (
 	"errors"
+	"strings"
 
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
 	"github.com/FerretDB/FerretDB/internal/types"
@@ -36,7 +37,8 @@ import (
 // Command error codes:
 //   - ErrBadPositionalProjection when array or filter at positional projection path is empty;
 //   - ErrBadPositionalProjection when there is no filter field key for positional projection path.
-//     If positional projection is `v.$`, the filter must contain `v` in the filter key such as `{v: 42}`.
+//     If positional projection is `v.$`, the filter must contain `v` or its dot notation field
+//     in the filter key such as `{v: 42}` or `{"v.foo": 42}`.
 func getPositionalProjection(arr *types.Array, filter *types.Document, positionalOperatorPath string) (*types.Array, error) {
 	if arr.Len() == 0 || filter.Len() == 0 {
 		return nil, commonerrors.NewCommandErrorMsgWithArgument(
@@ -98,6 +100,29 @@ func getPositionalProjection(arr *types.Array, filter *types.Document, positiona
 			}
 
 			if filterKey != path {
+				suffix, ok := strings.CutPrefix(filterKey, path+".")
+				if !ok {
+					continue
+				}
+
+				positionalPathFound = true
+
+				// filter on the field of array documents such as {"v.foo": 42} for `v.$`,
+				// elements that are not documents cannot match it.
+				elemDoc, ok := elem.(*types.Document)
+				if !ok {
+					break
+				}
+
+				matched, err := FilterDocument(elemDoc, must.NotFail(types.NewDocument(suffix, filterVal)))
+				if err != nil {
+					return nil, err
+				}
+
+				if !matched {
+					break
+				}
+
 				continue
 			}
 
@@ -114,17 +139,10 @@ func getPositionalProjection(arr *types.Array, filter *types.Document, positiona
 				continue
 			}
 
-			// array element does not have a key, use positional operator `$` as key,
-			// it would be used for error message but there will not be error because
-			// filterFieldExpr(...) has already been call before projection.
-			key := "$"
-			doc := must.NotFail(types.NewDocument(key, elem))
-
-			// In filtering filterFieldExpr was called to check if an array
-			// matched the filter.
-			// In this call, we already know that the array matched the filter,
-			// and we want to find out which array element matched the filter.
-			matched := must.NotFail(filterFieldExpr(doc, key, key, expr))
+			matched, err := positionalElementMatches(elem, expr)
+			if err != nil {
+				return nil, err
+			}
 
 			if !matched {
 				break
@@ -132,3 +150,61 @@ func getPositionalProjection(arr *types.Array, filter *types.Document, positiona
 		}
 	}
 }
+
+// positionalElementMatches returns true if the array element matches the filter expression
+// of the array field such as {$gt: 42}.
+//
+// $elemMatch is matched against the element alone,
+// $size applies to the whole array and does not select an element, so it is ignored.
+func positionalElementMatches(elem any, expr *types.Document) (bool, error) {
+	// array element does not have a key, use positional operator `$` as key,
+	// it would be used for error message but there will not be error because
+	// filterFieldExpr(...) has already been call before projection.
+	key := "$"
+
+	if expr.Len() == 0 || !strings.HasPrefix(expr.Keys()[0], "$") {
+		return filterFieldExpr(must.NotFail(types.NewDocument(key, elem)), key, key, expr)
+	}
+
+	elemExpr := new(types.Document)
+
+	iter := expr.Iterator()
+	defer iter.Close()
+
+	for {
+		exprKey, exprValue, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return false, lazyerrors.Error(err)
+		}
+
+		switch exprKey {
+		case "$size":
+			continue
+
+		case "$elemMatch":
+			doc := must.NotFail(types.NewDocument(key, must.NotFail(types.NewArray(elem))))
+
+			matched, err := filterFieldExprElemMatch(doc, key, key, exprValue)
+			if !matched || err != nil {
+				return false, err
+			}
+
+		default:
+			elemExpr.Set(exprKey, exprValue)
+		}
+	}
+
+	if elemExpr.Len() == 0 {
+		return true, nil
+	}
+
+	// In filtering filterFieldExpr was called to check if an array
+	// matched the filter.
+	// In this call, we already know that the array matched the filter,
+	// and we want to find out which array element matched the filter.
+	return filterFieldExpr(must.NotFail(types.NewDocument(key, elem)), key, key, elemExpr)
+}
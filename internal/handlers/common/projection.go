@@ -17,11 +17,13 @@ package common
 import (
 	"errors"
 	"fmt"
+	"math"
 	"slices"
 	"strings"
 	"time"
 
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/iterator"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
@@ -46,6 +48,9 @@ import (
 //   - `ErrBadPositionalProjection` when array or filter at positional projection path is empty;
 //   - `ErrBadPositionalProjection` when there is no filter field key for positional projection path;
 //   - `ErrElementMismatchPositionalProjection` when unexpected array was found on positional projection path;
+//   - `ErrSliceProjectionArg`, `ErrSliceProjectionArrayLen`, `ErrSliceProjectionLimit` when $slice argument is invalid;
+//   - `ErrElemMatchProjectionArg` when $elemMatch argument is not a document;
+//   - `ErrElemMatchProjectionNested` when $elemMatch is used on a nested field;
 //   - `ErrNotImplemented` when there is unimplemented projection operators and expressions.
 func ValidateProjection(projection *types.Document) (*types.Document, bool, error) {
 	validated := types.MakeDocument(0)
@@ -56,6 +61,7 @@ func ValidateProjection(projection *types.Document) (*types.Document, bool, erro
 	}
 
 	var inclusion *bool
+	var elemMatch bool
 
 	iter := projection.Iterator()
 	defer iter.Close()
@@ -147,10 +153,36 @@ func ValidateProjection(projection *types.Document) (*types.Document, bool, erro
 
 		switch value := value.(type) {
 		case *types.Document:
-			return nil, false, commonerrors.NewCommandErrorMsg(
-				commonerrors.ErrNotImplemented,
-				fmt.Sprintf("projection expression %s is not supported", types.FormatAnyValue(value)),
-			)
+			var op string
+			if value.Len() == 1 {
+				op = value.Keys()[0]
+			}
+
+			switch op {
+			case "$slice":
+				if _, _, err = getSliceProjectionArgs(must.NotFail(value.Get("$slice"))); err != nil {
+					return nil, false, err
+				}
+
+			case "$elemMatch":
+				if err = validateElemMatchProjection(path, must.NotFail(value.Get("$elemMatch"))); err != nil {
+					return nil, false, err
+				}
+
+				elemMatch = true
+
+			default:
+				return nil, false, commonerrors.NewCommandErrorMsg(
+					commonerrors.ErrNotImplemented,
+					fmt.Sprintf("projection expression %s is not supported", types.FormatAnyValue(value)),
+				)
+			}
+
+			validated.Set(key, value)
+
+			// $slice and $elemMatch projections make neither inclusion nor exclusion projection
+			continue
+
 		case *types.Array, string, types.Binary, types.ObjectID,
 			time.Time, types.NullType, types.Regex, types.Timestamp: // all these types are treated as new fields value
 			inclusionField = true
@@ -218,6 +250,12 @@ func ValidateProjection(projection *types.Document) (*types.Document, bool, erro
 		}
 	}
 
+	if inclusion == nil {
+		// only _id, $slice, and $elemMatch fields are projected;
+		// $elemMatch alone returns only matching elements
+		return validated, elemMatch, nil
+	}
+
 	return validated, *inclusion, nil
 }
 
@@ -296,6 +334,9 @@ func projectDocumentWithoutID(doc *types.Document, projection, filter *types.Doc
 		projected = docWithoutID.DeepCopy()
 	}
 
+	// $elemMatch projections are applied after all other fields
+	elemMatches := types.MakeDocument(0)
+
 	iter := projectionWithoutID.Iterator()
 	defer iter.Close()
 
@@ -315,13 +356,34 @@ func projectDocumentWithoutID(doc *types.Document, projection, filter *types.Doc
 		}
 
 		switch value := value.(type) { // found in the projection
-		case *types.Document: // field: { $elemMatch: { field2: value }}
-			return nil, commonerrors.NewCommandErrorMsg(
-				commonerrors.ErrCommandNotFound,
-				fmt.Sprintf("projection %s is not supported",
-					types.FormatAnyValue(value),
-				),
-			)
+		case *types.Document: // field: { $slice: value } or field: { $elemMatch: { field2: value }}
+			if value.Has("$elemMatch") {
+				elemMatches.Set(key, must.NotFail(value.Get("$elemMatch")))
+				continue
+			}
+
+			if !value.Has("$slice") {
+				return nil, commonerrors.NewCommandErrorMsg(
+					commonerrors.ErrCommandNotFound,
+					fmt.Sprintf("projection %s is not supported",
+						types.FormatAnyValue(value),
+					),
+				)
+			}
+
+			skip, limit, err := getSliceProjectionArgs(must.NotFail(value.Get("$slice")))
+			if err != nil {
+				return nil, err
+			}
+
+			if inclusion {
+				// the sliced field is included as is, then it is sliced in projected.
+				if _, err = includeProjection(path, 0, docWithoutID, projected, filter); err != nil {
+					return nil, err
+				}
+			}
+
+			sliceProjection(path, projected, skip, limit)
 
 		case *types.Array, string, types.Binary, types.ObjectID,
 			time.Time, types.NullType, types.Regex, types.Timestamp: // all these types are treated as new fields value
@@ -344,6 +406,19 @@ func projectDocumentWithoutID(doc *types.Document, projection, filter *types.Doc
 		}
 	}
 
+	for i, key := range elemMatches.Keys() {
+		projected.Remove(key)
+
+		v, err := elemMatchProjection(docWithoutID, key, elemMatches.Values()[i].(*types.Document))
+		if err != nil {
+			return nil, err
+		}
+
+		if v != nil {
+			projected.Set(key, v)
+		}
+	}
+
 	return projected, nil
 }
 
@@ -572,6 +647,229 @@ func excludeProjection(path types.Path, projected any) {
 	}
 }
 
+// getSliceProjectionArgs returns skip and limit of $slice projection argument.
+// It is either a number of elements to return, from the end of the array if negative,
+// or [skip, limit] array, where negative skip counts from the end of the array.
+//
+// Command error codes:
+//   - ErrSliceProjectionArg when argument is neither a number nor an array of numbers.
+//   - ErrSliceProjectionArrayLen when array argument does not have exactly two elements.
+//   - ErrSliceProjectionLimit when limit of array argument is not positive.
+func getSliceProjectionArgs(arg any) (int, int, error) {
+	if n, ok := sliceProjectionNumber(arg); ok {
+		if n < 0 {
+			return n, -n, nil
+		}
+
+		return 0, n, nil
+	}
+
+	arr, ok := arg.(*types.Array)
+	if !ok {
+		return 0, 0, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrSliceProjectionArg,
+			"$slice only supports numbers and [skip, limit] arrays",
+			"projection",
+		)
+	}
+
+	if arr.Len() != 2 {
+		return 0, 0, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrSliceProjectionArrayLen,
+			"$slice array argument should be of form [skip, limit]",
+			"projection",
+		)
+	}
+
+	skip, skipOk := sliceProjectionNumber(must.NotFail(arr.Get(0)))
+	limit, limitOk := sliceProjectionNumber(must.NotFail(arr.Get(1)))
+
+	if !skipOk || !limitOk {
+		return 0, 0, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrSliceProjectionArg,
+			"$slice only supports numbers and [skip, limit] arrays",
+			"projection",
+		)
+	}
+
+	if limit <= 0 {
+		return 0, 0, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrSliceProjectionLimit,
+			"$slice limit must be positive",
+			"projection",
+		)
+	}
+
+	return skip, limit, nil
+}
+
+// sliceProjectionNumber returns the number of $slice projection argument truncated to int32 range.
+// It returns false if the value is not a number.
+func sliceProjectionNumber(v any) (int, bool) {
+	var f float64
+
+	switch v := v.(type) {
+	case int32:
+		return int(v), true
+	case int64:
+		f = float64(v)
+	case float64:
+		if math.IsNaN(v) {
+			return 0, true
+		}
+
+		f = math.Trunc(v)
+	default:
+		return 0, false
+	}
+
+	return int(max(min(f, math.MaxInt32), math.MinInt32)), true
+}
+
+// sliceProjection replaces the array on the path in projected with its slice.
+// When an array of documents is on the path, it slices arrays in those documents.
+// Non-array values are left as is.
+//
+// Example: "v" path with skip -1 and limit 1:
+//
+//	{v: [1, 2, 3]}           -> {v: [3]}
+//	{v: 42}                  -> {v: 42}
+//
+// Example: "v.foo" path with skip 0 and limit 1:
+//
+//	{v: [{foo: [1, 2]}, {foo: [3]}]} -> {v: [{foo: [1]}, {foo: [3]}]}
+func sliceProjection(path types.Path, projected any, skip, limit int) {
+	switch projected := projected.(type) {
+	case *types.Document:
+		key := path.Prefix()
+
+		v, err := projected.Get(key)
+		if err != nil {
+			// key does not exist, nothing to slice.
+			return
+		}
+
+		if path.Len() > 1 {
+			sliceProjection(path.TrimPrefix(), v, skip, limit)
+			return
+		}
+
+		arr, ok := v.(*types.Array)
+		if !ok {
+			return
+		}
+
+		start := min(skip, arr.Len())
+		if skip < 0 {
+			start = max(arr.Len()+skip, 0)
+		}
+
+		end := min(start+limit, arr.Len())
+
+		sliced := types.MakeArray(end - start)
+		for i := start; i < end; i++ {
+			sliced.Append(must.NotFail(arr.Get(i)))
+		}
+
+		projected.Set(key, sliced)
+
+	case *types.Array:
+		for i := 0; i < projected.Len(); i++ {
+			if doc, ok := must.NotFail(projected.Get(i)).(*types.Document); ok {
+				sliceProjection(path, doc, skip, limit)
+			}
+		}
+	}
+}
+
+// validateElemMatchProjection checks $elemMatch projection path and argument.
+//
+// Command error codes:
+//   - ErrElemMatchProjectionNested when path is a dot notation path.
+//   - ErrElemMatchProjectionArg when argument is not a document.
+func validateElemMatchProjection(path types.Path, arg any) error {
+	if path.Len() > 1 {
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrElemMatchProjectionNested,
+			"Cannot use $elemMatch projection on a nested field.",
+			"projection",
+		)
+	}
+
+	if _, ok := arg.(*types.Document); !ok {
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrElemMatchProjectionArg,
+			fmt.Sprintf(
+				"elemMatch: Invalid argument, object required, but got %s",
+				commonparams.AliasFromType(arg),
+			),
+			"projection",
+		)
+	}
+
+	return nil
+}
+
+// elemMatchProjection returns an array with the first element of the array field key in doc
+// that matches $elemMatch condition.
+// It returns nil if the field is not an array or no element matches, such field is not projected.
+//
+// The condition is matched against document elements, or against any elements
+// if it uses query operators like `{$gt: 42}`.
+//
+// Example: "v" with condition {foo: 2}:
+//
+//	{v: [{foo: 1}, {foo: 2, bar: 1}, {foo: 2}]} -> {v: [{foo: 2, bar: 1}]}
+//	{v: [{foo: 1}]}                             -> {}
+func elemMatchProjection(doc *types.Document, key string, cond *types.Document) (*types.Array, error) {
+	v, err := doc.Get(key)
+	if err != nil {
+		return nil, nil
+	}
+
+	arr, ok := v.(*types.Array)
+	if !ok {
+		return nil, nil
+	}
+
+	var operators bool
+	if cond.Len() > 0 {
+		first := cond.Keys()[0]
+		operators = strings.HasPrefix(first, "$") && !slices.Contains([]string{"$and", "$or", "$nor"}, first)
+	}
+
+	for i := 0; i < arr.Len(); i++ {
+		elem := must.NotFail(arr.Get(i))
+
+		var matched bool
+
+		switch {
+		case operators:
+			matched, err = FilterDocument(
+				must.NotFail(types.NewDocument(key, elem)),
+				must.NotFail(types.NewDocument(key, cond)),
+			)
+		default:
+			elemDoc, ok := elem.(*types.Document)
+			if !ok {
+				continue
+			}
+
+			matched, err = FilterDocument(elemDoc, cond)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if matched {
+			return must.NotFail(types.NewArray(elem)), nil
+		}
+	}
+
+	return nil, nil
+}
+
 // setBySourceOrder sets the key value field to projected in same field order as the source.
 // Example:
 //
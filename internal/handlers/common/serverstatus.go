@@ -17,8 +17,13 @@ package common
 import (
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
 	"time"
 
+	"golang.org/x/exp/maps"
+
 	"github.com/FerretDB/FerretDB/build/version"
 	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
 	"github.com/FerretDB/FerretDB/internal/types"
@@ -28,7 +33,9 @@ import (
 )
 
 // ServerStatus returns a common part of serverStatus command response.
-func ServerStatus(state *state.State, cm *connmetrics.ConnMetrics) (*types.Document, error) {
+//
+// Now is returned as the server's local time and is used to calculate uptime.
+func ServerStatus(state *state.State, cm *connmetrics.ConnMetrics, now time.Time) (*types.Document, error) {
 	host, err := os.Hostname()
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -39,10 +46,22 @@ func ServerStatus(state *state.State, cm *connmetrics.ConnMetrics) (*types.Docum
 		return nil, lazyerrors.Error(err)
 	}
 
-	uptime := time.Since(state.Start)
+	uptime := now.Sub(state.Start)
 
 	metricsDoc := types.MakeDocument(0)
 
+	// FerretDB counts commands, not individual documents like MongoDB does
+	opcounters := map[string]int64{
+		"insert":  0,
+		"query":   0,
+		"update":  0,
+		"delete":  0,
+		"getmore": 0,
+		"command": 0,
+	}
+
+	var numRequests int64
+
 	metrics := cm.GetResponses()
 	for _, commands := range metrics {
 		for command, arguments := range commands {
@@ -57,9 +76,70 @@ func ServerStatus(state *state.State, cm *connmetrics.ConnMetrics) (*types.Docum
 
 			d := must.NotFail(types.NewDocument("total", int64(total), "failed", int64(failed)))
 			metricsDoc.Set(command, d)
+
+			opcounters[opcounter(command)] += int64(total)
+			numRequests += int64(total)
+		}
+	}
+
+	mechanisms := types.MakeDocument(0)
+
+	auths := cm.GetAuthentications()
+	for _, mechanism := range sortedKeys(auths) {
+		results := auths[mechanism]
+		mechanisms.Set(mechanism, must.NotFail(types.NewDocument(
+			"authenticate", must.NotFail(types.NewDocument(
+				"received", int64(results["ok"]+results["failed"]),
+				"successful", int64(results["ok"]),
+			)),
+		)))
+	}
+
+	// the same fields as MongoDB, plus "none" for plaintext connections
+	transportSecurity := must.NotFail(types.NewDocument(
+		"1.0", int64(0),
+		"1.1", int64(0),
+		"1.2", int64(0),
+		"1.3", int64(0),
+		"none", int64(0),
+		"unknown", int64(0),
+	))
+	ciphers := types.MakeDocument(0)
+
+	conns := cm.GetConnections()
+	for _, version := range sortedKeys(conns) {
+		for _, cipher := range sortedKeys(conns[version]) {
+			n := int64(conns[version][cipher])
+
+			key := version
+			if !transportSecurity.Has(key) {
+				key = "unknown"
+			}
+
+			transportSecurity.Set(key, must.NotFail(transportSecurity.Get(key)).(int64)+n)
+
+			if cipher == "none" {
+				continue
+			}
+
+			if v, _ := ciphers.Get(cipher); v != nil {
+				n += v.(int64)
+			}
+
+			ciphers.Set(cipher, n)
 		}
 	}
 
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	openConns := int32(cm.GetOpenConnections())
+
+	tlsMode := cm.GetTLSMode()
+	if tlsMode == "" {
+		tlsMode = connmetrics.TLSModeDisabled
+	}
+
 	res := must.NotFail(types.NewDocument(
 		"host", host,
 		"version", version.Get().MongoDBVersion,
@@ -68,13 +148,45 @@ func ServerStatus(state *state.State, cm *connmetrics.ConnMetrics) (*types.Docum
 		"uptime", uptime.Seconds(),
 		"uptimeMillis", uptime.Milliseconds(),
 		"uptimeEstimate", int64(uptime.Seconds()),
-		"localTime", time.Now(),
+		"localTime", now,
+		"connections", must.NotFail(types.NewDocument(
+			"current", openConns,
+			"available", maxIncomingConnections-openConns,
+			"totalCreated", int64(totalConnections(conns)),
+		)),
 		"freeMonitoring", must.NotFail(types.NewDocument(
 			"state", state.TelemetryString(),
 		)),
+		"mem", must.NotFail(types.NewDocument(
+			"bits", int32(strconv.IntSize),
+			"resident", int32((ms.Sys-ms.HeapReleased)>>20),
+			"virtual", int32(ms.Sys>>20),
+			"supported", true,
+		)),
 		"metrics", must.NotFail(types.NewDocument(
 			"commands", metricsDoc,
 		)),
+		"network", must.NotFail(types.NewDocument(
+			"numRequests", numRequests,
+		)),
+		"opcounters", must.NotFail(types.NewDocument(
+			"insert", opcounters["insert"],
+			"query", opcounters["query"],
+			"update", opcounters["update"],
+			"delete", opcounters["delete"],
+			"getmore", opcounters["getmore"],
+			"command", opcounters["command"],
+		)),
+		"security", must.NotFail(types.NewDocument(
+			"authentication", must.NotFail(types.NewDocument(
+				"mechanisms", mechanisms,
+			)),
+			"tls", must.NotFail(types.NewDocument(
+				"mode", tlsMode,
+				"ciphers", ciphers,
+			)),
+		)),
+		"transportSecurity", transportSecurity,
 
 		// our extensions
 		"ferretdbVersion", version.Get().Version,
@@ -84,3 +196,42 @@ func ServerStatus(state *state.State, cm *connmetrics.ConnMetrics) (*types.Docum
 
 	return res, nil
 }
+
+// maxIncomingConnections is the number of connections reported as available in total,
+// the same as MongoDB's default; FerretDB itself does not limit the number of connections.
+const maxIncomingConnections = 1_000_000
+
+// opcounter returns serverStatus opcounters field name for the given command.
+func opcounter(command string) string {
+	switch command {
+	case "insert", "update", "delete":
+		return command
+	case "find":
+		return "query"
+	case "getMore":
+		return "getmore"
+	default:
+		return "command"
+	}
+}
+
+// totalConnections returns the total number of established connections.
+func totalConnections(conns map[string]map[string]int) int {
+	var res int
+
+	for _, ciphers := range conns {
+		for _, n := range ciphers {
+			res += n
+		}
+	}
+
+	return res
+}
+
+// sortedKeys returns sorted keys of the given map.
+func sortedKeys[V any](m map[string]V) []string {
+	res := maps.Keys(m)
+	slices.Sort(res)
+
+	return res
+}
@@ -31,12 +31,50 @@ import (
 //
 // If sort path is invalid, it returns a possibly wrapped types.PathError.
 func SortDocuments(docs []*types.Document, sortDoc *types.Document) error {
-	if sortDoc.Len() == 0 {
+	sortFuncs, err := getSortFuncs(sortDoc)
+	if err != nil {
+		return err
+	}
+
+	if len(sortFuncs) == 0 {
+		// no keys to sort by
 		return nil
 	}
 
+	sorter := &docsSorter{docs: docs, sorts: sortFuncs}
+	sort.Sort(sorter)
+
+	return nil
+}
+
+// SortLess returns a function that reports whether document a must sort before document b
+// according to the given sorting conditions.
+// It returns nil function if there are no keys to sort by.
+//
+// If sort path is invalid, it returns a possibly wrapped types.PathError.
+func SortLess(sortDoc *types.Document) (func(a, b *types.Document) bool, error) {
+	sortFuncs, err := getSortFuncs(sortDoc)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sortFuncs) == 0 {
+		return nil, nil
+	}
+
+	return func(a, b *types.Document) bool {
+		return lessAll(sortFuncs, a, b)
+	}, nil
+}
+
+// getSortFuncs returns sort functions for each key of the given sorting conditions.
+func getSortFuncs(sortDoc *types.Document) ([]sortFunc, error) {
+	if sortDoc.Len() == 0 {
+		return nil, nil
+	}
+
 	if sortDoc.Len() > 32 {
-		return lazyerrors.Errorf("maximum sort keys exceeded: %v", sortDoc.Len())
+		return nil, lazyerrors.Errorf("maximum sort keys exceeded: %v", sortDoc.Len())
 	}
 
 	sortFuncs := make([]sortFunc, len(sortDoc.Keys()))
@@ -45,7 +83,7 @@ func SortDocuments(docs []*types.Document, sortDoc *types.Document) error {
 		fields := strings.Split(sortKey, ".")
 		for _, field := range fields {
 			if strings.HasPrefix(field, "$") {
-				return commonerrors.NewCommandErrorMsgWithArgument(
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
 					commonerrors.ErrFieldPathInvalidName,
 					"FieldPath field names may not start with '$'. Consider using $getField or $setField.",
 					"sort",
@@ -57,26 +95,18 @@ func SortDocuments(docs []*types.Document, sortDoc *types.Document) error {
 
 		sortType, err := GetSortType(sortKey, sortField)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		sortPath, err := types.NewPathFromString(sortKey)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		sortFuncs[i] = lessFunc(sortPath, sortType)
 	}
 
-	if len(sortFuncs) == 0 {
-		// no keys to sort by
-		return nil
-	}
-
-	sorter := &docsSorter{docs: docs, sorts: sortFuncs}
-	sort.Sort(sorter)
-
-	return nil
+	return sortFuncs, nil
 }
 
 // lessFunc takes sort key and type and returns sort.Interface's Less function which
@@ -118,11 +148,15 @@ func (ds *docsSorter) Swap(i, j int) {
 }
 
 func (ds *docsSorter) Less(i, j int) bool {
-	p, q := ds.docs[i], ds.docs[j]
+	return lessAll(ds.sorts, ds.docs[i], ds.docs[j])
+}
+
+// lessAll reports whether document p must sort before document q using given sort functions in order.
+func lessAll(sorts []sortFunc, p, q *types.Document) bool {
 	// Try all but the last comparison.
 	var k int
-	for k = 0; k < len(ds.sorts)-1; k++ {
-		sortFunc := ds.sorts[k]
+	for k = 0; k < len(sorts)-1; k++ {
+		sortFunc := sorts[k]
 
 		switch {
 		case sortFunc(p, q):
@@ -136,7 +170,7 @@ func (ds *docsSorter) Less(i, j int) bool {
 	}
 	// All comparisons to here said "equal", so just return whatever
 	// the final comparison reports.
-	return ds.sorts[k](p, q)
+	return sorts[k](p, q)
 }
 
 // GetSortType determines SortType from input sort value.
@@ -34,13 +34,19 @@ import (
 // UpdateDocument updates the given document with a series of update operators.
 // Returns true if document was changed.
 // To validate update document, must call ValidateUpdateOperators before calling UpdateDocument.
+// Paths with `$[]` and `$[<identifier>]` positional operators are expanded using the given array filters.
+// The given time is used by $currentDate operator.
 // UpdateDocument returns CommandError for findAndModify case-insensitive command name,
 // WriteError for other commands.
 // TODO https://github.com/FerretDB/FerretDB/issues/3013
-func UpdateDocument(command string, doc, update *types.Document) (bool, error) {
+func UpdateDocument(command string, doc, update *types.Document, arrayFilters ArrayFilters, now time.Time) (bool, error) {
 	var changed bool
 	var err error
 
+	if update, err = arrayFilters.expand(command, doc, update); err != nil {
+		return false, err
+	}
+
 	if update.Len() == 0 {
 		// replace to empty doc
 		for _, key := range doc.Keys() {
@@ -57,21 +63,24 @@ func UpdateDocument(command string, doc, update *types.Document) (bool, error) {
 	for _, updateOp := range update.Keys() {
 		updateV := must.NotFail(update.Get(updateOp))
 
+		// each operator reports its own change, the document is changed if any of them changed it
+		var opChanged bool
+
 		switch updateOp {
 		case "$currentDate":
-			changed, err = processCurrentDateFieldExpression(doc, updateV)
+			opChanged, err = processCurrentDateFieldExpression(command, doc, updateV, now)
 			if err != nil {
 				return false, err
 			}
 
 		case "$set":
-			changed, err = processSetFieldExpression(command, doc, updateV.(*types.Document), false)
+			opChanged, err = processSetFieldExpression(command, doc, updateV.(*types.Document), false)
 			if err != nil {
 				return false, err
 			}
 
 		case "$setOnInsert":
-			changed, err = processSetFieldExpression(command, doc, updateV.(*types.Document), true)
+			opChanged, err = processSetFieldExpression(command, doc, updateV.(*types.Document), true)
 			if err != nil {
 				return false, err
 			}
@@ -91,75 +100,72 @@ func UpdateDocument(command string, doc, update *types.Document) (bool, error) {
 
 				if doc.HasByPath(path) {
 					doc.RemoveByPath(path)
-					changed = true
+					opChanged = true
 				}
 			}
 
 		case "$inc":
-			changed, err = processIncFieldExpression(command, doc, updateV)
+			opChanged, err = processIncFieldExpression(command, doc, updateV)
 			if err != nil {
 				return false, err
 			}
 
 		case "$max":
-			changed, err = processMaxFieldExpression(command, doc, updateV)
+			opChanged, err = processMaxFieldExpression(command, doc, updateV)
 			if err != nil {
 				return false, err
 			}
 
 		case "$min":
-			changed, err = processMinFieldExpression(command, doc, updateV)
+			opChanged, err = processMinFieldExpression(command, doc, updateV)
 			if err != nil {
 				return false, err
 			}
 
 		case "$mul":
-			var mulChanged bool
-
-			if mulChanged, err = processMulFieldExpression(command, doc, updateV); err != nil {
+			opChanged, err = processMulFieldExpression(command, doc, updateV)
+			if err != nil {
 				return false, err
 			}
 
-			changed = changed || mulChanged
-
 		case "$rename":
-			changed, err = processRenameFieldExpression(command, doc, updateV.(*types.Document))
+			opChanged, err = processRenameFieldExpression(command, doc, updateV.(*types.Document))
 			if err != nil {
 				return false, err
 			}
 
 		case "$pop":
-			changed, err = processPopArrayUpdateExpression(doc, updateV.(*types.Document))
+			opChanged, err = processPopArrayUpdateExpression(doc, updateV.(*types.Document))
 			if err != nil {
 				return false, err
 			}
 
 		case "$push":
-			changed, err = processPushArrayUpdateExpression(doc, updateV.(*types.Document))
+			opChanged, err = processPushArrayUpdateExpression(doc, updateV.(*types.Document))
 			if err != nil {
 				return false, err
 			}
 
 		case "$addToSet":
-			changed, err = processAddToSetArrayUpdateExpression(doc, updateV.(*types.Document))
+			opChanged, err = processAddToSetArrayUpdateExpression(doc, updateV.(*types.Document))
 			if err != nil {
 				return false, err
 			}
 
 		case "$pullAll":
-			changed, err = processPullAllArrayUpdateExpression(doc, updateV.(*types.Document))
+			opChanged, err = processPullAllArrayUpdateExpression(doc, updateV.(*types.Document))
 			if err != nil {
 				return false, err
 			}
 
 		case "$pull":
-			changed, err = processPullArrayUpdateExpression(doc, updateV.(*types.Document))
+			opChanged, err = processPullArrayUpdateExpression(doc, updateV.(*types.Document))
 			if err != nil {
 				return false, err
 			}
 
 		case "$bit":
-			changed, err = processBitFieldExpression(command, doc, updateV.(*types.Document))
+			opChanged, err = processBitFieldExpression(command, doc, updateV.(*types.Document))
 			if err != nil {
 				return false, err
 			}
@@ -186,8 +192,10 @@ func UpdateDocument(command string, doc, update *types.Document) (bool, error) {
 				doc.Set(setKey, setValue)
 			}
 
-			changed = true
+			opChanged = true
 		}
+
+		changed = changed || opChanged
 	}
 
 	return changed, nil
@@ -415,9 +423,9 @@ func processIncFieldExpression(command string, doc *types.Document, updateV any)
 			return false, newUpdateError(
 				commonerrors.ErrBadValue,
 				fmt.Sprintf(
-					`Failed to apply $inc operations to current value ((NumberLong)%d) for document {_id: "%s"}`,
+					`Failed to apply $inc operations to current value ((NumberLong)%d) for document {_id: %s}`,
 					docValue,
-					must.NotFail(doc.Get("_id")),
+					types.FormatAnyValue(must.NotFail(doc.Get("_id"))),
 				),
 				command,
 			)
@@ -425,9 +433,9 @@ func processIncFieldExpression(command string, doc *types.Document, updateV any)
 			return false, newUpdateError(
 				commonerrors.ErrBadValue,
 				fmt.Sprintf(
-					`Failed to apply $inc operations to current value ((NumberInt)%d) for document {_id: "%s"}`,
+					`Failed to apply $inc operations to current value ((NumberInt)%d) for document {_id: %s}`,
 					docValue,
-					must.NotFail(doc.Get("_id")),
+					types.FormatAnyValue(must.NotFail(doc.Get("_id"))),
 				),
 				command,
 			)
@@ -686,9 +694,9 @@ func processMulFieldExpression(command string, doc *types.Document, updateV any)
 			return false, newUpdateError(
 				commonerrors.ErrBadValue,
 				fmt.Sprintf(
-					`Failed to apply $mul operations to current value ((NumberLong)%d) for document {_id: "%s"}`,
+					`Failed to apply $mul operations to current value ((NumberLong)%d) for document {_id: %s}`,
 					docValue,
-					must.NotFail(doc.Get("_id")),
+					types.FormatAnyValue(must.NotFail(doc.Get("_id"))),
 				),
 				command,
 			)
@@ -696,9 +704,9 @@ func processMulFieldExpression(command string, doc *types.Document, updateV any)
 			return false, newUpdateError(
 				commonerrors.ErrBadValue,
 				fmt.Sprintf(
-					`Failed to apply $mul operations to current value ((NumberInt)%d) for document {_id: "%s"}`,
+					`Failed to apply $mul operations to current value ((NumberInt)%d) for document {_id: %s}`,
 					docValue,
-					must.NotFail(doc.Get("_id")),
+					types.FormatAnyValue(must.NotFail(doc.Get("_id"))),
 				),
 				command,
 			)
@@ -712,42 +720,52 @@ func processMulFieldExpression(command string, doc *types.Document, updateV any)
 
 // processCurrentDateFieldExpression changes document according to $currentDate operator.
 // If the document was changed it returns true.
-func processCurrentDateFieldExpression(doc *types.Document, currentDateVal any) (bool, error) {
+func processCurrentDateFieldExpression(command string, doc *types.Document, currentDateVal any, now time.Time) (bool, error) {
 	var changed bool
 	currentDateExpression := currentDateVal.(*types.Document)
 
-	now := time.Now().UTC()
+	now = now.UTC()
 	keys := currentDateExpression.Keys()
 	sort.Strings(keys)
 
 	for _, field := range keys {
 		currentDateField := must.NotFail(currentDateExpression.Get(field))
 
+		var value any
+
 		switch currentDateField := currentDateField.(type) {
 		case *types.Document:
 			currentDateType, err := currentDateField.Get("$type")
 			if err != nil { // default is date
-				doc.Set(field, now)
-				changed = true
-				continue
+				value = now
+				break
 			}
 
-			currentDateType = currentDateType.(string)
-			switch currentDateType {
+			switch currentDateType.(string) {
 			case "timestamp":
-				doc.Set(field, types.NextTimestamp(now))
-				changed = true
-
+				value = types.NextTimestamp(now)
 			case "date":
-				doc.Set(field, now)
-				changed = true
+				value = now
 			}
 
 		case bool:
-			doc.Set(field, now)
-			changed = true
+			value = now
+		}
+
+		if value == nil {
+			continue
 		}
+
+		// field has valid path, checked in ValidateUpdateOperators.
+		path := must.NotFail(types.NewPathFromString(field))
+
+		if err := doc.SetByPath(path, value); err != nil {
+			return false, newUpdateError(commonerrors.ErrUnsuitableValueType, err.Error(), command)
+		}
+
+		changed = true
 	}
+
 	return changed, nil
 }
 
@@ -17,7 +17,11 @@ package common
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"strconv"
 
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/operators"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
 	"github.com/FerretDB/FerretDB/internal/types"
@@ -109,30 +113,37 @@ func processPopArrayUpdateExpression(doc *types.Document, update *types.Document
 // For example, if the path is "v.foo" and:
 //   - doc is {v: 42}, it returns ErrUnsuitableValueType, v is used by unsuitable value type;
 //   - doc is {c: 10}, it returns no error since the path does not exist.
+//
+// Arrays are traversed by indexes; other keys can't be used to traverse them.
 func checkUnsuitableValueError(doc *types.Document, key string, path types.Path) error {
-	// return no error if path is suffix or key.
-	if path.Len() == 1 {
-		return nil
-	}
+	elems := path.Slice()
 
-	prefix := path.Prefix()
+	// find the deepest existing value on the path
+	for i := len(elems) - 1; i > 0; i-- {
+		val, err := doc.GetByPath(types.NewStaticPath(elems[:i]...))
+		if err != nil {
+			continue
+		}
+
+		switch val.(type) {
+		case *types.Document:
+			// the next element is just missing
+			return nil
 
-	// check if part of the path exists in the document.
-	if doc.Has(prefix) {
-		val := must.NotFail(doc.Get(prefix))
-		if prefixDoc, ok := val.(*types.Document); ok {
-			// recursively check if document contains part of the part.
-			return checkUnsuitableValueError(prefixDoc, key, path.TrimPrefix())
+		case *types.Array:
+			if _, err = strconv.Atoi(elems[i]); err == nil {
+				// the index is out of bounds
+				return nil
+			}
 		}
 
-		// ErrUnsuitableValueType is returned if the document contains prefix.
 		return commonerrors.NewWriteErrorMsg(
 			commonerrors.ErrUnsuitableValueType,
 			fmt.Sprintf(
 				"Cannot use the part (%s) of (%s) to traverse the element ({%s: %v})",
-				path.Slice()[1],
+				elems[i],
 				key,
-				prefix,
+				elems[i-1],
 				types.FormatAnyValue(val),
 			),
 		)
@@ -160,22 +171,11 @@ func processPushArrayUpdateExpression(doc *types.Document, update *types.Documen
 			return false, lazyerrors.Error(err)
 		}
 
-		var each *types.Array
+		var modifiers *pushModifiers
 
-		if pushValue, ok := pushValueRaw.(*types.Document); ok {
-			if pushValue.Has("$each") {
-				eachRaw := must.NotFail(pushValue.Get("$each"))
-
-				each, ok = eachRaw.(*types.Array)
-				if !ok {
-					return false, commonerrors.NewWriteErrorMsg(
-						commonerrors.ErrBadValue,
-						fmt.Sprintf(
-							"The argument to $each in $push must be an array but it was of type: %s",
-							commonparams.AliasFromType(eachRaw),
-						),
-					)
-				}
+		if pushValue, ok := pushValueRaw.(*types.Document); ok && pushValue.Has("$each") {
+			if modifiers, err = parsePushModifiers(pushValue); err != nil {
+				return false, err
 			}
 		}
 
@@ -212,18 +212,17 @@ func processPushArrayUpdateExpression(doc *types.Document, update *types.Documen
 			)
 		}
 
-		if each == nil {
-			each = types.MakeArray(1)
-			each.Append(pushValueRaw)
+		if modifiers == nil {
+			modifiers = &pushModifiers{each: must.NotFail(types.NewArray(pushValueRaw))}
 		}
 
-		for i := 0; i < each.Len(); i++ {
-			array.Append(must.NotFail(each.Get(i)))
+		pushed := modifiers.apply(array)
 
+		if array.Len() != pushed.Len() || types.Compare(array, pushed) != types.Equal {
 			changed = true
 		}
 
-		if err = doc.SetByPath(path, array); err != nil {
+		if err = doc.SetByPath(path, pushed); err != nil {
 			return false, lazyerrors.Error(err)
 		}
 	}
@@ -231,6 +230,239 @@ func processPushArrayUpdateExpression(doc *types.Document, update *types.Documen
 	return changed, nil
 }
 
+// pushModifiers represents `$each`, `$position`, `$slice` and `$sort` modifiers of $push operator.
+type pushModifiers struct {
+	each     *types.Array
+	position *int64
+	slice    *int64
+
+	// sortFields is set for sorting documents by their fields;
+	// otherwise, if sortOrder is set, whole elements are sorted.
+	sortFields *types.Document
+	sortOrder  types.SortType
+}
+
+// parsePushModifiers validates $push modifiers document containing `$each`.
+func parsePushModifiers(pushValue *types.Document) (*pushModifiers, error) {
+	var res pushModifiers
+
+	for _, key := range pushValue.Keys() {
+		v := must.NotFail(pushValue.Get(key))
+
+		switch key {
+		case "$each":
+			each, ok := v.(*types.Array)
+			if !ok {
+				return nil, commonerrors.NewWriteErrorMsg(
+					commonerrors.ErrBadValue,
+					fmt.Sprintf(
+						"The argument to $each in $push must be an array but it was of type: %s",
+						commonparams.AliasFromType(v),
+					),
+				)
+			}
+
+			res.each = each
+
+		case "$position":
+			position, err := commonparams.GetWholeNumberParam(v)
+			if err != nil {
+				return nil, commonerrors.NewWriteErrorMsg(
+					commonerrors.ErrBadValue,
+					fmt.Sprintf(
+						"The value for $position must be an integer value, not of type: %s",
+						commonparams.AliasFromType(v),
+					),
+				)
+			}
+
+			res.position = &position
+
+		case "$slice":
+			slice, err := commonparams.GetWholeNumberParam(v)
+			if err != nil {
+				return nil, commonerrors.NewWriteErrorMsg(
+					commonerrors.ErrBadValue,
+					fmt.Sprintf(
+						"The value for $slice must be an integer value but was given type: %s",
+						commonparams.AliasFromType(v),
+					),
+				)
+			}
+
+			res.slice = &slice
+
+		case "$sort":
+			if err := res.parseSort(v); err != nil {
+				return nil, err
+			}
+
+		default:
+			return nil, commonerrors.NewWriteErrorMsg(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf("Unrecognized clause in $push: %s", key),
+			)
+		}
+	}
+
+	return &res, nil
+}
+
+// parseSort validates `$sort` modifier value.
+// It is either 1 or -1 for sorting whole elements, or a document of fields with 1 or -1 values.
+func (m *pushModifiers) parseSort(v any) error {
+	sortFields, ok := v.(*types.Document)
+	if !ok {
+		order, err := pushSortOrder(v)
+		if err != nil {
+			return commonerrors.NewWriteErrorMsg(
+				commonerrors.ErrBadValue,
+				"The $sort is invalid: use 1/-1 to sort the whole element, or {field:1/-1} to sort embedded fields",
+			)
+		}
+
+		m.sortOrder = order
+
+		return nil
+	}
+
+	if sortFields.Len() == 0 {
+		return commonerrors.NewWriteErrorMsg(
+			commonerrors.ErrBadValue,
+			"The $sort pattern is empty when it should be a set of fields.",
+		)
+	}
+
+	for _, key := range sortFields.Keys() {
+		if _, err := pushSortOrder(must.NotFail(sortFields.Get(key))); err != nil {
+			return commonerrors.NewWriteErrorMsg(
+				commonerrors.ErrBadValue,
+				"The $sort element value must be either 1 or -1",
+			)
+		}
+	}
+
+	m.sortFields = sortFields
+
+	return nil
+}
+
+// pushSortOrder returns sort order for 1 or -1 value of `$sort` modifier.
+func pushSortOrder(v any) (types.SortType, error) {
+	order, err := commonparams.GetWholeNumberParam(v)
+	if err != nil {
+		return 0, err
+	}
+
+	switch order {
+	case 1:
+		return types.Ascending, nil
+	case -1:
+		return types.Descending, nil
+	default:
+		return 0, lazyerrors.Errorf("invalid sort order %d", order)
+	}
+}
+
+// apply returns a new array with `$each` values inserted at `$position`,
+// then sorted according to `$sort` and limited by `$slice`.
+func (m *pushModifiers) apply(array *types.Array) *types.Array {
+	values := make([]any, 0, array.Len()+m.each.Len())
+
+	for i := 0; i < array.Len(); i++ {
+		values = append(values, must.NotFail(array.Get(i)))
+	}
+
+	position := len(values)
+
+	if m.position != nil {
+		switch p := *m.position; {
+		case p < 0:
+			position = max(len(values)+int(p), 0)
+		case p < int64(len(values)):
+			position = int(p)
+		}
+	}
+
+	each := make([]any, m.each.Len())
+	for i := range each {
+		each[i] = must.NotFail(m.each.Get(i))
+	}
+
+	values = slices.Insert(values, position, each...)
+
+	switch {
+	case m.sortFields != nil:
+		sort.SliceStable(values, func(i, j int) bool {
+			return pushFieldsLess(values[i], values[j], m.sortFields)
+		})
+	case m.sortOrder != 0:
+		sort.SliceStable(values, func(i, j int) bool {
+			res := types.CompareOrder(values[i], values[j], m.sortOrder)
+			if m.sortOrder == types.Descending {
+				return res == types.Greater
+			}
+
+			return res == types.Less
+		})
+	}
+
+	if m.slice != nil {
+		switch n := *m.slice; {
+		case n >= 0 && n < int64(len(values)):
+			values = values[:n]
+		case n < 0 && -n < int64(len(values)):
+			values = values[int64(len(values))+n:]
+		}
+	}
+
+	res := types.MakeArray(len(values))
+	for _, v := range values {
+		res.Append(v)
+	}
+
+	return res
+}
+
+// pushFieldsLess reports whether array element a sorts before b by the given document fields.
+// Elements that are not documents and missing fields are compared as null.
+func pushFieldsLess(a, b any, sortFields *types.Document) bool {
+	for _, key := range sortFields.Keys() {
+		order := must.NotFail(pushSortOrder(must.NotFail(sortFields.Get(key))))
+
+		// key was used as a document field name, so it is a valid path
+		path := must.NotFail(types.NewPathFromString(key))
+
+		aField, bField := pushSortField(a, path), pushSortField(b, path)
+
+		switch types.CompareOrderForSort(aField, bField, order) {
+		case types.Equal:
+			continue
+		case types.Less:
+			return order == types.Ascending
+		case types.Greater:
+			return order == types.Descending
+		}
+	}
+
+	return false
+}
+
+// pushSortField returns the field value of array element for sorting, or null.
+func pushSortField(elem any, path types.Path) any {
+	doc, ok := elem.(*types.Document)
+	if !ok {
+		return types.Null
+	}
+
+	v, err := doc.GetByPath(path)
+	if err != nil {
+		return types.Null
+	}
+
+	return v
+}
+
 // processAddToSetArrayUpdateExpression changes document according to $addToSet array update operator.
 // If the document was changed it returns true.
 func processAddToSetArrayUpdateExpression(doc, update *types.Document) (bool, error) {
@@ -265,6 +497,16 @@ func processAddToSetArrayUpdateExpression(doc, update *types.Document) (bool, er
 						),
 					)
 				}
+
+				if addToSetValue.Len() > 1 {
+					return false, commonerrors.NewWriteErrorMsg(
+						commonerrors.ErrBadValue,
+						fmt.Sprintf(
+							"Found unexpected fields after $each in $addToSet: %s",
+							types.FormatAnyValue(addToSetValue),
+						),
+					)
+				}
 			}
 		}
 
@@ -470,7 +712,12 @@ func processPullArrayUpdateExpression(doc *types.Document, update *types.Documen
 		for i := array.Len() - 1; i >= 0; i-- {
 			value := must.NotFail(array.Get(i))
 
-			if types.Compare(value, pullValueRaw) == types.Equal {
+			matches, err := pullMatches(value, pullValueRaw)
+			if err != nil {
+				return false, err
+			}
+
+			if matches {
 				array.Remove(i)
 
 				changed = true
@@ -484,3 +731,29 @@ func processPullArrayUpdateExpression(doc *types.Document, update *types.Documen
 
 	return changed, nil
 }
+
+// pullMatches returns true if array element value matches $pull condition.
+//
+// The condition document with query operators (like `{$gte: 6}`) is applied to the value itself,
+// other condition documents are applied as a query to document values.
+// Other conditions are compared for equality.
+func pullMatches(value, condition any) (bool, error) {
+	condDoc, ok := condition.(*types.Document)
+	if !ok || condDoc.Len() == 0 {
+		return types.Compare(value, condition) == types.Equal, nil
+	}
+
+	if operators.IsOperator(condDoc) {
+		return FilterDocument(
+			must.NotFail(types.NewDocument("value", value)),
+			must.NotFail(types.NewDocument("value", condDoc)),
+		)
+	}
+
+	valueDoc, ok := value.(*types.Document)
+	if !ok {
+		return false, nil
+	}
+
+	return FilterDocument(valueDoc, condDoc)
+}
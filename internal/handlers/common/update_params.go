@@ -32,7 +32,7 @@ type UpdateParams struct {
 
 	Comment string `ferretdb:"comment,opt"`
 
-	Let *types.Document `ferretdb:"let,unimplemented"`
+	Let *types.Document `ferretdb:"let,opt"`
 
 	Ordered                  bool            `ferretdb:"ordered,ignored"`
 	BypassDocumentValidation bool            `ferretdb:"bypassDocumentValidation,ignored"`
@@ -51,7 +51,9 @@ type Update struct {
 
 	C            *types.Document `ferretdb:"c,unimplemented"`
 	Collation    *types.Document `ferretdb:"collation,unimplemented"`
-	ArrayFilters *types.Array    `ferretdb:"arrayFilters,unimplemented"`
+	ArrayFilters *types.Array    `ferretdb:"arrayFilters,opt"`
+
+	ParsedArrayFilters ArrayFilters `ferretdb:"-"`
 
 	Hint string `ferretdb:"hint,ignored"`
 }
@@ -66,7 +68,7 @@ func GetUpdateParams(document *types.Document, l *zap.Logger) (*UpdateParams, er
 	}
 
 	if len(params.Updates) > 0 {
-		for _, update := range params.Updates {
+		for i, update := range params.Updates {
 			if update.Update == nil {
 				continue
 			}
@@ -74,6 +76,13 @@ func GetUpdateParams(document *types.Document, l *zap.Logger) (*UpdateParams, er
 			if err := ValidateUpdateOperators(document.Command(), update.Update); err != nil {
 				return nil, err
 			}
+
+			arrayFilters, err := NewArrayFilters(document.Command(), update.ArrayFilters, update.Update)
+			if err != nil {
+				return nil, err
+			}
+
+			params.Updates[i].ParsedArrayFilters = arrayFilters
 		}
 	}
 
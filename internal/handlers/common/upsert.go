@@ -0,0 +1,153 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// UpsertDocument returns the base document for upsert synthesized from equality conditions of the given filter.
+// Top-level `field: value` and `field: {$eq: value}` conditions are used,
+// including those inside `$and` and a single-element `$or`.
+// Dotted paths create embedded documents. Other conditions are ignored.
+//
+// It returns an error if the same path or overlapping paths are matched more than once.
+func UpsertDocument(command string, filter *types.Document) (*types.Document, error) {
+	doc := must.NotFail(types.NewDocument())
+
+	if filter == nil {
+		return doc, nil
+	}
+
+	var paths []string
+
+	if err := collectEqualities(command, filter, doc, &paths); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// collectEqualities sets equality conditions of the filter to the document.
+// Paths contains already set paths and it is used to detect conflicts.
+func collectEqualities(command string, filter, doc *types.Document, paths *[]string) error {
+	for _, key := range filter.Keys() {
+		v := must.NotFail(filter.Get(key))
+
+		switch key {
+		case "$and", "$or":
+			exprs, ok := v.(*types.Array)
+			if !ok || (key == "$or" && exprs.Len() != 1) {
+				continue
+			}
+
+			for i := 0; i < exprs.Len(); i++ {
+				expr, ok := must.NotFail(exprs.Get(i)).(*types.Document)
+				if !ok {
+					continue
+				}
+
+				if err := collectEqualities(command, expr, doc, paths); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		if strings.HasPrefix(key, "$") {
+			continue
+		}
+
+		value, ok := equalityValue(v)
+		if !ok {
+			continue
+		}
+
+		for _, p := range *paths {
+			switch {
+			case p == key:
+				return newUpdateError(
+					commonerrors.ErrNotSingleValueField,
+					fmt.Sprintf("cannot infer query fields to set, path '%s' is matched twice", key),
+					command,
+				)
+			case strings.HasPrefix(key, p+"."), strings.HasPrefix(p, key+"."):
+				return newUpdateError(
+					commonerrors.ErrNotSingleValueField,
+					fmt.Sprintf("cannot infer query fields to set, both paths '%s' and '%s' are matched", p, key),
+					command,
+				)
+			}
+		}
+
+		*paths = append(*paths, key)
+
+		path, err := types.NewPathFromString(key)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		// do not modify the command document
+		switch value := value.(type) {
+		case *types.Document:
+			err = doc.SetByPath(path, value.DeepCopy())
+		case *types.Array:
+			err = doc.SetByPath(path, value.DeepCopy())
+		default:
+			err = doc.SetByPath(path, value)
+		}
+
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// equalityValue returns the value the field must be equal to for the given filter condition.
+// It returns false if the condition is not an equality.
+func equalityValue(v any) (any, bool) {
+	switch v := v.(type) {
+	case *types.Document:
+		if v.Len() == 0 || !strings.HasPrefix(v.Keys()[0], "$") {
+			return v, true
+		}
+
+		eq, err := v.Get("$eq")
+		if err != nil {
+			return nil, false
+		}
+
+		if _, ok := eq.(types.Regex); ok {
+			return nil, false
+		}
+
+		return eq, true
+
+	case types.Regex:
+		return nil, false
+
+	default:
+		return v, true
+	}
+}
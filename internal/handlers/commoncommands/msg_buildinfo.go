@@ -19,19 +19,39 @@ import (
 	"strconv"
 
 	"github.com/FerretDB/FerretDB/build/version"
+	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/stages"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/state"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // MsgBuildInfo is a common implementation of the buildInfo command.
-func MsgBuildInfo(context.Context, *wire.OpMsg) (*wire.OpMsg, error) {
+//
+// Backend capabilities are taken from the given state.
+func MsgBuildInfo(_ context.Context, _ *wire.OpMsg, s *state.State) (*wire.OpMsg, error) {
 	aggregationStages := types.MakeArray(len(stages.Stages))
 	for stage := range stages.Stages {
 		aggregationStages.Append(stage)
 	}
 
+	capabilities := must.NotFail(types.NewDocument(
+		"backend", s.BackendName,
+		"backendVersion", s.BackendVersion,
+	))
+
+	for _, c := range backends.Capabilities {
+		v, ok := s.BackendCapabilities[c]
+
+		doc := must.NotFail(types.NewDocument("available", ok))
+		if v != "" {
+			doc.Set("version", v)
+		}
+
+		capabilities.Set(c, doc)
+	}
+
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.OpMsgSection{
 		Documents: []*types.Document{must.NotFail(types.NewDocument(
@@ -50,6 +70,7 @@ func MsgBuildInfo(context.Context, *wire.OpMsg) (*wire.OpMsg, error) {
 			"ferretdbFeatures", must.NotFail(types.NewDocument(
 				"aggregationStages", aggregationStages,
 			)),
+			"ferretdbCapabilities", capabilities,
 
 			"ok", float64(1),
 		))},
@@ -67,6 +67,10 @@ var Commands = map[string]command{
 		Help:    "Reduces the disk space collection takes and refreshes its statistics.",
 		Handler: handlers.Interface.MsgCompact,
 	},
+	"configureFailPoint": {
+		Help:    "Enables, disables or configures a fail point for testing.",
+		Handler: handlers.Interface.MsgConfigureFailPoint,
+	},
 	"connectionStatus": {
 		Help: "Returns information about the current connection, " +
 			"specifically the state of authenticated users and their available permissions.",
@@ -111,6 +115,10 @@ var Commands = map[string]command{
 		Help:    "Returns an array of distinct values for the given field.",
 		Handler: handlers.Interface.MsgDistinct,
 	},
+	"documentHistory": {
+		Help:    "Lists or purges previous versions of documents kept for the collection.",
+		Handler: handlers.Interface.MsgDocumentHistory,
+	},
 	"drop": {
 		Help:    "Drops the collection.",
 		Handler: handlers.Interface.MsgDrop,
@@ -123,10 +131,18 @@ var Commands = map[string]command{
 		Help:    "Drops indexes on a collection.",
 		Handler: handlers.Interface.MsgDropIndexes,
 	},
+	"endSessions": {
+		Help:    "Marks sessions as expired.",
+		Handler: handlers.Interface.MsgEndSessions,
+	},
 	"explain": {
 		Help:    "Returns the execution plan.",
 		Handler: handlers.Interface.MsgExplain,
 	},
+	"export": {
+		Help:    "Exports documents from the collection to Parquet file.",
+		Handler: handlers.Interface.MsgExport,
+	},
 	"find": {
 		Help:    "Returns documents matched by the query.",
 		Handler: handlers.Interface.MsgFind,
@@ -142,6 +158,10 @@ var Commands = map[string]command{
 		Help:    "Returns a summary of all runtime and configuration options.",
 		Handler: handlers.Interface.MsgGetCmdLineOpts,
 	},
+	"getDiagnosticData": {
+		Help:    "Returns diagnostic data snapshot in the format similar to MongoDB's FTDC.",
+		Handler: handlers.Interface.MsgGetDiagnosticData,
+	},
 	"getFreeMonitoringStatus": {
 		Help:    "Returns a status of the free monitoring.",
 		Handler: handlers.Interface.MsgGetFreeMonitoringStatus,
@@ -166,6 +186,10 @@ var Commands = map[string]command{
 		Help:    "Returns a summary of the system information.",
 		Handler: handlers.Interface.MsgHostInfo,
 	},
+	"import": {
+		Help:    "Imports documents from JSON or CSV file or URL into the collection.",
+		Handler: handlers.Interface.MsgImport,
+	},
 	"insert": {
 		Help:    "Docs documents into the database.",
 		Handler: handlers.Interface.MsgInsert,
@@ -177,6 +201,10 @@ var Commands = map[string]command{
 	"ismaster": { // old lowercase variant
 		Handler: handlers.Interface.MsgIsMaster,
 	},
+	"jobs": {
+		Help:    "Lists, pauses or resumes background jobs.",
+		Handler: handlers.Interface.MsgJobs,
+	},
 	"killCursors": {
 		Help:    "Closes server cursors.",
 		Handler: handlers.Interface.MsgKillCursors,
@@ -205,6 +233,10 @@ var Commands = map[string]command{
 		Help:    "Returns a pong response.",
 		Handler: handlers.Interface.MsgPing,
 	},
+	"refreshMaterializedView": {
+		Help:    "Replaces documents of the materialized view with its pipeline results.",
+		Handler: handlers.Interface.MsgRefreshMaterializedView,
+	},
 	"renameCollection": {
 		Help:    "Changes the name of an existing collection.",
 		Handler: handlers.Interface.MsgRenameCollection,
@@ -221,6 +253,14 @@ var Commands = map[string]command{
 		Help:    "Toggles free monitoring.",
 		Handler: handlers.Interface.MsgSetFreeMonitoring,
 	},
+	"top": {
+		Help:    "Returns usage statistics for each collection.",
+		Handler: handlers.Interface.MsgTop,
+	},
+	"trash": {
+		Help:    "Lists, restores or purges dropped collections in the trash.",
+		Handler: handlers.Interface.MsgTrash,
+	},
 	"update": {
 		Help:    "Updates documents that are matched by the query.",
 		Handler: handlers.Interface.MsgUpdate,
@@ -229,6 +269,10 @@ var Commands = map[string]command{
 		Help:    "Validate collection.",
 		Handler: handlers.Interface.MsgValidate,
 	},
+	"validateDBMetadata": {
+		Help:    "Validates FerretDB metadata against the backend schema and optionally repairs it.",
+		Handler: handlers.Interface.MsgValidateDBMetadata,
+	},
 	"whatsmyuri": {
 		Help:    "Returns peer information.",
 		Handler: handlers.Interface.MsgWhatsMyURI,
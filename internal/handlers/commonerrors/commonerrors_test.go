@@ -15,6 +15,8 @@
 package commonerrors
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"testing"
 
@@ -31,3 +33,49 @@ func TestErrorCodes(t *testing.T) {
 	assert.NotEmpty(t, errUnset.String())
 	assert.NotEmpty(t, errInternalError.String())
 }
+
+// testSQLStateError mimics PostgreSQL errors returned by backends.
+type testSQLStateError struct {
+	code string
+	msg  string
+}
+
+func (e testSQLStateError) Error() string    { return e.msg + " (SQLSTATE " + e.code + ")" }
+func (e testSQLStateError) SQLState() string { return e.code }
+
+func TestProtocolErrorTimeouts(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err      error
+		expected ErrorCode
+	}{
+		"Deadline": {
+			err:      fmt.Errorf("wrapped: %w", context.DeadlineExceeded),
+			expected: ErrMaxTimeMSExpired,
+		},
+		"StatementTimeout": {
+			err:      fmt.Errorf("wrapped: %w", testSQLStateError{"57014", "canceling statement due to statement timeout"}),
+			expected: ErrMaxTimeMSExpired,
+		},
+		"UserCancel": {
+			err:      fmt.Errorf("wrapped: %w", testSQLStateError{"57014", "canceling statement due to user request"}),
+			expected: ErrInterrupted,
+		},
+		"LockTimeout": {
+			err:      fmt.Errorf("wrapped: %w", testSQLStateError{"55P03", "canceling statement due to lock timeout"}),
+			expected: ErrLockTimeout,
+		},
+		"OtherSQLState": {
+			err:      testSQLStateError{"23505", "duplicate key value violates unique constraint"},
+			expected: errInternalError,
+		},
+		"Canceled": {
+			err:      context.Canceled,
+			expected: errInternalError,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ProtocolError(tc.err).(*CommandError).Code())
+		})
+	}
+}
@@ -16,7 +16,9 @@
 package commonerrors
 
 import (
+	"context"
 	"errors"
+	"strings"
 
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/wire"
@@ -51,6 +53,9 @@ const (
 	// ErrIllegalOperation indicated that operation is illegal.
 	ErrIllegalOperation = ErrorCode(20) // IllegalOperation
 
+	// ErrLockTimeout indicates that a lock could not be acquired in time.
+	ErrLockTimeout = ErrorCode(24) // LockTimeout
+
 	// ErrNamespaceNotFound indicates that a collection is not found.
 	ErrNamespaceNotFound = ErrorCode(26) // NamespaceNotFound
 
@@ -69,12 +74,18 @@ const (
 	// ErrNamespaceExists indicates that the collection already exists.
 	ErrNamespaceExists = ErrorCode(48) // NamespaceExists
 
+	// ErrMaxTimeMSExpired indicates that the operation exceeded its time limit.
+	ErrMaxTimeMSExpired = ErrorCode(50) // MaxTimeMSExpired
+
 	// ErrDollarPrefixedFieldName indicates the field name is prefixed with $.
 	ErrDollarPrefixedFieldName = ErrorCode(52) // DollarPrefixedFieldName
 
 	// ErrInvalidID indicates that _id field is invalid.
 	ErrInvalidID = ErrorCode(53) // InvalidID
 
+	// ErrNotSingleValueField indicates that the query matches the same path more than once.
+	ErrNotSingleValueField = ErrorCode(54) // NotSingleValueField
+
 	// ErrEmptyName indicates that the field name is empty.
 	ErrEmptyName = ErrorCode(56) // EmptyFieldName
 
@@ -102,6 +113,9 @@ const (
 	// ErrIndexKeySpecsConflict indicates that index build process failed due to key specs conflict.
 	ErrIndexKeySpecsConflict = ErrorCode(86) // IndexKeySpecsConflict
 
+	// ErrShutdownInProgress indicates that the server is shutting down.
+	ErrShutdownInProgress = ErrorCode(91) // ShutdownInProgress
+
 	// ErrOperationFailed indicates that the operation failed.
 	ErrOperationFailed = ErrorCode(96) // OperationFailed
 
@@ -120,12 +134,22 @@ const (
 	// ErrNotImplemented indicates that a flag or command is not implemented.
 	ErrNotImplemented = ErrorCode(238) // NotImplemented
 
+	// ErrQueryExceededMemoryLimitNoDiskUseAllowed indicates that a blocking stage exceeded its memory limit
+	// without allowDiskUse being set.
+	ErrQueryExceededMemoryLimitNoDiskUseAllowed = ErrorCode(292) // QueryExceededMemoryLimitNoDiskUseAllowed
+
 	// ErrIndexesWrongType indicates that indexes parameter has wrong type.
 	ErrIndexesWrongType = ErrorCode(10065) // Location10065
 
 	// ErrDuplicateKeyInsert indicates duplicate key violation on inserting document.
 	ErrDuplicateKeyInsert = ErrorCode(11000) // Location11000
 
+	// ErrInterrupted indicates that the operation was canceled.
+	ErrInterrupted = ErrorCode(11601) // Interrupted
+
+	// ErrMergeStageNoMatchingDocument indicates that $merge stage did not find a matching document.
+	ErrMergeStageNoMatchingDocument = ErrorCode(13113) // MergeStageNoMatchingDocument
+
 	// ErrSetBadExpression indicates set expression is not object.
 	ErrSetBadExpression = ErrorCode(40272) // Location40272
 
@@ -178,18 +202,72 @@ const (
 	// ErrFieldPathInvalidName indicates that FieldPath is invalid.
 	ErrFieldPathInvalidName = ErrorCode(16410) // Location16410
 
+	// ErrFieldPathContainsDot indicates that FieldPath field name contains a dot.
+	ErrFieldPathContainsDot = ErrorCode(16412) // Location16412
+
 	// ErrGroupInvalidFieldPath indicates invalid path is given for group _id.
 	ErrGroupInvalidFieldPath = ErrorCode(16872) // Location16872
 
+	// ErrMapNotObject indicates that $map argument is not an object.
+	ErrMapNotObject = ErrorCode(16878) // Location16878
+
+	// ErrMapUnknownArgument indicates that $map has unknown argument.
+	ErrMapUnknownArgument = ErrorCode(16879) // Location16879
+
+	// ErrMapMissingInput indicates that $map has no input argument.
+	ErrMapMissingInput = ErrorCode(16880) // Location16880
+
+	// ErrMapMissingIn indicates that $map has no in argument.
+	ErrMapMissingIn = ErrorCode(16882) // Location16882
+
+	// ErrMapInputType indicates that $map input is not an array.
+	ErrMapInputType = ErrorCode(16883) // Location16883
+
+	// ErrOutNotString indicates that $out stage argument is not a string or a document.
+	ErrOutNotString = ErrorCode(16990) // Location16990
+
 	// ErrGroupUndefinedVariable indicates the variable is not defined.
 	ErrGroupUndefinedVariable = ErrorCode(17276) // Location17276
 
+	// ErrOutSpecialCollection indicates that $out stage target is a system collection.
+	ErrOutSpecialCollection = ErrorCode(17385) // Location17385
+
+	// ErrFilterNotObject indicates that $filter argument is not an object.
+	ErrFilterNotObject = ErrorCode(28646) // Location28646
+
+	// ErrFilterUnknownArgument indicates that $filter has unknown argument.
+	ErrFilterUnknownArgument = ErrorCode(28647) // Location28647
+
+	// ErrFilterMissingInput indicates that $filter has no input argument.
+	ErrFilterMissingInput = ErrorCode(28648) // Location28648
+
+	// ErrFilterMissingCond indicates that $filter has no cond argument.
+	ErrFilterMissingCond = ErrorCode(28650) // Location28650
+
+	// ErrFilterInputType indicates that $filter input is not an array.
+	ErrFilterInputType = ErrorCode(28651) // Location28651
+
 	// ErrInvalidArg indicates invalid argument in projection document.
 	ErrInvalidArg = ErrorCode(28667) // Location28667
 
 	// ErrSliceFirstArg for $slice indicates that the first argument is not an array.
 	ErrSliceFirstArg = ErrorCode(28724) // Location28724
 
+	// ErrSampleNotObject indicates that $sample aggregation stage specification is not a document.
+	ErrSampleNotObject = ErrorCode(28745) // Location28745
+
+	// ErrSampleSizeNotNumber indicates that $sample aggregation stage size is not a number.
+	ErrSampleSizeNotNumber = ErrorCode(28746) // Location28746
+
+	// ErrSampleSizeNegative indicates that $sample aggregation stage size is negative.
+	ErrSampleSizeNegative = ErrorCode(28747) // Location28747
+
+	// ErrSampleUnknownOption indicates that $sample aggregation stage has unknown option.
+	ErrSampleUnknownOption = ErrorCode(28748) // Location28748
+
+	// ErrSampleMissingSize indicates that $sample aggregation stage has no size.
+	ErrSampleMissingSize = ErrorCode(28749) // Location28749
+
 	// ErrStageUnsetNoPath indicates that $unwind aggregation stage is empty.
 	ErrStageUnsetNoPath = ErrorCode(31119) // Location31119
 
@@ -199,12 +277,39 @@ const (
 	// ErrStageUnsetInvalidType indicates that $unset stage arguments has unexpected type.
 	ErrStageUnsetInvalidType = ErrorCode(31002) // Location31002
 
+	// ErrRegexMissingInput indicates that regex expression operator has no input argument.
+	ErrRegexMissingInput = ErrorCode(31022) // Location31022
+
+	// ErrRegexMissingRegex indicates that regex expression operator has no regex argument.
+	ErrRegexMissingRegex = ErrorCode(31023) // Location31023
+
+	// ErrRegexUnknownArgument indicates that regex expression operator has unknown argument.
+	ErrRegexUnknownArgument = ErrorCode(31024) // Location31024
+
+	// ErrStageUnwindPathNotString indicates that $unwind aggregation stage path is not a string.
+	ErrStageUnwindPathNotString = ErrorCode(28808) // Location28808
+
+	// ErrStageUnwindPreserveNotBool indicates that $unwind aggregation stage
+	// preserveNullAndEmptyArrays option is not a boolean.
+	ErrStageUnwindPreserveNotBool = ErrorCode(28809) // Location28809
+
+	// ErrStageUnwindIndexNotString indicates that $unwind aggregation stage
+	// includeArrayIndex option is not a non-empty string.
+	ErrStageUnwindIndexNotString = ErrorCode(28810) // Location28810
+
+	// ErrStageUnwindUnknownOption indicates that $unwind aggregation stage has unknown option.
+	ErrStageUnwindUnknownOption = ErrorCode(28811) // Location28811
+
 	// ErrStageUnwindNoPath indicates that $unwind aggregation stage is empty.
 	ErrStageUnwindNoPath = ErrorCode(28812) // Location28812
 
 	// ErrStageUnwindNoPrefix indicates that $unwind aggregation stage doesn't include '$' prefix.
 	ErrStageUnwindNoPrefix = ErrorCode(28818) // Location28818
 
+	// ErrStageUnwindIndexPrefix indicates that $unwind aggregation stage
+	// includeArrayIndex option starts with '$'.
+	ErrStageUnwindIndexPrefix = ErrorCode(28822) // Location28822
+
 	// ErrUnsetPathCollision indicates that an $unset path creates collision at another path in arguments.
 	ErrUnsetPathCollision = ErrorCode(31249) // Location31249
 
@@ -219,6 +324,21 @@ const (
 	// while projection document already marked as inclusion.
 	ErrProjectionExIn = ErrorCode(31254) // Location31254
 
+	// ErrSliceProjectionLimit indicates that $slice projection limit is not positive.
+	ErrSliceProjectionLimit = ErrorCode(31257) // Location31257
+
+	// ErrSliceProjectionArrayLen indicates that $slice projection array argument is not [skip, limit].
+	ErrSliceProjectionArrayLen = ErrorCode(31272) // Location31272
+
+	// ErrSliceProjectionArg indicates that $slice projection argument is neither a number nor an array.
+	ErrSliceProjectionArg = ErrorCode(31273) // Location31273
+
+	// ErrElemMatchProjectionArg indicates that $elemMatch projection argument is not a document.
+	ErrElemMatchProjectionArg = ErrorCode(31274) // Location31274
+
+	// ErrElemMatchProjectionNested indicates that $elemMatch projection is used on a nested field.
+	ErrElemMatchProjectionNested = ErrorCode(31275) // Location31275
+
 	// ErrAggregatePositionalProject indicates that positional projection cannot be used in aggregation.
 	ErrAggregatePositionalProject = ErrorCode(31324) // Location31324
 
@@ -232,6 +352,54 @@ const (
 	// ErrExclusionPositionalProjection indicates that exclusion cannot use positional projection.
 	ErrExclusionPositionalProjection = ErrorCode(31395) // Location31395
 
+	// ErrMergeSpecialCollection indicates that $merge stage target is a system collection.
+	ErrMergeSpecialCollection = ErrorCode(31320) // Location31320
+
+	// ErrZipNotObject indicates that $zip argument is not an object.
+	ErrZipNotObject = ErrorCode(34460) // Location34460
+
+	// ErrZipInputsType indicates that $zip inputs is not an array.
+	ErrZipInputsType = ErrorCode(34461) // Location34461
+
+	// ErrZipUseLongestLengthType indicates that $zip useLongestLength is not a bool.
+	ErrZipUseLongestLengthType = ErrorCode(34462) // Location34462
+
+	// ErrZipDefaultsType indicates that $zip defaults is not an array.
+	ErrZipDefaultsType = ErrorCode(34463) // Location34463
+
+	// ErrZipUnknownArgument indicates that $zip has unknown argument.
+	ErrZipUnknownArgument = ErrorCode(34464) // Location34464
+
+	// ErrZipMissingInputs indicates that $zip has no inputs argument.
+	ErrZipMissingInputs = ErrorCode(34465) // Location34465
+
+	// ErrZipDefaultsWithoutLongest indicates that $zip defaults are given without useLongestLength.
+	ErrZipDefaultsWithoutLongest = ErrorCode(34466) // Location34466
+
+	// ErrZipDefaultsLength indicates that $zip defaults and inputs have different lengths.
+	ErrZipDefaultsLength = ErrorCode(34467) // Location34467
+
+	// ErrZipInputType indicates that $zip input is not an array.
+	ErrZipInputType = ErrorCode(34468) // Location34468
+
+	// ErrReduceNotObject indicates that $reduce argument is not an object.
+	ErrReduceNotObject = ErrorCode(40075) // Location40075
+
+	// ErrReduceUnknownArgument indicates that $reduce has unknown argument.
+	ErrReduceUnknownArgument = ErrorCode(40076) // Location40076
+
+	// ErrReduceMissingInput indicates that $reduce has no input argument.
+	ErrReduceMissingInput = ErrorCode(40077) // Location40077
+
+	// ErrReduceMissingInitialValue indicates that $reduce has no initialValue argument.
+	ErrReduceMissingInitialValue = ErrorCode(40078) // Location40078
+
+	// ErrReduceMissingIn indicates that $reduce has no in argument.
+	ErrReduceMissingIn = ErrorCode(40079) // Location40079
+
+	// ErrReduceInputType indicates that $reduce input is not an array.
+	ErrReduceInputType = ErrorCode(40080) // Location40080
+
 	// ErrStageCountNonString indicates that $count aggregation stage expected string.
 	ErrStageCountNonString = ErrorCode(40156) // Location40156
 
@@ -244,6 +412,15 @@ const (
 	// ErrStageCountBadValue indicates that $count stage contains invalid value.
 	ErrStageCountBadValue = ErrorCode(40160) // Location40160
 
+	// ErrFacetNotObject indicates that $facet specification is not a non-empty document.
+	ErrFacetNotObject = ErrorCode(40169) // Location40169
+
+	// ErrFacetNotArray indicates that $facet argument is not an array.
+	ErrFacetNotArray = ErrorCode(40170) // Location40170
+
+	// ErrFacetElementNotObject indicates that $facet sub-pipeline contains a non-document element.
+	ErrFacetElementNotObject = ErrorCode(40171) // Location40171
+
 	// ErrAddFieldsExpressionWrongAmountOfArgs indicates that $addFields stage expression contain invalid
 	// amount of arguments.
 	ErrAddFieldsExpressionWrongAmountOfArgs = ErrorCode(40181) // Location40181
@@ -266,12 +443,51 @@ const (
 	// ErrInvalidFieldPath indicates that the field path is not valid.
 	ErrInvalidFieldPath = ErrorCode(40353) // Location40353
 
+	// ErrArrayToObjectNotArray indicates that $arrayToObject input is not an array.
+	ErrArrayToObjectNotArray = ErrorCode(40386) // Location40386
+
+	// ErrObjectToArrayNotObject indicates that $objectToArray input is not a document.
+	ErrObjectToArrayNotObject = ErrorCode(40390) // Location40390
+
+	// ErrArrayToObjectInconsistentObject indicates that $arrayToObject input mixes documents with other types.
+	ErrArrayToObjectInconsistentObject = ErrorCode(40391) // Location40391
+
+	// ErrArrayToObjectKeysLen indicates that $arrayToObject document element does not have exactly two fields.
+	ErrArrayToObjectKeysLen = ErrorCode(40392) // Location40392
+
+	// ErrArrayToObjectMissingKV indicates that $arrayToObject document element misses `k` or `v` field.
+	ErrArrayToObjectMissingKV = ErrorCode(40393) // Location40393
+
+	// ErrArrayToObjectObjectKeyType indicates that $arrayToObject document element has non-string `k` field.
+	ErrArrayToObjectObjectKeyType = ErrorCode(40394) // Location40394
+
+	// ErrArrayToObjectArrayKeyType indicates that $arrayToObject array element has non-string key.
+	ErrArrayToObjectArrayKeyType = ErrorCode(40395) // Location40395
+
+	// ErrArrayToObjectInconsistentArray indicates that $arrayToObject input mixes arrays with other types.
+	ErrArrayToObjectInconsistentArray = ErrorCode(40396) // Location40396
+
+	// ErrArrayToObjectArraySize indicates that $arrayToObject array element is not a key-value pair.
+	ErrArrayToObjectArraySize = ErrorCode(40397) // Location40397
+
+	// ErrArrayToObjectElementType indicates that $arrayToObject input element is neither an array nor a document.
+	ErrArrayToObjectElementType = ErrorCode(40398) // Location40398
+
+	// ErrMergeObjectsType indicates that $mergeObjects input is not a document.
+	ErrMergeObjectsType = ErrorCode(40400) // Location40400
+
 	// ErrMissingField indicates that the required field in document is missing.
 	ErrMissingField = ErrorCode(40414) // Location40414
 
 	// ErrFailedToParseInput indicates invalid input (absent or malformed fields).
 	ErrFailedToParseInput = ErrorCode(40415) // Location40415
 
+	// ErrFacetStageNotAllowed indicates that the stage is not allowed in $facet sub-pipeline.
+	ErrFacetStageNotAllowed = ErrorCode(40600) // Location40600
+
+	// ErrStageNotLast indicates that the stage must be the last stage in the pipeline.
+	ErrStageNotLast = ErrorCode(40601) // Location40601
+
 	// ErrCollStatsIsNotFirstStage indicates that $collStats must be the first stage in the pipeline.
 	ErrCollStatsIsNotFirstStage = ErrorCode(40602) // Location40602
 
@@ -282,15 +498,51 @@ const (
 	// ErrValueNegative indicates that value must not be negative.
 	ErrValueNegative = ErrorCode(51024) // Location51024
 
+	// ErrLookupStageNotAllowed indicates that the stage is not allowed in $lookup pipeline.
+	ErrLookupStageNotAllowed = ErrorCode(51047) // Location51047
+
+	// ErrMergeOnFieldMissing indicates that $merge 'on' field is missing in the document.
+	ErrMergeOnFieldMissing = ErrorCode(51132) // Location51132
+
+	// ErrMergeIntoWrongType indicates that $merge 'into' field is not a string or a document.
+	ErrMergeIntoWrongType = ErrorCode(51178) // Location51178
+
+	// ErrMergeNotObject indicates that $merge stage argument is not a string or a document.
+	ErrMergeNotObject = ErrorCode(51182) // Location51182
+
+	// ErrMergeOnWrongType indicates that $merge 'on' field is not a string or an array of strings.
+	ErrMergeOnWrongType = ErrorCode(51186) // Location51186
+
+	// ErrMergeOnEmpty indicates that $merge 'on' field is an empty array.
+	ErrMergeOnEmpty = ErrorCode(51187) // Location51187
+
 	// ErrRegexOptions indicates regex options error.
 	ErrRegexOptions = ErrorCode(51075) // Location51075
 
 	// ErrRegexMissingParen indicates missing parentheses in regex expression.
 	ErrRegexMissingParen = ErrorCode(51091) // Location51091
 
+	// ErrRegexNotObject indicates that regex expression operator arguments are not an object.
+	ErrRegexNotObject = ErrorCode(51103) // Location51103
+
+	// ErrRegexInputType indicates that input of regex expression operator is not a string.
+	ErrRegexInputType = ErrorCode(51104) // Location51104
+
+	// ErrRegexType indicates that regex of regex expression operator is not a string or regex.
+	ErrRegexType = ErrorCode(51105) // Location51105
+
+	// ErrRegexOptionsType indicates that options of regex expression operator is not a string.
+	ErrRegexOptionsType = ErrorCode(51106) // Location51106
+
+	// ErrRegexOptionsConflict indicates that options are specified in both regex and options arguments.
+	ErrRegexOptionsConflict = ErrorCode(51107) // Location51107
+
 	// ErrBadRegexOption indicates bad regex option value passed.
 	ErrBadRegexOption = ErrorCode(51108) // Location51108
 
+	// ErrRegexInvalid indicates invalid regular expression in regex expression operator.
+	ErrRegexInvalid = ErrorCode(51111) // Location51111
+
 	// ErrBadPositionalProjection indicates that positional operator could not find a matching element in the array.
 	ErrBadPositionalProjection = ErrorCode(51246) // Location51246
 
@@ -303,9 +555,18 @@ const (
 	// ErrEmptyProject indicates that projection specification must have at least one field.
 	ErrEmptyProject = ErrorCode(51272) // Location51272
 
+	// ErrFilterLimitType indicates that $filter limit is not a 32-bit integral value.
+	ErrFilterLimitType = ErrorCode(327391) // Location327391
+
+	// ErrFilterLimitValue indicates that $filter limit is not positive.
+	ErrFilterLimitValue = ErrorCode(327392) // Location327392
+
 	// ErrDuplicateField indicates duplicate field is specified.
 	ErrDuplicateField = ErrorCode(4822819) // Location4822819
 
+	// ErrArrayToObjectKeyNullByte indicates that $arrayToObject key contains a null byte.
+	ErrArrayToObjectKeyNullByte = ErrorCode(4940400) // Location4940400
+
 	// ErrStageSkipBadValue indicates that $skip stage contains invalid value.
 	ErrStageSkipBadValue = ErrorCode(5107200) // Location5107200
 
@@ -314,6 +575,33 @@ const (
 
 	// ErrStageCollStatsInvalidArg indicates invalid argument for the aggregation $collStats stage.
 	ErrStageCollStatsInvalidArg = ErrorCode(5447000) // Location5447000
+
+	// ErrAccumulatorNNotObject indicates that N-variant accumulator argument is not a document.
+	ErrAccumulatorNNotObject = ErrorCode(5787801) // Location5787801
+
+	// ErrAccumulatorNUnknownArgument indicates that N-variant accumulator has unknown argument.
+	ErrAccumulatorNUnknownArgument = ErrorCode(5787901) // Location5787901
+
+	// ErrAccumulatorNNotIntegral indicates that N-variant accumulator `n` is not an integral number.
+	ErrAccumulatorNNotIntegral = ErrorCode(5787902) // Location5787902
+
+	// ErrAccumulatorNMissingN indicates that N-variant accumulator has no `n` argument.
+	ErrAccumulatorNMissingN = ErrorCode(5787906) // Location5787906
+
+	// ErrAccumulatorNMissingInput indicates that N-variant accumulator has no `input` argument.
+	ErrAccumulatorNMissingInput = ErrorCode(5787907) // Location5787907
+
+	// ErrAccumulatorNNotPositive indicates that N-variant accumulator `n` is not positive.
+	ErrAccumulatorNNotPositive = ErrorCode(5787908) // Location5787908
+
+	// ErrAccumulatorTopMissingSortBy indicates that $topN or $bottomN has no `sortBy` argument.
+	ErrAccumulatorTopMissingSortBy = ErrorCode(5788005) // Location5788005
+
+	// ErrAccumulatorTopMissingOutput indicates that $topN or $bottomN has no `output` argument.
+	ErrAccumulatorTopMissingOutput = ErrorCode(5788006) // Location5788006
+
+	// ErrAccumulatorTopSortByType indicates that $topN or $bottomN `sortBy` is not a document.
+	ErrAccumulatorTopSortByType = ErrorCode(5788604) // Location5788604
 )
 
 // ErrInfo represents additional optional error information.
@@ -338,6 +626,7 @@ type ProtoErr interface {
 // Nil panics (it never should be passed),
 // *CommandError or *WriteErrors (possibly wrapped) are returned unwrapped,
 // *wire.ValidationError (possibly wrapped) is returned as CommandError with BadValue code,
+// timeouts (possibly wrapped) are returned as CommandError with MaxTimeMSExpired or LockTimeout codes,
 // any other values (including lazy errors) are returned as CommandError with InternalError code.
 func ProtocolError(err error) ProtoErr {
 	if err == nil {
@@ -360,6 +649,47 @@ func ProtocolError(err error) ProtoErr {
 		return NewCommandError(ErrBadValue, err).(*CommandError)
 	}
 
+	if code, msg := timeoutError(err); code != errUnset {
+		//nolint:errorlint // only *CommandError could be returned
+		return NewCommandErrorMsg(code, msg).(*CommandError)
+	}
+
 	//nolint:errorlint // only *CommandError could be returned
 	return NewCommandError(errInternalError, err).(*CommandError)
 }
+
+// sqlStateError is implemented by PostgreSQL errors returned by backends as is.
+type sqlStateError interface {
+	SQLState() string
+}
+
+// timeoutError returns error code and message for errors caused by timeouts and cancellations,
+// or errUnset for other errors.
+//
+// Handlers set context deadlines for maxTimeMS;
+// PostgreSQL cancels statements and lock waits after statement_timeout and lock_timeout.
+// Statements canceled for other reasons (for example, by pg_cancel_backend) are reported as interrupted.
+func timeoutError(err error) (ErrorCode, string) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrMaxTimeMSExpired, "operation exceeded time limit"
+	}
+
+	var sqlStateErr sqlStateError
+	if !errors.As(err, &sqlStateErr) {
+		return errUnset, ""
+	}
+
+	switch sqlStateErr.SQLState() {
+	case "57014": // query_canceled
+		// PostgreSQL uses the same code for statement_timeout and user requests; only the message differs
+		if strings.Contains(err.Error(), "statement timeout") {
+			return ErrMaxTimeMSExpired, "operation exceeded time limit"
+		}
+
+		return ErrInterrupted, "operation was interrupted"
+	case "55P03": // lock_not_available, including lock_timeout
+		return ErrLockTimeout, "unable to acquire lock within the configured lock timeout"
+	default:
+		return errUnset, ""
+	}
+}
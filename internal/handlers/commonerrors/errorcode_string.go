@@ -16,14 +16,17 @@ func _() {
 	_ = x[ErrTypeMismatch-14]
 	_ = x[ErrAuthenticationFailed-18]
 	_ = x[ErrIllegalOperation-20]
+	_ = x[ErrLockTimeout-24]
 	_ = x[ErrNamespaceNotFound-26]
 	_ = x[ErrIndexNotFound-27]
 	_ = x[ErrUnsuitableValueType-28]
 	_ = x[ErrConflictingUpdateOperators-40]
 	_ = x[ErrCursorNotFound-43]
 	_ = x[ErrNamespaceExists-48]
+	_ = x[ErrMaxTimeMSExpired-50]
 	_ = x[ErrDollarPrefixedFieldName-52]
 	_ = x[ErrInvalidID-53]
+	_ = x[ErrNotSingleValueField-54]
 	_ = x[ErrEmptyName-56]
 	_ = x[ErrCommandNotFound-59]
 	_ = x[ErrImmutableField-66]
@@ -33,14 +36,18 @@ func _() {
 	_ = x[ErrInvalidNamespace-73]
 	_ = x[ErrIndexOptionsConflict-85]
 	_ = x[ErrIndexKeySpecsConflict-86]
+	_ = x[ErrShutdownInProgress-91]
 	_ = x[ErrOperationFailed-96]
 	_ = x[ErrDocumentValidationFailure-121]
 	_ = x[ErrInvalidIndexSpecificationOption-197]
 	_ = x[ErrInvalidPipelineOperator-168]
 	_ = x[ErrClientMetadataCannotBeMutated-186]
 	_ = x[ErrNotImplemented-238]
+	_ = x[ErrQueryExceededMemoryLimitNoDiskUseAllowed-292]
 	_ = x[ErrIndexesWrongType-10065]
 	_ = x[ErrDuplicateKeyInsert-11000]
+	_ = x[ErrInterrupted-11601]
+	_ = x[ErrMergeStageNoMatchingDocument-13113]
 	_ = x[ErrSetBadExpression-40272]
 	_ = x[ErrStageGroupInvalidFields-15947]
 	_ = x[ErrStageGroupID-15948]
@@ -58,27 +65,77 @@ func _() {
 	_ = x[ErrPathContainsEmptyElement-15998]
 	_ = x[ErrOperatorWrongLenOfArgs-16020]
 	_ = x[ErrFieldPathInvalidName-16410]
+	_ = x[ErrFieldPathContainsDot-16412]
 	_ = x[ErrGroupInvalidFieldPath-16872]
+	_ = x[ErrMapNotObject-16878]
+	_ = x[ErrMapUnknownArgument-16879]
+	_ = x[ErrMapMissingInput-16880]
+	_ = x[ErrMapMissingIn-16882]
+	_ = x[ErrMapInputType-16883]
+	_ = x[ErrOutNotString-16990]
 	_ = x[ErrGroupUndefinedVariable-17276]
+	_ = x[ErrOutSpecialCollection-17385]
+	_ = x[ErrFilterNotObject-28646]
+	_ = x[ErrFilterUnknownArgument-28647]
+	_ = x[ErrFilterMissingInput-28648]
+	_ = x[ErrFilterMissingCond-28650]
+	_ = x[ErrFilterInputType-28651]
 	_ = x[ErrInvalidArg-28667]
 	_ = x[ErrSliceFirstArg-28724]
+	_ = x[ErrSampleNotObject-28745]
+	_ = x[ErrSampleSizeNotNumber-28746]
+	_ = x[ErrSampleSizeNegative-28747]
+	_ = x[ErrSampleUnknownOption-28748]
+	_ = x[ErrSampleMissingSize-28749]
 	_ = x[ErrStageUnsetNoPath-31119]
 	_ = x[ErrStageUnsetArrElementInvalidType-31120]
 	_ = x[ErrStageUnsetInvalidType-31002]
+	_ = x[ErrRegexMissingInput-31022]
+	_ = x[ErrRegexMissingRegex-31023]
+	_ = x[ErrRegexUnknownArgument-31024]
+	_ = x[ErrStageUnwindPathNotString-28808]
+	_ = x[ErrStageUnwindPreserveNotBool-28809]
+	_ = x[ErrStageUnwindIndexNotString-28810]
+	_ = x[ErrStageUnwindUnknownOption-28811]
 	_ = x[ErrStageUnwindNoPath-28812]
 	_ = x[ErrStageUnwindNoPrefix-28818]
+	_ = x[ErrStageUnwindIndexPrefix-28822]
 	_ = x[ErrUnsetPathCollision-31249]
 	_ = x[ErrUnsetPathOverwrite-31250]
 	_ = x[ErrProjectionInEx-31253]
 	_ = x[ErrProjectionExIn-31254]
+	_ = x[ErrSliceProjectionLimit-31257]
+	_ = x[ErrSliceProjectionArrayLen-31272]
+	_ = x[ErrSliceProjectionArg-31273]
+	_ = x[ErrElemMatchProjectionArg-31274]
+	_ = x[ErrElemMatchProjectionNested-31275]
 	_ = x[ErrAggregatePositionalProject-31324]
 	_ = x[ErrAggregateInvalidExpression-31325]
 	_ = x[ErrWrongPositionalOperatorLocation-31394]
 	_ = x[ErrExclusionPositionalProjection-31395]
+	_ = x[ErrMergeSpecialCollection-31320]
+	_ = x[ErrZipNotObject-34460]
+	_ = x[ErrZipInputsType-34461]
+	_ = x[ErrZipUseLongestLengthType-34462]
+	_ = x[ErrZipDefaultsType-34463]
+	_ = x[ErrZipUnknownArgument-34464]
+	_ = x[ErrZipMissingInputs-34465]
+	_ = x[ErrZipDefaultsWithoutLongest-34466]
+	_ = x[ErrZipDefaultsLength-34467]
+	_ = x[ErrZipInputType-34468]
+	_ = x[ErrReduceNotObject-40075]
+	_ = x[ErrReduceUnknownArgument-40076]
+	_ = x[ErrReduceMissingInput-40077]
+	_ = x[ErrReduceMissingInitialValue-40078]
+	_ = x[ErrReduceMissingIn-40079]
+	_ = x[ErrReduceInputType-40080]
 	_ = x[ErrStageCountNonString-40156]
 	_ = x[ErrStageCountNonEmptyString-40157]
 	_ = x[ErrStageCountBadPrefix-40158]
 	_ = x[ErrStageCountBadValue-40160]
+	_ = x[ErrFacetNotObject-40169]
+	_ = x[ErrFacetNotArray-40170]
+	_ = x[ErrFacetElementNotObject-40171]
 	_ = x[ErrAddFieldsExpressionWrongAmountOfArgs-40181]
 	_ = x[ErrStageGroupUnaryOperator-40237]
 	_ = x[ErrStageGroupMultipleAccumulator-40238]
@@ -86,25 +143,62 @@ func _() {
 	_ = x[ErrStageInvalid-40323]
 	_ = x[ErrEmptyFieldPath-40352]
 	_ = x[ErrInvalidFieldPath-40353]
+	_ = x[ErrArrayToObjectNotArray-40386]
+	_ = x[ErrObjectToArrayNotObject-40390]
+	_ = x[ErrArrayToObjectInconsistentObject-40391]
+	_ = x[ErrArrayToObjectKeysLen-40392]
+	_ = x[ErrArrayToObjectMissingKV-40393]
+	_ = x[ErrArrayToObjectObjectKeyType-40394]
+	_ = x[ErrArrayToObjectArrayKeyType-40395]
+	_ = x[ErrArrayToObjectInconsistentArray-40396]
+	_ = x[ErrArrayToObjectArraySize-40397]
+	_ = x[ErrArrayToObjectElementType-40398]
+	_ = x[ErrMergeObjectsType-40400]
 	_ = x[ErrMissingField-40414]
 	_ = x[ErrFailedToParseInput-40415]
+	_ = x[ErrFacetStageNotAllowed-40600]
+	_ = x[ErrStageNotLast-40601]
 	_ = x[ErrCollStatsIsNotFirstStage-40602]
 	_ = x[ErrFreeMonitoringDisabled-50840]
 	_ = x[ErrValueNegative-51024]
+	_ = x[ErrLookupStageNotAllowed-51047]
+	_ = x[ErrMergeOnFieldMissing-51132]
+	_ = x[ErrMergeIntoWrongType-51178]
+	_ = x[ErrMergeNotObject-51182]
+	_ = x[ErrMergeOnWrongType-51186]
+	_ = x[ErrMergeOnEmpty-51187]
 	_ = x[ErrRegexOptions-51075]
 	_ = x[ErrRegexMissingParen-51091]
+	_ = x[ErrRegexNotObject-51103]
+	_ = x[ErrRegexInputType-51104]
+	_ = x[ErrRegexType-51105]
+	_ = x[ErrRegexOptionsType-51106]
+	_ = x[ErrRegexOptionsConflict-51107]
 	_ = x[ErrBadRegexOption-51108]
+	_ = x[ErrRegexInvalid-51111]
 	_ = x[ErrBadPositionalProjection-51246]
 	_ = x[ErrElementMismatchPositionalProjection-51247]
 	_ = x[ErrEmptySubProject-51270]
 	_ = x[ErrEmptyProject-51272]
+	_ = x[ErrFilterLimitType-327391]
+	_ = x[ErrFilterLimitValue-327392]
 	_ = x[ErrDuplicateField-4822819]
+	_ = x[ErrArrayToObjectKeyNullByte-4940400]
 	_ = x[ErrStageSkipBadValue-5107200]
 	_ = x[ErrStageLimitInvalidArg-5107201]
 	_ = x[ErrStageCollStatsInvalidArg-5447000]
+	_ = x[ErrAccumulatorNNotObject-5787801]
+	_ = x[ErrAccumulatorNUnknownArgument-5787901]
+	_ = x[ErrAccumulatorNNotIntegral-5787902]
+	_ = x[ErrAccumulatorNMissingN-5787906]
+	_ = x[ErrAccumulatorNMissingInput-5787907]
+	_ = x[ErrAccumulatorNNotPositive-5787908]
+	_ = x[ErrAccumulatorTopMissingSortBy-5788005]
+	_ = x[ErrAccumulatorTopMissingOutput-5788006]
+	_ = x[ErrAccumulatorTopSortByType-5788604]
 }
 
-const _ErrorCode_name = "UnsetInternalErrorBadValueFailedToParseUnauthorizedTypeMismatchAuthenticationFailedIllegalOperationNamespaceNotFoundIndexNotFoundPathNotViableConflictingUpdateOperatorsCursorNotFoundNamespaceExistsDollarPrefixedFieldNameInvalidIDEmptyFieldNameCommandNotFoundImmutableFieldCannotCreateIndexIndexAlreadyExistsInvalidOptionsInvalidNamespaceIndexOptionsConflictIndexKeySpecsConflictOperationFailedDocumentValidationFailureInvalidPipelineOperatorClientMetadataCannotBeMutatedInvalidIndexSpecificationOptionNotImplementedLocation10065Location11000Location15947Location15948Location15955Location15958Location15959Location15969Location15973Location15974Location15975Location15976Location15981Location15983Location15998Location16020Location16406Location16410Location16872Location17276Location28667Location28724Location28812Location28818Location31002Location31119Location31120Location31249Location31250Location31253Location31254Location31324Location31325Location31394Location31395Location40156Location40157Location40158Location40160Location40181Location40234Location40237Location40238Location40272Location40323Location40352Location40353Location40414Location40415Location40602Location50840Location51024Location51075Location51091Location51108Location51246Location51247Location51270Location51272Location4822819Location5107200Location5107201Location5447000"
+const _ErrorCode_name = "UnsetInternalErrorBadValueFailedToParseUnauthorizedTypeMismatchAuthenticationFailedIllegalOperationLockTimeoutNamespaceNotFoundIndexNotFoundPathNotViableConflictingUpdateOperatorsCursorNotFoundNamespaceExistsMaxTimeMSExpiredDollarPrefixedFieldNameInvalidIDNotSingleValueFieldEmptyFieldNameCommandNotFoundImmutableFieldCannotCreateIndexIndexAlreadyExistsInvalidOptionsInvalidNamespaceIndexOptionsConflictIndexKeySpecsConflictShutdownInProgressOperationFailedDocumentValidationFailureInvalidPipelineOperatorClientMetadataCannotBeMutatedInvalidIndexSpecificationOptionNotImplementedQueryExceededMemoryLimitNoDiskUseAllowedLocation10065Location11000InterruptedMergeStageNoMatchingDocumentLocation15947Location15948Location15955Location15958Location15959Location15969Location15973Location15974Location15975Location15976Location15981Location15983Location15998Location16020Location16406Location16410Location16412Location16872Location16878Location16879Location16880Location16882Location16883Location16990Location17276Location17385Location28646Location28647Location28648Location28650Location28651Location28667Location28724Location28745Location28746Location28747Location28748Location28749Location28808Location28809Location28810Location28811Location28812Location28818Location28822Location31002Location31022Location31023Location31024Location31119Location31120Location31249Location31250Location31253Location31254Location31257Location31272Location31273Location31274Location31275Location31320Location31324Location31325Location31394Location31395Location34460Location34461Location34462Location34463Location34464Location34465Location34466Location34467Location34468Location40075Location40076Location40077Location40078Location40079Location40080Location40156Location40157Location40158Location40160Location40169Location40170Location40171Location40181Location40234Location40237Location40238Location40272Location40323Location40352Location40353Location40386Location40390Location40391Location40392Location40393Location40394Location40395Location40396Location40397Location40398Location40400Location40414Location40415Location40600Location40601Location40602Location50840Location51024Location51047Location51075Location51091Location51103Location51104Location51105Location51106Location51107Location51108Location51111Location51132Location51178Location51182Location51186Location51187Location51246Location51247Location51270Location51272Location327391Location327392Location4822819Location4940400Location5107200Location5107201Location5447000Location5787801Location5787901Location5787902Location5787906Location5787907Location5787908Location5788005Location5788006Location5788604"
 
 var _ErrorCode_map = map[ErrorCode]string{
 	0:       _ErrorCode_name[0:5],
@@ -115,92 +209,186 @@ var _ErrorCode_map = map[ErrorCode]string{
 	14:      _ErrorCode_name[51:63],
 	18:      _ErrorCode_name[63:83],
 	20:      _ErrorCode_name[83:99],
-	26:      _ErrorCode_name[99:116],
-	27:      _ErrorCode_name[116:129],
-	28:      _ErrorCode_name[129:142],
-	40:      _ErrorCode_name[142:168],
-	43:      _ErrorCode_name[168:182],
-	48:      _ErrorCode_name[182:197],
-	52:      _ErrorCode_name[197:220],
-	53:      _ErrorCode_name[220:229],
-	56:      _ErrorCode_name[229:243],
-	59:      _ErrorCode_name[243:258],
-	66:      _ErrorCode_name[258:272],
-	67:      _ErrorCode_name[272:289],
-	68:      _ErrorCode_name[289:307],
-	72:      _ErrorCode_name[307:321],
-	73:      _ErrorCode_name[321:337],
-	85:      _ErrorCode_name[337:357],
-	86:      _ErrorCode_name[357:378],
-	96:      _ErrorCode_name[378:393],
-	121:     _ErrorCode_name[393:418],
-	168:     _ErrorCode_name[418:441],
-	186:     _ErrorCode_name[441:470],
-	197:     _ErrorCode_name[470:501],
-	238:     _ErrorCode_name[501:515],
-	10065:   _ErrorCode_name[515:528],
-	11000:   _ErrorCode_name[528:541],
-	15947:   _ErrorCode_name[541:554],
-	15948:   _ErrorCode_name[554:567],
-	15955:   _ErrorCode_name[567:580],
-	15958:   _ErrorCode_name[580:593],
-	15959:   _ErrorCode_name[593:606],
-	15969:   _ErrorCode_name[606:619],
-	15973:   _ErrorCode_name[619:632],
-	15974:   _ErrorCode_name[632:645],
-	15975:   _ErrorCode_name[645:658],
-	15976:   _ErrorCode_name[658:671],
-	15981:   _ErrorCode_name[671:684],
-	15983:   _ErrorCode_name[684:697],
-	15998:   _ErrorCode_name[697:710],
-	16020:   _ErrorCode_name[710:723],
-	16406:   _ErrorCode_name[723:736],
-	16410:   _ErrorCode_name[736:749],
-	16872:   _ErrorCode_name[749:762],
-	17276:   _ErrorCode_name[762:775],
-	28667:   _ErrorCode_name[775:788],
-	28724:   _ErrorCode_name[788:801],
-	28812:   _ErrorCode_name[801:814],
-	28818:   _ErrorCode_name[814:827],
-	31002:   _ErrorCode_name[827:840],
-	31119:   _ErrorCode_name[840:853],
-	31120:   _ErrorCode_name[853:866],
-	31249:   _ErrorCode_name[866:879],
-	31250:   _ErrorCode_name[879:892],
-	31253:   _ErrorCode_name[892:905],
-	31254:   _ErrorCode_name[905:918],
-	31324:   _ErrorCode_name[918:931],
-	31325:   _ErrorCode_name[931:944],
-	31394:   _ErrorCode_name[944:957],
-	31395:   _ErrorCode_name[957:970],
-	40156:   _ErrorCode_name[970:983],
-	40157:   _ErrorCode_name[983:996],
-	40158:   _ErrorCode_name[996:1009],
-	40160:   _ErrorCode_name[1009:1022],
-	40181:   _ErrorCode_name[1022:1035],
-	40234:   _ErrorCode_name[1035:1048],
-	40237:   _ErrorCode_name[1048:1061],
-	40238:   _ErrorCode_name[1061:1074],
-	40272:   _ErrorCode_name[1074:1087],
-	40323:   _ErrorCode_name[1087:1100],
-	40352:   _ErrorCode_name[1100:1113],
-	40353:   _ErrorCode_name[1113:1126],
-	40414:   _ErrorCode_name[1126:1139],
-	40415:   _ErrorCode_name[1139:1152],
-	40602:   _ErrorCode_name[1152:1165],
-	50840:   _ErrorCode_name[1165:1178],
-	51024:   _ErrorCode_name[1178:1191],
-	51075:   _ErrorCode_name[1191:1204],
-	51091:   _ErrorCode_name[1204:1217],
-	51108:   _ErrorCode_name[1217:1230],
-	51246:   _ErrorCode_name[1230:1243],
-	51247:   _ErrorCode_name[1243:1256],
-	51270:   _ErrorCode_name[1256:1269],
-	51272:   _ErrorCode_name[1269:1282],
-	4822819: _ErrorCode_name[1282:1297],
-	5107200: _ErrorCode_name[1297:1312],
-	5107201: _ErrorCode_name[1312:1327],
-	5447000: _ErrorCode_name[1327:1342],
+	24:      _ErrorCode_name[99:110],
+	26:      _ErrorCode_name[110:127],
+	27:      _ErrorCode_name[127:140],
+	28:      _ErrorCode_name[140:153],
+	40:      _ErrorCode_name[153:179],
+	43:      _ErrorCode_name[179:193],
+	48:      _ErrorCode_name[193:208],
+	50:      _ErrorCode_name[208:224],
+	52:      _ErrorCode_name[224:247],
+	53:      _ErrorCode_name[247:256],
+	54:      _ErrorCode_name[256:275],
+	56:      _ErrorCode_name[275:289],
+	59:      _ErrorCode_name[289:304],
+	66:      _ErrorCode_name[304:318],
+	67:      _ErrorCode_name[318:335],
+	68:      _ErrorCode_name[335:353],
+	72:      _ErrorCode_name[353:367],
+	73:      _ErrorCode_name[367:383],
+	85:      _ErrorCode_name[383:403],
+	86:      _ErrorCode_name[403:424],
+	91:      _ErrorCode_name[424:442],
+	96:      _ErrorCode_name[442:457],
+	121:     _ErrorCode_name[457:482],
+	168:     _ErrorCode_name[482:505],
+	186:     _ErrorCode_name[505:534],
+	197:     _ErrorCode_name[534:565],
+	238:     _ErrorCode_name[565:579],
+	292:     _ErrorCode_name[579:619],
+	10065:   _ErrorCode_name[619:632],
+	11000:   _ErrorCode_name[632:645],
+	11601:   _ErrorCode_name[645:656],
+	13113:   _ErrorCode_name[656:684],
+	15947:   _ErrorCode_name[684:697],
+	15948:   _ErrorCode_name[697:710],
+	15955:   _ErrorCode_name[710:723],
+	15958:   _ErrorCode_name[723:736],
+	15959:   _ErrorCode_name[736:749],
+	15969:   _ErrorCode_name[749:762],
+	15973:   _ErrorCode_name[762:775],
+	15974:   _ErrorCode_name[775:788],
+	15975:   _ErrorCode_name[788:801],
+	15976:   _ErrorCode_name[801:814],
+	15981:   _ErrorCode_name[814:827],
+	15983:   _ErrorCode_name[827:840],
+	15998:   _ErrorCode_name[840:853],
+	16020:   _ErrorCode_name[853:866],
+	16406:   _ErrorCode_name[866:879],
+	16410:   _ErrorCode_name[879:892],
+	16412:   _ErrorCode_name[892:905],
+	16872:   _ErrorCode_name[905:918],
+	16878:   _ErrorCode_name[918:931],
+	16879:   _ErrorCode_name[931:944],
+	16880:   _ErrorCode_name[944:957],
+	16882:   _ErrorCode_name[957:970],
+	16883:   _ErrorCode_name[970:983],
+	16990:   _ErrorCode_name[983:996],
+	17276:   _ErrorCode_name[996:1009],
+	17385:   _ErrorCode_name[1009:1022],
+	28646:   _ErrorCode_name[1022:1035],
+	28647:   _ErrorCode_name[1035:1048],
+	28648:   _ErrorCode_name[1048:1061],
+	28650:   _ErrorCode_name[1061:1074],
+	28651:   _ErrorCode_name[1074:1087],
+	28667:   _ErrorCode_name[1087:1100],
+	28724:   _ErrorCode_name[1100:1113],
+	28745:   _ErrorCode_name[1113:1126],
+	28746:   _ErrorCode_name[1126:1139],
+	28747:   _ErrorCode_name[1139:1152],
+	28748:   _ErrorCode_name[1152:1165],
+	28749:   _ErrorCode_name[1165:1178],
+	28808:   _ErrorCode_name[1178:1191],
+	28809:   _ErrorCode_name[1191:1204],
+	28810:   _ErrorCode_name[1204:1217],
+	28811:   _ErrorCode_name[1217:1230],
+	28812:   _ErrorCode_name[1230:1243],
+	28818:   _ErrorCode_name[1243:1256],
+	28822:   _ErrorCode_name[1256:1269],
+	31002:   _ErrorCode_name[1269:1282],
+	31022:   _ErrorCode_name[1282:1295],
+	31023:   _ErrorCode_name[1295:1308],
+	31024:   _ErrorCode_name[1308:1321],
+	31119:   _ErrorCode_name[1321:1334],
+	31120:   _ErrorCode_name[1334:1347],
+	31249:   _ErrorCode_name[1347:1360],
+	31250:   _ErrorCode_name[1360:1373],
+	31253:   _ErrorCode_name[1373:1386],
+	31254:   _ErrorCode_name[1386:1399],
+	31257:   _ErrorCode_name[1399:1412],
+	31272:   _ErrorCode_name[1412:1425],
+	31273:   _ErrorCode_name[1425:1438],
+	31274:   _ErrorCode_name[1438:1451],
+	31275:   _ErrorCode_name[1451:1464],
+	31320:   _ErrorCode_name[1464:1477],
+	31324:   _ErrorCode_name[1477:1490],
+	31325:   _ErrorCode_name[1490:1503],
+	31394:   _ErrorCode_name[1503:1516],
+	31395:   _ErrorCode_name[1516:1529],
+	34460:   _ErrorCode_name[1529:1542],
+	34461:   _ErrorCode_name[1542:1555],
+	34462:   _ErrorCode_name[1555:1568],
+	34463:   _ErrorCode_name[1568:1581],
+	34464:   _ErrorCode_name[1581:1594],
+	34465:   _ErrorCode_name[1594:1607],
+	34466:   _ErrorCode_name[1607:1620],
+	34467:   _ErrorCode_name[1620:1633],
+	34468:   _ErrorCode_name[1633:1646],
+	40075:   _ErrorCode_name[1646:1659],
+	40076:   _ErrorCode_name[1659:1672],
+	40077:   _ErrorCode_name[1672:1685],
+	40078:   _ErrorCode_name[1685:1698],
+	40079:   _ErrorCode_name[1698:1711],
+	40080:   _ErrorCode_name[1711:1724],
+	40156:   _ErrorCode_name[1724:1737],
+	40157:   _ErrorCode_name[1737:1750],
+	40158:   _ErrorCode_name[1750:1763],
+	40160:   _ErrorCode_name[1763:1776],
+	40169:   _ErrorCode_name[1776:1789],
+	40170:   _ErrorCode_name[1789:1802],
+	40171:   _ErrorCode_name[1802:1815],
+	40181:   _ErrorCode_name[1815:1828],
+	40234:   _ErrorCode_name[1828:1841],
+	40237:   _ErrorCode_name[1841:1854],
+	40238:   _ErrorCode_name[1854:1867],
+	40272:   _ErrorCode_name[1867:1880],
+	40323:   _ErrorCode_name[1880:1893],
+	40352:   _ErrorCode_name[1893:1906],
+	40353:   _ErrorCode_name[1906:1919],
+	40386:   _ErrorCode_name[1919:1932],
+	40390:   _ErrorCode_name[1932:1945],
+	40391:   _ErrorCode_name[1945:1958],
+	40392:   _ErrorCode_name[1958:1971],
+	40393:   _ErrorCode_name[1971:1984],
+	40394:   _ErrorCode_name[1984:1997],
+	40395:   _ErrorCode_name[1997:2010],
+	40396:   _ErrorCode_name[2010:2023],
+	40397:   _ErrorCode_name[2023:2036],
+	40398:   _ErrorCode_name[2036:2049],
+	40400:   _ErrorCode_name[2049:2062],
+	40414:   _ErrorCode_name[2062:2075],
+	40415:   _ErrorCode_name[2075:2088],
+	40600:   _ErrorCode_name[2088:2101],
+	40601:   _ErrorCode_name[2101:2114],
+	40602:   _ErrorCode_name[2114:2127],
+	50840:   _ErrorCode_name[2127:2140],
+	51024:   _ErrorCode_name[2140:2153],
+	51047:   _ErrorCode_name[2153:2166],
+	51075:   _ErrorCode_name[2166:2179],
+	51091:   _ErrorCode_name[2179:2192],
+	51103:   _ErrorCode_name[2192:2205],
+	51104:   _ErrorCode_name[2205:2218],
+	51105:   _ErrorCode_name[2218:2231],
+	51106:   _ErrorCode_name[2231:2244],
+	51107:   _ErrorCode_name[2244:2257],
+	51108:   _ErrorCode_name[2257:2270],
+	51111:   _ErrorCode_name[2270:2283],
+	51132:   _ErrorCode_name[2283:2296],
+	51178:   _ErrorCode_name[2296:2309],
+	51182:   _ErrorCode_name[2309:2322],
+	51186:   _ErrorCode_name[2322:2335],
+	51187:   _ErrorCode_name[2335:2348],
+	51246:   _ErrorCode_name[2348:2361],
+	51247:   _ErrorCode_name[2361:2374],
+	51270:   _ErrorCode_name[2374:2387],
+	51272:   _ErrorCode_name[2387:2400],
+	327391:  _ErrorCode_name[2400:2414],
+	327392:  _ErrorCode_name[2414:2428],
+	4822819: _ErrorCode_name[2428:2443],
+	4940400: _ErrorCode_name[2443:2458],
+	5107200: _ErrorCode_name[2458:2473],
+	5107201: _ErrorCode_name[2473:2488],
+	5447000: _ErrorCode_name[2488:2503],
+	5787801: _ErrorCode_name[2503:2518],
+	5787901: _ErrorCode_name[2518:2533],
+	5787902: _ErrorCode_name[2533:2548],
+	5787906: _ErrorCode_name[2548:2563],
+	5787907: _ErrorCode_name[2563:2578],
+	5787908: _ErrorCode_name[2578:2593],
+	5788005: _ErrorCode_name[2593:2608],
+	5788006: _ErrorCode_name[2608:2623],
+	5788604: _ErrorCode_name[2623:2638],
 }
 
 func (i ErrorCode) String() string {
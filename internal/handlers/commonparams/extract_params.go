@@ -32,6 +32,7 @@ import (
 // ExtractParams fill passed value structure with parameters from the document.
 // If the passed value is not a pointer to the structure it panics.
 // Parameters are extracted by the field name or by the `ferretdb` tag.
+// Gossiped `$clusterTime` field is always skipped.
 //
 // Possible tags:
 //   - `opt` - field is optional, the field value would not be set if it's not present in the document;
@@ -82,6 +83,11 @@ func ExtractParams(doc *types.Document, command string, value any, l *zap.Logger
 			return lazyerrors.Error(err)
 		}
 
+		// gossiped cluster time is handled by clientconn
+		if key == "$clusterTime" {
+			continue
+		}
+
 		lookup := key
 
 		// If the key is the same as the command name, then it is a collection name.
@@ -99,6 +99,19 @@ func TestParse(t *testing.T) {
 				Filter:     must.NotFail(types.NewDocument("a", "b")),
 			},
 		},
+		"ClusterTime": {
+			command: "find",
+			doc: must.NotFail(types.NewDocument(
+				"$db", "test",
+				"find", "test",
+				"$clusterTime", must.NotFail(types.NewDocument("clusterTime", types.Timestamp(42))),
+			)),
+			params: new(allTagsThatPass),
+			wantParams: &allTagsThatPass{
+				DB:         "test",
+				Collection: "test",
+			},
+		},
 		"UnimplementedTag": {
 			command: "command",
 			doc: must.NotFail(types.NewDocument(
@@ -0,0 +1,296 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dataexport provides conversion of documents to rows of Parquet files
+// for the export command.
+//
+// The schema is inferred from the sample of documents: each top-level field becomes a column.
+// Values are mapped to column types as follows:
+//
+//	bool      boolean
+//	int       int32
+//	long      int64 (int values are widened)
+//	double    double (int and long values are widened)
+//	string    string
+//	objectId  string with 24 character hex representation
+//	date      timestamp
+//	other     JSON string with relaxed Extended JSON representation
+//
+// Fields with values of conflicting types become JSON columns.
+// Null and missing values are stored as Parquet nulls.
+// Fields that are not present in the sample are not exported.
+package dataexport
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/parquet"
+)
+
+// InferSchema returns columns for the given sample of documents.
+//
+// Columns are returned in the order of first occurrence of fields.
+func InferSchema(docs []*types.Document) []parquet.Column {
+	var res []parquet.Column
+	index := map[string]int{}
+	nulls := map[string]bool{}
+
+	for _, doc := range docs {
+		iter := doc.Iterator()
+
+		for {
+			k, v, err := iter.Next()
+			if err != nil {
+				break
+			}
+
+			i, ok := index[k]
+			if !ok {
+				i = len(res)
+				index[k] = i
+				res = append(res, parquet.Column{Name: k, Type: parquet.JSON})
+				nulls[k] = true
+			}
+
+			if v == types.Null {
+				continue
+			}
+
+			t := columnType(v)
+
+			if nulls[k] {
+				res[i].Type = t
+				nulls[k] = false
+
+				continue
+			}
+
+			res[i].Type = mergeTypes(res[i].Type, t)
+		}
+
+		iter.Close()
+	}
+
+	return res
+}
+
+// columnType returns column type for the given non-null value.
+func columnType(v any) parquet.Type {
+	switch v.(type) {
+	case bool:
+		return parquet.Boolean
+	case int32:
+		return parquet.Int32
+	case int64:
+		return parquet.Int64
+	case float64:
+		return parquet.Double
+	case string, types.ObjectID:
+		return parquet.String
+	case time.Time:
+		return parquet.Timestamp
+	default:
+		return parquet.JSON
+	}
+}
+
+// mergeTypes returns column type that can hold values of both given types.
+func mergeTypes(a, b parquet.Type) parquet.Type {
+	if a == b {
+		return a
+	}
+
+	numeric := map[parquet.Type]int{parquet.Int32: 1, parquet.Int64: 2, parquet.Double: 3}
+
+	if numeric[a] > 0 && numeric[b] > 0 {
+		if numeric[a] > numeric[b] {
+			return a
+		}
+
+		return b
+	}
+
+	return parquet.JSON
+}
+
+// Row returns values of the given document for the given columns.
+//
+// It returns an error if the value can't be stored in the column of inferred type;
+// that can happen if the sample was too small.
+func Row(columns []parquet.Column, doc *types.Document) ([]any, error) {
+	res := make([]any, len(columns))
+
+	for i, col := range columns {
+		v, _ := doc.Get(col.Name)
+		if v == nil || v == types.Null {
+			continue
+		}
+
+		var ok bool
+
+		switch col.Type {
+		case parquet.Boolean:
+			res[i], ok = v.(bool)
+
+		case parquet.Int32:
+			res[i], ok = v.(int32)
+
+		case parquet.Int64:
+			switch v := v.(type) {
+			case int32:
+				res[i], ok = int64(v), true
+			case int64:
+				res[i], ok = v, true
+			}
+
+		case parquet.Double:
+			switch v := v.(type) {
+			case int32:
+				res[i], ok = float64(v), true
+			case int64:
+				res[i], ok = float64(v), true
+			case float64:
+				res[i], ok = v, true
+			}
+
+		case parquet.String:
+			switch v := v.(type) {
+			case string:
+				res[i], ok = v, true
+			case types.ObjectID:
+				res[i], ok = fmt.Sprintf("%x", v[:]), true
+			}
+
+		case parquet.Timestamp:
+			res[i], ok = v.(time.Time)
+
+		case parquet.JSON:
+			var buf bytes.Buffer
+			if err := writeJSON(&buf, v); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			res[i], ok = buf.String(), true
+
+		default:
+			panic(fmt.Sprintf("unexpected column type %s", col.Type))
+		}
+
+		if !ok {
+			return nil, fmt.Errorf(
+				"field %q: %s value does not match %s column inferred from the sample",
+				col.Name, commonparams.AliasFromType(v), col.Type,
+			)
+		}
+	}
+
+	return res, nil
+}
+
+// writeJSON writes relaxed Extended JSON representation of the given value.
+func writeJSON(buf *bytes.Buffer, v any) error {
+	switch v := v.(type) {
+	case *types.Document:
+		buf.WriteByte('{')
+
+		for i, k := range v.Keys() {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			buf.Write(must.NotFail(json.Marshal(k)))
+			buf.WriteByte(':')
+
+			if err := writeJSON(buf, must.NotFail(v.Get(k))); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte('}')
+
+	case *types.Array:
+		buf.WriteByte('[')
+
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			if err := writeJSON(buf, must.NotFail(v.Get(i))); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte(']')
+
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			buf.WriteString(`{"$numberDouble":"NaN"}`)
+		case math.IsInf(v, 1):
+			buf.WriteString(`{"$numberDouble":"Infinity"}`)
+		case math.IsInf(v, -1):
+			buf.WriteString(`{"$numberDouble":"-Infinity"}`)
+		default:
+			buf.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+		}
+
+	case string:
+		buf.Write(must.NotFail(json.Marshal(v)))
+
+	case types.Binary:
+		fmt.Fprintf(buf, `{"$binary":{"base64":%q,"subType":"%02x"}}`, base64.StdEncoding.EncodeToString(v.B), byte(v.Subtype))
+
+	case types.ObjectID:
+		fmt.Fprintf(buf, `{"$oid":"%x"}`, v[:])
+
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+
+	case time.Time:
+		fmt.Fprintf(buf, `{"$date":%q}`, v.UTC().Format("2006-01-02T15:04:05.000Z07:00"))
+
+	case types.NullType:
+		buf.WriteString("null")
+
+	case types.Regex:
+		pattern := must.NotFail(json.Marshal(v.Pattern))
+		options := must.NotFail(json.Marshal(v.Options))
+		fmt.Fprintf(buf, `{"$regularExpression":{"pattern":%s,"options":%s}}`, pattern, options)
+
+	case int32:
+		buf.WriteString(strconv.FormatInt(int64(v), 10))
+
+	case types.Timestamp:
+		fmt.Fprintf(buf, `{"$timestamp":{"t":%d,"i":%d}}`, uint64(v)>>32, uint32(v))
+
+	case int64:
+		buf.WriteString(strconv.FormatInt(v, 10))
+
+	default:
+		return lazyerrors.Errorf("unexpected type %T", v)
+	}
+
+	return nil
+}
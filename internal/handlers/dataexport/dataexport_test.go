@@ -0,0 +1,91 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataexport
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/parquet"
+)
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+
+	id := types.ObjectID{0x65, 0x3e, 0x6f, 0x1c, 0x9b, 0x2a, 0x4c, 0x1d, 0x2e, 0x3f, 0x4a, 0x5b}
+	date := time.Date(2023, time.October, 29, 12, 0, 0, 0, time.UTC)
+
+	docs := []*types.Document{
+		must.NotFail(types.NewDocument(
+			"_id", id,
+			"n", int32(1),
+			"l", int32(2),
+			"d", date,
+			"x", types.Null,
+			"m", "a",
+		)),
+		must.NotFail(types.NewDocument(
+			"_id", id,
+			"n", int32(3),
+			"l", int64(4),
+			"x", true,
+			"m", int32(5),
+			"o", must.NotFail(types.NewDocument(
+				"a", must.NotFail(types.NewArray(int32(1), 1.5, math.Inf(1), "s", types.Null)),
+				"r", types.Regex{Pattern: "^a", Options: "i"},
+				"b", types.Binary{Subtype: types.BinaryGeneric, B: []byte("hi")},
+				"t", types.NewTimestamp(date, 7),
+				"i", id,
+				"d", date,
+			)),
+		)),
+	}
+
+	columns := InferSchema(docs)
+	expected := []parquet.Column{
+		{Name: "_id", Type: parquet.String},
+		{Name: "n", Type: parquet.Int32},
+		{Name: "l", Type: parquet.Int64},
+		{Name: "d", Type: parquet.Timestamp},
+		{Name: "x", Type: parquet.Boolean},
+		{Name: "m", Type: parquet.JSON},
+		{Name: "o", Type: parquet.JSON},
+	}
+	assert.Equal(t, expected, columns)
+
+	row, err := Row(columns, docs[0])
+	require.NoError(t, err)
+	assert.Equal(t, []any{"653e6f1c9b2a4c1d2e3f4a5b", int32(1), int64(2), date, nil, `"a"`, nil}, row)
+
+	row, err = Row(columns, docs[1])
+	require.NoError(t, err)
+
+	o := `{"a":[1,1.5,{"$numberDouble":"Infinity"},"s",null],` +
+		`"r":{"$regularExpression":{"pattern":"^a","options":"i"}},` +
+		`"b":{"$binary":{"base64":"aGk=","subType":"00"}},` +
+		`"t":{"$timestamp":{"t":1698580800,"i":7}},` +
+		`"i":{"$oid":"653e6f1c9b2a4c1d2e3f4a5b"},` +
+		`"d":{"$date":"2023-10-29T12:00:00.000Z"}}`
+	assert.Equal(t, []any{"653e6f1c9b2a4c1d2e3f4a5b", int32(3), int64(4), nil, true, "5", o}, row)
+
+	_, err = Row(columns, must.NotFail(types.NewDocument("n", "not a number", "extra", int32(1))))
+	assert.EqualError(t, err, `field "n": string value does not match int32 column inferred from the sample`)
+}
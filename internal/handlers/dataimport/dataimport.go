@@ -0,0 +1,134 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dataimport provides readers of documents from newline-delimited JSON and CSV data
+// for the bulk import command.
+//
+// JSON values are mapped to types as mongoimport does; see sjson.RelaxedDecoder.
+//
+// The first CSV line contains field names.
+// Numeric CSV values are converted like JSON numbers; all other values are kept as strings.
+package dataimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"regexp"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/sjson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// Format represents the format of imported data.
+type Format string
+
+const (
+	// FormatJSON is newline-delimited JSON (one document per line).
+	FormatJSON = Format("json")
+
+	// FormatCSV is CSV with a header line.
+	FormatCSV = Format("csv")
+)
+
+// Reader reads documents from imported data.
+type Reader interface {
+	// Read returns the next document, or io.EOF if there are no more documents.
+	Read() (*types.Document, error)
+}
+
+// NewReader returns a new reader of documents in the given format.
+func NewReader(r io.Reader, format Format) (Reader, error) {
+	switch format {
+	case FormatJSON:
+		return &jsonReader{d: sjson.NewRelaxedDecoder(r)}, nil
+
+	case FormatCSV:
+		c := csv.NewReader(r)
+		c.ReuseRecord = true
+
+		return &csvReader{r: c}, nil
+
+	default:
+		return nil, lazyerrors.Errorf("unknown format %q", format)
+	}
+}
+
+// jsonReader reads documents from newline-delimited JSON.
+type jsonReader struct {
+	d *sjson.RelaxedDecoder
+}
+
+// Read implements Reader interface.
+func (r *jsonReader) Read() (*types.Document, error) {
+	return r.d.Decode()
+}
+
+// csvNumber matches CSV values that are converted to numbers.
+var csvNumber = regexp.MustCompile(`^-?(\d+\.?\d*|\.\d+)([eE][-+]?\d+)?$`)
+
+// csvReader reads documents from CSV with a header line.
+type csvReader struct {
+	r      *csv.Reader
+	fields []string
+}
+
+// Read implements Reader interface.
+func (r *csvReader) Read() (*types.Document, error) {
+	if r.fields == nil {
+		header, err := r.r.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, io.EOF
+			}
+
+			return nil, err
+		}
+
+		// copy because ReuseRecord is set
+		r.fields = append([]string{}, header...)
+	}
+
+	record, err := r.r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+
+		return nil, err
+	}
+
+	doc := types.MakeDocument(len(r.fields))
+
+	for i, field := range r.fields {
+		var v any = record[i]
+
+		if csvNumber.MatchString(record[i]) {
+			if n, err := sjson.ParseRelaxedNumber(record[i]); err == nil {
+				v = n
+			}
+		}
+
+		doc.Set(field, v)
+	}
+
+	return doc, nil
+}
+
+// check interfaces
+var (
+	_ Reader = (*jsonReader)(nil)
+	_ Reader = (*csvReader)(nil)
+)
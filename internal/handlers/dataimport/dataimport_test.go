@@ -0,0 +1,112 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataimport
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+// readAll reads all documents from the given data.
+func readAll(t *testing.T, data string, format Format) ([]*types.Document, error) {
+	t.Helper()
+
+	r, err := NewReader(strings.NewReader(data), format)
+	require.NoError(t, err)
+
+	var res []*types.Document
+
+	for {
+		doc, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return res, nil
+		}
+
+		if err != nil {
+			return res, err
+		}
+
+		res = append(res, doc)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	t.Parallel()
+
+	data := `{"_id": {"$oid": "653e6f1c9b2a4c1d2e3f4a5b"}, "v": 42, "l": 4294967296, "f": 1.5}
+{"_id": 2, "a": [1, "b", null, true], "d": {"$date": "2023-10-29T12:00:00Z"}}
+
+{"_id": 3, "n": {"x": {"$numberLong": "7"}}, "m": {"$date": {"$numberLong": "1000"}}}
+`
+
+	docs, err := readAll(t, data, FormatJSON)
+	require.NoError(t, err)
+	require.Len(t, docs, 3)
+
+	expected := []*types.Document{
+		must.NotFail(types.NewDocument(
+			"_id", types.ObjectID{0x65, 0x3e, 0x6f, 0x1c, 0x9b, 0x2a, 0x4c, 0x1d, 0x2e, 0x3f, 0x4a, 0x5b},
+			"v", int32(42),
+			"l", int64(4294967296),
+			"f", 1.5,
+		)),
+		must.NotFail(types.NewDocument(
+			"_id", int32(2),
+			"a", must.NotFail(types.NewArray(int32(1), "b", types.Null, true)),
+			"d", time.Date(2023, time.October, 29, 12, 0, 0, 0, time.UTC),
+		)),
+		must.NotFail(types.NewDocument(
+			"_id", int32(3),
+			"n", must.NotFail(types.NewDocument("x", int64(7))),
+			"m", time.UnixMilli(1000).UTC(),
+		)),
+	}
+
+	for i, doc := range docs {
+		testutil.AssertEqual(t, expected[i], doc)
+	}
+
+	_, err = readAll(t, `{"_id": 1}`+"\n"+`[1]`, FormatJSON)
+	require.ErrorContains(t, err, "document 2: expected object")
+}
+
+func TestCSV(t *testing.T) {
+	t.Parallel()
+
+	data := "_id,name,score,ratio\n1,foo,-10,0.5\n2,inf,,1e3\n"
+
+	docs, err := readAll(t, data, FormatCSV)
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+
+	testutil.AssertEqual(t, must.NotFail(types.NewDocument(
+		"_id", int32(1), "name", "foo", "score", int32(-10), "ratio", 0.5,
+	)), docs[0])
+	testutil.AssertEqual(t, must.NotFail(types.NewDocument(
+		"_id", int32(2), "name", "inf", "score", "", "ratio", float64(1000),
+	)), docs[1])
+
+	_, err = readAll(t, "a,b\n1\n", FormatCSV)
+	require.Error(t, err)
+}
@@ -0,0 +1,187 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dropprotection protects namespaces from being dropped by `dropDatabase` and `drop` commands.
+//
+// Operators configure a list of protected namespaces and a mode.
+// In "deny" mode, protected namespaces can't be dropped at all.
+// In "confirm" mode, the first drop attempt fails with an error containing a one-time token;
+// the command should be repeated with the `confirm` field set to that token.
+package dropprotection
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// Mode represents drop protection mode.
+type Mode string
+
+// Supported drop protection modes.
+const (
+	// ModeOff disables drop protection.
+	ModeOff = Mode("off")
+
+	// ModeConfirm requires a confirmation token to drop protected namespaces.
+	ModeConfirm = Mode("confirm")
+
+	// ModeDeny disallows dropping protected namespaces.
+	ModeDeny = Mode("deny")
+)
+
+// Modes contains all supported drop protection modes.
+var Modes = []string{string(ModeOff), string(ModeConfirm), string(ModeDeny)}
+
+// tokenTTL is the duration during which the confirmation token is valid.
+const tokenTTL = time.Minute
+
+// token represents an issued confirmation token.
+type token struct {
+	value   string
+	expires time.Time
+}
+
+// Guard checks that protected namespaces are not dropped accidentally.
+//
+// Nil value is valid and allows everything.
+// Methods are safe for concurrent use.
+//
+//nolint:vet // for readability
+type Guard struct {
+	mode       Mode
+	namespaces []string
+
+	m      sync.Mutex
+	tokens map[string]token // namespace -> token
+	now    func() time.Time
+}
+
+// New returns a new Guard for the given mode and namespaces.
+//
+// Namespace is either a database name (that protects the database and all its collections),
+// a full collection name in the `db.collection` form, or `*` for all namespaces.
+//
+// It returns nil for ModeOff or no namespaces.
+func New(mode Mode, namespaces []string) (*Guard, error) {
+	switch mode {
+	case ModeOff:
+		return nil, nil
+	case ModeConfirm, ModeDeny:
+		// nothing
+	default:
+		return nil, fmt.Errorf("dropprotection.New: unknown mode %q", mode)
+	}
+
+	var ns []string
+
+	for _, n := range namespaces {
+		if n = strings.TrimSpace(n); n != "" {
+			ns = append(ns, n)
+		}
+	}
+
+	if len(ns) == 0 {
+		return nil, nil
+	}
+
+	return &Guard{
+		mode:       mode,
+		namespaces: ns,
+		tokens:     map[string]token{},
+		now:        time.Now,
+	}, nil
+}
+
+// Check returns an error if the database (if collection is empty) or the collection
+// should not be dropped.
+//
+// The confirm argument is the value of the `confirm` command field, or empty string.
+// A valid confirmation token can be used only once.
+func (g *Guard) Check(command, db, collection, confirm string) error {
+	if g == nil || !g.protected(db, collection) {
+		return nil
+	}
+
+	ns := db
+	if collection != "" {
+		ns += "." + collection
+	}
+
+	if g.mode == ModeDeny {
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrIllegalOperation,
+			fmt.Sprintf("Dropping protected namespace %s is not allowed.", ns),
+			command,
+		)
+	}
+
+	g.m.Lock()
+	defer g.m.Unlock()
+
+	now := g.now()
+
+	t, ok := g.tokens[ns]
+	if ok && confirm != "" && confirm == t.value && now.Before(t.expires) {
+		delete(g.tokens, ns)
+		return nil
+	}
+
+	t = token{
+		value:   newToken(),
+		expires: now.Add(tokenTTL),
+	}
+	g.tokens[ns] = t
+
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrIllegalOperation,
+		fmt.Sprintf(
+			"Dropping protected namespace %s requires confirmation. "+
+				"Repeat the command with {confirm: %q} within %s.",
+			ns, t.value, tokenTTL,
+		),
+		command,
+	)
+}
+
+// protected returns true if the database (if collection is empty) or the collection is protected.
+func (g *Guard) protected(db, collection string) bool {
+	for _, n := range g.namespaces {
+		switch {
+		case n == "*", n == db:
+			return true
+		case collection == "" && strings.HasPrefix(n, db+"."):
+			// dropping the database drops protected collection
+			return true
+		case collection != "" && n == db+"."+collection:
+			return true
+		}
+	}
+
+	return false
+}
+
+// newToken returns a new random confirmation token.
+func newToken() string {
+	b := make([]byte, 8)
+	must.NotFail(rand.Read(b))
+
+	return hex.EncodeToString(b)
+}
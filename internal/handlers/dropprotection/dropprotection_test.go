@@ -0,0 +1,115 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dropprotection
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	g, err := New(ModeOff, []string{"prod"})
+	require.NoError(t, err)
+	assert.Nil(t, g)
+
+	g, err = New(ModeDeny, []string{"", " "})
+	require.NoError(t, err)
+	assert.Nil(t, g)
+
+	_, err = New("unknown", []string{"prod"})
+	assert.Error(t, err)
+}
+
+func TestDeny(t *testing.T) {
+	t.Parallel()
+
+	var g *Guard
+	assert.NoError(t, g.Check("dropDatabase", "prod", "", ""))
+
+	g, err := New(ModeDeny, []string{"prod", "test.users"})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		db, collection string
+		err            bool
+	}{
+		"Database":            {db: "prod", err: true},
+		"DatabaseCollection":  {db: "prod", collection: "orders", err: true},
+		"Collection":          {db: "test", collection: "users", err: true},
+		"DatabaseOfProtected": {db: "test", err: true},
+		"OtherCollection":     {db: "test", collection: "orders"},
+		"OtherDatabase":       {db: "production"},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := g.Check("drop", tc.db, tc.collection, "")
+			if !tc.err {
+				assert.NoError(t, err)
+				return
+			}
+
+			var ce *commonerrors.CommandError
+			require.ErrorAs(t, err, &ce)
+			assert.Equal(t, commonerrors.ErrIllegalOperation, ce.Code())
+		})
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	t.Parallel()
+
+	g, err := New(ModeConfirm, []string{"*"})
+	require.NoError(t, err)
+
+	now := time.Date(2023, time.October, 1, 0, 0, 0, 0, time.UTC)
+	g.now = func() time.Time { return now }
+
+	tokenRe := regexp.MustCompile(`\{confirm: "([0-9a-f]+)"\}`)
+
+	getToken := func(t *testing.T, err error) string {
+		t.Helper()
+
+		require.Error(t, err)
+
+		m := tokenRe.FindStringSubmatch(err.Error())
+		require.Len(t, m, 2, "%s", err)
+
+		return m[1]
+	}
+
+	token := getToken(t, g.Check("dropDatabase", "prod", "", ""))
+
+	// token is bound to the namespace
+	getToken(t, g.Check("drop", "prod", "users", token))
+
+	require.NoError(t, g.Check("dropDatabase", "prod", "", token))
+
+	// token can't be reused
+	token = getToken(t, g.Check("dropDatabase", "prod", "", token))
+
+	// token expires
+	now = now.Add(tokenTTL + time.Second)
+	getToken(t, g.Check("dropDatabase", "prod", "", token))
+}
@@ -61,6 +61,9 @@ type Interface interface {
 	// MsgCompact reduces the disk space collection takes and refreshes its statistics.
 	MsgCompact(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgConfigureFailPoint enables, disables or configures a fail point for testing.
+	MsgConfigureFailPoint(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgConnectionStatus returns information about the current connection,
 	// specifically the state of authenticated users and their available permissions.
 	MsgConnectionStatus(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
@@ -92,6 +95,9 @@ type Interface interface {
 	// MsgDistinct returns an array of distinct values for the given field.
 	MsgDistinct(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgDocumentHistory lists or purges previous versions of documents kept for the collection.
+	MsgDocumentHistory(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgDrop drops the collection.
 	MsgDrop(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
@@ -101,9 +107,15 @@ type Interface interface {
 	// MsgDropDatabase drops production database.
 	MsgDropDatabase(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgEndSessions marks sessions as expired.
+	MsgEndSessions(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgExplain returns the execution plan.
 	MsgExplain(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgExport exports documents from the collection to Parquet file.
+	MsgExport(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgFind returns documents matched by the query.
 	MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
@@ -113,6 +125,9 @@ type Interface interface {
 	// MsgGetCmdLineOpts returns a summary of all runtime and configuration options.
 	MsgGetCmdLineOpts(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgGetDiagnosticData returns diagnostic data snapshot in the format similar to MongoDB's FTDC.
+	MsgGetDiagnosticData(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgGetFreeMonitoringStatus returns a status of the free monitoring.
 	MsgGetFreeMonitoringStatus(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
@@ -131,12 +146,18 @@ type Interface interface {
 	// MsgHostInfo returns a summary of the system information.
 	MsgHostInfo(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgImport imports documents from JSON or CSV file or URL into the collection.
+	MsgImport(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgInsert inserts documents into the database.
 	MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
 	// MsgIsMaster returns the role of the FerretDB instance.
 	MsgIsMaster(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgJobs lists, pauses or resumes background jobs.
+	MsgJobs(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgKillCursors closes server cursors.
 	MsgKillCursors(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
@@ -158,6 +179,9 @@ type Interface interface {
 	// MsgPing returns a pong response.
 	MsgPing(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgRefreshMaterializedView replaces documents of the materialized view with its pipeline results.
+	MsgRefreshMaterializedView(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgRenameCollection changes the name of an existing collection.
 	MsgRenameCollection(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
@@ -170,12 +194,21 @@ type Interface interface {
 	// MsgSetFreeMonitoring toggles free monitoring.
 	MsgSetFreeMonitoring(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgTop returns usage statistics for each collection.
+	MsgTop(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
+	// MsgTrash lists, restores or purges dropped collections in the trash.
+	MsgTrash(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgUpdate updates documents that are matched by the query.
 	MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
 	// MsgValidate validates collection.
 	MsgValidate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgValidateDBMetadata validates FerretDB metadata against the backend schema.
+	MsgValidateDBMetadata(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgWhatsMyURI returns peer information.
 	MsgWhatsMyURI(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
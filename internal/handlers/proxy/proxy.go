@@ -50,6 +50,8 @@ func (r *Router) Close() {
 }
 
 // Route routes the message by sending it to another wire protocol compatible service.
+//
+// It returns nil header and body for messages that do not expect a reply.
 func (r *Router) Route(ctx context.Context, header *wire.MsgHeader, body wire.MsgBody) (*wire.MsgHeader, wire.MsgBody) {
 	deadline, _ := ctx.Deadline()
 	r.conn.SetDeadline(deadline)
@@ -62,6 +64,10 @@ func (r *Router) Route(ctx context.Context, header *wire.MsgHeader, body wire.Ms
 		panic(err)
 	}
 
+	if msg, ok := body.(*wire.OpMsg); ok && msg.FlagBits.FlagSet(wire.OpMsgMoreToCome) {
+		return nil, nil
+	}
+
 	resHeader, resBody, err := wire.ReadMessage(r.bufr)
 	if err != nil {
 		panic(err)
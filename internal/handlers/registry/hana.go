@@ -33,6 +33,30 @@ func init() {
 			L:             opts.Logger.Named("hana"),
 			ConnMetrics:   opts.ConnMetrics,
 			StateProvider: opts.StateProvider,
+			FailPoints:    opts.FailPoints,
+
+			SizeCacheMaxAge: opts.SizeCacheMaxAge,
+			QueryCacheSize:  opts.QueryCacheSize,
+			DropProtection:  opts.DropProtection,
+			TrashRetention:  opts.TrashRetention,
+			ArchiveInterval: opts.ArchiveInterval,
+			AnalyzeInterval: opts.AnalyzeInterval,
+			CollectionStats: opts.CollectionStats,
+
+			MaterializedViewsInterval: opts.MaterializedViewsInterval,
+
+			DiagnosticsDir:      opts.DiagnosticsDir,
+			DiagnosticsInterval: opts.DiagnosticsInterval,
+
+			ImportDir: opts.ImportDir,
+			ExportDir: opts.ExportDir,
+			InitDir:   opts.InitDir,
+
+			ImportURLHosts: opts.ImportURLHosts,
+
+			SQLStage: opts.SQLStage,
+
+			AdminUsers: opts.AdminUsers,
 
 			DisableFilterPushdown:    opts.DisableFilterPushdown,
 			EnableUnsafeSortPushdown: opts.EnableUnsafeSortPushdown,
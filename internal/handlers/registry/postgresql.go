@@ -26,9 +26,38 @@ func init() {
 			Backend: "postgresql",
 			URI:     opts.PostgreSQLURL,
 
+			PostgreSQLRelationalViews:  opts.PostgreSQLRelationalViews,
+			PostgreSQLStatementTimeout: opts.PostgreSQLStatementTimeout,
+			PostgreSQLLockTimeout:      opts.PostgreSQLLockTimeout,
+			PostgreSQLTenantIsolation:  opts.PostgreSQLTenantIsolation,
+
 			L:             opts.Logger.Named("postgresql"),
 			ConnMetrics:   opts.ConnMetrics,
 			StateProvider: opts.StateProvider,
+			FailPoints:    opts.FailPoints,
+
+			SizeCacheMaxAge: opts.SizeCacheMaxAge,
+			QueryCacheSize:  opts.QueryCacheSize,
+			DropProtection:  opts.DropProtection,
+			TrashRetention:  opts.TrashRetention,
+			ArchiveInterval: opts.ArchiveInterval,
+			AnalyzeInterval: opts.AnalyzeInterval,
+			CollectionStats: opts.CollectionStats,
+
+			MaterializedViewsInterval: opts.MaterializedViewsInterval,
+
+			DiagnosticsDir:      opts.DiagnosticsDir,
+			DiagnosticsInterval: opts.DiagnosticsInterval,
+
+			ImportDir: opts.ImportDir,
+			ExportDir: opts.ExportDir,
+			InitDir:   opts.InitDir,
+
+			ImportURLHosts: opts.ImportURLHosts,
+
+			SQLStage: opts.SQLStage,
+
+			AdminUsers: opts.AdminUsers,
 
 			DisableFilterPushdown:    opts.DisableFilterPushdown,
 			EnableUnsafeSortPushdown: opts.EnableUnsafeSortPushdown,
@@ -17,11 +17,14 @@ package registry
 
 import (
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
+	"github.com/FerretDB/FerretDB/internal/clientconn/failpoints"
 	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/dropprotection"
 	"github.com/FerretDB/FerretDB/internal/util/state"
 )
 
@@ -40,9 +43,37 @@ type NewHandlerOpts struct {
 	Logger        *zap.Logger
 	ConnMetrics   *connmetrics.ConnMetrics
 	StateProvider *state.Provider
+	FailPoints    *failpoints.Registry // nil disables configureFailPoint command
+
+	SizeCacheMaxAge time.Duration         // 0 disables database size caching
+	QueryCacheSize  int                   // 0 disables query result caching
+	DropProtection  *dropprotection.Guard // nil disables drop protection
+	TrashRetention  time.Duration         // 0 disables keeping dropped collections in the trash
+	ArchiveInterval time.Duration         // 0 disables applying archiving policies
+	AnalyzeInterval time.Duration         // 0 disables periodic statistics refresh
+	CollectionStats bool                  // enables per-collection latency and document size histograms
+
+	MaterializedViewsInterval time.Duration // 0 disables periodic refresh of materialized views
+
+	DiagnosticsDir      string        // empty disables writing diagnostic data snapshots
+	DiagnosticsInterval time.Duration // 0 disables writing diagnostic data snapshots
+
+	ImportDir string // empty disables importing from files
+	ExportDir string // empty disables exporting
+	InitDir   string // empty disables seeding on the first start
+
+	ImportURLHosts []string // empty disables importing from URLs
+
+	SQLStage bool // enables $sql aggregation stage with raw SQL queries
+
+	AdminUsers []string // users allowed to import from URLs and use $sql stage
 
 	// for `postgresql` handler
-	PostgreSQLURL string
+	PostgreSQLURL              string
+	PostgreSQLRelationalViews  bool
+	PostgreSQLStatementTimeout time.Duration // 0 keeps the server's setting
+	PostgreSQLLockTimeout      time.Duration // 0 keeps the server's setting
+	PostgreSQLTenantIsolation  bool
 
 	// for `sqlite` handler
 	SQLiteURL string
@@ -18,8 +18,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
 )
 
 func convertDocument(d *types.Document) *documentType {
@@ -475,3 +479,40 @@ func FuzzDocumentWithFixedDocuments(f *testing.F) {
 func BenchmarkDocument(b *testing.B) {
 	benchmark(b, documentTestCases, func() sjsontype { return new(documentType) })
 }
+
+func BenchmarkDocumentLarge(b *testing.B) {
+	doc := testutil.LargeDocument(1000)
+	data := must.NotFail(Marshal(doc))
+
+	b.Run("Marshal", func(b *testing.B) {
+		var err error
+
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+
+		for i := 0; i < b.N; i++ {
+			_, err = Marshal(doc)
+		}
+
+		b.StopTimer()
+
+		require.NoError(b, err)
+	})
+
+	b.Run("Unmarshal", func(b *testing.B) {
+		var actual *types.Document
+		var err error
+
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+
+		for i := 0; i < b.N; i++ {
+			actual, err = Unmarshal(data)
+		}
+
+		b.StopTimer()
+
+		require.NoError(b, err)
+		assert.Equal(b, doc.Len(), actual.Len())
+	})
+}
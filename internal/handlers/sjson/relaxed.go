@@ -0,0 +1,280 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sjson
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// RelaxedDecoder reads documents from a stream of JSON objects
+// without sjson schema, mapping values to types as mongoimport does:
+// integer numbers become int32 or int64 (if they do not fit into int32), other numbers become float64.
+// The following Extended JSON wrappers are supported:
+// `{"$oid": "<hex>"}`, `{"$date": "<RFC 3339>" or <milliseconds>}`,
+// `{"$numberInt": "<n>"}`, `{"$numberLong": "<n>"}`, and `{"$numberDouble": "<n>"}`.
+type RelaxedDecoder struct {
+	d *json.Decoder
+	n int
+}
+
+// NewRelaxedDecoder returns a new decoder that reads JSON objects from r.
+func NewRelaxedDecoder(r io.Reader) *RelaxedDecoder {
+	d := json.NewDecoder(r)
+	d.UseNumber()
+
+	return &RelaxedDecoder{d: d}
+}
+
+// UnmarshalRelaxed converts a single JSON object to a document
+// using the same rules as RelaxedDecoder.
+func UnmarshalRelaxed(b []byte) (*types.Document, error) {
+	r := NewRelaxedDecoder(bytes.NewReader(b))
+
+	doc, err := r.Decode()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("expected object, got nothing")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if r.d.More() {
+		return nil, errors.New("unexpected data after object")
+	}
+
+	return doc, nil
+}
+
+// Decode returns the next document, or io.EOF if there are no more documents.
+func (r *RelaxedDecoder) Decode() (*types.Document, error) {
+	if !r.d.More() {
+		return nil, io.EOF
+	}
+
+	r.n++
+
+	t, err := r.d.Token()
+	if err != nil {
+		return nil, fmt.Errorf("document %d: %w", r.n, err)
+	}
+
+	if t != json.Delim('{') {
+		return nil, fmt.Errorf("document %d: expected object, got %v", r.n, t)
+	}
+
+	v, err := r.readObject()
+	if err != nil {
+		return nil, fmt.Errorf("document %d: %w", r.n, err)
+	}
+
+	doc, ok := v.(*types.Document)
+	if !ok {
+		return nil, fmt.Errorf("document %d: expected object, got %T", r.n, v)
+	}
+
+	return doc, nil
+}
+
+// readValue reads the next JSON value.
+func (r *RelaxedDecoder) readValue() (any, error) {
+	t, err := r.d.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := t.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			return r.readObject()
+		case '[':
+			return r.readArray()
+		default:
+			return nil, fmt.Errorf("unexpected %v", t)
+		}
+
+	case nil:
+		return types.Null, nil
+
+	case bool, string:
+		return t, nil
+
+	case json.Number:
+		return ParseRelaxedNumber(string(t))
+
+	default:
+		return nil, fmt.Errorf("unexpected token %v", t)
+	}
+}
+
+// readObject reads JSON object after the opening brace
+// and converts it to a document or a value of Extended JSON wrapper.
+func (r *RelaxedDecoder) readObject() (any, error) {
+	doc := types.MakeDocument(0)
+
+	for r.d.More() {
+		t, err := r.d.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		key, ok := t.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected %v", t)
+		}
+
+		v, err := r.readValue()
+		if err != nil {
+			return nil, err
+		}
+
+		doc.Set(key, v)
+	}
+
+	if _, err := r.d.Token(); err != nil {
+		return nil, err
+	}
+
+	if doc.Len() == 1 {
+		return unwrapExtJSON(doc)
+	}
+
+	return doc, nil
+}
+
+// readArray reads JSON array after the opening bracket.
+func (r *RelaxedDecoder) readArray() (*types.Array, error) {
+	arr := types.MakeArray(0)
+
+	for r.d.More() {
+		v, err := r.readValue()
+		if err != nil {
+			return nil, err
+		}
+
+		arr.Append(v)
+	}
+
+	if _, err := r.d.Token(); err != nil {
+		return nil, err
+	}
+
+	return arr, nil
+}
+
+// unwrapExtJSON returns a value for the given single-field document
+// if it is a supported Extended JSON wrapper, or the document itself otherwise.
+func unwrapExtJSON(doc *types.Document) (any, error) {
+	key := doc.Keys()[0]
+	v := doc.Values()[0]
+
+	switch key {
+	case "$oid":
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid $oid value %v", v)
+		}
+
+		b, err := hex.DecodeString(s)
+		if err != nil || len(b) != 12 {
+			return nil, fmt.Errorf("invalid $oid value %q", s)
+		}
+
+		return types.ObjectID(b), nil
+
+	case "$date":
+		switch v := v.(type) {
+		case string:
+			t, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid $date value %q", v)
+			}
+
+			return t.UTC(), nil
+
+		case int32:
+			return time.UnixMilli(int64(v)).UTC(), nil
+
+		case int64:
+			return time.UnixMilli(v).UTC(), nil
+
+		default:
+			return nil, fmt.Errorf("invalid $date value %v", v)
+		}
+
+	case "$numberInt":
+		s, _ := v.(string)
+
+		n, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid $numberInt value %v", v)
+		}
+
+		return int32(n), nil
+
+	case "$numberLong":
+		s, _ := v.(string)
+
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid $numberLong value %v", v)
+		}
+
+		return n, nil
+
+	case "$numberDouble":
+		s, _ := v.(string)
+
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid $numberDouble value %v", v)
+		}
+
+		return f, nil
+
+	default:
+		return doc, nil
+	}
+}
+
+// ParseRelaxedNumber converts the given number to int32, int64, or float64
+// like RelaxedDecoder does.
+func ParseRelaxedNumber(s string) (any, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if n >= math.MinInt32 && n <= math.MaxInt32 {
+			return int32(n), nil
+		}
+
+		return n, nil
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return nil, err
+	}
+
+	return f, nil
+}
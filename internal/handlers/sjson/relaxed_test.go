@@ -0,0 +1,42 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sjson
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestUnmarshalRelaxed(t *testing.T) {
+	t.Parallel()
+
+	doc, err := UnmarshalRelaxed([]byte(`{"a": 1, "b": {"$numberLong": "2"}}`))
+	require.NoError(t, err)
+	testutil.AssertEqual(t, must.NotFail(types.NewDocument("a", int32(1), "b", int64(2))), doc)
+
+	_, err = UnmarshalRelaxed([]byte(`{"a": 1} {"b": 2}`))
+	require.EqualError(t, err, "unexpected data after object")
+
+	_, err = UnmarshalRelaxed([]byte(`[1]`))
+	require.Error(t, err)
+
+	_, err = UnmarshalRelaxed(nil)
+	require.EqualError(t, err, "expected object, got nothing")
+}
@@ -0,0 +1,48 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// analyze refreshes statistics of all databases (that is, runs ANALYZE or similar operation).
+func (h *Handler) analyze(ctx context.Context) error {
+	res, err := h.b.ListDatabases(ctx, nil)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	var errs []error
+
+	for _, dbInfo := range res.Databases {
+		db, err := h.b.Database(dbInfo.Name)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		_, err = db.Stats(ctx, &backends.DatabaseStatsParams{Refresh: true})
+		if err != nil && !backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseDoesNotExist) {
+			errs = append(errs, fmt.Errorf("%s: %w", dbInfo.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
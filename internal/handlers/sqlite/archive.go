@@ -0,0 +1,288 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// archivePolicy represents collection-level document archiving policy.
+//
+// Documents matching the filter with the date in the given field older than the given number of days
+// are moved to another collection of the same database.
+// For the `_id` field, the ObjectID's timestamp is used.
+type archivePolicy struct {
+	filter     *types.Document
+	field      string
+	days       int64
+	to         string
+	tablespace string // empty for the default tablespace
+}
+
+// marshal returns the value of the archive collection setting.
+func (p *archivePolicy) marshal() *types.Document {
+	return must.NotFail(types.NewDocument(
+		"filter", p.filter,
+		"field", p.field,
+		"days", p.days,
+		"to", p.to,
+		"tablespace", p.tablespace,
+	))
+}
+
+// unmarshalArchivePolicy returns archiving policy for the given value of the archive collection setting.
+func unmarshalArchivePolicy(v any) (*archivePolicy, error) {
+	doc, ok := v.(*types.Document)
+	if !ok {
+		return nil, lazyerrors.Errorf("invalid %q setting: %T", settingArchive, v)
+	}
+
+	var res archivePolicy
+
+	v, _ = doc.Get("filter")
+	res.filter, _ = v.(*types.Document)
+
+	v, _ = doc.Get("field")
+	res.field, _ = v.(string)
+
+	v, _ = doc.Get("days")
+	res.days, _ = v.(int64)
+
+	v, _ = doc.Get("to")
+	res.to, _ = v.(string)
+
+	v, _ = doc.Get("tablespace")
+	res.tablespace, _ = v.(string)
+
+	if res.filter == nil || res.field == "" || res.days <= 0 || res.to == "" {
+		return nil, lazyerrors.Errorf("invalid %q setting: %v", settingArchive, doc)
+	}
+
+	return &res, nil
+}
+
+// archive applies all archiving policies once.
+func (h *Handler) archive(ctx context.Context) error {
+	settings, err := h.listCollectionSetting(ctx, "", settingArchive)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	var errs []error
+
+	for _, s := range settings {
+		key := collectionKey(s.dbName, s.cName)
+
+		policy, err := unmarshalArchivePolicy(s.v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+
+		n, err := h.archiveCollection(ctx, s.dbName, s.cName, policy)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+
+		if n > 0 {
+			h.L.Info("Archived documents", zap.String("ns", key), zap.String("to", policy.to), zap.Int("count", n))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// archiveBatchSize is the maximal number of documents moved to the archive collection at once.
+const archiveBatchSize = 1000
+
+// archiveCollection moves documents according to the given policy.
+// It returns the number of moved documents.
+//
+// Documents are moved in batches, so they are never all loaded into memory.
+// Documents of each batch are inserted into the archive collection before they are deleted from the source collection,
+// so they are never lost; documents that are already archived by the interrupted run are skipped.
+func (h *Handler) archiveCollection(ctx context.Context, dbName, cName string, policy *archivePolicy) (int, error) {
+	db, err := h.b.Database(dbName)
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	c, err := db.Collection(cName)
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	archive, err := db.Collection(policy.to)
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	filter, cond := policy.filters(h.now())
+
+	var moved int
+
+	for {
+		docs, err := archiveBatch(ctx, c, filter, policy.filter, cond)
+		if err != nil {
+			return moved, lazyerrors.Error(err)
+		}
+
+		if len(docs) == 0 {
+			return moved, nil
+		}
+
+		if moved == 0 && policy.tablespace != "" {
+			err = db.CreateCollection(ctx, &backends.CreateCollectionParams{Name: policy.to, Tablespace: policy.tablespace})
+			if err != nil && !backends.ErrorCodeIs(err, backends.ErrorCodeCollectionAlreadyExists) {
+				return moved, lazyerrors.Error(err)
+			}
+		}
+
+		if err = archiveInsert(ctx, archive, docs); err != nil {
+			return moved, lazyerrors.Error(err)
+		}
+
+		ids := make([]any, len(docs))
+		for i, doc := range docs {
+			ids[i] = must.NotFail(doc.Get("_id"))
+		}
+
+		res, err := c.DeleteAll(ctx, &backends.DeleteAllParams{IDs: ids})
+		if err != nil {
+			return moved, lazyerrors.Error(err)
+		}
+
+		moved += int(res.Deleted)
+
+		// the last batch, or documents were deleted concurrently
+		if len(docs) < archiveBatchSize || res.Deleted == 0 {
+			return moved, nil
+		}
+	}
+}
+
+// filters returns the filter that is pushed down to the backend
+// and the condition on the policy field for the given current time.
+//
+// Both the policy filter and the condition should be checked for returned documents.
+func (p *archivePolicy) filters(now time.Time) (*types.Document, *types.Document) {
+	var cutoff any = now.Add(-time.Duration(p.days) * 24 * time.Hour)
+
+	// the ObjectID's timestamp is used for _id
+	if p.field == "_id" {
+		var id types.ObjectID
+		binary.BigEndian.PutUint32(id[0:4], uint32(cutoff.(time.Time).Unix()))
+		cutoff = id
+	}
+
+	lt := must.NotFail(types.NewDocument("$lt", cutoff))
+	cond := must.NotFail(types.NewDocument(p.field, lt))
+
+	filter := p.filter.DeepCopy()
+	if !filter.Has(p.field) {
+		filter.Set(p.field, lt)
+	}
+
+	return filter, cond
+}
+
+// archiveBatch returns up to archiveBatchSize documents of the given collection
+// that match both the filter and the condition.
+//
+// The pushdown filter is passed to the backend; it may return more documents.
+//
+//nolint:lll // for readability
+func archiveBatch(ctx context.Context, c backends.Collection, pushdown, filter, cond *types.Document) ([]*types.Document, error) {
+	qr, err := c.Query(ctx, &backends.QueryParams{Filter: pushdown})
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist) {
+			return nil, nil
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	iter := qr.Iter
+	defer iter.Close()
+
+	var res []*types.Document
+
+	for len(res) < archiveBatchSize {
+		_, doc, err := iter.Next()
+		if err != nil {
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				break
+			}
+
+			return nil, lazyerrors.Error(err)
+		}
+
+		matches, err := common.FilterDocument(doc, cond)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if !matches {
+			continue
+		}
+
+		if matches, err = common.FilterDocument(doc, filter); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if matches {
+			res = append(res, doc)
+		}
+	}
+
+	return res, nil
+}
+
+// archiveInsert inserts documents into the archive collection.
+//
+// If some of them are already there, others are inserted one by one.
+func archiveInsert(ctx context.Context, archive backends.Collection, docs []*types.Document) error {
+	_, err := archive.InsertAll(ctx, &backends.InsertAllParams{Docs: docs})
+	if err == nil {
+		return nil
+	}
+
+	if !backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID) {
+		return lazyerrors.Error(err)
+	}
+
+	for _, doc := range docs {
+		_, err = archive.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{doc}})
+		if err != nil && !backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID) {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return nil
+}
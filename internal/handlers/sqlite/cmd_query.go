@@ -22,6 +22,7 @@ import (
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
 	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
@@ -33,7 +34,12 @@ func (h *Handler) CmdQuery(ctx context.Context, query *wire.OpQuery) (*wire.OpRe
 
 	// both are valid and are allowed to be run against any database as we don't support authorization yet
 	if (cmd == "ismaster" || cmd == "isMaster") && strings.HasSuffix(collection, ".$cmd") {
-		return common.IsMaster(ctx, query.Query)
+		return common.IsMaster(ctx, query.Query, h.now())
+	}
+
+	// older drivers and shells may send hello via OP_QUERY too
+	if cmd == "hello" && strings.HasSuffix(collection, ".$cmd") {
+		return cmdQueryMsg(ctx, query, h.MsgHello)
 	}
 
 	// TODO https://github.com/FerretDB/FerretDB/issues/3008
@@ -59,3 +65,30 @@ func (h *Handler) CmdQuery(ctx context.Context, query *wire.OpQuery) (*wire.OpRe
 		"OpQuery: "+cmd,
 	)
 }
+
+// cmdQueryMsg runs OP_QUERY command using the given OP_MSG handler method
+// and returns its result as OP_REPLY.
+func cmdQueryMsg(ctx context.Context, query *wire.OpQuery, method func(context.Context, *wire.OpMsg) (*wire.OpMsg, error)) (*wire.OpReply, error) { //nolint:lll // for readability
+	doc := query.Query.DeepCopy()
+	doc.Set("$db", strings.TrimSuffix(query.FullCollectionName, ".$cmd"))
+
+	var msg wire.OpMsg
+	must.NoError(msg.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{doc},
+	}))
+
+	resMsg, err := method(ctx, &msg)
+	if err != nil {
+		return nil, err
+	}
+
+	resDoc, err := resMsg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &wire.OpReply{
+		NumberReturned: 1,
+		Documents:      []*types.Document{resDoc},
+	}, nil
+}
@@ -0,0 +1,343 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// Fields of the collection settings document stored in the backend's collection metadata;
+// see [backends.Database.SetCollectionSettings].
+//
+// Settings are kept when the collection is renamed and removed when it is dropped.
+const (
+	settingArchive       = "archive"       // archive policy, see archivePolicy
+	settingDefaultIDType = "defaultIdType" // type of generated _id values; ObjectID is used if unset
+	settingDefaults      = "defaults"      // default values or expressions of fields missing in inserted documents
+	settingHistory       = "history"       // name of the collection that keeps previous versions of documents
+	settingMaterialized  = "materialized"  // materialized view definition, see materializedView
+)
+
+// Types of generated _id values.
+const (
+	idTypeObjectID = "objectId"
+	idTypeUUID     = "uuid"
+)
+
+// collectionKey returns the "db.collection" key of the given collection.
+func collectionKey(dbName, cName string) string {
+	return dbName + "." + cName
+}
+
+// collectionSettings returns settings of the given collection.
+//
+// Empty document is returned if the collection does not exist or has no settings.
+// It can be safely modified by a caller.
+func collectionSettings(ctx context.Context, db backends.Database, cName string) (*types.Document, error) {
+	res, err := db.ListCollections(ctx, &backends.ListCollectionsParams{Name: cName})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if len(res.Collections) == 0 || res.Collections[0].Settings == nil {
+		return must.NotFail(types.NewDocument()), nil
+	}
+
+	return res.Collections[0].Settings, nil
+}
+
+// setCollectionSetting stores the value of the given setting of the existing collection;
+// nil value removes the setting.
+//
+// Updates are serialized to avoid losing concurrent changes of other settings.
+func (h *Handler) setCollectionSetting(ctx context.Context, db backends.Database, cName, key string, v any) error {
+	h.settingsM.Lock()
+	defer h.settingsM.Unlock()
+
+	settings, err := collectionSettings(ctx, db, cName)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if v == nil {
+		if !settings.Has(key) {
+			return nil
+		}
+
+		settings.Remove(key)
+	} else {
+		settings.Set(key, v)
+	}
+
+	params := &backends.SetCollectionSettingsParams{Name: cName}
+	if settings.Len() > 0 {
+		params.Settings = settings
+	}
+
+	if err = db.SetCollectionSettings(ctx, params); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// collectionSetting represents the value of the setting of a single collection.
+type collectionSetting struct {
+	dbName string
+	cName  string
+	v      any
+}
+
+// listCollectionSetting returns values of the given setting of all collections that have it,
+// in the given database or in all databases if dbName is empty.
+func (h *Handler) listCollectionSetting(ctx context.Context, dbName, key string) ([]collectionSetting, error) {
+	dbNames := []string{dbName}
+
+	if dbName == "" {
+		res, err := h.b.ListDatabases(ctx, nil)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		dbNames = make([]string, len(res.Databases))
+		for i, dbInfo := range res.Databases {
+			dbNames[i] = dbInfo.Name
+		}
+	}
+
+	var res []collectionSetting
+
+	for _, dbName := range dbNames {
+		db, err := h.b.Database(dbName)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		list, err := db.ListCollections(ctx, nil)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		for _, c := range list.Collections {
+			if c.Settings == nil {
+				continue
+			}
+
+			if v, _ := c.Settings.Get(key); v != nil {
+				res = append(res, collectionSetting{dbName: dbName, cName: c.Name, v: v})
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// getDefaultIDType returns the type of generated _id values for the given `defaultIdType` field value.
+func getDefaultIDType(command string, v any) (string, error) {
+	idType, ok := v.(string)
+	if !ok {
+		return "", commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			fmt.Sprintf("'defaultIdType' must be a string, not %s", commonparams.AliasFromType(v)),
+			command,
+		)
+	}
+
+	switch idType {
+	case idTypeObjectID, idTypeUUID:
+		return idType, nil
+	default:
+		return "", commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("'defaultIdType' must be %q or %q, not %q", idTypeObjectID, idTypeUUID, idType),
+			command,
+		)
+	}
+}
+
+// setDefaultIDType stores the type of generated _id values for the given collection.
+func (h *Handler) setDefaultIDType(ctx context.Context, db backends.Database, cName, idType string) error {
+	var v any
+	if idType != idTypeObjectID {
+		v = idType
+	}
+
+	return h.setCollectionSetting(ctx, db, cName, settingDefaultIDType, v)
+}
+
+// newIDFunc returns a function that generates _id values for documents without them
+// inserted or upserted into the collection with the given settings.
+func (h *Handler) newIDFunc(settings *types.Document) func() any {
+	if v, _ := settings.Get(settingDefaultIDType); v == idTypeUUID {
+		return func() any { return types.NewUUIDv7(h.now()) }
+	}
+
+	return func() any { return h.newObjectID() }
+}
+
+// getFieldDefaults returns field defaults for the given `defaults` field value,
+// or nil if it is "off".
+//
+// The value is a document where keys are top-level field names (except _id)
+// and values are literal values or aggregation expressions (like "$field" or {$concat: [...]})
+// evaluated against the inserted document.
+func getFieldDefaults(command string, v any) (*types.Document, error) {
+	if v == "off" {
+		return nil, nil
+	}
+
+	doc, ok := v.(*types.Document)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			fmt.Sprintf("'defaults' must be a document or \"off\", not %s", commonparams.AliasFromType(v)),
+			command,
+		)
+	}
+
+	if doc.Len() == 0 {
+		return nil, nil
+	}
+
+	for _, k := range doc.Keys() {
+		if k == "" || k == "_id" || strings.HasPrefix(k, "$") || strings.Contains(k, ".") {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf("invalid default field name %q: top-level field names except _id are allowed", k),
+				command,
+			)
+		}
+
+		if _, err := operators.NewExpression(must.NotFail(doc.Get(k)), command); err != nil {
+			return nil, err
+		}
+	}
+
+	return doc, nil
+}
+
+// fieldDefaultsFunc returns a function that sets defaults of fields missing in documents
+// inserted into the collection with the given settings, or nil if the collection has no defaults.
+//
+// Defaults are applied in order, so expressions can use fields set by previous defaults.
+func fieldDefaultsFunc(settings *types.Document) (func(doc *types.Document) error, error) {
+	v, _ := settings.Get(settingDefaults)
+	if v == nil {
+		return nil, nil
+	}
+
+	defaults, ok := v.(*types.Document)
+	if !ok {
+		return nil, lazyerrors.Errorf("invalid %q setting: %T", settingDefaults, v)
+	}
+
+	keys := defaults.Keys()
+	ops := make([]operators.Operator, len(keys))
+
+	for i, k := range keys {
+		var err error
+		if ops[i], err = operators.NewExpression(must.NotFail(defaults.Get(k)), "insert"); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	return func(doc *types.Document) error {
+		for i, k := range keys {
+			if doc.Has(k) {
+				continue
+			}
+
+			v, err := ops[i].Process(doc)
+			if err != nil {
+				return fmt.Errorf("failed to compute default value of field %q: %w", k, err)
+			}
+
+			doc.Set(k, v)
+		}
+
+		return nil
+	}, nil
+}
+
+// getHistoryCollection returns the history collection name for the given `history` field value,
+// or empty string if history should be disabled.
+//
+// The value is either a boolean (`true` uses `<collection>_history`) or a collection name in the same database.
+func getHistoryCollection(command, cName string, v any) (string, error) {
+	var name string
+
+	switch v := v.(type) {
+	case bool:
+		if !v {
+			return "", nil
+		}
+
+		name = cName + "_history"
+
+	case string:
+		name = v
+
+	default:
+		return "", commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			fmt.Sprintf("'history' must be a boolean or a string, not %s", commonparams.AliasFromType(v)),
+			command,
+		)
+	}
+
+	if name == "" {
+		return "", commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"history collection name must not be empty",
+			command,
+		)
+	}
+
+	if name == cName {
+		return "", commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"history collection must differ from the source collection",
+			command,
+		)
+	}
+
+	return name, nil
+}
+
+// historyCollectionName returns the history collection name for the given collection,
+// or empty string if history is disabled for it.
+//
+// It implements history.CollectionFunc.
+func historyCollectionName(ctx context.Context, db backends.Database, cName string) (string, error) {
+	settings, err := collectionSettings(ctx, db, cName)
+	if err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	name, _ := settings.Get(settingHistory)
+	res, _ := name.(string)
+
+	return res, nil
+}
@@ -0,0 +1,235 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/sjson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// collectionWriter implements aggregations.CollectionWriter for stages such as $out and $merge.
+type collectionWriter struct {
+	h      *Handler
+	dbName string // current database
+}
+
+// collectionWriter returns a writer for collections of the given current database and other databases.
+func (h *Handler) collectionWriter(dbName string) aggregations.CollectionWriter {
+	return &collectionWriter{
+		h:      h,
+		dbName: dbName,
+	}
+}
+
+// database returns the backend database with the given name, or the current database if name is empty.
+func (w *collectionWriter) database(dbName, cName string) (backends.Database, string, error) {
+	if dbName == "" {
+		dbName = w.dbName
+	}
+
+	db, err := w.h.b.Database(dbName)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", dbName, cName)
+			return nil, "", commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, "aggregate")
+		}
+
+		return nil, "", lazyerrors.Error(err)
+	}
+
+	return db, dbName, nil
+}
+
+// Query implements aggregations.CollectionWriter interface.
+func (w *collectionWriter) Query(ctx context.Context, dbName, cName string) (types.DocumentsIterator, error) {
+	db, dbName, err := w.database(dbName, cName)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.h.collectionQuery(db, dbName)(ctx, cName, nil)
+}
+
+// Write implements aggregations.CollectionWriter interface.
+func (w *collectionWriter) Write(ctx context.Context, params *aggregations.WriteParams) error {
+	db, dbName, err := w.database(params.DB, params.Collection)
+	if err != nil {
+		return err
+	}
+
+	err = db.CreateCollection(ctx, &backends.CreateCollectionParams{Name: params.Collection})
+
+	switch {
+	case err == nil:
+		// nothing
+	case backends.ErrorCodeIs(err, backends.ErrorCodeCollectionAlreadyExists):
+		// nothing
+	case backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid):
+		msg := fmt.Sprintf("Invalid collection name: %s", params.Collection)
+		return commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, "aggregate")
+	default:
+		return lazyerrors.Error(err)
+	}
+
+	c, err := db.Collection(params.Collection)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	settings, err := collectionSettings(ctx, db, params.Collection)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	newID := w.h.newIDFunc(settings)
+
+	for _, doc := range params.Insert {
+		if !doc.Has("_id") {
+			doc.Set("_id", newID())
+		}
+	}
+
+	for _, docs := range [][]*types.Document{params.Insert, params.Update} {
+		for _, doc := range docs {
+			if err = validateWrittenDocument(doc); err != nil {
+				return err
+			}
+		}
+	}
+
+	duplicateErr := commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrDuplicateKeyInsert,
+		fmt.Sprintf("E11000 duplicate key error collection: %s.%s", dbName, params.Collection),
+		"aggregate",
+	)
+
+	if params.Replace {
+		// check duplicates before existing documents are removed
+		if _, ok := duplicateID(params.Insert); ok {
+			return duplicateErr
+		}
+
+		return replaceDocuments(ctx, c, params.Insert)
+	}
+
+	if len(params.Update) > 0 {
+		if _, err = c.UpdateAll(ctx, &backends.UpdateAllParams{Docs: params.Update}); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	if len(params.Insert) > 0 {
+		_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: params.Insert})
+
+		switch {
+		case err == nil:
+			// nothing
+		case backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID):
+			return duplicateErr
+		default:
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// validateWrittenDocument returns an error if the given document could not be stored.
+func validateWrittenDocument(doc *types.Document) error {
+	err := doc.ValidateData()
+	if err == nil {
+		return nil
+	}
+
+	var ve *types.ValidationError
+	if !errors.As(err, &ve) {
+		return lazyerrors.Error(err)
+	}
+
+	code := commonerrors.ErrBadValue
+	if ve.Code() == types.ErrWrongIDType {
+		code = commonerrors.ErrInvalidID
+	}
+
+	return commonerrors.NewCommandErrorMsgWithArgument(code, ve.Error(), "aggregate")
+}
+
+// duplicateID returns the first duplicated _id value of the given documents.
+// Values are compared by their JSON representation.
+func duplicateID(docs []*types.Document) (any, bool) {
+	ids := make(map[string]struct{}, len(docs))
+
+	for _, doc := range docs {
+		id := must.NotFail(doc.Get("_id"))
+		key := string(must.NotFail(sjson.MarshalSingleValue(id)))
+
+		if _, ok := ids[key]; ok {
+			return id, true
+		}
+
+		ids[key] = struct{}{}
+	}
+
+	return nil, false
+}
+
+// replaceDocuments replaces all documents of the given collection with the given documents.
+//
+// Readers may observe the collection empty or partially filled during the replacement.
+func replaceDocuments(ctx context.Context, c backends.Collection, docs []*types.Document) error {
+	qr, err := c.Query(ctx, nil)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	existing, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](qr.Iter))
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if len(existing) > 0 {
+		ids := make([]any, len(existing))
+		for i, doc := range existing {
+			ids[i] = must.NotFail(doc.Get("_id"))
+		}
+
+		if _, err = c.DeleteAll(ctx, &backends.DeleteAllParams{IDs: ids}); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	if len(docs) > 0 {
+		if _, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: docs}); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// check interfaces
+var (
+	_ aggregations.CollectionWriter = (*collectionWriter)(nil)
+)
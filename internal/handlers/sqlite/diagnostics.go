@@ -0,0 +1,57 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// diagnosticData returns a diagnostic data snapshot in the same shape as MongoDB's FTDC:
+// `{start: <date>, serverStatus: {...}, end: <date>}`.
+func (h *Handler) diagnosticData(ctx context.Context) (*types.Document, error) {
+	start := h.now()
+
+	serverStatus, err := h.serverStatus(ctx)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	serverStatus.Remove("ok")
+
+	return must.NotFail(types.NewDocument(
+		"start", start,
+		"serverStatus", serverStatus,
+		"end", h.now(),
+	)), nil
+}
+
+// writeDiagnosticData writes a diagnostic data snapshot to the diagnostics directory.
+func (h *Handler) writeDiagnosticData(ctx context.Context) error {
+	data, err := h.diagnosticData(ctx)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if err = h.ftdc.Write(must.NotFail(data.Get("start")).(time.Time), data); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,480 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// sphereIndexVersion is the only supported 2dsphere index version.
+const sphereIndexVersion = int32(3)
+
+// earthRadius is the radius of the Earth in meters that is used to convert radians to meters,
+// the same as in MongoDB.
+const earthRadius = 6378100.0
+
+// geoOperators are geospatial query operators.
+var geoOperators = []string{"$geoWithin", "$geoIntersects", "$near", "$nearSphere"}
+
+// geoJSONDepths maps supported GeoJSON geometry types to the nesting depth of their coordinates arrays.
+var geoJSONDepths = map[string]int{
+	"Point":           0,
+	"LineString":      1,
+	"MultiPoint":      1,
+	"Polygon":         2,
+	"MultiLineString": 2,
+	"MultiPolygon":    3,
+}
+
+// prepareGeoQuery extracts the top-level geospatial query operator from the given filter.
+//
+// It returns nil GeoQuery and the same filter if there is none.
+// Otherwise, it returns parsed operator and a copy of the filter without it.
+// It checks that the backend supports geospatial queries,
+// and that the collection has a 2dsphere index for $near and $nearSphere.
+// Those operators are allowed only for the find command.
+//
+// Geospatial operators in other places (like $or) are left as is, and are rejected by the filter.
+func (h *Handler) prepareGeoQuery(ctx context.Context, c backends.Collection, command string, filter *types.Document) (*backends.GeoQuery, *types.Document, error) { //nolint:lll // for readability
+	var res *backends.GeoQuery
+	var resFilter *types.Document
+
+	for _, field := range filter.Keys() {
+		if strings.HasPrefix(field, "$") {
+			continue
+		}
+
+		expr, ok := must.NotFail(filter.Get(field)).(*types.Document)
+		if !ok {
+			continue
+		}
+
+		i := slices.IndexFunc(expr.Keys(), func(k string) bool { return slices.Contains(geoOperators, k) })
+		if i < 0 {
+			continue
+		}
+
+		op := expr.Keys()[i]
+
+		if res != nil {
+			return nil, nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				"Multiple geospatial query operators are not implemented yet",
+				op,
+			)
+		}
+
+		if err := common.CheckCapability(h.StateProvider.Get(), backends.CapabilityPostGIS, op, op); err != nil {
+			return nil, nil, err
+		}
+
+		near := op == "$near" || op == "$nearSphere"
+		if near && command != "find" {
+			return nil, nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				"$geoNear, $near, and $nearSphere are not allowed in this context",
+				op,
+			)
+		}
+
+		var err error
+
+		if res, err = parseGeoQuery(field, op, expr); err != nil {
+			return nil, nil, err
+		}
+
+		if near {
+			if err = checkSphereIndex(ctx, c, field); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		if resFilter == nil {
+			resFilter = filter.DeepCopy()
+		}
+
+		rest := expr.DeepCopy()
+		rest.Remove(op)
+
+		if near {
+			rest.Remove("$minDistance")
+			rest.Remove("$maxDistance")
+		}
+
+		if rest.Len() == 0 {
+			resFilter.Remove(field)
+		} else {
+			resFilter.Set(field, rest)
+		}
+	}
+
+	if res == nil {
+		return nil, filter, nil
+	}
+
+	return res, resFilter, nil
+}
+
+// checkSphereIndex returns an error if the collection does not have a 2dsphere index on the given field.
+func checkSphereIndex(ctx context.Context, c backends.Collection, field string) error {
+	res, err := c.ListIndexes(ctx, new(backends.ListIndexesParams))
+	if err != nil && !backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist) {
+		return lazyerrors.Error(err)
+	}
+
+	if res == nil || !slices.ContainsFunc(res.Indexes, func(index backends.IndexInfo) bool {
+		return index.Sphere && index.Key[0].Field == field
+	}) {
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrIndexNotFound,
+			"unable to find index for $geoNear query",
+			"$near",
+		)
+	}
+
+	return nil
+}
+
+// parseGeoQuery parses the given geospatial query operator of the field's expression.
+func parseGeoQuery(field, op string, expr *types.Document) (*backends.GeoQuery, error) {
+	v := must.NotFail(expr.Get(op))
+
+	switch op {
+	case "$geoWithin":
+		return parseGeoWithin(field, v)
+
+	case "$geoIntersects":
+		spec, ok := v.(*types.Document)
+		if !ok || !spec.Has("$geometry") {
+			return nil, newGeoBadValueError(op, "$geoIntersects requires a $geometry argument")
+		}
+
+		geometry, err := parseGeoJSON(op, must.NotFail(spec.Get("$geometry")), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		return &backends.GeoQuery{
+			Field:    field,
+			Operator: backends.GeoIntersects,
+			Geometry: geometry,
+		}, nil
+
+	case "$near", "$nearSphere":
+		return parseGeoNear(field, op, expr)
+
+	default:
+		panic(fmt.Sprintf("unexpected geo operator %q", op))
+	}
+}
+
+// parseGeoWithin parses the value of $geoWithin query operator.
+func parseGeoWithin(field string, v any) (*backends.GeoQuery, error) {
+	const op = "$geoWithin"
+
+	spec, ok := v.(*types.Document)
+	if !ok || spec.Len() != 1 {
+		return nil, newGeoBadValueError(op, "$geoWithin requires exactly one shape specifier")
+	}
+
+	shape := spec.Keys()[0]
+	shapeV := must.NotFail(spec.Get(shape))
+
+	switch shape {
+	case "$geometry":
+		geometry, err := parseGeoJSON(op, shapeV, []string{"Polygon", "MultiPolygon"})
+		if err != nil {
+			return nil, err
+		}
+
+		return &backends.GeoQuery{
+			Field:    field,
+			Operator: backends.GeoWithin,
+			Geometry: geometry,
+		}, nil
+
+	case "$centerSphere":
+		arr, ok := shapeV.(*types.Array)
+		if !ok || arr.Len() != 2 {
+			return nil, newGeoBadValueError(op, "$centerSphere requires [[x, y], radius] argument")
+		}
+
+		center, err := parseLegacyPoint(op, must.NotFail(arr.Get(0)))
+		if err != nil {
+			return nil, err
+		}
+
+		radius, ok := geoNumber(must.NotFail(arr.Get(1)))
+		if !ok || radius < 0 {
+			return nil, newGeoBadValueError(op, "radius must be a non-negative number")
+		}
+
+		distance := radius * earthRadius
+
+		return &backends.GeoQuery{
+			Field:       field,
+			Operator:    backends.GeoWithin,
+			Geometry:    center,
+			MaxDistance: &distance,
+		}, nil
+
+	case "$box", "$polygon", "$center":
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrNotImplemented,
+			fmt.Sprintf("%s shape specifier for 2d indexes is not implemented yet", shape),
+			op,
+		)
+
+	default:
+		return nil, newGeoBadValueError(op, fmt.Sprintf("unknown geo specifier: %s", shape))
+	}
+}
+
+// parseGeoNear parses $near or $nearSphere query operator of the field's expression.
+//
+// GeoJSON points use distances in meters;
+// legacy coordinate pairs (only for $nearSphere) use distances in radians.
+func parseGeoNear(field, op string, expr *types.Document) (*backends.GeoQuery, error) {
+	res := &backends.GeoQuery{
+		Field:    field,
+		Operator: backends.GeoNear,
+	}
+
+	// distances may be set next to the operator
+	distances := expr
+
+	var multiplier float64
+
+	switch v := must.NotFail(expr.Get(op)).(type) {
+	case *types.Document:
+		if !v.Has("$geometry") {
+			return nil, newGeoBadValueError(op, fmt.Sprintf("%s requires a $geometry argument or a legacy point", op))
+		}
+
+		geometry, err := parseGeoJSON(op, must.NotFail(v.Get("$geometry")), []string{"Point"})
+		if err != nil {
+			return nil, err
+		}
+
+		res.Geometry = geometry
+		multiplier = 1
+
+		if v.Has("$minDistance") || v.Has("$maxDistance") {
+			distances = v
+		}
+
+	case *types.Array:
+		if op == "$near" {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				"$near with legacy coordinate pairs requires 2d index that is not implemented yet; "+
+					"use GeoJSON point or $nearSphere",
+				op,
+			)
+		}
+
+		geometry, err := parseLegacyPoint(op, v)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Geometry = geometry
+		multiplier = earthRadius
+
+	default:
+		return nil, newGeoBadValueError(op, fmt.Sprintf("%s requires a $geometry argument or a legacy point", op))
+	}
+
+	for _, k := range []string{"$minDistance", "$maxDistance"} {
+		v, _ := distances.Get(k)
+		if v == nil {
+			continue
+		}
+
+		d, ok := geoNumber(v)
+		if !ok || d < 0 {
+			return nil, newGeoBadValueError(op, fmt.Sprintf("%s must be a non-negative number", k))
+		}
+
+		d *= multiplier
+
+		if k == "$minDistance" {
+			res.MinDistance = &d
+		} else {
+			res.MaxDistance = &d
+		}
+	}
+
+	return res, nil
+}
+
+// parseLegacyPoint parses legacy coordinate pair and returns it as GeoJSON point.
+func parseLegacyPoint(op string, v any) (string, error) {
+	arr, ok := v.(*types.Array)
+	if !ok || arr.Len() != 2 {
+		return "", newGeoBadValueError(op, "point must be an array of two numbers")
+	}
+
+	return parseGeoJSON(op, must.NotFail(types.NewDocument("type", "Point", "coordinates", arr)), nil)
+}
+
+// parseGeoJSON validates the given GeoJSON geometry and returns it as JSON string.
+//
+// If allowed is not empty, only those geometry types are allowed.
+func parseGeoJSON(op string, v any, allowed []string) (string, error) {
+	geometry, ok := v.(*types.Document)
+	if !ok {
+		return "", newGeoBadValueError(op, "$geometry must be an object")
+	}
+
+	for _, k := range geometry.Keys() {
+		switch k {
+		case "type", "coordinates":
+			// checked below
+		case "crs":
+			return "", commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				"custom GeoJSON crs is not implemented yet",
+				op,
+			)
+		default:
+			return "", newGeoBadValueError(op, fmt.Sprintf("unknown GeoJSON field: %s", k))
+		}
+	}
+
+	t, err := common.GetRequiredParam[string](geometry, "type")
+	if err != nil {
+		return "", newGeoBadValueError(op, "GeoJSON type must be a string")
+	}
+
+	depth, ok := geoJSONDepths[t]
+	if !ok {
+		if t == "GeometryCollection" {
+			return "", commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				"GeoJSON GeometryCollection is not implemented yet",
+				op,
+			)
+		}
+
+		return "", newGeoBadValueError(op, fmt.Sprintf("unknown GeoJSON type: %s", t))
+	}
+
+	if len(allowed) > 0 && !slices.Contains(allowed, t) {
+		return "", newGeoBadValueError(
+			op,
+			fmt.Sprintf("GeoJSON type %s is not supported by %s; expected %s", t, op, strings.Join(allowed, " or ")),
+		)
+	}
+
+	coordinates, err := common.GetRequiredParam[*types.Array](geometry, "coordinates")
+	if err != nil {
+		return "", newGeoBadValueError(op, "GeoJSON coordinates must be an array")
+	}
+
+	c, err := geoCoordinates(op, coordinates, depth)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := json.Marshal(map[string]any{"type": t, "coordinates": c})
+	if err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	return string(b), nil
+}
+
+// geoCoordinates validates GeoJSON coordinates array of the given nesting depth
+// and converts it to built-in types for JSON encoding.
+//
+// Positions (depth 0) are longitude/latitude pairs.
+func geoCoordinates(op string, arr *types.Array, depth int) (any, error) {
+	if depth == 0 {
+		if arr.Len() < 2 || arr.Len() > 3 {
+			return nil, newGeoBadValueError(op, "GeoJSON position must have two or three coordinates")
+		}
+
+		res := make([]float64, arr.Len())
+
+		for i := 0; i < arr.Len(); i++ {
+			n, ok := geoNumber(must.NotFail(arr.Get(i)))
+			if !ok {
+				return nil, newGeoBadValueError(op, "GeoJSON position must only contain numeric elements")
+			}
+
+			res[i] = n
+		}
+
+		if res[0] < -180 || res[0] > 180 || res[1] < -90 || res[1] > 90 {
+			return nil, newGeoBadValueError(
+				op,
+				fmt.Sprintf("longitude/latitude is out of bounds, lng: %v lat: %v", res[0], res[1]),
+			)
+		}
+
+		return res, nil
+	}
+
+	res := make([]any, arr.Len())
+
+	for i := 0; i < arr.Len(); i++ {
+		nested, ok := must.NotFail(arr.Get(i)).(*types.Array)
+		if !ok {
+			return nil, newGeoBadValueError(op, "GeoJSON coordinates are nested incorrectly")
+		}
+
+		var err error
+		if res[i], err = geoCoordinates(op, nested, depth-1); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// geoNumber returns the given numeric value as float64.
+func geoNumber(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// newGeoBadValueError returns BadValue error for the invalid geospatial query operator.
+func newGeoBadValueError(op, msg string) error {
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrBadValue,
+		fmt.Sprintf("invalid %s query: %s", op, msg),
+		op,
+	)
+}
@@ -0,0 +1,274 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// importOp represents an import operation in progress.
+type importOp struct {
+	id      int32
+	ns      string
+	from    string
+	client  string // empty for Unix domain socket connections
+	started time.Time
+	total   int64 // -1 if unknown
+
+	read atomic.Int64 // bytes
+	docs atomic.Int64
+}
+
+// reader wraps the given reader to count bytes read from it.
+func (op *importOp) reader(r io.Reader) io.Reader {
+	return &countingReader{r: r, n: &op.read}
+}
+
+// document returns a document describing the import operation for currentOp command.
+func (op *importOp) document(now time.Time) *types.Document {
+	doc := must.NotFail(types.NewDocument(
+		"type", "op",
+		"opid", op.id,
+		"active", true,
+		"op", "command",
+		"desc", "import",
+		"ns", op.ns,
+		"command", must.NotFail(types.NewDocument("import", op.from)),
+		"secs_running", int64(now.Sub(op.started).Seconds()),
+		"progress", must.NotFail(types.NewDocument(
+			"done", op.read.Load(),
+			"total", op.total,
+		)),
+		"nInserted", op.docs.Load(),
+	))
+
+	if op.client != "" {
+		doc.Set("client", op.client)
+	}
+
+	return doc
+}
+
+// importOps tracks import operations in progress.
+type importOps struct {
+	rw     sync.RWMutex
+	ops    map[int32]*importOp
+	lastID int32
+}
+
+// newImportOps creates a new import operations tracker.
+func newImportOps() *importOps {
+	return &importOps{
+		ops: map[int32]*importOp{},
+	}
+}
+
+// start registers a new import operation started by the given client.
+func (ops *importOps) start(ns, from, client string, total int64, now time.Time) *importOp {
+	ops.rw.Lock()
+	defer ops.rw.Unlock()
+
+	ops.lastID++
+
+	op := &importOp{
+		id:      ops.lastID,
+		ns:      ns,
+		from:    from,
+		client:  client,
+		started: now,
+		total:   total,
+	}
+	ops.ops[op.id] = op
+
+	return op
+}
+
+// finish unregisters the given import operation.
+func (ops *importOps) finish(op *importOp) {
+	ops.rw.Lock()
+	defer ops.rw.Unlock()
+
+	delete(ops.ops, op.id)
+}
+
+// list returns import operations in progress sorted by ID.
+func (ops *importOps) list() []*importOp {
+	ops.rw.RLock()
+	defer ops.rw.RUnlock()
+
+	ids := maps.Keys(ops.ops)
+	slices.Sort(ids)
+
+	res := make([]*importOp, len(ids))
+	for i, id := range ids {
+		res[i] = ops.ops[id]
+	}
+
+	return res
+}
+
+// countingReader counts bytes read from the wrapped reader.
+type countingReader struct {
+	r io.Reader
+	n *atomic.Int64
+}
+
+// Read implements io.Reader interface.
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n.Add(int64(n))
+
+	return n, err
+}
+
+// importAuth represents credentials for importing from URL.
+type importAuth struct {
+	username string
+	password string
+	token    string
+}
+
+// importRedirectsLimit is the maximum number of HTTP redirects followed when importing from URL.
+const importRedirectsLimit = 10
+
+// isImportURL returns true if the given import source is an HTTP(S) URL.
+func isImportURL(from string) bool {
+	return strings.HasPrefix(from, "http://") || strings.HasPrefix(from, "https://")
+}
+
+// redactImportURL returns the given import URL without user information, query, and fragment
+// that may contain credentials.
+func redactImportURL(from string) string {
+	u, err := url.Parse(from)
+	if err != nil {
+		return "<invalid URL>"
+	}
+
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+
+	return u.String()
+}
+
+// importURLHostAllowed returns true if the host of the given URL is listed in the given allowed hosts.
+func importURLHostAllowed(u *url.URL, hosts []string) bool {
+	return slices.ContainsFunc(hosts, func(host string) bool {
+		return strings.EqualFold(host, u.Hostname())
+	})
+}
+
+// openImportSource opens the given file (relative to the import directory) or HTTP(S) URL for reading.
+//
+// URLs are allowed only for hosts listed in ImportURLHosts, including redirect targets.
+// Returned errors are safe to be sent to the client; details are logged.
+//
+// It returns the size of the data, or -1 if it is unknown.
+func (h *Handler) openImportSource(ctx context.Context, from string, auth *importAuth) (io.ReadCloser, int64, error) {
+	if isImportURL(from) {
+		if len(h.ImportURLHosts) == 0 {
+			return nil, 0, fmt.Errorf("importing from URLs is disabled; set --import-url-hosts flag")
+		}
+
+		u, err := url.Parse(from)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid URL")
+		}
+
+		if !importURLHostAllowed(u, h.ImportURLHosts) {
+			return nil, 0, fmt.Errorf("host %q is not allowed; add it to --import-url-hosts flag", u.Hostname())
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid URL")
+		}
+
+		if auth != nil {
+			if auth.token != "" {
+				req.Header.Set("Authorization", "Bearer "+auth.token)
+			} else {
+				req.SetBasicAuth(auth.username, auth.password)
+			}
+		}
+
+		client := &http.Client{
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= importRedirectsLimit {
+					return fmt.Errorf("stopped after %d redirects", importRedirectsLimit)
+				}
+
+				if !importURLHostAllowed(req.URL, h.ImportURLHosts) {
+					return fmt.Errorf("redirect to host %q is not allowed", req.URL.Hostname())
+				}
+
+				return nil
+			},
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			h.L.Warn("Failed to fetch import data", zap.String("from", redactImportURL(from)), zap.Error(err))
+			return nil, 0, fmt.Errorf("failed to fetch data")
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			_ = resp.Body.Close()
+			return nil, 0, fmt.Errorf("failed to fetch data: unexpected HTTP status code %d", resp.StatusCode)
+		}
+
+		return resp.Body, resp.ContentLength, nil
+	}
+
+	if h.ImportDir == "" {
+		return nil, 0, fmt.Errorf("importing from files is disabled; set --import-dir flag")
+	}
+
+	if !filepath.IsLocal(from) {
+		return nil, 0, fmt.Errorf("file path %q must be relative to the import directory", from)
+	}
+
+	f, err := os.Open(filepath.Join(h.ImportDir, from))
+	if err != nil {
+		h.L.Warn("Failed to open import file", zap.String("from", from), zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to open file %q", from)
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, 0, lazyerrors.Error(err)
+	}
+
+	return f, fi.Size(), nil
+}
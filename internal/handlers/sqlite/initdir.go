@@ -0,0 +1,190 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/handlers/dataimport"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// File name suffixes of init directory files.
+const (
+	initIndexesSuffix   = ".indexes.json"
+	initDocumentsSuffix = ".json"
+)
+
+// initialize executes seed files and index definitions from the init directory
+// if there are no databases yet (on the first start).
+//
+// Files are processed in the lexical order of their names:
+//   - `<db>.<collection>.indexes.json` files contain index specifications
+//     in the createIndexes command format, one per line;
+//   - other `<db>.<collection>.json` files contain documents in Extended JSON, one per line,
+//     like files for the import command.
+//
+// Other files are ignored.
+// Documents are inserted and indexes are created with regular commands,
+// so collection settings such as field defaults are applied.
+func (h *Handler) initialize(ctx context.Context) error {
+	res, err := h.b.ListDatabases(ctx, nil)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if len(res.Databases) > 0 {
+		h.L.Info("Databases already exist, skipping init directory", zap.String("dir", h.InitDir))
+		return nil
+	}
+
+	entries, err := os.ReadDir(h.InitDir)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(entries))
+
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+
+		if !strings.HasSuffix(e.Name(), initDocumentsSuffix) {
+			h.L.Warn("Ignoring file in init directory", zap.String("file", e.Name()))
+			continue
+		}
+
+		names = append(names, e.Name())
+	}
+
+	slices.Sort(names)
+
+	ctx = conninfo.Ctx(ctx, conninfo.New())
+
+	for _, name := range names {
+		if err = h.initializeFile(ctx, name); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// initializeFile executes a single file from the init directory.
+func (h *Handler) initializeFile(ctx context.Context, name string) error {
+	indexes := strings.HasSuffix(name, initIndexesSuffix)
+
+	ns := strings.TrimSuffix(name, initDocumentsSuffix)
+	if indexes {
+		ns = strings.TrimSuffix(name, initIndexesSuffix)
+	}
+
+	dbName, cName, ok := strings.Cut(ns, ".")
+	if !ok || dbName == "" || cName == "" {
+		return errors.New("file name should be <db>.<collection>.json or <db>.<collection>.indexes.json")
+	}
+
+	f, err := os.Open(filepath.Join(h.InitDir, name))
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck // we are only reading
+
+	r := must.NotFail(dataimport.NewReader(f, dataimport.FormatJSON))
+
+	var docs []*types.Document
+
+	for {
+		var doc *types.Document
+
+		if doc, err = r.Read(); errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		docs = append(docs, doc)
+	}
+
+	if indexes {
+		return h.initializeCommand(ctx, h.MsgCreateIndexes, "createIndexes", cName, dbName, "indexes", docs)
+	}
+
+	for i := 0; i < len(docs); i += importDefaultBatchSize {
+		batch := docs[i:min(i+importDefaultBatchSize, len(docs))]
+
+		if err = h.initializeCommand(ctx, h.MsgInsert, "insert", cName, dbName, "documents", batch); err != nil {
+			return err
+		}
+	}
+
+	h.L.Info("Initialized collection", zap.String("file", name), zap.Int("documents", len(docs)))
+
+	return nil
+}
+
+// initializeCommand runs the given command for the init directory
+// with the given documents as the value of the field f.
+//
+// It returns an error if the command failed or returned write errors.
+func (h *Handler) initializeCommand(ctx context.Context, handle func(context.Context, *wire.OpMsg) (*wire.OpMsg, error), command, cName, dbName, f string, docs []*types.Document) error { //nolint:lll // for readability
+	arr := types.MakeArray(len(docs))
+	for _, doc := range docs {
+		arr.Append(doc)
+	}
+
+	var msg wire.OpMsg
+	must.NoError(msg.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			command, cName,
+			f, arr,
+			"$db", dbName,
+		))},
+	}))
+
+	reply, err := handle(ctx, &msg)
+	if err != nil {
+		return err
+	}
+
+	res, err := reply.Document()
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	v, _ := res.Get("writeErrors")
+	if writeErrors, _ := v.(*types.Array); writeErrors != nil && writeErrors.Len() > 0 {
+		we := must.NotFail(writeErrors.Get(0)).(*types.Document)
+		return fmt.Errorf("%v", must.NotFail(we.Get("errmsg")))
+	}
+
+	return nil
+}
@@ -0,0 +1,394 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/stages"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// materializedView represents a collection that stores results of the aggregation pipeline
+// on another collection of the same database.
+//
+// Results are replaced when the view is refreshed: on demand, and every refresh interval if it is set.
+type materializedView struct {
+	viewOn         string
+	pipeline       []*types.Document
+	refreshSeconds int64 // 0 for on-demand refresh only
+}
+
+// marshal returns the value of the materialized collection setting.
+func (v *materializedView) marshal() *types.Document {
+	pipeline := types.MakeArray(len(v.pipeline))
+	for _, d := range v.pipeline {
+		pipeline.Append(d)
+	}
+
+	return must.NotFail(types.NewDocument(
+		"viewOn", v.viewOn,
+		"pipeline", pipeline,
+		"refreshSeconds", v.refreshSeconds,
+	))
+}
+
+// unmarshalMaterializedView returns materialized view definition
+// for the given value of the materialized collection setting.
+func unmarshalMaterializedView(v any) (*materializedView, error) {
+	doc, ok := v.(*types.Document)
+	if !ok {
+		return nil, lazyerrors.Errorf("invalid %q setting: %T", settingMaterialized, v)
+	}
+
+	var res materializedView
+
+	v, _ = doc.Get("viewOn")
+	res.viewOn, _ = v.(string)
+
+	v, _ = doc.Get("refreshSeconds")
+	res.refreshSeconds, _ = v.(int64)
+
+	v, _ = doc.Get("pipeline")
+	pipeline, _ := v.(*types.Array)
+
+	if res.viewOn == "" || pipeline == nil {
+		return nil, lazyerrors.Errorf("invalid %q setting: %v", settingMaterialized, doc)
+	}
+
+	res.pipeline = make([]*types.Document, pipeline.Len())
+
+	for i := 0; i < pipeline.Len(); i++ {
+		if res.pipeline[i], ok = must.NotFail(pipeline.Get(i)).(*types.Document); !ok {
+			return nil, lazyerrors.Errorf("invalid %q setting: %v", settingMaterialized, doc)
+		}
+	}
+
+	return &res, nil
+}
+
+// getMaterializedView returns materialized view definition for the create command
+// with the given `materialized` field value.
+//
+// The value is either true for views that are refreshed only on demand,
+// or a document with `refreshSeconds` field for views that are also refreshed periodically.
+// `viewOn` and `pipeline` fields of the command are required.
+func getMaterializedView(document *types.Document, cName string, v any) (*materializedView, error) {
+	command := document.Command()
+
+	var refreshSeconds int64
+
+	switch v := v.(type) {
+	case bool:
+		if !v {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				"'materialized' must be true or a document",
+				command,
+			)
+		}
+
+	case *types.Document:
+		for _, k := range v.Keys() {
+			if k != "refreshSeconds" {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrBadValue,
+					fmt.Sprintf("unknown materialized option %q", k),
+					command,
+				)
+			}
+		}
+
+		if secondsV, _ := v.Get("refreshSeconds"); secondsV != nil {
+			var err error
+			if refreshSeconds, err = commonparams.GetWholeNumberParam(secondsV); err != nil || refreshSeconds <= 0 {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrBadValue,
+					"materialized option 'refreshSeconds' must be a positive integer",
+					command,
+				)
+			}
+		}
+
+	default:
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			fmt.Sprintf("'materialized' must be true or a document, not %s", commonparams.AliasFromType(v)),
+			command,
+		)
+	}
+
+	viewOn, err := common.GetRequiredParam[string](document, "viewOn")
+	if err != nil {
+		return nil, err
+	}
+
+	if viewOn == cName {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"materialized view must differ from the source collection",
+			command,
+		)
+	}
+
+	pipeline, err := common.GetRequiredParam[*types.Array](document, "pipeline")
+	if err != nil {
+		return nil, err
+	}
+
+	stagesDocs := make([]*types.Document, pipeline.Len())
+
+	for i := 0; i < pipeline.Len(); i++ {
+		d, ok := must.NotFail(pipeline.Get(i)).(*types.Document)
+		if !ok {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrTypeMismatch,
+				"Each element of the 'pipeline' array must be an object",
+				command,
+			)
+		}
+
+		stagesDocs[i] = d
+	}
+
+	// validate pipeline early
+	if _, err = newMaterializedStages(command, stagesDocs); err != nil {
+		return nil, err
+	}
+
+	return &materializedView{
+		viewOn:         viewOn,
+		pipeline:       stagesDocs,
+		refreshSeconds: refreshSeconds,
+	}, nil
+}
+
+// newMaterializedStages returns aggregation stages for the given pipeline of the materialized view.
+//
+// Only stages that process documents are allowed.
+func newMaterializedStages(command string, pipeline []*types.Document) ([]aggregations.Stage, error) {
+	res := make([]aggregations.Stage, len(pipeline))
+
+	for i, d := range pipeline {
+		switch d.Command() {
+		case "$collStats", "$merge", "$out", "$sql":
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf("%s stage is not supported in materialized views", d.Command()),
+				command,
+			)
+		}
+
+		s, err := stages.NewStage(d)
+		if err != nil {
+			return nil, err
+		}
+
+		res[i] = s
+	}
+
+	return res, nil
+}
+
+// createMaterializedView stores the definition of the given materialized view and refreshes it.
+func (h *Handler) createMaterializedView(ctx context.Context, db backends.Database, dbName, cName string, view *materializedView) error {
+	if err := h.setCollectionSetting(ctx, db, cName, settingMaterialized, view.marshal()); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if _, err := h.refreshMaterializedView(ctx, dbName, cName, view); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// refreshMaterializedViews refreshes materialized views with the refresh interval that are due.
+func (h *Handler) refreshMaterializedViews(ctx context.Context) error {
+	settings, err := h.listCollectionSetting(ctx, "", settingMaterialized)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	var errs []error
+
+	for _, s := range settings {
+		key := collectionKey(s.dbName, s.cName)
+
+		view, err := unmarshalMaterializedView(s.v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+
+		if view.refreshSeconds == 0 {
+			continue
+		}
+
+		h.viewsM.Lock()
+		last := h.viewsRefreshed[key]
+		h.viewsM.Unlock()
+
+		if h.now().Sub(last) < time.Duration(view.refreshSeconds)*time.Second {
+			continue
+		}
+
+		n, err := h.refreshMaterializedView(ctx, s.dbName, s.cName, view)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+
+		h.L.Debug("Materialized view refreshed", zap.String("ns", key), zap.Int("count", n))
+	}
+
+	return errors.Join(errs...)
+}
+
+// refreshMaterializedView replaces documents of the given materialized view with the results of its pipeline.
+// It returns the number of stored documents.
+//
+// Refreshes are serialized. Results are computed before existing documents are removed,
+// but readers may observe the view empty or partially filled during the refresh.
+func (h *Handler) refreshMaterializedView(ctx context.Context, dbName, cName string, view *materializedView) (int, error) {
+	h.viewsM.Lock()
+	defer h.viewsM.Unlock()
+
+	docs, err := h.materializedViewResults(ctx, dbName, cName, view)
+	if err != nil {
+		return 0, err
+	}
+
+	db, err := h.b.Database(dbName)
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	c, err := db.Collection(cName)
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	if err = replaceDocuments(ctx, c, docs); err != nil {
+		return 0, err
+	}
+
+	if h.viewsRefreshed == nil {
+		h.viewsRefreshed = map[string]time.Time{}
+	}
+
+	h.viewsRefreshed[collectionKey(dbName, cName)] = h.now()
+
+	return len(docs), nil
+}
+
+// materializedViewResults runs the pipeline of the given materialized view
+// and returns documents that could be stored in it.
+func (h *Handler) materializedViewResults(ctx context.Context, dbName, cName string, view *materializedView) ([]*types.Document, error) { //nolint:lll // for readability
+	stagesDocs := make([]any, len(view.pipeline))
+	for i, d := range view.pipeline {
+		stagesDocs[i] = d
+	}
+
+	aggregationStages, err := newMaterializedStages("refreshMaterializedView", view.pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := h.b.Database(dbName)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	stages.SetCollectionQuery(aggregationStages, h.collectionQuery(db, dbName))
+
+	// refresh is not limited by client's options, so blocking stages are always allowed to use disk
+	stages.SetMemoryLimit(aggregationStages, h.aggregationMemoryLimit(), true)
+
+	source, err := db.Collection(view.viewOn)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	qp := new(backends.QueryParams)
+	if !h.DisableFilterPushdown {
+		qp.Filter, _, _ = aggregations.GetPushdownQuery(stagesDocs)
+	}
+
+	closer := iterator.NewMultiCloser()
+	defer closer.Close()
+
+	iter, err := processStagesDocuments(ctx, closer, &stagesDocumentsParams{
+		c:      source,
+		qp:     qp,
+		db:     db,
+		stages: aggregationStages,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	closer.Add(iter)
+
+	docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](iter))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	settings, err := collectionSettings(ctx, db, cName)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	newID := h.newIDFunc(settings)
+
+	for _, doc := range docs {
+		if !doc.Has("_id") {
+			doc.Set("_id", newID())
+		}
+
+		if err = doc.ValidateData(); err != nil {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf("invalid materialized view document: %s", err),
+				"refreshMaterializedView",
+			)
+		}
+	}
+
+	// check duplicates before existing documents are removed
+	if id, ok := duplicateID(docs); ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrDuplicateKeyInsert,
+			fmt.Sprintf("materialized view results contain duplicate _id %s", types.FormatAnyValue(id)),
+			"refreshMaterializedView",
+		)
+	}
+
+	return docs, nil
+}
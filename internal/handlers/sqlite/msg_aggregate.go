@@ -48,15 +48,37 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 
 	common.Ignored(document, h.L, "lsid")
 
-	if err = common.Unimplemented(document, "explain", "collation", "let"); err != nil {
+	if err = common.Unimplemented(document, "explain", "collation"); err != nil {
 		return nil, err
 	}
 
+	// variables can't be referenced yet, so `let` is only validated
+	if v, _ := document.Get("let"); v != nil {
+		if _, ok := v.(*types.Document); !ok {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrTypeMismatch,
+				fmt.Sprintf(
+					"BSON field 'aggregate.let' is the wrong type '%s', expected type 'object'",
+					commonparams.AliasFromType(v),
+				),
+				document.Command(),
+			)
+		}
+	}
+
 	common.Ignored(
 		document, h.L,
-		"allowDiskUse", "bypassDocumentValidation", "readConcern", "hint", "comment", "writeConcern",
+		"bypassDocumentValidation", "readConcern", "hint", "comment", "writeConcern",
 	)
 
+	var allowDiskUse bool
+
+	if v, _ := document.Get("allowDiskUse"); v != nil {
+		if allowDiskUse, err = commonparams.GetBoolOptionalParam("aggregate.allowDiskUse", v); err != nil {
+			return nil, err
+		}
+	}
+
 	var dbName string
 
 	if dbName, err = common.GetRequiredParam[string](document, "$db"); err != nil {
@@ -91,7 +113,7 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 		return nil, lazyerrors.Error(err)
 	}
 
-	c, err := db.Collection(cName)
+	c, err := h.collection(ctx, db, dbName, cName)
 	if err != nil {
 		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
 			msg := fmt.Sprintf("Invalid collection name: %s", cName)
@@ -181,6 +203,11 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 	stagesDocuments := make([]aggregations.Stage, 0, len(aggregationStages))
 	collStatsDocuments := make([]aggregations.Stage, 0, len(aggregationStages))
 
+	var sqlQuery string
+
+	var documentHistory bool
+	var documentHistoryID any
+
 	for i, v := range aggregationStages {
 		var d *types.Document
 
@@ -208,6 +235,60 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 				)
 			}
 
+			collStatsDocuments = append(collStatsDocuments, s)
+		case "$sql":
+			if !h.SQLStage {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrUnauthorized,
+					"$sql stage is disabled; set --sql-stage flag",
+					document.Command(),
+				)
+			}
+
+			if !h.isAdmin(ctx) {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrUnauthorized,
+					"$sql stage requires a user listed in --admin-users flag",
+					document.Command(),
+				)
+			}
+
+			if i > 0 {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrBadValue,
+					"$sql is only valid as the first stage in a pipeline",
+					document.Command(),
+				)
+			}
+
+			sqlQuery = must.NotFail(stages.SQLQuery(d))
+
+			stagesDocuments = append(stagesDocuments, s)
+			collStatsDocuments = append(collStatsDocuments, s)
+		case "$documentHistory":
+			if i > 0 {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrBadValue,
+					"$documentHistory is only valid as the first stage in a pipeline",
+					document.Command(),
+				)
+			}
+
+			documentHistory = true
+			documentHistoryID = must.NotFail(stages.DocumentHistoryID(d))
+
+			stagesDocuments = append(stagesDocuments, s)
+			collStatsDocuments = append(collStatsDocuments, s)
+		case "$merge", "$out":
+			if i != len(aggregationStages)-1 {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrStageNotLast,
+					fmt.Sprintf("%s can only be the final stage in the pipeline", d.Command()),
+					document.Command(),
+				)
+			}
+
+			stagesDocuments = append(stagesDocuments, s)
 			collStatsDocuments = append(collStatsDocuments, s)
 		default:
 			stagesDocuments = append(stagesDocuments, s)
@@ -247,6 +328,27 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 		return nil, err
 	}
 
+	var history types.DocumentsIterator
+
+	if documentHistory {
+		var hc backends.Collection
+		if hc, err = h.historyCollection(ctx, db, dbName, cName, document.Command()); err != nil {
+			return nil, err
+		}
+
+		filter := must.NotFail(types.NewDocument())
+		if documentHistoryID != nil {
+			filter = historyFilter(documentHistoryID)
+		}
+
+		var records []*types.Document
+		if records, err = historyRecords(ctx, hc, filter); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		history = iterator.Values(iterator.ForSlice(records))
+	}
+
 	cancel := func() {}
 	if maxTimeMS != 0 {
 		// It is not clear if maxTimeMS affects only aggregate, or both aggregate and getMore (as the current code does).
@@ -259,7 +361,16 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 	var iter iterator.Interface[struct{}, *types.Document]
 
 	if len(collStatsDocuments) == len(stagesDocuments) {
-		filter, sort := aggregations.GetPushdownQuery(aggregationStages)
+		// stages are validated above, so errors are reported for the pipeline as it was given
+		if aggregationStages, stagesDocuments, err = optimizePipeline(aggregationStages); err != nil {
+			return nil, err
+		}
+
+		stages.SetCollectionQuery(stagesDocuments, h.collectionQuery(db, dbName))
+		stages.SetCollectionWriter(stagesDocuments, h.collectionWriter(dbName))
+		stages.SetMemoryLimit(stagesDocuments, h.aggregationMemoryLimit(), allowDiskUse)
+
+		filter, sort, limit := aggregations.GetPushdownQuery(aggregationStages)
 
 		// only documents stages or no stages - fetch documents from the DB and apply stages to them
 		qp := new(backends.QueryParams)
@@ -286,13 +397,41 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 			}
 		}
 
-		iter, err = processStagesDocuments(ctx, closer, &stagesDocumentsParams{c, qp, stagesDocuments})
+		// Limit pushdown is not applied if:
+		//  - `filter` is set, it must fetch all documents to filter them in memory;
+		//  - `sort` is set but not pushed down, it must fetch all documents and sort them in memory.
+		if filter.Len() == 0 && (sort.Len() == 0 || qp.Sort != nil) && sqlQuery == "" {
+			qp.Limit = limit
+		}
+
+		var groupCount *aggregations.GroupCountPushdown
+		if !h.DisableFilterPushdown {
+			groupCount = aggregations.GetGroupCountPushdown(aggregationStages)
+
+			// the leading $unwind stage means that there is nothing else to push down
+			qp.Unwind = aggregations.GetUnwindPushdown(aggregationStages)
+		}
+
+		iter, err = processStagesDocuments(ctx, closer, &stagesDocumentsParams{
+			c:          c,
+			qp:         qp,
+			db:         db,
+			sql:        sqlQuery,
+			sqlTime:    sqlStageTimeout(maxTimeMS),
+			history:    history,
+			groupCount: groupCount,
+			stages:     stagesDocuments,
+		})
 	} else {
 		// TODO https://github.com/FerretDB/FerretDB/issues/2423
 		statistics := stages.GetStatistics(collStatsDocuments)
 
+		stages.SetCollectionQuery(collStatsDocuments, h.collectionQuery(db, dbName))
+		stages.SetCollectionWriter(collStatsDocuments, h.collectionWriter(dbName))
+		stages.SetMemoryLimit(collStatsDocuments, h.aggregationMemoryLimit(), allowDiskUse)
+
 		iter, err = processStagesStats(ctx, closer, &stagesStatsParams{
-			c, db, dbName, cName, statistics, collStatsDocuments,
+			c, db, dbName, cName, statistics, collStatsDocuments, h.now(),
 		})
 	}
 
@@ -345,26 +484,138 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 	return &reply, nil
 }
 
+// optimizePipeline returns the optimized pipeline stages documents and stages created from them.
+func optimizePipeline(stagesDocs []any) ([]any, []aggregations.Stage, error) {
+	optimized := aggregations.OptimizePipeline(stagesDocs)
+
+	res := make([]aggregations.Stage, len(optimized))
+
+	for i, d := range optimized {
+		s, err := stages.NewStage(d.(*types.Document))
+		if err != nil {
+			return nil, nil, lazyerrors.Error(err)
+		}
+
+		res[i] = s
+	}
+
+	return optimized, res, nil
+}
+
+// aggregationMemoryLimit returns the maximum size in bytes of documents
+// that blocking aggregation stages may hold in memory.
+func (h *Handler) aggregationMemoryLimit() int64 {
+	if h.AggregationMemoryLimit != 0 {
+		return h.AggregationMemoryLimit
+	}
+
+	return stages.DefaultMemoryLimit
+}
+
+// collectionQuery returns a function that queries all documents of collections in the given database.
+// It is used by stages that read other collections, such as $lookup.
+func (h *Handler) collectionQuery(db backends.Database, dbName string) aggregations.CollectionQuery {
+	return func(ctx context.Context, collection string, filter *types.Document) (types.DocumentsIterator, error) {
+		c, err := h.collection(ctx, db, dbName, collection)
+		if err != nil {
+			if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
+				msg := fmt.Sprintf("Invalid collection name: %s", collection)
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, "aggregate")
+			}
+
+			return nil, lazyerrors.Error(err)
+		}
+
+		res, err := c.Query(ctx, &backends.QueryParams{Filter: filter})
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		return res.Iter, nil
+	}
+}
+
+// sqlStageDefaultTimeout is the maximum execution time of $sql stage query if maxTimeMS is not set.
+const sqlStageDefaultTimeout = time.Minute
+
+// sqlStageTimeout returns the maximum execution time of $sql stage query for the given maxTimeMS.
+func sqlStageTimeout(maxTimeMS int64) time.Duration {
+	if maxTimeMS == 0 {
+		return sqlStageDefaultTimeout
+	}
+
+	return time.Duration(maxTimeMS) * time.Millisecond
+}
+
 // stagesDocumentsParams contains the parameters for processStagesDocuments.
 type stagesDocumentsParams struct {
-	c      backends.Collection
-	qp     *backends.QueryParams
+	c       backends.Collection
+	qp      *backends.QueryParams
+	db      backends.Database
+	sql     string                  // raw SQL query of $sql stage that replaces collection documents, if set
+	sqlTime time.Duration           // maximum execution time of sql
+	history types.DocumentsIterator // history records of $documentHistory stage that replace collection documents, if set
+
+	// leading stages that the backend could replace with counting documents, if set
+	groupCount *aggregations.GroupCountPushdown
+
 	stages []aggregations.Stage
 }
 
 // processStagesDocuments retrieves the documents from the database and then processes them through the stages.
 func processStagesDocuments(ctx context.Context, closer *iterator.MultiCloser, p *stagesDocumentsParams) (types.DocumentsIterator, error) { //nolint:lll // for readability
-	queryRes, err := p.c.Query(ctx, p.qp)
-	if err != nil {
-		closer.Close()
-		return nil, lazyerrors.Error(err)
+	var iter types.DocumentsIterator
+	stages := p.stages
+
+	switch {
+	case p.sql != "":
+		queryRes, err := p.db.QueryRaw(ctx, &backends.QueryRawParams{SQL: p.sql, Timeout: p.sqlTime})
+		if err != nil {
+			closer.Close()
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrOperationFailed,
+				fmt.Sprintf("$sql query failed: %s", err),
+				"$sql (stage)",
+			)
+		}
+
+		iter = queryRes.Iter
+
+	case p.history != nil:
+		iter = p.history
+
+	default:
+		if p.groupCount != nil {
+			var err error
+			if iter, err = countGroups(ctx, p.c, p.groupCount); err != nil {
+				closer.Close()
+				return nil, err
+			}
+
+			if iter != nil {
+				stages = stages[p.groupCount.Stages:]
+				break
+			}
+		}
+
+		queryRes, err := p.c.Query(ctx, p.qp)
+		if err != nil {
+			closer.Close()
+			return nil, lazyerrors.Error(err)
+		}
+
+		iter = queryRes.Iter
+
+		if queryRes.Unwound {
+			stages = stages[1:]
+		}
 	}
 
-	closer.Add(queryRes.Iter)
+	closer.Add(iter)
 
-	iter := queryRes.Iter
+	var err error
 
-	for _, s := range p.stages {
+	for _, s := range stages {
 		if iter, err = s.Process(ctx, iter, closer); err != nil {
 			return nil, err
 		}
@@ -373,6 +624,65 @@ func processStagesDocuments(ctx context.Context, closer *iterator.MultiCloser, p
 	return iter, nil
 }
 
+// countGroups returns documents produced by the leading $match and $group stages described by gc,
+// using the backend to count documents for each group.
+//
+// It returns nil iterator if the backend could not do that.
+func countGroups(ctx context.Context, c backends.Collection, gc *aggregations.GroupCountPushdown) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	res, err := c.Count(ctx, &backends.CountParams{Filter: gc.Match, GroupBy: gc.Field})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if !res.Pushdown {
+		return nil, nil
+	}
+
+	// backends may count equal values of different types separately;
+	// $group treats missing fields as nulls
+	var ids []any
+	var counts []int64
+
+	for _, g := range res.Groups {
+		v := g.Value
+		if v == nil {
+			v = types.Null
+		}
+
+		i := slices.IndexFunc(ids, func(id any) bool {
+			return types.CompareForAggregation(id, v) == types.Equal
+		})
+
+		if i < 0 {
+			ids = append(ids, v)
+			counts = append(counts, 0)
+			i = len(ids) - 1
+		}
+
+		counts[i] += g.Count
+	}
+
+	docs := make([]*types.Document, len(ids))
+
+	for i, id := range ids {
+		// the same types as $sum of int32 values and $count return
+		var count any = counts[i]
+		if counts[i] <= math.MaxInt32 {
+			count = int32(counts[i])
+		}
+
+		doc := must.NotFail(types.NewDocument("_id", id))
+
+		for _, f := range gc.Fields {
+			doc.Set(f, count)
+		}
+
+		docs[i] = doc
+	}
+
+	return iterator.Values(iterator.ForSlice(docs)), nil
+}
+
 // stagesStatsParams contains the parameters for processStagesStats.
 type stagesStatsParams struct {
 	c          backends.Collection
@@ -381,6 +691,7 @@ type stagesStatsParams struct {
 	cName      string
 	statistics map[stages.Statistic]struct{}
 	stages     []aggregations.Stage
+	now        time.Time
 }
 
 // processStagesStats retrieves the statistics from the database and then processes them through the stages.
@@ -403,7 +714,7 @@ func processStagesStats(ctx context.Context, closer *iterator.MultiCloser, p *st
 	doc := must.NotFail(types.NewDocument(
 		"ns", p.dbName+"."+p.cName,
 		"host", host,
-		"localTime", time.Now().UTC().Format(time.RFC3339),
+		"localTime", p.now.UTC().Format(time.RFC3339),
 	))
 
 	var collStats *backends.CollectionStatsResult
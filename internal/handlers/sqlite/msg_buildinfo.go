@@ -23,5 +23,5 @@ import (
 
 // MsgBuildInfo implements HandlerInterface.
 func (h *Handler) MsgBuildInfo(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
-	return commoncommands.MsgBuildInfo(ctx, msg)
+	return commoncommands.MsgBuildInfo(ctx, msg, h.StateProvider.Get())
 }
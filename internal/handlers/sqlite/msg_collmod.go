@@ -16,15 +16,262 @@ package sqlite
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // MsgCollMod implements HandlerInterface.
 func (h *Handler) MsgCollMod(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
-	return nil, commonerrors.NewCommandErrorMsg(
-		commonerrors.ErrNotImplemented,
-		"`collMod` command is not implemented yet",
-	)
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	unimplementedFields := []string{
+		"index",
+		"validator",
+		"validationLevel",
+		"validationAction",
+		"viewOn",
+		"pipeline",
+		"expireAfterSeconds",
+		"timeseries",
+		"changeStreamPreAndPostImages",
+		"cappedSize",
+		"cappedMax",
+	}
+	if err = common.Unimplemented(document, unimplementedFields...); err != nil {
+		return nil, err
+	}
+
+	common.Ignored(document, h.L, "writeConcern", "comment")
+
+	command := document.Command()
+
+	dbName, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	cName, err := common.GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := h.b.Database(dbName)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", dbName, cName)
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, command)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	list, err := db.ListCollections(ctx, nil)
+	if err != nil && !backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseDoesNotExist) {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var found bool
+
+	if list != nil {
+		for _, c := range list.Collections {
+			if c.Name == cName {
+				found = true
+				break
+			}
+		}
+	}
+
+	if !found {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrNamespaceNotFound, "ns does not exist", command)
+	}
+
+	if v, _ := document.Get("archive"); v != nil {
+		var policy *archivePolicy
+		if policy, err = getArchivePolicy(command, cName, v); err != nil {
+			return nil, err
+		}
+
+		if policy != nil && policy.tablespace != "" {
+			feature := "Archive option 'tablespace'"
+			if err = common.CheckCapability(h.StateProvider.Get(), backends.CapabilityTablespaces, feature, command); err != nil {
+				return nil, err
+			}
+		}
+
+		var setting any
+		if policy != nil {
+			setting = policy.marshal()
+		}
+
+		if err = h.setCollectionSetting(ctx, db, cName, settingArchive, setting); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	if v, _ := document.Get("defaultIdType"); v != nil {
+		var idType string
+		if idType, err = getDefaultIDType(command, v); err != nil {
+			return nil, err
+		}
+
+		if err = h.setDefaultIDType(ctx, db, cName, idType); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	if v, _ := document.Get("defaults"); v != nil {
+		var defaults *types.Document
+		if defaults, err = getFieldDefaults(command, v); err != nil {
+			return nil, err
+		}
+
+		var setting any
+		if defaults != nil {
+			setting = defaults
+		}
+
+		if err = h.setCollectionSetting(ctx, db, cName, settingDefaults, setting); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	if v, _ := document.Get("history"); v != nil {
+		var name string
+		if name, err = getHistoryCollection(command, cName, v); err != nil {
+			return nil, err
+		}
+
+		var setting any
+		if name != "" {
+			setting = name
+		}
+
+		if err = h.setCollectionSetting(ctx, db, cName, settingHistory, setting); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
+
+// getArchivePolicy returns archiving policy for the given `archive` field value,
+// or nil if it is "off".
+//
+// The value is a document with the following fields:
+//   - `days` (required) - the minimal age of archived documents;
+//   - `field` - the date field used to determine the age; defaults to `_id` (ObjectID's timestamp is used);
+//   - `filter` - only documents matching it are archived;
+//   - `to` - the archive collection name in the same database; defaults to `<collection>_archive`;
+//   - `tablespace` - the tablespace for the archive collection if it does not exist yet.
+func getArchivePolicy(command, cName string, v any) (*archivePolicy, error) {
+	if v == "off" {
+		return nil, nil
+	}
+
+	doc, ok := v.(*types.Document)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			fmt.Sprintf("'archive' must be a document or \"off\", not %s", commonparams.AliasFromType(v)),
+			command,
+		)
+	}
+
+	for _, k := range doc.Keys() {
+		switch k {
+		case "days", "field", "filter", "to", "tablespace":
+			// nothing
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf("unknown archive option %q", k),
+				command,
+			)
+		}
+	}
+
+	daysV, err := doc.Get("days")
+	if err != nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrMissingField,
+			"archive option 'days' is required",
+			command,
+		)
+	}
+
+	days, err := commonparams.GetWholeNumberParam(daysV)
+	if err != nil || days <= 0 {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"archive option 'days' must be a positive integer",
+			command,
+		)
+	}
+
+	field, err := common.GetOptionalParam(doc, "field", "_id")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = types.NewPathFromString(field); err != nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("invalid archive option 'field': %s", field),
+			command,
+		)
+	}
+
+	filter, err := common.GetOptionalParam(doc, "filter", must.NotFail(types.NewDocument()))
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := common.GetOptionalParam(doc, "to", cName+"_archive")
+	if err != nil {
+		return nil, err
+	}
+
+	tablespace, err := common.GetOptionalParam(doc, "tablespace", "")
+	if err != nil {
+		return nil, err
+	}
+
+	if to == cName {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"archive collection must differ from the source collection",
+			command,
+		)
+	}
+
+	// validate filter early
+	if _, err = common.FilterDocument(must.NotFail(types.NewDocument()), filter); err != nil {
+		return nil, err
+	}
+
+	return &archivePolicy{
+		filter:     filter,
+		field:      field,
+		days:       days,
+		to:         to,
+		tablespace: tablespace,
+	}, nil
 }
@@ -21,6 +21,7 @@ import (
 	"slices"
 
 	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/decorators/collstats"
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
@@ -158,6 +159,21 @@ func (h *Handler) MsgCollStats(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 		)
 	}
 
+	if h.collStats != nil {
+		if cs := h.collStats.Stats(dbName, collection); cs != nil {
+			pairs = append(pairs,
+				"latencyStats", must.NotFail(types.NewDocument(
+					"reads", latencyStatsDocument(&cs.ReadLatency),
+					"writes", latencyStatsDocument(&cs.WriteLatency),
+				)),
+				"documentSizeStats", must.NotFail(types.NewDocument(
+					"reads", sizeStatsDocument(&cs.ReadSizes),
+					"writes", sizeStatsDocument(&cs.WriteSizes),
+				)),
+			)
+		}
+	}
+
 	pairs = append(pairs,
 		"ok", float64(1),
 	)
@@ -169,3 +185,38 @@ func (h *Handler) MsgCollStats(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 
 	return &reply, nil
 }
+
+// latencyStatsDocument returns a document in the same format as MongoDB's latencyStats
+// with latencies in microseconds.
+func latencyStatsDocument(h *collstats.Histogram) *types.Document {
+	histogram := types.MakeArray(len(h.Buckets))
+	for _, b := range h.Buckets {
+		histogram.Append(must.NotFail(types.NewDocument(
+			"micros", int64(b.LowerBound*1e6),
+			"count", int64(b.Count),
+		)))
+	}
+
+	return must.NotFail(types.NewDocument(
+		"latency", int64(h.Sum*1e6),
+		"ops", int64(h.Count),
+		"histogram", histogram,
+	))
+}
+
+// sizeStatsDocument returns a document with sizes of read or written documents in bytes.
+func sizeStatsDocument(h *collstats.Histogram) *types.Document {
+	histogram := types.MakeArray(len(h.Buckets))
+	for _, b := range h.Buckets {
+		histogram.Append(must.NotFail(types.NewDocument(
+			"bytes", int64(b.LowerBound),
+			"count", int64(b.Count),
+		)))
+	}
+
+	return must.NotFail(types.NewDocument(
+		"bytes", int64(h.Sum),
+		"docs", int64(h.Count),
+		"histogram", histogram,
+	))
+}
@@ -0,0 +1,269 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/failpoints"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgConfigureFailPoint implements HandlerInterface.
+func (h *Handler) MsgConfigureFailPoint(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := document.Command()
+
+	// like mongod without enableTestCommands
+	if h.FailPoints == nil {
+		return nil, commonerrors.NewCommandErrorMsg(
+			commonerrors.ErrCommandNotFound,
+			fmt.Sprintf("no such command: '%s'", command),
+		)
+	}
+
+	dbName, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if dbName != "admin" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	name, err := common.GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	if !failpoints.IsKnown(name) {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("unknown fail point: %s", name),
+			command,
+		)
+	}
+
+	modeV, err := document.Get("mode")
+	if err != nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrMissingField,
+			"missing mode",
+			command,
+		)
+	}
+
+	var count int64
+
+	switch modeV {
+	case "off":
+		count = h.FailPoints.Disable(name)
+
+	default:
+		var mode failpoints.Mode
+		if mode, err = getFailPointMode(command, modeV); err != nil {
+			return nil, err
+		}
+
+		var dataDoc *types.Document
+		if dataDoc, err = common.GetOptionalParam(document, "data", new(types.Document)); err != nil {
+			return nil, err
+		}
+
+		var data *failpoints.Data
+		if data, err = getFailPointData(command, dataDoc); err != nil {
+			return nil, err
+		}
+
+		// like MongoDB, do not let a misconfigured fail point break all commands
+		if name == failpoints.FailCommand && len(data.FailCommands) == 0 {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				"'failCommands' must be a non-empty array of command names",
+				command,
+			)
+		}
+
+		count = h.FailPoints.Enable(name, mode, data)
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"count", count,
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
+
+// getFailPointMode returns fail point mode for the given `mode` field value
+// which is either "alwaysOn" or a document with `times` and/or `skip` fields.
+func getFailPointMode(command string, v any) (failpoints.Mode, error) {
+	var mode failpoints.Mode
+
+	switch v := v.(type) {
+	case string:
+		if v == "alwaysOn" {
+			return mode, nil
+		}
+
+	case *types.Document:
+		iter := v.Iterator()
+		defer iter.Close()
+
+		for {
+			k, f, err := iter.Next()
+			if err != nil {
+				if errors.Is(err, iterator.ErrIteratorDone) {
+					return mode, nil
+				}
+
+				return mode, lazyerrors.Error(err)
+			}
+
+			n, err := commonparams.GetWholeNumberParam(f)
+			if err != nil || n < 0 {
+				return mode, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrBadValue,
+					fmt.Sprintf("'%s' must be a non-negative integer", k),
+					command,
+				)
+			}
+
+			switch k {
+			case "times":
+				mode.Times = n
+			case "skip":
+				mode.Skip = n
+			default:
+				return mode, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrNotImplemented,
+					fmt.Sprintf("mode %q is not implemented yet", k),
+					command,
+				)
+			}
+		}
+	}
+
+	return mode, commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrBadValue,
+		fmt.Sprintf("invalid mode: %s", types.FormatAnyValue(v)),
+		command,
+	)
+}
+
+// getFailPointData returns fail point data for the given `data` document.
+func getFailPointData(command string, doc *types.Document) (*failpoints.Data, error) {
+	var data failpoints.Data
+
+	iter := doc.Iterator()
+	defer iter.Close()
+
+	for {
+		k, v, err := iter.Next()
+		if err != nil {
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				return &data, nil
+			}
+
+			return nil, lazyerrors.Error(err)
+		}
+
+		switch k {
+		case "failCommands":
+			var arr *types.Array
+			if arr, err = common.AssertType[*types.Array](v); err != nil {
+				return nil, err
+			}
+
+			data.FailCommands = make([]string, arr.Len())
+
+			for i := 0; i < arr.Len(); i++ {
+				if data.FailCommands[i], err = common.AssertType[string](must.NotFail(arr.Get(i))); err != nil {
+					return nil, err
+				}
+			}
+
+		case "errorCode":
+			var code int64
+			if code, err = commonparams.GetWholeNumberParam(v); err != nil {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrBadValue,
+					"'errorCode' must be an integer",
+					command,
+				)
+			}
+
+			data.ErrorCode = int32(code)
+
+		case "closeConnection":
+			if data.CloseConnection, err = commonparams.GetBoolOptionalParam(k, v); err != nil {
+				return nil, err
+			}
+
+		case "blockConnection":
+			if data.BlockConnection, err = commonparams.GetBoolOptionalParam(k, v); err != nil {
+				return nil, err
+			}
+
+		case "blockTimeMS":
+			var ms int64
+			if ms, err = commonparams.GetWholeNumberParam(v); err != nil || ms < 0 {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrBadValue,
+					"'blockTimeMS' must be a non-negative integer",
+					command,
+				)
+			}
+
+			data.BlockTime = time.Duration(ms) * time.Millisecond
+
+		case "writeConcernError":
+			var wce *types.Document
+			if wce, err = common.AssertType[*types.Document](v); err != nil {
+				return nil, err
+			}
+
+			data.WriteConcernError = wce.DeepCopy()
+
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				fmt.Sprintf("fail point data field %q is not implemented yet", k),
+				command,
+			)
+		}
+	}
+}
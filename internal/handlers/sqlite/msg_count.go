@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
@@ -41,6 +42,13 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 		return nil, err
 	}
 
+	cancel := func() {}
+	if params.MaxTimeMS != 0 {
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(params.MaxTimeMS)*time.Millisecond)
+	}
+
+	defer cancel()
+
 	db, err := h.b.Database(params.DB)
 	if err != nil {
 		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
@@ -51,7 +59,7 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 		return nil, lazyerrors.Error(err)
 	}
 
-	c, err := db.Collection(params.Collection)
+	c, err := h.collection(ctx, db, params.DB, params.Collection)
 	if err != nil {
 		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
 			msg := fmt.Sprintf("Invalid collection name: %s", params.Collection)
@@ -61,7 +69,37 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 		return nil, lazyerrors.Error(err)
 	}
 
-	var qp backends.QueryParams
+	textSearch, filter, err := prepareTextSearch(ctx, c, params.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	params.Filter = filter
+
+	geo, filter, err := h.prepareGeoQuery(ctx, c, "count", params.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	params.Filter = filter
+
+	if textSearch == nil && geo == nil && !h.DisableFilterPushdown {
+		var countRes *backends.CountResult
+
+		if countRes, err = c.Count(ctx, &backends.CountParams{Filter: params.Filter}); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if countRes.Pushdown {
+			return countReply(countRes.Count, params.Skip, params.Limit), nil
+		}
+	}
+
+	qp := backends.QueryParams{
+		TextSearch: textSearch,
+		Geo:        geo,
+	}
+
 	if !h.DisableFilterPushdown {
 		qp.Filter = params.Filter
 	}
@@ -96,13 +134,24 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 	count, _ := res.Get("count")
 	n, _ := count.(int32)
 
+	return countReply(int64(n), 0, 0), nil
+}
+
+// countReply returns the reply for the count command
+// with the number of matching documents after applying skip and limit.
+func countReply(count, skip, limit int64) *wire.OpMsg {
+	n := max(count-skip, 0)
+	if limit > 0 {
+		n = min(n, limit)
+	}
+
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.OpMsgSection{
 		Documents: []*types.Document{must.NotFail(types.NewDocument(
-			"n", n,
+			"n", int32(n),
 			"ok", float64(1),
 		))},
 	}))
 
-	return &reply, nil
+	return &reply
 }
@@ -41,10 +41,14 @@ func (h *Handler) MsgCreate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		"validator",
 		"validationLevel",
 		"validationAction",
-		"viewOn",
-		"pipeline",
 		"collation",
 	}
+
+	// viewOn and pipeline are supported only for FerretDB-specific materialized views
+	if !document.Has("materialized") {
+		unimplementedFields = append(unimplementedFields, "viewOn", "pipeline")
+	}
+
 	if err = common.Unimplemented(document, unimplementedFields...); err != nil {
 		return nil, err
 	}
@@ -110,6 +114,20 @@ func (h *Handler) MsgCreate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		}
 	}
 
+	idType := idTypeObjectID
+	if v, _ := document.Get("defaultIdType"); v != nil {
+		if idType, err = getDefaultIDType(command, v); err != nil {
+			return nil, err
+		}
+	}
+
+	var view *materializedView
+	if v, _ := document.Get("materialized"); v != nil {
+		if view, err = getMaterializedView(document, collectionName, v); err != nil {
+			return nil, err
+		}
+	}
+
 	db, err := h.b.Database(dbName)
 	if err != nil {
 		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
@@ -124,6 +142,18 @@ func (h *Handler) MsgCreate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 
 	switch {
 	case err == nil:
+		if idType != idTypeObjectID {
+			if err = h.setDefaultIDType(ctx, db, collectionName, idType); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+		}
+
+		if view != nil {
+			if err = h.createMaterializedView(ctx, db, dbName, collectionName, view); err != nil {
+				return nil, err
+			}
+		}
+
 		var reply wire.OpMsg
 		must.NoError(reply.SetSections(wire.OpMsgSection{
 			Documents: []*types.Document{must.NotFail(types.NewDocument(
@@ -119,6 +119,16 @@ func (h *Handler) MsgCreateIndexes(ctx context.Context, msg *wire.OpMsg) (*wire.
 		return nil, err
 	}
 
+	for _, index := range toCreate {
+		if !index.Sphere {
+			continue
+		}
+
+		if err = common.CheckCapability(h.StateProvider.Get(), backends.CapabilityPostGIS, "2dsphere index", command); err != nil {
+			return nil, err
+		}
+	}
+
 	var createCollection bool
 	beforeCreate, err := c.ListIndexes(ctx, new(backends.ListIndexesParams))
 	if err != nil {
@@ -285,7 +295,23 @@ func processIndex(command string, indexDoc *types.Document) (*backends.IndexInfo
 			}
 		}
 
-		index.Key, err = processIndexKey(command, keyDoc)
+		switch {
+		case slices.Contains(keyDoc.Values(), any("text")):
+			index.Key, err = processTextIndexKey(command, keyDoc)
+			index.Text = true
+
+			if index.DefaultLanguage == "" {
+				index.DefaultLanguage = defaultTextSearchLanguage
+			}
+
+		case slices.Contains(keyDoc.Values(), any("2dsphere")):
+			index.Key, err = processSphereIndexKey(command, keyDoc)
+			index.Sphere = true
+
+		default:
+			index.Key, err = processIndexKey(command, keyDoc)
+		}
+
 		if err != nil {
 			return nil, err
 		}
@@ -354,9 +380,120 @@ func processIndex(command string, indexDoc *types.Document) (*backends.IndexInfo
 		case "background":
 			// ignore deprecated options
 
-		case "sparse", "partialFilterExpression", "expireAfterSeconds", "hidden", "storageEngine",
-			"weights", "default_language", "language_override", "textIndexVersion", "2dsphereIndexVersion",
-			"bits", "min", "max", "bucketSize", "collation", "wildcardProjection":
+		case "v":
+			// ODMs send index version they expect; only the default one is supported
+			v := must.NotFail(indexDoc.Get(opt))
+
+			if version, err := commonparams.GetWholeNumberParam(v); err != nil || version != 2 {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrNotImplemented,
+					fmt.Sprintf("Index version %s is not implemented yet", types.FormatAnyValue(v)),
+					command,
+				)
+			}
+
+		case "sparse", "hidden":
+			// false is the default value, and ODMs send it explicitly
+			if v, ok := must.NotFail(indexDoc.Get(opt)).(bool); !ok || v {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrNotImplemented,
+					fmt.Sprintf("Index option %q is not implemented yet", opt),
+					command,
+				)
+			}
+
+		case "collation":
+			// the simple binary collation is the default one,
+			// and MongoDB does not return it in index specifications
+			collation, ok := must.NotFail(indexDoc.Get(opt)).(*types.Document)
+			if !ok || collation.Len() != 1 || !collation.Has("locale") || must.NotFail(collation.Get("locale")) != "simple" {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrNotImplemented,
+					fmt.Sprintf("Index option %q is not implemented yet", opt),
+					command,
+				)
+			}
+
+		case "partialFilterExpression":
+			v := must.NotFail(indexDoc.Get(opt))
+
+			filter, ok := v.(*types.Document)
+			if !ok {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrTypeMismatch,
+					fmt.Sprintf(
+						"The field 'partialFilterExpression' must be an object, but got %s",
+						commonparams.AliasFromType(v),
+					),
+					command,
+				)
+			}
+
+			if err = validatePartialFilterExpression(command, filter); err != nil {
+				return nil, err
+			}
+
+			index.PartialFilterExpression = filter
+
+		case "default_language", "textIndexVersion":
+			v := must.NotFail(indexDoc.Get(opt))
+
+			if !index.Text {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrInvalidIndexSpecificationOption,
+					fmt.Sprintf("The field '%s' is valid only for text indexes", opt),
+					command,
+				)
+			}
+
+			if opt == "textIndexVersion" {
+				if version, err := commonparams.GetWholeNumberParam(v); err != nil || version != int64(textIndexVersion) {
+					return nil, commonerrors.NewCommandErrorMsgWithArgument(
+						commonerrors.ErrNotImplemented,
+						fmt.Sprintf("Text index version %s is not implemented yet", types.FormatAnyValue(v)),
+						command,
+					)
+				}
+
+				break
+			}
+
+			language, ok := v.(string)
+			if !ok || !slices.Contains(textSearchLanguages, language) {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrCannotCreateIndex,
+					fmt.Sprintf(
+						"Error in specification %s :: caused by :: unsupported language: %s for text index version %d",
+						types.FormatAnyValue(indexDoc), types.FormatAnyValue(v), textIndexVersion,
+					),
+					command,
+				)
+			}
+
+			index.DefaultLanguage = language
+
+		case "2dsphereIndexVersion":
+			v := must.NotFail(indexDoc.Get(opt))
+
+			if !index.Sphere {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrInvalidIndexSpecificationOption,
+					fmt.Sprintf("The field '%s' is valid only for 2dsphere indexes", opt),
+					command,
+				)
+			}
+
+			if version, err := commonparams.GetWholeNumberParam(v); err != nil || version != int64(sphereIndexVersion) {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrNotImplemented,
+					fmt.Sprintf("2dsphere index version %s is not implemented yet", types.FormatAnyValue(v)),
+					command,
+				)
+			}
+
+		case "expireAfterSeconds", "storageEngine",
+			"weights", "language_override",
+			"bits", "min", "max", "bucketSize", "wildcardProjection":
 			return nil, commonerrors.NewCommandErrorMsgWithArgument(
 				commonerrors.ErrNotImplemented,
 				fmt.Sprintf("Index option %q is not implemented yet", opt),
@@ -439,6 +576,174 @@ func processIndexKey(command string, keyDoc *types.Document) ([]backends.IndexKe
 	}
 }
 
+// validatePartialFilterExpression checks that the given partialFilterExpression
+// consists only of expressions supported by backends for partial indexes:
+// top-level field equality with scalar values, $eq, $exists: true,
+// numeric $gt, $gte, $lt, $lte, and $and of those.
+func validatePartialFilterExpression(command string, filter *types.Document) error {
+	iter := filter.Iterator()
+	defer iter.Close()
+
+	for {
+		k, v, err := iter.Next()
+
+		switch {
+		case err == nil:
+			// do nothing
+		case errors.Is(err, iterator.ErrIteratorDone):
+			return nil
+		default:
+			return lazyerrors.Error(err)
+		}
+
+		if k == "$and" {
+			arr, ok := v.(*types.Array)
+			if !ok || arr.Len() == 0 {
+				return commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrBadValue,
+					"$and must be a nonempty array",
+					command,
+				)
+			}
+
+			for i := 0; i < arr.Len(); i++ {
+				doc, ok := must.NotFail(arr.Get(i)).(*types.Document)
+				if !ok {
+					return commonerrors.NewCommandErrorMsgWithArgument(
+						commonerrors.ErrBadValue,
+						"$and/$or/$nor entries need to be full objects",
+						command,
+					)
+				}
+
+				if err = validatePartialFilterExpression(command, doc); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		if strings.HasPrefix(k, "$") || strings.Contains(k, ".") {
+			return newPartialFilterNotImplementedError(command, k, v)
+		}
+
+		exprs, ok := v.(*types.Document)
+		if !ok {
+			if !partialFilterEqSupported(v) {
+				return newPartialFilterNotImplementedError(command, k, v)
+			}
+
+			continue
+		}
+
+		for _, op := range exprs.Keys() {
+			opV := must.NotFail(exprs.Get(op))
+
+			var supported bool
+
+			switch op {
+			case "$eq":
+				supported = partialFilterEqSupported(opV)
+			case "$exists":
+				supported = opV == true
+			case "$gt", "$gte", "$lt", "$lte":
+				switch opV.(type) {
+				case int32, int64, float64:
+					supported = true
+				}
+			}
+
+			if !supported {
+				return newPartialFilterNotImplementedError(command, k, v)
+			}
+		}
+	}
+}
+
+// partialFilterEqSupported returns true if equality with the given value
+// can be used in the partial index filter.
+func partialFilterEqSupported(v any) bool {
+	switch v.(type) {
+	case string, types.ObjectID, bool, int32, int64, float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// newPartialFilterNotImplementedError returns an error for the unsupported partialFilterExpression condition.
+func newPartialFilterNotImplementedError(command, k string, v any) error {
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrNotImplemented,
+		fmt.Sprintf(
+			"Expression { %s: %s } in partialFilterExpression is not implemented yet",
+			k, types.FormatAnyValue(v),
+		),
+		command,
+	)
+}
+
+// processTextIndexKey processes the document containing the text index key.
+// All fields should have "text" value.
+func processTextIndexKey(command string, keyDoc *types.Document) ([]backends.IndexKeyPair, error) {
+	res := make([]backends.IndexKeyPair, 0, keyDoc.Len())
+
+	for _, field := range keyDoc.Keys() {
+		if must.NotFail(keyDoc.Get(field)) != "text" {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				"Compound text indexes are not implemented yet",
+				command,
+			)
+		}
+
+		if slices.ContainsFunc(res, func(pair backends.IndexKeyPair) bool { return pair.Field == field }) {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf(
+					"Error in specification %s, the field %q appears multiple times",
+					types.FormatAnyValue(keyDoc), field,
+				),
+				command,
+			)
+		}
+
+		res = append(res, backends.IndexKeyPair{Field: field})
+	}
+
+	return res, nil
+}
+
+// processSphereIndexKey processes the document containing the 2dsphere index key.
+// Only single-field 2dsphere indexes are supported.
+func processSphereIndexKey(command string, keyDoc *types.Document) ([]backends.IndexKeyPair, error) {
+	if keyDoc.Len() != 1 {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrNotImplemented,
+			"Compound 2dsphere indexes are not implemented yet",
+			command,
+		)
+	}
+
+	return []backends.IndexKeyPair{{Field: keyDoc.Keys()[0]}}, nil
+}
+
+// indexKeyString formats the key of the given index to a string.
+//
+// All text indexes have the same key, like in MongoDB, so a collection can't have two of them.
+func indexKeyString(index backends.IndexInfo) string {
+	if index.Text {
+		return `_fts: "text", _ftsx: 1`
+	}
+
+	if index.Sphere {
+		return index.Key[0].Field + `: "2dsphere"`
+	}
+
+	return formatIndexKey(index.Key)
+}
+
 // formatIndexKey formats the given index key to a string.
 func formatIndexKey(key []backends.IndexKeyPair) string {
 	res := make([]string, len(key))
@@ -455,6 +760,16 @@ func formatIndexKey(key []backends.IndexKeyPair) string {
 	return strings.Join(res, ", ")
 }
 
+// samePartialFilterExpression returns true if both indexes have the same partialFilterExpression,
+// or both have none.
+func samePartialFilterExpression(a, b backends.IndexInfo) bool {
+	if a.PartialFilterExpression == nil || b.PartialFilterExpression == nil {
+		return a.PartialFilterExpression == b.PartialFilterExpression
+	}
+
+	return types.FormatAnyValue(a.PartialFilterExpression) == types.FormatAnyValue(b.PartialFilterExpression)
+}
+
 // validateIndexesForCreation validates the given list of indexes to create against the existing ones.
 // It filters out duplicate indexes and returns a slice of indexes to create.
 // It returns an error if at least one provided index has an invalid specification.
@@ -463,7 +778,7 @@ func validateIndexesForCreation(command string, existing, toCreate []backends.In
 	copy(filteredToCreate, toCreate)
 
 	for i, newIdx := range toCreate {
-		newKey := formatIndexKey(newIdx.Key)
+		newKey := indexKeyString(newIdx)
 
 		if newIdx.Name == "" {
 			msg := fmt.Sprintf(
@@ -486,10 +801,12 @@ func validateIndexesForCreation(command string, existing, toCreate []backends.In
 
 		// Iterate backwards to check if the current index is a duplicate of any other index provided in the list earlier.
 		for j := i - 1; j >= 0; j-- {
-			otherKey := formatIndexKey(toCreate[j].Key)
+			otherKey := indexKeyString(toCreate[j])
 			otherName := toCreate[j].Name
 
-			if otherName == newIdx.Name && otherKey == newKey {
+			samePartial := samePartialFilterExpression(newIdx, toCreate[j])
+
+			if otherName == newIdx.Name && otherKey == newKey && samePartial {
 				msg := fmt.Sprintf("Identical index already exists: %s", otherName)
 
 				return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrIndexAlreadyExists, msg, command)
@@ -507,7 +824,7 @@ func validateIndexesForCreation(command string, existing, toCreate []backends.In
 				return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrIndexKeySpecsConflict, msg, command)
 			}
 
-			if newKey == otherKey {
+			if newKey == otherKey && samePartial {
 				msg := fmt.Sprintf(
 					"Index already exists with a different name: %s", otherName,
 				)
@@ -518,7 +835,18 @@ func validateIndexesForCreation(command string, existing, toCreate []backends.In
 
 		// Check for conflicts with existing indexes.
 		for _, existingIdx := range existing {
-			existingKey := formatIndexKey(existingIdx.Key)
+			existingKey := indexKeyString(existingIdx)
+			samePartial := samePartialFilterExpression(newIdx, existingIdx)
+
+			if newIdx.Name == existingIdx.Name && newKey == existingKey && !samePartial {
+				msg := fmt.Sprintf(
+					"An existing index has the same name as the requested index but different options."+
+						" Requested index: { key: { %s }, name: %q }, existing index: { key: { %s }, name: %q }",
+					newKey, newIdx.Name, existingKey, existingIdx.Name,
+				)
+
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrIndexOptionsConflict, msg, command)
+			}
 
 			if newIdx.Name == existingIdx.Name && newKey == existingKey {
 				// Fully identical indexes are ignored, no need to attempt to create them.
@@ -538,7 +866,7 @@ func validateIndexesForCreation(command string, existing, toCreate []backends.In
 				return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrIndexKeySpecsConflict, msg, command)
 			}
 
-			if newKey == existingKey {
+			if newKey == existingKey && samePartial {
 				msg := fmt.Sprintf("Index already exists with a different name: %s", existingIdx.Name)
 				return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrIndexOptionsConflict, msg, command)
 			}
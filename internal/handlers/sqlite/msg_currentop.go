@@ -17,11 +17,81 @@ package sqlite
 import (
 	"context"
 
-	"github.com/FerretDB/FerretDB/internal/handlers/commoncommands"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/jobs"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // MsgCurrentOp implements HandlerInterface.
+//
+// Only background jobs (running ones, or all of them if `$all` is true)
+// and import operations in progress are returned.
 func (h *Handler) MsgCurrentOp(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
-	return commoncommands.MsgCurrentOp(ctx, msg)
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var all bool
+
+	if v, _ := document.Get("$all"); v != nil {
+		if all, err = commonparams.GetBoolOptionalParam("$all", v); err != nil {
+			return nil, err
+		}
+	}
+
+	common.Ignored(document, h.L, "$ownOps", "comment")
+
+	inprog := types.MakeArray(0)
+
+	for _, info := range h.jobs.Jobs() {
+		if !all && !info.Running {
+			continue
+		}
+
+		inprog.Append(jobDocument(&info))
+	}
+
+	now := h.now()
+	for _, op := range h.imports.list() {
+		inprog.Append(op.document(now))
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"inprog", inprog,
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
+
+// jobDocument returns a document describing the given background job
+// for currentOp and jobs commands.
+func jobDocument(info *jobs.Info) *types.Document {
+	doc := must.NotFail(types.NewDocument(
+		"type", "job",
+		"desc", "job:"+info.Name,
+		"active", info.Running,
+		"paused", info.Paused,
+		"intervalSecs", int64(info.Interval.Seconds()),
+		"runs", info.Runs,
+	))
+
+	if !info.LastStart.IsZero() {
+		doc.Set("lastStart", info.LastStart)
+		doc.Set("lastDurationMillis", info.LastDuration.Milliseconds())
+	}
+
+	if info.LastError != "" {
+		doc.Set("lastError", info.LastError)
+	}
+
+	return doc
 }
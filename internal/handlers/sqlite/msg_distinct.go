@@ -17,6 +17,7 @@ package sqlite
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
@@ -40,6 +41,13 @@ func (h *Handler) MsgDistinct(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg
 		return nil, err
 	}
 
+	cancel := func() {}
+	if params.MaxTimeMS != 0 {
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(params.MaxTimeMS)*time.Millisecond)
+	}
+
+	defer cancel()
+
 	db, err := h.b.Database(params.DB)
 	if err != nil {
 		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
@@ -50,7 +58,7 @@ func (h *Handler) MsgDistinct(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg
 		return nil, lazyerrors.Error(err)
 	}
 
-	c, err := db.Collection(params.Collection)
+	c, err := h.collection(ctx, db, params.DB, params.Collection)
 	if err != nil {
 		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
 			msg := fmt.Sprintf("Invalid collection name: %s", params.Collection)
@@ -68,6 +76,11 @@ func (h *Handler) MsgDistinct(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg
 		qp.Filter = params.Filter
 	}
 
+	// backends may return one document per value, which is valid only if no documents are filtered out later
+	if params.Filter.Len() == 0 {
+		qp.Distinct = params.Key
+	}
+
 	// TODO https://github.com/FerretDB/FerretDB/issues/3235
 	queryRes, err := c.Query(ctx, &qp)
 	if err != nil {
@@ -0,0 +1,198 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/decorators/history"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgDocumentHistory implements HandlerInterface.
+func (h *Handler) MsgDocumentHistory(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := document.Command()
+
+	dbName, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	action, err := common.GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	if action != "list" && action != "purge" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("unknown documentHistory action %q, expected 'list' or 'purge'", action),
+			command,
+		)
+	}
+
+	cName, err := common.GetRequiredParam[string](document, "collection")
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := h.b.Database(dbName)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", dbName, cName)
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, command)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	hc, err := h.historyCollection(ctx, db, dbName, cName, command)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := must.NotFail(types.NewDocument())
+	if id, _ := document.Get("documentId"); id != nil {
+		filter = historyFilter(id)
+	}
+
+	records, err := historyRecords(ctx, hc, filter)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res := must.NotFail(types.NewDocument())
+
+	switch action {
+	case "list":
+		versions := types.MakeArray(len(records))
+		for _, r := range records {
+			versions.Append(r)
+		}
+
+		res.Set("versions", versions)
+
+	case "purge":
+		if len(records) > 0 {
+			ids := make([]any, len(records))
+			for i, r := range records {
+				ids[i] = must.NotFail(r.Get("_id"))
+			}
+
+			if _, err = hc.DeleteAll(ctx, &backends.DeleteAllParams{IDs: ids}); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+		}
+
+		res.Set("purged", int32(len(records)))
+	}
+
+	res.Set("ok", float64(1))
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{res},
+	}))
+
+	return &reply, nil
+}
+
+// historyCollection returns the history collection of the given collection.
+// It returns a command error if history is not enabled for it.
+func (h *Handler) historyCollection(ctx context.Context, db backends.Database, dbName, cName, command string) (backends.Collection, error) { //nolint:lll // for readability
+	name, err := historyCollectionName(ctx, db, cName)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if name == "" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrIllegalOperation,
+			fmt.Sprintf("document history is not enabled for collection %s.%s", dbName, cName),
+			command,
+		)
+	}
+
+	c, err := db.Collection(name)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return c, nil
+}
+
+// historyFilter returns a filter that matches history records of the document with the given _id.
+func historyFilter(id any) *types.Document {
+	return must.NotFail(types.NewDocument(
+		history.FieldDocumentID, must.NotFail(types.NewDocument("$eq", id)),
+	))
+}
+
+// historyRecords returns history records matching the given filter, oldest first.
+func historyRecords(ctx context.Context, hc backends.Collection, filter *types.Document) ([]*types.Document, error) {
+	qr, err := hc.Query(ctx, &backends.QueryParams{Filter: filter})
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist) {
+			return nil, nil
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	defer qr.Iter.Close()
+
+	var res []*types.Document
+
+	for {
+		_, doc, err := qr.Iter.Next()
+		if err != nil {
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				break
+			}
+
+			return nil, lazyerrors.Error(err)
+		}
+
+		matches, err := common.FilterDocument(doc, filter)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if matches {
+			res = append(res, doc)
+		}
+	}
+
+	sort := must.NotFail(types.NewDocument(history.FieldTime, int32(1), "_id", int32(1)))
+	if err = common.SortDocuments(res, sort); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
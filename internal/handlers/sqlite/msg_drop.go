@@ -48,6 +48,15 @@ func (h *Handler) MsgDrop(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		return nil, err
 	}
 
+	confirm, err := common.GetOptionalParam(document, "confirm", "")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = h.DropProtection.Check(command, dbName, collectionName, confirm); err != nil {
+		return nil, err
+	}
+
 	// Most backends would block on `DropCollection` below otherwise.
 	//
 	// There is a race condition: another client could create a new cursor for that collection
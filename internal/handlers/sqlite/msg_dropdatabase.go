@@ -39,6 +39,15 @@ func (h *Handler) MsgDropDatabase(ctx context.Context, msg *wire.OpMsg) (*wire.O
 		return nil, err
 	}
 
+	confirm, err := common.GetOptionalParam(document, "confirm", "")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = h.DropProtection.Check(document.Command(), dbName, "", confirm); err != nil {
+		return nil, err
+	}
+
 	// Most backends would block on `DropDatabase` below otherwise.
 	//
 	// There is a race condition: another client could create a new cursor for that database
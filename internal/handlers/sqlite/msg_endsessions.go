@@ -12,22 +12,40 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package commoncommands
+package sqlite
 
 import (
 	"context"
 
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
 	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
-// MsgCurrentOp is a common implementation of currentOp command.
-func MsgCurrentOp(context.Context, *wire.OpMsg) (*wire.OpMsg, error) {
+// MsgEndSessions implements HandlerInterface.
+//
+// Sessions are not tracked, so there is nothing to expire.
+// Drivers send that command when the client is closed.
+func (h *Handler) MsgEndSessions(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if _, err = common.GetRequiredParam[*types.Array](document, document.Command()); err != nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			"BSON field 'endSessions' is the wrong type, expected type 'array'",
+			document.Command(),
+		)
+	}
+
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.OpMsgSection{
 		Documents: []*types.Document{must.NotFail(types.NewDocument(
-			"inprog", must.NotFail(types.NewArray()),
 			"ok", float64(1),
 		))},
 	}))
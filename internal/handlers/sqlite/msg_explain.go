@@ -69,7 +69,7 @@ func (h *Handler) MsgExplain(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, lazyerrors.Error(err)
 	}
 
-	coll, err := db.Collection(params.Collection)
+	coll, err := h.collection(ctx, db, params.DB, params.Collection)
 	if err != nil {
 		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
 			msg := fmt.Sprintf("Invalid collection name: %s", params.Collection)
@@ -80,19 +80,46 @@ func (h *Handler) MsgExplain(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 	}
 
 	qp := backends.ExplainParams{
-		Filter: params.Filter,
+		Filter:  params.Filter,
+		Analyze: params.Analyze(),
 	}
 
+	var optimizedStages *types.Array
+
 	if params.Aggregate {
-		qp.Filter, params.Sort = aggregations.GetPushdownQuery(params.StagesDocs)
+		stagesDocs := aggregations.OptimizePipeline(params.StagesDocs)
+
+		optimizedStages = types.MakeArray(len(stagesDocs))
+		for _, d := range stagesDocs {
+			optimizedStages.Append(d)
+		}
+
+		// pushdown conditions below are applied to the leading stages of the pipeline
+		qp.Filter, params.Sort, params.Limit = aggregations.GetPushdownQuery(stagesDocs)
+		params.Filter = qp.Filter
+	}
+
+	queryPlanner := explainQueryPlanner(params)
+
+	sort := params.Sort
+
+	natural, err := naturalSort(sort)
+	if err != nil {
+		return nil, err
+	}
+
+	if natural != nil {
+		// documents are sorted by the backend
+		qp.Sort = natural
+		sort = nil
 	}
 
 	// Skip sorting if there are more than one sort parameters
-	if h.EnableUnsafeSortPushdown && params.Sort.Len() == 1 {
+	if h.EnableUnsafeSortPushdown && sort.Len() == 1 {
 		var order types.SortType
 
-		k := params.Sort.Keys()[0]
-		v := params.Sort.Values()[0]
+		k := sort.Keys()[0]
+		v := sort.Values()[0]
 
 		order, err = common.GetSortType(k, v)
 		if err != nil {
@@ -105,17 +132,39 @@ func (h *Handler) MsgExplain(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		}
 	}
 
+	hint, err := naturalHint(params.Hint)
+	if err != nil {
+		return nil, err
+	}
+
+	// see applyNaturalHint
+	if hint != nil {
+		qp.Filter = nil
+
+		switch {
+		case qp.Sort != nil || sort.Len() > 0:
+			// explicit sort is applied as usual
+		case hint.Descending:
+			qp.Sort = hint
+		default:
+			qp.SeqScan = true
+		}
+	}
+
 	// Limit pushdown is not applied if:
 	//  - `filter` is set, it must fetch all documents to filter them in memory;
 	//  - `sort` is set but `UnsafeSortPushdown` is not set, it must fetch all documents
 	//  and sort them in memory;
 	//  - `skip` is non-zero value, skip pushdown is not supported yet.
-	if params.Filter.Len() == 0 && (params.Sort.Len() == 0 || h.EnableUnsafeSortPushdown) && params.Skip == 0 {
+	if params.Filter.Len() == 0 && (sort.Len() == 0 || h.EnableUnsafeSortPushdown) && params.Skip == 0 {
 		qp.Limit = params.Limit
 	}
 
 	if h.DisableFilterPushdown {
 		qp.Filter = nil
+	} else {
+		// see MsgCount
+		qp.Count = params.Command.Command() == "count"
 	}
 
 	if !h.EnableUnsafeSortPushdown {
@@ -127,23 +176,74 @@ func (h *Handler) MsgExplain(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, lazyerrors.Error(err)
 	}
 
+	replyDoc := must.NotFail(types.NewDocument(
+		"queryPlanner", queryPlanner,
+		"explainVersion", "1",
+		"command", cmd,
+		"serverInfo", serverInfo,
+
+		// our extensions
+		"backendPlan", res.QueryPlanner,
+		// TODO https://github.com/FerretDB/FerretDB/issues/3235
+		"pushdown", res.QueryPushdown,
+		"regexPushdown", res.RegexPushdown,
+		"sortingPushdown", res.UnsafeSortPushdown,
+		"limitPushdown", res.UnsafeLimitPushdown,
+		"countPushdown", res.CountPushdown,
+	))
+
+	if optimizedStages != nil {
+		// pipeline after reordering and merging of stages
+		replyDoc.Set("stages", optimizedStages)
+	}
+
+	replyDoc.Set("ok", float64(1))
+
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.OpMsgSection{
-		Documents: []*types.Document{must.NotFail(types.NewDocument(
-			"queryPlanner", res.QueryPlanner,
-			"explainVersion", "1",
-			"command", cmd,
-			"serverInfo", serverInfo,
-
-			// our extensions
-			// TODO https://github.com/FerretDB/FerretDB/issues/3235
-			"pushdown", res.QueryPushdown,
-			"sortingPushdown", res.UnsafeSortPushdown,
-			"limitPushdown", res.UnsafeLimitPushdown,
-
-			"ok", float64(1),
-		))},
+		Documents: []*types.Document{replyDoc},
 	}))
 
 	return &reply, nil
 }
+
+// explainQueryPlanner returns MongoDB-shaped queryPlanner section of the explain command reply.
+//
+// The winning plan describes how the query is processed as a whole, including parts done by the handler;
+// backend's plan and pushdown flags show what was done by the backend.
+func explainQueryPlanner(params *common.ExplainParams) *types.Document {
+	filter := params.Filter
+	if filter == nil {
+		filter = types.MakeDocument(0)
+	}
+
+	plan := must.NotFail(types.NewDocument(
+		"stage", "COLLSCAN",
+		"filter", filter,
+		"direction", "forward",
+	))
+
+	if params.Sort.Len() > 0 {
+		plan = must.NotFail(types.NewDocument("stage", "SORT", "sortPattern", params.Sort, "inputStage", plan))
+	}
+
+	if params.Skip > 0 {
+		plan = must.NotFail(types.NewDocument("stage", "SKIP", "skipAmount", params.Skip, "inputStage", plan))
+	}
+
+	if params.Limit > 0 {
+		plan = must.NotFail(types.NewDocument("stage", "LIMIT", "limitAmount", params.Limit, "inputStage", plan))
+	}
+
+	if params.Command.Command() == "count" {
+		plan = must.NotFail(types.NewDocument("stage", "COUNT", "inputStage", plan))
+	}
+
+	return must.NotFail(types.NewDocument(
+		"namespace", params.DB+"."+params.Collection,
+		"indexFilterSet", false,
+		"parsedQuery", filter,
+		"winningPlan", plan,
+		"rejectedPlans", types.MakeArray(0),
+	))
+}
@@ -0,0 +1,274 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/FerretDB/FerretDB/build/version"
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/handlers/dataexport"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/parquet"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+const (
+	// exportDefaultSampleSize is the default number of documents used by the export command to infer the schema.
+	exportDefaultSampleSize = 1000
+
+	// exportDefaultRowGroupSize is the default number of documents in a single Parquet row group.
+	exportDefaultRowGroupSize = 10000
+)
+
+// MsgExport implements HandlerInterface.
+//
+// It writes documents of the collection, optionally filtered and projected,
+// to the Parquet file in the export directory.
+// The file schema is inferred from the first documents; see package dataexport for details.
+func (h *Handler) MsgExport(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	common.Ignored(document, h.L, "comment")
+
+	command := document.Command()
+
+	dbName, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if dbName != "admin" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	cName, err := common.GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	exportDB, err := common.GetRequiredParam[string](document, "db")
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := common.GetRequiredParam[string](document, "to")
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := common.GetOptionalParam(document, "filter", types.MakeDocument(0))
+	if err != nil {
+		return nil, err
+	}
+
+	projection, err := common.GetOptionalParam(document, "projection", types.MakeDocument(0))
+	if err != nil {
+		return nil, err
+	}
+
+	sampleSize := int64(exportDefaultSampleSize)
+	if v, _ := document.Get("sampleSize"); v != nil {
+		if sampleSize, err = commonparams.GetValidatedNumberParamWithMinValue(command, "sampleSize", v, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	rowGroupSize := int64(exportDefaultRowGroupSize)
+	if v, _ := document.Get("rowGroupSize"); v != nil {
+		if rowGroupSize, err = commonparams.GetValidatedNumberParamWithMinValue(command, "rowGroupSize", v, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	if h.ExportDir == "" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrOperationFailed,
+			"exporting is disabled; set --export-dir flag",
+			command,
+		)
+	}
+
+	if !filepath.IsLocal(to) {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("file path %q must be relative to the export directory", to),
+			command,
+		)
+	}
+
+	db, err := h.b.Database(exportDB)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", exportDB, cName)
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, command)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	c, err := db.Collection(cName)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid collection name: %s", cName)
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, command)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	var qp backends.QueryParams
+	if !h.DisableFilterPushdown {
+		qp.Filter = filter
+	}
+
+	queryRes, err := c.Query(ctx, &qp)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	closer := iterator.NewMultiCloser(queryRes.Iter)
+	defer closer.Close()
+
+	iter := common.FilterIterator(queryRes.Iter, closer, filter)
+
+	if iter, err = common.ProjectionIterator(iter, closer, projection, filter); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	sample, err := iterator.ConsumeValuesN(iterator.Interface[struct{}, *types.Document](iter), int(sampleSize))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	columns := dataexport.InferSchema(sample)
+
+	// write to the temporary file first, so the incomplete file is never visible
+	path := filepath.Join(h.ExportDir, to)
+
+	f, err := os.CreateTemp(filepath.Dir(path), ".export-*")
+	if err != nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrOperationFailed, err.Error(), command)
+	}
+
+	defer os.Remove(f.Name()) //nolint:errcheck // it is already renamed on success
+	defer f.Close()           //nolint:errcheck // checked below on success
+
+	w, err := parquet.NewWriter(f, columns, "FerretDB "+version.Get().Version)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var exported int64
+	rows := make([][]any, 0, rowGroupSize)
+
+	flush := func() error {
+		if len(rows) == 0 {
+			return nil
+		}
+
+		if err := w.WriteRowGroup(rows); err != nil {
+			return err
+		}
+
+		exported += int64(len(rows))
+		rows = rows[:0]
+
+		return nil
+	}
+
+	addRow := func(doc *types.Document) error {
+		row, err := dataexport.Row(columns, doc)
+		if err != nil {
+			msg := fmt.Sprintf("export stopped after %d documents: %s; increase sampleSize", exported+int64(len(rows)), err)
+			return commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrBadValue, msg, command)
+		}
+
+		rows = append(rows, row)
+
+		if int64(len(rows)) < rowGroupSize {
+			return nil
+		}
+
+		return flush()
+	}
+
+	for _, doc := range sample {
+		if err = addRow(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		var doc *types.Document
+
+		_, doc, err = iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if err = addRow(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = flush(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if err = w.Close(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if err = f.Close(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if err = os.Rename(f.Name(), path); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"nExported", exported,
+			"file", to,
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
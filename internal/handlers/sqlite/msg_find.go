@@ -44,6 +44,11 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		return nil, err
 	}
 
+	projection, meta, err := common.MetaProjection(params.Projection)
+	if err != nil {
+		return nil, err
+	}
+
 	username, _ := conninfo.Get(ctx).Auth()
 
 	db, err := h.b.Database(params.DB)
@@ -56,7 +61,7 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		return nil, lazyerrors.Error(err)
 	}
 
-	c, err := db.Collection(params.Collection)
+	c, err := h.collection(ctx, db, params.DB, params.Collection)
 	if err != nil {
 		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
 			msg := fmt.Sprintf("Invalid collection name: %s", params.Collection)
@@ -66,8 +71,24 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		return nil, lazyerrors.Error(err)
 	}
 
+	textSearch, filter, err := prepareTextSearch(ctx, c, params.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	params.Filter = filter
+
+	geo, filter, err := h.prepareGeoQuery(ctx, c, "find", params.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	params.Filter = filter
+
 	qp := &backends.QueryParams{
-		Comment: params.Comment,
+		Comment:    params.Comment,
+		TextSearch: textSearch,
+		Geo:        geo,
 	}
 
 	if params.Filter != nil {
@@ -80,12 +101,25 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		qp.Filter = params.Filter
 	}
 
+	sort := params.Sort
+
+	natural, err := naturalSort(sort)
+	if err != nil {
+		return nil, err
+	}
+
+	if natural != nil {
+		// documents are sorted by the backend
+		qp.Sort = natural
+		sort = nil
+	}
+
 	// Skip sorting if there are more than one sort parameters
-	if h.EnableUnsafeSortPushdown && params.Sort.Len() == 1 {
+	if h.EnableUnsafeSortPushdown && sort.Len() == 1 {
 		var order types.SortType
 
-		k := params.Sort.Keys()[0]
-		v := params.Sort.Values()[0]
+		k := sort.Keys()[0]
+		v := sort.Values()[0]
 
 		order, err = common.GetSortType(k, v)
 		if err != nil {
@@ -98,12 +132,25 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		}
 	}
 
+	hint, err := naturalHint(params.Hint)
+	if err != nil {
+		return nil, err
+	}
+
+	applyNaturalHint(qp, hint, sort)
+
+	// Projection pushdown is not applied for text and geospatial queries,
+	// and if projection uses $meta.
+	if textSearch == nil && geo == nil && meta.Len() == 0 {
+		qp.Projection = projectionFields(projection, params.Filter, params.Sort)
+	}
+
 	// Limit pushdown is not applied if:
 	//  - `filter` is set, it must fetch all documents to filter them in memory;
 	//  - `sort` is set but `UnsafeSortPushdown` is not set, it must fetch all documents
 	//  and sort them in memory;
 	//  - `skip` is non-zero value, skip pushdown is not supported yet.
-	if params.Filter.Len() == 0 && (params.Sort.Len() == 0 || h.EnableUnsafeSortPushdown) && params.Skip == 0 {
+	if params.Filter.Len() == 0 && (sort.Len() == 0 || h.EnableUnsafeSortPushdown) && params.Skip == 0 {
 		qp.Limit = params.Limit
 	}
 
@@ -127,7 +174,7 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 
 	iter := common.FilterIterator(queryRes.Iter, closer, params.Filter)
 
-	iter, err = common.SortIterator(iter, closer, params.Sort)
+	iter, err = common.SortIterator(iter, closer, sort)
 	if err != nil {
 		closer.Close()
 
@@ -147,7 +194,13 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 
 	iter = common.LimitIterator(iter, closer, params.Limit)
 
-	iter, err = common.ProjectionIterator(iter, closer, params.Projection, params.Filter)
+	iter, err = common.MetaIterator(iter, closer, meta, params.Sort, queryRes.TextScores)
+	if err != nil {
+		closer.Close()
+		return nil, err
+	}
+
+	iter, err = common.ProjectionIterator(iter, closer, projection, params.Filter)
 	if err != nil {
 		closer.Close()
 		return nil, lazyerrors.Error(err)
@@ -97,6 +97,9 @@ func (h *Handler) MsgFindAndModify(ctx context.Context, msg *wire.OpMsg) (*wire.
 // Upon finding a document, if `remove` flag is set that document is removed,
 // otherwise it updates the document applying operators if any.
 // When no document is found, a document is inserted if `upsert` flag is set.
+//
+// Finding and modifying is done by a single backend call,
+// so the found document could not be changed concurrently before it is modified.
 func (h *Handler) findAndModifyDocument(ctx context.Context, params *common.FindAndModifyParams) (*findAndModifyResult, error) {
 	db, err := h.b.Database(params.DB)
 	if err != nil {
@@ -120,42 +123,100 @@ func (h *Handler) findAndModifyDocument(ctx context.Context, params *common.Find
 		return nil, lazyerrors.Error(err)
 	}
 
+	var projection *types.Document
+	var inclusion bool
+
+	if params.Fields != nil {
+		if projection, inclusion, err = common.ValidateProjection(params.Fields); err != nil {
+			return nil, err
+		}
+	}
+
 	cancel := func() {}
 	if params.MaxTimeMS != 0 {
 		// TODO https://github.com/FerretDB/FerretDB/issues/2168
 		ctx, cancel = context.WithTimeout(ctx, time.Duration(params.MaxTimeMS)*time.Millisecond)
 	}
 
-	// closer accumulates all things that should be closed / canceled.
-	closer := iterator.NewMultiCloser(iterator.CloserFunc(cancel))
-	defer closer.Close()
+	defer cancel()
 
-	var qp backends.QueryParams
+	var fp backends.FindAndModifyParams
 	if !h.DisableFilterPushdown {
-		qp.Filter = params.Query
+		fp.Filter = params.Query
 	}
 
-	queryRes, err := c.Query(ctx, &qp)
+	// settings are read before the backend call that may hold locks
+	var newID func() any
+
+	if params.Upsert {
+		var settings *types.Document
+		if settings, err = collectionSettings(ctx, db, params.Collection); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		newID = h.newIDFunc(settings)
+	}
+
+	var res *findAndModifyResult
+
+	fp.Modify = func(iter types.DocumentsIterator) (*backends.FindAndModifyChange, error) {
+		var change *backends.FindAndModifyChange
+		res, change, err = h.findAndModifyChange(iter, params, newID)
+
+		return change, err
+	}
+
+	fmRes, err := c.FindAndModify(ctx, &fp)
 	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID) {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrDuplicateKeyInsert,
+				fmt.Sprintf("E11000 duplicate key error collection: %s.%s", params.DB, params.Collection),
+				"findAndModify",
+			)
+		}
+
 		return nil, lazyerrors.Error(err)
 	}
 
-	closer.Add(queryRes.Iter)
+	res.modified = fmRes.Updated + fmRes.Deleted + fmRes.Inserted
 
-	iter := common.FilterIterator(queryRes.Iter, closer, params.Query)
+	if doc, ok := res.value.(*types.Document); ok && projection != nil {
+		if res.value, err = common.ProjectDocument(doc, projection, params.Query, inclusion); err != nil {
+			return nil, err
+		}
+	}
 
-	iter, err = common.SortIterator(iter, closer, params.Sort)
+	return res, nil
+}
+
+// findAndModifyChange finds a single document in the given iterator and returns
+// the change to apply to it (or to insert for upsert) and the result to return to the client.
+// Nil change is returned if nothing should be modified.
+//
+// The `modified` field of the result is set later, after the change is applied.
+// newID generates _id values for upserted documents; it is nil if upsert is not requested.
+//
+//nolint:lll // for readability
+func (h *Handler) findAndModifyChange(iter types.DocumentsIterator, params *common.FindAndModifyParams, newID func() any) (*findAndModifyResult, *backends.FindAndModifyChange, error) {
+	// closer accumulates all things that should be closed / canceled.
+	closer := iterator.NewMultiCloser(iter)
+	defer closer.Close()
+
+	iter = common.FilterIterator(iter, closer, params.Query)
+
+	iter, err := common.SortIterator(iter, closer, params.Sort)
 	if err != nil {
 		var pathErr *types.PathError
 		if errors.As(err, &pathErr) && pathErr.Code() == types.ErrPathElementEmpty {
-			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			return nil, nil, commonerrors.NewCommandErrorMsgWithArgument(
 				commonerrors.ErrPathContainsEmptyElement,
 				"FieldPath field names may not be empty strings.",
 				"findAndModify",
 			)
 		}
 
-		return nil, lazyerrors.Error(err)
+		return nil, nil, lazyerrors.Error(err)
 	}
 
 	// findAndModify modifies a single document
@@ -166,33 +227,35 @@ func (h *Handler) findAndModifyDocument(ctx context.Context, params *common.Find
 		// iterator did not find any document, upsert inserts a document, otherwise nothing to do
 		if params.Remove {
 			return &findAndModifyResult{
-				modified: int32(0),
-				value:    types.Null,
-			}, nil
+				value: types.Null,
+			}, nil, nil
 		}
 
 		if !params.Upsert {
 			return &findAndModifyResult{
-				modified:       int32(0),
 				updateExisting: false,
 				value:          types.Null,
-			}, nil
+			}, nil, nil
 		}
 
-		doc := params.Update
+		var doc *types.Document
+
 		if params.HasUpdateOperators {
 			doc = must.NotFail(types.NewDocument())
-			if _, err = common.UpdateDocument("findAndModify", doc, params.Update); err != nil {
+			if _, err = common.UpdateDocument("findAndModify", doc, params.Update, params.ParsedArrayFilters, h.now()); err != nil {
 				// TODO https://github.com/FerretDB/FerretDB/issues/2168
-				return nil, err
+				return nil, nil, err
 			}
+		} else {
+			// do not modify the command document
+			doc = params.Update.DeepCopy()
 		}
 
 		upserted, _ := doc.Get("_id")
 		if upserted == nil {
 			upserted, err = params.Query.Get("_id")
 			if err != nil {
-				upserted = types.NewObjectID()
+				upserted = newID()
 			}
 
 			idDoc, ok := upserted.(*types.Document)
@@ -201,11 +264,11 @@ func (h *Handler) findAndModifyDocument(ctx context.Context, params *common.Find
 
 				if hasOp, err = common.HasQueryOperator(idDoc); err != nil {
 					// TODO https://github.com/FerretDB/FerretDB/issues/2168
-					return nil, err
+					return nil, nil, err
 				}
 
 				if hasOp {
-					upserted = types.NewObjectID()
+					upserted = newID()
 				}
 			}
 
@@ -221,66 +284,46 @@ func (h *Handler) findAndModifyDocument(ctx context.Context, params *common.Find
 			var we *writeError
 
 			if we, err = handleValidationError(err); err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 
 			writeErrors.Append(we.Document())
 		}
 
-		if _, err = c.InsertAll(ctx, &backends.InsertAllParams{
-			Docs: []*types.Document{doc},
-		}); err != nil {
-			if backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID) {
-				// TODO https://github.com/FerretDB/FerretDB/issues/2168
-				we := &writeError{
-					index:  int32(0),
-					code:   commonerrors.ErrDuplicateKeyInsert,
-					errmsg: fmt.Sprintf(`E11000 duplicate key error collection: %s.%s`, params.DB, params.Collection),
-				}
-				writeErrors.Append(we.Document())
-			}
-
-			return nil, lazyerrors.Error(err)
-		}
-
 		var value any = types.Null
 		if params.ReturnNewDocument {
 			value = doc
 		}
 
 		return &findAndModifyResult{
-			modified:       int32(1),
 			updateExisting: false,
 			upserted:       upserted,
 			value:          value,
 			writeErrors:    writeErrors,
-		}, nil
+		}, &backends.FindAndModifyChange{Insert: doc}, nil
 	}
 
 	if err != nil {
-		return nil, lazyerrors.Error(err)
+		return nil, nil, lazyerrors.Error(err)
 	}
 
 	if params.Remove {
-		var delRes *backends.DeleteAllResult
-
-		if delRes, err = c.DeleteAll(ctx, &backends.DeleteAllParams{IDs: []any{must.NotFail(v.Get("_id"))}}); err != nil {
-			return nil, lazyerrors.Error(err)
-		}
-
 		return &findAndModifyResult{
-			modified: delRes.Deleted,
-			value:    v,
-		}, nil
+			value: v,
+		}, &backends.FindAndModifyChange{DeleteID: must.NotFail(v.Get("_id"))}, nil
 	}
 
 	// TODO https://github.com/FerretDB/FerretDB/issues/3040
-	doc := params.Update
+	var doc *types.Document
+
 	if params.HasUpdateOperators {
 		doc = v.DeepCopy()
-		if _, err = common.UpdateDocument("findAndModify", doc, params.Update); err != nil {
-			return nil, err
+		if _, err = common.UpdateDocument("findAndModify", doc, params.Update, params.ParsedArrayFilters, h.now()); err != nil {
+			return nil, nil, err
 		}
+	} else {
+		// do not modify the command document
+		doc = params.Update.DeepCopy()
 	}
 
 	id := must.NotFail(v.Get("_id"))
@@ -291,7 +334,7 @@ func (h *Handler) findAndModifyDocument(ctx context.Context, params *common.Find
 	}
 
 	if updateID != nil && updateID != id {
-		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+		return nil, nil, commonerrors.NewCommandErrorMsgWithArgument(
 			commonerrors.ErrImmutableField,
 			fmt.Sprintf(
 				`Plan executor error during findAndModify :: caused `+
@@ -312,28 +355,22 @@ func (h *Handler) findAndModifyDocument(ctx context.Context, params *common.Find
 		var we *writeError
 
 		if we, err = handleValidationError(err); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		writeErrors.Append(we.Document())
 	}
 
-	updateRes, err := c.UpdateAll(ctx, &backends.UpdateAllParams{Docs: []*types.Document{doc}})
-	if err != nil {
-		return nil, lazyerrors.Error(err)
-	}
-
 	value := v
 	if params.ReturnNewDocument {
 		value = doc
 	}
 
 	return &findAndModifyResult{
-		modified:       updateRes.Updated,
 		updateExisting: true,
 		value:          value,
 		writeErrors:    writeErrors,
-	}, nil
+	}, &backends.FindAndModifyChange{Update: doc}, nil
 }
 
 // handleValidationError checks validation error code and returns *writeError.
@@ -19,7 +19,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
-	"time"
 
 	"go.uber.org/zap"
 
@@ -129,7 +128,7 @@ func (h *Handler) MsgGetLog(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 				"id":   42000,
 				"ctx":  "initandlisten",
 				"t": map[string]string{
-					"$date": time.Now().UTC().Format("2006-01-02T15:04:05.999Z07:00"),
+					"$date": h.now().UTC().Format("2006-01-02T15:04:05.999Z07:00"),
 				},
 			})
 			if err != nil {
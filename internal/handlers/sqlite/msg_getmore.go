@@ -17,11 +17,28 @@ package sqlite
 import (
 	"context"
 
+	"github.com/FerretDB/FerretDB/internal/clientconn/failpoints"
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // MsgGetMore implements handlers.Interface.
 func (h *Handler) MsgGetMore(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
-	return common.GetMore(ctx, msg, h.cursors)
+	return common.GetMore(ctx, msg, h.cursors, func() error {
+		data := h.FailPoints.Check(failpoints.FailGetMoreAfterCursorCheckout, "getMore")
+		if data == nil {
+			return nil
+		}
+
+		errMsg := "Hit the 'failGetMoreAfterCursorCheckout' failpoint"
+
+		// InternalError is used if the code is not set
+		if data.ErrorCode == 0 {
+			return lazyerrors.New(errMsg)
+		}
+
+		return commonerrors.NewCommandErrorMsg(commonerrors.ErrorCode(data.ErrorCode), errMsg)
+	})
 }
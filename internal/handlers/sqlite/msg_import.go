@@ -0,0 +1,256 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/handlers/dataimport"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// importDefaultBatchSize is the default number of documents inserted at once by the import command.
+const importDefaultBatchSize = 10000
+
+// MsgImport implements HandlerInterface.
+//
+// It streams newline-delimited JSON or CSV data from the file in the import directory or HTTP(S) URL
+// into the collection.
+// Importing from URLs requires an administrator and is allowed only for configured hosts.
+// Progress is reported by currentOp command.
+func (h *Handler) MsgImport(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	common.Ignored(document, h.L, "comment")
+
+	command := document.Command()
+
+	dbName, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	cName, err := common.GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := common.GetRequiredParam[string](document, "from")
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := common.GetOptionalParam(document, "format", string(dataimport.FormatJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	switch dataimport.Format(format) {
+	case dataimport.FormatJSON, dataimport.FormatCSV:
+		// nothing
+	default:
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("'format' must be %q or %q, not %q", dataimport.FormatJSON, dataimport.FormatCSV, format),
+			command,
+		)
+	}
+
+	batchSize := int64(importDefaultBatchSize)
+	if v, _ := document.Get("batchSize"); v != nil {
+		if batchSize, err = commonparams.GetValidatedNumberParamWithMinValue(command, "batchSize", v, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	var auth *importAuth
+	if v, _ := document.Get("auth"); v != nil {
+		if auth, err = getImportAuth(command, v); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := h.b.Database(dbName)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", dbName, cName)
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, command)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	c, err := db.Collection(cName)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid collection name: %s", cName)
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, command)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	displayFrom := from
+
+	if isImportURL(from) {
+		if !h.isAdmin(ctx) {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrUnauthorized,
+				"importing from URLs requires a user listed in --admin-users flag",
+				command,
+			)
+		}
+
+		displayFrom = redactImportURL(from)
+	}
+
+	src, total, err := h.openImportSource(ctx, from, auth)
+	if err != nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrOperationFailed, err.Error(), command)
+	}
+	defer src.Close() //nolint:errcheck // we are only reading
+
+	op := h.imports.start(dbName+"."+cName, displayFrom, conninfo.Get(ctx).PeerAddr, total, h.now())
+	defer h.imports.finish(op)
+
+	r, err := dataimport.NewReader(op.reader(src), dataimport.Format(format))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	settings, err := collectionSettings(ctx, db, cName)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	newID := h.newIDFunc(settings)
+
+	var done bool
+	for !done {
+		docs := make([]*types.Document, 0, batchSize)
+
+		for int64(len(docs)) < batchSize {
+			var doc *types.Document
+
+			doc, err = r.Read()
+			if errors.Is(err, io.EOF) {
+				done = true
+				break
+			}
+
+			if err != nil {
+				return nil, importError(op, commonerrors.ErrFailedToParse, err)
+			}
+
+			if !doc.Has("_id") {
+				doc.Set("_id", newID())
+			}
+
+			// TODO https://github.com/FerretDB/FerretDB/issues/3454
+			if err = doc.ValidateData(); err != nil {
+				return nil, importError(op, commonerrors.ErrBadValue, err)
+			}
+
+			docs = append(docs, doc)
+		}
+
+		if len(docs) == 0 {
+			break
+		}
+
+		if _, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: docs}); err != nil {
+			if backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID) {
+				err = fmt.Errorf("E11000 duplicate key error collection: %s.%s", dbName, cName)
+				return nil, importError(op, commonerrors.ErrDuplicateKeyInsert, err)
+			}
+
+			return nil, lazyerrors.Error(err)
+		}
+
+		op.docs.Add(int64(len(docs)))
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"nInserted", op.docs.Load(),
+			"bytesRead", op.read.Load(),
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
+
+// importError returns an error for the failed import operation
+// that includes the number of already inserted documents.
+func importError(op *importOp, code commonerrors.ErrorCode, err error) error {
+	msg := fmt.Sprintf("import stopped after inserting %d documents: %s", op.docs.Load(), err)
+	return commonerrors.NewCommandErrorMsgWithArgument(code, msg, "import")
+}
+
+// getImportAuth returns credentials for the given `auth` field value.
+//
+// The value is a document with either `username` and `password` fields for HTTP basic authentication,
+// or `token` field for bearer token authentication.
+func getImportAuth(command string, v any) (*importAuth, error) {
+	doc, ok := v.(*types.Document)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			fmt.Sprintf("'auth' must be a document, not %s", commonparams.AliasFromType(v)),
+			command,
+		)
+	}
+
+	var auth importAuth
+	var err error
+
+	if auth.username, err = common.GetOptionalParam(doc, "username", ""); err != nil {
+		return nil, err
+	}
+
+	if auth.password, err = common.GetOptionalParam(doc, "password", ""); err != nil {
+		return nil, err
+	}
+
+	if auth.token, err = common.GetOptionalParam(doc, "token", ""); err != nil {
+		return nil, err
+	}
+
+	if auth.token != "" && auth.username != "" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"'auth' must contain either 'username' and 'password', or 'token'",
+			command,
+		)
+	}
+
+	return &auth, nil
+}
@@ -87,9 +87,21 @@ func (h *Handler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 	docsIter := params.Docs.Iterator()
 	defer docsIter.Close()
 
-	var inserted int32
+	var inserted, skipped int32
 	var writeErrors []*writeError
 
+	settings, err := collectionSettings(ctx, db, params.Collection)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	newID := h.newIDFunc(settings)
+
+	setDefaults, err := fieldDefaultsFunc(settings)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
 	var done bool
 	for !done {
 		const batchSize = 1000
@@ -113,7 +125,23 @@ func (h *Handler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 			doc := d.(*types.Document)
 
 			if !doc.Has("_id") {
-				doc.Set("_id", types.NewObjectID())
+				doc.Set("_id", newID())
+			}
+
+			if setDefaults != nil {
+				if err = setDefaults(doc); err != nil {
+					writeErrors = append(writeErrors, &writeError{
+						index:  int32(i),
+						code:   commonerrors.ErrBadValue,
+						errmsg: err.Error(),
+					})
+
+					if params.Ordered {
+						break
+					}
+
+					continue
+				}
 			}
 
 			// TODO https://github.com/FerretDB/FerretDB/issues/3454
@@ -150,8 +178,15 @@ func (h *Handler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 			}
 		}
 
-		if _, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: docs}); err == nil {
-			inserted += int32(len(docs))
+		var insertRes *backends.InsertAllResult
+
+		insertRes, err = c.InsertAll(ctx, &backends.InsertAllParams{
+			Docs:           docs,
+			SkipDuplicates: params.SkipDuplicates,
+		})
+		if err == nil {
+			inserted += int32(len(docs) - len(insertRes.Skipped))
+			skipped += int32(len(insertRes.Skipped))
 
 			if params.Ordered && len(writeErrors) > 0 {
 				break
@@ -162,10 +197,15 @@ func (h *Handler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 
 		// insert doc one by one upon failing on batch insertion
 		for j, doc := range docs {
-			if _, err = c.InsertAll(ctx, &backends.InsertAllParams{
-				Docs: []*types.Document{doc},
+			if insertRes, err = c.InsertAll(ctx, &backends.InsertAllParams{
+				Docs:           []*types.Document{doc},
+				SkipDuplicates: params.SkipDuplicates,
 			}); err == nil {
-				inserted++
+				if len(insertRes.Skipped) > 0 {
+					skipped++
+				} else {
+					inserted++
+				}
 
 				continue
 			}
@@ -190,6 +230,10 @@ func (h *Handler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		"n", inserted,
 	))
 
+	if params.SkipDuplicates {
+		res.Set("nSkipped", skipped)
+	}
+
 	if len(writeErrors) > 0 {
 		slices.SortFunc(writeErrors, func(a, b *writeError) int {
 			return cmp.Compare(a.index, b.index)
@@ -34,9 +34,14 @@ func (h *Handler) MsgIsMaster(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg
 		return nil, lazyerrors.Error(err)
 	}
 
+	compression, err := common.NegotiateCompression(ctx, doc)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.OpMsgSection{
-		Documents: common.IsMasterDocuments(),
+		Documents: common.IsMasterDocuments(compression, h.now()),
 	}))
 
 	return &reply, nil
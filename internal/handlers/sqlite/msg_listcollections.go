@@ -73,9 +73,29 @@ func (h *Handler) MsgListCollections(ctx context.Context, msg *wire.OpMsg) (*wir
 	collections := types.MakeArray(len(res.Collections))
 
 	for _, collection := range res.Collections {
+		options := new(types.Document)
+
+		if collection.Capped() {
+			options.Set("capped", true)
+			options.Set("size", collection.CappedSize)
+
+			if collection.CappedDocuments > 0 {
+				options.Set("max", collection.CappedDocuments)
+			}
+		}
+
 		d := must.NotFail(types.NewDocument(
 			"name", collection.Name,
 			"type", "collection",
+			"options", options,
+			"info", must.NotFail(types.NewDocument(
+				"readOnly", false,
+			)),
+			"idIndex", must.NotFail(types.NewDocument(
+				"v", int32(2),
+				"key", must.NotFail(types.NewDocument("_id", int32(1))),
+				"name", "_id_",
+			)),
 		))
 
 		matches, err := common.FilterDocument(d, filter)
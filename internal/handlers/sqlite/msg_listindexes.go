@@ -19,9 +19,13 @@ import (
 	"fmt"
 
 	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/clientconn/cursor"
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
 	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/wire"
@@ -46,6 +50,29 @@ func (h *Handler) MsgListIndexes(ctx context.Context, msg *wire.OpMsg) (*wire.Op
 		return nil, err
 	}
 
+	// all indexes are returned in the first batch by default
+	batchSize := int64(-1)
+
+	if v, _ := document.Get("cursor"); v != nil {
+		cursorDoc, ok := v.(*types.Document)
+		if !ok {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrTypeMismatch,
+				fmt.Sprintf(
+					"BSON field 'listIndexes.cursor' is the wrong type '%s', expected type 'object'",
+					commonparams.AliasFromType(v),
+				),
+				command,
+			)
+		}
+
+		if v, _ = cursorDoc.Get("batchSize"); v != nil {
+			if batchSize, err = commonparams.GetValidatedNumberParamWithMinValue(command, "batchSize", v, 0); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	db, err := h.b.Database(dbName)
 	if err != nil {
 		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
@@ -76,39 +103,38 @@ func (h *Handler) MsgListIndexes(ctx context.Context, msg *wire.OpMsg) (*wire.Op
 		return nil, lazyerrors.Error(err)
 	}
 
-	firstBatch := types.MakeArray(len(res.Indexes))
-
-	for _, index := range res.Indexes {
-		indexKey := must.NotFail(types.NewDocument())
+	specs := make([]*types.Document, len(res.Indexes))
+	for i, index := range res.Indexes {
+		specs[i] = indexSpec(&index)
+	}
 
-		for _, key := range index.Key {
-			order := int32(1)
-			if key.Descending {
-				order = -1
-			}
+	var cursorID int64
 
-			indexKey.Set(key.Field, order)
-		}
+	if batchSize >= 0 && int64(len(specs)) > batchSize {
+		// remaining specs are returned by getMore
+		username, _ := conninfo.Get(ctx).Auth()
 
-		indexDoc := must.NotFail(types.NewDocument(
-			"v", int32(2), // for compatibility, the meaning of this field is not documented
-			"key", indexKey,
-			"name", index.Name,
-		))
+		cursor := h.cursors.NewCursor(ctx, &cursor.NewParams{
+			Iter:       iterator.Values(iterator.ForSlice(specs[batchSize:])),
+			DB:         dbName,
+			Collection: collection,
+			Username:   username,
+		})
 
-		// only non-default unique indexes should have unique field in the response
-		if index.Unique && index.Name != backends.DefaultIndexName {
-			indexDoc.Set("unique", index.Unique)
-		}
+		cursorID = cursor.ID
+		specs = specs[:batchSize]
+	}
 
-		firstBatch.Append(indexDoc)
+	firstBatch := types.MakeArray(len(specs))
+	for _, spec := range specs {
+		firstBatch.Append(spec)
 	}
 
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.OpMsgSection{
 		Documents: []*types.Document{must.NotFail(types.NewDocument(
 			"cursor", must.NotFail(types.NewDocument(
-				"id", int64(0),
+				"id", cursorID,
 				"ns", fmt.Sprintf("%s.%s", dbName, collection),
 				"firstBatch", firstBatch,
 			)),
@@ -118,3 +144,59 @@ func (h *Handler) MsgListIndexes(ctx context.Context, msg *wire.OpMsg) (*wire.Op
 
 	return &reply, nil
 }
+
+// indexSpec returns the index specification document as it was passed to createIndexes,
+// with the same key order, name, and options.
+func indexSpec(index *backends.IndexInfo) *types.Document {
+	indexKey := must.NotFail(types.NewDocument())
+
+	for _, key := range index.Key {
+		order := int32(1)
+		if key.Descending {
+			order = -1
+		}
+
+		indexKey.Set(key.Field, order)
+	}
+
+	if index.Text {
+		indexKey = must.NotFail(types.NewDocument("_fts", "text", "_ftsx", int32(1)))
+	}
+
+	if index.Sphere {
+		indexKey = must.NotFail(types.NewDocument(index.Key[0].Field, "2dsphere"))
+	}
+
+	indexDoc := must.NotFail(types.NewDocument(
+		"v", int32(2), // for compatibility, the meaning of this field is not documented
+		"key", indexKey,
+		"name", index.Name,
+	))
+
+	if index.Text {
+		weights := types.MakeDocument(len(index.Key))
+		for _, key := range index.Key {
+			weights.Set(key.Field, int32(1))
+		}
+
+		indexDoc.Set("weights", weights)
+		indexDoc.Set("default_language", index.DefaultLanguage)
+		indexDoc.Set("language_override", "language")
+		indexDoc.Set("textIndexVersion", textIndexVersion)
+	}
+
+	if index.Sphere {
+		indexDoc.Set("2dsphereIndexVersion", sphereIndexVersion)
+	}
+
+	// only non-default unique indexes should have unique field in the response
+	if index.Unique && index.Name != backends.DefaultIndexName {
+		indexDoc.Set("unique", index.Unique)
+	}
+
+	if index.PartialFilterExpression != nil {
+		indexDoc.Set("partialFilterExpression", index.PartialFilterExpression)
+	}
+
+	return indexDoc
+}
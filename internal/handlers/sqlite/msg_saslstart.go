@@ -42,7 +42,13 @@ func (h *Handler) MsgSASLStart(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 	// we can't use it to query the database
 	_ = dbName
 
-	if err = common.SASLStart(ctx, document); err != nil {
+	err = common.SASLStart(ctx, document)
+
+	v, _ := document.Get("mechanism")
+	mechanism, _ := v.(string)
+	h.ConnMetrics.AuthenticationAttempted(mechanism, err == nil)
+
+	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
@@ -19,6 +19,7 @@ import (
 
 	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
@@ -27,7 +28,48 @@ import (
 
 // MsgServerStatus implements HandlerInterface.
 func (h *Handler) MsgServerStatus(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
-	res, err := common.ServerStatus(h.StateProvider.Get(), h.ConnMetrics)
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res, err := h.serverStatus(ctx)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	// sections could be excluded with `{serverStatus: 1, <section>: 0}`
+	for _, key := range document.Keys() {
+		switch key {
+		case document.Command(), "$db", "ok":
+			continue
+		}
+
+		if !res.Has(key) {
+			continue
+		}
+
+		var include bool
+		if include, err = commonparams.GetBoolOptionalParam(key, must.NotFail(document.Get(key))); err != nil {
+			return nil, err
+		}
+
+		if !include {
+			res.Remove(key)
+		}
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{res},
+	}))
+
+	return &reply, nil
+}
+
+// serverStatus returns serverStatus command's reply document, including the `ok` field.
+func (h *Handler) serverStatus(ctx context.Context) (*types.Document, error) {
+	res, err := common.ServerStatus(h.StateProvider.Get(), h.ConnMetrics, h.now())
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
@@ -47,10 +89,5 @@ func (h *Handler) MsgServerStatus(ctx context.Context, msg *wire.OpMsg) (*wire.O
 		"internalViews", int32(0),
 	)))
 
-	var reply wire.OpMsg
-	must.NoError(reply.SetSections(wire.OpMsgSection{
-		Documents: []*types.Document{res},
-	}))
-
-	return &reply, nil
+	return res, nil
 }
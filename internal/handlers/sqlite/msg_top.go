@@ -0,0 +1,96 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// topFields contains fields of a single namespace in the top command's reply, in MongoDB order.
+var topFields = []string{
+	"total", "readLock", "writeLock", "queries", "getmore", "insert", "update", "remove", "commands",
+}
+
+// MsgTop implements HandlerInterface.
+func (h *Handler) MsgTop(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := document.Command()
+
+	dbName, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if dbName != "admin" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	dbs, err := h.b.ListDatabases(ctx, nil)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	totals := must.NotFail(types.NewDocument(
+		"note", "all times in microseconds",
+	))
+
+	for _, dbInfo := range dbs.Databases {
+		db, err := h.b.Database(dbInfo.Name)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		list, err := db.ListCollections(ctx, nil)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		for _, cInfo := range list.Collections {
+			// FerretDB does not track per-collection usage yet, so all counters are zero;
+			// the reply has the MongoDB shape so GUI clients can display it.
+			ns := types.MakeDocument(len(topFields))
+			for _, f := range topFields {
+				ns.Set(f, must.NotFail(types.NewDocument("time", int64(0), "count", int64(0))))
+			}
+
+			totals.Set(dbInfo.Name+"."+cInfo.Name, ns)
+		}
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"totals", totals,
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
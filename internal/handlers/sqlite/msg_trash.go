@@ -0,0 +1,173 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgTrash implements HandlerInterface.
+func (h *Handler) MsgTrash(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := document.Command()
+
+	if h.trash == nil {
+		return nil, commonerrors.NewCommandErrorMsg(
+			commonerrors.ErrCommandNotFound,
+			fmt.Sprintf("no such command: '%s'", command),
+		)
+	}
+
+	dbName, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	action, err := common.GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	res := must.NotFail(types.NewDocument())
+
+	switch action {
+	case "list":
+		entries, err := h.trash.List(ctx, dbName)
+		if err != nil && !backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseDoesNotExist) {
+			return nil, lazyerrors.Error(err)
+		}
+
+		collections := types.MakeArray(len(entries))
+		for _, e := range entries {
+			collections.Append(must.NotFail(types.NewDocument(
+				"name", e.Name,
+				"collection", e.Collection,
+				"dropped", e.Dropped,
+			)))
+		}
+
+		res.Set("collections", collections)
+
+	case "restore":
+		var name, to string
+		if name, err = common.GetRequiredParam[string](document, "name"); err != nil {
+			return nil, err
+		}
+
+		if to, err = common.GetOptionalParam(document, "to", ""); err != nil {
+			return nil, err
+		}
+
+		restored, err := h.trash.Restore(ctx, dbName, name, to)
+
+		switch {
+		case err == nil:
+			res.Set("restored", restored)
+
+		case backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist):
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNamespaceNotFound,
+				fmt.Sprintf("Collection %s.%s is not in the trash", dbName, name),
+				command,
+			)
+
+		case backends.ErrorCodeIs(err, backends.ErrorCodeCollectionAlreadyExists):
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNamespaceExists,
+				fmt.Sprintf("Collection %s.%s already exists", dbName, to),
+				command,
+			)
+
+		case backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid):
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrIllegalOperation,
+				fmt.Sprintf("Invalid collection name: %s", to),
+				command,
+			)
+
+		default:
+			return nil, lazyerrors.Error(err)
+		}
+
+	case "purge":
+		var name string
+		if name, err = common.GetOptionalParam(document, "name", ""); err != nil {
+			return nil, err
+		}
+
+		var names []string
+
+		if name != "" {
+			names = []string{name}
+		} else {
+			entries, err := h.trash.List(ctx, dbName)
+			if err != nil && !backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseDoesNotExist) {
+				return nil, lazyerrors.Error(err)
+			}
+
+			for _, e := range entries {
+				names = append(names, e.Name)
+			}
+		}
+
+		for _, n := range names {
+			err = h.trash.Purge(ctx, dbName, n)
+
+			switch {
+			case err == nil:
+				// nothing
+			case backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist):
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrNamespaceNotFound,
+					fmt.Sprintf("Collection %s.%s is not in the trash", dbName, n),
+					command,
+				)
+			default:
+				return nil, lazyerrors.Error(err)
+			}
+		}
+
+		res.Set("purged", int32(len(names)))
+
+	default:
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("unknown trash action %q, expected 'list', 'restore' or 'purge'", action),
+			command,
+		)
+	}
+
+	res.Set("ok", float64(1))
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{res},
+	}))
+
+	return &reply, nil
+}
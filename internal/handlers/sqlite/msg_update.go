@@ -116,7 +116,14 @@ func (h *Handler) updateDocument(ctx context.Context, params *common.UpdateParam
 		return 0, 0, nil, lazyerrors.Error(err)
 	}
 
-	for _, u := range params.Updates {
+	settings, err := collectionSettings(ctx, db, params.Collection)
+	if err != nil {
+		return 0, 0, nil, lazyerrors.Error(err)
+	}
+
+	newID := h.newIDFunc(settings)
+
+	for i, u := range params.Updates {
 		c, err := db.Collection(params.Collection)
 		if err != nil {
 			if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
@@ -175,17 +182,9 @@ func (h *Handler) updateDocument(ctx context.Context, params *common.UpdateParam
 				continue
 			}
 
-			// TODO https://github.com/FerretDB/FerretDB/issues/3040
-			hasQueryOperators, err := common.HasQueryOperator(u.Filter)
+			doc, err := common.UpsertDocument("update", u.Filter)
 			if err != nil {
-				return 0, 0, nil, lazyerrors.Error(err)
-			}
-
-			var doc *types.Document
-			if hasQueryOperators {
-				doc = must.NotFail(types.NewDocument())
-			} else {
-				doc = u.Filter
+				return 0, 0, nil, err
 			}
 
 			hasUpdateOperators, err := common.HasSupportedUpdateModifiers("update", u.Update)
@@ -195,18 +194,25 @@ func (h *Handler) updateDocument(ctx context.Context, params *common.UpdateParam
 
 			if hasUpdateOperators {
 				// TODO https://github.com/FerretDB/FerretDB/issues/3044
-				if _, err = common.UpdateDocument("update", doc, u.Update); err != nil {
+				if _, err = common.UpdateDocument("update", doc, u.Update, u.ParsedArrayFilters, h.now()); err != nil {
 					return 0, 0, nil, err
 				}
 			} else {
-				doc = u.Update
+				// replacement document only takes _id from the query, do not modify the command document
+				id, _ := doc.Get("_id")
+
+				doc = u.Update.DeepCopy()
+				if id != nil && !doc.Has("_id") {
+					doc.Set("_id", id)
+				}
 			}
 
 			if !doc.Has("_id") {
-				doc.Set("_id", types.NewObjectID())
+				doc.Set("_id", newID())
 			}
+
 			upserted.Append(must.NotFail(types.NewDocument(
-				"index", int32(upserted.Len()),
+				"index", int32(i),
 				"_id", must.NotFail(doc.Get("_id")),
 			)))
 
@@ -236,7 +242,7 @@ func (h *Handler) updateDocument(ctx context.Context, params *common.UpdateParam
 		matched += int32(len(resDocs))
 
 		for _, doc := range resDocs {
-			changed, err := common.UpdateDocument("update", doc, u.Update)
+			changed, err := common.UpdateDocument("update", doc, u.Update, u.ParsedArrayFilters, h.now())
 			if err != nil {
 				return 0, 0, nil, lazyerrors.Error(err)
 			}
@@ -252,7 +258,7 @@ func (h *Handler) updateDocument(ctx context.Context, params *common.UpdateParam
 
 			updateRes, err := c.UpdateAll(ctx, &backends.UpdateAllParams{Docs: []*types.Document{doc}})
 			if err != nil {
-				return 0, 0, nil, lazyerrors.Error(err)
+				return 0, 0, nil, err
 			}
 
 			modified += int32(updateRes.Updated)
@@ -0,0 +1,122 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgValidateDBMetadata implements HandlerInterface.
+//
+// Unlike MongoDB's command that checks API version compatibility,
+// it compares FerretDB metadata with the actual backend schema:
+// collections without tables, tables without collections, and missing indexes are reported.
+// With `repair: true`, collections without tables are removed and missing indexes are recreated.
+func (h *Handler) MsgValidateDBMetadata(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	common.Ignored(document, h.L, "apiParameters", "comment")
+
+	if _, err = common.GetRequiredParam[string](document, "$db"); err != nil {
+		return nil, err
+	}
+
+	dbName, err := common.GetOptionalParam(document, "db", "")
+	if err != nil {
+		return nil, err
+	}
+
+	var repair bool
+	if v, _ := document.Get("repair"); v != nil {
+		if repair, err = commonparams.GetBoolOptionalParam("repair", v); err != nil {
+			return nil, err
+		}
+	}
+
+	dbNames := []string{dbName}
+
+	if dbName == "" {
+		var res *backends.ListDatabasesResult
+		if res, err = h.b.ListDatabases(ctx, nil); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		dbNames = make([]string, len(res.Databases))
+		for i, dbInfo := range res.Databases {
+			dbNames[i] = dbInfo.Name
+		}
+	}
+
+	problems := types.MakeArray(0)
+
+	for _, name := range dbNames {
+		db, err := h.b.Database(name)
+		if err != nil {
+			if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
+				continue
+			}
+
+			return nil, lazyerrors.Error(err)
+		}
+
+		res, err := db.ValidateMetadata(ctx, &backends.ValidateMetadataParams{Repair: repair})
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		for _, p := range res.Problems {
+			problem := must.NotFail(types.NewDocument(
+				"type", string(p.Type),
+				"db", name,
+			))
+
+			if p.Collection != "" {
+				problem.Set("collection", p.Collection)
+			}
+
+			problem.Set("table", p.Table)
+
+			if p.Index != "" {
+				problem.Set("index", p.Index)
+			}
+
+			problem.Set("repaired", p.Repaired)
+
+			problems.Append(problem)
+		}
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"apiVersionErrors", types.MakeArray(0),
+			"problems", problems,
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
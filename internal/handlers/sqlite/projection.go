@@ -0,0 +1,155 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// projectionFields returns top-level fields that the backend should return for the given
+// inclusion projection, filter, and sort documents.
+// Fields used by the filter and sort are included, as documents are filtered and sorted in memory.
+//
+// It returns nil if projection could not be pushed down: it is empty, it is an exclusion projection,
+// or projection or filter use operators or expressions.
+// In that case, whole documents should be fetched.
+func projectionFields(projection, filter, sort *types.Document) []string {
+	if projection.Len() == 0 {
+		return nil
+	}
+
+	fields := []string{"_id"}
+	var inclusion bool
+
+	for i, key := range projection.Keys() {
+		include, ok := projectionInclusion(projection.Values()[i])
+		if !ok || strings.ContainsRune(key, '$') {
+			return nil
+		}
+
+		if key == "_id" {
+			if include && projection.Len() == 1 {
+				inclusion = true
+			}
+
+			continue
+		}
+
+		if !include {
+			return nil
+		}
+
+		inclusion = true
+
+		if fields = appendTopLevelField(fields, key); fields == nil {
+			return nil
+		}
+	}
+
+	if !inclusion {
+		return nil
+	}
+
+	if fields = appendFilterFields(fields, filter); fields == nil {
+		return nil
+	}
+
+	for _, key := range sort.Keys() {
+		if fields = appendTopLevelField(fields, key); fields == nil {
+			return nil
+		}
+	}
+
+	return fields
+}
+
+// projectionInclusion returns true if the given projection value includes the field,
+// and false if it excludes it.
+// The second returned value is false for operators and expressions.
+func projectionInclusion(value any) (bool, bool) {
+	switch value := value.(type) {
+	case bool:
+		return value, true
+	case int32:
+		return value != 0, true
+	case int64:
+		return value != 0, true
+	case float64:
+		return value != 0, true
+	default:
+		return false, false
+	}
+}
+
+// appendFilterFields appends top-level fields used by the given filter to fields.
+// It returns nil if the filter contains operators that could use any field.
+func appendFilterFields(fields []string, filter *types.Document) []string {
+	for i, key := range filter.Keys() {
+		switch key {
+		case "$comment":
+			continue
+
+		case "$and", "$or", "$nor":
+			arr, ok := filter.Values()[i].(*types.Array)
+			if !ok {
+				return nil
+			}
+
+			for j := 0; j < arr.Len(); j++ {
+				expr, ok := must.NotFail(arr.Get(j)).(*types.Document)
+				if !ok {
+					return nil
+				}
+
+				if fields = appendFilterFields(fields, expr); fields == nil {
+					return nil
+				}
+			}
+
+			continue
+		}
+
+		if strings.HasPrefix(key, "$") {
+			// $expr, $where, $jsonSchema, etc.
+			return nil
+		}
+
+		if fields = appendTopLevelField(fields, key); fields == nil {
+			return nil
+		}
+	}
+
+	return fields
+}
+
+// appendTopLevelField appends the first element of the given dot notation path to fields
+// if it is not there yet.
+// It returns nil for invalid paths.
+func appendTopLevelField(fields []string, path string) []string {
+	field, _, _ := strings.Cut(path, ".")
+	if field == "" {
+		return nil
+	}
+
+	if !slices.Contains(fields, field) {
+		fields = append(fields, field)
+	}
+
+	return fields
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// naturalSort returns a sort field for the backend if the given sort document is `{$natural: 1}` or `{$natural: -1}`.
+// It returns nil if natural order is not requested.
+//
+// Natural order is always applied by the backend, so in-memory sorting should be skipped.
+func naturalSort(sort *types.Document) (*backends.SortField, error) {
+	if sort == nil || !sort.Has(backends.NaturalSortKey) {
+		return nil, nil
+	}
+
+	if sort.Len() != 1 {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"$natural sort cannot be combined with other sort fields",
+			"sort",
+		)
+	}
+
+	order, err := common.GetSortType(backends.NaturalSortKey, must.NotFail(sort.Get(backends.NaturalSortKey)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &backends.SortField{
+		Key:        backends.NaturalSortKey,
+		Descending: order == types.Descending,
+	}, nil
+}
+
+// naturalHint returns a natural sort field if the given hint is `{$natural: 1}` or `{$natural: -1}`.
+// It returns nil for other hints; index hints are ignored.
+//
+// Natural hint bypasses index selection: the filter is not pushed down,
+// and the forward one reads the whole table sequentially in physical order.
+func naturalHint(hint any) (*backends.SortField, error) {
+	doc, ok := hint.(*types.Document)
+	if !ok || !doc.Has(backends.NaturalSortKey) {
+		return nil, nil
+	}
+
+	if doc.Len() != 1 {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"$natural hint cannot be combined with other fields",
+			"hint",
+		)
+	}
+
+	order, err := common.GetSortType(backends.NaturalSortKey, must.NotFail(doc.Get(backends.NaturalSortKey)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &backends.SortField{
+		Key:        backends.NaturalSortKey,
+		Descending: order == types.Descending,
+	}, nil
+}
+
+// applyNaturalHint updates query parameters for the natural hint, if any.
+//
+// It should be called after sort pushdown is set up;
+// sort is the remaining sort document that is applied in memory.
+func applyNaturalHint(qp *backends.QueryParams, hint *backends.SortField, sort *types.Document) {
+	// text and geospatial queries require indexes
+	if hint == nil || qp.TextSearch != nil || qp.Geo != nil {
+		return
+	}
+
+	// the filter is applied by the handler
+	qp.Filter = nil
+
+	switch {
+	case qp.Sort != nil || sort.Len() > 0:
+		// explicit sort is applied as usual
+	case hint.Descending:
+		qp.Sort = hint
+	default:
+		qp.SeqScan = true
+	}
+}
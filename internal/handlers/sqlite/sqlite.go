@@ -18,20 +18,43 @@
 package sqlite
 
 import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 
 	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/decorators/collstats"
+	"github.com/FerretDB/FerretDB/internal/backends/decorators/history"
 	"github.com/FerretDB/FerretDB/internal/backends/decorators/oplog"
+	"github.com/FerretDB/FerretDB/internal/backends/decorators/querycache"
+	"github.com/FerretDB/FerretDB/internal/backends/decorators/sizecache"
+	"github.com/FerretDB/FerretDB/internal/backends/decorators/trash"
 	"github.com/FerretDB/FerretDB/internal/backends/hana"
 	"github.com/FerretDB/FerretDB/internal/backends/postgresql"
 	"github.com/FerretDB/FerretDB/internal/backends/sqlite"
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
 	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
 	"github.com/FerretDB/FerretDB/internal/clientconn/cursor"
+	"github.com/FerretDB/FerretDB/internal/clientconn/failpoints"
 	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/dropprotection"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/ftdc"
+	"github.com/FerretDB/FerretDB/internal/util/jobs"
 	"github.com/FerretDB/FerretDB/internal/util/state"
 )
 
+// Diagnostic data files rotation parameters, the same as MongoDB's defaults.
+const (
+	diagnosticsMaxFileSize = 10 * 1024 * 1024
+	diagnosticsMaxFiles    = 20
+)
+
 // Handler implements handlers.Interface.
 type Handler struct {
 	*NewOpts
@@ -39,6 +62,26 @@ type Handler struct {
 	b backends.Backend
 
 	cursors *cursor.Registry
+
+	trash *trash.Backend // nil if disabled
+
+	collStats *collstats.Backend // nil if disabled
+
+	jobs *jobs.Scheduler
+
+	ftdc *ftdc.Writer // nil if disabled
+
+	imports *importOps
+
+	// settingsM serializes collection settings updates
+	settingsM sync.Mutex
+
+	// viewsM serializes materialized views refreshes and protects viewsRefreshed
+	viewsM         sync.Mutex
+	viewsRefreshed map[string]time.Time // "db.collection" -> last refresh time
+
+	now         func() time.Time
+	newObjectID func() types.ObjectID
 }
 
 // NewOpts represents handler configuration.
@@ -48,27 +91,76 @@ type NewOpts struct {
 	Backend string
 	URI     string
 
+	// for postgresql backend only
+	PostgreSQLRelationalViews  bool
+	PostgreSQLStatementTimeout time.Duration
+	PostgreSQLLockTimeout      time.Duration
+	PostgreSQLTenantIsolation  bool
+
 	L             *zap.Logger
 	ConnMetrics   *connmetrics.ConnMetrics
 	StateProvider *state.Provider
+	FailPoints    *failpoints.Registry // nil disables configureFailPoint command
+
+	SizeCacheMaxAge time.Duration         // 0 disables database size caching
+	QueryCacheSize  int                   // 0 disables query result caching
+	DropProtection  *dropprotection.Guard // nil disables drop protection
+	TrashRetention  time.Duration         // 0 disables keeping dropped collections in the trash
+	ArchiveInterval time.Duration         // 0 disables applying archiving policies
+	AnalyzeInterval time.Duration         // 0 disables periodic statistics refresh
+	CollectionStats bool                  // enables per-collection latency and document size histograms
+
+	MaterializedViewsInterval time.Duration // 0 disables periodic refresh of materialized views
+
+	DiagnosticsDir      string        // empty disables writing diagnostic data snapshots
+	DiagnosticsInterval time.Duration // 0 disables writing diagnostic data snapshots
+
+	ImportDir string // empty disables importing from files
+	ExportDir string // empty disables exporting
+	InitDir   string // empty disables seeding on the first start
+
+	ImportURLHosts []string // empty disables importing from URLs
+
+	SQLStage bool // enables $sql aggregation stage with raw SQL queries
+
+	AdminUsers []string // users allowed to import from URLs and use $sql stage
 
 	// test options
 	DisableFilterPushdown    bool
 	EnableUnsafeSortPushdown bool
 	EnableOplog              bool
+
+	// for testing only; stages.DefaultMemoryLimit is used if 0
+	AggregationMemoryLimit int64
+
+	// for testing only; system time and random ObjectIDs are used if nil
+	Now         func() time.Time
+	NewObjectID func() types.ObjectID
 }
 
 // New returns a new handler.
 func New(opts *NewOpts) (handlers.Interface, error) {
+	var fw *ftdc.Writer
+	if opts.DiagnosticsDir != "" && opts.DiagnosticsInterval > 0 {
+		var err error
+		if fw, err = ftdc.NewWriter(opts.DiagnosticsDir, diagnosticsMaxFileSize, diagnosticsMaxFiles); err != nil {
+			return nil, err
+		}
+	}
+
 	var b backends.Backend
 	var err error
 
 	switch opts.Backend {
 	case "postgresql":
 		b, err = postgresql.NewBackend(&postgresql.NewBackendParams{
-			URI: opts.URI,
-			L:   opts.L,
-			P:   opts.StateProvider,
+			URI:              opts.URI,
+			L:                opts.L,
+			P:                opts.StateProvider,
+			RelationalViews:  opts.PostgreSQLRelationalViews,
+			StatementTimeout: opts.PostgreSQLStatementTimeout,
+			LockTimeout:      opts.PostgreSQLLockTimeout,
+			TenantIsolation:  opts.PostgreSQLTenantIsolation,
 		})
 	case "sqlite":
 		b, err = sqlite.NewBackend(&sqlite.NewBackendParams{
@@ -87,22 +179,119 @@ func New(opts *NewOpts) (handlers.Interface, error) {
 	}
 
 	if err != nil {
+		if fw != nil {
+			_ = fw.Close()
+		}
+
 		return nil, err
 	}
 
+	scheduler := jobs.NewScheduler(opts.L.Named("jobs"))
+
+	// wrap the backend first so writes made by other decorators invalidate cached results
+	if opts.QueryCacheSize > 0 {
+		b = querycache.NewBackend(b, querycache.NewMemoryStore(opts.QueryCacheSize), opts.L.Named("querycache"))
+	}
+
+	var tb *trash.Backend
+	if opts.TrashRetention > 0 {
+		tb = trash.NewBackend(b, opts.TrashRetention, opts.L.Named("trash"))
+		b = tb
+
+		scheduler.Add("trashPurge", min(opts.TrashRetention, time.Hour), backgroundJob(tb.PurgeExpired))
+	}
+
+	if opts.SizeCacheMaxAge > 0 {
+		scb := sizecache.NewBackend(b, opts.SizeCacheMaxAge, opts.L.Named("sizecache"))
+		b = scb
+
+		scheduler.Add("sizeCacheRefresh", opts.SizeCacheMaxAge/2, backgroundJob(scb.Refresh))
+	}
+
+	b = history.NewBackend(b, historyCollectionName, opts.L.Named("history"))
+
 	if opts.EnableOplog {
 		b = oplog.NewBackend(b, opts.L.Named("oplog"))
 	}
 
-	return &Handler{
-		b:       b,
-		NewOpts: opts,
-		cursors: cursor.NewRegistry(opts.L.Named("cursors")),
-	}, nil
+	var csb *collstats.Backend
+	if opts.CollectionStats {
+		csb = collstats.NewBackend(b, opts.L.Named("collstats"))
+		b = csb
+	}
+
+	h := &Handler{
+		b:           b,
+		NewOpts:     opts,
+		cursors:     cursor.NewRegistry(opts.L.Named("cursors")),
+		trash:       tb,
+		collStats:   csb,
+		jobs:        scheduler,
+		ftdc:        fw,
+		imports:     newImportOps(),
+		now:         time.Now,
+		newObjectID: types.NewObjectID,
+	}
+
+	if opts.Now != nil {
+		h.now = opts.Now
+	}
+
+	if opts.NewObjectID != nil {
+		h.newObjectID = opts.NewObjectID
+	}
+
+	if opts.InitDir != "" {
+		if err = h.initialize(context.Background()); err != nil {
+			h.Close()
+			return nil, fmt.Errorf("failed to initialize from %s: %w", opts.InitDir, err)
+		}
+	}
+
+	if opts.ArchiveInterval > 0 {
+		scheduler.Add("archive", opts.ArchiveInterval, backgroundJob(h.archive))
+	}
+
+	if opts.AnalyzeInterval > 0 {
+		scheduler.Add("analyze", opts.AnalyzeInterval, backgroundJob(h.analyze))
+	}
+
+	if opts.MaterializedViewsInterval > 0 {
+		scheduler.Add("materializedViews", opts.MaterializedViewsInterval, backgroundJob(h.refreshMaterializedViews))
+	}
+
+	if fw != nil {
+		scheduler.Add("diagnostics", opts.DiagnosticsInterval, backgroundJob(h.writeDiagnosticData))
+	}
+
+	return h, nil
+}
+
+// backgroundJob returns a job function that calls f with connection information without authentication,
+// so backends and decorators could be used the same way as for client connections.
+func backgroundJob(f jobs.Func) jobs.Func {
+	return func(ctx context.Context) error {
+		return f(conninfo.Ctx(ctx, conninfo.New()))
+	}
+}
+
+// isAdmin returns true if the user authenticated on the connection is listed in AdminUsers.
+func (h *Handler) isAdmin(ctx context.Context) bool {
+	username, _ := conninfo.Get(ctx).Auth()
+
+	return username != "" && slices.Contains(h.AdminUsers, username)
 }
 
 // Close implements handlers.Interface.
 func (h *Handler) Close() {
+	h.jobs.Close()
+
+	if h.ftdc != nil {
+		if err := h.ftdc.Close(); err != nil {
+			h.L.Warn("Failed to close diagnostic data file", zap.Error(err))
+		}
+	}
+
 	h.cursors.Close()
 	h.b.Close()
 }
@@ -111,12 +300,14 @@ func (h *Handler) Close() {
 func (h *Handler) Describe(ch chan<- *prometheus.Desc) {
 	h.b.Describe(ch)
 	h.cursors.Describe(ch)
+	h.jobs.Describe(ch)
 }
 
 // Collect implements handlers.Interface.
 func (h *Handler) Collect(ch chan<- prometheus.Metric) {
 	h.b.Collect(ch)
 	h.cursors.Collect(ch)
+	h.jobs.Collect(ch)
 }
 
 // check interfaces
@@ -0,0 +1,200 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// errSystemCollectionReadOnly is returned when emulated system collections are modified.
+var errSystemCollectionReadOnly = errors.New("system collections are read-only")
+
+// collection returns the collection with the given name for reading documents.
+//
+// Some system collections inspected by tools are emulated:
+//   - system.views contains definitions of materialized views of the database;
+//   - system.js is always empty, stored JavaScript functions are not supported;
+//   - system.version of the admin database contains the feature compatibility version.
+//
+// They are read-only and are not listed. Other collections are returned by the backend.
+func (h *Handler) collection(ctx context.Context, db backends.Database, dbName, cName string) (backends.Collection, error) {
+	var docs []*types.Document
+
+	switch {
+	case cName == "system.views":
+		var err error
+		if docs, err = h.systemViews(ctx, dbName); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+	case cName == "system.js":
+		// no documents
+
+	case cName == "system.version" && dbName == "admin":
+		docs = []*types.Document{must.NotFail(types.NewDocument(
+			"_id", "featureCompatibilityVersion",
+			"version", "6.0",
+		))}
+
+	default:
+		return db.Collection(cName)
+	}
+
+	return &systemCollection{docs: docs}, nil
+}
+
+// systemViews returns system.views documents for materialized views of the given database,
+// sorted by _id.
+func (h *Handler) systemViews(ctx context.Context, dbName string) ([]*types.Document, error) {
+	settings, err := h.listCollectionSetting(ctx, dbName, settingMaterialized)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res := make([]*types.Document, 0, len(settings))
+
+	for _, s := range settings {
+		view, err := unmarshalMaterializedView(s.v)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		pipeline := types.MakeArray(len(view.pipeline))
+		for _, d := range view.pipeline {
+			pipeline.Append(d)
+		}
+
+		res = append(res, must.NotFail(types.NewDocument(
+			"_id", collectionKey(s.dbName, s.cName),
+			"viewOn", view.viewOn,
+			"pipeline", pipeline,
+		)))
+	}
+
+	return res, nil
+}
+
+// systemCollection implements backends.Collection interface for emulated read-only system collections.
+//
+// Query filters are not applied, the handler filters documents anyway.
+type systemCollection struct {
+	docs []*types.Document
+}
+
+// Query implements backends.Collection interface.
+func (sc *systemCollection) Query(ctx context.Context, params *backends.QueryParams) (*backends.QueryResult, error) {
+	docs := slices.Clone(sc.docs)
+
+	if params != nil && params.Sort != nil {
+		if params.Sort.Key == backends.NaturalSortKey {
+			// $natural order
+			if params.Sort.Descending {
+				slices.Reverse(docs)
+			}
+		} else {
+			order := int32(1)
+			if params.Sort.Descending {
+				order = -1
+			}
+
+			if err := common.SortDocuments(docs, must.NotFail(types.NewDocument(params.Sort.Key, order))); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+		}
+	}
+
+	if params != nil && params.Limit > 0 && int64(len(docs)) > params.Limit {
+		docs = docs[:params.Limit]
+	}
+
+	return &backends.QueryResult{
+		Iter: iterator.Values(iterator.ForSlice(docs)),
+	}, nil
+}
+
+// Count implements backends.Collection interface.
+//
+// System collections are small; documents are counted by handlers.
+func (sc *systemCollection) Count(ctx context.Context, params *backends.CountParams) (*backends.CountResult, error) {
+	return new(backends.CountResult), nil
+}
+
+// InsertAll implements backends.Collection interface.
+func (sc *systemCollection) InsertAll(ctx context.Context, params *backends.InsertAllParams) (*backends.InsertAllResult, error) { //nolint:lll // for readability
+	return nil, backends.NewError(backends.ErrorCodeCollectionNameIsInvalid, errSystemCollectionReadOnly)
+}
+
+// UpdateAll implements backends.Collection interface.
+func (sc *systemCollection) UpdateAll(ctx context.Context, params *backends.UpdateAllParams) (*backends.UpdateAllResult, error) { //nolint:lll // for readability
+	return nil, backends.NewError(backends.ErrorCodeCollectionNameIsInvalid, errSystemCollectionReadOnly)
+}
+
+// DeleteAll implements backends.Collection interface.
+func (sc *systemCollection) DeleteAll(ctx context.Context, params *backends.DeleteAllParams) (*backends.DeleteAllResult, error) { //nolint:lll // for readability
+	return nil, backends.NewError(backends.ErrorCodeCollectionNameIsInvalid, errSystemCollectionReadOnly)
+}
+
+// FindAndModify implements backends.Collection interface.
+func (sc *systemCollection) FindAndModify(ctx context.Context, params *backends.FindAndModifyParams) (*backends.FindAndModifyResult, error) { //nolint:lll // for readability
+	return nil, backends.NewError(backends.ErrorCodeCollectionNameIsInvalid, errSystemCollectionReadOnly)
+}
+
+// Explain implements backends.Collection interface.
+func (sc *systemCollection) Explain(ctx context.Context, params *backends.ExplainParams) (*backends.ExplainResult, error) {
+	return &backends.ExplainResult{
+		QueryPlanner: must.NotFail(types.NewDocument("Plan", "emulated system collection")),
+	}, nil
+}
+
+// Stats implements backends.Collection interface.
+func (sc *systemCollection) Stats(ctx context.Context, params *backends.CollectionStatsParams) (*backends.CollectionStatsResult, error) { //nolint:lll // for readability
+	return &backends.CollectionStatsResult{
+		CountDocuments: int64(len(sc.docs)),
+	}, nil
+}
+
+// Compact implements backends.Collection interface.
+func (sc *systemCollection) Compact(ctx context.Context, params *backends.CompactParams) (*backends.CompactResult, error) {
+	return nil, backends.NewError(backends.ErrorCodeCollectionNameIsInvalid, errSystemCollectionReadOnly)
+}
+
+// ListIndexes implements backends.Collection interface.
+func (sc *systemCollection) ListIndexes(ctx context.Context, params *backends.ListIndexesParams) (*backends.ListIndexesResult, error) { //nolint:lll // for readability
+	return new(backends.ListIndexesResult), nil
+}
+
+// CreateIndexes implements backends.Collection interface.
+func (sc *systemCollection) CreateIndexes(ctx context.Context, params *backends.CreateIndexesParams) (*backends.CreateIndexesResult, error) { //nolint:lll // for readability
+	return nil, backends.NewError(backends.ErrorCodeCollectionNameIsInvalid, errSystemCollectionReadOnly)
+}
+
+// DropIndexes implements backends.Collection interface.
+func (sc *systemCollection) DropIndexes(ctx context.Context, params *backends.DropIndexesParams) (*backends.DropIndexesResult, error) { //nolint:lll // for readability
+	return nil, backends.NewError(backends.ErrorCodeCollectionNameIsInvalid, errSystemCollectionReadOnly)
+}
+
+// check interfaces
+var (
+	_ backends.Collection = (*systemCollection)(nil)
+)
@@ -0,0 +1,222 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// defaultTextSearchLanguage is the default language of text indexes.
+const defaultTextSearchLanguage = "english"
+
+// textSearchLanguages are languages supported by text indexes and $text queries.
+var textSearchLanguages = []string{
+	"danish", "dutch", "english", "finnish", "french", "german", "hungarian", "italian",
+	"norwegian", "portuguese", "romanian", "russian", "spanish", "swedish", "turkish", "none",
+}
+
+// textIndexVersion is the only supported text index version.
+const textIndexVersion = int32(3)
+
+// prepareTextSearch extracts the top-level $text query operator from the given filter.
+//
+// It returns nil TextSearch and the same filter if there is no $text.
+// Otherwise, it returns parsed $text and a copy of the filter without it.
+// In that case, it checks that the collection has a text index.
+//
+// $text in other places (like $or) is left as is, and is rejected by the filter.
+func prepareTextSearch(ctx context.Context, c backends.Collection, filter *types.Document) (*backends.TextSearch, *types.Document, error) { //nolint:lll // for readability
+	v, _ := filter.Get("$text")
+	if v == nil {
+		return nil, filter, nil
+	}
+
+	ts, err := parseTextSearch(v)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res, err := c.ListIndexes(ctx, new(backends.ListIndexesParams))
+	if err != nil && !backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist) {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	if res == nil || !slices.ContainsFunc(res.Indexes, func(index backends.IndexInfo) bool { return index.Text }) {
+		return nil, nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrIndexNotFound,
+			"text index required for $text query",
+			"$text",
+		)
+	}
+
+	filter = filter.DeepCopy()
+	filter.Remove("$text")
+
+	return ts, filter, nil
+}
+
+// parseTextSearch parses the value of $text query operator.
+func parseTextSearch(v any) (*backends.TextSearch, error) {
+	expr, ok := v.(*types.Document)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"$text expects an object",
+			"$text",
+		)
+	}
+
+	var search string
+	var ts backends.TextSearch
+
+	for _, k := range expr.Keys() {
+		v := must.NotFail(expr.Get(k))
+
+		switch k {
+		case "$search", "$language":
+			s, ok := v.(string)
+			if !ok {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrTypeMismatch,
+					fmt.Sprintf(
+						"BSON field '%s' is the wrong type '%s', expected type 'string'",
+						k, commonparams.AliasFromType(v),
+					),
+					"$text",
+				)
+			}
+
+			if k == "$search" {
+				search = s
+				break
+			}
+
+			if !slices.Contains(textSearchLanguages, s) {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrBadValue,
+					fmt.Sprintf("language %q is not supported", s),
+					"$text",
+				)
+			}
+
+			ts.Language = s
+
+		case "$caseSensitive", "$diacriticSensitive":
+			b, ok := v.(bool)
+			if !ok {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrTypeMismatch,
+					fmt.Sprintf(
+						"BSON field '%s' is the wrong type '%s', expected type 'bool'",
+						k, commonparams.AliasFromType(v),
+					),
+					"$text",
+				)
+			}
+
+			if b {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrNotImplemented,
+					fmt.Sprintf("%s is not implemented yet", k),
+					"$text",
+				)
+			}
+
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf("unknown $text field: %s", k),
+				"$text",
+			)
+		}
+	}
+
+	if !expr.Has("$search") {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFailedToParse,
+			"BSON field '$search' is missing but a required field",
+			"$text",
+		)
+	}
+
+	parseTextSearchString(search, &ts)
+
+	return &ts, nil
+}
+
+// parseTextSearchString parses $search string into terms, phrases, and negations.
+//
+// Phrases are enclosed in double quotes; terms and phrases prefixed with hyphen are negations.
+func parseTextSearchString(search string, ts *backends.TextSearch) {
+	for search != "" {
+		search = strings.TrimLeft(search, " \t\n\r")
+		if search == "" {
+			break
+		}
+
+		var negation bool
+		if strings.HasPrefix(search, "-") {
+			negation = true
+			search = search[1:]
+		}
+
+		var token string
+		var phrase bool
+
+		if strings.HasPrefix(search, `"`) {
+			phrase = true
+			search = search[1:]
+
+			end := strings.Index(search, `"`)
+			if end < 0 {
+				end = len(search)
+			}
+
+			token = search[:end]
+			search = strings.TrimPrefix(search[end:], `"`)
+		} else {
+			end := strings.IndexAny(search, " \t\n\r")
+			if end < 0 {
+				end = len(search)
+			}
+
+			token = search[:end]
+			search = search[end:]
+		}
+
+		if strings.TrimSpace(token) == "" {
+			continue
+		}
+
+		switch {
+		case negation:
+			ts.Negations = append(ts.Negations, token)
+		case phrase:
+			ts.Phrases = append(ts.Phrases, token)
+		default:
+			ts.Terms = append(ts.Terms, token)
+		}
+	}
+}
@@ -61,6 +61,27 @@ func (a *Array) Freeze() {
 	}
 }
 
+// DeepFreeze freezes the array and all documents and arrays inside it.
+// After that, it is safe to share the array between goroutines without copying.
+//
+// It is safe to call DeepFreeze multiple times.
+func (a *Array) DeepFreeze() {
+	if a != nil {
+		deepFreeze(a)
+	}
+}
+
+// Frozen returns true if the array is frozen.
+//
+// It returns false for nil Array.
+func (a *Array) Frozen() bool {
+	if a == nil {
+		return false
+	}
+
+	return a.frozen
+}
+
 // checkFrozen panics if array is frozen.
 func (a *Array) checkFrozen() {
 	if a.frozen {
@@ -89,6 +89,26 @@ func TestArray(t *testing.T) {
 		})
 	})
 
+	t.Run("DeepFreeze", func(t *testing.T) {
+		t.Parallel()
+
+		nested := must.NotFail(NewArray(int32(42)))
+		a := must.NotFail(NewArray(nested, must.NotFail(NewDocument("foo", nested))))
+
+		a.DeepFreeze()
+		assert.True(t, a.Frozen())
+		assert.True(t, nested.Frozen())
+		assert.True(t, must.NotFail(a.Get(1)).(*Document).Frozen())
+
+		assert.PanicsWithValue(t, "array is frozen and can't be modified", func() {
+			nested.Set(0, int32(13))
+		})
+
+		b := a.DeepCopy()
+		assert.False(t, b.Frozen())
+		assert.False(t, must.NotFail(b.Get(0)).(*Array).Frozen())
+	})
+
 	t.Run("DeepCopy", func(t *testing.T) {
 		t.Parallel()
 
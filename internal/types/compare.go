@@ -100,9 +100,15 @@ func compareScalars(v1, v2 any) CompareResult {
 	case float64:
 		switch v2 := v2.(type) {
 		case float64:
-			if math.IsNaN(v1) && math.IsNaN(v2) {
+			switch {
+			case math.IsNaN(v1) && math.IsNaN(v2):
 				return Equal
+			case math.IsNaN(v1):
+				return Less
+			case math.IsNaN(v2):
+				return Greater
 			}
+
 			return compareOrdered(v1, v2)
 		case int32:
 			return compareNumbers(v1, int64(v2))
@@ -250,8 +256,16 @@ func compareOrdered[T constraints.Ordered](a, b T) CompareResult {
 	}
 }
 
-// compareNumbers compares BSON numbers.
+// compareNumbers compares BSON double and long numbers.
+//
+// Comparison is exact: long values beyond 2^53 are not converted to double,
+// so they are not rounded to the nearest representable double.
+// NaN is less than any other number, as in MongoDB.
 func compareNumbers(a float64, b int64) CompareResult {
+	if math.IsNaN(a) {
+		return Less
+	}
+
 	bigA := new(big.Float).SetFloat64(a).SetPrec(100000)
 	bigB := new(big.Float).SetInt64(b).SetPrec(100000)
 
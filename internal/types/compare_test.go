@@ -15,6 +15,7 @@
 package types
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -115,3 +116,120 @@ func TestCompare(t *testing.T) {
 		})
 	}
 }
+
+// TestComparePrecision tests comparison of numbers that could not be represented exactly as doubles.
+func TestComparePrecision(t *testing.T) {
+	t.Parallel()
+
+	const maxSafe = 1 << 53
+
+	for name, tc := range map[string]struct {
+		a        any
+		b        any
+		expected CompareResult
+	}{
+		"LongAboveMaxSafeDoubleGreater": {
+			a:        int64(maxSafe + 1),
+			b:        float64(maxSafe),
+			expected: Greater,
+		},
+		"LongAboveMaxSafeDoubleLess": {
+			a:        int64(maxSafe + 1),
+			b:        float64(maxSafe + 2),
+			expected: Less,
+		},
+		"LongAboveMaxSafeDoubleEqual": {
+			a:        int64(maxSafe + 2),
+			b:        float64(maxSafe + 2),
+			expected: Equal,
+		},
+		"NegativeLongBelowMinSafeDouble": {
+			a:        int64(-maxSafe - 1),
+			b:        float64(-maxSafe),
+			expected: Less,
+		},
+		"DoubleLongAboveMaxSafe": {
+			a:        float64(maxSafe),
+			b:        int64(maxSafe + 1),
+			expected: Less,
+		},
+		"MaxLongDouble": {
+			a:        int64(math.MaxInt64),
+			b:        float64(math.MaxInt64), // rounded to 2^63
+			expected: Less,
+		},
+		"MinLongDouble": {
+			a:        int64(math.MinInt64),
+			b:        float64(math.MinInt64), // exactly -2^63
+			expected: Equal,
+		},
+		"MaxLongMaxLongMinusOne": {
+			a:        int64(math.MaxInt64),
+			b:        int64(math.MaxInt64 - 1),
+			expected: Greater,
+		},
+		"DoubleFraction": {
+			a:        float64(maxSafe) - 0.5,
+			b:        int64(maxSafe - 1),
+			expected: Greater,
+		},
+		"IntDoubleFraction": {
+			a:        int32(1),
+			b:        1.0000000000000002,
+			expected: Less,
+		},
+		"InfLong": {
+			a:        math.Inf(+1),
+			b:        int64(math.MaxInt64),
+			expected: Greater,
+		},
+		"NegativeInfLong": {
+			a:        math.Inf(-1),
+			b:        int64(math.MinInt64),
+			expected: Less,
+		},
+		"NaNDouble": {
+			a:        math.NaN(),
+			b:        math.Inf(-1),
+			expected: Less,
+		},
+		"DoubleNaN": {
+			a:        float64(0),
+			b:        math.NaN(),
+			expected: Greater,
+		},
+		"NaNLong": {
+			a:        math.NaN(),
+			b:        int64(math.MinInt64),
+			expected: Less,
+		},
+		"LongNaN": {
+			a:        int64(0),
+			b:        math.NaN(),
+			expected: Greater,
+		},
+		"NaNInt": {
+			a:        math.NaN(),
+			b:        int32(0),
+			expected: Less,
+		},
+		"IntNaN": {
+			a:        int32(math.MinInt32),
+			b:        math.NaN(),
+			expected: Greater,
+		},
+		"NaNNaN": {
+			a:        math.NaN(),
+			b:        math.NaN(),
+			expected: Equal,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.expected, Compare(tc.a, tc.b))
+			require.Equal(t, compareInvert(tc.expected), Compare(tc.b, tc.a))
+		})
+	}
+}
@@ -166,6 +166,29 @@ func (d *Document) Freeze() {
 	}
 }
 
+// DeepFreeze freezes the document and all documents and arrays inside it.
+// After that, it is safe to share the document between goroutines without copying.
+//
+// Binary values are not copied, so their bytes should not be modified.
+//
+// It is safe to call DeepFreeze multiple times.
+func (d *Document) DeepFreeze() {
+	if d != nil {
+		deepFreeze(d)
+	}
+}
+
+// Frozen returns true if the document is frozen.
+//
+// It returns false for nil Document.
+func (d *Document) Frozen() bool {
+	if d == nil {
+		return false
+	}
+
+	return d.frozen
+}
+
 // checkFrozen panics if document is frozen.
 func (d *Document) checkFrozen() {
 	if d.frozen {
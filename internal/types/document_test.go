@@ -89,6 +89,39 @@ func TestDocument(t *testing.T) {
 		})
 	})
 
+	t.Run("DeepFreeze", func(t *testing.T) {
+		t.Parallel()
+
+		nested := must.NotFail(NewDocument("bar", int32(42)))
+		arr := must.NotFail(NewArray(must.NotFail(NewDocument("baz", int32(42)))))
+		doc := must.NotFail(NewDocument("foo", nested, "arr", arr))
+
+		doc.DeepFreeze()
+		doc.DeepFreeze()
+		assert.True(t, doc.Frozen())
+		assert.True(t, nested.Frozen())
+		assert.True(t, arr.Frozen())
+
+		assert.PanicsWithValue(t, "document is frozen and can't be modified", func() {
+			nested.Set("bar", Null)
+		})
+		assert.PanicsWithValue(t, "document is frozen and can't be modified", func() {
+			must.NotFail(arr.Get(0)).(*Document).Remove("baz")
+		})
+		assert.PanicsWithValue(t, "array is frozen and can't be modified", func() {
+			arr.Append(int32(13))
+		})
+
+		c := doc.DeepCopy()
+		assert.False(t, c.Frozen())
+		assert.False(t, must.NotFail(c.Get("foo")).(*Document).Frozen())
+		c.Set("foo", Null)
+
+		var nilDoc *Document
+		nilDoc.DeepFreeze()
+		assert.False(t, nilDoc.Frozen())
+	})
+
 	t.Run("DeepCopy", func(t *testing.T) {
 		t.Parallel()
 
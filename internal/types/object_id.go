@@ -15,8 +15,8 @@
 package types
 
 import (
+	"crypto/rand"
 	"encoding/binary"
-	"math/rand"
 	"sync/atomic"
 	"time"
 
@@ -32,19 +32,65 @@ type ObjectID [ObjectIDLen]byte
 // ObjectIDLen is an ObjectID length in bytes.
 const ObjectIDLen = 12
 
-// NewObjectID returns a new ObjectID.
-func NewObjectID() ObjectID {
-	return newObjectIDTime(time.Now()) // https://github.com/FerretDB/FerretDB/issues/3486
+// Timestamp returns the creation time encoded in the ObjectID, with a second precision.
+func (id ObjectID) Timestamp() time.Time {
+	return time.Unix(int64(binary.BigEndian.Uint32(id[0:4])), 0)
+}
+
+// ObjectIDGenerator generates ObjectIDs according to the specification
+// (https://github.com/mongodb/specifications/blob/master/source/bson-objectid/objectid.md):
+// 4-byte timestamp, 5-byte value unique to the machine and process, and 3-byte incrementing counter.
+//
+// It is safe for concurrent use.
+type ObjectIDGenerator struct {
+	now     func() time.Time
+	process [5]byte
+	counter atomic.Uint32 // only the least significant 3 bytes are used
+}
+
+// newObjectIDGenerator returns a new generator with random process-unique value and counter.
+func newObjectIDGenerator() *ObjectIDGenerator {
+	g := &ObjectIDGenerator{
+		now: time.Now,
+	}
+
+	// to make debugging easier
+	if debugbuild.Enabled {
+		return g
+	}
+
+	must.NotFail(rand.Read(g.process[:]))
+
+	var c [4]byte
+	must.NotFail(rand.Read(c[:]))
+	g.counter.Store(binary.BigEndian.Uint32(c[:]))
+
+	return g
 }
 
-// newObjectIDTime returns a new ObjectID with given time.
-func newObjectIDTime(t time.Time) ObjectID {
+// NewObjectIDGenerator returns a generator that produces deterministic ObjectIDs
+// with the time returned by now, zero process-unique value, and counter starting from 1.
+//
+// It is used by tests.
+func NewObjectIDGenerator(now func() time.Time) *ObjectIDGenerator {
+	return &ObjectIDGenerator{
+		now: now,
+	}
+}
+
+// New returns a new ObjectID with the current time.
+func (g *ObjectIDGenerator) New() ObjectID {
+	return g.NewWithTime(g.now())
+}
+
+// NewWithTime returns a new ObjectID with the given time.
+func (g *ObjectIDGenerator) NewWithTime(t time.Time) ObjectID {
 	var res ObjectID
 
 	binary.BigEndian.PutUint32(res[0:4], uint32(t.Unix()))
-	copy(res[4:9], objectIDProcess[:])
+	copy(res[4:9], g.process[:])
 
-	c := objectIDCounter.Add(1)
+	c := g.counter.Add(1)
 
 	// ignore the most significant byte for correct wraparound
 	res[9] = byte(c >> 16)
@@ -54,17 +100,17 @@ func newObjectIDTime(t time.Time) ObjectID {
 	return res
 }
 
-var (
-	objectIDProcess [5]byte
-	objectIDCounter atomic.Uint32
-)
+// defaultObjectIDGenerator is used by NewObjectID and NewObjectIDWithTime.
+var defaultObjectIDGenerator = newObjectIDGenerator()
 
-func init() {
-	// to make debugging easier
-	if debugbuild.Enabled {
-		return
-	}
+// NewObjectID returns a new ObjectID with the current time.
+func NewObjectID() ObjectID {
+	return defaultObjectIDGenerator.New() // https://github.com/FerretDB/FerretDB/issues/3486
+}
 
-	must.NotFail(rand.Read(objectIDProcess[:]))
-	objectIDCounter.Store(rand.Uint32())
+// NewObjectIDWithTime returns a new ObjectID with the given time.
+//
+// It is unique in the same way as ObjectIDs returned by NewObjectID.
+func NewObjectIDWithTime(t time.Time) ObjectID {
+	return defaultObjectIDGenerator.NewWithTime(t)
 }
@@ -21,38 +21,63 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-//nolint:paralleltest // we modify the global objectIDProcess
-func TestNewObjectID(t *testing.T) {
-	objectIDProcess = [5]byte{0x0b, 0xad, 0xc0, 0xff, 0xee}
+func TestObjectIDGenerator(t *testing.T) {
+	t.Parallel()
+
+	g := NewObjectIDGenerator(time.Now)
+	g.process = [5]byte{0x0b, 0xad, 0xc0, 0xff, 0xee}
 	d := time.Date(2022, time.April, 13, 12, 44, 42, 0, time.UTC)
 
-	objectIDCounter.Store(0)
 	assert.Equal(
 		t,
 		ObjectID{0x62, 0x56, 0xc5, 0xba, 0x0b, 0xad, 0xc0, 0xff, 0xee, 0x00, 0x00, 0x01},
-		newObjectIDTime(d),
+		g.NewWithTime(d),
 	)
 	assert.Equal(
 		t,
 		ObjectID{0x62, 0x56, 0xc5, 0xba, 0x0b, 0xad, 0xc0, 0xff, 0xee, 0x00, 0x00, 0x02},
-		newObjectIDTime(d),
+		g.NewWithTime(d),
 	)
 
 	// test wraparound
-	objectIDCounter.Store(1<<24 - 2)
+	g.counter.Store(1<<24 - 2)
 	assert.Equal(
 		t,
 		ObjectID{0x62, 0x56, 0xc5, 0xba, 0x0b, 0xad, 0xc0, 0xff, 0xee, 0xff, 0xff, 0xff},
-		newObjectIDTime(d),
+		g.NewWithTime(d),
 	)
 	assert.Equal(
 		t,
 		ObjectID{0x62, 0x56, 0xc5, 0xba, 0x0b, 0xad, 0xc0, 0xff, 0xee, 0x00, 0x00, 0x00},
-		newObjectIDTime(d),
+		g.NewWithTime(d),
 	)
 	assert.Equal(
 		t,
 		ObjectID{0x62, 0x56, 0xc5, 0xba, 0x0b, 0xad, 0xc0, 0xff, 0xee, 0x00, 0x00, 0x01},
-		newObjectIDTime(d),
+		g.NewWithTime(d),
 	)
+
+	assert.Equal(t, d, g.NewWithTime(d).Timestamp().UTC())
+}
+
+func TestNewObjectIDGenerator(t *testing.T) {
+	t.Parallel()
+
+	d := time.Date(2022, time.April, 13, 12, 44, 42, 0, time.UTC)
+	gen := NewObjectIDGenerator(func() time.Time { return d })
+
+	assert.Equal(t, ObjectID{0x62, 0x56, 0xc5, 0xba, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01}, gen.New())
+	assert.Equal(t, ObjectID{0x62, 0x56, 0xc5, 0xba, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02}, gen.New())
+
+	// generators do not share counters
+	gen = NewObjectIDGenerator(func() time.Time { return d })
+	assert.Equal(t, ObjectID{0x62, 0x56, 0xc5, 0xba, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01}, gen.New())
+}
+
+func TestNewObjectID(t *testing.T) {
+	t.Parallel()
+
+	a, b := NewObjectID(), NewObjectID()
+	assert.NotEqual(t, a, b)
+	assert.Equal(t, a[4:9], b[4:9])
 }
@@ -18,14 +18,12 @@ import (
 	"fmt"
 	"regexp"
 	"regexp/syntax"
+	"strings"
 
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 )
 
 var (
-	// ErrOptionNotImplemented indicates unimplemented regex option.
-	ErrOptionNotImplemented = fmt.Errorf("regex: option not implemented")
-
 	// ErrMissingParen indicates missing parentheses in regex expression.
 	ErrMissingParen = fmt.Errorf("Regular expression is invalid: missing )")
 
@@ -63,22 +61,46 @@ type Regex struct {
 	Options string
 }
 
+// UnknownOption returns the first option that is not one of
+// the PCRE options supported by MongoDB (i, m, s, x) and true, if there is such option.
+func (r Regex) UnknownOption() (rune, bool) {
+	for _, o := range r.Options {
+		switch o {
+		case 'i', 'm', 's', 'x':
+			continue
+		default:
+			return o, true
+		}
+	}
+
+	return 0, false
+}
+
 // Compile returns Go Regexp object.
+//
+// PCRE options i, m and s are translated to Go regexp flags,
+// option x is emulated by removing whitespaces and comments from the pattern.
+// Unknown options are ignored; use UnknownOption to validate them first.
 func (r Regex) Compile() (*regexp.Regexp, error) {
 	var opts string
+	var extended bool
+
 	for _, o := range r.Options {
 		switch o {
 		case 'i', 'm', 's':
 			opts += string(o)
 		case 'x':
-			// TODO https://github.com/FerretDB/FerretDB/issues/592
-			return nil, ErrOptionNotImplemented
+			extended = true
 		default:
 			continue
 		}
 	}
 
 	expr := r.Pattern
+	if extended {
+		expr = stripExtended(expr)
+	}
+
 	if opts != "" {
 		expr = "(?" + opts + ")" + expr
 	}
@@ -120,3 +142,54 @@ func (r Regex) Compile() (*regexp.Regexp, error) {
 
 	return nil, lazyerrors.Error(err)
 }
+
+// stripExtended removes unescaped whitespaces and #-comments outside of character classes
+// from the pattern, as PCRE does for the extended (x) option.
+func stripExtended(pattern string) string {
+	var res strings.Builder
+	var escaped, inClass, inComment bool
+
+	// classStart is the position of the first character of the current character class;
+	// `]` at that position is a literal, as in `[]a]` or `[^]a]`
+	classStart := -1
+
+	for i, c := range pattern {
+		switch {
+		case inComment:
+			if c == '\n' {
+				inComment = false
+			}
+
+			continue
+
+		case escaped:
+			escaped = false
+
+		case c == '\\':
+			escaped = true
+
+		case inClass:
+			switch {
+			case c == '^' && i == classStart:
+				classStart++
+			case c == ']' && i != classStart:
+				inClass = false
+			}
+
+		case c == '[':
+			inClass = true
+			classStart = i + 1
+
+		case c == '#':
+			inComment = true
+			continue
+
+		case strings.ContainsRune(" \t\n\v\f\r", c):
+			continue
+		}
+
+		res.WriteRune(c)
+	}
+
+	return res.String()
+}
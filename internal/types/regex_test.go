@@ -0,0 +1,75 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegexCompileExtended(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		pattern  string
+		expected string
+	}{
+		"Whitespace": {
+			pattern:  "a b\tc\nd",
+			expected: "abcd",
+		},
+		"Comment": {
+			pattern:  "a # comment\nb",
+			expected: "ab",
+		},
+		"Escaped": {
+			pattern:  `a\ b\#c`,
+			expected: `a\ b\#c`,
+		},
+		"CharacterClass": {
+			pattern:  "[ #] [^] ] []#]",
+			expected: "[ #][^] ][]#]",
+		},
+	} {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, stripExtended(tc.pattern))
+
+			_, err := Regex{Pattern: tc.pattern, Options: "x"}.Compile()
+			require.NoError(t, err)
+		})
+	}
+
+	re, err := Regex{Pattern: "f o o # comment", Options: "ix"}.Compile()
+	require.NoError(t, err)
+	assert.True(t, re.MatchString("FOO"))
+	assert.False(t, re.MatchString("f o o"))
+}
+
+func TestRegexUnknownOption(t *testing.T) {
+	t.Parallel()
+
+	_, ok := Regex{Options: "imsx"}.UnknownOption()
+	assert.False(t, ok)
+
+	o, ok := Regex{Options: "ig"}.UnknownOption()
+	assert.True(t, ok)
+	assert.Equal(t, 'g', o)
+}
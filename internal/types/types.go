@@ -183,3 +183,22 @@ func deepCopy(value any) any {
 		panic(fmt.Sprintf("types.deepCopy: unexpected type %[1]T (%#[1]v)", value))
 	}
 }
+
+// deepFreeze freezes the given value and all documents and arrays inside it.
+func deepFreeze(value any) {
+	switch value := value.(type) {
+	case *Document:
+		value.Freeze()
+
+		for _, f := range value.fields {
+			deepFreeze(f.value)
+		}
+
+	case *Array:
+		value.Freeze()
+
+		for _, v := range value.s {
+			deepFreeze(v)
+		}
+	}
+}
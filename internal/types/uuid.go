@@ -0,0 +1,47 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// NewUUIDv7 returns a new UUID version 7 with the given time as a Binary value with UUID subtype.
+//
+// UUIDv7 values start with a millisecond Unix timestamp, so they are sortable by creation time.
+// See https://www.rfc-editor.org/rfc/rfc9562#section-5.7.
+// Sub-millisecond precision is stored in rand_a field (method 3 of section 6.2) to improve monotonicity.
+func NewUUIDv7(t time.Time) Binary {
+	b := make([]byte, 16)
+	must.NotFail(rand.Read(b[8:]))
+
+	ms := t.UnixMilli()
+	binary.BigEndian.PutUint64(b[0:8], uint64(ms)<<16)
+
+	// 12 bits of the fraction of millisecond
+	frac := uint16((t.UnixNano() - ms*int64(time.Millisecond)) * 4096 / int64(time.Millisecond))
+	binary.BigEndian.PutUint16(b[6:8], 0x7000|frac&0x0fff)
+
+	b[8] = 0x80 | b[8]&0x3f // variant 10
+
+	return Binary{
+		Subtype: BinaryUUID,
+		B:       b,
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUUIDv7(t *testing.T) {
+	t.Parallel()
+
+	d := time.Date(2023, time.October, 1, 12, 0, 0, int(500*time.Microsecond), time.UTC)
+	u := NewUUIDv7(d)
+
+	require.Equal(t, BinaryUUID, u.Subtype)
+	require.Len(t, u.B, 16)
+
+	assert.Equal(t, uint64(d.UnixMilli()), binary.BigEndian.Uint64(u.B[0:8])>>16)
+	assert.Equal(t, byte(0x7), u.B[6]>>4, "version")
+	assert.Equal(t, uint16(2048), binary.BigEndian.Uint16(u.B[6:8])&0x0fff, "half of millisecond")
+	assert.Equal(t, byte(0x2), u.B[8]>>6, "variant")
+
+	assert.NotEqual(t, u.B[8:], NewUUIDv7(d).B[8:])
+}
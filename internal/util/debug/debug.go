@@ -18,12 +18,14 @@ package debug
 import (
 	"bytes"
 	"context"
+	"crypto/subtle"
 	_ "expvar" // for metrics
 	"fmt"
 	"net"
 	"net/http"
 	_ "net/http/pprof" // for profiling
 	"slices"
+	"strings"
 	"text/template"
 	"time"
 
@@ -36,8 +38,26 @@ import (
 	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
-// RunHandler runs debug handler.
-func RunHandler(ctx context.Context, addr string, r prometheus.Registerer, l *zap.Logger) {
+// RunHandlerOpts represents debug handler configuration.
+type RunHandlerOpts struct {
+	Addr string
+	R    prometheus.Registerer
+	L    *zap.Logger
+
+	TLSCertFile string // if empty, plain HTTP is used
+	TLSKeyFile  string
+
+	Username string // if empty, basic authentication is disabled
+	Password string
+	Token    string // if empty, bearer token authentication is disabled
+}
+
+// RunHandler runs debug handler until ctx is canceled.
+//
+// If username or token is set, all requests should be authenticated with either of them.
+func RunHandler(ctx context.Context, opts *RunHandlerOpts) {
+	addr, r, l := opts.Addr, opts.R, opts.L
+
 	stdL := must.NotFail(zap.NewStdLogAt(l, zap.WarnLevel))
 
 	http.Handle("/debug/metrics", promhttp.InstrumentMetricHandler(
@@ -49,11 +69,11 @@ func RunHandler(ctx context.Context, addr string, r prometheus.Registerer, l *za
 		}),
 	))
 
-	opts := []statsviz.Option{
+	statsvizOpts := []statsviz.Option{
 		statsviz.Root("/debug/graphs"),
 		// TODO https://github.com/FerretDB/FerretDB/issues/3600
 	}
-	must.NoError(statsviz.Register(http.DefaultServeMux, opts...))
+	must.NoError(statsviz.Register(http.DefaultServeMux, statsvizOpts...))
 
 	handlers := map[string]string{
 		// custom handlers registered above
@@ -88,6 +108,7 @@ func RunHandler(ctx context.Context, addr string, r prometheus.Registerer, l *za
 
 	s := http.Server{
 		Addr:     addr,
+		Handler:  authHandler(http.DefaultServeMux, opts.Username, opts.Password, opts.Token),
 		ErrorLog: stdL,
 		BaseContext: func(_ net.Listener) context.Context {
 			return ctx
@@ -97,7 +118,12 @@ func RunHandler(ctx context.Context, addr string, r prometheus.Registerer, l *za
 	go func() {
 		lis := must.NotFail(net.Listen("tcp", addr))
 
-		root := fmt.Sprintf("http://%s", lis.Addr())
+		scheme := "http"
+		if opts.TLSCertFile != "" {
+			scheme = "https"
+		}
+
+		root := fmt.Sprintf("%s://%s", scheme, lis.Addr())
 
 		l.Sugar().Infof("Starting debug server on %s ...", root)
 
@@ -108,7 +134,14 @@ func RunHandler(ctx context.Context, addr string, r prometheus.Registerer, l *za
 			l.Sugar().Infof("%s%s - %s", root, path, handlers[path])
 		}
 
-		if err := s.Serve(lis); err != http.ErrServerClosed {
+		var err error
+		if opts.TLSCertFile != "" {
+			err = s.ServeTLS(lis, opts.TLSCertFile, opts.TLSKeyFile)
+		} else {
+			err = s.Serve(lis)
+		}
+
+		if err != http.ErrServerClosed {
 			panic(err)
 		}
 	}()
@@ -122,3 +155,44 @@ func RunHandler(ctx context.Context, addr string, r prometheus.Registerer, l *za
 	s.Close()
 	l.Sugar().Info("Debug server stopped.")
 }
+
+// authHandler returns a handler that checks basic authentication credentials or bearer token
+// before calling h.
+//
+// If both username and token are empty, h is returned as is.
+func authHandler(h http.Handler, username, password, token string) http.Handler {
+	if username == "" && token == "" {
+		return h
+	}
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if username != "" {
+			if u, p, ok := req.BasicAuth(); ok && equal(u, username) && equal(p, password) {
+				h.ServeHTTP(rw, req)
+				return
+			}
+		}
+
+		if token != "" {
+			if t, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok && equal(t, token) {
+				h.ServeHTTP(rw, req)
+				return
+			}
+		}
+
+		if username != "" {
+			rw.Header().Add("WWW-Authenticate", `Basic realm="FerretDB", charset="UTF-8"`)
+		}
+
+		if token != "" {
+			rw.Header().Add("WWW-Authenticate", `Bearer realm="FerretDB"`)
+		}
+
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	})
+}
+
+// equal compares strings in constant time.
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
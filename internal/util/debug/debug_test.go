@@ -14,8 +14,89 @@
 
 package debug
 
-import "testing"
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
 
-func TestDummy(t *testing.T) {
-	// we need at least one test per package to correctly calculate coverage
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthHandler(t *testing.T) {
+	t.Parallel()
+
+	ok := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	})
+
+	for name, tc := range map[string]struct {
+		username string
+		password string
+		token    string
+		setup    func(req *http.Request)
+		code     int
+	}{
+		"Disabled": {
+			setup: func(*http.Request) {},
+			code:  http.StatusNoContent,
+		},
+		"BasicOK": {
+			username: "user",
+			password: "pass",
+			setup:    func(req *http.Request) { req.SetBasicAuth("user", "pass") },
+			code:     http.StatusNoContent,
+		},
+		"BasicWrongPassword": {
+			username: "user",
+			password: "pass",
+			setup:    func(req *http.Request) { req.SetBasicAuth("user", "wrong") },
+			code:     http.StatusUnauthorized,
+		},
+		"BasicMissing": {
+			username: "user",
+			password: "pass",
+			setup:    func(*http.Request) {},
+			code:     http.StatusUnauthorized,
+		},
+		"BearerOK": {
+			token: "secret",
+			setup: func(req *http.Request) { req.Header.Set("Authorization", "Bearer secret") },
+			code:  http.StatusNoContent,
+		},
+		"BearerWrong": {
+			token: "secret",
+			setup: func(req *http.Request) { req.Header.Set("Authorization", "Bearer wrong") },
+			code:  http.StatusUnauthorized,
+		},
+		"BearerNotEnabled": {
+			username: "user",
+			password: "pass",
+			setup:    func(req *http.Request) { req.Header.Set("Authorization", "Bearer ") },
+			code:     http.StatusUnauthorized,
+		},
+		"BothBasic": {
+			username: "user",
+			password: "pass",
+			token:    "secret",
+			setup:    func(req *http.Request) { req.SetBasicAuth("user", "pass") },
+			code:     http.StatusNoContent,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/debug/metrics", nil)
+			tc.setup(req)
+
+			rw := httptest.NewRecorder()
+			authHandler(ok, tc.username, tc.password, tc.token).ServeHTTP(rw, req)
+
+			assert.Equal(t, tc.code, rw.Code)
+
+			if tc.code == http.StatusUnauthorized {
+				assert.NotEmpty(t, rw.Header().Values("WWW-Authenticate"))
+			}
+		})
+	}
 }
@@ -26,6 +26,7 @@ import (
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/observability"
 	"github.com/FerretDB/FerretDB/internal/util/resource"
+	"github.com/FerretDB/FerretDB/internal/util/sqllog"
 )
 
 // DB wraps [*database/sql.DB] with tracing, metrics, logging, and resource tracking.
@@ -80,6 +81,7 @@ func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*Row
 
 	fields = append(fields, zap.Duration("time", time.Since(start)), zap.Error(err))
 	db.l.Sugar().With(fields...).Debugf("<<< %s", query)
+	sqllog.Log(ctx, db.l, query, args, time.Since(start), err)
 
 	return wrapRows(rows), err
 }
@@ -97,6 +99,7 @@ func (db *DB) QueryRowContext(ctx context.Context, query string, args ...any) *s
 
 	fields = append(fields, zap.Duration("time", time.Since(start)), zap.Error(row.Err()))
 	db.l.Sugar().With(fields...).Debugf("<<< %s", query)
+	sqllog.Log(ctx, db.l, query, args, time.Since(start), row.Err())
 
 	return row
 }
@@ -122,10 +125,26 @@ func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.R
 
 	fields = append(fields, zap.Int64p("rows", ra), zap.Duration("time", time.Since(start)), zap.Error(err))
 	db.l.Sugar().With(fields...).Debugf("<<< %s", query)
+	sqllog.Log(ctx, db.l, query, args, time.Since(start), err)
 
 	return res, err
 }
 
+// BeginTx calls [*sql.DB.BeginTx].
+//
+// The caller should commit or roll back the returned transaction.
+// InTransaction should be used instead when the transaction does not outlive a single function call.
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	defer observability.FuncCall(ctx)()
+
+	sqlTx, err := db.sqlDB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return wrapTx(sqlTx, db.l), nil
+}
+
 // InTransaction wraps the given function f in a transaction.
 //
 // If f returns an error or context is canceled, the transaction is rolled back.
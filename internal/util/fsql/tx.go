@@ -23,6 +23,7 @@ import (
 
 	"github.com/FerretDB/FerretDB/internal/util/observability"
 	"github.com/FerretDB/FerretDB/internal/util/resource"
+	"github.com/FerretDB/FerretDB/internal/util/sqllog"
 )
 
 // Tx wraps [*database/sql.Tx] with resource tracking.
@@ -76,6 +77,7 @@ func (tx *Tx) QueryContext(ctx context.Context, query string, args ...any) (*Row
 
 	fields = append(fields, zap.Duration("time", time.Since(start)), zap.Error(err))
 	tx.l.Sugar().With(fields...).Debugf("<<< %s", query)
+	sqllog.Log(ctx, tx.l, query, args, time.Since(start), err)
 
 	return wrapRows(rows), err
 }
@@ -93,6 +95,7 @@ func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...any) *s
 
 	fields = append(fields, zap.Duration("time", time.Since(start)), zap.Error(row.Err()))
 	tx.l.Sugar().With(fields...).Debugf("<<< %s", query)
+	sqllog.Log(ctx, tx.l, query, args, time.Since(start), row.Err())
 
 	return row
 }
@@ -118,6 +121,7 @@ func (tx *Tx) ExecContext(ctx context.Context, query string, args ...any) (sql.R
 
 	fields = append(fields, zap.Int64p("rows", ra), zap.Duration("time", time.Since(start)), zap.Error(err))
 	tx.l.Sugar().With(fields...).Debugf("<<< %s", query)
+	sqllog.Log(ctx, tx.l, query, args, time.Since(start), err)
 
 	return res, err
 }
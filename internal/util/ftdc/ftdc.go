@@ -0,0 +1,181 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ftdc writes diagnostic data snapshots in a format similar to MongoDB's
+// Full Time Diagnostic Data Capture (FTDC).
+//
+// Each file is a sequence of BSON documents `{_id: <date>, type: 0, doc: <snapshot>}`.
+// Unlike MongoDB, snapshots are stored as is, without delta encoding and compression.
+package ftdc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/bson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// filePrefix is the prefix of diagnostic data file names, the same as MongoDB uses.
+const filePrefix = "metrics."
+
+// Writer writes diagnostic data snapshots to files in the directory, rotating them.
+//
+// Methods are safe for concurrent use.
+//
+//nolint:vet // for readability
+type Writer struct {
+	dir         string
+	maxFileSize int64
+	maxFiles    int
+
+	m    sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// NewWriter creates a new Writer for the given directory, creating it if needed.
+//
+// A new file is started when the current one exceeds maxFileSize bytes;
+// only maxFiles newest files are kept.
+func NewWriter(dir string, maxFileSize int64, maxFiles int) (*Writer, error) {
+	if err := os.MkdirAll(dir, 0o777); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &Writer{
+		dir:         dir,
+		maxFileSize: maxFileSize,
+		maxFiles:    maxFiles,
+	}, nil
+}
+
+// Write writes a snapshot taken at the given time.
+func (w *Writer) Write(t time.Time, doc *types.Document) error {
+	d := must.NotFail(types.NewDocument(
+		"_id", t,
+		"type", int32(0),
+		"doc", doc,
+	))
+
+	bd, err := bson.ConvertDocument(d)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	b, err := bd.MarshalBinary()
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	if w.f == nil || w.size >= w.maxFileSize {
+		if err = w.rotate(t); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	n, err := w.f.Write(b)
+	w.size += int64(n)
+
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// rotate closes the current file, starts a new one, and removes old files.
+//
+// The caller must hold the lock.
+func (w *Writer) rotate(t time.Time) error {
+	if w.f != nil {
+		if err := w.f.Close(); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		w.f = nil
+	}
+
+	// the same format as MongoDB uses: metrics.2023-10-01T12-00-00Z-00000
+	name := filePrefix + t.UTC().Format("2006-01-02T15-04-05Z")
+
+	var f *os.File
+
+	for i := 0; ; i++ {
+		var err error
+
+		f, err = os.OpenFile(filepath.Join(w.dir, fmt.Sprintf("%s-%05d", name, i)), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o666)
+		if err == nil {
+			break
+		}
+
+		if !os.IsExist(err) {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	w.f = f
+	w.size = 0
+
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	var files []string
+
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), filePrefix) {
+			files = append(files, e.Name())
+		}
+	}
+
+	// names are sortable by time
+	slices.Sort(files)
+
+	for len(files) > w.maxFiles {
+		if err = os.Remove(filepath.Join(w.dir, files[0])); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		files = files[1:]
+	}
+
+	return nil
+}
+
+// Close closes the current file.
+func (w *Writer) Close() error {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	if w.f == nil {
+		return nil
+	}
+
+	err := w.f.Close()
+	w.f = nil
+
+	return err
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ftdc
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/bson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	w, err := NewWriter(dir, 1, 2)
+	require.NoError(t, err)
+
+	start := time.Date(2023, time.October, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		doc := must.NotFail(types.NewDocument("i", int32(i)))
+		require.NoError(t, w.Write(start.Add(time.Duration(i)*time.Second), doc))
+	}
+
+	require.NoError(t, w.Close())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+
+	// each file exceeds the limit after one write, and only two newest files are kept
+	assert.Equal(t, []string{"metrics.2023-10-01T12-00-01Z-00000", "metrics.2023-10-01T12-00-02Z-00000"}, names)
+
+	f, err := os.Open(filepath.Join(dir, names[1]))
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	var raw bson.Document
+	require.NoError(t, raw.ReadFrom(bufio.NewReader(f)))
+
+	actual, err := types.ConvertDocument(&raw)
+	require.NoError(t, err)
+
+	expected := must.NotFail(types.NewDocument(
+		"_id", start.Add(2*time.Second),
+		"type", int32(0),
+		"doc", must.NotFail(types.NewDocument("i", int32(2))),
+	))
+	testutil.AssertEqual(t, expected, actual)
+}
@@ -0,0 +1,274 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jobs provides a scheduler for periodic background jobs.
+//
+// Jobs are functions that are called with fixed intervals until the scheduler is closed.
+// They can be paused and resumed, and their state is exposed for currentOp command and metrics.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// Parts of Prometheus metric names.
+const (
+	namespace = "ferretdb"
+	subsystem = "jobs"
+)
+
+// Func is a job function.
+//
+// It should return when the context is canceled.
+type Func func(ctx context.Context) error
+
+// Info represents job state.
+//
+//nolint:vet // for readability
+type Info struct {
+	Name     string
+	Interval time.Duration
+	Paused   bool
+	Running  bool
+
+	Runs         int64
+	LastStart    time.Time // zero if the job never started
+	LastDuration time.Duration
+	LastError    string
+}
+
+// job represents a scheduled job.
+type job struct {
+	f    Func
+	info Info
+}
+
+// Scheduler runs periodic jobs.
+//
+// Methods are safe for concurrent use.
+//
+//nolint:vet // for readability
+type Scheduler struct {
+	l *zap.Logger
+
+	m    sync.Mutex
+	jobs map[string]*job
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	runs     *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewScheduler creates a new Scheduler without jobs.
+func NewScheduler(l *zap.Logger) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Scheduler{
+		l:      l,
+		jobs:   map[string]*job{},
+		ctx:    ctx,
+		cancel: cancel,
+		runs: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "runs_total",
+				Help:      "Total number of job runs.",
+			},
+			[]string{"job", "result"},
+		),
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "duration_seconds",
+				Help:      "Job runs duration in seconds.",
+				Buckets:   prometheus.ExponentialBuckets(0.001, 4, 10), // 1ms - 262s
+			},
+			[]string{"job"},
+		),
+	}
+}
+
+// Add schedules a new job with the given unique name and positive interval.
+//
+// The first run happens after the interval.
+func (s *Scheduler) Add(name string, interval time.Duration, f Func) {
+	if interval <= 0 {
+		panic(fmt.Sprintf("jobs.Scheduler.Add: invalid interval %s for job %q", interval, name))
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.jobs[name] != nil {
+		panic(fmt.Sprintf("jobs.Scheduler.Add: job %q already exists", name))
+	}
+
+	j := &job{
+		f: f,
+		info: Info{
+			Name:     name,
+			Interval: interval,
+		},
+	}
+	s.jobs[name] = j
+
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+		s.loop(j)
+	}()
+}
+
+// loop runs the given job until the scheduler is closed.
+func (s *Scheduler) loop(j *job) {
+	ticker := time.NewTicker(j.info.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.run(j)
+		}
+	}
+}
+
+// run runs the given job once unless it is paused.
+func (s *Scheduler) run(j *job) {
+	s.m.Lock()
+
+	if j.info.Paused {
+		s.m.Unlock()
+		return
+	}
+
+	name := j.info.Name
+	start := time.Now()
+	j.info.Running = true
+	j.info.LastStart = start
+
+	s.m.Unlock()
+
+	err := j.f(s.ctx)
+	d := time.Since(start)
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	j.info.Running = false
+	j.info.Runs++
+	j.info.LastDuration = d
+	j.info.LastError = ""
+
+	result := "ok"
+
+	if err != nil {
+		result = "error"
+		j.info.LastError = err.Error()
+
+		if s.ctx.Err() == nil {
+			s.l.Warn("Job failed", zap.String("job", name), zap.Duration("duration", d), zap.Error(err))
+		}
+	}
+
+	s.runs.WithLabelValues(name, result).Inc()
+	s.duration.WithLabelValues(name).Observe(d.Seconds())
+}
+
+// Pause pauses the job with the given name.
+// The current run, if any, is not interrupted.
+//
+// It returns false if there is no such job.
+func (s *Scheduler) Pause(name string) bool {
+	return s.setPaused(name, true)
+}
+
+// Resume resumes the job with the given name.
+//
+// It returns false if there is no such job.
+func (s *Scheduler) Resume(name string) bool {
+	return s.setPaused(name, false)
+}
+
+// setPaused sets the paused state of the job with the given name.
+func (s *Scheduler) setPaused(name string, paused bool) bool {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	j := s.jobs[name]
+	if j == nil {
+		return false
+	}
+
+	if j.info.Paused != paused {
+		s.l.Info("Job state changed", zap.String("job", name), zap.Bool("paused", paused))
+	}
+
+	j.info.Paused = paused
+
+	return true
+}
+
+// Jobs returns the state of all jobs sorted by name.
+func (s *Scheduler) Jobs() []Info {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	res := make([]Info, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		res = append(res, j.info)
+	}
+
+	slices.SortFunc(res, func(a, b Info) int { return strings.Compare(a.Name, b.Name) })
+
+	return res
+}
+
+// Close stops all jobs and waits for running jobs to return.
+func (s *Scheduler) Close() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Describe implements prometheus.Collector.
+func (s *Scheduler) Describe(ch chan<- *prometheus.Desc) {
+	s.runs.Describe(ch)
+	s.duration.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (s *Scheduler) Collect(ch chan<- prometheus.Metric) {
+	s.runs.Collect(ch)
+	s.duration.Collect(ch)
+}
+
+// check interfaces
+var (
+	_ prometheus.Collector = (*Scheduler)(nil)
+)
@@ -0,0 +1,84 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestScheduler(t *testing.T) {
+	t.Parallel()
+
+	s := NewScheduler(testutil.Logger(t))
+
+	var okRuns, failRuns atomic.Int64
+
+	s.Add("ok", 10*time.Millisecond, func(context.Context) error {
+		okRuns.Add(1)
+		return nil
+	})
+
+	s.Add("fail", 10*time.Millisecond, func(context.Context) error {
+		failRuns.Add(1)
+		return errors.New("boom")
+	})
+
+	assert.Panics(t, func() { s.Add("ok", time.Second, nil) })
+	assert.Panics(t, func() { s.Add("zero", 0, nil) })
+
+	require.Eventually(t, func() bool {
+		return okRuns.Load() > 1 && failRuns.Load() > 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.True(t, s.Pause("ok"))
+	assert.False(t, s.Pause("unknown"))
+
+	jobs := s.Jobs()
+	require.Len(t, jobs, 2)
+
+	assert.Equal(t, "fail", jobs[0].Name)
+	assert.Equal(t, "boom", jobs[0].LastError)
+	assert.False(t, jobs[0].Paused)
+	assert.NotZero(t, jobs[0].Runs)
+
+	assert.Equal(t, "ok", jobs[1].Name)
+	assert.Empty(t, jobs[1].LastError)
+	assert.True(t, jobs[1].Paused)
+	assert.False(t, jobs[1].LastStart.IsZero())
+
+	// wait for the current run, if any
+	time.Sleep(50 * time.Millisecond)
+
+	paused := okRuns.Load()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, paused, okRuns.Load())
+
+	require.True(t, s.Resume("ok"))
+
+	require.Eventually(t, func() bool {
+		return okRuns.Load() > paused
+	}, 5*time.Second, 10*time.Millisecond)
+
+	s.Close()
+}
@@ -0,0 +1,465 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package parquet provides a minimal writer of Apache Parquet files.
+//
+// Only flat schemas with optional columns of a few primitive types are supported.
+// Data is written uncompressed, using PLAIN encoding for values
+// and RLE encoding for definition levels (nulls), with a single data page per column chunk.
+package parquet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// magic is written at the start and the end of Parquet file.
+const magic = "PAR1"
+
+// Type represents column type.
+type Type int
+
+const (
+	// Boolean column contains bool values.
+	Boolean Type = iota
+
+	// Int32 column contains int32 values.
+	Int32
+
+	// Int64 column contains int64 values.
+	Int64
+
+	// Double column contains float64 values.
+	Double
+
+	// String column contains UTF-8 string values.
+	String
+
+	// JSON column contains string values with JSON documents.
+	JSON
+
+	// Timestamp column contains time.Time values stored with millisecond precision.
+	Timestamp
+)
+
+// String implements fmt.Stringer interface.
+func (t Type) String() string {
+	switch t {
+	case Boolean:
+		return "boolean"
+	case Int32:
+		return "int32"
+	case Int64:
+		return "int64"
+	case Double:
+		return "double"
+	case String:
+		return "string"
+	case JSON:
+		return "json"
+	case Timestamp:
+		return "timestamp"
+	default:
+		return fmt.Sprintf("Type(%d)", int(t))
+	}
+}
+
+// Parquet physical types.
+const (
+	physicalBoolean   = 0
+	physicalInt32     = 1
+	physicalInt64     = 2
+	physicalDouble    = 5
+	physicalByteArray = 6
+)
+
+// Parquet converted types.
+const (
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+	convertedJSON            = 19
+)
+
+// Other Parquet enums.
+const (
+	repetitionOptional = 1
+	encodingPlain      = 0
+	encodingRLE        = 3
+	codecUncompressed  = 0
+	pageTypeData       = 0
+)
+
+// physical returns Parquet physical type.
+func (t Type) physical() int32 {
+	switch t {
+	case Boolean:
+		return physicalBoolean
+	case Int32:
+		return physicalInt32
+	case Int64, Timestamp:
+		return physicalInt64
+	case Double:
+		return physicalDouble
+	case String, JSON:
+		return physicalByteArray
+	default:
+		panic(fmt.Sprintf("unexpected type %d", t))
+	}
+}
+
+// Column represents a column of the file schema.
+type Column struct {
+	Name string
+	Type Type
+}
+
+// columnChunk represents metadata of the written column chunk.
+type columnChunk struct {
+	offset    int64
+	size      int64
+	numValues int64
+}
+
+// rowGroup represents metadata of the written row group.
+type rowGroup struct {
+	columns []columnChunk
+	size    int64
+	numRows int64
+}
+
+// Writer writes Parquet file.
+//
+// It is not safe for concurrent use.
+type Writer struct {
+	w         io.Writer
+	columns   []Column
+	rowGroups []rowGroup
+	offset    int64
+	createdBy string
+}
+
+// NewWriter creates a new writer of Parquet file with the given schema.
+//
+// createdBy is stored in the file metadata.
+func NewWriter(w io.Writer, columns []Column, createdBy string) (*Writer, error) {
+	pw := &Writer{
+		w:         w,
+		columns:   columns,
+		createdBy: createdBy,
+	}
+
+	if err := pw.write([]byte(magic)); err != nil {
+		return nil, err
+	}
+
+	return pw, nil
+}
+
+// write writes the given bytes, tracking the current offset.
+func (pw *Writer) write(b []byte) error {
+	n, err := pw.w.Write(b)
+	pw.offset += int64(n)
+
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// WriteRowGroup writes a row group with the given rows.
+//
+// Each row should contain a value for each column in schema order.
+// Values should be nil or have Go types matching column types:
+// bool, int32, int64, float64, string (for both String and JSON), and time.Time.
+func (pw *Writer) WriteRowGroup(rows [][]any) error {
+	rg := rowGroup{
+		columns: make([]columnChunk, len(pw.columns)),
+		numRows: int64(len(rows)),
+	}
+
+	for i, col := range pw.columns {
+		values := make([]any, len(rows))
+
+		for j, row := range rows {
+			if len(row) != len(pw.columns) {
+				return lazyerrors.Errorf("row %d has %d values, expected %d", j, len(row), len(pw.columns))
+			}
+
+			values[j] = row[i]
+		}
+
+		page, err := encodePage(col, values)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		chunk := columnChunk{
+			offset:    pw.offset,
+			size:      int64(len(page)),
+			numValues: int64(len(values)),
+		}
+
+		if err = pw.write(page); err != nil {
+			return err
+		}
+
+		rg.columns[i] = chunk
+		rg.size += chunk.size
+	}
+
+	pw.rowGroups = append(pw.rowGroups, rg)
+
+	return nil
+}
+
+// Close writes file metadata.
+// It does not close the underlying writer.
+func (pw *Writer) Close() error {
+	meta := pw.fileMetadata()
+
+	var footer [4]byte
+	binary.LittleEndian.PutUint32(footer[:], uint32(len(meta)))
+
+	if err := pw.write(meta); err != nil {
+		return err
+	}
+
+	if err := pw.write(footer[:]); err != nil {
+		return err
+	}
+
+	return pw.write([]byte(magic))
+}
+
+// fileMetadata returns encoded FileMetaData structure.
+func (pw *Writer) fileMetadata() []byte {
+	var numRows int64
+	for _, rg := range pw.rowGroups {
+		numRows += rg.numRows
+	}
+
+	var t thriftWriter
+	t.structBegin()
+
+	t.fieldI32(1, 1) // version
+
+	// schema: root element and columns
+	t.fieldList(2, thriftStruct, len(pw.columns)+1)
+
+	t.structBegin()
+	t.fieldString(4, "schema")
+	t.fieldI32(5, int32(len(pw.columns)))
+	t.structEnd()
+
+	for _, col := range pw.columns {
+		t.structBegin()
+		t.fieldI32(1, col.Type.physical())
+		t.fieldI32(3, repetitionOptional)
+		t.fieldString(4, col.Name)
+
+		switch col.Type {
+		case String:
+			t.fieldI32(6, convertedUTF8)
+		case JSON:
+			t.fieldI32(6, convertedJSON)
+		case Timestamp:
+			t.fieldI32(6, convertedTimestampMillis)
+		case Boolean, Int32, Int64, Double:
+			// no converted type
+		}
+
+		t.structEnd()
+	}
+
+	t.fieldI64(3, numRows)
+
+	t.fieldList(4, thriftStruct, len(pw.rowGroups))
+
+	for _, rg := range pw.rowGroups {
+		t.structBegin()
+
+		t.fieldList(1, thriftStruct, len(rg.columns))
+
+		for i, chunk := range rg.columns {
+			col := pw.columns[i]
+
+			t.structBegin()
+			t.fieldI64(2, chunk.offset) // file_offset
+
+			t.fieldStruct(3) // meta_data
+			t.fieldI32(1, col.Type.physical())
+			t.fieldList(2, thriftI32, 2) // encodings
+			t.varint(encodingPlain)
+			t.varint(encodingRLE)
+			t.fieldList(3, thriftBinary, 1) // path_in_schema
+			t.string(col.Name)
+			t.fieldI32(4, codecUncompressed)
+			t.fieldI64(5, chunk.numValues)
+			t.fieldI64(6, chunk.size) // total_uncompressed_size
+			t.fieldI64(7, chunk.size) // total_compressed_size
+			t.fieldI64(9, chunk.offset)
+			t.structEnd()
+
+			t.structEnd()
+		}
+
+		t.fieldI64(2, rg.size)
+		t.fieldI64(3, rg.numRows)
+		t.structEnd()
+	}
+
+	if pw.createdBy != "" {
+		t.fieldString(6, pw.createdBy)
+	}
+
+	t.structEnd()
+
+	return t.b
+}
+
+// encodePage returns encoded data page (with header) for the given column values.
+func encodePage(col Column, values []any) ([]byte, error) {
+	levels := make([]bool, len(values))
+
+	var data []byte
+	var bits []bool
+
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+
+		levels[i] = true
+
+		switch col.Type {
+		case Boolean:
+			b, ok := v.(bool)
+			if !ok {
+				return nil, valueError(col, v)
+			}
+
+			bits = append(bits, b)
+
+		case Int32:
+			n, ok := v.(int32)
+			if !ok {
+				return nil, valueError(col, v)
+			}
+
+			data = binary.LittleEndian.AppendUint32(data, uint32(n))
+
+		case Int64:
+			n, ok := v.(int64)
+			if !ok {
+				return nil, valueError(col, v)
+			}
+
+			data = binary.LittleEndian.AppendUint64(data, uint64(n))
+
+		case Double:
+			f, ok := v.(float64)
+			if !ok {
+				return nil, valueError(col, v)
+			}
+
+			data = binary.LittleEndian.AppendUint64(data, math.Float64bits(f))
+
+		case String, JSON:
+			s, ok := v.(string)
+			if !ok {
+				return nil, valueError(col, v)
+			}
+
+			data = binary.LittleEndian.AppendUint32(data, uint32(len(s)))
+			data = append(data, s...)
+
+		case Timestamp:
+			t, ok := v.(time.Time)
+			if !ok {
+				return nil, valueError(col, v)
+			}
+
+			data = binary.LittleEndian.AppendUint64(data, uint64(t.UnixMilli()))
+
+		default:
+			panic(fmt.Sprintf("unexpected type %d", col.Type))
+		}
+	}
+
+	if col.Type == Boolean {
+		data = make([]byte, (len(bits)+7)/8)
+		for i, b := range bits {
+			if b {
+				data[i/8] |= 1 << (i % 8)
+			}
+		}
+	}
+
+	defLevels := encodeLevels(levels)
+
+	body := binary.LittleEndian.AppendUint32(nil, uint32(len(defLevels)))
+	body = append(body, defLevels...)
+	body = append(body, data...)
+
+	var t thriftWriter
+	t.structBegin()
+	t.fieldI32(1, pageTypeData)
+	t.fieldI32(2, int32(len(body))) // uncompressed_page_size
+	t.fieldI32(3, int32(len(body))) // compressed_page_size
+	t.fieldStruct(5)                // data_page_header
+	t.fieldI32(1, int32(len(values)))
+	t.fieldI32(2, encodingPlain)
+	t.fieldI32(3, encodingRLE) // definition_level_encoding
+	t.fieldI32(4, encodingRLE) // repetition_level_encoding
+	t.structEnd()
+	t.structEnd()
+
+	return append(t.b, body...), nil
+}
+
+// encodeLevels encodes definition levels (with max level 1)
+// using runs of RLE / bit-packing hybrid encoding.
+func encodeLevels(levels []bool) []byte {
+	var res []byte
+
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+
+		res = binary.AppendUvarint(res, uint64(j-i)<<1)
+
+		if levels[i] {
+			res = append(res, 1)
+		} else {
+			res = append(res, 0)
+		}
+
+		i = j
+	}
+
+	return res
+}
+
+// valueError returns an error for the value that does not match column type.
+func valueError(col Column, v any) error {
+	return fmt.Errorf("column %q: unexpected value of type %T for %s column", col.Name, v, col.Type)
+}
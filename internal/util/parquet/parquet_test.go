@@ -0,0 +1,116 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	columns := []Column{
+		{Name: "_id", Type: String},
+		{Name: "b", Type: Boolean},
+		{Name: "i", Type: Int32},
+		{Name: "l", Type: Int64},
+		{Name: "d", Type: Double},
+		{Name: "t", Type: Timestamp},
+		{Name: "j", Type: JSON},
+	}
+
+	var buf bytes.Buffer
+
+	w, err := NewWriter(&buf, columns, "test")
+	require.NoError(t, err)
+
+	ts := time.Date(2023, time.October, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, w.WriteRowGroup([][]any{
+		{"a", true, int32(1), int64(2), 3.5, ts, `{"x":1}`},
+		{"b", nil, nil, nil, nil, nil, nil},
+	}))
+	require.NoError(t, w.WriteRowGroup([][]any{
+		{"c", false, int32(-1), int64(-2), -3.5, ts, `[]`},
+	}))
+
+	require.NoError(t, w.Close())
+
+	b := buf.Bytes()
+	require.Greater(t, len(b), 12)
+	assert.Equal(t, magic, string(b[:4]))
+	assert.Equal(t, magic, string(b[len(b)-4:]))
+
+	footer := int(binary.LittleEndian.Uint32(b[len(b)-8:]))
+	require.Less(t, footer, len(b)-12)
+
+	meta := b[len(b)-8-footer : len(b)-8]
+	for _, col := range columns {
+		assert.True(t, bytes.Contains(meta, []byte(col.Name)), "%s", col.Name)
+	}
+	assert.True(t, bytes.Contains(meta, []byte("test")))
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+
+		w, err := NewWriter(new(bytes.Buffer), columns[:1], "")
+		require.NoError(t, err)
+
+		err = w.WriteRowGroup([][]any{{int32(1)}})
+		assert.ErrorContains(t, err, `column "_id": unexpected value of type int32 for string column`)
+
+		err = w.WriteRowGroup([][]any{{"a", "b"}})
+		assert.ErrorContains(t, err, "row 0 has 2 values, expected 1")
+	})
+}
+
+func TestEncodeLevels(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		levels   []bool
+		expected []byte
+	}{
+		"Empty": {
+			levels:   nil,
+			expected: nil,
+		},
+		"AllDefined": {
+			levels:   []bool{true, true, true},
+			expected: []byte{3 << 1, 1},
+		},
+		"Mixed": {
+			levels:   []bool{true, false, false, true},
+			expected: []byte{1 << 1, 1, 2 << 1, 0, 1 << 1, 1},
+		},
+		"LongRun": {
+			levels:   make([]bool, 100),
+			expected: []byte{0xc8, 0x01, 0},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, encodeLevels(tc.levels))
+		})
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"encoding/binary"
+)
+
+// Thrift compact protocol field types.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes Parquet metadata structures using Thrift compact protocol.
+//
+// Only features used by Parquet metadata are implemented.
+type thriftWriter struct {
+	b    []byte
+	last []int16 // last field IDs of nested structs
+}
+
+// structBegin starts a struct.
+func (w *thriftWriter) structBegin() {
+	w.last = append(w.last, 0)
+}
+
+// structEnd writes a stop field and finishes a struct.
+func (w *thriftWriter) structEnd() {
+	w.b = append(w.b, 0)
+	w.last = w.last[:len(w.last)-1]
+}
+
+// fieldHeader writes a header of the field with the given ID and type.
+func (w *thriftWriter) fieldHeader(id int16, typ byte) {
+	last := &w.last[len(w.last)-1]
+
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.b = append(w.b, byte(delta)<<4|typ)
+	} else {
+		w.b = append(w.b, typ)
+		w.varint(int64(id))
+	}
+
+	*last = id
+}
+
+// varint writes a zigzag-encoded variable-length integer.
+func (w *thriftWriter) varint(v int64) {
+	w.b = binary.AppendVarint(w.b, v)
+}
+
+// uvarint writes an unsigned variable-length integer.
+func (w *thriftWriter) uvarint(v uint64) {
+	w.b = binary.AppendUvarint(w.b, v)
+}
+
+// fieldI32 writes i32 field.
+func (w *thriftWriter) fieldI32(id int16, v int32) {
+	w.fieldHeader(id, thriftI32)
+	w.varint(int64(v))
+}
+
+// fieldI64 writes i64 field.
+func (w *thriftWriter) fieldI64(id int16, v int64) {
+	w.fieldHeader(id, thriftI64)
+	w.varint(v)
+}
+
+// fieldString writes string (binary) field.
+func (w *thriftWriter) fieldString(id int16, v string) {
+	w.fieldHeader(id, thriftBinary)
+	w.string(v)
+}
+
+// string writes string value.
+func (w *thriftWriter) string(v string) {
+	w.uvarint(uint64(len(v)))
+	w.b = append(w.b, v...)
+}
+
+// fieldStruct writes struct field header and starts a struct; structEnd should be called after its fields.
+func (w *thriftWriter) fieldStruct(id int16) {
+	w.fieldHeader(id, thriftStruct)
+	w.structBegin()
+}
+
+// fieldList writes list field header with the given element type and size.
+func (w *thriftWriter) fieldList(id int16, elemType byte, size int) {
+	w.fieldHeader(id, thriftList)
+
+	if size < 15 {
+		w.b = append(w.b, byte(size)<<4|elemType)
+		return
+	}
+
+	w.b = append(w.b, 0xf0|elemType)
+	w.uvarint(uint64(size))
+}
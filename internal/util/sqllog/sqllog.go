@@ -0,0 +1,100 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sqllog provides per-request logging of SQL statements generated by backends.
+package sqllog
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Mode represents SQL statement logging mode.
+type Mode string
+
+const (
+	// NoneMode disables SQL statement logging.
+	NoneMode Mode = "none"
+
+	// RedactedMode logs SQL statements with bind parameters replaced by their types.
+	RedactedMode Mode = "redacted"
+
+	// FullMode logs SQL statements with bind parameter values.
+	FullMode Mode = "full"
+)
+
+// AllModes includes all SQL statement logging modes, with the first one being the default.
+var AllModes = []string{
+	string(NoneMode),
+	string(RedactedMode),
+	string(FullMode),
+}
+
+// contextKey is a named unexported type for the safe use of context.WithValue.
+type contextKey struct{}
+
+// Context key for Ctx/Log.
+var requestKey = contextKey{}
+
+// request represents information about the client request that is stored in the context.
+type request struct {
+	id   int32
+	mode Mode
+}
+
+// Ctx returns a derived context with the given wire protocol request ID and logging mode.
+//
+// If mode disables logging, ctx is returned as is.
+func Ctx(ctx context.Context, requestID int32, mode Mode) context.Context {
+	if mode != RedactedMode && mode != FullMode {
+		return ctx
+	}
+
+	return context.WithValue(ctx, requestKey, &request{id: requestID, mode: mode})
+}
+
+// Log logs the executed SQL statement with its bind parameters, duration and error
+// if logging is enabled for the request stored in ctx.
+func Log(ctx context.Context, l *zap.Logger, query string, args []any, d time.Duration, err error) {
+	r, _ := ctx.Value(requestKey).(*request)
+	if r == nil {
+		return
+	}
+
+	l.Info(
+		"SQL statement",
+		zap.Int32("request_id", r.id),
+		zap.String("sql", query),
+		zap.Any("args", bindArgs(r.mode, args)),
+		zap.Duration("time", d),
+		zap.Error(err),
+	)
+}
+
+// bindArgs returns bind parameters to log for the given mode.
+func bindArgs(mode Mode, args []any) []any {
+	if mode == FullMode {
+		return args
+	}
+
+	res := make([]any, len(args))
+	for i, arg := range args {
+		res[i] = fmt.Sprintf("<%T>", arg)
+	}
+
+	return res
+}
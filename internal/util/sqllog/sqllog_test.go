@@ -0,0 +1,79 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqllog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLog(t *testing.T) {
+	t.Parallel()
+
+	query := "SELECT _jsonb FROM test WHERE _jsonb->'v' = $1 LIMIT $2"
+	args := []any{"secret", int64(42)}
+
+	for name, tc := range map[string]struct {
+		mode Mode
+		args []any // nil if nothing should be logged
+	}{
+		"Empty": {
+			mode: "",
+		},
+		"None": {
+			mode: NoneMode,
+		},
+		"Redacted": {
+			mode: RedactedMode,
+			args: []any{"<string>", "<int64>"},
+		},
+		"Full": {
+			mode: FullMode,
+			args: args,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			core, logs := observer.New(zapcore.DebugLevel)
+			ctx := Ctx(context.Background(), 7, tc.mode)
+
+			Log(ctx, zap.New(core), query, args, time.Second, errors.New("boom"))
+
+			if tc.args == nil {
+				assert.Zero(t, logs.Len())
+				return
+			}
+
+			entries := logs.AllUntimed()
+			require.Len(t, entries, 1)
+
+			fields := entries[0].ContextMap()
+			assert.Equal(t, int32(7), fields["request_id"])
+			assert.Equal(t, query, fields["sql"])
+			assert.Equal(t, tc.args, fields["args"])
+			assert.Equal(t, "boom", fields["error"])
+		})
+	}
+}
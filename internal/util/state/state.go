@@ -16,6 +16,7 @@
 package state
 
 import (
+	"maps"
 	"time"
 
 	"github.com/AlekSi/pointer"
@@ -36,6 +37,10 @@ type State struct {
 	BackendName    string `json:"-"`
 	BackendVersion string `json:"-"`
 
+	// capability name (see backends.Capabilities) -> version (may be empty) for detected capabilities;
+	// may be nil if FerretDB did not connect to the backend yet
+	BackendCapabilities map[string]string `json:"-"`
+
 	// as reported by beacon, if known
 	LatestVersion   string `json:"-"`
 	UpdateAvailable bool   `json:"-"`
@@ -96,5 +101,7 @@ func (s *State) deepCopy() *State {
 		BackendVersion:  s.BackendVersion,
 		LatestVersion:   s.LatestVersion,
 		UpdateAvailable: s.UpdateAvailable,
+
+		BackendCapabilities: maps.Clone(s.BackendCapabilities),
 	}
 }
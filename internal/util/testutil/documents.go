@@ -0,0 +1,42 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// LargeDocument returns a large document with the given number of fields
+// containing nested documents and arrays.
+// It is close to typical documents from real workloads and is used in benchmarks.
+func LargeDocument(fields int) *types.Document {
+	doc := must.NotFail(types.NewDocument("_id", types.NewObjectID()))
+
+	for i := 0; i < fields; i++ {
+		doc.Set(fmt.Sprintf("field%d", i), must.NotFail(types.NewDocument(
+			"string", fmt.Sprintf("value %d", i),
+			"int32", int32(i),
+			"double", float64(i)+0.5,
+			"date", time.Date(2021, 11, 1, 10, 18, 42, 123000000, time.UTC),
+			"array", must.NotFail(types.NewArray(int32(i), "foo", false, types.Null)),
+		)))
+	}
+
+	return doc
+}
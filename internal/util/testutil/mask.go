@@ -0,0 +1,206 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/testutil/testtb"
+)
+
+// MaskWildcard is a path element that matches all fields of a document or all elements of an array.
+const MaskWildcard = "*"
+
+// MaskAction describes what Mask does with matched values.
+type MaskAction int
+
+const (
+	// MaskRemove removes matched fields from documents.
+	// Array elements can't be removed; they are replaced with null instead.
+	MaskRemove MaskAction = iota
+
+	// MaskType replaces matched values with the zero value of the same type,
+	// so only types are compared.
+	MaskType
+
+	// MaskNonZero replaces matched non-zero numbers with 1 of the same type,
+	// so only the fact that the value is set is compared.
+	// It is useful for cursor IDs.
+	MaskNonZero
+)
+
+// Mask describes a volatile value of the response that should not be compared as is,
+// like ObjectID, date, or size.
+//
+// Path may contain MaskWildcard elements.
+// Paths that do not exist are ignored.
+type Mask struct {
+	Path   types.Path
+	Action MaskAction
+}
+
+// ResponseMasks are masks for fields that are expected to differ in all responses
+// between FerretDB and MongoDB, and between runs.
+var ResponseMasks = []Mask{
+	{Path: types.NewStaticPath("$clusterTime"), Action: MaskRemove},
+	{Path: types.NewStaticPath("operationTime"), Action: MaskRemove},
+	{Path: types.NewStaticPath("cursor", "id"), Action: MaskNonZero},
+}
+
+// Normalize returns a deep copy of the given document with masks applied.
+func Normalize(tb testtb.TB, doc *types.Document, masks ...Mask) *types.Document {
+	tb.Helper()
+
+	res := doc.DeepCopy()
+
+	for _, m := range masks {
+		applyMask(tb, res, m.Path.Slice(), m.Action)
+	}
+
+	return res
+}
+
+// AssertEqualNormalized asserts that two documents are equal after applying masks to both of them.
+func AssertEqualNormalized(tb testtb.TB, expected, actual *types.Document, masks ...Mask) bool {
+	tb.Helper()
+
+	return AssertEqual(tb, Normalize(tb, expected, masks...), Normalize(tb, actual, masks...))
+}
+
+// applyMask applies the mask action to all values of comp matched by path elements.
+func applyMask(tb testtb.TB, comp any, path []string, action MaskAction) {
+	tb.Helper()
+
+	if len(path) == 0 {
+		return
+	}
+
+	elem, rest := path[0], path[1:]
+
+	switch c := comp.(type) {
+	case *types.Document:
+		keys := []string{elem}
+		if elem == MaskWildcard {
+			keys = c.Keys()
+		}
+
+		for _, k := range keys {
+			v, err := c.Get(k)
+			if err != nil {
+				continue
+			}
+
+			if len(rest) > 0 {
+				applyMask(tb, v, rest, action)
+				continue
+			}
+
+			if action == MaskRemove {
+				c.Remove(k)
+				continue
+			}
+
+			c.Set(k, maskValue(v, action))
+		}
+
+	case *types.Array:
+		indexes := make([]int, 0, c.Len())
+
+		if elem == MaskWildcard {
+			for i := 0; i < c.Len(); i++ {
+				indexes = append(indexes, i)
+			}
+		} else if i, err := strconv.Atoi(elem); err == nil && i >= 0 && i < c.Len() {
+			indexes = append(indexes, i)
+		}
+
+		for _, i := range indexes {
+			v, err := c.Get(i)
+			require.NoError(tb, err)
+
+			if len(rest) > 0 {
+				applyMask(tb, v, rest, action)
+				continue
+			}
+
+			var masked any = types.Null
+			if action != MaskRemove {
+				masked = maskValue(v, action)
+			}
+
+			require.NoError(tb, c.Set(i, masked))
+		}
+	}
+}
+
+// maskValue returns the masked replacement of the given value.
+func maskValue(v any, action MaskAction) any {
+	switch action {
+	case MaskType:
+		switch v.(type) {
+		case *types.Document:
+			return new(types.Document)
+		case *types.Array:
+			return new(types.Array)
+		case float64:
+			return float64(0)
+		case string:
+			return ""
+		case types.Binary:
+			return types.Binary{}
+		case types.ObjectID:
+			return types.ObjectID{}
+		case bool:
+			return false
+		case time.Time:
+			return time.Time{}
+		case types.Regex:
+			return types.Regex{}
+		case int32:
+			return int32(0)
+		case types.Timestamp:
+			return types.Timestamp(0)
+		case int64:
+			return int64(0)
+		default:
+			return v
+		}
+
+	case MaskNonZero:
+		switch v := v.(type) {
+		case float64:
+			if v != 0 {
+				return float64(1)
+			}
+		case int32:
+			if v != 0 {
+				return int32(1)
+			}
+		case int64:
+			if v != 0 {
+				return int64(1)
+			}
+		}
+
+		return v
+
+	default:
+		return v
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	newDoc := func(id types.ObjectID, date time.Time, size int32, cursorID int64) *types.Document {
+		return must.NotFail(types.NewDocument(
+			"cursor", must.NotFail(types.NewDocument(
+				"firstBatch", must.NotFail(types.NewArray(
+					must.NotFail(types.NewDocument("_id", id, "date", date)),
+					must.NotFail(types.NewDocument("_id", id, "date", date)),
+				)),
+				"id", cursorID,
+			)),
+			"size", size,
+			"operationTime", types.Timestamp(uint64(date.Unix())),
+			"ok", float64(1),
+		))
+	}
+
+	masks := append([]Mask{
+		{Path: types.NewStaticPath("cursor", "firstBatch", MaskWildcard, "_id"), Action: MaskType},
+		{Path: types.NewStaticPath("cursor", "firstBatch", MaskWildcard, "date"), Action: MaskType},
+		{Path: types.NewStaticPath("size"), Action: MaskType},
+		{Path: types.NewStaticPath("does-not-exist", "foo"), Action: MaskRemove},
+	}, ResponseMasks...)
+
+	now := time.Now()
+	d1 := newDoc(types.NewObjectID(), now, 42, 100)
+	d2 := newDoc(types.NewObjectID(), now.Add(time.Hour), 43, 200)
+
+	AssertNotEqual(t, d1, d2)
+	AssertEqualNormalized(t, d1, d2, masks...)
+
+	expected := must.NotFail(types.NewDocument(
+		"cursor", must.NotFail(types.NewDocument(
+			"firstBatch", must.NotFail(types.NewArray(
+				must.NotFail(types.NewDocument("_id", types.ObjectID{}, "date", time.Time{})),
+				must.NotFail(types.NewDocument("_id", types.ObjectID{}, "date", time.Time{})),
+			)),
+			"id", int64(1),
+		)),
+		"size", int32(0),
+		"ok", float64(1),
+	))
+	AssertEqual(t, expected, Normalize(t, d1, masks...))
+
+	// the original document is not modified
+	assert.Equal(t, int32(42), must.NotFail(d1.Get("size")))
+
+	// zero cursor ID is kept
+	AssertNotEqual(t, Normalize(t, newDoc(types.ObjectID{}, now, 0, 0), masks...), Normalize(t, d1, masks...))
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testfixtures
+
+import (
+	"math"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// Scalars contains one or more values of every supported scalar BSON type.
+//
+// Keys are prefixed by the type alias, so tests could select a type matrix by prefix.
+//
+// This fixture is frozen. If you need more values, add them in the test itself.
+var Scalars = NewValues("Scalars", map[string]any{
+	"double":          42.13,
+	"double-whole":    42.0,
+	"double-zero":     0.0,
+	"double-max":      math.MaxFloat64,
+	"double-smallest": math.SmallestNonzeroFloat64,
+	"double-big":      float64(1 << 61),
+
+	"string":       "foo",
+	"string-empty": "",
+
+	"binData":       types.Binary{Subtype: types.BinaryUser, B: []byte{42, 0, 13}},
+	"binData-empty": types.Binary{B: []byte{}},
+
+	"objectId":       types.ObjectID{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x10, 0x11},
+	"objectId-empty": types.ObjectID{},
+
+	"bool-false": false,
+	"bool-true":  true,
+
+	"date":       time.Date(2021, 11, 1, 10, 18, 42, 123000000, time.UTC),
+	"date-epoch": time.Unix(0, 0).UTC(),
+
+	"null": types.Null,
+
+	"regex":       types.Regex{Pattern: "foo", Options: "i"},
+	"regex-empty": types.Regex{},
+
+	"int":      int32(42),
+	"int-zero": int32(0),
+	"int-max":  int32(math.MaxInt32),
+	"int-min":  int32(math.MinInt32),
+
+	"timestamp":   types.Timestamp(42),
+	"timestamp-i": types.Timestamp(1),
+
+	"long":      int64(42),
+	"long-zero": int64(0),
+	"long-max":  int64(math.MaxInt64),
+	"long-min":  int64(math.MinInt64),
+
+	"unset": Unset,
+})
+
+// Composites contains composite values (documents and arrays) for tests.
+//
+// This fixture is frozen. If you need more values, add them in the test itself.
+var Composites = NewValues("Composites", map[string]any{
+	"document":           must.NotFail(types.NewDocument("foo", int32(42))),
+	"document-composite": must.NotFail(types.NewDocument("foo", int32(42), "42", "foo", "array", must.NotFail(types.NewArray(int32(42), "foo", types.Null)))),
+	"document-empty":     types.MakeDocument(0),
+
+	"array":           must.NotFail(types.NewArray(int32(42))),
+	"array-two":       must.NotFail(types.NewArray(42.13, "foo")),
+	"array-three":     must.NotFail(types.NewArray(int32(42), "foo", types.Null)),
+	"array-embedded":  must.NotFail(types.NewArray(must.NotFail(types.NewDocument("foo", int32(42))))),
+	"array-empty":     types.MakeArray(0),
+	"array-null":      must.NotFail(types.NewArray(types.Null)),
+	"array-documents": must.NotFail(types.NewArray(must.NotFail(types.NewDocument("field", int32(42))), must.NotFail(types.NewDocument("field", int32(44))))),
+})
@@ -0,0 +1,162 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package testfixtures provides deterministic test data sets (fixtures) and a seeding API.
+//
+// Fixtures are declared as plain Go values and are usable by both unit tests (via Seed and backends)
+// and integration tests (via shareddata adapters), making tests hermetic.
+// They complement integration/shareddata providers rather than replace them;
+// all fixture values must be storable by every FerretDB backend.
+//
+// It is in a separate package to avoid import cycles.
+package testfixtures
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// Unset represents a value that should not be set.
+var Unset = struct{}{}
+
+// Fixture represents a named deterministic set of documents.
+//
+//nolint:vet // for readability
+type Fixture struct {
+	name     string
+	backends []string // empty value means all backends
+	docs     func() []*types.Document
+}
+
+// NewValues returns a fixture with {"_id": key, "v": value} documents sorted by key.
+//
+// Values equal to Unset produce documents without "v" field.
+// Documents are copied on every Docs call, so they could be safely modified by tests.
+func NewValues(name string, data map[string]any) *Fixture {
+	return &Fixture{
+		name: name,
+		docs: func() []*types.Document {
+			keys := maps.Keys(data)
+			slices.Sort(keys)
+
+			res := make([]*types.Document, 0, len(keys))
+
+			for _, k := range keys {
+				doc := must.NotFail(types.NewDocument("_id", k))
+
+				if v := data[k]; v != Unset {
+					doc.Set("v", v)
+				}
+
+				res = append(res, doc.DeepCopy())
+			}
+
+			return res
+		},
+	}
+}
+
+// NewDocuments returns a fixture with given documents in the given order.
+//
+// Documents are copied on every Docs call, so they could be safely modified by tests.
+func NewDocuments(name string, backends []string, docs ...*types.Document) *Fixture {
+	for i, doc := range docs {
+		if !doc.Has("_id") {
+			panic(fmt.Sprintf("fixture %q: document %d has no _id", name, i))
+		}
+	}
+
+	return &Fixture{
+		name:     name,
+		backends: backends,
+		docs: func() []*types.Document {
+			res := make([]*types.Document, len(docs))
+			for i, doc := range docs {
+				res[i] = doc.DeepCopy()
+			}
+
+			return res
+		},
+	}
+}
+
+// Name returns fixture name.
+func (f *Fixture) Name() string {
+	return f.name
+}
+
+// Backends returns backend names the fixture is applicable to; empty value means all backends.
+func (f *Fixture) Backends() []string {
+	return slices.Clone(f.backends)
+}
+
+// IsCompatible returns true if the fixture is applicable to the given backend.
+func (f *Fixture) IsCompatible(backend string) bool {
+	return len(f.backends) == 0 || slices.Contains(f.backends, backend)
+}
+
+// Docs returns new copies of fixture documents in a deterministic order.
+//
+// All calls return the same documents in the same order.
+func (f *Fixture) Docs() []*types.Document {
+	return f.docs()
+}
+
+// Seed inserts documents of all given fixtures into the given backend collection.
+//
+// The collection is created if it does not exist.
+func Seed(ctx context.Context, coll backends.Collection, fixtures ...*Fixture) error {
+	var docs []*types.Document
+	for _, f := range fixtures {
+		docs = append(docs, f.Docs()...)
+	}
+
+	if len(docs) == 0 {
+		return nil
+	}
+
+	if _, err := coll.InsertAll(ctx, &backends.InsertAllParams{Docs: docs}); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// All returns all fixtures in a deterministic order.
+func All() []*Fixture {
+	fixtures := []*Fixture{
+		Scalars,
+		Composites,
+	}
+
+	// check that names are unique
+	names := make(map[string]struct{}, len(fixtures))
+	for _, f := range fixtures {
+		if _, ok := names[f.name]; ok {
+			panic("duplicate fixture name: " + f.name)
+		}
+
+		names[f.name] = struct{}{}
+	}
+
+	return fixtures
+}
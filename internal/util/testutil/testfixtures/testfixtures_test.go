@@ -0,0 +1,108 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testfixtures
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/sqlite"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/state"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestFixtures(t *testing.T) {
+	t.Parallel()
+
+	for _, f := range All() {
+		f := f
+
+		t.Run(f.Name(), func(t *testing.T) {
+			t.Parallel()
+
+			docs1 := f.Docs()
+			docs2 := f.Docs()
+			require.NotEmpty(t, docs1)
+			testutil.AssertEqualSlices(t, docs1, docs2)
+
+			// documents should not be shared between calls
+			docs1[0].Set("_id", "changed")
+			assert.NotEqual(t, "changed", must.NotFail(docs2[0].Get("_id")))
+			assert.NotEqual(t, "changed", must.NotFail(f.Docs()[0].Get("_id")))
+
+			assert.True(t, f.IsCompatible("sqlite"))
+
+			// all fixture values should be storable by FerretDB
+			for _, doc := range docs2 {
+				assert.NoError(t, doc.ValidateData(), "%s", must.NotFail(doc.Get("_id")))
+			}
+		})
+	}
+}
+
+func TestNewDocuments(t *testing.T) {
+	t.Parallel()
+
+	f := NewDocuments("Test", []string{"postgresql"},
+		must.NotFail(types.NewDocument("_id", int32(2))),
+		must.NotFail(types.NewDocument("_id", int32(1))),
+	)
+
+	docs := f.Docs()
+	require.Len(t, docs, 2)
+	assert.Equal(t, int32(2), must.NotFail(docs[0].Get("_id")))
+	assert.Equal(t, int32(1), must.NotFail(docs[1].Get("_id")))
+
+	assert.True(t, f.IsCompatible("postgresql"))
+	assert.False(t, f.IsCompatible("sqlite"))
+
+	assert.Panics(t, func() {
+		NewDocuments("NoID", nil, must.NotFail(types.NewDocument("v", int32(1))))
+	})
+}
+
+func TestSeed(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	b, err := sqlite.NewBackend(&sqlite.NewBackendParams{URI: testutil.TestSQLiteURI(t, ""), L: testutil.Logger(t), P: sp})
+	require.NoError(t, err)
+	t.Cleanup(b.Close)
+
+	db, err := b.Database(testutil.DatabaseName(t))
+	require.NoError(t, err)
+
+	coll, err := db.Collection(testutil.CollectionName(t))
+	require.NoError(t, err)
+
+	require.NoError(t, Seed(ctx, coll, Scalars, Composites))
+
+	res, err := coll.Query(ctx, &backends.QueryParams{Sort: &backends.SortField{Key: "_id"}})
+	require.NoError(t, err)
+
+	docs, err := iterator.ConsumeValues[struct{}, *types.Document](res.Iter)
+	require.NoError(t, err)
+	assert.Len(t, docs, len(Scalars.Docs())+len(Composites.Docs()))
+}
@@ -0,0 +1,187 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// Compressor represents OP_COMPRESSED compressor ID.
+type Compressor uint8
+
+const (
+	// CompressorNoop does not compress the message.
+	CompressorNoop = Compressor(0)
+
+	// CompressorSnappy uses snappy block format.
+	CompressorSnappy = Compressor(1)
+
+	// CompressorZlib uses zlib format.
+	CompressorZlib = Compressor(2)
+
+	// CompressorZstd uses zstd format.
+	CompressorZstd = Compressor(3)
+)
+
+// compressorNames maps supported compressors to names used in the handshake.
+var compressorNames = map[Compressor]string{
+	CompressorNoop:   "noop",
+	CompressorSnappy: "snappy",
+	CompressorZlib:   "zlib",
+	CompressorZstd:   "zstd",
+}
+
+// zstdEncoder and zstdDecoder are created once and shared;
+// their EncodeAll and DecodeAll methods are safe for concurrent use.
+var (
+	zstdEncoder = sync.OnceValues(func() (*zstd.Encoder, error) {
+		return zstd.NewWriter(nil)
+	})
+	zstdDecoder = sync.OnceValues(func() (*zstd.Decoder, error) {
+		return zstd.NewReader(nil, zstd.WithDecoderMaxMemory(MaxMsgLen))
+	})
+)
+
+// CompressorFromName returns compressor for the given handshake name.
+// It returns false if compressor is not supported.
+func CompressorFromName(name string) (Compressor, bool) {
+	for c, n := range compressorNames {
+		if n == name {
+			return c, true
+		}
+	}
+
+	return CompressorNoop, false
+}
+
+// String returns compressor name as used in the handshake.
+func (c Compressor) String() string {
+	if n, ok := compressorNames[c]; ok {
+		return n
+	}
+
+	return fmt.Sprintf("Compressor(%d)", c)
+}
+
+// compress returns b compressed with c.
+func (c Compressor) compress(b []byte) ([]byte, error) {
+	switch c {
+	case CompressorNoop:
+		return b, nil
+
+	case CompressorSnappy:
+		return snappy.Encode(nil, b), nil
+
+	case CompressorZlib:
+		var buf bytes.Buffer
+
+		w := zlib.NewWriter(&buf)
+		if _, err := w.Write(b); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if err := w.Close(); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		return buf.Bytes(), nil
+
+	case CompressorZstd:
+		e, err := zstdEncoder()
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		return e.EncodeAll(b, nil), nil
+
+	default:
+		return nil, lazyerrors.Errorf("unsupported compressor %s", c)
+	}
+}
+
+// decompress returns b decompressed with c.
+// The result must have exactly size bytes.
+func (c Compressor) decompress(b []byte, size int32) ([]byte, error) {
+	if size < 0 || size > MaxMsgLen {
+		return nil, lazyerrors.Errorf("invalid uncompressed size %d", size)
+	}
+
+	var res []byte
+
+	switch c {
+	case CompressorNoop:
+		res = b
+
+	case CompressorSnappy:
+		l, err := snappy.DecodedLen(b)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if l != int(size) {
+			return nil, lazyerrors.Errorf("expected uncompressed size %d, got %d", size, l)
+		}
+
+		if res, err = snappy.Decode(nil, b); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+	case CompressorZlib:
+		r, err := zlib.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		defer r.Close()
+
+		// read one extra byte to detect data larger than declared
+		if res, err = io.ReadAll(io.LimitReader(r, int64(size)+1)); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+	case CompressorZstd:
+		d, err := zstdDecoder()
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if res, err = d.DecodeAll(b, make([]byte, 0, size)); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+	default:
+		return nil, lazyerrors.Errorf("unsupported compressor %s", c)
+	}
+
+	if len(res) != int(size) {
+		return nil, lazyerrors.Errorf("expected uncompressed size %d, got %d", size, len(res))
+	}
+
+	return res, nil
+}
+
+// check interfaces
+var (
+	_ fmt.Stringer = Compressor(0)
+)
@@ -56,6 +56,11 @@ func ReadMessage(r *bufio.Reader) (*MsgHeader, MsgBody, error) {
 		return nil, nil, lazyerrors.Errorf("expected %d, read %d: %w", len(b), n, err)
 	}
 
+	return readBody(&header, b)
+}
+
+// readBody parses message body b for the given header.
+func readBody(header *MsgHeader, b []byte) (*MsgHeader, MsgBody, error) {
 	switch header.OpCode {
 	case OpCodeReply: // not sent by clients, but we should be able to read replies from a proxy
 		var reply OpReply
@@ -63,19 +68,19 @@ func ReadMessage(r *bufio.Reader) (*MsgHeader, MsgBody, error) {
 			return nil, nil, lazyerrors.Error(err)
 		}
 
-		return &header, &reply, nil
+		return header, &reply, nil
 
 	case OpCodeMsg:
-		if err := validateChecksum(&header, b); err != nil {
-			return &header, nil, lazyerrors.Error(err)
+		if err := validateChecksum(header, b); err != nil {
+			return header, nil, lazyerrors.Error(err)
 		}
 
 		var msg OpMsg
 		if err := msg.UnmarshalBinary(b); err != nil {
-			return &header, nil, lazyerrors.Error(err)
+			return header, nil, lazyerrors.Error(err)
 		}
 
-		return &header, &msg, nil
+		return header, &msg, nil
 
 	case OpCodeQuery:
 		var query OpQuery
@@ -83,7 +88,15 @@ func ReadMessage(r *bufio.Reader) (*MsgHeader, MsgBody, error) {
 			return nil, nil, lazyerrors.Error(err)
 		}
 
-		return &header, &query, nil
+		return header, &query, nil
+
+	case OpCodeCompressed:
+		var msg OpCompressed
+		if err := msg.UnmarshalBinary(b); err != nil {
+			return nil, nil, lazyerrors.Error(err)
+		}
+
+		return header, &msg, nil
 
 	case OpCodeUpdate:
 		fallthrough
@@ -96,8 +109,6 @@ func ReadMessage(r *bufio.Reader) (*MsgHeader, MsgBody, error) {
 	case OpCodeDelete:
 		fallthrough
 	case OpCodeKillCursors:
-		fallthrough
-	case OpCodeCompressed:
 		return nil, nil, lazyerrors.Errorf("unhandled opcode %s", header.OpCode)
 
 	default:
@@ -56,7 +56,7 @@ const (
 	// OpCodeKillCursors is deprecated and unused.
 	OpCodeKillCursors = OpCode(2007) // OP_KILL_CURSORS
 
-	// OpCodeCompressed is not implemented yet.
+	// OpCodeCompressed wraps other messages compressed with the negotiated compressor.
 	OpCodeCompressed = OpCode(2012) // OP_COMPRESSED
 
 	// OpCodeMsg is the main operation for client-server communication.
@@ -0,0 +1,157 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// OpCompressed wraps another message compressed with one of the negotiated compressors.
+type OpCompressed struct {
+	OriginalOpCode    OpCode
+	UncompressedSize  int32
+	Compressor        Compressor
+	CompressedMessage []byte
+}
+
+func (msg *OpCompressed) msgbody() {}
+
+func (msg *OpCompressed) readFrom(bufr *bufio.Reader) error {
+	if err := binary.Read(bufr, binary.LittleEndian, &msg.OriginalOpCode); err != nil {
+		return lazyerrors.Errorf("wire.OpCompressed.ReadFrom (binary.Read): %w", err)
+	}
+	if err := binary.Read(bufr, binary.LittleEndian, &msg.UncompressedSize); err != nil {
+		return lazyerrors.Errorf("wire.OpCompressed.ReadFrom (binary.Read): %w", err)
+	}
+	if err := binary.Read(bufr, binary.LittleEndian, &msg.Compressor); err != nil {
+		return lazyerrors.Errorf("wire.OpCompressed.ReadFrom (binary.Read): %w", err)
+	}
+
+	if msg.OriginalOpCode == OpCodeCompressed {
+		return lazyerrors.New("wire.OpCompressed.ReadFrom: nested OP_COMPRESSED")
+	}
+
+	if s := msg.UncompressedSize; s < 0 || s > MaxMsgLen-MsgHeaderLen {
+		return lazyerrors.Errorf("wire.OpCompressed.ReadFrom: invalid UncompressedSize %d", s)
+	}
+
+	var err error
+	if msg.CompressedMessage, err = io.ReadAll(bufr); err != nil {
+		return lazyerrors.Errorf("wire.OpCompressed.ReadFrom: %w", err)
+	}
+
+	return nil
+}
+
+// UnmarshalBinary reads an OpCompressed from a byte array.
+func (msg *OpCompressed) UnmarshalBinary(b []byte) error {
+	bufr := bufio.NewReader(bytes.NewReader(b))
+
+	if err := msg.readFrom(bufr); err != nil {
+		return lazyerrors.Errorf("wire.OpCompressed.UnmarshalBinary: %w", err)
+	}
+
+	return nil
+}
+
+// MarshalBinary writes an OpCompressed to a byte array.
+func (msg *OpCompressed) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+
+	binary.Write(&buf, binary.LittleEndian, msg.OriginalOpCode)
+	binary.Write(&buf, binary.LittleEndian, msg.UncompressedSize)
+	binary.Write(&buf, binary.LittleEndian, msg.Compressor)
+	buf.Write(msg.CompressedMessage)
+
+	return buf.Bytes(), nil
+}
+
+// String returns a string representation for logging.
+func (msg *OpCompressed) String() string {
+	if msg == nil {
+		return "<nil>"
+	}
+
+	m := map[string]any{
+		"OriginalOpCode":   msg.OriginalOpCode.String(),
+		"UncompressedSize": msg.UncompressedSize,
+		"Compressor":       msg.Compressor.String(),
+		"CompressedSize":   len(msg.CompressedMessage),
+	}
+
+	return string(must.NotFail(json.MarshalIndent(m, "", "  ")))
+}
+
+// Compress returns header and OP_COMPRESSED body wrapping the given message compressed with c.
+func Compress(header *MsgHeader, msg MsgBody, c Compressor) (*MsgHeader, *OpCompressed, error) {
+	if header.OpCode == OpCodeCompressed {
+		return nil, nil, lazyerrors.New("message is already compressed")
+	}
+
+	b, err := msg.MarshalBinary()
+	if err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	compressed, err := c.compress(b)
+	if err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	res := &OpCompressed{
+		OriginalOpCode:    header.OpCode,
+		UncompressedSize:  int32(len(b)),
+		Compressor:        c,
+		CompressedMessage: compressed,
+	}
+
+	resHeader := &MsgHeader{
+		MessageLength: int32(MsgHeaderLen + 9 + len(compressed)),
+		RequestID:     header.RequestID,
+		ResponseTo:    header.ResponseTo,
+		OpCode:        OpCodeCompressed,
+	}
+
+	return resHeader, res, nil
+}
+
+// Decompress returns header and body of the message wrapped by OP_COMPRESSED.
+func Decompress(header *MsgHeader, msg *OpCompressed) (*MsgHeader, MsgBody, error) {
+	b, err := msg.Compressor.decompress(msg.CompressedMessage, msg.UncompressedSize)
+	if err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	originalHeader := MsgHeader{
+		MessageLength: MsgHeaderLen + msg.UncompressedSize,
+		RequestID:     header.RequestID,
+		ResponseTo:    header.ResponseTo,
+		OpCode:        msg.OriginalOpCode,
+	}
+
+	return readBody(&originalHeader, b)
+}
+
+// check interfaces
+var (
+	_ MsgBody = (*OpCompressed)(nil)
+)
@@ -0,0 +1,115 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestOpCompressed(t *testing.T) {
+	t.Parallel()
+
+	var msg OpMsg
+	require.NoError(t, msg.SetSections(OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"insert", "values",
+			"documents", must.NotFail(types.NewArray(
+				must.NotFail(types.NewDocument("_id", int32(1), "v", "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")),
+			)),
+			"$db", "test",
+		))},
+	}))
+
+	b := must.NotFail(msg.MarshalBinary())
+	header := &MsgHeader{
+		MessageLength: int32(MsgHeaderLen + len(b)),
+		RequestID:     3,
+		ResponseTo:    2,
+		OpCode:        OpCodeMsg,
+	}
+
+	for _, c := range []Compressor{CompressorNoop, CompressorSnappy, CompressorZlib, CompressorZstd} {
+		c := c
+		t.Run(c.String(), func(t *testing.T) {
+			t.Parallel()
+
+			compressedHeader, compressed, err := Compress(header, &msg, c)
+			require.NoError(t, err)
+			assert.Equal(t, OpCodeCompressed, compressedHeader.OpCode)
+			assert.Equal(t, header.RequestID, compressedHeader.RequestID)
+			assert.Equal(t, OpCodeMsg, compressed.OriginalOpCode)
+			assert.Equal(t, int32(len(b)), compressed.UncompressedSize)
+
+			var buf bytes.Buffer
+			bufw := bufio.NewWriter(&buf)
+			require.NoError(t, WriteMessage(bufw, compressedHeader, compressed))
+			require.NoError(t, bufw.Flush())
+
+			readHeader, readBody, err := ReadMessage(bufio.NewReader(&buf))
+			require.NoError(t, err)
+			assert.Equal(t, compressedHeader, readHeader)
+			require.IsType(t, new(OpCompressed), readBody)
+
+			originalHeader, originalBody, err := Decompress(readHeader, readBody.(*OpCompressed))
+			require.NoError(t, err)
+			assert.Equal(t, header, originalHeader)
+			testutil.AssertEqual(t, must.NotFail(msg.Document()), must.NotFail(originalBody.(*OpMsg).Document()))
+		})
+	}
+
+	t.Run("InvalidSize", func(t *testing.T) {
+		t.Parallel()
+
+		compressedHeader, compressed, err := Compress(header, &msg, CompressorZlib)
+		require.NoError(t, err)
+
+		compressed.UncompressedSize--
+
+		_, _, err = Decompress(compressedHeader, compressed)
+		require.Error(t, err)
+	})
+
+	t.Run("UnknownCompressor", func(t *testing.T) {
+		t.Parallel()
+
+		compressedHeader, compressed, err := Compress(header, &msg, CompressorNoop)
+		require.NoError(t, err)
+
+		compressed.Compressor = Compressor(42)
+
+		_, _, err = Decompress(compressedHeader, compressed)
+		require.Error(t, err)
+	})
+}
+
+func TestCompressorFromName(t *testing.T) {
+	t.Parallel()
+
+	c, ok := CompressorFromName("zstd")
+	assert.True(t, ok)
+	assert.Equal(t, CompressorZstd, c)
+
+	_, ok = CompressorFromName("lz4")
+	assert.False(t, ok)
+}